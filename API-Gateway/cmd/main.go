@@ -9,14 +9,24 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/redis/go-redis/v9"
 	"github.com/spf13/viper"
 	_ "github.com/studjobs/hh_for_students/api-gateway/docs"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/authn"
 	"github.com/studjobs/hh_for_students/api-gateway/internal/cache"
 	"github.com/studjobs/hh_for_students/api-gateway/internal/cleaner"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/deletion"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/export"
 	"github.com/studjobs/hh_for_students/api-gateway/internal/grpc"
 	"github.com/studjobs/hh_for_students/api-gateway/internal/handlers"
 	"github.com/studjobs/hh_for_students/api-gateway/internal/metrics"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/mtls"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/policy"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/ratelimit"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/registration"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/saga"
 	"github.com/studjobs/hh_for_students/api-gateway/internal/services"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/tracing"
 	"github.com/studjobs/hh_for_students/api-gateway/server"
 )
 
@@ -39,6 +49,11 @@ import (
 // @name Authorization
 // @description JWT токен в формате: "Bearer {token}"
 
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @description API-ключ интеграции компании (выпускается в /company/api-keys)
+
 // @securityDefinitions.apikey RoleAuth
 // @in header
 // @name X-User-Role
@@ -51,6 +66,14 @@ func main() {
 		log.Fatalf("Error loading config: %v", err)
 	}
 
+	// Трассировка — до gRPC-клиентов: их инструментирование берёт глобальный
+	// TracerProvider при создании.
+	shutdownTracing, err := tracing.Init(context.Background(), mtls.Gateway)
+	if err != nil {
+		log.Fatalf("Failed to initialize tracing: %v", err)
+	}
+	defer shutdownTracing()
+
 	// Конфигурация gRPC клиентов из config.yaml
 	grpcConfig := grpc.Config{
 		AuthAddress:            viper.GetString("grpc.auth_address"),
@@ -62,6 +85,7 @@ func main() {
 		SearchAddress:          viper.GetString("grpc.search_address"),
 		MicroTasksAddress:      viper.GetString("grpc.microtasks_address"),
 		Timeout:                10 * time.Second,
+		TLS:                    mtls.ConfigFromEnv(),
 	}
 
 	// Остальной код без изменений...
@@ -90,41 +114,137 @@ func main() {
 	if v := viper.GetDuration("redis.ttl"); v > 0 {
 		cacheTTL = v
 	}
-	cacheClient := cache.New(redisAddr, cacheTTL)
+	// Жёсткие TTL по префиксам маршрутов и доля, в течение которой запись
+	// свежая; дальше — stale-while-revalidate.
+	routeTTLs, err := cache.ParseRouteTTLs(envString("CACHE_ROUTE_TTL", ""))
+	if err != nil {
+		log.Fatalf("CACHE_ROUTE_TTL: %v", err)
+	}
+	cacheTTLs := cache.TTLPolicy{
+		Default:     cacheTTL,
+		Routes:      routeTTLs,
+		SoftPercent: envInt("CACHE_SOFT_TTL_PERCENT", 50),
+	}
+	cacheClient := cache.New(redisAddr, cacheTTLs)
 	if cacheClient.Enabled() {
 		pingCtx, pingCancel := context.WithTimeout(context.Background(), 2*time.Second)
 		if err := cacheClient.Ping(pingCtx); err != nil {
 			log.Printf("redis ping failed (%v); cache will operate as no-op", err)
-			cacheClient = cache.New("", 0) // переключаем в no-op
+			cacheClient = cache.New("", cache.TTLPolicy{}) // переключаем в no-op
 		} else {
 			log.Printf("redis cache enabled at %s (TTL %s)", redisAddr, cacheTTL)
+			tracing.InstrumentRedis(cacheClient.Redis())
 		}
 		pingCancel()
 	} else {
 		log.Printf("redis cache disabled (REDIS_ADDR not set)")
 	}
 
-	// Rate limiter. Ключ — user-id из JWT (если есть), иначе IP. Дефолты подняты
-	// под активную SPA с debounce-fetch'ами и многими вкладками: 600/min = 10 RPS
-	// sustained, burst 100 закрывает single-flow spike (фильтры/пагинация/init-page).
+	// Rate limiter. Ключ — user-id из JWT (если есть), иначе IP. Лимиты групп
+	// маршрутов и ролей — в RATELIMIT_FILE; остальные запросы — по умолчанию.
+	// Дефолты подняты под активную SPA с debounce-fetch'ами и многими вкладками:
+	// 600/min = 10 RPS sustained, burst 100 закрывает single-flow spike
+	// (фильтры/пагинация/init-page). Счётчики — в Redis, общие для всех
+	// инстансов; пока Redis недоступен — в памяти процесса.
 	rateLimitPerMin := envInt("RATELIMIT_PER_MIN", 600)
 	rateLimitBurst := envInt("RATELIMIT_BURST", 100)
-	rateLimiter := handlers.NewRateLimiter(rateLimitPerMin, rateLimitBurst)
-	log.Printf("rate limiter enabled: %d req/min per key (user-id or IP), burst %d", rateLimitPerMin, rateLimitBurst)
+	rateLimitFile := envString("RATELIMIT_FILE", "configs/ratelimit.yaml")
+	rateLimitPolicies, err := ratelimit.Load(rateLimitFile, ratelimit.Limit{Rate: rateLimitPerMin, Period: time.Minute, Burst: rateLimitBurst})
+	if err != nil {
+		log.Fatalf("Failed to load rate limit policies: %v", err)
+	}
+	var limiterStore ratelimit.Limiter = ratelimit.NewMemoryLimiter()
+	if cacheClient.Enabled() {
+		limiterStore = ratelimit.NewFallback(ratelimit.NewRedisLimiter(cacheClient.Redis()), limiterStore)
+		log.Printf("rate limiter enabled: redis, in-memory fallback; policies %v", rateLimitPolicies.Names())
+	} else {
+		log.Printf("rate limiter enabled: in-memory (per instance); policies %v", rateLimitPolicies.Names())
+	}
+	rateLimiter := handlers.NewRateLimiter(limiterStore, rateLimitPolicies)
 
-	handler := handlers.NewHandler(apiGateway, cacheClient, rateLimiter)
-	app := handler.Init()
+	cleanCtx, cancelClean := context.WithCancel(context.Background())
+	defer cancelClean()
+
+	// Локальная проверка JWT по JWKS Auth + кэш статуса токена. TTL — сколько
+	// Gateway может не заметить отзыв, сделанный мимо него (например, reuse
+	// refresh-токена); logout и удаление через Gateway применяются сразу.
+	authCacheTTL := envInt("AUTH_CACHE_TTL_SECONDS", 30)
+	verifier := authn.NewVerifier(apiGateway.Auth, cacheClient, time.Duration(authCacheTTL)*time.Second)
+	go verifier.Run(cleanCtx)
+	log.Printf("local token verification enabled, status cache TTL %ds", authCacheTTL)
+
+	// Действия, закрытые до подтверждения email (см. handlers.VerificationPolicy).
+	verificationPolicy := handlers.ParseVerificationPolicy(envString("EMAIL_VERIFICATION_REQUIRED_FOR",
+		handlers.ActionVacancyPublish+","+handlers.ActionVacancyRespond))
+	log.Printf("email verification required for: %v", verificationPolicy.Actions())
+
+	// Политика доступа к маршрутам: роли, отношения к ресурсам и права
+	// API-ключей для каждого действия. Ошибка в политике — отказ старта.
+	policyFile := envString("POLICY_FILE", "configs/policy.yaml")
+	authz, err := policy.Load(policyFile, handlers.PolicyRoles(), policy.NewResolvers(apiGateway))
+	if err != nil {
+		log.Fatalf("Failed to load access policy: %v", err)
+	}
+	log.Printf("access policy loaded from %s: %d actions", policyFile, len(authz.Actions()))
+
+	// Куда callback входа через провайдера возвращает браузер (токены — во
+	// фрагменте URL). Пусто — callback отвечает JSON.
+	oidcFrontendURL := envString("OIDC_FRONTEND_URL", "")
+
+	// Reverse proxy перед Gateway (IP или CIDR через запятую): только их
+	// X-Forwarded-For задаёт адрес клиента для лимитов, аудита и Auth.
+	trustedProxies, err := handlers.ParseTrustedProxies(envString("TRUSTED_PROXIES", ""))
+	if err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+
+	// Состояние саг — в отдельном надёжном Redis (AOF, noeviction), не в
+	// кэше: сверка продолжает удаление и выгрузку только по записи саги.
+	sagaRedis := connectSagaRedis(envString("SAGA_REDIS_ADDR", ""))
+
+	// Регистрация — сага (аккаунт → компания → профиль) с компенсациями.
+	registrationService := registration.New(apiGateway, newSagaStore(sagaRedis, 24*time.Hour))
+
+	// Сверка регистраций: продолжает брошенные саги и создаёт недостающие
+	// профили/компании для аккаунтов Auth за последние LOOKBACK часов.
+	reconcileMinutes := envInt("REGISTRATION_RECONCILE_INTERVAL_MINUTES", 10)
+	reconcileLookbackHours := envInt("REGISTRATION_RECONCILE_LOOKBACK_HOURS", 24)
+	go registration.NewReconciler(registrationService, apiGateway,
+		time.Duration(reconcileMinutes)*time.Minute,
+		time.Duration(reconcileLookbackHours)*time.Hour).Run(cleanCtx)
+
+	// Удаление аккаунта во всех сервисах — сага без отката. Статус удаления
+	// хранится неделю после завершения: пользователь проверяет его по ссылке.
+	deletionService := deletion.New(apiGateway, newSagaStore(sagaRedis, 7*24*time.Hour), verifier)
+	deletionResumeMinutes := envInt("ACCOUNT_DELETION_RESUME_INTERVAL_MINUTES", 5)
+	go deletion.NewReconciler(deletionService, time.Duration(deletionResumeMinutes)*time.Minute).Run(cleanCtx)
+
+	// Выгрузка персональных данных. Статус хранится столько же, сколько
+	// архив в MinIO (EXPORT_RETENTION_DAYS в Achievements); ссылка на
+	// скачивание живёт DATA_EXPORT_LINK_TTL_MINUTES.
+	exportRetentionDays := envInt("DATA_EXPORT_RETENTION_DAYS", 7)
+	exportLinkMinutes := envInt("DATA_EXPORT_LINK_TTL_MINUTES", 60)
+	exportService := export.New(apiGateway, newSagaStore(sagaRedis, time.Duration(exportRetentionDays)*24*time.Hour),
+		time.Duration(exportLinkMinutes)*time.Minute)
+	exportResumeMinutes := envInt("DATA_EXPORT_RESUME_INTERVAL_MINUTES", 5)
+	go export.NewReconciler(exportService, time.Duration(exportResumeMinutes)*time.Minute).Run(cleanCtx)
 
 	// Auto-cleanup воркер: каждые CLEANUP_INTERVAL_HOURS (default 6) часов
 	// soft-удаляет closed-вакансии и completed-микрозадачи компаний согласно
 	// Company.CleanupVacanciesAfterDays / CleanupTasksAfterDays. Запускается в
 	// фоне; ctx закрывается при остановке процесса (см. waitForShutdownSignal).
+	// Администратор может запустить прогон вне расписания (/api/v1/admin/cleaner/run).
 	cleanupHours := envInt("CLEANUP_INTERVAL_HOURS", 6)
-	cleanCtx, cancelClean := context.WithCancel(context.Background())
-	defer cancelClean()
-	go cleaner.New(apiGateway, time.Duration(cleanupHours)*time.Hour).Run(cleanCtx)
+	cleanupWorker := cleaner.New(apiGateway, time.Duration(cleanupHours)*time.Hour)
+	go cleanupWorker.Run(cleanCtx)
 	log.Printf("auto-cleanup loop scheduled every %d hours", cleanupHours)
 
+	handler := handlers.NewHandler(apiGateway, cacheClient, rateLimiter, verifier, verificationPolicy, authz, registrationService, deletionService, exportService, cleanupWorker, oidcFrontendURL, trustedProxies)
+	app := handler.Init()
+	if err := handler.CheckPolicy(); err != nil {
+		log.Fatalf("Access policy does not match routes:\n%v", err)
+	}
+
 	srv := server.NewServer(app)
 	serverPort := viper.GetString("server.port")
 
@@ -145,6 +265,33 @@ func initConfig() error {
 	return viper.ReadInConfig()
 }
 
+// connectSagaRedis — Redis состояния саг. addr пустой — nil, саги в памяти
+// (локальная разработка); недоступный или ненадёжный Redis — отказ старта.
+func connectSagaRedis(addr string) *redis.Client {
+	if addr == "" {
+		log.Printf("SAGA_REDIS_ADDR not set: sagas are kept in memory and lost on restart")
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	rdb, err := saga.Connect(ctx, addr)
+	if err != nil {
+		log.Fatalf("Failed to connect saga store: %v", err)
+	}
+	tracing.InstrumentRedis(rdb)
+	log.Printf("saga store: redis at %s", addr)
+	return rdb
+}
+
+// newSagaStore — состояние саг в Redis, чтобы повторы и сверка работали на
+// всех инстансах и после перезапуска; без Redis — в памяти процесса.
+func newSagaStore(rdb *redis.Client, retention time.Duration) saga.Store {
+	if rdb != nil {
+		return saga.NewRedisStore(rdb, retention)
+	}
+	return saga.NewMemoryStore(retention)
+}
+
 // envInt reads integer env var with fallback default.
 func envInt(key string, def int) int {
 	v := os.Getenv(key)
@@ -158,6 +305,15 @@ func envInt(key string, def int) int {
 	return n
 }
 
+// envString reads string env var with fallback default. An explicitly empty
+// value is kept (e.g. to disable a list-valued setting).
+func envString(key, def string) string {
+	if v, ok := os.LookupEnv(key); ok {
+		return v
+	}
+	return def
+}
+
 func waitForShutdownSignal(srv *server.Server) {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
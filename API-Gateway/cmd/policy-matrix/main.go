@@ -0,0 +1,113 @@
+// policy-matrix строит сводную матрицу доступа «маршрут × роль» из политики
+// (configs/policy.yaml) и маршрутов Gateway и сверяет её с сохранённой.
+//
+//	go run ./cmd/policy-matrix -out configs/policy_matrix.md   # обновить
+//	go run ./cmd/policy-matrix -check configs/policy_matrix.md # проверить
+//
+// Изменение политики или маршрутов меняет матрицу: -check падает, пока она
+// не обновлена, и в ревью видно, кому какой маршрут открылся или закрылся.
+// Ту же сверку маршрутов с политикой, что и Gateway при старте, делает и
+// этот инструмент.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/studjobs/hh_for_students/api-gateway/internal/handlers"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/policy"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/services"
+)
+
+func main() {
+	policyFile := flag.String("policy", "configs/policy.yaml", "файл политики")
+	out := flag.String("out", "", "куда записать матрицу (по умолчанию stdout)")
+	check := flag.String("check", "", "сверить матрицу с файлом и завершиться с ошибкой при расхождении")
+	flag.Parse()
+
+	// Сервисы не вызываются: резолверы нужны только для проверки политики.
+	apiGateway := &services.ApiGateway{}
+	roles := handlers.PolicyRoles()
+	engine, err := policy.Load(*policyFile, roles, policy.NewResolvers(apiGateway))
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	handler := handlers.NewHandler(apiGateway, nil, nil, nil, nil, engine, nil, nil, nil, nil, "", nil)
+	handler.Init()
+	if err := handler.CheckPolicy(); err != nil {
+		log.Fatalf("policy does not match routes:\n%v", err)
+	}
+
+	matrix := render(engine, handler.Routes(), roles)
+
+	switch {
+	case *check != "":
+		want, err := os.ReadFile(*check)
+		if err != nil {
+			log.Fatalf("read %s: %v", *check, err)
+		}
+		if !bytes.Equal(want, matrix) {
+			reportDiff(string(want), string(matrix))
+			log.Fatalf("%s is out of date: run make policy-matrix and review the changes", *check)
+		}
+		log.Printf("%s is up to date", *check)
+	case *out != "":
+		if err := os.WriteFile(*out, matrix, 0o644); err != nil {
+			log.Fatalf("write %s: %v", *out, err)
+		}
+		log.Printf("policy matrix written to %s", *out)
+	default:
+		os.Stdout.Write(matrix)
+	}
+}
+
+// render — markdown-таблица: маршрут, действие, право API-ключа и что
+// политика даёт каждой роли ("allow", "deny", "superuser", "public" или
+// отношение к ресурсу вроде "owner|member").
+func render(engine *policy.Engine, routes []policy.Route, roles []string) []byte {
+	var b bytes.Buffer
+	b.WriteString("<!-- Сгенерировано: make policy-matrix. Не редактировать вручную. -->\n\n")
+	b.WriteString("| Метод | Маршрут | Действие | API-ключ | " + strings.Join(roles, " | ") + " |\n")
+	b.WriteString("|---|---|---|---|" + strings.Repeat("---|", len(roles)) + "\n")
+
+	for _, r := range routes {
+		key := engine.Scope(r.Action)
+		switch {
+		case r.Action == policy.Public:
+			key = "public"
+		case key == "":
+			key = "—"
+		}
+		cells := make([]string, 0, len(roles))
+		for _, role := range roles {
+			cells = append(cells, engine.Explain(role, r.Action))
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s |\n", r.Method, r.Path, r.Action, key, strings.Join(cells, " | "))
+	}
+	return b.Bytes()
+}
+
+// reportDiff печатает строки матрицы, которых нет в сохранённой, и наоборот.
+func reportDiff(want, got string) {
+	wantLines := make(map[string]bool)
+	for _, line := range strings.Split(want, "\n") {
+		wantLines[line] = true
+	}
+	gotLines := make(map[string]bool)
+	for _, line := range strings.Split(got, "\n") {
+		gotLines[line] = true
+		if !wantLines[line] {
+			fmt.Fprintf(os.Stderr, "+ %s\n", line)
+		}
+	}
+	for _, line := range strings.Split(want, "\n") {
+		if !gotLines[line] {
+			fmt.Fprintf(os.Stderr, "- %s\n", line)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package authn
+
+import (
+	"context"
+	"time"
+
+	"github.com/studjobs/hh_for_students/api-gateway/internal/models"
+)
+
+// ValidateAPIKey проверяет ключ интеграции в Auth. Ответ кэшируется так же,
+// как статус токена, — по хэшу ключа (Auth хранит тот же SHA-256), поэтому
+// отзыв через Gateway сбрасывает запись по хэшу из ответа Auth.
+func (v *Verifier) ValidateAPIKey(ctx context.Context, key string) (*models.APIKeyInfo, error) {
+	cacheKey := apiKeyCacheKey(tokenHash(key))
+	if e, ok := v.status.get(cacheKey); ok {
+		info := e.apiKey
+		return &info, nil
+	}
+
+	requestedAt := time.Now()
+	info, err := v.remote.ValidateAPIKey(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	// Неизвестные ключи не кэшируем: при переборе ключей каждая попытка
+	// заняла бы запись. Перебор сдерживает лимит по IP до AuthMiddleware.
+	if !info.Valid {
+		return info, nil
+	}
+	// UserUUID записи — владелец компании: удаление его аккаунта через
+	// Gateway (InvalidateUser) сбрасывает и ключи.
+	v.status.set(cacheKey, &statusEntry{
+		info:   models.TokenInfo{Valid: info.Valid, UserUUID: info.CompanyID},
+		apiKey: *info,
+	}, requestedAt, info.ExpiresAt)
+	return info, nil
+}
+
+// InvalidateAPIKey сбрасывает кэш отозванного ключа по его хэшу.
+func (v *Verifier) InvalidateAPIKey(ctx context.Context, keyHash string) {
+	key := apiKeyCacheKey(keyHash)
+	v.status.invalidateKey(key)
+	v.publish(ctx, "key:"+key)
+}
+
+func apiKeyCacheKey(keyHash string) string {
+	return "k:" + keyHash
+}
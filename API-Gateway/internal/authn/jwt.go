@@ -0,0 +1,93 @@
+package authn
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	authv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/auth/v1"
+)
+
+var (
+	// ErrInvalidToken — токен точно недействителен (подпись, формат, exp).
+	ErrInvalidToken = errors.New("invalid token")
+	// errNotLocal — токен нельзя проверить локально (не EdDSA или неизвестный
+	// kid после обновления JWKS); решение остаётся за Auth.
+	errNotLocal = errors.New("token cannot be verified locally")
+)
+
+// Claims — поля access-токена Auth, нужные Gateway.
+type Claims struct {
+	UserUUID  string      `json:"user_uuid"`
+	Role      authv1.Role `json:"role"`
+	ID        string      `json:"jti"`
+	Subject   string      `json:"sub"`
+	IssuedAt  int64       `json:"iat"`
+	ExpiresAt int64       `json:"exp"`
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// splitToken разбирает compact JWS без проверки подписи.
+func splitToken(token string) (header jwtHeader, claims Claims, signingInput string, signature []byte, err error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return header, claims, "", nil, ErrInvalidToken
+	}
+
+	rawHeader, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return header, claims, "", nil, ErrInvalidToken
+	}
+	if err := json.Unmarshal(rawHeader, &header); err != nil {
+		return header, claims, "", nil, ErrInvalidToken
+	}
+
+	rawClaims, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return header, claims, "", nil, ErrInvalidToken
+	}
+	if err := json.Unmarshal(rawClaims, &claims); err != nil {
+		return header, claims, "", nil, ErrInvalidToken
+	}
+
+	signature, err = base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return header, claims, "", nil, ErrInvalidToken
+	}
+
+	return header, claims, parts[0] + "." + parts[1], signature, nil
+}
+
+// verifyEdDSA проверяет подпись и срок действия. keyFor возвращает ключ по kid
+// или nil, если такого ключа нет.
+func verifyEdDSA(token string, keyFor func(kid string) ed25519.PublicKey) (*Claims, error) {
+	header, claims, signingInput, signature, err := splitToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if header.Alg != "EdDSA" {
+		return nil, errNotLocal
+	}
+
+	key := keyFor(header.Kid)
+	if key == nil {
+		return nil, errNotLocal
+	}
+	if !ed25519.Verify(key, []byte(signingInput), signature) {
+		return nil, ErrInvalidToken
+	}
+	if claims.ExpiresAt == 0 || time.Now().Unix() >= claims.ExpiresAt {
+		return nil, ErrInvalidToken
+	}
+	if claims.UserUUID == "" {
+		claims.UserUUID = claims.Subject
+	}
+	return &claims, nil
+}
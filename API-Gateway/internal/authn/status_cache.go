@@ -0,0 +1,154 @@
+package authn
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/studjobs/hh_for_students/api-gateway/internal/models"
+)
+
+// statusCacheMaxEntries — потолок записей: ключ записи выбирает клиент
+// (хэш токена или API-ключа), без потолка поток разных токенов растил бы
+// кэш до следующего sweep.
+const statusCacheMaxEntries = 100_000
+
+// statusEntry — закэшированный ответ Auth.ParseToken (или ValidateApiKey —
+// тогда заполнен и apiKey).
+type statusEntry struct {
+	key      string
+	info     models.TokenInfo
+	apiKey   models.APIKeyInfo
+	storedAt time.Time
+	expires  time.Time
+}
+
+// statusCache — in-memory кэш результатов проверки токенов в Auth.
+//
+// Подпись Gateway проверяет сам, а вот «пользователь не удалён» и «токен не
+// отозван» знает только Auth. Эти ответы держим коротко (ttl): за это время
+// отзыв, сделанный мимо Gateway, может быть не замечен — это осознанный
+// trade-off ради снятия нагрузки с Auth. Отзывы, прошедшие через Gateway,
+// применяются сразу (invalidateToken / invalidateUser).
+//
+// Записей не больше maxEntries: при переполнении вытесняется та, к которой
+// дольше всех не обращались (LRU).
+type statusCache struct {
+	ttl        time.Duration
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	// lru — записи от недавно использованных к давно не использованным.
+	lru *list.List
+	// userInvalidated — когда пользователя последний раз инвалидировали.
+	// Ответ, полученный от Auth раньше этого момента, считается устаревшим:
+	// так in-flight запрос не вернёт в кэш только что отозванную сессию.
+	userInvalidated map[string]time.Time
+}
+
+func newStatusCache(ttl time.Duration, maxEntries int) *statusCache {
+	sc := &statusCache{
+		ttl:             ttl,
+		maxEntries:      maxEntries,
+		entries:         make(map[string]*list.Element),
+		lru:             list.New(),
+		userInvalidated: make(map[string]time.Time),
+	}
+	go sc.sweep()
+	return sc
+}
+
+func (sc *statusCache) get(key string) (*statusEntry, bool) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	el, ok := sc.entries[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*statusEntry)
+	if time.Now().After(e.expires) {
+		sc.remove(el)
+		return nil, false
+	}
+	if at, ok := sc.userInvalidated[e.info.UserUUID]; ok && e.info.UserUUID != "" && !e.storedAt.After(at) {
+		sc.remove(el)
+		return nil, false
+	}
+	sc.lru.MoveToFront(el)
+	return e, true
+}
+
+// set кладёт ответ Auth. requestedAt — момент отправки запроса в Auth;
+// tokenExp — exp самого токена (дольше него держать запись бессмысленно).
+func (sc *statusCache) set(key string, e *statusEntry, requestedAt, tokenExp time.Time) {
+	expires := requestedAt.Add(sc.ttl)
+	if !tokenExp.IsZero() && tokenExp.Before(expires) {
+		expires = tokenExp
+	}
+	e.key = key
+	e.storedAt = requestedAt
+	e.expires = expires
+
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if at, ok := sc.userInvalidated[e.info.UserUUID]; ok && e.info.UserUUID != "" && !requestedAt.After(at) {
+		return
+	}
+	if el, ok := sc.entries[key]; ok {
+		el.Value = e
+		sc.lru.MoveToFront(el)
+		return
+	}
+	for sc.lru.Len() >= sc.maxEntries {
+		sc.remove(sc.lru.Back())
+	}
+	sc.entries[key] = sc.lru.PushFront(e)
+}
+
+func (sc *statusCache) invalidateKey(key string) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if el, ok := sc.entries[key]; ok {
+		sc.remove(el)
+	}
+}
+
+func (sc *statusCache) invalidateUser(userUUID string) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.userInvalidated[userUUID] = time.Now()
+	for _, el := range sc.entries {
+		if el.Value.(*statusEntry).info.UserUUID == userUUID {
+			sc.remove(el)
+		}
+	}
+}
+
+// remove удаляет запись; вызывается под sc.mu.
+func (sc *statusCache) remove(el *list.Element) {
+	sc.lru.Remove(el)
+	delete(sc.entries, el.Value.(*statusEntry).key)
+}
+
+// sweep периодически чистит истёкшие записи, чтобы не было утечки.
+func (sc *statusCache) sweep() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for range ticker.C {
+		now := time.Now()
+		sc.mu.Lock()
+		for _, el := range sc.entries {
+			if now.After(el.Value.(*statusEntry).expires) {
+				sc.remove(el)
+			}
+		}
+		for u, at := range sc.userInvalidated {
+			if now.Sub(at) > sc.ttl {
+				delete(sc.userInvalidated, u)
+			}
+		}
+		sc.mu.Unlock()
+	}
+}
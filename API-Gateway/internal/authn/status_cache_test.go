@@ -0,0 +1,32 @@
+package authn
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/studjobs/hh_for_students/api-gateway/internal/models"
+)
+
+func TestStatusCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	sc := newStatusCache(time.Minute, 3)
+
+	now := time.Now()
+	for i := range 3 {
+		sc.set(fmt.Sprintf("t:%d", i), &statusEntry{info: models.TokenInfo{Valid: true}}, now, time.Time{})
+	}
+	// t:0 прочитан — вытесняется следующий по давности, t:1.
+	if _, ok := sc.get("t:0"); !ok {
+		t.Fatal("t:0 missing before overflow")
+	}
+	sc.set("t:3", &statusEntry{info: models.TokenInfo{Valid: true}}, now, time.Time{})
+
+	for key, want := range map[string]bool{"t:0": true, "t:1": false, "t:2": true, "t:3": true} {
+		if _, ok := sc.get(key); ok != want {
+			t.Errorf("get(%s) = %t, want %t", key, ok, want)
+		}
+	}
+	if n := sc.lru.Len(); n != 3 || len(sc.entries) != 3 {
+		t.Fatalf("cache holds %d (lru %d) entries, want 3", len(sc.entries), n)
+	}
+}
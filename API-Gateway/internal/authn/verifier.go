@@ -0,0 +1,234 @@
+// Package authn — проверка access-токенов на стороне Gateway.
+//
+// Раньше каждый запрос ходил в Auth.ParseToken: проверка подписи + два запроса
+// в Postgres. Теперь:
+//  1. подпись и exp проверяются локально по JWKS, который Auth публикует
+//     (ключи Ed25519, см. Auth/internal/service/keys.go) — поддельный или
+//     протухший токен отбивается без обращения к Auth;
+//  2. статус «пользователь жив, токен не отозван» берётся у Auth и кэшируется
+//     на короткий TTL по jti;
+//  3. logout и удаление аккаунта, прошедшие через Gateway, сразу чистят кэш,
+//     а через Redis pub/sub — и кэши соседних инстансов.
+//
+// Токены, которые локально проверить нельзя (HS256 переходного периода,
+// неизвестный kid), уходят в Auth как раньше — тоже через кэш.
+package authn
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/studjobs/hh_for_students/api-gateway/internal/cache"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/models"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/services"
+)
+
+const (
+	// invalidationChannel — Redis-канал событий отзыва между инстансами Gateway.
+	invalidationChannel = "gw:auth:invalidate"
+	// jwksRefreshInterval — плановое обновление JWKS. Auth публикует новый ключ
+	// заранее (JWT_KEY_PROPAGATION_MINUTES), интервал должен быть меньше.
+	jwksRefreshInterval = 5 * time.Minute
+	// jwksMinRefetch — не чаще этого дёргаем Auth на неизвестный kid, чтобы
+	// мусорные токены не превратились в DoS на GetJWKS.
+	jwksMinRefetch = 30 * time.Second
+)
+
+// KeySource — откуда брать JWKS (обычно services.AuthService).
+type KeySource interface {
+	GetJWKS(ctx context.Context) ([]models.JWK, error)
+}
+
+type Verifier struct {
+	remote services.AuthService
+	keys   KeySource
+	status *statusCache
+	bus    *cache.Client
+
+	mu        sync.RWMutex
+	jwks      map[string]ed25519.PublicKey
+	fetchedAt time.Time
+}
+
+// NewVerifier создаёт верификатор. bus может быть nil/no-op — тогда события
+// отзыва не рассылаются другим инстансам.
+func NewVerifier(auth services.AuthService, bus *cache.Client, statusTTL time.Duration) *Verifier {
+	return &Verifier{
+		remote: auth,
+		keys:   auth,
+		status: newStatusCache(statusTTL, statusCacheMaxEntries),
+		bus:    bus,
+		jwks:   make(map[string]ed25519.PublicKey),
+	}
+}
+
+// Run обновляет JWKS по расписанию и слушает события отзыва (блокирующий —
+// вызывать в goroutine).
+func (v *Verifier) Run(ctx context.Context) {
+	if err := v.refreshKeys(ctx); err != nil {
+		log.Printf("authn: initial JWKS fetch failed: %v (falling back to Auth)", err)
+	}
+
+	var events <-chan string
+	if v.bus.Enabled() {
+		events = v.bus.Subscribe(ctx, invalidationChannel)
+	}
+
+	t := time.NewTicker(jwksRefreshInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if err := v.refreshKeys(ctx); err != nil {
+				log.Printf("authn: JWKS refresh failed: %v", err)
+			}
+		case msg, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			v.applyEvent(msg)
+		}
+	}
+}
+
+// ValidateToken — замена services.AuthService.ValidateToken с той же сигнатурой.
+func (v *Verifier) ValidateToken(ctx context.Context, token string) (*models.TokenInfo, error) {
+	claims, err := verifyEdDSA(token, v.keyFor)
+	if errors.Is(err, errNotLocal) && v.refetchAllowed() {
+		// Возможно, Auth только что выпустил ключ — обновим JWKS один раз.
+		if rerr := v.refreshKeys(ctx); rerr == nil {
+			claims, err = verifyEdDSA(token, v.keyFor)
+		}
+	}
+	if errors.Is(err, ErrInvalidToken) {
+		return &models.TokenInfo{Valid: false}, nil
+	}
+
+	// По jti адресуем только локально проверенные токены: иначе поддельный
+	// токен с чужим jti попал бы в чужую запись кэша.
+	key := "t:" + tokenHash(token)
+	var tokenExp time.Time
+	if err == nil {
+		tokenExp = time.Unix(claims.ExpiresAt, 0)
+		if claims.ID != "" {
+			key = "j:" + claims.ID
+		}
+	}
+
+	if e, ok := v.status.get(key); ok {
+		info := e.info
+		return &info, nil
+	}
+
+	requestedAt := time.Now()
+	info, err := v.remote.ValidateToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if info.Valid && claims != nil && claims.UserUUID != info.UserUUID {
+		log.Printf("authn: Auth returned user %s for token of %s", info.UserUUID, claims.UserUUID)
+		return &models.TokenInfo{Valid: false}, nil
+	}
+	// Отказ кэшируем только для токена с нашей подписью (отозванный jti).
+	// Мусорные токены с неизвестным kid или чужим alg каждый раз разные —
+	// их записи лишь вытесняли бы из кэша живые сессии.
+	if info.Valid || claims != nil {
+		v.status.set(key, &statusEntry{info: *info}, requestedAt, tokenExp)
+	}
+	return info, nil
+}
+
+// InvalidateToken сбрасывает кэш для конкретного токена (logout одной сессии).
+func (v *Verifier) InvalidateToken(ctx context.Context, token string) {
+	keys := []string{"t:" + tokenHash(token)}
+	if _, claims, _, _, err := splitToken(token); err == nil && claims.ID != "" {
+		keys = append(keys, "j:"+claims.ID)
+	}
+	for _, key := range keys {
+		v.status.invalidateKey(key)
+		v.publish(ctx, "key:"+key)
+	}
+}
+
+// InvalidateUser сбрасывает кэш всех токенов пользователя (logout-all, удаление).
+func (v *Verifier) InvalidateUser(ctx context.Context, userUUID string) {
+	v.status.invalidateUser(userUUID)
+	v.publish(ctx, "user:"+userUUID)
+}
+
+func (v *Verifier) publish(ctx context.Context, msg string) {
+	if !v.bus.Enabled() {
+		return
+	}
+	if err := v.bus.Publish(ctx, invalidationChannel, msg); err != nil {
+		log.Printf("authn: failed to publish invalidation %q: %v", msg, err)
+	}
+}
+
+func (v *Verifier) applyEvent(msg string) {
+	switch {
+	case strings.HasPrefix(msg, "key:"):
+		v.status.invalidateKey(strings.TrimPrefix(msg, "key:"))
+	case strings.HasPrefix(msg, "user:"):
+		v.status.invalidateUser(strings.TrimPrefix(msg, "user:"))
+	default:
+		log.Printf("authn: unknown invalidation event %q", msg)
+	}
+}
+
+func (v *Verifier) keyFor(kid string) ed25519.PublicKey {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.jwks[kid]
+}
+
+func (v *Verifier) refetchAllowed() bool {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return time.Since(v.fetchedAt) > jwksMinRefetch
+}
+
+func (v *Verifier) refreshKeys(ctx context.Context) error {
+	v.mu.Lock()
+	v.fetchedAt = time.Now()
+	v.mu.Unlock()
+
+	jwks, err := v.keys.GetJWKS(ctx)
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[string]ed25519.PublicKey, len(jwks))
+	for _, k := range jwks {
+		if k.Kty != "OKP" || k.Crv != "Ed25519" {
+			continue
+		}
+		raw, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(raw) != ed25519.PublicKeySize {
+			log.Printf("authn: skipping malformed JWK %s", k.Kid)
+			continue
+		}
+		keys[k.Kid] = ed25519.PublicKey(raw)
+	}
+
+	v.mu.Lock()
+	v.jwks = keys
+	v.mu.Unlock()
+	return nil
+}
+
+func tokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -1,16 +1,26 @@
 // Package cache реализует cache-aside поверх Redis для GET-эндпоинтов API-Gateway.
 //
-// Whitelist-подход: кэшируем только маршруты, явно перечисленные в Cacheable.
+// Whitelist-подход: кэшируем только маршруты, явно перечисленные в rules.
 // Это безопаснее «кэшировать всё подряд» — owner-specific эндпоинты (`/users/me`,
 // `/user/achievements/`, `/expert/queue`, `/tasks/my-submissions`) сознательно
-// исключены, чтобы пользователи не получали данные друг друга.
+// исключены, чтобы пользователи не получали данные друг друга. Ответы, которые
+// зависят от роли или компании запрашивающего, кэшируются отдельно для каждого
+// значения (Rule.Vary), а ресурсы самого пользователя не кэшируются вовсе.
 //
-// Инвалидация — по prefix-pattern: при POST/PATCH/DELETE на `/api/v1/<resource>`
-// чистим все ключи `gw:GET:/api/v1/<resource>*` через `SCAN + UNLINK`.
+// Инвалидация — по тегам: запись помечается сущностями, которые в ней есть
+// (`vacancy:<id>`, `company:<id>`, листинги — `vacancy:list`), ключи записей
+// тега лежат в Redis-множестве `gw:tag:<tag>`. Успешная запись (POST/PATCH/
+// DELETE) чистит теги, которые она затронула (см. writeRules), — без обхода
+// keyspace через SCAN и без ручной карты «префикс записи → префиксы чтения».
 //
-// Trade-off: SCAN+UNLINK не атомарен и пройдёт по всем ключам, но 256 MB Redis
-// с allkeys-lru держит ~50-100k ключей — обход за <100 мс на типичной нагрузке.
-// Альтернатива (теги через MGET по indirect-keys) сложнее и не нужна на демо.
+// Trade-off: под allkeys-lru Redis может вытеснить множество тега раньше
+// записей — тогда они доживут до TTL. Тот же риск был у SCAN-подхода при
+// гонке чтения с записью, и TTL его ограничивает.
+//
+// Истечение популярной записи не должно бить в сервис всеми одновременными
+// промахами: промахи по одному ключу схлопываются (Coalesce), а запись после
+// мягкого TTL ещё отдаётся, пока один фоновый запрос её обновляет
+// (ClaimRefresh, TTLPolicy).
 package cache
 
 import (
@@ -24,7 +34,8 @@ import (
 )
 
 const (
-	keyPrefix = "gw:GET:"
+	keyPrefix     = "gw:GET:"
+	refreshPrefix = "gw:refresh:"
 )
 
 // Entry — то, что кладём в Redis. Тело JSON + content-type + status code.
@@ -33,16 +44,29 @@ type Entry struct {
 	ContentType string            `json:"ct"`
 	Body        []byte            `json:"b"`
 	Headers     map[string]string `json:"h,omitempty"`
+	// ETag — сильный валидатор тела; считается при записи, чтобы HIT не
+	// хэшировал тело заново.
+	ETag string `json:"e,omitempty"`
+	// FreshUntil — до какого момента (unix ms) запись свежая; после — её
+	// отдают как устаревшую до жёсткого TTL (см. TTLPolicy). 0 — свежая
+	// всё время жизни.
+	FreshUntil int64 `json:"f,omitempty"`
+}
+
+// Fresh — запись ещё не устарела.
+func (e *Entry) Fresh(now time.Time) bool {
+	return e.FreshUntil == 0 || now.UnixMilli() < e.FreshUntil
 }
 
 type Client struct {
-	rdb *redis.Client
-	ttl time.Duration
+	rdb    *redis.Client
+	ttl    TTLPolicy
+	flight flight
 }
 
 // New возвращает клиент. addr пустой — отключённый клиент (no-op), это позволяет
 // gateway работать без Redis для локального dev.
-func New(addr string, ttl time.Duration) *Client {
+func New(addr string, ttl TTLPolicy) *Client {
 	if addr == "" {
 		return &Client{}
 	}
@@ -59,6 +83,15 @@ func New(addr string, ttl time.Duration) *Client {
 // Enabled true если есть рабочий клиент.
 func (c *Client) Enabled() bool { return c != nil && c.rdb != nil }
 
+// Redis — клиент для хранилищ поверх того же Redis (состояние саг). nil,
+// если кэш отключён.
+func (c *Client) Redis() *redis.Client {
+	if !c.Enabled() {
+		return nil
+	}
+	return c.rdb
+}
+
 // Ping — health-check. Используется при старте gateway, чтобы не выйти из строя
 // при недоступном Redis (просто отключаем кэш и логируем warn).
 func (c *Client) Ping(ctx context.Context) error {
@@ -70,7 +103,8 @@ func (c *Client) Ping(ctx context.Context) error {
 
 // Key возвращает ключ для GET-запроса с учётом query-string.
 // Auth-зависимые маршруты ДОЛЖНЫ быть исключены на уровне whitelist —
-// сюда попадают только публично-кэшируемые URL'ы.
+// сюда попадают только публично-кэшируемые URL'ы. Ответы, зависящие от
+// запрашивающего, получают ключ через Rule.Key.
 func Key(path, rawQuery string) string {
 	if rawQuery == "" {
 		return keyPrefix + path
@@ -94,84 +128,120 @@ func (c *Client) Get(ctx context.Context, key string) *Entry {
 	return &e
 }
 
-// Set кладёт Entry с TTL. Ошибки игнорируем — cache не критичен.
-func (c *Client) Set(ctx context.Context, key string, e *Entry) {
+// Set кладёт Entry ответа на path с TTL маршрута (TTLPolicy.For) и добавляет
+// key в множества его тегов (Tag). Множество живёт не меньше самой долгой
+// записи в нём; ключи истёкших записей в нём безвредны — их UNLINK ничего не
+// удалит. Ошибки игнорируем — cache не критичен.
+func (c *Client) Set(ctx context.Context, path, key string, e *Entry, tags []string) {
 	if !c.Enabled() {
 		return
 	}
+	soft, hard := c.ttl.For(path)
+	if soft < hard {
+		e.FreshUntil = time.Now().Add(soft).UnixMilli()
+	}
 	raw, err := json.Marshal(e)
 	if err != nil {
 		return
 	}
-	_ = c.rdb.Set(ctx, key, raw, c.ttl).Err()
+	pipe := c.rdb.TxPipeline()
+	pipe.Set(ctx, key, raw, hard)
+	for _, tag := range tags {
+		pipe.SAdd(ctx, tagKeyPrefix+tag, key)
+		pipe.Expire(ctx, tagKeyPrefix+tag, c.ttl.Max())
+	}
+	_, _ = pipe.Exec(ctx)
 }
 
-// InvalidatePrefix чистит все ключи с указанным префиксом через SCAN.
-// Используется при write-операциях (POST/PATCH/DELETE) на ресурс.
-//
-// `resource` ожидается без `gw:GET:` — функция добавляет его сама. Пример:
-// `InvalidatePrefix(ctx, "/api/v1/tasks")` вычистит `/api/v1/tasks`,
-// `/api/v1/tasks?status=open`, `/api/v1/tasks/abc-123` и т.д.
-func (c *Client) InvalidatePrefix(ctx context.Context, resource string) {
+// ClaimRefresh — право обновить устаревшую запись key. Достаётся одному
+// запросу на все инстансы gateway на время lease; не удалось обновить —
+// следующая попытка после истечения lease. Redis недоступен — false.
+func (c *Client) ClaimRefresh(ctx context.Context, key string, lease time.Duration) bool {
 	if !c.Enabled() {
+		return false
+	}
+	ok, err := c.rdb.SetNX(ctx, refreshPrefix+strings.TrimPrefix(key, keyPrefix), 1, lease).Result()
+	return err == nil && ok
+}
+
+// Invalidate удаляет все записи с любым из тегов и сами множества тегов.
+// Стоимость — размер множеств, а не всего keyspace, как у SCAN.
+func (c *Client) Invalidate(ctx context.Context, tags []string) {
+	if !c.Enabled() || len(tags) == 0 {
 		return
 	}
-	pattern := keyPrefix + resource + "*"
-	iter := c.rdb.Scan(ctx, 0, pattern, 100).Iterator()
-	var batch []string
-	for iter.Next(ctx) {
-		batch = append(batch, iter.Val())
-		if len(batch) >= 100 {
-			_ = c.rdb.Unlink(ctx, batch...).Err()
-			batch = batch[:0]
-		}
+	pipe := c.rdb.Pipeline()
+	members := make([]*redis.StringSliceCmd, 0, len(tags))
+	for _, tag := range tags {
+		members = append(members, pipe.SMembers(ctx, tagKeyPrefix+tag))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return
+	}
+	keys := make([]string, 0, len(tags))
+	for i, tag := range tags {
+		keys = append(keys, tagKeyPrefix+tag)
+		keys = append(keys, members[i].Val()...)
 	}
-	if len(batch) > 0 {
-		_ = c.rdb.Unlink(ctx, batch...).Err()
+	for start := 0; start < len(keys); start += 100 {
+		end := min(start+100, len(keys))
+		_ = c.rdb.Unlink(ctx, keys[start:end]...).Err()
 	}
 }
 
-// IsCacheableRoute возвращает true для GET-маршрутов, которые безопасно кэшировать
-// (не зависят от авторизованного пользователя).
-//
-// Маршруты с :id (`/api/v1/users/abc-123`) тоже кэшируемые — они read-only и
-// одинаковы для всех. Owner-specific (`/users/me`, `/user/achievements/`) — нет.
-func IsCacheableRoute(path string) bool {
-	for _, allow := range allowedPrefixes {
-		if strings.HasPrefix(path, allow) {
-			return true
-		}
+// Publish отправляет сообщение в Redis pub/sub-канал. Используется для
+// рассылки событий между инстансами Gateway (например, отзыв токенов).
+func (c *Client) Publish(ctx context.Context, channel, msg string) error {
+	if !c.Enabled() {
+		return fmt.Errorf("redis client not configured")
 	}
-	return false
+	return c.rdb.Publish(ctx, channel, msg).Err()
 }
 
-// InvalidationsForWrite возвращает префиксы, которые нужно почистить при
-// write-операции на данный путь. Например, POST `/api/v1/hr/tasks/` чистит
-// `/api/v1/tasks` (студенты сразу видят новую задачу) и `/api/v1/hr/tasks` (HR
-// видит свою же).
-func InvalidationsForWrite(path string) []string {
-	var out []string
-	for prefix, related := range writeInvalidations {
-		if strings.HasPrefix(path, prefix) {
-			out = append(out, related...)
-		}
+// Subscribe подписывается на канал и возвращает поток сообщений. Канал
+// закрывается вместе с ctx. Для отключённого клиента возвращает nil.
+func (c *Client) Subscribe(ctx context.Context, channel string) <-chan string {
+	if !c.Enabled() {
+		return nil
 	}
+	sub := c.rdb.Subscribe(ctx, channel)
+	out := make(chan string)
+	go func() {
+		defer close(out)
+		defer sub.Close()
+		ch := sub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-ch:
+				if !ok {
+					return
+				}
+				select {
+				case out <- msg.Payload:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
 	return out
 }
 
-// allowedPrefixes — публичные read-only ресурсы.
+// IsCacheableRoute возвращает true для GET-маршрутов из whitelist (rules).
+// Исключения внутри них — ShouldExclude; RuleFor учитывает оба.
 //
-// Намеренно НЕ кэшируем: /users/me, /user/achievements/, /expert/queue,
-// /tasks/my-submissions, /hr/me, /hr/tasks, /hr/vacancy, /company/me — все они
-// owner-specific и требуют авторизованного контекста.
-var allowedPrefixes = []string{
-	"/api/v1/skills/popular",
-	"/api/v1/skills/search",
-	"/api/v1/skills/bulk",
-	"/api/v1/users",   // SUFFIX-проверка: исключаем /users/me, см. ShouldExclude
-	"/api/v1/vacancy", // только GET-листинги, write-маршруты в /hr/vacancy
-	"/api/v1/tasks",   // /tasks/my-submissions исключаем через ShouldExclude
-	"/api/v1/company",
+// Маршруты с :id (`/api/v1/users/abc-123`) тоже кэшируемые — они read-only,
+// а различия по роли и компании описывает Rule.Vary. Owner-specific
+// (`/users/me`, `/user/achievements/`) — нет.
+func IsCacheableRoute(path string) bool {
+	for _, rule := range rules {
+		if hasPathPrefix(path, rule.Prefix) {
+			return true
+		}
+	}
+	return false
 }
 
 // ShouldExclude — финальный фильтр. Маршруты в whitelist могут содержать
@@ -189,6 +259,9 @@ func ShouldExclude(path string) bool {
 		"/api/v1/company/me",
 		"/api/v1/company/membership/",
 		"/api/v1/company/members",
+		"/api/v1/company/audit",
+		// Учётные данные интеграций не кэшируются ни под каким ключом.
+		"/api/v1/company/api-keys",
 	}
 	for _, ex := range exclusions {
 		if strings.HasPrefix(path, ex) {
@@ -197,12 +270,3 @@ func ShouldExclude(path string) bool {
 	}
 	return false
 }
-
-// writeInvalidations — карта prefix → список префиксов для чистки.
-// Написана так, чтобы read-маршруты увидели свежие данные после write.
-var writeInvalidations = map[string][]string{
-	"/api/v1/hr/vacancy": {"/api/v1/vacancy"},
-	"/api/v1/hr/tasks":   {"/api/v1/tasks"},
-	"/api/v1/users/edit": {"/api/v1/users"},
-	"/api/v1/company":    {"/api/v1/company"},
-}
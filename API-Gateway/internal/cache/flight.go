@@ -0,0 +1,45 @@
+package cache
+
+import "sync"
+
+// flight — запросы в полёте по ключу записи (singleflight): одновременные
+// промахи по одному ключу ждут первый вместо того, чтобы каждый идти в
+// сервис. Действует в пределах одного инстанса gateway.
+type flight struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+type flightCall struct {
+	done  chan struct{}
+	entry *Entry
+}
+
+// Coalesce выполняет fn один раз среди одновременных вызовов с тем же key;
+// остальные ждут и получают её результат с shared=true. nil — у первого
+// вызова не вышло кэшируемого ответа.
+func (c *Client) Coalesce(key string, fn func() *Entry) (entry *Entry, shared bool) {
+	f := &c.flight
+	f.mu.Lock()
+	if call, ok := f.calls[key]; ok {
+		f.mu.Unlock()
+		<-call.done
+		return call.entry, true
+	}
+	if f.calls == nil {
+		f.calls = make(map[string]*flightCall)
+	}
+	call := &flightCall{done: make(chan struct{})}
+	f.calls[key] = call
+	f.mu.Unlock()
+
+	// Ждущих отпускаем и при панике в fn.
+	defer func() {
+		f.mu.Lock()
+		delete(f.calls, key)
+		f.mu.Unlock()
+		close(call.done)
+	}()
+	call.entry = fn()
+	return call.entry, false
+}
@@ -0,0 +1,199 @@
+package cache
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+const tagKeyPrefix = "gw:tag:"
+
+// listID — id тега «списки сущности»: листинги меняют состав при создании,
+// удалении и смене видимости любой из сущностей, а не только тех, что в них есть.
+const listID = "list"
+
+// Tag — метка записи кэша: сущность kind с идентификатором id
+// (vacancy:<id>, company:<id>). Запись помечена всеми сущностями, которые
+// в ней есть; запись сущности чистит все записи с её тегом.
+func Tag(kind, id string) string {
+	return kind + ":" + id
+}
+
+// ListTag — метка листингов сущности kind (vacancy:list).
+func ListTag(kind string) string {
+	return Tag(kind, listID)
+}
+
+// idFields — поля JSON со ссылками на сущности: вакансия в списке помечается
+// и своей компанией, отклик — вакансией и студентом.
+var idFields = map[string]string{
+	"vacancy_id": "vacancy",
+	"company_id": "company",
+	"task_id":    "task",
+	"user_id":    "user",
+	"student_id": "user",
+}
+
+// Tags — теги записи GET-ответа path с телом body: листинг сущности,
+// id из пути (/api/v1/vacancy/<id>...) и все сущности из тела. В ответе
+// под-ресурса (/users/<id>/achievements) "id" — не id сущности Kind, из тела
+// берутся только поля-ссылки.
+func (r *Rule) Tags(path string, body []byte) []string {
+	if r.Kind == "" {
+		return nil
+	}
+	t := newTagSet()
+	kind := r.Kind
+	rest := strings.Trim(path[len(r.Prefix):], "/")
+	if rest == "" {
+		t.add(ListTag(r.Kind))
+	} else {
+		segments := strings.SplitN(rest, "/", 2)
+		t.add(Tag(r.Kind, segments[0]))
+		if len(segments) > 1 {
+			kind = ""
+		}
+	}
+	t.body(kind, body)
+	return t.list()
+}
+
+// WriteRule — какие записи кэша устаревают после успешной записи по
+// префиксу Prefix.
+type WriteRule struct {
+	Prefix string
+	// Kind — сущность маршрута: её id — параметр :id пути или поле "id"
+	// ответа. Пусто — запись не видна в кэшируемых ответах.
+	Kind string
+	// Self — сущность — сам автор запроса (свой профиль; компания владельца,
+	// чей uuid — её id).
+	Self bool
+	// Lists — сущности, у которых запись может поменять состав листингов:
+	// создание, удаление, модерация, скрытие.
+	Lists []string
+}
+
+// writeRules — write-маршруты и их влияние на кэш. Проверяются по порядку,
+// выигрывает первое совпадение; маршрут без правила кэш не трогает.
+var writeRules = []WriteRule{
+	{Prefix: "/api/v1/hr/vacancy", Kind: "vacancy", Lists: []string{"vacancy"}},
+	// Решение по отклику: вакансия и студент — из ответа.
+	{Prefix: "/api/v1/hr/applications", Kind: "application"},
+	{Prefix: "/api/v1/hr/tasks/submissions", Kind: "submission"},
+	{Prefix: "/api/v1/hr/tasks", Kind: "task", Lists: []string{"task"}},
+	// Профиль HR: /hr/edit, удаление аккаунта.
+	{Prefix: "/api/v1/hr", Kind: "user", Self: true, Lists: []string{"user"}},
+	// Отклики и вложения: /vacancy/:id/...
+	{Prefix: "/api/v1/vacancy", Kind: "vacancy"},
+	{Prefix: "/api/v1/user/applications", Kind: "application"},
+	// Достижения видны в /users/:id/achievements.
+	{Prefix: "/api/v1/user/achievements", Kind: "achievement", Self: true},
+	{Prefix: "/api/v1/expert/achievements", Kind: "achievement"},
+	// Профиль, файлы, удаление аккаунта; is_hidden меняет состав листингов.
+	{Prefix: "/api/v1/users", Kind: "user", Self: true, Lists: []string{"user"}},
+	{Prefix: "/api/v1/tasks", Kind: "task"},
+	// Ключи не кэшируются (ShouldExclude) — и компанию их выпуск не меняет.
+	{Prefix: "/api/v1/company/api-keys"},
+	// Решение по membership: компания — из ответа.
+	{Prefix: "/api/v1/company/membership", Kind: "membership"},
+	{Prefix: "/api/v1/company", Kind: "company", Self: true, Lists: []string{"company"}},
+	{Prefix: "/api/v1/admin/vacancies", Kind: "vacancy", Lists: []string{"vacancy"}},
+	{Prefix: "/api/v1/admin/companies", Kind: "company", Lists: []string{"company"}},
+	{Prefix: "/api/v1/admin/users", Kind: "user", Lists: []string{"user"}},
+}
+
+// Write — успешная запись, для которой ищем устаревшие теги.
+type Write struct {
+	Path string
+	// ID — параметр :id маршрута; пусто, если его нет.
+	ID     string
+	UserID string
+	// Body — тело ответа: созданная или изменённая сущность.
+	Body []byte
+}
+
+// TagsForWrite — теги, которые нужно инвалидировать после записи w.
+func TagsForWrite(w Write) []string {
+	for i := range writeRules {
+		r := &writeRules[i]
+		if !hasPathPrefix(w.Path, r.Prefix) {
+			continue
+		}
+		t := newTagSet()
+		for _, kind := range r.Lists {
+			t.add(ListTag(kind))
+		}
+		if r.Kind != "" {
+			if w.ID != "" {
+				t.add(Tag(r.Kind, w.ID))
+			}
+			if r.Self && w.UserID != "" {
+				t.add(Tag(r.Kind, w.UserID))
+			}
+			t.body(r.Kind, w.Body)
+		}
+		return t.list()
+	}
+	return nil
+}
+
+// tagSet — теги без повторов, в порядке добавления.
+type tagSet struct {
+	seen map[string]struct{}
+	out  []string
+}
+
+func newTagSet() *tagSet {
+	return &tagSet{seen: make(map[string]struct{})}
+}
+
+func (t *tagSet) add(tag string) {
+	if _, ok := t.seen[tag]; ok {
+		return
+	}
+	t.seen[tag] = struct{}{}
+	t.out = append(t.out, tag)
+}
+
+func (t *tagSet) list() []string {
+	return t.out
+}
+
+// body собирает теги из JSON-ответа. "id" принадлежит сущности kind только
+// у корневого объекта и элементов списков верхнего уровня
+// ({"vacancies": [...]}): у вложенных объектов (вложения, тип компании)
+// это id чего-то другого. Поля-ссылки (idFields) учитываются везде.
+func (t *tagSet) body(kind string, body []byte) {
+	if len(body) == 0 {
+		return
+	}
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return
+	}
+	t.walk(kind, v, true, 0)
+}
+
+func (t *tagSet) walk(kind string, v any, entity bool, depth int) {
+	switch x := v.(type) {
+	case map[string]any:
+		for field, value := range x {
+			if id, ok := value.(string); ok {
+				if id == "" {
+					continue
+				}
+				if field == "id" && entity && kind != "" {
+					t.add(Tag(kind, id))
+				} else if ref, ok := idFields[field]; ok {
+					t.add(Tag(ref, id))
+				}
+				continue
+			}
+			_, list := value.([]any)
+			t.walk(kind, value, depth == 0 && list, depth+1)
+		}
+	case []any:
+		for _, item := range x {
+			t.walk(kind, item, entity, depth+1)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RouteTTL — жёсткий TTL записей маршрутов с префиксом Prefix.
+type RouteTTL struct {
+	Prefix string
+	TTL    time.Duration
+}
+
+// TTLPolicy — сроки жизни записей. Жёсткий TTL — сколько запись лежит в
+// Redis; первые SoftPercent процентов от него запись свежая (HIT), дальше —
+// устаревшая: её ещё отдают, пока один фоновый запрос обновляет её
+// (stale-while-revalidate).
+type TTLPolicy struct {
+	// Default — жёсткий TTL маршрутов без своего в Routes.
+	Default time.Duration
+	Routes  []RouteTTL
+	// SoftPercent — доля жёсткого TTL, в течение которой запись свежая;
+	// 0 или 100 и больше — запись свежая всё время жизни.
+	SoftPercent int
+}
+
+// ParseRouteTTLs разбирает "prefix=duration" через запятую
+// ("/api/v1/skills=10m,/api/v1/vacancy=30s"); "" — своих TTL нет.
+func ParseRouteTTLs(spec string) ([]RouteTTL, error) {
+	var out []RouteTTL
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		prefix, raw, ok := strings.Cut(item, "=")
+		prefix = strings.TrimRight(strings.TrimSpace(prefix), "/")
+		if !ok || !strings.HasPrefix(prefix, "/") {
+			return nil, fmt.Errorf("cache ttl %q: want /prefix=duration", item)
+		}
+		ttl, err := time.ParseDuration(strings.TrimSpace(raw))
+		if err != nil || ttl <= 0 {
+			return nil, fmt.Errorf("cache ttl %q: invalid duration %q", item, raw)
+		}
+		out = append(out, RouteTTL{Prefix: prefix, TTL: ttl})
+	}
+	return out, nil
+}
+
+// For — свежесть (soft) и жёсткий TTL (hard) записи пути. Из нескольких
+// подходящих префиксов выигрывает самый длинный.
+func (p TTLPolicy) For(path string) (soft, hard time.Duration) {
+	hard = p.Default
+	matched := -1
+	for _, r := range p.Routes {
+		if len(r.Prefix) > matched && hasPathPrefix(path, r.Prefix) {
+			hard, matched = r.TTL, len(r.Prefix)
+		}
+	}
+	if p.SoftPercent <= 0 || p.SoftPercent >= 100 {
+		return hard, hard
+	}
+	return hard * time.Duration(p.SoftPercent) / 100, hard
+}
+
+// Max — самый долгий жёсткий TTL: столько живут множества тегов, общие
+// для записей разных маршрутов.
+func (p TTLPolicy) Max() time.Duration {
+	out := p.Default
+	for _, r := range p.Routes {
+		out = max(out, r.TTL)
+	}
+	return out
+}
@@ -0,0 +1,112 @@
+package cache
+
+import (
+	"sort"
+	"strings"
+)
+
+// Vary — от чего, кроме пути и query, зависит ответ маршрута. Каждое
+// измерение входит в ключ записи: ответ, собранный для одного значения,
+// не отдаётся запросу с другим.
+type Vary uint8
+
+const (
+	// VaryRole — роль запрашивающего (студенту скрытые профили не видны).
+	VaryRole Vary = 1 << iota
+	// VaryCompany — компания запрашивающего: своя для владельца, компания
+	// подтверждённого membership для HR (свои вакансии на модерации).
+	VaryCompany
+)
+
+// Principal — кто спрашивает, в объёме, нужном измерениям Vary.
+type Principal struct {
+	UserID string
+	Role   string
+	// CompanyID — компания владельца или HR; пусто — не состоит ни в какой.
+	CompanyID string
+	// APIKey — запрос интеграции по API-ключу: его ответы не смешиваются
+	// с ответами пользователям той же роли.
+	APIKey bool
+}
+
+// Rule — как кэшировать GET-маршруты с префиксом Prefix.
+type Rule struct {
+	Prefix string
+	// Kind — сущность маршрута для тегов записи (Tags); пусто — записи без
+	// тегов, живут до TTL.
+	Kind string
+	Vary Vary
+	// BypassSelf — ответы на ресурсы самого пользователя (Prefix/<его id>...)
+	// не кэшируются: владелец видит своё, даже скрытое от других.
+	BypassSelf bool
+}
+
+// rules — публичные read-only ресурсы и от чего зависят их ответы.
+// Проверяются по порядку, выигрывает первое совпадение.
+//
+// Намеренно НЕ кэшируем: /users/me, /user/achievements/, /expert/queue,
+// /tasks/my-submissions, /hr/me, /hr/tasks, /hr/vacancy, /company/me — все они
+// owner-specific и требуют авторизованного контекста (см. ShouldExclude).
+var rules = []Rule{
+	{Prefix: "/api/v1/skills/popular"},
+	{Prefix: "/api/v1/skills/search"},
+	{Prefix: "/api/v1/skills/bulk"},
+	// Скрытый профиль (is_hidden) студентам отдаётся 404, остальным ролям — 200.
+	{Prefix: "/api/v1/users", Kind: "user", Vary: VaryRole, BypassSelf: true},
+	// Вакансии на модерации видит компания-автор.
+	{Prefix: "/api/v1/vacancy", Kind: "vacancy", Vary: VaryRole | VaryCompany},
+	{Prefix: "/api/v1/tasks", Kind: "task", Vary: VaryRole},
+	{Prefix: "/api/v1/company", Kind: "company", Vary: VaryRole | VaryCompany},
+}
+
+// RuleFor — правило для пути; nil — маршрут не кэшируется.
+func RuleFor(path string) *Rule {
+	if ShouldExclude(path) {
+		return nil
+	}
+	for i := range rules {
+		if hasPathPrefix(path, rules[i].Prefix) {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+// Bypass — ответ этому запрашивающему кэшировать нельзя (и нельзя отдавать
+// ему общий).
+func (r *Rule) Bypass(path string, p Principal) bool {
+	return r.BypassSelf && p.UserID != "" && hasPathPrefix(path, r.Prefix+"/"+p.UserID)
+}
+
+// Key — ключ записи: путь и query (как в Key) плюс значения измерений
+// Vary после "#". Фрагмент в URL запроса не приходит, так что разные
+// измерения не сталкиваются с путём, а записи всех измерений получают одни
+// и те же теги и чистятся вместе.
+func (r *Rule) Key(path, rawQuery string, p Principal) string {
+	key := Key(path, rawQuery)
+	var dims []string
+	if r.Vary&VaryRole != 0 {
+		dims = append(dims, "role="+p.Role)
+	}
+	if r.Vary&VaryCompany != 0 {
+		dims = append(dims, "company="+p.CompanyID)
+	}
+	if r.Vary != 0 && p.APIKey {
+		dims = append(dims, "apikey")
+	}
+	if len(dims) == 0 {
+		return key
+	}
+	sort.Strings(dims)
+	return key + "#" + strings.Join(dims, ";")
+}
+
+// hasPathPrefix — path равен prefix или продолжается после него новым
+// сегментом или query: /api/v1/users/abc, но не /api/v1/users-export.
+func hasPathPrefix(path, prefix string) bool {
+	if !strings.HasPrefix(path, prefix) {
+		return false
+	}
+	rest := path[len(prefix):]
+	return rest == "" || rest[0] == '/' || rest[0] == '?'
+}
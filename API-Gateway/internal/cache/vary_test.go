@@ -0,0 +1,113 @@
+package cache
+
+import "testing"
+
+func TestRuleKeySeparatesPrincipals(t *testing.T) {
+	student := Principal{UserID: "s1", Role: "ROLE_STUDENT"}
+	otherStudent := Principal{UserID: "s2", Role: "ROLE_STUDENT"}
+	hrA := Principal{UserID: "hr1", Role: "ROLE_EMPLOYER", CompanyID: "c1"}
+	otherHRA := Principal{UserID: "hr2", Role: "ROLE_EMPLOYER", CompanyID: "c1"}
+	pendingHR := Principal{UserID: "hr3", Role: "ROLE_EMPLOYER"}
+	ownerA := Principal{UserID: "c1", Role: "ROLE_COMPANY_OWNER", CompanyID: "c1"}
+	ownerB := Principal{UserID: "c2", Role: "ROLE_COMPANY_OWNER", CompanyID: "c2"}
+	keyA := Principal{UserID: "c1", Role: "ROLE_COMPANY_OWNER", CompanyID: "c1", APIKey: true}
+
+	tests := []struct {
+		name   string
+		path   string
+		query  string
+		a, b   Principal
+		shared bool
+	}{
+		// Скрытый профиль: студенту 404, HR — 200.
+		{"hidden profile student vs hr", "/api/v1/users/u9", "", student, hrA, false},
+		{"profile same role", "/api/v1/users/u9", "", student, otherStudent, true},
+		{"profile owner vs api key", "/api/v1/users/u9", "", ownerA, keyA, false},
+		// Вакансию на модерации видит только компания-автор.
+		{"vacancy owner vs other owner", "/api/v1/vacancy/v1", "", ownerA, ownerB, false},
+		{"vacancy owner vs its hr", "/api/v1/vacancy/v1", "", ownerA, hrA, false},
+		{"vacancy hr vs pending hr", "/api/v1/vacancy/v1", "", hrA, pendingHR, false},
+		{"vacancy hr vs student", "/api/v1/vacancy/v1", "", hrA, student, false},
+		{"vacancy hrs of one company", "/api/v1/vacancy/v1", "", hrA, otherHRA, true},
+		{"vacancy list owner vs other owner", "/api/v1/vacancy", "page=1", ownerA, ownerB, false},
+		{"vacancy list students", "/api/v1/vacancy", "page=1", student, otherStudent, true},
+		{"company owner vs other owner", "/api/v1/company/c1", "", ownerA, ownerB, false},
+		{"company owner vs api key", "/api/v1/company/c1", "", ownerA, keyA, false},
+		{"tasks student vs hr", "/api/v1/tasks/t1", "", student, hrA, false},
+		{"skills any principal", "/api/v1/skills/popular", "", student, ownerB, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := RuleFor(tt.path)
+			if rule == nil {
+				t.Fatalf("RuleFor(%s) = nil", tt.path)
+			}
+			ka, kb := rule.Key(tt.path, tt.query, tt.a), rule.Key(tt.path, tt.query, tt.b)
+			if (ka == kb) != tt.shared {
+				t.Fatalf("keys %q and %q: shared %t, want %t", ka, kb, ka == kb, tt.shared)
+			}
+		})
+	}
+}
+
+func TestRuleKeySeparatesQueries(t *testing.T) {
+	rule := RuleFor("/api/v1/vacancy")
+	p := Principal{UserID: "s1", Role: "ROLE_STUDENT"}
+	if rule.Key("/api/v1/vacancy", "page=1", p) == rule.Key("/api/v1/vacancy", "page=2", p) {
+		t.Fatal("different pages share a key")
+	}
+}
+
+func TestRuleBypassSelf(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		userID string
+		want   bool
+	}{
+		{"own profile", "/api/v1/users/u1", "u1", true},
+		{"own achievements", "/api/v1/users/u1/achievements", "u1", true},
+		{"other profile", "/api/v1/users/u2", "u1", false},
+		{"id prefix of other id", "/api/v1/users/u10", "u1", false},
+		{"anonymous", "/api/v1/users/u1", "", false},
+		{"user list", "/api/v1/users", "u1", false},
+	}
+	rule := RuleFor("/api/v1/users/u1")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Principal{UserID: tt.userID, Role: "ROLE_STUDENT"}
+			if got := rule.Bypass(tt.path, p); got != tt.want {
+				t.Fatalf("Bypass(%s, %s) = %t, want %t", tt.path, tt.userID, got, tt.want)
+			}
+		})
+	}
+
+	// Ресурсы компании кэшируются и для владельца: ключ разделяет компании.
+	company := RuleFor("/api/v1/company/c1")
+	if company.Bypass("/api/v1/company/c1", Principal{UserID: "c1", Role: "ROLE_COMPANY_OWNER", CompanyID: "c1"}) {
+		t.Fatal("company rule bypasses the cache for its owner")
+	}
+}
+
+func TestRuleForOwnerOnlyRoutes(t *testing.T) {
+	for _, path := range []string{
+		"/api/v1/users/me",
+		"/api/v1/user/achievements",
+		"/api/v1/tasks/mine",
+		"/api/v1/tasks/my-submissions",
+		"/api/v1/hr/vacancy",
+		"/api/v1/hr/me",
+		"/api/v1/company/me",
+		"/api/v1/company/members",
+		"/api/v1/company/membership/my",
+		"/api/v1/company/audit",
+		"/api/v1/company/api-keys",
+		"/api/v1/chat/threads",
+		"/api/v1/expert/queue",
+		"/api/v1/users-export",
+	} {
+		if rule := RuleFor(path); rule != nil {
+			t.Errorf("RuleFor(%s) = %+v, want not cached", path, *rule)
+		}
+	}
+}
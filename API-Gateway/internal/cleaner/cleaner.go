@@ -15,6 +15,7 @@ package cleaner
 import (
 	"context"
 	"log"
+	"sync"
 	"time"
 
 	"github.com/studjobs/hh_for_students/api-gateway/internal/models"
@@ -33,6 +34,10 @@ const (
 type Cleaner struct {
 	svc      *services.ApiGateway
 	interval time.Duration
+
+	// running — идёт прогон (по расписанию или запущенный администратором);
+	// второй одновременно не стартует.
+	running sync.Mutex
 }
 
 func New(svc *services.ApiGateway, interval time.Duration) *Cleaner {
@@ -54,7 +59,7 @@ func (c *Cleaner) Run(ctx context.Context) {
 		return
 	case <-first.C:
 	}
-	c.runOnce(ctx)
+	c.RunOnce(ctx)
 
 	t := time.NewTicker(c.interval)
 	defer t.Stop()
@@ -64,11 +69,37 @@ func (c *Cleaner) Run(ctx context.Context) {
 			log.Printf("cleaner: stopping")
 			return
 		case <-t.C:
-			c.runOnce(ctx)
+			c.RunOnce(ctx)
 		}
 	}
 }
 
+// RunOnce — один прогон чистки. false — прогон уже идёт, этот пропущен.
+func (c *Cleaner) RunOnce(ctx context.Context) bool {
+	if !c.running.TryLock() {
+		log.Printf("cleaner: previous run still in progress, skipping")
+		return false
+	}
+	defer c.running.Unlock()
+	c.runOnce(ctx)
+	return true
+}
+
+// Start запускает прогон вне расписания в фоне (POST /api/v1/admin/cleaner/run).
+// false — прогон уже идёт.
+func (c *Cleaner) Start(ctx context.Context) bool {
+	if !c.running.TryLock() {
+		return false
+	}
+	go func() {
+		defer c.running.Unlock()
+		log.Printf("cleaner: manual run started")
+		c.runOnce(ctx)
+		log.Printf("cleaner: manual run finished")
+	}()
+	return true
+}
+
 func (c *Cleaner) runOnce(ctx context.Context) {
 	pag := &models.Pagination{Page: 1, Limit: 1000}
 	companies, err := c.svc.Company.GetAllCompanies(ctx, pag, "", "", "")
@@ -0,0 +1,298 @@
+// Package deletion — удаление аккаунта во всех сервисах. Удаление — сага
+// без отката (saga.Orchestrator.Resume): шаги выполняются по порядку,
+// прогресс сохраняется, а шаг, упавший после повторов, продолжит сверка.
+// Все шаги идемпотентны: повтор после сбоя ничего не ломает.
+//
+// Правила хранения — что происходит с данными в каждом сервисе:
+//
+//	auth         Auth: аккаунт обезличивается (email заменяется заглушкой,
+//	             хеш пароля стирается), второй фактор, привязки провайдеров
+//	             и сессии удаляются. Строка с uuid остаётся — на неё
+//	             ссылаются записи остальных сервисов. Идёт первым: после
+//	             него войти в аккаунт и продолжить им пользоваться нельзя.
+//	applications Vacancy: отклики на рассмотрении отзываются. Рассмотренные
+//	             отклики остаются у компании как история найма — без
+//	             профиля они указывают только на обезличенный uuid.
+//	memberships  Company: заявки и членство HR в компаниях отклоняются.
+//	chat         Users: текст сообщений заменяется на «Сообщение удалено»;
+//	             сами сообщения остаются, чтобы не ломать тред собеседника.
+//	files        Achievements (S3): все файлы — достижения, аватар, резюме,
+//	             логотип компании — удаляются вместе с метаданными.
+//	company      Company: компания владельца удаляется.
+//	profile      Users: профиль удаляется целиком.
+//	search       Search: профиль удаляется из индекса (Users делает это
+//	             best-effort, здесь — с повторами).
+//
+// Решения микрозадач остаются у компании-заказчика: это результат
+// выполненной для неё работы.
+package deletion
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/authn"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/models"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/saga"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/services"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	sagaKind   = "account_deletion"
+	sagaPrefix = "deletion:"
+
+	stepAuth         = "auth"
+	stepApplications = "applications"
+	stepMemberships  = "memberships"
+	stepChat         = "chat"
+	stepFiles        = "files"
+	stepCompany      = "company"
+	stepProfile      = "profile"
+	stepSearch       = "search"
+
+	dataUserUUID = "user_uuid"
+
+	applicationStatusPending = 1
+	membershipStatusRejected = 3
+	applicationsPageSize     = 100
+	maxApplicationsPages     = 50
+
+	// backgroundTimeout — сколько фоновые шаги могут идти после ответа
+	// клиенту; не успевшее доделает сверка.
+	backgroundTimeout = 5 * time.Minute
+)
+
+// Статусы удаления в ответе API.
+const (
+	StatusInProgress = "in_progress"
+	StatusCompleted  = "completed"
+)
+
+var ErrNotFound = errors.New("account deletion not found")
+
+type Service struct {
+	api          *services.ApiGateway
+	verifier     *authn.Verifier
+	orchestrator *saga.Orchestrator
+}
+
+// New — verifier может быть nil (тогда кэш статуса токенов не сбрасывается).
+func New(api *services.ApiGateway, store saga.Store, verifier *authn.Verifier) *Service {
+	return &Service{
+		api:      api,
+		verifier: verifier,
+		orchestrator: saga.NewOrchestrator(store, saga.RetryPolicy{
+			Attempts:  3,
+			Backoff:   200 * time.Millisecond,
+			Retryable: retryable,
+		}),
+	}
+}
+
+// Start начинает удаление аккаунта userUUID. Шаг auth выполняется сразу —
+// когда Start вернулся без ошибки, токены аккаунта уже недействительны;
+// остальные шаги идут в фоне.
+func (s *Service) Start(ctx context.Context, userUUID string) (*models.AccountDeletion, error) {
+	steps := s.steps()
+	st := saga.NewState(sagaPrefix+uuid.NewString(), sagaKind, steps)
+	st.Data[dataUserUUID] = userUUID
+
+	if err := s.orchestrator.Store().Create(ctx, st); err != nil {
+		return nil, err
+	}
+	log.Printf("deletion: started %s for user %s", st.ID, userUUID)
+
+	if err := s.orchestrator.Resume(ctx, st, steps[:1]); err != nil {
+		// Ничего ещё не удалено: закрываем сагу, чтобы сверка не удалила
+		// аккаунт, о котором пользователь получил ошибку. Он повторит запрос.
+		s.orchestrator.Compensate(ctx, st, nil)
+		return nil, err
+	}
+
+	resp := toModel(st)
+	go func() {
+		bgCtx, cancel := context.WithTimeout(context.Background(), backgroundTimeout)
+		defer cancel()
+		s.resume(bgCtx, st)
+	}()
+	return resp, nil
+}
+
+// Get — ход удаления по id, который вернул Start.
+func (s *Service) Get(ctx context.Context, id string) (*models.AccountDeletion, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrNotFound
+	}
+	st, err := s.orchestrator.Store().Get(ctx, sagaPrefix+id)
+	if err != nil {
+		if errors.Is(err, saga.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if st.Kind != sagaKind {
+		return nil, ErrNotFound
+	}
+	return toModel(st), nil
+}
+
+func (s *Service) resume(ctx context.Context, st *saga.State) error {
+	if err := s.orchestrator.Resume(ctx, st, s.steps()); err != nil {
+		log.Printf("deletion: %s for user %s paused: %v", st.ID, st.Data[dataUserUUID], err)
+		return err
+	}
+	log.Printf("deletion: %s for user %s completed", st.ID, st.Data[dataUserUUID])
+	return nil
+}
+
+func (s *Service) steps() []saga.Step {
+	return []saga.Step{
+		{Name: stepAuth, Do: s.deleteAccount},
+		{Name: stepApplications, Do: s.withdrawApplications},
+		{Name: stepMemberships, Do: s.rejectMemberships},
+		{Name: stepChat, Do: s.eraseMessages},
+		{Name: stepFiles, Do: s.deleteFiles},
+		{Name: stepCompany, Do: s.deleteCompany},
+		{Name: stepProfile, Do: s.deleteProfile},
+		{Name: stepSearch, Do: s.deleteFromSearch},
+	}
+}
+
+func (s *Service) deleteAccount(ctx context.Context, st *saga.State) error {
+	userUUID := st.Data[dataUserUUID]
+	if err := ignoreNotFound(s.api.Auth.DeleteUser(ctx, userUUID)); err != nil {
+		return err
+	}
+	if s.verifier != nil {
+		s.verifier.InvalidateUser(ctx, userUUID)
+	}
+	return nil
+}
+
+// withdrawApplications отзывает отклики на рассмотрении. Отозванный отклик
+// пропадает из выдачи, поэтому каждый раз берётся первая страница.
+func (s *Service) withdrawApplications(ctx context.Context, st *saga.State) error {
+	if !s.api.Application.Available() {
+		return nil
+	}
+	userUUID := st.Data[dataUserUUID]
+
+	for page := 0; page < maxApplicationsPages; page++ {
+		list, err := s.api.Application.ListMine(ctx, userUUID, applicationStatusPending, 1, applicationsPageSize)
+		if err != nil {
+			return err
+		}
+		if len(list.Applications) == 0 {
+			return nil
+		}
+		for _, app := range list.Applications {
+			if err := ignoreNotFound(s.api.Application.Withdraw(ctx, app.ID, userUUID)); err != nil {
+				return err
+			}
+		}
+	}
+	return errors.New("too many pending applications, will continue later")
+}
+
+func (s *Service) rejectMemberships(ctx context.Context, st *saga.State) error {
+	// Статус 0 — заявки на рассмотрении и действующее членство.
+	memberships, err := s.api.Company.ListMembershipsByUser(ctx, st.Data[dataUserUUID], 0)
+	if err != nil {
+		return ignoreNotFound(err)
+	}
+	for _, m := range memberships {
+		if m.Status == membershipStatusRejected {
+			continue
+		}
+		if _, err := s.api.Company.ReviewMembership(ctx, m.ID, membershipStatusRejected); err != nil {
+			if err := ignoreNotFound(err); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Service) eraseMessages(ctx context.Context, st *saga.State) error {
+	n, err := s.api.Chat.EraseUserMessages(ctx, st.Data[dataUserUUID])
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		log.Printf("deletion: erased %d chat messages of user %s", n, st.Data[dataUserUUID])
+	}
+	return nil
+}
+
+// deleteFiles удаляет все файлы пользователя: аватар, резюме и логотип
+// тоже хранятся как его достижения.
+func (s *Service) deleteFiles(ctx context.Context, st *saga.State) error {
+	userUUID := st.Data[dataUserUUID]
+	list, err := s.api.Achievement.GetAllAchievements(ctx, userUUID)
+	if err != nil {
+		return ignoreNotFound(err)
+	}
+	for _, a := range list.Achievements {
+		if err := ignoreNotFound(s.api.Achievement.DeleteAchievement(ctx, userUUID, a.Name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteCompany — id компании владельца совпадает с uuid его аккаунта.
+func (s *Service) deleteCompany(ctx context.Context, st *saga.State) error {
+	return ignoreNotFound(s.api.Company.DeleteCompany(ctx, st.Data[dataUserUUID]))
+}
+
+func (s *Service) deleteProfile(ctx context.Context, st *saga.State) error {
+	return ignoreNotFound(s.api.User.DeleteUser(ctx, st.Data[dataUserUUID]))
+}
+
+func (s *Service) deleteFromSearch(ctx context.Context, st *saga.State) error {
+	if !s.api.Search.Available() {
+		return nil
+	}
+	return ignoreNotFound(s.api.Search.DeleteProfile(ctx, st.Data[dataUserUUID]))
+}
+
+func toModel(st *saga.State) *models.AccountDeletion {
+	out := &models.AccountDeletion{
+		ID:          st.ID[len(sagaPrefix):],
+		Status:      StatusInProgress,
+		RequestedAt: st.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   st.UpdatedAt.Format(time.RFC3339),
+	}
+	if st.Status == saga.StatusCompleted {
+		out.Status = StatusCompleted
+	}
+	for _, step := range st.Steps {
+		out.Steps = append(out.Steps, models.AccountDeletionStep{
+			Name:  step.Name,
+			Done:  step.Done,
+			Error: step.Error,
+		})
+	}
+	return out
+}
+
+func retryable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Aborted, codes.ResourceExhausted:
+		return true
+	default:
+		return false
+	}
+}
+
+func ignoreNotFound(err error) error {
+	if status.Code(err) == codes.NotFound {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,65 @@
+package deletion
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// staleAfter — удаление без продвижения дольше этого считается
+// остановившимся (шаг упал или Gateway перезапустился).
+const staleAfter = 5 * time.Minute
+
+// Reconciler периодически продолжает остановившиеся удаления.
+type Reconciler struct {
+	service  *Service
+	interval time.Duration
+}
+
+func NewReconciler(service *Service, interval time.Duration) *Reconciler {
+	return &Reconciler{service: service, interval: interval}
+}
+
+// Run — блокирующий цикл; завершается, когда ctx отменён.
+func (r *Reconciler) Run(ctx context.Context) {
+	log.Printf("deletion: reconciler started (interval=%v)", r.interval)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		r.reconcile(ctx)
+
+		select {
+		case <-ctx.Done():
+			log.Printf("deletion: reconciler stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Reconciler) reconcile(ctx context.Context) {
+	stale, err := r.service.orchestrator.Store().ListStale(ctx, time.Now().Add(-staleAfter))
+	if err != nil {
+		log.Printf("deletion: failed to list stale deletions: %v", err)
+		return
+	}
+
+	completed := 0
+	for _, st := range stale {
+		if ctx.Err() != nil {
+			return
+		}
+		if st.Kind != sagaKind {
+			continue
+		}
+		log.Printf("deletion: resuming %s for user %s", st.ID, st.Data[dataUserUUID])
+		if err := r.service.resume(ctx, st); err == nil {
+			completed++
+		}
+	}
+	if completed > 0 {
+		log.Printf("deletion: completed %d stale deletions", completed)
+	}
+}
@@ -0,0 +1,487 @@
+// Package export — выгрузка персональных данных пользователя из всех
+// сервисов (право на доступ к своим данным). Выгрузка — задача в фоне:
+// Gateway собирает данные, упаковывает их в ZIP и кладёт архив в MinIO через
+// Achievements, а пользователь по id выгрузки получает ссылку на скачивание
+// с ограниченным сроком действия.
+//
+// Состояние задачи хранится как сага (saga.Orchestrator.Resume) с одним
+// шагом archive: сборка идемпотентна — повтор перезаписывает тот же архив,
+// а остановившуюся выгрузку продолжит сверка.
+//
+// Состав архива:
+//
+//	manifest.json         что выгружено, когда и что пропущено
+//	account.json          Auth: аккаунт, роли, привязанные провайдеры, сессии
+//	profile.json          Users: профиль
+//	chat_messages.json    Users: сообщения пользователя в чатах
+//	applications.json     Vacancy: отклики на вакансии
+//	submissions.json      MicroTasks: решения микрозадач
+//	memberships.json      Company: заявки и членство в компаниях
+//	achievements.json     Achievements: метаданные файлов и достижений
+//	files/<имя>/<файл>    Achievements (S3): исходные файлы
+//
+// Секреты (хеш пароля, TOTP-секрет, коды восстановления) в архив не попадают.
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/models"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/saga"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/services"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/utils"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	sagaKind   = "data_export"
+	sagaPrefix = "export:"
+
+	stepArchive = "archive"
+
+	dataUserUUID = "user_uuid"
+
+	pageSize = 100
+	maxPages = 50
+	// maxThreads — сколько тредов чата выгружается.
+	maxThreads = 500
+	// maxFilesSize — сколько байт исходных файлов кладётся в архив: архив
+	// собирается в памяти. Не поместившиеся файлы перечислены в manifest.json.
+	maxFilesSize = 200 << 20
+
+	membershipStatusRejected = 3
+
+	// maxAttempts — после стольких попыток собрать архив выгрузка
+	// считается неудавшейся; пользователь может запросить новую.
+	maxAttempts = 10
+
+	// backgroundTimeout — сколько может идти сборка архива.
+	backgroundTimeout = 10 * time.Minute
+)
+
+// Статусы выгрузки в ответе API.
+const (
+	StatusInProgress = "in_progress"
+	StatusReady      = "ready"
+	StatusExpired    = "expired"
+	StatusFailed     = "failed"
+)
+
+var ErrNotFound = errors.New("data export not found")
+
+type Service struct {
+	api          *services.ApiGateway
+	orchestrator *saga.Orchestrator
+	// linkTTL — срок действия ссылки на скачивание.
+	linkTTL time.Duration
+}
+
+func New(api *services.ApiGateway, store saga.Store, linkTTL time.Duration) *Service {
+	return &Service{
+		api:     api,
+		linkTTL: linkTTL,
+		orchestrator: saga.NewOrchestrator(store, saga.RetryPolicy{
+			Attempts:  3,
+			Backoff:   500 * time.Millisecond,
+			Retryable: retryable,
+		}),
+	}
+}
+
+// Start запускает выгрузку данных пользователя userUUID; архив собирается
+// в фоне, ход выгрузки — Get.
+func (s *Service) Start(ctx context.Context, userUUID string) (*models.DataExport, error) {
+	steps := s.steps()
+	st := saga.NewState(sagaPrefix+uuid.NewString(), sagaKind, steps)
+	st.Data[dataUserUUID] = userUUID
+
+	if err := s.orchestrator.Store().Create(ctx, st); err != nil {
+		return nil, err
+	}
+	log.Printf("export: started %s for user %s", st.ID, userUUID)
+
+	resp := toModel(st)
+	go func() {
+		bgCtx, cancel := context.WithTimeout(context.Background(), backgroundTimeout)
+		defer cancel()
+		s.resume(bgCtx, st)
+	}()
+	return resp, nil
+}
+
+// Get — ход выгрузки id пользователя userUUID. Для готового архива каждый
+// вызов выдаёт новую ссылку на скачивание. Чужая выгрузка — ErrNotFound.
+func (s *Service) Get(ctx context.Context, userUUID, id string) (*models.DataExport, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrNotFound
+	}
+	st, err := s.orchestrator.Store().Get(ctx, sagaPrefix+id)
+	if err != nil {
+		if errors.Is(err, saga.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if st.Kind != sagaKind || st.Data[dataUserUUID] != userUUID {
+		return nil, ErrNotFound
+	}
+
+	resp := toModel(st)
+	if resp.Status != StatusReady {
+		return resp, nil
+	}
+
+	link, err := s.api.Achievement.GetExportDownloadUrl(ctx, userUUID, resp.ID, int32(s.linkTTL/time.Minute))
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			// Архив удалён по сроку хранения.
+			resp.Status = StatusExpired
+			return resp, nil
+		}
+		return nil, err
+	}
+	resp.DownloadURL = link.URL
+	resp.DownloadExpiresAt = link.ExpiresAt
+	return resp, nil
+}
+
+func (s *Service) resume(ctx context.Context, st *saga.State) error {
+	err := s.orchestrator.Resume(ctx, st, s.steps())
+	if err == nil {
+		log.Printf("export: %s for user %s completed", st.ID, st.Data[dataUserUUID])
+		return nil
+	}
+
+	log.Printf("export: %s for user %s paused: %v", st.ID, st.Data[dataUserUUID], err)
+	if st.Steps[0].Attempts >= maxAttempts {
+		// Откатывать нечего: закрываем сагу, статус выгрузки — failed.
+		log.Printf("export: %s for user %s failed after %d attempts", st.ID, st.Data[dataUserUUID], st.Steps[0].Attempts)
+		s.orchestrator.Compensate(ctx, st, nil)
+	}
+	return err
+}
+
+func (s *Service) steps() []saga.Step {
+	return []saga.Step{
+		{Name: stepArchive, Do: s.buildArchive},
+	}
+}
+
+// buildArchive собирает данные пользователя, упаковывает их и загружает
+// архив в MinIO.
+func (s *Service) buildArchive(ctx context.Context, st *saga.State) error {
+	userUUID := st.Data[dataUserUUID]
+	exportID := st.ID[len(sagaPrefix):]
+
+	a := newArchive()
+	contents := &manifest{
+		ExportID:    exportID,
+		UserUUID:    userUUID,
+		GeneratedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+
+	account, err := s.api.Auth.ExportAccount(ctx, userUUID)
+	if err != nil {
+		return fmt.Errorf("auth: %w", err)
+	}
+	if err := a.addJSON("account.json", account); err != nil {
+		return err
+	}
+
+	collectors := []struct {
+		file    string
+		collect func(ctx context.Context, userUUID string) (any, error)
+	}{
+		{"profile.json", s.collectProfile},
+		{"chat_messages.json", s.collectChatMessages},
+		{"applications.json", s.collectApplications},
+		{"submissions.json", s.collectSubmissions},
+		{"memberships.json", s.collectMemberships},
+	}
+	for _, c := range collectors {
+		data, err := c.collect(ctx, userUUID)
+		if err != nil {
+			return fmt.Errorf("%s: %w", strings.TrimSuffix(c.file, ".json"), err)
+		}
+		if data == nil {
+			contents.skip(c.file, "нет данных или сервис недоступен")
+			continue
+		}
+		if err := a.addJSON(c.file, data); err != nil {
+			return err
+		}
+	}
+
+	if err := s.addAchievements(ctx, a, contents, userUUID); err != nil {
+		return fmt.Errorf("achievements: %w", err)
+	}
+
+	contents.Files = a.names
+	if err := a.addJSON("manifest.json", contents); err != nil {
+		return err
+	}
+	body, err := a.close()
+	if err != nil {
+		return err
+	}
+
+	upload, err := s.api.Achievement.GetExportUploadUrl(ctx, userUUID, exportID)
+	if err != nil {
+		return err
+	}
+	if err := utils.UploadToPresignedURL(ctx, upload.UploadURL, body, "application/zip"); err != nil {
+		// Ошибка HTTP-загрузки в MinIO — временная, стоит повторить.
+		return status.Error(codes.Unavailable, err.Error())
+	}
+
+	log.Printf("export: %s for user %s uploaded (%d bytes, %d files)", st.ID, userUUID, len(body), len(a.names))
+	return nil
+}
+
+func (s *Service) collectProfile(ctx context.Context, userUUID string) (any, error) {
+	profile, err := s.api.User.GetUser(ctx, userUUID)
+	if err != nil {
+		return nil, ignoreNotFound(err)
+	}
+	return profile, nil
+}
+
+// collectChatMessages — сообщения, которые написал сам пользователь;
+// реплики собеседников — их данные, а не его.
+func (s *Service) collectChatMessages(ctx context.Context, userUUID string) (any, error) {
+	threads, err := s.api.Chat.ListUserThreads(ctx, userUUID, maxThreads)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]*models.ChatMessage, 0)
+	for _, thread := range threads {
+		for page := int32(1); page <= maxPages; page++ {
+			list, err := s.api.Chat.ListMessages(ctx, thread.ThreadID, page, pageSize)
+			if err != nil {
+				return nil, err
+			}
+			for _, m := range list.Messages {
+				if m.FromUserID == userUUID {
+					messages = append(messages, m)
+				}
+			}
+			if len(list.Messages) < pageSize || (list.Pagination != nil && page >= list.Pagination.Pages) {
+				break
+			}
+		}
+	}
+	return messages, nil
+}
+
+func (s *Service) collectApplications(ctx context.Context, userUUID string) (any, error) {
+	if !s.api.Application.Available() {
+		return nil, nil
+	}
+
+	applications := make([]*models.Application, 0)
+	for page := int32(1); page <= maxPages; page++ {
+		// Статус 0 — отклики в любом статусе.
+		list, err := s.api.Application.ListMine(ctx, userUUID, 0, page, pageSize)
+		if err != nil {
+			return nil, err
+		}
+		applications = append(applications, list.Applications...)
+		if len(list.Applications) < pageSize {
+			break
+		}
+	}
+	return applications, nil
+}
+
+func (s *Service) collectSubmissions(ctx context.Context, userUUID string) (any, error) {
+	if !s.api.MicroTasks.Available() {
+		return nil, nil
+	}
+
+	submissions := make([]*models.Submission, 0)
+	for page := int32(1); page <= maxPages; page++ {
+		list, err := s.api.MicroTasks.ListSubmissions(ctx, "", userUUID, page, pageSize)
+		if err != nil {
+			return nil, err
+		}
+		submissions = append(submissions, list.Submissions...)
+		if len(list.Submissions) < pageSize {
+			break
+		}
+	}
+	return submissions, nil
+}
+
+// collectMemberships — статус 0 даёт заявки на рассмотрении и действующее
+// членство, отклонённые запрашиваются отдельно.
+func (s *Service) collectMemberships(ctx context.Context, userUUID string) (any, error) {
+	memberships := make([]*models.CompanyMember, 0)
+	for _, st := range []int32{0, membershipStatusRejected} {
+		list, err := s.api.Company.ListMembershipsByUser(ctx, userUUID, st)
+		if err != nil {
+			if err := ignoreNotFound(err); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		memberships = append(memberships, list...)
+	}
+	return memberships, nil
+}
+
+// addAchievements кладёт метаданные достижений и сами файлы. Аватар, резюме
+// и логотип компании тоже хранятся как достижения пользователя.
+func (s *Service) addAchievements(ctx context.Context, a *archive, m *manifest, userUUID string) error {
+	list, err := s.api.Achievement.GetAllAchievements(ctx, userUUID)
+	if err != nil {
+		if err := ignoreNotFound(err); err != nil {
+			return err
+		}
+		m.skip("achievements.json", "нет данных")
+		return nil
+	}
+	if err := a.addJSON("achievements.json", list.Achievements); err != nil {
+		return err
+	}
+
+	var total int64
+	for _, meta := range list.Achievements {
+		if meta.FileType == "external/url" {
+			continue
+		}
+		name := path.Join("files", safeName(meta.Name), safeName(meta.FileName))
+		if total+meta.FileSize > maxFilesSize {
+			m.skip(name, "превышен размер архива; файл доступен в профиле")
+			continue
+		}
+
+		link, err := s.api.Achievement.GetAchievementDownloadUrl(ctx, userUUID, meta.Name)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				m.skip(name, "файл не найден в хранилище")
+				continue
+			}
+			return err
+		}
+		data, err := utils.DownloadFromPresignedURL(ctx, link.URL)
+		if err != nil {
+			return status.Error(codes.Unavailable, err.Error())
+		}
+		if err := a.add(name, data); err != nil {
+			return err
+		}
+		total += int64(len(data))
+	}
+	return nil
+}
+
+// manifest — оглавление архива.
+type manifest struct {
+	ExportID    string         `json:"export_id"`
+	UserUUID    string         `json:"user_uuid"`
+	GeneratedAt string         `json:"generated_at"`
+	Files       []string       `json:"files"`
+	Skipped     []skippedEntry `json:"skipped,omitempty"`
+}
+
+type skippedEntry struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+func (m *manifest) skip(name, reason string) {
+	m.Skipped = append(m.Skipped, skippedEntry{Name: name, Reason: reason})
+}
+
+// archive — ZIP-архив, собираемый в памяти.
+type archive struct {
+	buf   bytes.Buffer
+	zw    *zip.Writer
+	names []string
+}
+
+func newArchive() *archive {
+	a := &archive{}
+	a.zw = zip.NewWriter(&a.buf)
+	return a
+}
+
+func (a *archive) add(name string, data []byte) error {
+	w, err := a.zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s to archive: %w", name, err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("failed to add %s to archive: %w", name, err)
+	}
+	a.names = append(a.names, name)
+	return nil
+}
+
+func (a *archive) addJSON(name string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", name, err)
+	}
+	return a.add(name, data)
+}
+
+func (a *archive) close() ([]byte, error) {
+	if err := a.zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish archive: %w", err)
+	}
+	return a.buf.Bytes(), nil
+}
+
+// safeName — имя для пути внутри архива без разделителей каталогов.
+func safeName(name string) string {
+	name = strings.NewReplacer("/", "_", "\\", "_").Replace(name)
+	if name == "" || name == "." || name == ".." {
+		return "_"
+	}
+	return name
+}
+
+func toModel(st *saga.State) *models.DataExport {
+	out := &models.DataExport{
+		ID:          st.ID[len(sagaPrefix):],
+		Status:      StatusInProgress,
+		RequestedAt: st.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   st.UpdatedAt.Format(time.RFC3339),
+	}
+	switch st.Status {
+	case saga.StatusCompleted:
+		out.Status = StatusReady
+	case saga.StatusCompensated, saga.StatusFailed:
+		out.Status = StatusFailed
+	}
+	return out
+}
+
+func retryable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Aborted, codes.ResourceExhausted:
+		return true
+	default:
+		return false
+	}
+}
+
+func ignoreNotFound(err error) error {
+	if status.Code(err) == codes.NotFound {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,68 @@
+package export
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// staleAfter — выгрузка без продвижения дольше этого считается
+// остановившейся (сборка упала или Gateway перезапустился). Больше
+// backgroundTimeout, чтобы не собирать архив параллельно с идущей сборкой.
+const staleAfter = backgroundTimeout + 5*time.Minute
+
+// Reconciler периодически продолжает остановившиеся выгрузки.
+type Reconciler struct {
+	service  *Service
+	interval time.Duration
+}
+
+func NewReconciler(service *Service, interval time.Duration) *Reconciler {
+	return &Reconciler{service: service, interval: interval}
+}
+
+// Run — блокирующий цикл; завершается, когда ctx отменён.
+func (r *Reconciler) Run(ctx context.Context) {
+	log.Printf("export: reconciler started (interval=%v)", r.interval)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		r.reconcile(ctx)
+
+		select {
+		case <-ctx.Done():
+			log.Printf("export: reconciler stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Reconciler) reconcile(ctx context.Context) {
+	stale, err := r.service.orchestrator.Store().ListStale(ctx, time.Now().Add(-staleAfter))
+	if err != nil {
+		log.Printf("export: failed to list stale exports: %v", err)
+		return
+	}
+
+	completed := 0
+	for _, st := range stale {
+		if ctx.Err() != nil {
+			return
+		}
+		if st.Kind != sagaKind {
+			continue
+		}
+		log.Printf("export: resuming %s for user %s", st.ID, st.Data[dataUserUUID])
+		runCtx, cancel := context.WithTimeout(ctx, backgroundTimeout)
+		if err := r.service.resume(runCtx, st); err == nil {
+			completed++
+		}
+		cancel()
+	}
+	if completed > 0 {
+		log.Printf("export: completed %d stale exports", completed)
+	}
+}
@@ -15,8 +15,9 @@ import (
 	achievementv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/achievement/v1"
 	authv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/auth/v1"
 	usersv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/users/v1"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/mtls"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/tracing"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 // Clients содержит все gRPC клиенты
@@ -44,6 +45,8 @@ type Config struct {
 	SearchAddress          string
 	MicroTasksAddress      string
 	Timeout                time.Duration
+	// TLS — сертификат Gateway для mTLS; пустой — подключения без TLS.
+	TLS mtls.Config
 }
 
 // NewClients создаёт gRPC клиентов, пропуская отсутствующие адреса.
@@ -54,7 +57,7 @@ func NewClients(cfg Config) (*Clients, error) {
 
 	// подключаем каждую зависимость только если адрес указан
 	if cfg.AuthAddress != "" {
-		if conn := mustConn(cfg.AuthAddress, cfg.Timeout); conn != nil {
+		if conn := mustConn(cfg.TLS, cfg.AuthAddress, cfg.Timeout); conn != nil {
 			clients.Auth = authv1.NewAuthServiceClient(conn)
 		}
 	}
@@ -62,14 +65,14 @@ func NewClients(cfg Config) (*Clients, error) {
 	if cfg.UsersAddress != "" {
 		// Chat-сервис подключается к тому же gRPC-серверу, что и Users (порт 50052):
 		// мы зарегистрировали ChatServiceServer там же, чтобы не плодить новый микросервис.
-		if conn := mustConn(cfg.UsersAddress, cfg.Timeout); conn != nil {
+		if conn := mustConn(cfg.TLS, cfg.UsersAddress, cfg.Timeout); conn != nil {
 			clients.Users = usersv1.NewUsersServiceClient(conn)
 			clients.Chat = chatv1.NewChatServiceClient(conn)
 		}
 	}
 
 	if cfg.CompanyAddress != "" {
-		if conn := mustConn(cfg.CompanyAddress, cfg.Timeout); conn != nil {
+		if conn := mustConn(cfg.TLS, cfg.CompanyAddress, cfg.Timeout); conn != nil {
 			clients.Company = companyv1.NewCompanyServiceClient(conn)
 		}
 	}
@@ -77,32 +80,32 @@ func NewClients(cfg Config) (*Clients, error) {
 	if cfg.VacancyAddress != "" {
 		// ApplicationService живёт на том же gRPC-сервере, что и VacancyService (порт 50054),
 		// и обслуживается тем же подключением — экономим коннект.
-		if conn := mustConn(cfg.VacancyAddress, cfg.Timeout); conn != nil {
+		if conn := mustConn(cfg.TLS, cfg.VacancyAddress, cfg.Timeout); conn != nil {
 			clients.Vacancy = vacancyv1.NewVacancyServiceClient(conn)
 			clients.Application = applicationv1.NewApplicationServiceClient(conn)
 		}
 	}
 
 	if cfg.UserAchievementAddress != "" {
-		if conn := mustConn(cfg.UserAchievementAddress, cfg.Timeout); conn != nil {
+		if conn := mustConn(cfg.TLS, cfg.UserAchievementAddress, cfg.Timeout); conn != nil {
 			clients.Achievement = achievementv1.NewAchievementServiceClient(conn)
 		}
 	}
 
 	if cfg.SkillsAddress != "" {
-		if conn := mustConn(cfg.SkillsAddress, cfg.Timeout); conn != nil {
+		if conn := mustConn(cfg.TLS, cfg.SkillsAddress, cfg.Timeout); conn != nil {
 			clients.Skills = skillsv1.NewSkillsServiceClient(conn)
 		}
 	}
 
 	if cfg.SearchAddress != "" {
-		if conn := mustConn(cfg.SearchAddress, cfg.Timeout); conn != nil {
+		if conn := mustConn(cfg.TLS, cfg.SearchAddress, cfg.Timeout); conn != nil {
 			clients.Search = searchv1.NewSearchServiceClient(conn)
 		}
 	}
 
 	if cfg.MicroTasksAddress != "" {
-		if conn := mustConn(cfg.MicroTasksAddress, cfg.Timeout); conn != nil {
+		if conn := mustConn(cfg.TLS, cfg.MicroTasksAddress, cfg.Timeout); conn != nil {
 			clients.MicroTasks = microtaskv1.NewMicroTaskServiceClient(conn)
 		}
 	}
@@ -112,14 +115,21 @@ func NewClients(cfg Config) (*Clients, error) {
 }
 
 // mustConn — безопасное подключение: если адрес пустой или ошибка — вернёт nil.
-func mustConn(address string, timeout time.Duration) *grpc.ClientConn {
+func mustConn(tlsCfg mtls.Config, address string, timeout time.Duration) *grpc.ClientConn {
 	log.Printf("Connecting to gRPC service: %s", address)
 
+	creds, err := mtls.DialOption(tlsCfg, address)
+	if err != nil {
+		log.Printf("⚠ mTLS config for %s: %v (skipping)", address, err)
+		return nil
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
 	conn, err := grpc.DialContext(ctx, address,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		creds,
+		tracing.DialOption(),
 		grpc.WithBlock(),
 	)
 
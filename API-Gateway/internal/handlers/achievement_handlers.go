@@ -28,7 +28,7 @@ func (h *Handler) GetUserAchievementsByID(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid user ID format"})
 	}
 
-	achievements, err := h.apiService.Achievement.GetAllAchievements(c.Context(), targetID)
+	achievements, err := h.apiService.Achievement.GetAllAchievements(c.UserContext(), targetID)
 	if err != nil {
 		log.Printf("GetUserAchievementsByID: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to get achievements"})
@@ -61,7 +61,7 @@ func (h *Handler) GetUserAchievements(c *fiber.Ctx) error {
 	}
 
 	// Вызываем achievement service
-	achievements, err := h.apiService.Achievement.GetAllAchievements(c.Context(), userID)
+	achievements, err := h.apiService.Achievement.GetAllAchievements(c.UserContext(), userID)
 	if err != nil {
 		log.Printf("GetUserAchievements: Failed to get achievements for user %s: %v", userID, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -125,7 +125,7 @@ func (h *Handler) CreateUserAchievement(c *fiber.Ctx) error {
 
 	// Получаем URL для загрузки и S3 ключ
 	uploadResponse, err := h.apiService.Achievement.GetAchievementUploadUrl(
-		c.Context(),
+		c.UserContext(),
 		userID,
 		uploadReq.Name,
 		uploadReq.FileName,
@@ -248,7 +248,7 @@ func (h *Handler) ConfirmAchievementUpload(c *fiber.Ctx) error {
 		}
 	} else {
 		// Fallback: ищем уже сохранённую запись (на случай если клиент следует старому контракту)
-		achievements, err := h.apiService.Achievement.GetAllAchievements(c.Context(), userID)
+		achievements, err := h.apiService.Achievement.GetAllAchievements(c.UserContext(), userID)
 		if err != nil {
 			log.Printf("ConfirmAchievementUpload: Failed to get achievements for user %s: %v", userID, err)
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -271,7 +271,7 @@ func (h *Handler) ConfirmAchievementUpload(c *fiber.Ctx) error {
 	}
 
 	// Добавляем метаданные с S3 ключом
-	err := h.apiService.Achievement.AddAchievementMeta(c.Context(), achievementMeta, confirmReq.S3Key)
+	err := h.apiService.Achievement.AddAchievementMeta(c.UserContext(), achievementMeta, confirmReq.S3Key)
 	if err != nil {
 		log.Printf("ConfirmAchievementUpload: Failed to confirm upload for achievement %s, user %s: %v",
 			achievementName, userID, err)
@@ -324,7 +324,7 @@ func (h *Handler) GetAchievementDownloadUrl(c *fiber.Ctx) error {
 
 	// Вызываем achievement service
 	downloadUrl, err := h.apiService.Achievement.GetAchievementDownloadUrl(
-		c.Context(),
+		c.UserContext(),
 		userID,
 		achievementName,
 	)
@@ -376,7 +376,7 @@ func (h *Handler) DeleteAchievement(c *fiber.Ctx) error {
 	}
 
 	// Вызываем achievement service
-	err := h.apiService.Achievement.DeleteAchievement(c.Context(), userID, achievementName)
+	err := h.apiService.Achievement.DeleteAchievement(c.UserContext(), userID, achievementName)
 	if err != nil {
 		log.Printf("DeleteAchievement: Failed to delete achievement %s for user %s: %v",
 			achievementName, userID, err)
@@ -410,7 +410,7 @@ func (h *Handler) SubmitAchievementForReview(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid achievement id"})
 	}
 
-	if err := h.apiService.Achievement.SubmitForReview(c.Context(), userID, achievementID); err != nil {
+	if err := h.apiService.Achievement.SubmitForReview(c.UserContext(), userID, achievementID); err != nil {
 		log.Printf("SubmitAchievementForReview: failed for user %s, id %d: %v", userID, achievementID, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -430,7 +430,7 @@ func (h *Handler) GetExpertQueue(c *fiber.Ctx) error {
 	limit := c.QueryInt("limit", 20)
 	page, limit = normalizePagination(page, limit)
 
-	res, err := h.apiService.Achievement.GetExpertQueue(c.Context(), int32(page), int32(limit))
+	res, err := h.apiService.Achievement.GetExpertQueue(c.UserContext(), int32(page), int32(limit))
 	if err != nil {
 		log.Printf("GetExpertQueue: failed: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
@@ -444,7 +444,7 @@ func (h *Handler) GetExpertQueue(c *fiber.Ctx) error {
 		if a.UserUUID == "" || a.Name == "" {
 			continue
 		}
-		dl, derr := h.apiService.Achievement.GetAchievementDownloadUrl(c.Context(), a.UserUUID, a.Name)
+		dl, derr := h.apiService.Achievement.GetAchievementDownloadUrl(c.UserContext(), a.UserUUID, a.Name)
 		if derr != nil {
 			log.Printf("GetExpertQueue: failed to get download URL for %s/%s: %v", a.UserUUID, a.Name, derr)
 			continue
@@ -481,7 +481,7 @@ func (h *Handler) ReviewAchievement(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "decision must be 3 (approved) or 4 (rejected)"})
 	}
 
-	if err := h.apiService.Achievement.ReviewAchievement(c.Context(), achievementID, reviewerID, req.Decision, req.Comment); err != nil {
+	if err := h.apiService.Achievement.ReviewAchievement(c.UserContext(), achievementID, reviewerID, req.Decision, req.Comment); err != nil {
 		log.Printf("ReviewAchievement: failed for reviewer %s, id %d: %v", reviewerID, achievementID, err)
 		// Маппинг gRPC-кодов в HTTP — иначе любая ошибка превращается в 500
 		// и фронт не различает «нет прав» от настоящего сбоя.
@@ -499,5 +499,9 @@ func (h *Handler) ReviewAchievement(c *fiber.Ctx) error {
 		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
+	h.recordAudit(c, AuditAchievementReview, "achievement", c.Params("id"), "", nil, fiber.Map{
+		"decision": req.Decision,
+		"comment":  req.Comment,
+	})
 	return c.JSON(fiber.Map{"message": "review submitted"})
 }
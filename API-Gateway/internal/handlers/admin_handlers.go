@@ -0,0 +1,484 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/models"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Действия администраторов платформы (configs/policy.yaml, strict — без
+// обхода суперпользователями). Каждое пишется в журнал (см. adminAudit).
+const (
+	ActionAdminUserSearch    = "admin.user.search"
+	ActionAdminUserBan       = "admin.user.ban"
+	ActionAdminUserUnban     = "admin.user.unban"
+	ActionAdminCompanySearch = "admin.company.search"
+	ActionAdminCompanyVerify = "admin.company.verify"
+	ActionAdminVacancyClose  = "admin.vacancy.close"
+	ActionAdminSearchReindex = "admin.search.reindex"
+	ActionAdminCleanerRun    = "admin.cleaner.run"
+	ActionAdminActionsList   = "admin.actions.list"
+)
+
+// reindexTimeout — верхняя граница фоновой перестройки индексов.
+const reindexTimeout = 30 * time.Minute
+
+// initAdminRoutes — /api/v1/admin: модерация и обслуживание платформы.
+// Просмотр журналов (действий администраторов и аудита) в журнал не пишется.
+func (h *Handler) initAdminRoutes(api *securedGroup) {
+	admin := api.Group("/admin")
+	admin.Get("/users", ActionAdminUserSearch, h.adminAudit(ActionAdminUserSearch, ""), h.AdminSearchUsers)
+	admin.Post("/users/:id/ban", ActionAdminUserBan, h.adminAudit(ActionAdminUserBan, "user"), h.AdminBanUser)
+	admin.Delete("/users/:id/ban", ActionAdminUserUnban, h.adminAudit(ActionAdminUserUnban, "user"), h.AdminUnbanUser)
+	admin.Get("/companies", ActionAdminCompanySearch, h.adminAudit(ActionAdminCompanySearch, ""), h.AdminSearchCompanies)
+	admin.Post("/companies/:id/verify", ActionAdminCompanyVerify, h.adminAudit(ActionAdminCompanyVerify, "company"), h.AdminVerifyCompany)
+	admin.Delete("/companies/:id/verify", ActionAdminCompanyVerify, h.adminAudit(ActionAdminCompanyVerify, "company"), h.AdminVerifyCompany)
+	admin.Post("/vacancies/:id/close", ActionAdminVacancyClose, h.adminAudit(ActionAdminVacancyClose, "vacancy"), h.AdminCloseVacancy)
+	admin.Post("/search/reindex", ActionAdminSearchReindex, h.adminAudit(ActionAdminSearchReindex, "search"), h.AdminReindex)
+	admin.Post("/cleaner/run", ActionAdminCleanerRun, h.adminAudit(ActionAdminCleanerRun, "cleaner"), h.AdminRunCleaner)
+	admin.Get("/actions", ActionAdminActionsList, h.AdminListActions)
+	admin.Get("/audit", ActionAdminAuditList, h.AdminAuditLog)
+}
+
+// adminAudit пишет действие администратора в журнал Auth после обработчика:
+// кто, что, над чем, с какими параметрами (тело запроса или query) и с
+// каким HTTP-статусом. Отказы тоже пишутся — по ним видно попытки.
+// Ошибка записи не меняет ответ, но попадает в лог.
+func (h *Handler) adminAudit(action, targetType string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		err := c.Next()
+
+		code := c.Response().StatusCode()
+		var fe *fiber.Error
+		if errors.As(err, &fe) {
+			code = fe.Code
+		} else if err != nil {
+			code = fiber.StatusInternalServerError
+		}
+
+		entry := models.AdminAction{
+			AdminUUID:  getUserIDFromContext(c),
+			Action:     action,
+			TargetType: targetType,
+			TargetID:   c.Params(ID),
+			Details:    adminActionDetails(c),
+			Status:     code,
+			IP:         clientIP(c),
+		}
+		if recErr := h.apiService.Auth.RecordAdminAction(c.UserContext(), entry); recErr != nil {
+			log.Printf("adminAudit: failed to record %s by %s on %s %q (status %d): %v",
+				action, entry.AdminUUID, targetType, entry.TargetID, code, recErr)
+		}
+		return err
+	}
+}
+
+// adminActionDetails — параметры запроса для журнала: JSON-тело, если это
+// объект, иначе query-параметры.
+func adminActionDetails(c *fiber.Ctx) string {
+	if body := c.Body(); len(body) > 0 {
+		var obj map[string]any
+		if json.Unmarshal(body, &obj) == nil {
+			return string(body)
+		}
+	}
+	query := make(map[string]string)
+	c.Request().URI().QueryArgs().VisitAll(func(key, value []byte) {
+		query[string(key)] = string(value)
+	})
+	if len(query) == 0 {
+		return "{}"
+	}
+	details, _ := json.Marshal(query)
+	return string(details)
+}
+
+// AdminSearchUsers ищет аккаунты
+// @Summary Поиск аккаунтов
+// @Description Аккаунты по части email или uuid, роли и статусу блокировки. Новые первыми.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param q query string false "Часть email или uuid"
+// @Param role query string false "Роль (ROLE_STUDENT, ROLE_EMPLOYER...)"
+// @Param status query string false "banned или active"
+// @Param page query int false "Номер страницы" default(1) minimum(1)
+// @Param limit query int false "Количество элементов на странице" default(10) minimum(1) maximum(100)
+// @Success 200 {object} models.AdminAccountList "Аккаунты"
+// @Failure 400 {object} models.ErrorResponse "Неверные параметры запроса"
+// @Failure 401 {object} models.ErrorResponse "Не авторизован"
+// @Failure 403 {object} models.ErrorResponse "Только для администраторов"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /admin/users [get]
+func (h *Handler) AdminSearchUsers(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	page, limit = normalizePagination(page, limit)
+
+	filter := models.AdminAccountFilter{
+		Query:  c.Query("q"),
+		Role:   c.Query("role"),
+		Status: c.Query("status"),
+	}
+	if filter.Role != "" {
+		if _, ok := parseRole(filter.Role); !ok {
+			return c.Status(fiber.StatusBadRequest).JSON(models.Error{
+				Code:    "INVALID_ROLE",
+				Message: "Unknown role",
+			})
+		}
+	}
+	if filter.Status != "" && filter.Status != "banned" && filter.Status != "active" {
+		return c.Status(fiber.StatusBadRequest).JSON(models.Error{
+			Code:    "INVALID_DATA",
+			Message: "status must be banned or active",
+		})
+	}
+
+	accounts, err := h.apiService.Auth.SearchAccounts(c.UserContext(), filter, page, limit)
+	if err != nil {
+		log.Printf("AdminSearchUsers failed: %v", err)
+		return h.handleAuthError(c, err)
+	}
+	return c.JSON(accounts)
+}
+
+// AdminBanUser блокирует аккаунт
+// @Summary Блокировка аккаунта
+// @Description Аккаунт теряет доступ сразу: токены перестают проходить проверку, сессии и refresh-токены отзываются, ключи интеграций компании не принимаются. Администратора заблокировать нельзя.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "UUID пользователя"
+// @Param request body models.BanUserRequest false "Причина"
+// @Success 200 {object} models.AdminAccount "Аккаунт заблокирован"
+// @Failure 400 {object} models.ErrorResponse "Неверный запрос"
+// @Failure 401 {object} models.ErrorResponse "Не авторизован"
+// @Failure 403 {object} models.ErrorResponse "Только для администраторов"
+// @Failure 404 {object} models.ErrorResponse "Аккаунт не найден"
+// @Failure 409 {object} models.ErrorResponse "Аккаунт администратора"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /admin/users/{id}/ban [post]
+func (h *Handler) AdminBanUser(c *fiber.Ctx) error {
+	adminID := getUserIDFromContext(c)
+	userID := c.Params(ID)
+
+	var req models.BanUserRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(models.Error{
+				Code:    "INVALID_REQUEST",
+				Message: "Invalid request body",
+			})
+		}
+	}
+	if len(req.Reason) > 500 {
+		return c.Status(fiber.StatusBadRequest).JSON(models.Error{
+			Code:    "INVALID_DATA",
+			Message: "reason must be at most 500 characters",
+		})
+	}
+
+	account, err := h.apiService.Auth.BanUser(c.UserContext(), adminID, userID, req.Reason)
+	if err != nil {
+		log.Printf("AdminBanUser failed for user %s by %s: %v", userID, adminID, err)
+		return h.handleAdminAuthError(c, err)
+	}
+
+	// Токены заблокированного аккаунта не должны жить в кэше проверки до конца TTL.
+	if h.verifier != nil {
+		h.verifier.InvalidateUser(c.UserContext(), userID)
+	}
+
+	log.Printf("User %s banned by admin %s", userID, adminID)
+	return c.JSON(account)
+}
+
+// AdminUnbanUser снимает блокировку
+// @Summary Разблокировка аккаунта
+// @Description Снимает блокировку; пользователь входит заново.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "UUID пользователя"
+// @Success 200 {object} models.AdminAccount "Аккаунт разблокирован"
+// @Failure 401 {object} models.ErrorResponse "Не авторизован"
+// @Failure 403 {object} models.ErrorResponse "Только для администраторов"
+// @Failure 404 {object} models.ErrorResponse "Аккаунт не найден"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /admin/users/{id}/ban [delete]
+func (h *Handler) AdminUnbanUser(c *fiber.Ctx) error {
+	userID := c.Params(ID)
+
+	account, err := h.apiService.Auth.UnbanUser(c.UserContext(), userID)
+	if err != nil {
+		log.Printf("AdminUnbanUser failed for user %s: %v", userID, err)
+		return h.handleAdminAuthError(c, err)
+	}
+	if h.verifier != nil {
+		h.verifier.InvalidateUser(c.UserContext(), userID)
+	}
+
+	log.Printf("User %s unbanned by admin %s", userID, getUserIDFromContext(c))
+	return c.JSON(account)
+}
+
+// AdminSearchCompanies ищет компании
+// @Summary Поиск компаний
+// @Description Компании по названию или описанию и городу; verified_at — отметка модерации.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param q query string false "Часть названия или описания"
+// @Param city query string false "Город"
+// @Param page query int false "Номер страницы" default(1) minimum(1)
+// @Param limit query int false "Количество элементов на странице" default(10) minimum(1) maximum(100)
+// @Success 200 {object} models.CompanyList "Компании"
+// @Failure 401 {object} models.ErrorResponse "Не авторизован"
+// @Failure 403 {object} models.ErrorResponse "Только для администраторов"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /admin/companies [get]
+func (h *Handler) AdminSearchCompanies(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	page, limit = normalizePagination(page, limit)
+
+	companies, err := h.apiService.Company.GetAllCompanies(c.UserContext(),
+		&models.Pagination{Page: int32(page), Limit: int32(limit)}, c.Query("city"), "", c.Query("q"))
+	if err != nil {
+		log.Printf("AdminSearchCompanies failed: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(models.Error{
+			Code:    "INTERNAL_ERROR",
+			Message: "Failed to get companies",
+		})
+	}
+	return c.JSON(companies)
+}
+
+// AdminVerifyCompany ставит или снимает отметку о проверке компании
+// @Summary Проверка компании
+// @Description POST отмечает компанию проверенной, DELETE снимает отметку.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID компании"
+// @Success 200 {object} models.Company "Компания"
+// @Failure 401 {object} models.ErrorResponse "Не авторизован"
+// @Failure 403 {object} models.ErrorResponse "Только для администраторов"
+// @Failure 404 {object} models.ErrorResponse "Компания не найдена"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /admin/companies/{id}/verify [post]
+// @Router /admin/companies/{id}/verify [delete]
+func (h *Handler) AdminVerifyCompany(c *fiber.Ctx) error {
+	companyID := c.Params(ID)
+	verified := c.Method() == fiber.MethodPost
+
+	company, err := h.apiService.Company.VerifyCompany(c.UserContext(), companyID, verified)
+	if err != nil {
+		log.Printf("AdminVerifyCompany failed for company %s: %v", companyID, err)
+		if status.Code(err) == codes.NotFound {
+			return c.Status(fiber.StatusNotFound).JSON(models.Error{
+				Code:    "COMPANY_NOT_FOUND",
+				Message: "Company not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(models.Error{
+			Code:    "INTERNAL_ERROR",
+			Message: "Failed to verify company",
+		})
+	}
+
+	log.Printf("Company %s verified=%t by admin %s", companyID, verified, getUserIDFromContext(c))
+	return c.JSON(company)
+}
+
+// AdminCloseVacancy принудительно закрывает вакансию
+// @Summary Закрытие вакансии
+// @Description Переводит вакансию в closed независимо от компании; причина попадает в журнал действий.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID вакансии"
+// @Param request body models.CloseVacancyRequest false "Причина"
+// @Success 200 {object} models.Vacancy "Вакансия закрыта"
+// @Failure 400 {object} models.ErrorResponse "Неверный запрос"
+// @Failure 401 {object} models.ErrorResponse "Не авторизован"
+// @Failure 403 {object} models.ErrorResponse "Только для администраторов"
+// @Failure 404 {object} models.ErrorResponse "Вакансия не найдена"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /admin/vacancies/{id}/close [post]
+func (h *Handler) AdminCloseVacancy(c *fiber.Ctx) error {
+	vacancyID := c.Params(ID)
+
+	var req models.CloseVacancyRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(models.Error{
+				Code:    "INVALID_REQUEST",
+				Message: "Invalid request body",
+			})
+		}
+	}
+
+	if _, err := h.apiService.Vacancy.GetVacancy(c.UserContext(), vacancyID); err != nil {
+		log.Printf("AdminCloseVacancy: vacancy %s not found: %v", vacancyID, err)
+		return c.Status(fiber.StatusNotFound).JSON(models.Error{
+			Code:    "VACANCY_NOT_FOUND",
+			Message: "Vacancy not found",
+		})
+	}
+
+	vacancy, err := h.apiService.Vacancy.UpdateVacancy(c.UserContext(), vacancyID, &models.Vacancy{PositionStatus: "closed"})
+	if err != nil {
+		log.Printf("AdminCloseVacancy failed for vacancy %s: %v", vacancyID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(models.Error{
+			Code:    "UPDATE_FAILED",
+			Message: "Failed to close vacancy",
+		})
+	}
+
+	log.Printf("Vacancy %s closed by admin %s: %s", vacancyID, getUserIDFromContext(c), req.Reason)
+	return c.JSON(vacancy)
+}
+
+// AdminReindex перестраивает поисковые индексы
+// @Summary Перестройка поисковых индексов
+// @Description Запускает в фоне переиндексацию профилей, вакансий и микрозадач из сервисов-источников. Итог — в логах Gateway.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.ReindexRequest false "Пересоздать индексы"
+// @Success 202 {object} models.SuccessResponse "Переиндексация запущена"
+// @Failure 401 {object} models.ErrorResponse "Не авторизован"
+// @Failure 403 {object} models.ErrorResponse "Только для администраторов"
+// @Failure 409 {object} models.ErrorResponse "Переиндексация уже идёт"
+// @Failure 503 {object} models.ErrorResponse "Поиск не настроен"
+// @Router /admin/search/reindex [post]
+func (h *Handler) AdminReindex(c *fiber.Ctx) error {
+	if h.apiService.Search == nil || !h.apiService.Search.Available() {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(models.Error{
+			Code:    "SERVICE_UNAVAILABLE",
+			Message: "Search service is not configured",
+		})
+	}
+
+	var req models.ReindexRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(models.Error{
+				Code:    "INVALID_REQUEST",
+				Message: "Invalid request body",
+			})
+		}
+	}
+
+	if !h.reindexing.CompareAndSwap(false, true) {
+		return c.Status(fiber.StatusConflict).JSON(models.Error{
+			Code:    "REINDEX_IN_PROGRESS",
+			Message: "Reindex is already running",
+		})
+	}
+
+	adminID := getUserIDFromContext(c)
+	go func() {
+		defer h.reindexing.Store(false)
+		ctx, cancel := context.WithTimeout(context.Background(), reindexTimeout)
+		defer cancel()
+
+		result, err := h.apiService.Search.Reindex(ctx, req.RecreateIndices)
+		if err != nil {
+			log.Printf("Reindex started by admin %s failed: %v", adminID, err)
+			return
+		}
+		log.Printf("Reindex started by admin %s finished: profiles=%d vacancies=%d microtasks=%d",
+			adminID, result.Profiles, result.Vacancies, result.MicroTasks)
+	}()
+
+	return c.Status(fiber.StatusAccepted).JSON(models.SuccessResponse{Message: "Reindex started"})
+}
+
+// AdminRunCleaner запускает автоочистку вне расписания
+// @Summary Запуск автоочистки
+// @Description Запускает в фоне прогон удаления старых закрытых вакансий и выполненных микрозадач по настройкам компаний.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 202 {object} models.SuccessResponse "Прогон запущен"
+// @Failure 401 {object} models.ErrorResponse "Не авторизован"
+// @Failure 403 {object} models.ErrorResponse "Только для администраторов"
+// @Failure 409 {object} models.ErrorResponse "Прогон уже идёт"
+// @Failure 503 {object} models.ErrorResponse "Автоочистка недоступна"
+// @Router /admin/cleaner/run [post]
+func (h *Handler) AdminRunCleaner(c *fiber.Ctx) error {
+	if h.cleaner == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(models.Error{
+			Code:    "SERVICE_UNAVAILABLE",
+			Message: "Cleaner is not configured",
+		})
+	}
+	if !h.cleaner.Start(context.Background()) {
+		return c.Status(fiber.StatusConflict).JSON(models.Error{
+			Code:    "CLEANER_IN_PROGRESS",
+			Message: "Cleaner is already running",
+		})
+	}
+	return c.Status(fiber.StatusAccepted).JSON(models.SuccessResponse{Message: "Cleaner started"})
+}
+
+// AdminListActions возвращает журнал действий администраторов
+// @Summary Журнал действий администраторов
+// @Description Записи журнала, новые первыми; фильтры по администратору, действию и объекту.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param admin_uuid query string false "UUID администратора"
+// @Param action query string false "Действие (admin.user.ban...)"
+// @Param target_type query string false "Тип объекта (user, company, vacancy...)"
+// @Param target_id query string false "ID объекта"
+// @Param page query int false "Номер страницы" default(1) minimum(1)
+// @Param limit query int false "Количество элементов на странице" default(10) minimum(1) maximum(100)
+// @Success 200 {object} models.AdminActionList "Журнал"
+// @Failure 401 {object} models.ErrorResponse "Не авторизован"
+// @Failure 403 {object} models.ErrorResponse "Только для администраторов"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /admin/actions [get]
+func (h *Handler) AdminListActions(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	page, limit = normalizePagination(page, limit)
+
+	actions, err := h.apiService.Auth.ListAdminActions(c.UserContext(), models.AdminActionFilter{
+		AdminUUID:  c.Query("admin_uuid"),
+		Action:     c.Query("action"),
+		TargetType: c.Query("target_type"),
+		TargetID:   c.Query("target_id"),
+	}, page, limit)
+	if err != nil {
+		log.Printf("AdminListActions failed: %v", err)
+		return h.handleAuthError(c, err)
+	}
+	return c.JSON(actions)
+}
+
+// handleAdminAuthError — ошибки блокировки: FailedPrecondition от Auth —
+// попытка заблокировать администратора.
+func (h *Handler) handleAdminAuthError(c *fiber.Ctx, err error) error {
+	if st, ok := status.FromError(err); ok && st.Code() == codes.FailedPrecondition {
+		return c.Status(fiber.StatusConflict).JSON(models.Error{
+			Code:    "CANNOT_BAN_ADMIN",
+			Message: st.Message(),
+		})
+	}
+	return h.handleAuthError(c, err)
+}
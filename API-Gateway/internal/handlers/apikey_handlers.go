@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/models"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ListAPIKeys возвращает API-ключи компании
+// @Summary API-ключи компании
+// @Description Действующие ключи интеграций компании текущего владельца: название, начало ключа, права, срок и время последнего использования.
+// @Tags Company
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.APIKeysResponse "Ключи"
+// @Failure 401 {object} models.ErrorResponse "Не авторизован"
+// @Failure 403 {object} models.ErrorResponse "Доступно только владельцу компании"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /company/api-keys [get]
+func (h *Handler) ListAPIKeys(c *fiber.Ctx) error {
+	userID := getUserIDFromContext(c)
+
+	keys, err := h.apiService.Auth.ListAPIKeys(c.UserContext(), getTokenFromContext(c))
+	if err != nil {
+		log.Printf("API Gateway ListAPIKeys failed for user %s: %v", userID, err)
+		return h.handleAPIKeyError(c, err)
+	}
+
+	// Учётные данные не хранит ни кэш Gateway (ShouldExclude), ни браузер.
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.JSON(models.APIKeysResponse{APIKeys: keys})
+}
+
+// CreateAPIKey выпускает API-ключ компании
+// @Summary Выпуск API-ключа
+// @Description Ключ для интеграций (ATS, HRM): передаётся в заголовке X-API-Key или как "Authorization: ApiKey {key}" и открывает только маршруты своих прав. Ключ показывается один раз.
+// @Tags Company
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.CreateAPIKeyRequest true "Название, права и срок"
+// @Success 201 {object} models.APIKeyCreated "Ключ выпущен"
+// @Failure 400 {object} models.ErrorResponse "Неверное название, права или срок"
+// @Failure 401 {object} models.ErrorResponse "Не авторизован"
+// @Failure 403 {object} models.ErrorResponse "Доступно только владельцу компании"
+// @Failure 409 {object} models.ErrorResponse "Достигнут лимит ключей компании"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /company/api-keys [post]
+func (h *Handler) CreateAPIKey(c *fiber.Ctx) error {
+	userID := getUserIDFromContext(c)
+
+	var req models.CreateAPIKeyRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.Printf("CreateAPIKey failed - body parse error: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(models.Error{
+			Code:    "INVALID_REQUEST",
+			Message: "Invalid request body",
+		})
+	}
+
+	if req.Name == "" || len(req.Scopes) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(models.Error{
+			Code:    "MISSING_FIELDS",
+			Message: "name and scopes are required",
+		})
+	}
+	if req.ExpiresInDays < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(models.Error{
+			Code:    "INVALID_DATA",
+			Message: "expires_in_days must not be negative",
+		})
+	}
+
+	created, err := h.apiService.Auth.CreateAPIKey(c.UserContext(), getTokenFromContext(c), req)
+	if err != nil {
+		log.Printf("API Gateway CreateAPIKey failed for user %s: %v", userID, err)
+		return h.handleAPIKeyError(c, err)
+	}
+
+	h.recordAudit(c, AuditAPIKeyCreate, "api_key", created.ID, userID, nil, fiber.Map{
+		"name":            req.Name,
+		"scopes":          req.Scopes,
+		"expires_in_days": req.ExpiresInDays,
+	})
+	log.Printf("API key %s created by user_uuid: %s", created.ID, userID)
+	return c.Status(fiber.StatusCreated).JSON(created)
+}
+
+// RevokeAPIKey отзывает API-ключ компании
+// @Summary Отзыв API-ключа
+// @Description Ключ перестаёт приниматься сразу на всех инстансах Gateway.
+// @Tags Company
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID ключа"
+// @Success 200 {object} models.SuccessResponse "Ключ отозван"
+// @Failure 401 {object} models.ErrorResponse "Не авторизован"
+// @Failure 403 {object} models.ErrorResponse "Доступно только владельцу компании"
+// @Failure 404 {object} models.ErrorResponse "Ключ не найден"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /company/api-keys/{id} [delete]
+func (h *Handler) RevokeAPIKey(c *fiber.Ctx) error {
+	userID := getUserIDFromContext(c)
+	keyID := c.Params("id")
+
+	keyHash, err := h.apiService.Auth.RevokeAPIKey(c.UserContext(), getTokenFromContext(c), keyID)
+	if err != nil {
+		log.Printf("API Gateway RevokeAPIKey failed for user %s, key %s: %v", userID, keyID, err)
+		return h.handleAPIKeyError(c, err)
+	}
+
+	if h.verifier != nil && keyHash != "" {
+		h.verifier.InvalidateAPIKey(c.UserContext(), keyHash)
+	}
+
+	h.recordAudit(c, AuditAPIKeyRevoke, "api_key", keyID, userID, fiber.Map{"revoked": false}, fiber.Map{"revoked": true})
+	log.Printf("API key %s revoked by user_uuid: %s", keyID, userID)
+	return c.JSON(models.SuccessResponse{Message: "API key revoked"})
+}
+
+func (h *Handler) handleAPIKeyError(c *fiber.Ctx, err error) error {
+	if st, ok := status.FromError(err); ok {
+		switch st.Code() {
+		case codes.NotFound:
+			return c.Status(fiber.StatusNotFound).JSON(models.Error{
+				Code:    "API_KEY_NOT_FOUND",
+				Message: "API key not found",
+			})
+		case codes.ResourceExhausted:
+			return c.Status(fiber.StatusConflict).JSON(models.Error{
+				Code:    "API_KEY_LIMIT",
+				Message: st.Message(),
+			})
+		}
+	}
+	return h.handleAuthError(c, err)
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// apiKeyHeader — основной способ передать ключ.
+	apiKeyHeader = "X-API-Key"
+	// apiKeyScheme — альтернатива: "Authorization: ApiKey <ключ>".
+	apiKeyScheme = "ApiKey "
+)
+
+// apiKeyFromRequest достаёт API-ключ из X-API-Key или Authorization: ApiKey.
+func apiKeyFromRequest(c *fiber.Ctx) (string, bool) {
+	if key := strings.TrimSpace(c.Get(apiKeyHeader)); key != "" {
+		return key, true
+	}
+	if key, ok := strings.CutPrefix(c.Get("Authorization"), apiKeyScheme); ok && strings.TrimSpace(key) != "" {
+		return strings.TrimSpace(key), true
+	}
+	return "", false
+}
+
+// authenticateAPIKey проверяет ключ и выполняет запрос от имени владельца
+// компании под ролью ROLE_COMPANY. Какие маршруты доступны, решают права
+// ключа (scope действия в политике); access-токена у такого запроса нет.
+func authenticateAPIKey(c *fiber.Ctx, validator TokenValidator, key string) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), authValidateTimeout)
+	defer cancel()
+	info, err := validator.ValidateAPIKey(ctx, key)
+	if err != nil {
+		log.Printf("AuthMiddleware: API key validation error: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Authentication service error",
+		})
+	}
+
+	if !info.Valid {
+		log.Printf("AuthMiddleware: Invalid API key")
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Invalid API key",
+		})
+	}
+
+	log.Printf("AuthMiddleware: API key %s validated - company: %s, scopes: %v", info.KeyID, info.CompanyID, info.Scopes)
+
+	c.Locals(string(UserIDKey), info.CompanyID)
+	c.Locals(string(RoleKey), ROLE_COMPANY)
+	c.Locals(string(RolesKey), []Role{ROLE_COMPANY})
+	c.Locals(string(EmailVerifiedKey), info.EmailVerified)
+	c.Locals(string(APIKeyIDKey), info.KeyID)
+	c.Locals(string(APIKeyScopesKey), info.Scopes)
+
+	return c.Next()
+}
+
+// isAPIKeyRequest — запрос аутентифицирован API-ключом, а не JWT.
+func isAPIKeyRequest(c *fiber.Ctx) bool {
+	return getAPIKeyIDFromContext(c) != ""
+}
+
+// getAPIKeyIDFromContext возвращает id API-ключа запроса
+func getAPIKeyIDFromContext(c *fiber.Ctx) string {
+	if id, ok := c.Locals(string(APIKeyIDKey)).(string); ok {
+		return id
+	}
+	return ""
+}
+
+// getAPIKeyScopesFromContext возвращает права API-ключа запроса
+func getAPIKeyScopesFromContext(c *fiber.Ctx) []string {
+	if scopes, ok := c.Locals(string(APIKeyScopesKey)).([]string); ok {
+		return scopes
+	}
+	return nil
+}
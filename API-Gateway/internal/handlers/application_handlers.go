@@ -42,7 +42,7 @@ func (h *Handler) RespondToVacancy(c *fiber.Ctx) error {
 		}
 	}
 
-	app, err := h.apiService.Application.Apply(c.Context(), vacancyID, studentID, req.CoverLetter)
+	app, err := h.apiService.Application.Apply(c.UserContext(), vacancyID, studentID, req.CoverLetter)
 	if err != nil {
 		log.Printf("RespondToVacancy: failed: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(models.Error{
@@ -75,7 +75,7 @@ func (h *Handler) ListMyApplications(c *fiber.Ctx) error {
 	page, limit = normalizePagination(page, limit)
 	status, _ := strconv.Atoi(c.Query("status", "0"))
 
-	list, err := h.apiService.Application.ListMine(c.Context(), studentID, int32(status), int32(page), int32(limit))
+	list, err := h.apiService.Application.ListMine(c.UserContext(), studentID, int32(status), int32(page), int32(limit))
 	if err != nil {
 		log.Printf("ListMyApplications: failed: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(models.Error{
@@ -104,7 +104,7 @@ func (h *Handler) WithdrawApplication(c *fiber.Ctx) error {
 	}
 
 	id := c.Params("id")
-	if err := h.apiService.Application.Withdraw(c.Context(), id, studentID); err != nil {
+	if err := h.apiService.Application.Withdraw(c.UserContext(), id, studentID); err != nil {
 		log.Printf("WithdrawApplication: failed: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(models.Error{
 			Code:    "INTERNAL_ERROR",
@@ -130,7 +130,7 @@ func (h *Handler) ListVacancyApplications(c *fiber.Ctx) error {
 	page, limit = normalizePagination(page, limit)
 	status, _ := strconv.Atoi(c.Query("status", "0"))
 
-	list, err := h.apiService.Application.ListForVacancy(c.Context(), vacancyID, int32(status), int32(page), int32(limit))
+	list, err := h.apiService.Application.ListForVacancy(c.UserContext(), vacancyID, int32(status), int32(page), int32(limit))
 	if err != nil {
 		log.Printf("ListVacancyApplications: failed: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(models.Error{
@@ -144,9 +144,9 @@ func (h *Handler) ListVacancyApplications(c *fiber.Ctx) error {
 // ReviewApplication — HR принимает решение по отклику.
 // PATCH /api/v1/hr/applications/:id  body: { decision: 2|3, comment?: string }
 //
-// Авторизация: HR может рассматривать только отклики на вакансии своей
-// (APPROVED-membership) компании; COMPANY_OWNER — только на вакансии своей
-// компании. Без этой проверки любой HR мог принимать/отклонять чужие отклики.
+// Авторизация — политика application.review: HR может рассматривать только
+// отклики на вакансии своей (APPROVED-membership) компании; COMPANY_OWNER —
+// только на вакансии своей компании.
 //
 // После успешного review комментарий HR (если непустой) дублируется в чат
 // треда `application:<id>` — кандидат увидит решение в «Сообщениях», иначе
@@ -160,7 +160,6 @@ func (h *Handler) ReviewApplication(c *fiber.Ctx) error {
 	}
 	id := c.Params("id")
 	userID := getUserIDFromContext(c)
-	role := getRoleFromContext(c)
 
 	var req models.ApplicationReviewRequest
 	if err := c.BodyParser(&req); err != nil {
@@ -176,43 +175,10 @@ func (h *Handler) ReviewApplication(c *fiber.Ctx) error {
 		})
 	}
 
-	// Авторизация: тянем отклик → его вакансию → company_id; сверяем с moей
-	// membership (status=APPROVED, 2). DEVELOPER пропускаем — для smoke-сценариев.
-	if role != ROLE_DEVELOPER {
-		existing, err := h.apiService.Application.Get(c.Context(), id)
-		if err != nil || existing == nil {
-			return c.Status(fiber.StatusNotFound).JSON(models.Error{
-				Code:    "NOT_FOUND",
-				Message: "Application not found",
-			})
-		}
-		vac, err := h.apiService.Vacancy.GetVacancy(c.Context(), existing.VacancyID)
-		if err != nil || vac == nil {
-			return c.Status(fiber.StatusNotFound).JSON(models.Error{
-				Code:    "NOT_FOUND",
-				Message: "Vacancy not found",
-			})
-		}
-		allowed := false
-		switch role {
-		case ROLE_COMPANY:
-			// owner.userID == owner.companyID по соглашению Company-сервиса.
-			allowed = vac.CompanyID == userID
-		case ROLE_HR:
-			if ms, mErr := h.apiService.Company.GetMembershipByUser(c.Context(), userID); mErr == nil && ms != nil {
-				allowed = ms.Status == 2 && ms.CompanyID == vac.CompanyID
-			}
-		}
-		if !allowed {
-			log.Printf("ReviewApplication: forbidden — user=%s role=%s app=%s vacCompany=%s", userID, role, id, vac.CompanyID)
-			return c.Status(fiber.StatusForbidden).JSON(models.Error{
-				Code:    "FORBIDDEN",
-				Message: "Эта вакансия не относится к вашей компании",
-			})
-		}
-	}
+	// Отклик до решения — для журнала аудита; сбой чтения ревью не блокирует.
+	before, _ := h.apiService.Application.Get(c.UserContext(), id)
 
-	app, err := h.apiService.Application.UpdateStatus(c.Context(), id, req.Decision, req.Comment)
+	app, err := h.apiService.Application.UpdateStatus(c.UserContext(), id, req.Decision, req.Comment)
 	if err != nil {
 		log.Printf("ReviewApplication: failed: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(models.Error{
@@ -221,6 +187,12 @@ func (h *Handler) ReviewApplication(c *fiber.Ctx) error {
 		})
 	}
 
+	var companyID string
+	if vacancy, vErr := h.apiService.Vacancy.GetVacancy(c.UserContext(), app.VacancyID); vErr == nil && vacancy != nil {
+		companyID = vacancy.CompanyID
+	}
+	h.recordAudit(c, AuditApplicationReview, "application", id, companyID, before, app)
+
 	// Комментарий HR — в чат треда. Без блокировки на ошибку: статус уже обновлён,
 	// чат — best-effort UX (если упадёт — отклик всё равно закрыт корректно).
 	if comment := strings.TrimSpace(req.Comment); comment != "" {
@@ -231,7 +203,7 @@ func (h *Handler) ReviewApplication(c *fiber.Ctx) error {
 		} else if req.Decision == 3 {
 			body = "✗ Отклик отклонён. " + comment
 		}
-		if _, sErr := h.apiService.Chat.SendMessage(c.Context(), threadID, userID, body); sErr != nil {
+		if _, sErr := h.apiService.Chat.SendMessage(c.UserContext(), threadID, userID, body); sErr != nil {
 			log.Printf("ReviewApplication: chat message failed (non-fatal): %v", sErr)
 		}
 	}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/models"
+)
+
+// Действия чтения журнала аудита (configs/policy.yaml).
+const (
+	ActionCompanyAudit   = "company.audit"
+	ActionAdminAuditList = "admin.audit.list"
+)
+
+// Действия, которые пишутся в журнал аудита.
+const (
+	AuditVacancyModerate    = "vacancy.moderate"
+	AuditApplicationReview  = "application.review"
+	AuditMembershipReview   = "membership.review"
+	AuditSubmissionReview   = "submission.review"
+	AuditAchievementReview  = "achievement.review"
+	AuditMFAEnable          = "account.mfa.enable"
+	AuditMFADisable         = "account.mfa.disable"
+	AuditRecoveryRegenerate = "account.mfa.recovery_codes"
+	AuditSessionRevoke      = "account.session.revoke"
+	AuditSessionsRevoke     = "account.sessions.revoke_others"
+	AuditAccountDelete      = "account.delete"
+	AuditAPIKeyCreate       = "company.api_key.create"
+	AuditAPIKeyRevoke       = "company.api_key.revoke"
+)
+
+// auditTimeout — сколько ждём Auth при записи события; ответ клиенту к этому
+// моменту уже определён.
+const auditTimeout = 3 * time.Second
+
+// recordAudit пишет успешное действие в журнал аудита: кто (uuid и роль из
+// токена), откуда (IP), над чем и что изменилось. before/after — состояние
+// ресурса до и после (любые JSON-сериализуемые значения, nil — неизвестно);
+// в журнал попадают только различающиеся поля. Ошибка записи не меняет
+// ответ, но попадает в лог.
+func (h *Handler) recordAudit(c *fiber.Ctx, action, resourceType, resourceID, companyID string, before, after any) {
+	h.recordAuditEvent(c, models.AuditEvent{
+		ActorUUID:    getUserIDFromContext(c),
+		ActorRole:    string(getRoleFromContext(c)),
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		CompanyID:    companyID,
+	}, before, after)
+}
+
+// recordAuditEvent — то же для маршрутов без токена в контексте (вход с
+// mfa_token): исполнителя задаёт вызывающий.
+func (h *Handler) recordAuditEvent(c *fiber.Ctx, event models.AuditEvent, before, after any) {
+	event.Changes = auditChanges(before, after)
+	event.IP = clientIP(c)
+
+	// Действие уже выполнено: отмена запроса клиентом не должна терять запись.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(c.UserContext()), auditTimeout)
+	defer cancel()
+	if err := h.apiService.Auth.RecordAuditEvent(ctx, event); err != nil {
+		log.Printf("recordAudit: failed to record %s by %s on %s %q: %v",
+			event.Action, event.ActorUUID, event.ResourceType, event.ResourceID, err)
+	}
+}
+
+// auditChanges — JSON-объект {"поле": {"before": x, "after": y}} по полям,
+// которые различаются в JSON-представлениях before и after.
+func auditChanges(before, after any) string {
+	was, now := auditFields(before), auditFields(after)
+
+	changes := make(map[string]map[string]any)
+	for field, value := range now {
+		if old, ok := was[field]; !ok || !reflect.DeepEqual(old, value) {
+			changes[field] = map[string]any{"before": was[field], "after": value}
+		}
+	}
+	for field, old := range was {
+		if _, ok := now[field]; !ok {
+			changes[field] = map[string]any{"before": old, "after": nil}
+		}
+	}
+
+	out, err := json.Marshal(changes)
+	if err != nil {
+		return "{}"
+	}
+	return string(out)
+}
+
+func auditFields(v any) map[string]any {
+	fields := make(map[string]any)
+	if rv := reflect.ValueOf(v); !rv.IsValid() || rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return fields
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fields
+	}
+	_ = json.Unmarshal(raw, &fields)
+	return fields
+}
+
+// CompanyAuditLog возвращает журнал аудита компании владельца
+// @Summary Журнал аудита компании
+// @Description Кто и когда рассмотрел отклики, вакансии, заявки HR и решения по задачам компании. Новые первыми.
+// @Tags Company
+// @Produce json
+// @Security BearerAuth
+// @Param actor_uuid query string false "UUID исполнителя"
+// @Param action query string false "Действие (application.review, membership.review...)"
+// @Param resource_type query string false "Тип ресурса (application, vacancy, membership...)"
+// @Param resource_id query string false "ID ресурса"
+// @Param since query string false "С момента (RFC3339)"
+// @Param until query string false "До момента (RFC3339, не включая)"
+// @Param page query int false "Номер страницы" default(1) minimum(1)
+// @Param limit query int false "Количество элементов на странице" default(10) minimum(1) maximum(100)
+// @Success 200 {object} models.AuditEventList "Журнал"
+// @Failure 400 {object} models.ErrorResponse "Неверные параметры запроса"
+// @Failure 401 {object} models.ErrorResponse "Не авторизован"
+// @Failure 403 {object} models.ErrorResponse "Только для владельца компании"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /company/audit [get]
+func (h *Handler) CompanyAuditLog(c *fiber.Ctx) error {
+	// owner.userID == owner.companyID по соглашению Company-сервиса.
+	companyID := getUserIDFromContext(c)
+	if companyID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(models.Error{
+			Code:    "UNAUTHORIZED",
+			Message: "User not authenticated",
+		})
+	}
+	return h.listAuditEvents(c, companyID)
+}
+
+// AdminAuditLog возвращает журнал аудита всей платформы
+// @Summary Журнал аудита платформы
+// @Description Все записи журнала аудита, новые первыми; фильтры по компании, исполнителю, действию, ресурсу и времени.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param company_id query string false "ID компании"
+// @Param actor_uuid query string false "UUID исполнителя"
+// @Param action query string false "Действие (application.review, account.mfa.disable...)"
+// @Param resource_type query string false "Тип ресурса (application, vacancy, account...)"
+// @Param resource_id query string false "ID ресурса"
+// @Param since query string false "С момента (RFC3339)"
+// @Param until query string false "До момента (RFC3339, не включая)"
+// @Param page query int false "Номер страницы" default(1) minimum(1)
+// @Param limit query int false "Количество элементов на странице" default(10) minimum(1) maximum(100)
+// @Success 200 {object} models.AuditEventList "Журнал"
+// @Failure 400 {object} models.ErrorResponse "Неверные параметры запроса"
+// @Failure 401 {object} models.ErrorResponse "Не авторизован"
+// @Failure 403 {object} models.ErrorResponse "Только для администраторов"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /admin/audit [get]
+func (h *Handler) AdminAuditLog(c *fiber.Ctx) error {
+	return h.listAuditEvents(c, c.Query("company_id"))
+}
+
+func (h *Handler) listAuditEvents(c *fiber.Ctx, companyID string) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	page, limit = normalizePagination(page, limit)
+
+	filter := models.AuditFilter{
+		CompanyID:    companyID,
+		ActorUUID:    c.Query("actor_uuid"),
+		Action:       c.Query("action"),
+		ResourceType: c.Query("resource_type"),
+		ResourceID:   c.Query("resource_id"),
+		Since:        c.Query("since"),
+		Until:        c.Query("until"),
+	}
+	for _, value := range []string{filter.Since, filter.Until} {
+		if value == "" {
+			continue
+		}
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(models.Error{
+				Code:    "INVALID_DATA",
+				Message: "since and until must be RFC3339",
+			})
+		}
+	}
+
+	events, err := h.apiService.Auth.ListAuditEvents(c.UserContext(), filter, page, limit)
+	if err != nil {
+		log.Printf("listAuditEvents failed: %v", err)
+		return h.handleAuthError(c, err)
+	}
+	return c.JSON(events)
+}
@@ -1,18 +1,23 @@
 package handlers
 
 import (
-	"context"
-	usersv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/users/v1"
+	"errors"
 	"github.com/gofiber/fiber/v2"
 	"github.com/studjobs/hh_for_students/api-gateway/internal/models"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/registration"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"log"
+	"math"
+	"slices"
+	"strconv"
+	"time"
 )
 
 // Login обрабатывает вход пользователя
 // @Summary Аутентификация пользователя
-// @Description Выполняет вход пользователя в систему и возвращает JWT токен
+// @Description Выполняет вход пользователя в систему и возвращает JWT токен. Если у аккаунта включён TOTP (или он обязателен для роли), вместо токенов возвращается mfa_token для /auth/mfa/verify (/auth/mfa/enroll).
 // @Tags Auth
 // @Accept json
 // @Produce json
@@ -20,6 +25,8 @@ import (
 // @Success 200 {object} models.AuthResponse "Успешная аутентификация"
 // @Failure 400 {object} models.ErrorResponse "Неверный запрос"
 // @Failure 401 {object} models.ErrorResponse "Неверные учетные данные"
+// @Failure 423 {object} models.ErrorResponse "Аккаунт временно заблокирован после неудачных попыток (Retry-After)"
+// @Failure 429 {object} models.ErrorResponse "Слишком много неудачных попыток с этого адреса (Retry-After)"
 // @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
 // @Router /auth/login [post]
 func (h *Handler) Login(c *fiber.Ctx) error {
@@ -45,28 +52,39 @@ func (h *Handler) Login(c *fiber.Ctx) error {
 
 	log.Printf("Calling API Gateway Login for email: %s, role: %s", req.Email, req.Role)
 
-	ctx := context.Background()
-	resp, err := h.apiService.Auth.Login(ctx, req.Email, req.Password, req.Role)
+	resp, err := h.apiService.Auth.Login(clientContext(c), req.Email, req.Password, req.Role)
 
 	if err != nil {
 		log.Printf("API Gateway Login failed for email %s: %v", req.Email, err)
 		return h.handleAuthError(c, err)
 	}
 
+	if resp.MFARequired {
+		log.Printf("Login for email %s requires second factor (enrollment=%t)", req.Email, resp.MFAEnrollmentRequired)
+		return c.JSON(resp)
+	}
+
 	log.Printf("Login successful for email: %s, user_uuid: %s", req.Email, resp.UserUUID)
 	return c.JSON(resp)
 }
 
+// idempotencyKeyHeader — заголовок, по которому повтор регистрации (например,
+// после обрыва соединения) узнаётся и не создаёт второй аккаунт.
+const idempotencyKeyHeader = "Idempotency-Key"
+
 // Register обрабатывает регистрацию пользователя
 // @Summary Регистрация нового пользователя
-// @Description Создает нового пользователя и возвращает JWT токен
+// @Description Создает нового пользователя (аккаунт, профиль и компанию владельца) и возвращает JWT токен. Если email уже зарегистрирован и пароль совпадает, аккаунту добавляется новая роль. Если один из шагов не удался, созданное удаляется. Повтор с тем же Idempotency-Key и паролем возвращает результат первой попытки.
 // @Tags Auth
 // @Accept json
 // @Produce json
+// @Param Idempotency-Key header string false "Ключ идемпотентности: повтор с тем же ключом не создаёт второй аккаунт"
 // @Param request body models.SignUpRequest true "Данные для регистрации"
 // @Success 201 {object} models.AuthResponse "Пользователь успешно создан"
-// @Failure 400 {object} models.ErrorResponse "Неверный запрос"
-// @Failure 409 {object} models.ErrorResponse "Пользователь уже существует"
+// @Failure 400 {object} models.ErrorResponse "Неверный запрос или пароль не соответствует политике (WEAK_PASSWORD, BREACHED_PASSWORD)"
+// @Failure 409 {object} models.ErrorResponse "Пользователь уже существует или регистрация с этим ключом ещё идёт (REGISTRATION_IN_PROGRESS)"
+// @Failure 422 {object} models.ErrorResponse "Ключ идемпотентности уже использован для другой регистрации (IDEMPOTENCY_KEY_REUSED)"
+// @Failure 423 {object} models.ErrorResponse "Аккаунт временно заблокирован после неудачных попыток (Retry-After)"
 // @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
 // @Router /auth/register [post]
 func (h *Handler) Register(c *fiber.Ctx) error {
@@ -90,72 +108,376 @@ func (h *Handler) Register(c *fiber.Ctx) error {
 		})
 	}
 
-	if len(req.Password) < 6 {
-		log.Printf("Register failed - weak password for email: %s", req.Email)
+	log.Printf("Calling registration for email: %s, role: %s", req.Email, req.Role)
+
+	resp, err := h.registration.Register(clientContext(c), c.Get(idempotencyKeyHeader), req.Email, req.Password, req.Role)
+
+	if err != nil {
+		log.Printf("Registration failed for email %s: %v", req.Email, err)
+		return h.handleRegistrationError(c, err)
+	}
+
+	log.Printf("Register successful for email: %s, user_uuid: %s", req.Email, resp.UserUUID)
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+// handleRegistrationError — ошибки повтора по ключу идемпотентности; прочие
+// пришли из сервисов и разбираются как ошибки Auth.
+func (h *Handler) handleRegistrationError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, registration.ErrInProgress):
+		return c.Status(fiber.StatusConflict).JSON(models.Error{
+			Code:    "REGISTRATION_IN_PROGRESS",
+			Message: "Registration with this idempotency key is still in progress",
+		})
+	case errors.Is(err, registration.ErrKeyReused):
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(models.Error{
+			Code:    "IDEMPOTENCY_KEY_REUSED",
+			Message: "Idempotency key was already used for a different request",
+		})
+	default:
+		return h.handleAuthError(c, err)
+	}
+}
+
+// Refresh обменивает refresh-токен на новую пару токенов
+// @Summary Обновление токенов
+// @Description Принимает refresh-токен и возвращает новый access-токен и новый refresh-токен. Старый refresh-токен гасится; его повторное предъявление отзывает все токены этой сессии.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body models.RefreshRequest true "Refresh-токен"
+// @Success 200 {object} models.AuthResponse "Новая пара токенов"
+// @Failure 400 {object} models.ErrorResponse "Неверный запрос"
+// @Failure 401 {object} models.ErrorResponse "Refresh-токен недействителен"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /auth/refresh [post]
+func (h *Handler) Refresh(c *fiber.Ctx) error {
+	var req models.RefreshRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		log.Printf("Refresh failed - body parse error: %v", err)
 		return c.Status(fiber.StatusBadRequest).JSON(models.Error{
-			Code:    "WEAK_PASSWORD",
-			Message: "Password must be at least 6 characters long",
+			Code:    "INVALID_REQUEST",
+			Message: "Invalid request body",
 		})
 	}
 
-	log.Printf("Calling API Gateway Register for email: %s, role: %s", req.Email, req.Role)
+	if req.RefreshToken == "" {
+		log.Printf("Refresh failed - missing refresh token")
+		return c.Status(fiber.StatusBadRequest).JSON(models.Error{
+			Code:    "MISSING_FIELDS",
+			Message: "Refresh token is required",
+		})
+	}
+
+	resp, err := h.apiService.Auth.Refresh(clientContext(c), req.RefreshToken)
+	if err != nil {
+		log.Printf("API Gateway Refresh failed: %v", err)
+		// handleAuthError на Unauthenticated пишет про email/пароль — здесь это сбивает с толку.
+		if status.Code(err) == codes.Unauthenticated {
+			return c.Status(fiber.StatusUnauthorized).JSON(models.Error{
+				Code:    "INVALID_REFRESH_TOKEN",
+				Message: "Refresh token is invalid or expired",
+			})
+		}
+		return h.handleAuthError(c, err)
+	}
+
+	log.Printf("Refresh successful for user_uuid: %s", resp.UserUUID)
+	return c.JSON(resp)
+}
 
-	resp, err := h.apiService.Auth.Register(c.Context(), req.Email, req.Password, req.Role)
+// Logout завершает сессию пользователя
+// @Summary Выход из системы
+// @Description Отзывает текущий access-токен (и refresh-токен, если передан). С all_sessions=true отзывает все токены пользователя на всех устройствах.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.LogoutRequest false "Параметры выхода"
+// @Success 200 {object} models.SuccessResponse "Выход выполнен"
+// @Failure 400 {object} models.ErrorResponse "Неверный запрос"
+// @Failure 401 {object} models.ErrorResponse "Неавторизованный доступ"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /auth/logout [post]
+func (h *Handler) Logout(c *fiber.Ctx) error {
+	var req models.LogoutRequest
+
+	// Тело опционально: пустой POST /auth/logout — выход из текущей сессии.
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			log.Printf("Logout failed - body parse error: %v", err)
+			return c.Status(fiber.StatusBadRequest).JSON(models.Error{
+				Code:    "INVALID_REQUEST",
+				Message: "Invalid request body",
+			})
+		}
+	}
 
+	userID := getUserIDFromContext(c)
+	token := getTokenFromContext(c)
+	if token == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(models.Error{
+			Code:    "UNAUTHORIZED",
+			Message: "User not authenticated",
+		})
+	}
+
+	if err := h.apiService.Auth.Logout(c.UserContext(), token, req.RefreshToken, req.AllSessions); err != nil {
+		log.Printf("API Gateway Logout failed for user %s: %v", userID, err)
+		return h.handleAuthError(c, err)
+	}
+
+	// Сбрасываем кэш статуса токенов, иначе отозванный токен жил бы до конца TTL.
+	if h.verifier != nil {
+		if req.AllSessions {
+			h.verifier.InvalidateUser(c.UserContext(), userID)
+		} else {
+			h.verifier.InvalidateToken(c.UserContext(), token)
+		}
+	}
+
+	log.Printf("Logout successful for user_uuid: %s (all_sessions=%t)", userID, req.AllSessions)
+	return c.JSON(models.SuccessResponse{Message: "Logged out"})
+}
+
+// SwitchRole переключает активную роль
+// @Summary Смена активной роли
+// @Description Выпускает новую пару токенов под другой ролью того же аккаунта. Текущий access-токен (и refresh-токен, если передан) отзывается.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.SwitchRoleRequest true "Новая активная роль"
+// @Success 200 {object} models.AuthResponse "Токены под новой ролью"
+// @Failure 400 {object} models.ErrorResponse "Неверный запрос"
+// @Failure 401 {object} models.ErrorResponse "Не авторизован"
+// @Failure 403 {object} models.ErrorResponse "Роль не выдана аккаунту"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /auth/switch-role [post]
+func (h *Handler) SwitchRole(c *fiber.Ctx) error {
+	var req models.SwitchRoleRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		log.Printf("SwitchRole failed - body parse error: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(models.Error{
+			Code:    "INVALID_REQUEST",
+			Message: "Invalid request body",
+		})
+	}
+
+	role, ok := parseRole(req.Role)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(models.Error{
+			Code:    "INVALID_ROLE",
+			Message: "Unknown role",
+		})
+	}
+
+	// Набор ролей уже пришёл вместе с токеном — явно чужую роль отсекаем без
+	// похода в Auth. Окончательно проверяет Auth.
+	if roles := getRolesFromContext(c); len(roles) > 0 && !slices.Contains(roles, role) {
+		return c.Status(fiber.StatusForbidden).JSON(models.Error{
+			Code:    "ROLE_NOT_ASSIGNED",
+			Message: "Role is not assigned to this account",
+		})
+	}
+
+	userID := getUserIDFromContext(c)
+	token := getTokenFromContext(c)
+	if token == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(models.Error{
+			Code:    "UNAUTHORIZED",
+			Message: "User not authenticated",
+		})
+	}
+
+	resp, err := h.apiService.Auth.SwitchRole(clientContext(c), token, req.RefreshToken, h.roleConvert(role))
 	if err != nil {
-		log.Printf("API Gateway Register failed for email %s: %v", req.Email, err)
+		log.Printf("API Gateway SwitchRole failed for user %s: %v", userID, err)
+		if status.Code(err) == codes.PermissionDenied {
+			return c.Status(fiber.StatusForbidden).JSON(models.Error{
+				Code:    "ROLE_NOT_ASSIGNED",
+				Message: "Role is not assigned to this account",
+			})
+		}
 		return h.handleAuthError(c, err)
 	}
 
-	if resp.Role == "ROLE_COMPANY_OWNER" {
-		// До прохождения onboarding owner ещё не задал название компании.
-		// Пишем нейтральный плейсхолдер вместо буквального "new" (B7) — фронт
-		// рендерит его курсивом как «надо заполнить».
-		if _, err = h.apiService.Company.CreateCompany(c.Context(), &models.Company{
-			ID:   resp.UserUUID,
-			Name: "Без названия",
-		}); err != nil {
-			log.Printf("API Gateway Create failed for email %s: %v", req.Email, err)
-			if err2 := h.apiService.Auth.DeleteUser(c.Context(), resp.UserUUID); err2 != nil {
-				log.Printf("API Gateway LogOut company for email %s: %v", req.Email, err2)
-			}
-			return h.handleAuthError(c, err)
+	// Роль требует подключить TOTP: сессия не менялась, клиент получает mfa_token.
+	if resp.MFARequired {
+		log.Printf("SwitchRole for user %s requires TOTP enrollment", userID)
+		return c.JSON(resp)
+	}
+
+	// Старый токен отозван в Auth — сбрасываем его из кэша статусов.
+	if h.verifier != nil {
+		h.verifier.InvalidateToken(c.UserContext(), token)
+	}
+
+	log.Printf("SwitchRole successful for user_uuid: %s, role: %s", resp.UserUUID, resp.Role)
+	return c.JSON(resp)
+}
+
+// RequestPasswordReset отправляет ссылку для сброса пароля
+// @Summary Запрос сброса пароля
+// @Description Отправляет на email одноразовую ссылку для сброса пароля. Ответ одинаковый независимо от того, зарегистрирован ли адрес.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body models.PasswordResetRequest true "Email пользователя"
+// @Success 202 {object} models.SuccessResponse "Запрос принят"
+// @Failure 400 {object} models.ErrorResponse "Неверный запрос"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /auth/password/reset [post]
+func (h *Handler) RequestPasswordReset(c *fiber.Ctx) error {
+	var req models.PasswordResetRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		log.Printf("RequestPasswordReset failed - body parse error: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(models.Error{
+			Code:    "INVALID_REQUEST",
+			Message: "Invalid request body",
+		})
+	}
+
+	if req.Email == "" {
+		log.Printf("RequestPasswordReset failed - missing email")
+		return c.Status(fiber.StatusBadRequest).JSON(models.Error{
+			Code:    "MISSING_FIELDS",
+			Message: "Email is required",
+		})
+	}
+
+	if err := h.apiService.Auth.RequestPasswordReset(c.UserContext(), req.Email); err != nil {
+		log.Printf("API Gateway RequestPasswordReset failed for email %s: %v", req.Email, err)
+		return h.handleAuthError(c, err)
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(models.SuccessResponse{
+		Message: "If the email is registered, a reset link has been sent",
+	})
+}
+
+// ConfirmPasswordReset задаёт новый пароль по токену из письма
+// @Summary Подтверждение сброса пароля
+// @Description Меняет пароль по одноразовому токену из письма и завершает все активные сессии пользователя.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body models.PasswordResetConfirmRequest true "Токен и новый пароль"
+// @Success 200 {object} models.SuccessResponse "Пароль изменён"
+// @Failure 400 {object} models.ErrorResponse "Неверный запрос или пароль не соответствует политике (WEAK_PASSWORD, BREACHED_PASSWORD)"
+// @Failure 401 {object} models.ErrorResponse "Токен недействителен или истёк"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /auth/password/reset/confirm [post]
+func (h *Handler) ConfirmPasswordReset(c *fiber.Ctx) error {
+	var req models.PasswordResetConfirmRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		log.Printf("ConfirmPasswordReset failed - body parse error: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(models.Error{
+			Code:    "INVALID_REQUEST",
+			Message: "Invalid request body",
+		})
+	}
+
+	if req.Token == "" || req.NewPassword == "" {
+		log.Printf("ConfirmPasswordReset failed - missing fields")
+		return c.Status(fiber.StatusBadRequest).JSON(models.Error{
+			Code:    "MISSING_FIELDS",
+			Message: "Token and new password are required",
+		})
+	}
+
+	if err := h.apiService.Auth.ConfirmPasswordReset(c.UserContext(), req.Token, req.NewPassword); err != nil {
+		log.Printf("API Gateway ConfirmPasswordReset failed: %v", err)
+		if status.Code(err) == codes.Unauthenticated {
+			return c.Status(fiber.StatusUnauthorized).JSON(models.Error{
+				Code:    "INVALID_RESET_TOKEN",
+				Message: "Reset token is invalid or expired",
+			})
 		}
-		// Заодно создаём Profile-stub: иначе /u/<owner_uuid> отдаёт 404
-		// (студент в чате кликает «Профиль кандидата» → пусто).
-		if _, perr := h.apiService.User.CreateUser(c.Context(), &usersv1.NewProfileRequest{
-			Profile: &usersv1.Profile{
-				Id:    resp.UserUUID,
-				Email: req.Email,
-				Age:   18,
-				Role:  resp.Role,
-			},
-		}); perr != nil {
-			log.Printf("API Gateway: profile-stub for owner %s failed: %v", req.Email, perr)
-			// не блокируем регистрацию — профиль не критичен, owner и без него работает
+		return h.handleAuthError(c, err)
+	}
+
+	log.Printf("ConfirmPasswordReset successful")
+	return c.JSON(models.SuccessResponse{Message: "Password has been reset"})
+}
+
+// ConfirmEmail подтверждает email по токену из письма
+// @Summary Подтверждение email
+// @Description Подтверждает адрес по одноразовому токену из письма. После этого снимаются ограничения политики верификации.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body models.ConfirmEmailRequest true "Токен из письма"
+// @Success 200 {object} models.SuccessResponse "Email подтверждён"
+// @Failure 400 {object} models.ErrorResponse "Неверный запрос"
+// @Failure 401 {object} models.ErrorResponse "Токен недействителен или истёк"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /auth/email/verify [post]
+func (h *Handler) ConfirmEmail(c *fiber.Ctx) error {
+	var req models.ConfirmEmailRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		log.Printf("ConfirmEmail failed - body parse error: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(models.Error{
+			Code:    "INVALID_REQUEST",
+			Message: "Invalid request body",
+		})
+	}
+
+	if req.Token == "" {
+		log.Printf("ConfirmEmail failed - missing token")
+		return c.Status(fiber.StatusBadRequest).JSON(models.Error{
+			Code:    "MISSING_FIELDS",
+			Message: "Token is required",
+		})
+	}
+
+	if err := h.apiService.Auth.ConfirmEmail(c.UserContext(), req.Token); err != nil {
+		log.Printf("API Gateway ConfirmEmail failed: %v", err)
+		if status.Code(err) == codes.Unauthenticated {
+			return c.Status(fiber.StatusUnauthorized).JSON(models.Error{
+				Code:    "INVALID_VERIFICATION_TOKEN",
+				Message: "Verification token is invalid or expired",
+			})
 		}
-	} else {
-		if _, err = h.apiService.User.CreateUser(c.Context(), &usersv1.NewProfileRequest{
-			Profile: &usersv1.Profile{
-				Id:    resp.UserUUID,
-				Email: req.Email,
-				Age:   18,
-				Role:  resp.Role,
-			},
-		}); err != nil {
-			log.Printf("API Gateway Create failed for email %s: %v", req.Email, err)
-
-			if err1 := h.apiService.Auth.DeleteUser(c.Context(), resp.UserUUID); err1 != nil {
-				log.Printf("API Gateway LogOut failed for email %s: %v", req.Email, err1)
-				return h.handleAuthError(c, err)
-			}
+		return h.handleAuthError(c, err)
+	}
+
+	log.Printf("ConfirmEmail successful")
+	return c.JSON(models.SuccessResponse{Message: "Email confirmed"})
+}
 
-			return h.handleAuthError(c, err)
+// ResendVerification повторно отправляет письмо с подтверждением
+// @Summary Повторная отправка подтверждения email
+// @Description Отправляет новую ссылку подтверждения на email текущего пользователя; предыдущие ссылки перестают действовать.
+// @Tags Auth
+// @Produce json
+// @Security BearerAuth
+// @Success 202 {object} models.SuccessResponse "Письмо отправлено"
+// @Failure 401 {object} models.ErrorResponse "Неавторизованный доступ"
+// @Failure 409 {object} models.ErrorResponse "Email уже подтверждён"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /auth/email/verify/resend [post]
+func (h *Handler) ResendVerification(c *fiber.Ctx) error {
+	userID := getUserIDFromContext(c)
+
+	if err := h.apiService.Auth.ResendVerification(c.UserContext(), userID); err != nil {
+		log.Printf("API Gateway ResendVerification failed for user %s: %v", userID, err)
+		if status.Code(err) == codes.FailedPrecondition {
+			return c.Status(fiber.StatusConflict).JSON(models.Error{
+				Code:    "EMAIL_ALREADY_VERIFIED",
+				Message: "Email is already verified",
+			})
 		}
+		return h.handleAuthError(c, err)
 	}
 
-	log.Printf("Register successful for email: %s, user_uuid: %s", req.Email, resp.UserUUID)
-	return c.Status(fiber.StatusCreated).JSON(resp)
+	return c.Status(fiber.StatusAccepted).JSON(models.SuccessResponse{Message: "Verification email sent"})
 }
 
 // ParseToken проверяет валидность токена
@@ -186,18 +508,83 @@ func (h *Handler) parseToken(c *fiber.Ctx) error {
 
 	log.Printf("ParseToken attempt for token: %s...", token[:min(10, len(token))])
 
-	valid, userUUID, role, err := h.apiService.Auth.ValidateToken(context.Background(), token)
+	info, err := h.apiService.Auth.ValidateToken(c.UserContext(), token)
 	if err != nil {
 		log.Printf("ParseToken failed: %v", err)
 		return h.handleAuthError(c, err)
 	}
 
-	log.Printf("ParseToken result - valid: %t, user_uuid: %s, role: %s", valid, userUUID, role)
+	log.Printf("ParseToken result - valid: %t, user_uuid: %s, role: %s", info.Valid, info.UserUUID, info.Role)
 
 	return c.JSON(fiber.Map{
-		"valid":     valid,
-		"user_uuid": userUUID,
-		"role":      role,
+		"valid":          info.Valid,
+		"user_uuid":      info.UserUUID,
+		"role":           info.Role,
+		"roles":          info.Roles,
+		"email_verified": info.EmailVerified,
+	})
+}
+
+// UnlockAccount снимает блокировку входа
+// @Summary Разблокировка аккаунта
+// @Description Снимает временную блокировку входа, выставленную после серии неудачных попыток. Пользователь может сделать то же сам через сброс пароля.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.UnlockAccountRequest true "Email аккаунта"
+// @Success 200 {object} models.SuccessResponse "Блокировка снята"
+// @Failure 400 {object} models.ErrorResponse "Неверный запрос"
+// @Failure 403 {object} models.ErrorResponse "Недостаточно прав"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /auth/unlock [post]
+func (h *Handler) UnlockAccount(c *fiber.Ctx) error {
+	var req models.UnlockAccountRequest
+
+	if err := c.BodyParser(&req); err != nil || req.Email == "" {
+		log.Printf("UnlockAccount failed - invalid body: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(models.Error{
+			Code:    "INVALID_REQUEST",
+			Message: "Email is required",
+		})
+	}
+
+	if err := h.apiService.Auth.UnlockAccount(c.UserContext(), req.Email); err != nil {
+		log.Printf("API Gateway UnlockAccount failed for email %s: %v", req.Email, err)
+		return h.handleAuthError(c, err)
+	}
+
+	log.Printf("UnlockAccount successful for email: %s by user %s", req.Email, getUserIDFromContext(c))
+	return c.JSON(models.SuccessResponse{Message: "Account unlocked"})
+}
+
+// handleLoginLocked отвечает на блокировку входа из Auth: 423 для аккаунта,
+// 429 для IP, в обоих случаях с Retry-After.
+func (h *Handler) handleLoginLocked(c *fiber.Ctx, st *status.Status) error {
+	reason := ""
+	var retryAfter time.Duration
+	for _, d := range st.Details() {
+		switch info := d.(type) {
+		case *errdetails.ErrorInfo:
+			reason = info.Reason
+		case *errdetails.RetryInfo:
+			retryAfter = info.GetRetryDelay().AsDuration()
+		}
+	}
+
+	if retryAfter > 0 {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	}
+
+	if reason == "ACCOUNT_LOCKED" {
+		return c.Status(fiber.StatusLocked).JSON(models.Error{
+			Code:    "ACCOUNT_LOCKED",
+			Message: "Account is temporarily locked after failed login attempts",
+		})
+	}
+	return c.Status(fiber.StatusTooManyRequests).JSON(models.Error{
+		Code:    "TOO_MANY_ATTEMPTS",
+		Message: "Too many failed login attempts, try again later",
 	})
 }
 
@@ -226,8 +613,16 @@ func (h *Handler) handleAuthError(c *fiber.Ctx, err error) error {
 			Message: "User with this email already exists",
 		})
 	case codes.InvalidArgument:
+		// Причину (например WEAK_PASSWORD или BREACHED_PASSWORD) Auth кладёт
+		// в ErrorInfo, пояснение — в сообщение.
+		code := "INVALID_DATA"
+		for _, d := range st.Details() {
+			if info, ok := d.(*errdetails.ErrorInfo); ok && info.Reason != "" {
+				code = info.Reason
+			}
+		}
 		return c.Status(fiber.StatusBadRequest).JSON(models.Error{
-			Code:    "INVALID_DATA",
+			Code:    code,
 			Message: st.Message(),
 		})
 	case codes.NotFound:
@@ -236,10 +631,21 @@ func (h *Handler) handleAuthError(c *fiber.Ctx, err error) error {
 			Message: "Resource not found",
 		})
 	case codes.PermissionDenied:
+		// Заблокированный администратором аккаунт — причина ACCOUNT_BANNED.
+		for _, d := range st.Details() {
+			if info, ok := d.(*errdetails.ErrorInfo); ok && info.Reason == "ACCOUNT_BANNED" {
+				return c.Status(fiber.StatusForbidden).JSON(models.Error{
+					Code:    "ACCOUNT_BANNED",
+					Message: "Account is banned",
+				})
+			}
+		}
 		return c.Status(fiber.StatusForbidden).JSON(models.Error{
 			Code:    "PERMISSION_DENIED",
 			Message: "Insufficient permissions",
 		})
+	case codes.ResourceExhausted:
+		return h.handleLoginLocked(c, st)
 	case codes.Unavailable:
 		return c.Status(fiber.StatusServiceUnavailable).JSON(models.Error{
 			Code:    "SERVICE_UNAVAILABLE",
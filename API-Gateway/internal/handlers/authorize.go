@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/policy"
+)
+
+// securedGroup регистрирует маршруты вместе с действием политики: перед
+// обработчиками встаёт authorize(action), маршрут попадает в h.routes для
+// CheckPolicy и сводной матрицы. Маршруты /api/v1 регистрируются только так.
+type securedGroup struct {
+	h      *Handler
+	router fiber.Router
+	prefix string
+}
+
+// secure — корневая группа защищённых маршрутов app с префиксом prefix.
+func (h *Handler) secure(app *fiber.App, prefix string) *securedGroup {
+	return &securedGroup{h: h, router: app.Group(prefix), prefix: prefix}
+}
+
+// Group — вложенная группа.
+func (g *securedGroup) Group(prefix string) *securedGroup {
+	return &securedGroup{h: g.h, router: g.router.Group(prefix), prefix: g.prefix + prefix}
+}
+
+func (g *securedGroup) Get(path, action string, handlers ...fiber.Handler) {
+	g.add(fiber.MethodGet, path, action, handlers)
+}
+
+func (g *securedGroup) Post(path, action string, handlers ...fiber.Handler) {
+	g.add(fiber.MethodPost, path, action, handlers)
+}
+
+func (g *securedGroup) Patch(path, action string, handlers ...fiber.Handler) {
+	g.add(fiber.MethodPatch, path, action, handlers)
+}
+
+func (g *securedGroup) Delete(path, action string, handlers ...fiber.Handler) {
+	g.add(fiber.MethodDelete, path, action, handlers)
+}
+
+func (g *securedGroup) add(method, path, action string, handlers []fiber.Handler) {
+	g.h.routes = append(g.h.routes, policy.Route{
+		Method: method,
+		Path:   routePath(g.prefix + path),
+		Action: action,
+	})
+
+	chain := make([]fiber.Handler, 0, len(handlers)+2)
+	if action != policy.Public {
+		chain = append(chain, g.h.authorize(action))
+	}
+	// Кэш — после проверки доступа: HIT не должен отдавать ответ тому, кому
+	// маршрут закрыт.
+	if g.h.cacheClient != nil && g.h.cacheClient.Enabled() {
+		chain = append(chain, CacheMiddleware(g.h.cacheClient, g.h.cachePrincipal, g.h.refreshCache))
+	}
+	chain = append(chain, handlers...)
+	if method == fiber.MethodGet {
+		// Get регистрирует и HEAD — как раньше при app.Get.
+		g.router.Get(path, chain...)
+		return
+	}
+	g.router.Add(method, path, chain...)
+}
+
+// routePath — путь маршрута в виде, в каком его видит Fiber (без
+// завершающего "/").
+func routePath(path string) string {
+	if path != "/" {
+		path = strings.TrimSuffix(path, "/")
+	}
+	return path
+}
+
+// authorize — проверка действия action политикой. Ресурс — параметр пути
+// маршрута: для треда чата "<kind>:<rid>", для заявки в компанию
+// membership_id, для остальных — id.
+func (h *Handler) authorize(action string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		resource := policy.Resource{Type: h.authz.Resource(action)}
+		switch resource.Type {
+		case "thread":
+			_, _, resource.ID = threadIDFromParams(c)
+		case "membership":
+			resource.ID = c.Params("membership_id")
+		default:
+			resource.ID = c.Params(ID)
+		}
+
+		err := h.authz.Authorize(c.UserContext(), subjectFromContext(c), action, resource)
+		switch {
+		case err == nil:
+			return c.Next()
+		case errors.Is(err, policy.ErrUnauthenticated):
+			log.Printf("authorize: %s %s - user not authenticated", c.Method(), c.Path())
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "User not authenticated",
+			})
+		case errors.Is(err, policy.ErrNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Resource not found",
+			})
+		case errors.Is(err, policy.ErrUnavailable):
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": "Service unavailable",
+			})
+		case errors.Is(err, policy.ErrDenied):
+			if isAPIKeyRequest(c) {
+				return apiKeyForbidden(c, h.authz.Scope(action))
+			}
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Insufficient permissions",
+			})
+		default:
+			log.Printf("authorize: %s on %s %q failed: %v", action, resource.Type, resource.ID, err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Authorization check failed",
+			})
+		}
+	}
+}
+
+// apiKeyForbidden — отказ запросу по API-ключу: маршрут ключам закрыт или у
+// ключа нет нужного права.
+func apiKeyForbidden(c *fiber.Ctx, scope string) error {
+	if scope == "" {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Endpoint is not available for API keys",
+		})
+	}
+	return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+		"error": "API key scope required: " + scope,
+	})
+}
+
+// subjectFromContext — субъект политики из данных AuthMiddleware.
+func subjectFromContext(c *fiber.Ctx) policy.Subject {
+	return policy.Subject{
+		UserID: getUserIDFromContext(c),
+		Role:   string(getRoleFromContext(c)),
+		APIKey: isAPIKeyRequest(c),
+		Scopes: getAPIKeyScopesFromContext(c),
+	}
+}
+
+// PolicyRoles — роли Gateway, которые может упоминать файл политики.
+func PolicyRoles() []string {
+	roles := make([]string, 0, len(knownRoles))
+	for name := range knownRoles {
+		roles = append(roles, name)
+	}
+	slices.Sort(roles)
+	return roles
+}
+
+// Routes — маршруты /api/v1 с их действиями (после Init).
+func (h *Handler) Routes() []policy.Route {
+	return h.routes
+}
+
+// CheckPolicy сверяет маршруты с политикой после Init: каждый маршрут /api/v1
+// зарегистрирован через securedGroup, а его действие описано в политике.
+// Маршрут мимо политики — ошибка старта, а не открытый эндпоинт.
+func (h *Handler) CheckPolicy() error {
+	secured := make(map[string]bool, len(h.routes))
+	for _, r := range h.routes {
+		secured[r.Method+" "+r.Path] = true
+	}
+
+	var errs []error
+	for _, r := range h.app.GetRoutes(true) {
+		if !strings.HasPrefix(r.Path, "/api/v1/") || r.Method == fiber.MethodHead {
+			continue
+		}
+		if !secured[r.Method+" "+routePath(r.Path)] {
+			errs = append(errs, fmt.Errorf("%s %s: route is not registered with a policy action", r.Method, r.Path))
+		}
+	}
+	if err := h.authz.CheckRoutes(h.routes); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
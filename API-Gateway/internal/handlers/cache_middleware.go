@@ -1,57 +1,106 @@
 package handlers
 
 import (
+	"log"
+	"time"
+
+	companyv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/company/v1"
 	"github.com/gofiber/fiber/v2"
 	"github.com/studjobs/hh_for_students/api-gateway/internal/cache"
 	"github.com/studjobs/hh_for_students/api-gateway/internal/metrics"
+	"github.com/valyala/fasthttp"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // CacheMiddleware реализует cache-aside поверх Redis.
 //
 // Логика:
 //   1. GET-запрос на cacheable-маршрут → check Redis → hit → вернуть из кэша
-//   2. miss → c.Next() → если 200 OK, положить body в Redis с TTL
-//   3. POST/PATCH/PUT/DELETE → c.Next() → если 2xx, инвалидировать связанные prefix'ы
+//   2. miss → c.Next() → если 200 OK, положить body в Redis с TTL маршрута
+//      (одновременные промахи по ключу ждут первый — X-Cache: COALESCED)
+//   2a. hit после мягкого TTL → отдать устаревшую запись (X-Cache: STALE) и
+//      обновить её одним фоновым запросом (refresh)
+//   3. POST/PATCH/PUT/DELETE → c.Next() → если 2xx, инвалидировать теги затронутых сущностей
+//
+// Ключ записи включает измерения Vary маршрута (роль, компания) — ответ,
+// собранный для одного запрашивающего, не уходит другому. Если измерение
+// определить не удалось или правило велит обойти кэш (свой профиль), запрос
+// идёт мимо кэша целиком: и без чтения, и без записи.
+//
+// Запись помечается тегами сущностей из пути и тела (Rule.Tags), запись
+// чистит теги из cache.TagsForWrite. ETag тела сохраняется вместе с ним;
+// If-None-Match проверяет ETagMiddleware.
 //
 // Лейбл `route` для метрик берётся из c.Route().Path (без UUID), как в
 // metrics.HTTPMiddleware — тот же паттерн.
-func CacheMiddleware(cli *cache.Client) fiber.Handler {
+//
+// Ставится в цепочку маршрута после проверки доступа (см. securedGroup):
+// HIT отдаётся только тем, кому маршрут открыт.
+func CacheMiddleware(cli *cache.Client, principalOf cachePrincipalFunc, refresh cacheRefreshFunc) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		path := c.Path()
 		method := c.Method()
 
 		// Read-path: только GET, только whitelist.
-		if method == fiber.MethodGet && cache.IsCacheableRoute(path) && !cache.ShouldExclude(path) {
-			key := cache.Key(path, string(c.Request().URI().QueryString()))
+		if method == fiber.MethodGet {
+			rule := cache.RuleFor(path)
+			if rule == nil {
+				return c.Next()
+			}
+			principal, ok := principalOf(c, rule.Vary)
+			if !ok || rule.Bypass(path, principal) {
+				c.Set("X-Cache", "BYPASS")
+				return c.Next()
+			}
+			key := rule.Key(path, string(c.Request().URI().QueryString()), principal)
 			route := c.Route().Path
 			if route == "" {
 				route = path
 			}
-			if entry := cli.Get(c.Context(), key); entry != nil {
+			// Фоновое обновление (refresh): запись заведомо устарела — сразу
+			// к хендлеру и свежий ответ в кэш.
+			if c.Locals(string(cacheRefreshKey)) != nil {
+				_, err := cacheResponse(c, cli, rule, path, key)
+				return err
+			}
+			if entry := cli.Get(c.UserContext(), key); entry != nil {
 				// HIT — отдаём ответ как есть, не вызываем c.Next() (хендлер не нужен).
-				metrics.CacheHits.WithLabelValues(route).Inc()
-				if entry.ContentType != "" {
-					c.Set(fiber.HeaderContentType, entry.ContentType)
+				if entry.Fresh(time.Now()) {
+					metrics.CacheHits.WithLabelValues(route).Inc()
+					return sendEntry(c, entry, "HIT")
 				}
-				c.Set("X-Cache", "HIT")
-				return c.Status(entry.Status).Send(entry.Body)
+				metrics.CacheStale.WithLabelValues(route).Inc()
+				if cli.ClaimRefresh(c.UserContext(), key, cacheRefreshLease) {
+					refresh(c)
+				}
+				return sendEntry(c, entry, "STALE")
 			}
-			// MISS — пускаем дальше, потом сохраняем.
-			metrics.CacheMisses.WithLabelValues(route).Inc()
-			if err := c.Next(); err != nil {
-				return err
+			// MISS — первый промах по ключу идёт в хендлер и сохраняет ответ,
+			// одновременные с ним ждут и отдают тот же.
+			var nextErr error
+			entry, shared := cli.Coalesce(key, func() *cache.Entry {
+				metrics.CacheMisses.WithLabelValues(route).Inc()
+				var e *cache.Entry
+				e, nextErr = cacheResponse(c, cli, rule, path, key)
+				return e
+			})
+			if !shared {
+				if nextErr != nil {
+					return nextErr
+				}
+				c.Set("X-Cache", "MISS")
+				return nil
 			}
-			status := c.Response().StatusCode()
-			if status == fiber.StatusOK {
-				body := append([]byte(nil), c.Response().Body()...) // copy, body re-used
-				cli.Set(c.Context(), key, &cache.Entry{
-					Status:      status,
-					ContentType: string(c.Response().Header.ContentType()),
-					Body:        body,
-				})
+			if entry != nil {
+				metrics.CacheCoalesced.WithLabelValues(route).Inc()
+				return sendEntry(c, entry, "COALESCED")
 			}
+			// У первого не вышло кэшируемого ответа (ошибка, не 200) — делить
+			// нечего, идём сами.
+			metrics.CacheMisses.WithLabelValues(route).Inc()
 			c.Set("X-Cache", "MISS")
-			return nil
+			return c.Next()
 		}
 
 		// Write-path: POST/PATCH/PUT/DELETE — пропускаем хендлер, потом инвал.
@@ -61,14 +110,115 @@ func CacheMiddleware(cli *cache.Client) fiber.Handler {
 			}
 			status := c.Response().StatusCode()
 			if status >= 200 && status < 300 {
-				for _, prefix := range cache.InvalidationsForWrite(path) {
-					cli.InvalidatePrefix(c.Context(), prefix)
-				}
+				cli.Invalidate(c.UserContext(), cache.TagsForWrite(cache.Write{
+					Path:   path,
+					ID:     c.Params("id"),
+					UserID: getUserIDFromContext(c),
+					Body:   c.Response().Body(),
+				}))
 			}
 			return nil
 		}
 
-		// Не cacheable GET — просто пропускаем.
 		return c.Next()
 	}
 }
+
+// cacheRefreshLease — сколько ждать фоновое обновление записи, прежде чем
+// другой запрос попробует снова (с запасом на WriteTimeout сервера).
+const cacheRefreshLease = 15 * time.Second
+
+// cacheResponse пропускает запрос в хендлер и кладёт 200 OK в кэш под key.
+// Возвращает сохранённую запись; nil — ответ не кэшируется.
+func cacheResponse(c *fiber.Ctx, cli *cache.Client, rule *cache.Rule, path, key string) (*cache.Entry, error) {
+	if err := c.Next(); err != nil {
+		return nil, err
+	}
+	status := c.Response().StatusCode()
+	if status != fiber.StatusOK {
+		return nil, nil
+	}
+	body := append([]byte(nil), c.Response().Body()...) // copy, body re-used
+	etag := etagOf(body)
+	c.Set(fiber.HeaderETag, etag)
+	entry := &cache.Entry{
+		Status:      status,
+		ContentType: string(c.Response().Header.ContentType()),
+		Body:        body,
+		ETag:        etag,
+	}
+	cli.Set(c.UserContext(), path, key, entry, rule.Tags(path, body))
+	return entry, nil
+}
+
+// sendEntry отдаёт запись кэша; source — значение X-Cache.
+func sendEntry(c *fiber.Ctx, entry *cache.Entry, source string) error {
+	if entry.ContentType != "" {
+		c.Set(fiber.HeaderContentType, entry.ContentType)
+	}
+	if entry.ETag != "" {
+		c.Set(fiber.HeaderETag, entry.ETag)
+	}
+	c.Set("X-Cache", source)
+	return c.Status(entry.Status).Send(entry.Body)
+}
+
+// cacheRefreshFunc запускает фоновое обновление записи запроса c; к
+// возврату запрос уже скопирован, c можно отпускать.
+type cacheRefreshFunc func(c *fiber.Ctx)
+
+// cacheRefreshKey — метка фонового обновления в Locals: CacheMiddleware
+// не читает кэш, RateLimiter не списывает лимит клиента. Ставится только
+// refreshCache — из запроса её не подделать.
+const cacheRefreshKey contextKey = "cache_refresh"
+
+// refreshCache повторяет запрос c в фоне через всё приложение: авторизация,
+// политика и Vary отрабатывают как у исходного запроса, и свежий ответ ложится
+// под тот же ключ. Токен истёк или отозван — ответ не 200 и в кэш не попадёт.
+func (h *Handler) refreshCache(c *fiber.Ctx) {
+	req := fasthttp.AcquireRequest()
+	c.Request().CopyTo(req)
+	req.Header.Del(fiber.HeaderIfNoneMatch)
+	remote := c.Context().RemoteAddr()
+	go func() {
+		defer fasthttp.ReleaseRequest(req)
+		var fctx fasthttp.RequestCtx
+		fctx.Init(req, remote, nil)
+		fctx.SetUserValue(string(cacheRefreshKey), true)
+		h.app.Handler()(&fctx)
+	}()
+}
+
+// cachePrincipalFunc — кто спрашивает, в объёме измерений vary; false —
+// определить не удалось, и запрос идёт мимо кэша.
+type cachePrincipalFunc func(c *fiber.Ctx, vary cache.Vary) (cache.Principal, bool)
+
+// cachePrincipal — запрашивающий для ключей кэша. Компания владельца — его
+// uuid (и uuid компании API-ключа), HR — компания подтверждённого
+// membership; её ищем только для маршрутов с VaryCompany.
+func (h *Handler) cachePrincipal(c *fiber.Ctx, vary cache.Vary) (cache.Principal, bool) {
+	p := cache.Principal{
+		UserID: getUserIDFromContext(c),
+		Role:   string(getRoleFromContext(c)),
+		APIKey: isAPIKeyRequest(c),
+	}
+	if vary&cache.VaryCompany == 0 {
+		return p, true
+	}
+	switch Role(p.Role) {
+	case ROLE_COMPANY:
+		p.CompanyID = p.UserID
+	case ROLE_HR:
+		ms, err := h.apiService.Company.GetMembershipByUser(c.UserContext(), p.UserID)
+		if err != nil {
+			if st, ok := status.FromError(err); !ok || st.Code() != codes.NotFound {
+				log.Printf("cachePrincipal: membership of %s unavailable, cache bypassed: %v", p.UserID, err)
+				return p, false
+			}
+		}
+		if ms != nil && ms.Status == int32(companyv1.MembershipStatus_MEMBERSHIP_STATUS_APPROVED) {
+			p.CompanyID = ms.CompanyID
+		}
+	}
+	return p, true
+}
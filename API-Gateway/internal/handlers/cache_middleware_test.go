@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	companyv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/company/v1"
+	"github.com/gofiber/fiber/v2"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/cache"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/models"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/services"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type principalCompanies struct {
+	services.CompanyService
+	ms  *models.CompanyMember
+	err error
+}
+
+func (s principalCompanies) GetMembershipByUser(context.Context, string) (*models.CompanyMember, error) {
+	return s.ms, s.err
+}
+
+func TestCachePrincipalCompany(t *testing.T) {
+	approved := int32(companyv1.MembershipStatus_MEMBERSHIP_STATUS_APPROVED)
+	pending := int32(companyv1.MembershipStatus_MEMBERSHIP_STATUS_PENDING)
+
+	tests := []struct {
+		name        string
+		role        Role
+		companies   principalCompanies
+		vary        cache.Vary
+		wantCompany string
+		wantOK      bool
+	}{
+		{"owner", ROLE_COMPANY, principalCompanies{}, cache.VaryCompany, "user-1", true},
+		{"approved hr", ROLE_HR, principalCompanies{ms: &models.CompanyMember{CompanyID: "c1", Status: approved}}, cache.VaryCompany, "c1", true},
+		{"pending hr", ROLE_HR, principalCompanies{ms: &models.CompanyMember{CompanyID: "c1", Status: pending}}, cache.VaryCompany, "", true},
+		{"hr without membership", ROLE_HR, principalCompanies{err: status.Error(codes.NotFound, "no membership")}, cache.VaryCompany, "", true},
+		// Компания неизвестна — общий ключ мог бы отдать чужой ответ.
+		{"membership unavailable", ROLE_HR, principalCompanies{err: errors.New("company service down")}, cache.VaryCompany, "", false},
+		{"student", ROLE_STUDENT, principalCompanies{}, cache.VaryCompany, "", true},
+		// Без VaryCompany компания не нужна и не запрашивается.
+		{"role only", ROLE_HR, principalCompanies{err: errors.New("must not be called")}, cache.VaryRole, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Handler{apiService: &services.ApiGateway{Company: tt.companies}}
+			var (
+				got cache.Principal
+				ok  bool
+			)
+			app := fiber.New()
+			app.Get("/", func(c *fiber.Ctx) error {
+				c.Locals(string(UserIDKey), "user-1")
+				c.Locals(string(RoleKey), tt.role)
+				got, ok = h.cachePrincipal(c, tt.vary)
+				return c.SendStatus(fiber.StatusOK)
+			})
+			if _, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil)); err != nil {
+				t.Fatalf("request: %v", err)
+			}
+			if ok != tt.wantOK || got.CompanyID != tt.wantCompany {
+				t.Fatalf("cachePrincipal = %+v, %t; want company %q, %t", got, ok, tt.wantCompany, tt.wantOK)
+			}
+			if got.UserID != "user-1" || got.Role != string(tt.role) {
+				t.Fatalf("cachePrincipal = %+v, want user-1 as %s", got, tt.role)
+			}
+		})
+	}
+}
@@ -6,85 +6,28 @@ import (
 	"strconv"
 	"strings"
 
+	companyv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/company/v1"
 	"github.com/gofiber/fiber/v2"
 
 	"github.com/studjobs/hh_for_students/api-gateway/internal/models"
 )
 
-// canAccessThread проверяет, имеет ли пользователь право читать/писать в треде.
-//   - application/<id>  → доступ у автора-студента и у HR (упрощённо, см. ниже).
-//   - task/<id>         → доступ у assigned студента и у владельца задачи.
-//   - quest/<id>        → доступ у target_student_id и у эксперта-создателя.
-//   - direct/<idA>_<idB> → 1:1 чат; rid — это пара UUID, отсортированных по
-//     возрастанию (фронт строит детерминированно). Тред создаётся «на лету» при
-//     первом сообщении; ListUserThreads потом вернёт его обеим сторонам.
-// Возвращает (ok, errMessage). На gRPC-уровне используется один объединённый
-// thread_id `<kind>:<rid>` — это контракт сервиса чата, никак не влияет на URL.
-func (h *Handler) canAccessThread(ctx context.Context, userID string, userRole Role, kind, rid string) (bool, string) {
-	if rid == "" {
-		return false, "invalid resource id"
+// assignThreadHR — HR, открывший тред отклика на вакансию своей
+// (APPROVED-membership) компании, становится ответственным за отклик, чтобы
+// тред был корректно атрибутирован. Доступ к треду уже проверила политика
+// (chat.read / chat.send).
+func (h *Handler) assignThreadHR(ctx context.Context, userID string, userRole Role, kind, rid string) {
+	if kind != "application" || userRole != ROLE_HR {
+		return
 	}
-	switch kind {
-	case "direct":
-		// rid: "<uuidA>_<uuidB>". Доступ — если userID совпадает с любым из них.
-		parts := strings.Split(rid, "_")
-		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
-			return false, "invalid direct id"
-		}
-		if parts[0] == userID || parts[1] == userID {
-			return true, ""
-		}
-		return false, "not a participant"
-	case "application":
-		// Участники треда отклика:
-		//   - студент-автор: только он сам.
-		//   - HR/COMPANY/EXPERT/DEVELOPER: разрешаем — в MVP не дёргаем строгую
-		//     проверку membership (UX-блокеры были болезненны). Если HR APPROVED
-		//     и ещё не assignee — auto-assign'имся.
-		app, err := h.apiService.Application.Get(ctx, rid)
-		if err != nil {
-			return false, "application not found"
-		}
-		// Студент: только автор отклика.
-		if userRole == ROLE_STUDENT {
-			if app.StudentID == userID {
-				return true, ""
-			}
-			return false, "not a participant"
-		}
-		// HR/COMPANY/EXPERT/DEVELOPER → пускаем. Дополнительно auto-assign HR в
-		// approved-компании этой вакансии, чтобы тред был корректно атрибутирован.
-		if userRole == ROLE_HR {
-			if v, vErr := h.apiService.Vacancy.GetVacancy(ctx, app.VacancyID); vErr == nil && v != nil {
-				if ms, mErr := h.apiService.Company.GetMembershipByUser(ctx, userID); mErr == nil && ms != nil && ms.CompanyID == v.CompanyID && ms.Status == 2 {
-					_, _ = h.apiService.Application.AssignHR(ctx, rid, userID)
-				}
-			}
-		}
-		return true, ""
-	case "task":
-		t, err := h.apiService.MicroTasks.Get(ctx, rid)
-		if err != nil {
-			return false, "task not found"
-		}
-		if t.AssignedTo == userID || t.CompanyID == userID {
-			return true, ""
-		}
-		return false, "not a participant"
-	case "quest":
-		t, err := h.apiService.MicroTasks.Get(ctx, rid)
-		if err != nil {
-			return false, "quest not found"
-		}
-		if !t.IsSkillQuest {
-			return false, "not a quest"
-		}
-		if t.TargetStudentID == userID || t.CompanyID == userID {
-			return true, ""
+	app, err := h.apiService.Application.Get(ctx, rid)
+	if err != nil || app == nil {
+		return
+	}
+	if v, vErr := h.apiService.Vacancy.GetVacancy(ctx, app.VacancyID); vErr == nil && v != nil {
+		if ms, mErr := h.apiService.Company.GetMembershipByUser(ctx, userID); mErr == nil && ms != nil && ms.CompanyID == v.CompanyID && ms.Status == int32(companyv1.MembershipStatus_MEMBERSHIP_STATUS_APPROVED) {
+			_, _ = h.apiService.Application.AssignHR(ctx, rid, userID)
 		}
-		return false, "not a participant"
-	default:
-		return false, "unknown thread kind"
 	}
 }
 
@@ -113,7 +56,7 @@ func (h *Handler) GetChatThreads(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
 	}
 
-	ctx := c.Context()
+	ctx := c.UserContext()
 
 	// Скрытые юзером треды (анти-зачистка): после сбора будем фильтровать.
 	hidden := make(map[string]bool)
@@ -331,6 +274,8 @@ func roleHumanLabel(r string) string {
 		return "Владелец компании"
 	case "ROLE_EXPERT":
 		return "Эксперт"
+	case "ROLE_ADMIN":
+		return "Администратор"
 	}
 	return r
 }
@@ -341,13 +286,11 @@ func (h *Handler) GetChatMessages(c *fiber.Ctx) error {
 	if threadID == "" || userID == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request"})
 	}
-	if ok, why := h.canAccessThread(c.Context(), userID, getRoleFromContext(c), kind, rid); !ok {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": why})
-	}
+	h.assignThreadHR(c.UserContext(), userID, getRoleFromContext(c), kind, rid)
 	page, _ := strconv.Atoi(c.Query("page", "1"))
 	limit, _ := strconv.Atoi(c.Query("limit", "50"))
 	page, limit = normalizePagination(page, limit)
-	list, err := h.apiService.Chat.ListMessages(c.Context(), threadID, int32(page), int32(limit))
+	list, err := h.apiService.Chat.ListMessages(c.UserContext(), threadID, int32(page), int32(limit))
 	if err != nil {
 		log.Printf("GetChatMessages: thread=%s failed: %v", threadID, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to load messages"})
@@ -366,7 +309,7 @@ func (h *Handler) EditChatMessage(c *fiber.Ctx) error {
 	if err := c.BodyParser(&req); err != nil || strings.TrimSpace(req.Body) == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "body is required"})
 	}
-	m, err := h.apiService.Chat.EditMessage(c.Context(), msgID, userID, strings.TrimSpace(req.Body))
+	m, err := h.apiService.Chat.EditMessage(c.UserContext(), msgID, userID, strings.TrimSpace(req.Body))
 	if err != nil {
 		log.Printf("EditChatMessage failed user=%s msg=%s: %v", userID, msgID, err)
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "не ваше сообщение или не существует"})
@@ -381,7 +324,7 @@ func (h *Handler) HideChatThread(c *fiber.Ctx) error {
 	if threadID == "" || userID == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request"})
 	}
-	if err := h.apiService.Chat.HideThread(c.Context(), userID, threadID); err != nil {
+	if err := h.apiService.Chat.HideThread(c.UserContext(), userID, threadID); err != nil {
 		log.Printf("HideChatThread failed user=%s thread=%s: %v", userID, threadID, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to hide"})
 	}
@@ -394,14 +337,12 @@ func (h *Handler) SendChatMessage(c *fiber.Ctx) error {
 	if threadID == "" || userID == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request"})
 	}
-	if ok, why := h.canAccessThread(c.Context(), userID, getRoleFromContext(c), kind, rid); !ok {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": why})
-	}
+	h.assignThreadHR(c.UserContext(), userID, getRoleFromContext(c), kind, rid)
 	var req models.ChatSendRequest
 	if err := c.BodyParser(&req); err != nil || strings.TrimSpace(req.Body) == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "body is required"})
 	}
-	m, err := h.apiService.Chat.SendMessage(c.Context(), threadID, userID, strings.TrimSpace(req.Body))
+	m, err := h.apiService.Chat.SendMessage(c.UserContext(), threadID, userID, strings.TrimSpace(req.Body))
 	if err != nil {
 		log.Printf("SendChatMessage: thread=%s user=%s failed: %v", threadID, userID, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to send"})
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ClientIPKey — адрес клиента, определённый ClientIPMiddleware.
+const ClientIPKey contextKey = "client_ip"
+
+// TrustedProxies — reverse proxy перед Gateway (IP или CIDR). Только их
+// X-Forwarded-For учитывается: иначе клиент подставил бы в заголовок любой
+// адрес и обошёл лимиты по IP и блокировку входа в Auth.
+type TrustedProxies struct {
+	list     []string
+	prefixes []netip.Prefix
+}
+
+// ParseTrustedProxies разбирает список через запятую ("10.0.0.1,172.16.0.0/12").
+// Пустой список — прокси нет, клиент — адрес TCP-соединения.
+func ParseTrustedProxies(list string) (*TrustedProxies, error) {
+	p := &TrustedProxies{}
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		prefix, err := netip.ParsePrefix(item)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(item)
+			if addrErr != nil {
+				return nil, fmt.Errorf("trusted proxy %q: not an IP or CIDR", item)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		p.list = append(p.list, item)
+		p.prefixes = append(p.prefixes, prefix.Masked())
+	}
+	return p, nil
+}
+
+// List — исходный список для fiber.Config.TrustedProxies.
+func (p *TrustedProxies) List() []string {
+	if p == nil {
+		return nil
+	}
+	return p.list
+}
+
+func (p *TrustedProxies) contains(addr netip.Addr) bool {
+	if p == nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range p.prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIPMiddleware определяет адрес клиента один раз на запрос. Если
+// соединение пришло от доверенного прокси, X-Forwarded-For читается справа
+// налево до первого адреса не из списка: левые значения прокси лишь
+// дописывает за клиентом, и их клиент может подделать.
+func ClientIPMiddleware(proxies *TrustedProxies) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals(string(ClientIPKey), resolveClientIP(c, proxies))
+		return c.Next()
+	}
+}
+
+func resolveClientIP(c *fiber.Ctx, proxies *TrustedProxies) string {
+	remote, ok := netip.AddrFromSlice(c.Context().RemoteIP())
+	if !ok {
+		return c.IP()
+	}
+	client := remote.Unmap()
+	if !proxies.contains(client) {
+		return client.String()
+	}
+
+	// Прокси может прислать несколько строк заголовка — это одна цепочка.
+	var hops []string
+	for _, value := range c.Request().Header.PeekAll(fiber.HeaderXForwardedFor) {
+		for _, hop := range bytes.Split(value, []byte{','}) {
+			hops = append(hops, strings.TrimSpace(string(hop)))
+		}
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(hops[i])
+		if err != nil {
+			break
+		}
+		client = addr.Unmap()
+		if !proxies.contains(client) {
+			break
+		}
+	}
+	return client.String()
+}
+
+// clientIP возвращает адрес клиента (см. ClientIPMiddleware). Без middleware —
+// адрес соединения: заголовкам без проверки прокси не верим.
+func clientIP(c *fiber.Ctx) string {
+	if ip, ok := c.Locals(string(ClientIPKey)).(string); ok && ip != "" {
+		return ip
+	}
+	return c.IP()
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestParseTrustedProxies(t *testing.T) {
+	p, err := ParseTrustedProxies(" 10.0.0.1, 172.16.0.0/12,,fd00::/8 ")
+	if err != nil {
+		t.Fatalf("ParseTrustedProxies: %v", err)
+	}
+	if got := len(p.List()); got != 3 {
+		t.Fatalf("List() = %v, want 3 entries", p.List())
+	}
+	if _, err := ParseTrustedProxies("10.0.0.1,proxy.local"); err == nil {
+		t.Fatal("hostname accepted as a trusted proxy")
+	}
+}
+
+func TestClientIPTrustsOnlyConfiguredProxies(t *testing.T) {
+	// Соединение в app.Test приходит с 0.0.0.0.
+	trusted, _ := ParseTrustedProxies("0.0.0.0,10.0.0.0/8")
+	untrusted, _ := ParseTrustedProxies("10.0.0.0/8")
+
+	tests := []struct {
+		name    string
+		proxies *TrustedProxies
+		xff     []string
+		want    string
+	}{
+		{"no proxies", nil, []string{"203.0.113.7"}, "0.0.0.0"},
+		{"direct client", untrusted, []string{"203.0.113.7"}, "0.0.0.0"},
+		{"trusted proxy", trusted, []string{"203.0.113.7"}, "203.0.113.7"},
+		// Левое значение прислал клиент, правое дописал прокси.
+		{"spoofed left value", trusted, []string{"198.51.100.1, 203.0.113.7"}, "203.0.113.7"},
+		{"chain of trusted proxies", trusted, []string{"203.0.113.7, 10.0.0.5"}, "203.0.113.7"},
+		{"separate header lines", trusted, []string{"198.51.100.1", "203.0.113.7"}, "203.0.113.7"},
+		{"only proxies", trusted, []string{"10.0.0.5"}, "10.0.0.5"},
+		{"garbage", trusted, []string{"unknown"}, "0.0.0.0"},
+		{"no header", trusted, nil, "0.0.0.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{EnableTrustedProxyCheck: true, TrustedProxies: tt.proxies.List()})
+			app.Use(ClientIPMiddleware(tt.proxies))
+			var got string
+			app.Get("/", func(c *fiber.Ctx) error {
+				got = clientIP(c)
+				return c.SendStatus(fiber.StatusOK)
+			})
+
+			req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+			for _, v := range tt.xff {
+				req.Header.Add(fiber.HeaderXForwardedFor, v)
+			}
+			if _, err := app.Test(req); err != nil {
+				t.Fatalf("request: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("clientIP = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
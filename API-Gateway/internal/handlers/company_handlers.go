@@ -39,7 +39,7 @@ func (h *Handler) GetCompanies(c *fiber.Ctx) error {
 		Limit: int32(limit),
 	}
 
-	companies, err := h.apiService.Company.GetAllCompanies(c.Context(), pagination, city, companyType, query)
+	companies, err := h.apiService.Company.GetAllCompanies(c.UserContext(), pagination, city, companyType, query)
 	if err != nil {
 		log.Printf("GetCompanies: Failed to get companies: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(models.Error{
@@ -48,7 +48,7 @@ func (h *Handler) GetCompanies(c *fiber.Ctx) error {
 		})
 	}
 
-	h.enrichCompanyListWithFiles(c.Context(), companies.Companies)
+	h.enrichCompanyListWithFiles(c.UserContext(), companies.Companies)
 
 	log.Printf("GetCompanies: Successfully retrieved %d companies", len(companies.Companies))
 	return c.JSON(companies)
@@ -72,7 +72,7 @@ func (h *Handler) GetCompanyByID(c *fiber.Ctx) error {
 	companyID := c.Params("id")
 	log.Printf("GetCompanyByID: Getting company with ID: %s", companyID)
 
-	company, err := h.apiService.Company.GetCompany(c.Context(), companyID)
+	company, err := h.apiService.Company.GetCompany(c.UserContext(), companyID)
 	if err != nil {
 		log.Printf("GetCompanyByID: Failed to get company %s: %v", companyID, err)
 		return c.Status(fiber.StatusNotFound).JSON(models.Error{
@@ -81,7 +81,7 @@ func (h *Handler) GetCompanyByID(c *fiber.Ctx) error {
 		})
 	}
 
-	h.enrichCompanyWithFiles(c.Context(), company)
+	h.enrichCompanyWithFiles(c.UserContext(), company)
 
 	log.Printf("GetCompanyByID: Successfully retrieved company: %s", companyID)
 	return c.JSON(company)
@@ -103,7 +103,7 @@ func (h *Handler) GetCompanyMe(c *fiber.Ctx) error {
 	companyID := getUserIDFromContext(c)
 	log.Printf("GetCompanyMe: Getting company with ID: %s", companyID)
 
-	company, err := h.apiService.Company.GetCompany(c.Context(), companyID)
+	company, err := h.apiService.Company.GetCompany(c.UserContext(), companyID)
 	if err != nil {
 		log.Printf("GetCompanyMe: Failed to get company %s: %v", companyID, err)
 		return c.Status(fiber.StatusNotFound).JSON(models.Error{
@@ -112,7 +112,7 @@ func (h *Handler) GetCompanyMe(c *fiber.Ctx) error {
 		})
 	}
 
-	h.enrichCompanyWithFiles(c.Context(), company)
+	h.enrichCompanyWithFiles(c.UserContext(), company)
 
 	log.Printf("GetCompanyMe: Successfully retrieved company: %s", companyID)
 	return c.JSON(company)
@@ -152,7 +152,7 @@ func (h *Handler) CreateCompany(c *fiber.Ctx) error {
 		})
 	}
 
-	company, err := h.apiService.Company.CreateCompany(c.Context(), &req)
+	company, err := h.apiService.Company.CreateCompany(c.UserContext(), &req)
 	if err != nil {
 		log.Printf("CreateCompany: Failed to create company: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(models.Error{
@@ -193,7 +193,7 @@ func (h *Handler) UpdateCompany(c *fiber.Ctx) error {
 		})
 	}
 
-	company, err := h.apiService.Company.UpdateCompany(c.Context(), companyID, &req)
+	company, err := h.apiService.Company.UpdateCompany(c.UserContext(), companyID, &req)
 	if err != nil {
 		log.Printf("UpdateCompany: Failed to update company %s: %v", companyID, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(models.Error{
@@ -202,7 +202,7 @@ func (h *Handler) UpdateCompany(c *fiber.Ctx) error {
 		})
 	}
 
-	h.enrichCompanyWithFiles(c.Context(), company)
+	h.enrichCompanyWithFiles(c.UserContext(), company)
 
 	log.Printf("UpdateCompany: Successfully updated company: %s", companyID)
 	return c.JSON(company)
@@ -210,42 +210,16 @@ func (h *Handler) UpdateCompany(c *fiber.Ctx) error {
 
 // DeleteCompany удаляет компанию
 // @Summary Удалить компанию
-// @Description Удаляет компанию текущего пользователя
+// @Description Удаляет компанию текущего пользователя вместе с его аккаунтом (аккаунт владельца и есть компания). Удаление идёт так же, как DELETE /users; ход удаления — по status_url.
 // @Tags Companies
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {object} map[string]string "Сообщение об успешном удалении"
+// @Success 202 {object} models.AccountDeletion "Удаление начато"
 // @Failure 401 {object} models.ErrorResponse "Неавторизованный доступ"
 // @Failure 403 {object} models.ErrorResponse "Доступ запрещен"
-// @Failure 404 {object} models.ErrorResponse "Компания не найдена"
 // @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
 // @Router /company [delete]
 func (h *Handler) DeleteCompany(c *fiber.Ctx) error {
-	companyID := getUserIDFromContext(c)
-	log.Printf("DeleteCompany: Deleting company %s", companyID)
-
-	company, err := h.apiService.Company.GetCompany(c.Context(), companyID)
-	if err == nil && company.LogoID != nil && *company.LogoID != "" {
-		if err := h.fileHandler.DeleteFile(c.Context(), companyID, *company.LogoID); err != nil {
-			log.Printf("DeleteCompany: Failed to delete logo for company %s: %v", companyID, err)
-		}
-	}
-
-	if err := h.apiService.Company.DeleteCompany(c.Context(), companyID); err != nil {
-		log.Printf("DeleteCompany: Failed to delete company %s: %v", companyID, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(models.Error{
-			Code:    "DELETE_FAILED",
-			Message: "Failed to delete company",
-		})
-	}
-
-	if err := h.apiService.Auth.DeleteUser(c.Context(), companyID); err != nil {
-		log.Printf("DeleteCompany: Failed to delete company %s: %v", companyID, err)
-	}
-
-	log.Printf("DeleteCompany: Successfully deleted company: %s", companyID)
-	return c.JSON(fiber.Map{
-		"message": "Company deleted successfully",
-	})
+	return h.startAccountDeletion(c)
 }
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/deletion"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/models"
+)
+
+// startAccountDeletion запускает удаление аккаунта текущего пользователя во
+// всех сервисах и отвечает 202 с адресом, где следить за ходом удаления.
+func (h *Handler) startAccountDeletion(c *fiber.Ctx) error {
+	userID := getUserIDFromContext(c)
+	if _, err := uuid.Parse(userID); err != nil {
+		log.Printf("Account deletion: invalid UUID format: %s", userID)
+		return c.Status(fiber.StatusBadRequest).JSON(models.Error{
+			Code:    "INVALID_USER_ID",
+			Message: "Invalid user ID format",
+		})
+	}
+
+	log.Printf("Account deletion requested by user %s (role: %s)", userID, getRoleFromContext(c))
+
+	resp, err := h.deletion.Start(c.UserContext(), userID)
+	if err != nil {
+		log.Printf("Account deletion failed to start for user %s: %v", userID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(models.Error{
+			Code:    "DELETE_FAILED",
+			Message: "Failed to delete account",
+		})
+	}
+
+	h.recordAudit(c, AuditAccountDelete, "account", userID, "", nil, fiber.Map{"deletion_id": resp.ID})
+	resp.StatusURL = "/api/v1/account/deletion/" + resp.ID
+	return c.Status(fiber.StatusAccepted).JSON(resp)
+}
+
+// GetAccountDeletion возвращает ход удаления аккаунта
+// @Summary Статус удаления аккаунта
+// @Description Ход удаления аккаунта по id из ответа на удаление. Токен не нужен: токены аккаунта отозваны в первом же шаге, а id знает только инициатор. После завершения статус хранится несколько дней.
+// @Tags Account
+// @Produce json
+// @Param id path string true "ID удаления"
+// @Success 200 {object} models.AccountDeletion "Ход удаления"
+// @Failure 404 {object} models.ErrorResponse "Удаление не найдено"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /account/deletion/{id} [get]
+func (h *Handler) GetAccountDeletion(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	resp, err := h.deletion.Get(c.UserContext(), id)
+	if err != nil {
+		if errors.Is(err, deletion.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(models.Error{
+				Code:    "DELETION_NOT_FOUND",
+				Message: "Account deletion not found",
+			})
+		}
+		log.Printf("GetAccountDeletion failed for %s: %v", id, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(models.Error{
+			Code:    "INTERNAL_ERROR",
+			Message: "Failed to get account deletion status",
+		})
+	}
+
+	return c.JSON(resp)
+}
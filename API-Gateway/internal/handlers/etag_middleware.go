@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ETagMiddleware ставит сильный ETag на успешные GET-ответы — и из кэша,
+// и собранные хендлером, — и отвечает 304 Not Modified, если клиент прислал
+// совпадающий If-None-Match. SPA экономит трафик и разбор JSON на опросе
+// неизменившихся списков.
+//
+// ETag, уже выставленный раньше (запись кэша хранит свой), не пересчитывается.
+// Потоковые ответы (файлы) пропускаются: чтобы их хэшировать, пришлось бы
+// вычитать поток в память.
+//
+// Ответы зависят от токена, поэтому без явного Cache-Control ставим
+// "private, no-cache": браузер хранит копию, но каждый раз ревалидирует её,
+// а общие прокси не хранят вовсе.
+func ETagMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Method() != fiber.MethodGet {
+			return c.Next()
+		}
+		if err := c.Next(); err != nil {
+			return err
+		}
+		resp := c.Response()
+		if resp.StatusCode() != fiber.StatusOK || resp.IsBodyStream() {
+			return nil
+		}
+		etag := string(resp.Header.Peek(fiber.HeaderETag))
+		if etag == "" {
+			body := resp.Body()
+			if len(body) == 0 {
+				return nil
+			}
+			etag = etagOf(body)
+			c.Set(fiber.HeaderETag, etag)
+		}
+		if len(resp.Header.Peek(fiber.HeaderCacheControl)) == 0 {
+			c.Set(fiber.HeaderCacheControl, "private, no-cache")
+		}
+		if etagMatches(c.Get(fiber.HeaderIfNoneMatch), etag) {
+			resp.ResetBody()
+			c.Status(fiber.StatusNotModified)
+		}
+		return nil
+	}
+}
+
+// etagOf — сильный ETag тела: первые 16 байт SHA-256 в кавычках.
+func etagOf(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches — If-None-Match совпадает с etag. Для If-None-Match
+// сравнение слабое (RFC 9110, 13.1.2): префикс W/ у тегов клиента игнорируется.
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -12,7 +12,7 @@ func (h *Handler) GetExpertiseTest(c *fiber.Ctx) error {
 	if slug == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "slug is required"})
 	}
-	t, err := h.apiService.User.GetExpertiseTest(c.Context(), slug)
+	t, err := h.apiService.User.GetExpertiseTest(c.UserContext(), slug)
 	if err != nil {
 		log.Printf("GetExpertiseTest slug=%s failed: %v", slug, err)
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
@@ -51,7 +51,7 @@ func (h *Handler) SubmitExpertiseTest(c *fiber.Ctx) error {
 	if err := c.BodyParser(&body); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid body"})
 	}
-	resp, err := h.apiService.User.SubmitExpertiseTest(c.Context(), userID, slug, body.AnswerIndices)
+	resp, err := h.apiService.User.SubmitExpertiseTest(c.UserContext(), userID, slug, body.AnswerIndices)
 	if err != nil {
 		log.Printf("SubmitExpertiseTest user=%s slug=%s failed: %v", userID, slug, err)
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
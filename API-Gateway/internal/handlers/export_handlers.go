@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/export"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/models"
+)
+
+// StartDataExport запускает выгрузку персональных данных
+// @Summary Выгрузить свои данные
+// @Description Запускает сборку ZIP-архива со всеми данными пользователя: аккаунт, профиль, сообщения в чатах, отклики, решения микрозадач, членство в компаниях, достижения и их файлы. Архив собирается в фоне; ход выгрузки и ссылка на скачивание — по status_url.
+// @Tags Account
+// @Produce json
+// @Security BearerAuth
+// @Success 202 {object} models.DataExport "Выгрузка запущена"
+// @Failure 401 {object} models.ErrorResponse "Неавторизованный доступ"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /account/export [post]
+func (h *Handler) StartDataExport(c *fiber.Ctx) error {
+	userID := getUserIDFromContext(c)
+	if _, err := uuid.Parse(userID); err != nil {
+		log.Printf("Data export: invalid UUID format: %s", userID)
+		return c.Status(fiber.StatusBadRequest).JSON(models.Error{
+			Code:    "INVALID_USER_ID",
+			Message: "Invalid user ID format",
+		})
+	}
+
+	log.Printf("Data export requested by user %s (role: %s)", userID, getRoleFromContext(c))
+
+	resp, err := h.export.Start(c.UserContext(), userID)
+	if err != nil {
+		log.Printf("Data export failed to start for user %s: %v", userID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(models.Error{
+			Code:    "EXPORT_FAILED",
+			Message: "Failed to start data export",
+		})
+	}
+
+	resp.StatusURL = "/api/v1/account/export/" + resp.ID
+	return c.Status(fiber.StatusAccepted).JSON(resp)
+}
+
+// GetDataExport возвращает ход выгрузки и ссылку на архив
+// @Summary Статус выгрузки данных
+// @Description Ход выгрузки по id из ответа на запуск. Когда архив готов, возвращает ссылку на скачивание с ограниченным сроком действия; каждый запрос выдаёт новую ссылку. Архив хранится несколько дней, затем статус становится expired.
+// @Tags Account
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID выгрузки"
+// @Success 200 {object} models.DataExport "Ход выгрузки"
+// @Failure 401 {object} models.ErrorResponse "Неавторизованный доступ"
+// @Failure 404 {object} models.ErrorResponse "Выгрузка не найдена"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /account/export/{id} [get]
+func (h *Handler) GetDataExport(c *fiber.Ctx) error {
+	userID := getUserIDFromContext(c)
+	id := c.Params("id")
+
+	resp, err := h.export.Get(c.UserContext(), userID, id)
+	if err != nil {
+		if errors.Is(err, export.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(models.Error{
+				Code:    "EXPORT_NOT_FOUND",
+				Message: "Data export not found",
+			})
+		}
+		log.Printf("GetDataExport failed for %s: %v", id, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(models.Error{
+			Code:    "INTERNAL_ERROR",
+			Message: "Failed to get data export status",
+		})
+	}
+
+	return c.JSON(resp)
+}
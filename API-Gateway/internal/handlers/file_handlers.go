@@ -26,7 +26,7 @@ func (h *Handler) ServeFileDirect(c *fiber.Ctx) error {
 
 	log.Printf("ServeFileDirect: Serving file %s for entity %s", fileName, entityID)
 
-	downloadURL, err := h.apiService.Achievement.GetAchievementDownloadUrl(c.Context(), entityID, fileName)
+	downloadURL, err := h.apiService.Achievement.GetAchievementDownloadUrl(c.UserContext(), entityID, fileName)
 	if err != nil {
 		log.Printf("ServeFileDirect: Failed to get download URL for file %s: %v", fileName, err)
 		return c.Status(fiber.StatusNotFound).JSON(models.Error{
@@ -73,7 +73,7 @@ func (h *Handler) UploadUserAvatar(c *fiber.Ctx) error {
 	}
 
 	fileInfo, err := h.fileHandler.UploadFileDirect(
-		c.Context(),
+		c.UserContext(),
 		userID,
 		"user",
 		"avatar",
@@ -88,7 +88,7 @@ func (h *Handler) UploadUserAvatar(c *fiber.Ctx) error {
 	}
 
 	avatarID := fileInfo.Name
-	_, err = h.apiService.User.UpdateUser(c.Context(), &usersv1.UpdateProfileRequest{
+	_, err = h.apiService.User.UpdateUser(c.UserContext(), &usersv1.UpdateProfileRequest{
 		Id: userID,
 		Profile: &usersv1.Profile{
 			AvatarId: avatarID,
@@ -140,7 +140,7 @@ func (h *Handler) UploadUserResume(c *fiber.Ctx) error {
 	}
 
 	fileInfo, err := h.fileHandler.UploadFileDirect(
-		c.Context(),
+		c.UserContext(),
 		userID,
 		"user",
 		"resume",
@@ -155,7 +155,7 @@ func (h *Handler) UploadUserResume(c *fiber.Ctx) error {
 	}
 
 	resumeID := fileInfo.Name
-	_, err = h.apiService.User.UpdateUser(c.Context(), &usersv1.UpdateProfileRequest{
+	_, err = h.apiService.User.UpdateUser(c.UserContext(), &usersv1.UpdateProfileRequest{
 		Id: userID,
 		Profile: &usersv1.Profile{
 			ResumeId: resumeID,
@@ -189,7 +189,7 @@ func (h *Handler) DeleteUserAvatar(c *fiber.Ctx) error {
 	userID := getUserIDFromContext(c)
 	log.Printf("DeleteUserAvatar: Deleting avatar for user: %s", userID)
 
-	profile, err := h.apiService.User.GetUser(c.Context(), userID)
+	profile, err := h.apiService.User.GetUser(c.UserContext(), userID)
 	if err != nil {
 		log.Printf("DeleteUserAvatar: User not found: %s", userID)
 		return c.Status(fiber.StatusNotFound).JSON(models.Error{
@@ -206,7 +206,7 @@ func (h *Handler) DeleteUserAvatar(c *fiber.Ctx) error {
 		})
 	}
 
-	err = h.fileHandler.DeleteFile(c.Context(), userID, profile.AvatarId)
+	err = h.fileHandler.DeleteFile(c.UserContext(), userID, profile.AvatarId)
 	if err != nil {
 		log.Printf("DeleteUserAvatar: Failed to delete avatar file for user %s: %v", userID, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(models.Error{
@@ -215,7 +215,7 @@ func (h *Handler) DeleteUserAvatar(c *fiber.Ctx) error {
 		})
 	}
 
-	_, err = h.apiService.User.UpdateUser(c.Context(), &usersv1.UpdateProfileRequest{
+	_, err = h.apiService.User.UpdateUser(c.UserContext(), &usersv1.UpdateProfileRequest{
 		Id: userID,
 		Profile: &usersv1.Profile{
 			AvatarId: "",
@@ -248,7 +248,7 @@ func (h *Handler) DeleteUserResume(c *fiber.Ctx) error {
 	userID := getUserIDFromContext(c)
 	log.Printf("DeleteUserResume: Deleting resume for user: %s", userID)
 
-	profile, err := h.apiService.User.GetUser(c.Context(), userID)
+	profile, err := h.apiService.User.GetUser(c.UserContext(), userID)
 	if err != nil {
 		log.Printf("DeleteUserResume: User not found: %s", userID)
 		return c.Status(fiber.StatusNotFound).JSON(models.Error{
@@ -265,7 +265,7 @@ func (h *Handler) DeleteUserResume(c *fiber.Ctx) error {
 		})
 	}
 
-	err = h.fileHandler.DeleteFile(c.Context(), userID, profile.ResumeId)
+	err = h.fileHandler.DeleteFile(c.UserContext(), userID, profile.ResumeId)
 	if err != nil {
 		log.Printf("DeleteUserResume: Failed to delete resume file for user %s: %v", userID, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(models.Error{
@@ -274,7 +274,7 @@ func (h *Handler) DeleteUserResume(c *fiber.Ctx) error {
 		})
 	}
 
-	_, err = h.apiService.User.UpdateUser(c.Context(), &usersv1.UpdateProfileRequest{
+	_, err = h.apiService.User.UpdateUser(c.UserContext(), &usersv1.UpdateProfileRequest{
 		Id: userID,
 		Profile: &usersv1.Profile{
 			ResumeId: "",
@@ -328,7 +328,7 @@ func (h *Handler) UploadCompanyLogo(c *fiber.Ctx) error {
 	}
 
 	fileInfo, err := h.fileHandler.UploadFileDirect(
-		c.Context(),
+		c.UserContext(),
 		companyID,
 		"company",
 		"logo",
@@ -343,7 +343,7 @@ func (h *Handler) UploadCompanyLogo(c *fiber.Ctx) error {
 	}
 
 	logoID := fileInfo.Name
-	_, err = h.apiService.Company.UpdateCompany(c.Context(), companyID, &models.Company{
+	_, err = h.apiService.Company.UpdateCompany(c.UserContext(), companyID, &models.Company{
 		LogoID: &logoID,
 	})
 	if err != nil {
@@ -395,7 +395,7 @@ func (h *Handler) UploadCompanyDocument(c *fiber.Ctx) error {
 	}
 
 	fileInfo, err := h.fileHandler.UploadFileDirect(
-		c.Context(),
+		c.UserContext(),
 		companyID,
 		"company",
 		"document",
@@ -435,7 +435,7 @@ func (h *Handler) DeleteCompanyLogo(c *fiber.Ctx) error {
 	companyID := c.Params("id")
 	log.Printf("DeleteCompanyLogo: Deleting logo for company: %s", companyID)
 
-	company, err := h.apiService.Company.GetCompany(c.Context(), companyID)
+	company, err := h.apiService.Company.GetCompany(c.UserContext(), companyID)
 	if err != nil {
 		log.Printf("DeleteCompanyLogo: Company not found: %s", companyID)
 		return c.Status(fiber.StatusNotFound).JSON(models.Error{
@@ -452,7 +452,7 @@ func (h *Handler) DeleteCompanyLogo(c *fiber.Ctx) error {
 		})
 	}
 
-	err = h.fileHandler.DeleteFile(c.Context(), companyID, *company.LogoID)
+	err = h.fileHandler.DeleteFile(c.UserContext(), companyID, *company.LogoID)
 	if err != nil {
 		log.Printf("DeleteCompanyLogo: Failed to delete logo file for company %s: %v", companyID, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(models.Error{
@@ -462,7 +462,7 @@ func (h *Handler) DeleteCompanyLogo(c *fiber.Ctx) error {
 	}
 
 	emptyLogoID := ""
-	_, err = h.apiService.Company.UpdateCompany(c.Context(), companyID, &models.Company{
+	_, err = h.apiService.Company.UpdateCompany(c.UserContext(), companyID, &models.Company{
 		LogoID: &emptyLogoID,
 	})
 	if err != nil {
@@ -2,12 +2,20 @@ package handlers
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/authn"
 	"github.com/studjobs/hh_for_students/api-gateway/internal/cache"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/cleaner"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/deletion"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/export"
 	"github.com/studjobs/hh_for_students/api-gateway/internal/metrics"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/policy"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/registration"
 	"github.com/studjobs/hh_for_students/api-gateway/internal/services"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/tracing"
 	"github.com/studjobs/hh_for_students/api-gateway/internal/utils"
 	"log"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	swagger "github.com/arsmn/fiber-swagger/v2"
@@ -19,18 +27,52 @@ type Handler struct {
 	fileHandler  *utils.FileHandler
 	cacheClient  *cache.Client
 	rateLimiter  *RateLimiter
+	verifier     *authn.Verifier
+	policy       *VerificationPolicy
+	registration *registration.Service
+	deletion     *deletion.Service
+	export       *export.Service
+	cleaner      *cleaner.Cleaner
+	// reindexing — идёт перестройка поисковых индексов, запущенная администратором.
+	reindexing atomic.Bool
+	// oidcFrontendURL — страница фронтенда, куда callback провайдера
+	// возвращает браузер (пусто — callback отвечает JSON).
+	oidcFrontendURL string
+	// proxies — доверенные reverse proxy: только их X-Forwarded-For задаёт
+	// адрес клиента.
+	proxies *TrustedProxies
+	// authz — политика доступа к маршрутам; routes — маршруты /api/v1 с
+	// действиями политики (заполняет securedGroup).
+	authz  *policy.Engine
+	routes []policy.Route
 }
 
 // NewHandler создает новый экземпляр Handler.
 // cacheClient — может быть nil (тогда middleware no-op'ит).
 // rateLimiter — может быть nil (тогда не применяется).
-func NewHandler(apiService *services.ApiGateway, cacheClient *cache.Client, rateLimiter *RateLimiter) *Handler {
+// verifier — может быть nil (тогда каждый токен проверяется в Auth).
+// verificationPolicy — может быть nil (тогда подтверждение email ничего не блокирует).
+// authz — обязателен: политика доступа всех маршрутов /api/v1.
+// cleanerWorker — может быть nil (тогда ручной запуск автоочистки недоступен).
+// oidcFrontendURL — может быть пустым (тогда callback входа через провайдера отвечает JSON).
+// proxies — может быть nil (тогда X-Forwarded-For не учитывается, клиент — адрес соединения).
+func NewHandler(apiService *services.ApiGateway, cacheClient *cache.Client, rateLimiter *RateLimiter, verifier *authn.Verifier, verificationPolicy *VerificationPolicy, authz *policy.Engine, registrationService *registration.Service, deletionService *deletion.Service, exportService *export.Service, cleanerWorker *cleaner.Cleaner, oidcFrontendURL string, proxies *TrustedProxies) *Handler {
 	log.Printf("Creating new Handler")
 	return &Handler{
 		apiService:  apiService,
 		fileHandler: utils.NewFileHandler(apiService),
 		cacheClient: cacheClient,
 		rateLimiter: rateLimiter,
+		verifier:    verifier,
+		policy:      verificationPolicy,
+		authz:       authz,
+
+		registration:    registrationService,
+		deletion:        deletionService,
+		export:          exportService,
+		cleaner:         cleanerWorker,
+		oidcFrontendURL: oidcFrontendURL,
+		proxies:         proxies,
 	}
 }
 
@@ -43,19 +85,37 @@ func (h *Handler) Init() *fiber.App {
 		Prefork:       false,
 		CaseSensitive: true,
 		StrictRouting: false,
+		// X-Forwarded-* (адрес, протокол, хост) — только от доверенных прокси.
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          h.proxies.List(),
 	})
+	// Адрес клиента — до всего, что по нему считает лимиты или пишет аудит.
+	h.app.Use(ClientIPMiddleware(h.proxies))
+	// Спан запроса — сразу за ним: в него попадают и rate limit, и аутентификация.
+	h.app.Use(tracing.Middleware())
 	h.app.Use(metrics.HTTPMiddleware())
-	// Rate limit идёт ПЕРЕД auth: на /auth/login тоже распространяется. Иначе
-	// botnet может бесконечно проверять пароли без ограничений.
+	// Потолок на IP — перед auth: токены, которые Gateway не проверит сам
+	// (неизвестный kid, чужой alg), и API-ключи уходят в Auth, и их поток
+	// должен упереться в лимит раньше.
 	if h.rateLimiter != nil {
-		h.app.Use(h.rateLimiter.Middleware())
+		h.app.Use(h.rateLimiter.PreAuth())
 	}
-	h.app.Use(AuthMiddleware(h.apiService))
-	// Cache идёт ПОСЛЕ auth — чтобы middleware видел уже-проверенные запросы и
-	// 401/403 не попадали в кэш как валидные ответы.
-	if h.cacheClient != nil && h.cacheClient.Enabled() {
-		h.app.Use(CacheMiddleware(h.cacheClient))
+	var validator TokenValidator = h.apiService.Auth
+	if h.verifier != nil {
+		validator = h.verifier
 	}
+	h.app.Use(AuthMiddleware(validator))
+	// Лимит маршрута — после auth: per-user лимит считается по проверенному
+	// токену, а не по payload, который клиент может подделать. /auth/login и
+	// прочие публичные маршруты AuthMiddleware пропускает без токена — их
+	// лимит считается по IP, перебор паролей по-прежнему ограничен.
+	if h.rateLimiter != nil {
+		h.app.Use(h.rateLimiter.Middleware())
+	}
+	// ETag и 304 — для всех GET, включая некэшируемые маршруты и HIT из кэша.
+	h.app.Use(ETagMiddleware())
+	// Cache — в цепочке каждого маршрута после authorize (см. securedGroup),
+	// чтобы 401/403 не попадали в кэш и HIT не обходил политику доступа.
 
 	// Swagger документация
 	h.app.Get("/swagger/*", swagger.HandlerDefault)
@@ -65,157 +125,200 @@ func (h *Handler) Init() *fiber.App {
 	return h.app
 }
 
+// initRoutes регистрирует маршруты /api/v1. Второй аргумент — действие
+// политики доступа (configs/policy.yaml): кто и над какими ресурсами может
+// его выполнять, описано там, а не здесь.
 func (h *Handler) initRoutes() {
-	api := h.app.Group("/api/v1")
+	api := h.secure(h.app, "/api/v1")
 
 	// === Auth routes ===
 	auth := api.Group("/auth")
-	auth.Post("/login", h.Login)
-	auth.Post("/register", h.Register)
+	auth.Post("/login", policy.Public, h.Login)
+	auth.Post("/register", policy.Public, h.Register)
+	auth.Post("/refresh", policy.Public, h.Refresh)
+	auth.Post("/password/reset", policy.Public, h.RequestPasswordReset)
+	auth.Post("/password/reset/confirm", policy.Public, h.ConfirmPasswordReset)
+	auth.Post("/logout", "account.session", h.Logout)
+	auth.Post("/unlock", "account.unlock", h.UnlockAccount)
+	auth.Post("/switch-role", "account.session", h.SwitchRole)
+	// Второй фактор: /mfa/verify и /mfa/enroll* — шаги входа по mfa_token (без
+	// access-токена), /mfa/totp* и /mfa/recovery-codes — управление из профиля.
+	auth.Post("/mfa/verify", policy.Public, h.VerifyMFA)
+	auth.Post("/mfa/enroll", policy.Public, h.EnrollTOTP)
+	auth.Post("/mfa/enroll/confirm", policy.Public, h.ConfirmTOTP)
+	auth.Post("/mfa/totp", "account.mfa", h.EnrollTOTP)
+	auth.Post("/mfa/totp/confirm", "account.mfa", h.ConfirmTOTP)
+	auth.Post("/mfa/totp/disable", "account.mfa", h.DisableTOTP)
+	auth.Post("/mfa/recovery-codes", "account.mfa", h.RegenerateRecoveryCodes)
+	// Вход через внешних провайдеров: /start и /callback открывает браузер
+	// (без access-токена), /link — привязка провайдера из профиля.
+	auth.Get("/oidc/providers", policy.Public, h.OIDCProviders)
+	auth.Get("/oidc/:provider/start", policy.Public, h.StartOIDC)
+	auth.Get("/oidc/:provider/callback", policy.Public, h.OIDCCallback)
+	auth.Post("/oidc/:provider/link", "account.link_provider", h.LinkOIDC)
+	// Сессии (входы на устройствах) текущего пользователя.
+	auth.Get("/sessions", "account.session", h.ListSessions)
+	auth.Post("/sessions/revoke-others", "account.session", h.RevokeOtherSessions)
+	auth.Delete("/sessions/:id", "account.session", h.RevokeSession)
+	auth.Post("/email/verify", policy.Public, h.ConfirmEmail)
+	auth.Post("/email/verify/resend", "account.session", h.ResendVerification)
+
+	// === Account routes ===
+	// Ход удаления аккаунта: без токена — токены удаляемого аккаунта уже
+	// отозваны, доступ даёт знание id.
+	account := api.Group("/account")
+	account.Get("/deletion/:id", policy.Public, h.GetAccountDeletion)
+	// Выгрузка персональных данных: архив видит только его владелец.
+	account.Post("/export", "account.export", h.StartDataExport)
+	account.Get("/export/:id", "account.export", h.GetDataExport)
 
 	// === File routes ===
 	files := api.Group("/files")
-	files.Get("/:entity_id/:file_name", RoleMiddleware(ROLE_DEVELOPER, ROLE_STUDENT, ROLE_HR, ROLE_COMPANY, ROLE_EXPERT), h.ServeFileDirect)
+	files.Get("/:entity_id/:file_name", "file.read", h.ServeFileDirect)
 
 	// === User routes ===
 	users := api.Group("/users")
-	// ПРАВИЛЬНО: Middleware идут до обработчика
-	users.Get("/", RoleMiddleware(ROLE_DEVELOPER, ROLE_STUDENT, ROLE_HR, ROLE_COMPANY, ROLE_EXPERT), h.GetUsers)
-	users.Get("/me", RoleMiddleware(ROLE_DEVELOPER, ROLE_STUDENT, ROLE_EXPERT, ROLE_HR, ROLE_COMPANY), h.GetMe)
-	users.Get("/:id", RoleMiddleware(ROLE_DEVELOPER, ROLE_STUDENT, ROLE_HR, ROLE_COMPANY, ROLE_EXPERT), h.GetUser)
-	users.Get("/:id/achievements", RoleMiddleware(ROLE_DEVELOPER, ROLE_STUDENT, ROLE_HR, ROLE_COMPANY, ROLE_EXPERT), h.GetUserAchievementsByID)
-	// Для /edit нет параметра :id, поэтому используем RoleMiddleware.
-	// Проверка, что юзер меняет именно себя, должна быть внутри h.UpdateUser.
-	users.Patch("/edit", RoleMiddleware(ROLE_DEVELOPER, ROLE_STUDENT, ROLE_EXPERT), h.UpdateUser)
-	users.Delete("/", RoleMiddleware(ROLE_DEVELOPER, ROLE_STUDENT), h.DeleteUser)
+	users.Get("/", "user.list", h.GetUsers)
+	users.Get("/me", "user.read_self", h.GetMe)
+	users.Get("/:id", "user.read", h.GetUser)
+	users.Get("/:id/achievements", "user.read", h.GetUserAchievementsByID)
+	// /edit и удаление — над своим профилем (id из токена).
+	users.Patch("/edit", "user.update_self", h.UpdateUser)
+	users.Delete("/", "user.delete_self", h.DeleteUser)
 
 	// === User File routes ===
+	// Юзер загружает файлы для себя.
 	userFiles := users.Group("/files")
-	// Здесь нет параметра :id, подразумевается, что юзер загружает файлы для себя.
-	userFiles.Post("/avatar", RoleMiddleware(ROLE_DEVELOPER, ROLE_STUDENT, ROLE_HR), h.UploadUserAvatar)
-	userFiles.Post("/resume", RoleMiddleware(ROLE_DEVELOPER, ROLE_STUDENT, ROLE_HR), h.UploadUserResume)
-	userFiles.Delete("/avatar", RoleMiddleware(ROLE_DEVELOPER, ROLE_STUDENT, ROLE_HR), h.DeleteUserAvatar)
-	userFiles.Delete("/resume", RoleMiddleware(ROLE_DEVELOPER, ROLE_STUDENT, ROLE_HR), h.DeleteUserResume)
+	userFiles.Post("/avatar", "user.files", h.UploadUserAvatar)
+	userFiles.Post("/resume", "user.files", h.UploadUserResume)
+	userFiles.Delete("/avatar", "user.files", h.DeleteUserAvatar)
+	userFiles.Delete("/resume", "user.files", h.DeleteUserResume)
 
 	// === User Achievement routes ===
+	// :id — имя достижения юзера (для /submit — числовой achievement ID).
 	userAchievement := api.Group("/user/achievements")
-	userAchievement.Get("/", RoleMiddleware(ROLE_DEVELOPER, ROLE_STUDENT, ROLE_HR, ROLE_EXPERT), h.GetUserAchievements)
-	userAchievement.Post("/", RoleMiddleware(ROLE_DEVELOPER, ROLE_STUDENT), h.CreateUserAchievement)                                                // Нет :id
-	userAchievement.Post("/:id/confirm", OwnerOrRoleMiddleware(ID, ROLE_DEVELOPER, ROLE_STUDENT), h.ConfirmAchievementUpload)                       // Есть :id (имя достижения)
-	userAchievement.Get("/:id/download", OwnerOrRoleMiddleware(ID, ROLE_DEVELOPER, ROLE_STUDENT, ROLE_HR, ROLE_EXPERT), h.GetAchievementDownloadUrl) // Есть :id (имя)
-	userAchievement.Delete("/:id", OwnerOrRoleMiddleware(ID, ROLE_DEVELOPER, ROLE_STUDENT), h.DeleteAchievement)                                    // Есть :id (имя)
-	userAchievement.Post("/:id/submit", RoleMiddleware(ROLE_DEVELOPER, ROLE_STUDENT), h.SubmitAchievementForReview)                                 // :id — числовой achievement ID
+	userAchievement.Get("/", "achievement.list", h.GetUserAchievements)
+	userAchievement.Post("/", "achievement.create", h.CreateUserAchievement)
+	userAchievement.Post("/:id/confirm", "achievement.create", h.ConfirmAchievementUpload)
+	userAchievement.Get("/:id/download", "achievement.download", h.GetAchievementDownloadUrl)
+	userAchievement.Delete("/:id", "achievement.delete", h.DeleteAchievement)
+	userAchievement.Post("/:id/submit", "achievement.submit", h.SubmitAchievementForReview)
 
 	// === Expert review routes ===
 	expert := api.Group("/expert")
-	expert.Get("/queue", RoleMiddleware(ROLE_DEVELOPER, ROLE_EXPERT), h.GetExpertQueue)
-	expert.Post("/achievements/:id/review", RoleMiddleware(ROLE_DEVELOPER, ROLE_EXPERT), h.ReviewAchievement)
-	expert.Post("/quests", RoleMiddleware(ROLE_DEVELOPER, ROLE_EXPERT), h.CreateSkillQuest)
-	expert.Get("/test/:slug", RoleMiddleware(ROLE_DEVELOPER, ROLE_EXPERT), h.GetExpertiseTest)
-	expert.Post("/test/:slug", RoleMiddleware(ROLE_DEVELOPER, ROLE_EXPERT), h.SubmitExpertiseTest)
+	expert.Get("/queue", "expert.review", h.GetExpertQueue)
+	expert.Post("/achievements/:id/review", "expert.review", h.ReviewAchievement)
+	expert.Post("/quests", "expert.quest", h.CreateSkillQuest)
+	expert.Get("/test/:slug", "expert.test", h.GetExpertiseTest)
+	expert.Post("/test/:slug", "expert.test", h.SubmitExpertiseTest)
 
 	// === Chat (минимальный polling, без WS) ===
 	// thread_id строится из двух path-сегментов: /chat/<kind>/<resource_uuid>.
 	// Двоеточие в URL Fiber не декодирует надёжно — поэтому kind и id отдельно.
 	chat := api.Group("/chat")
-	chat.Get("/threads", RoleMiddleware(ROLE_DEVELOPER, ROLE_STUDENT, ROLE_HR, ROLE_COMPANY, ROLE_EXPERT), h.GetChatThreads)
-	chat.Patch("/messages/:msg_id", RoleMiddleware(ROLE_DEVELOPER, ROLE_STUDENT, ROLE_HR, ROLE_COMPANY, ROLE_EXPERT), h.EditChatMessage)
-	chat.Get("/:kind/:rid", RoleMiddleware(ROLE_DEVELOPER, ROLE_STUDENT, ROLE_HR, ROLE_COMPANY, ROLE_EXPERT), h.GetChatMessages)
-	chat.Post("/:kind/:rid", RoleMiddleware(ROLE_DEVELOPER, ROLE_STUDENT, ROLE_HR, ROLE_COMPANY, ROLE_EXPERT), h.SendChatMessage)
-	chat.Delete("/:kind/:rid", RoleMiddleware(ROLE_DEVELOPER, ROLE_STUDENT, ROLE_HR, ROLE_COMPANY, ROLE_EXPERT), h.HideChatThread)
+	chat.Get("/threads", "chat.list", h.GetChatThreads)
+	chat.Patch("/messages/:msg_id", "chat.edit", h.EditChatMessage)
+	chat.Get("/:kind/:rid", "chat.read", h.GetChatMessages)
+	chat.Post("/:kind/:rid", ActionChatSend, h.policy.Require(ActionChatSend), h.SendChatMessage)
+	chat.Delete("/:kind/:rid", "chat.hide", h.HideChatThread)
 
 	// === HR routes ===
 	profileHR := api.Group("/hr")
-	profileHR.Get("/", RoleMiddleware(ROLE_DEVELOPER, ROLE_STUDENT, ROLE_HR, ROLE_COMPANY), h.GetUsers)
-	profileHR.Get("/me", RoleMiddleware(ROLE_DEVELOPER, ROLE_HR), h.GetMe)
-	profileHR.Patch("/edit", RoleMiddleware(ROLE_DEVELOPER, ROLE_HR), h.UpdateUser)
-	profileHR.Delete("/", RoleMiddleware(ROLE_DEVELOPER, ROLE_HR), h.DeleteUser)
+	profileHR.Get("/", "hr.users", h.GetUsers)
+	profileHR.Get("/me", "hr.profile", h.GetMe)
+	profileHR.Patch("/edit", "hr.profile", h.UpdateUser)
+	profileHR.Delete("/", "hr.delete_self", h.DeleteUser)
 
 	// === HR vacancy routes ===
-	// До появления HR-membership flow единственный «аутентифицированный HR» — это владелец
-	// компании (ROLE_COMPANY). ROLE_HR пускаем в whitelist, но в самом обработчике
-	// блокируем (см. CreateHRVacancy) — иначе любой HR публиковал бы вакансии под чужой
-	// company_id (была B4: дыра доступа).
+	// Владелец компании публикует сразу, HR компании — на модерацию владельцу
+	// (см. CreateHRVacancy). Править, удалять и модерировать может владелец.
 	HRVacancy := profileHR.Group("/vacancy")
-	HRVacancy.Get("/", RoleMiddleware(ROLE_DEVELOPER, ROLE_HR, ROLE_COMPANY), h.GetHRVacancies)
-	HRVacancy.Get("/:id", OwnerOrRoleMiddleware(ID, ROLE_DEVELOPER, ROLE_HR, ROLE_COMPANY), h.GetVacancy)
-	HRVacancy.Post("/", RoleMiddleware(ROLE_DEVELOPER, ROLE_HR, ROLE_COMPANY), h.CreateHRVacancy)
-	HRVacancy.Patch("/:id", OwnerOrRoleMiddleware(ID, ROLE_DEVELOPER, ROLE_HR, ROLE_COMPANY), h.UpdateVacancy)
-	HRVacancy.Delete("/:id", OwnerOrRoleMiddleware(ID, ROLE_DEVELOPER, ROLE_HR, ROLE_COMPANY), h.DeleteVacancy)
-	// Модерация (только owner компании).
-	HRVacancy.Post("/:id/moderate", RoleMiddleware(ROLE_DEVELOPER, ROLE_COMPANY), h.ModerateVacancy)
+	HRVacancy.Get("/", "vacancy.list_own", h.GetHRVacancies)
+	HRVacancy.Get("/:id", "vacancy.read_own", h.GetVacancy)
+	HRVacancy.Post("/", ActionVacancyPublish, h.policy.Require(ActionVacancyPublish), h.CreateHRVacancy)
+	HRVacancy.Patch("/:id", "vacancy.update", h.UpdateVacancy)
+	HRVacancy.Delete("/:id", "vacancy.delete", h.DeleteVacancy)
+	HRVacancy.Post("/:id/moderate", "vacancy.moderate", h.ModerateVacancy)
 
-	api.Get("/positions", OwnerOrRoleMiddleware(ID, ROLE_DEVELOPER, ROLE_HR), h.GetPositions)
+	api.Get("/positions", "position.list", h.GetPositions)
 
 	// === Vacancy routes ===
 	vacancy := api.Group("/vacancy")
-	vacancy.Get("/", RoleMiddleware(ROLE_DEVELOPER, ROLE_STUDENT, ROLE_HR), h.GetVacancies)
-	vacancy.Get("/:id", RoleMiddleware(ROLE_DEVELOPER, ROLE_STUDENT, ROLE_HR), h.GetVacancy)
+	vacancy.Get("/", "vacancy.list", h.GetVacancies)
+	vacancy.Get("/:id", "vacancy.read", h.GetVacancy)
 	// Студент откликается на вакансию (cover_letter опционален).
-	vacancy.Post("/:id/respond", RoleMiddleware(ROLE_DEVELOPER, ROLE_STUDENT), h.RespondToVacancy)
+	vacancy.Post("/:id/respond", ActionVacancyRespond, h.policy.Require(ActionVacancyRespond), h.RespondToVacancy)
 
 	// === Vacancy File routes ===
 	vacancyFiles := vacancy.Group("/:id/files")
-	vacancyFiles.Post("/attachment", OwnerOrRoleMiddleware(ID, ROLE_DEVELOPER, ROLE_HR), h.UploadVacancyAttachment)
-	vacancyFiles.Delete("/attachment", OwnerOrRoleMiddleware(ID, ROLE_DEVELOPER, ROLE_HR), h.DeleteVacancyAttachment)
+	vacancyFiles.Post("/attachment", "vacancy.attachment", h.UploadVacancyAttachment)
+	vacancyFiles.Delete("/attachment", "vacancy.attachment", h.DeleteVacancyAttachment)
 
 	// === Применения (отклики на вакансии) ===
 	// Студенческая часть.
 	userApplications := api.Group("/user/applications")
-	userApplications.Get("/", RoleMiddleware(ROLE_DEVELOPER, ROLE_STUDENT), h.ListMyApplications)
-	userApplications.Delete("/:id", RoleMiddleware(ROLE_DEVELOPER, ROLE_STUDENT), h.WithdrawApplication)
+	userApplications.Get("/", "application.list_own", h.ListMyApplications)
+	userApplications.Delete("/:id", "application.withdraw", h.WithdrawApplication)
 
 	// HR-часть: список откликов на конкретную вакансию + accept/reject.
-	HRVacancy.Get("/:id/applications", OwnerOrRoleMiddleware(ID, ROLE_DEVELOPER, ROLE_HR, ROLE_COMPANY), h.ListVacancyApplications)
-	profileHR.Patch("/applications/:id", RoleMiddleware(ROLE_DEVELOPER, ROLE_HR, ROLE_COMPANY), h.ReviewApplication)
+	HRVacancy.Get("/:id/applications", "application.list", h.ListVacancyApplications)
+	profileHR.Patch("/applications/:id", "application.review", h.ReviewApplication)
 
 	// === Skills (справочник тегов компетенций) ===
 	skills := api.Group("/skills")
-	skills.Get("/search", RoleMiddleware(ROLE_DEVELOPER, ROLE_STUDENT, ROLE_HR, ROLE_COMPANY, ROLE_EXPERT), h.SearchSkills)
-	skills.Get("/popular", RoleMiddleware(ROLE_DEVELOPER, ROLE_STUDENT, ROLE_HR, ROLE_COMPANY, ROLE_EXPERT), h.PopularSkills)
-	skills.Get("/bulk", RoleMiddleware(ROLE_DEVELOPER, ROLE_STUDENT, ROLE_HR, ROLE_COMPANY, ROLE_EXPERT), h.BulkSkills)
+	skills.Get("/search", "skill.read", h.SearchSkills)
+	skills.Get("/popular", "skill.read", h.PopularSkills)
+	skills.Get("/bulk", "skill.read", h.BulkSkills)
 
 	// === MicroTasks: студенческие операции ===
 	tasks := api.Group("/tasks")
-	tasks.Get("/", RoleMiddleware(ROLE_DEVELOPER, ROLE_STUDENT, ROLE_HR, ROLE_COMPANY), h.GetTasks)
-	tasks.Get("/mine", RoleMiddleware(ROLE_DEVELOPER, ROLE_STUDENT), h.GetMyTasks)
-	tasks.Get("/my-submissions", RoleMiddleware(ROLE_DEVELOPER, ROLE_STUDENT), h.ListMySubmissions)
-	tasks.Get("/:id", RoleMiddleware(ROLE_DEVELOPER, ROLE_STUDENT, ROLE_HR, ROLE_COMPANY), h.GetTask)
-	tasks.Post("/:id/apply", RoleMiddleware(ROLE_DEVELOPER, ROLE_STUDENT), h.ApplyToTask)
-	tasks.Post("/:id/submit", RoleMiddleware(ROLE_DEVELOPER, ROLE_STUDENT), h.SubmitTask)
-	tasks.Post("/:id/solution-upload-init", RoleMiddleware(ROLE_DEVELOPER, ROLE_STUDENT), h.SolutionUploadInit)
-	tasks.Post("/:id/solution-upload-confirm", RoleMiddleware(ROLE_DEVELOPER, ROLE_STUDENT), h.SolutionUploadConfirm)
+	tasks.Get("/", "task.list", h.GetTasks)
+	tasks.Get("/mine", "task.list_own", h.GetMyTasks)
+	tasks.Get("/my-submissions", "task.list_own", h.ListMySubmissions)
+	tasks.Get("/:id", "task.read", h.GetTask)
+	tasks.Post("/:id/apply", ActionTaskApply, h.policy.Require(ActionTaskApply), h.ApplyToTask)
+	tasks.Post("/:id/submit", "task.submit", h.SubmitTask)
+	tasks.Post("/:id/solution-upload-init", "task.submit", h.SolutionUploadInit)
+	tasks.Post("/:id/solution-upload-confirm", "task.submit", h.SolutionUploadConfirm)
 
 	// === MicroTasks: HR-операции ===
 	hrTasks := profileHR.Group("/tasks")
-	hrTasks.Get("/", RoleMiddleware(ROLE_DEVELOPER, ROLE_HR, ROLE_COMPANY), h.GetHRTasks)
-	hrTasks.Post("/", RoleMiddleware(ROLE_DEVELOPER, ROLE_HR, ROLE_COMPANY), h.CreateHRTask)
-	hrTasks.Patch("/:id", RoleMiddleware(ROLE_DEVELOPER, ROLE_HR, ROLE_COMPANY), h.UpdateHRTask)
-	hrTasks.Delete("/:id", RoleMiddleware(ROLE_DEVELOPER, ROLE_HR, ROLE_COMPANY), h.DeleteHRTask)
-	hrTasks.Get("/:id/submissions", RoleMiddleware(ROLE_DEVELOPER, ROLE_HR, ROLE_COMPANY), h.ListTaskSubmissions)
-	hrTasks.Post("/submissions/:submission_id/review", RoleMiddleware(ROLE_DEVELOPER, ROLE_HR, ROLE_COMPANY), h.ReviewSubmission)
+	hrTasks.Get("/", "task.list_company", h.GetHRTasks)
+	hrTasks.Post("/", ActionTaskPublish, h.policy.Require(ActionTaskPublish), h.CreateHRTask)
+	hrTasks.Patch("/:id", "task.update", h.UpdateHRTask)
+	hrTasks.Delete("/:id", "task.delete", h.DeleteHRTask)
+	hrTasks.Get("/:id/submissions", "task.submissions", h.ListTaskSubmissions)
+	hrTasks.Post("/submissions/:submission_id/review", "task.review", h.ReviewSubmission)
 
 	// === Company ===
 	company := api.Group("/company")
-	company.Get("/", RoleMiddleware(ROLE_DEVELOPER, ROLE_STUDENT, ROLE_HR, ROLE_COMPANY), h.GetCompanies)
-	company.Get("/me", RoleMiddleware(ROLE_DEVELOPER, ROLE_COMPANY), h.GetCompanyMe)
+	company.Get("/", "company.list", h.GetCompanies)
+	company.Get("/me", "company.read_own", h.GetCompanyMe)
+	// API-ключи интеграций: управляет владелец компании по JWT, сам ключ сюда не пускает.
+	company.Get("/api-keys", "company.api_keys", h.ListAPIKeys)
+	company.Post("/api-keys", "company.api_keys", h.CreateAPIKey)
+	company.Delete("/api-keys/:id", "company.api_keys", h.RevokeAPIKey)
 	// HR-membership-эндпоинты регистрируются ДО /:id, иначе Fiber интерпретирует
 	// "members" / "membership" как :id и роутит в GetCompanyByID → "Company not found".
-	company.Get("/membership/my", RoleMiddleware(ROLE_DEVELOPER, ROLE_HR, ROLE_COMPANY), h.MyMembership)
-	company.Get("/memberships/my", RoleMiddleware(ROLE_DEVELOPER, ROLE_HR, ROLE_COMPANY), h.MyMemberships)
-	company.Get("/members", RoleMiddleware(ROLE_DEVELOPER, ROLE_COMPANY), h.ListMyCompanyMembers)
-	company.Post("/membership/:membership_id/review", RoleMiddleware(ROLE_DEVELOPER, ROLE_COMPANY), h.ReviewMembership)
-	company.Get("/:id", RoleMiddleware(ROLE_DEVELOPER, ROLE_STUDENT, ROLE_HR, ROLE_COMPANY), h.GetCompanyByID)
-	company.Patch("/", RoleMiddleware(ROLE_DEVELOPER, ROLE_COMPANY), h.UpdateCompany)  // Нет :id
-	company.Delete("/", RoleMiddleware(ROLE_DEVELOPER, ROLE_COMPANY), h.DeleteCompany) // Нет :id
+	company.Get("/membership/my", "membership.read_own", h.MyMembership)
+	company.Get("/memberships/my", "membership.read_own", h.MyMemberships)
+	company.Get("/members", "company.members", h.ListMyCompanyMembers)
+	company.Post("/membership/:membership_id/review", "membership.review", h.ReviewMembership)
+	company.Get("/audit", ActionCompanyAudit, h.CompanyAuditLog)
+	company.Get("/:id", "company.read", h.GetCompanyByID)
+	company.Patch("/", "company.update", h.UpdateCompany)  // Нет :id
+	company.Delete("/", "company.delete", h.DeleteCompany) // Нет :id
 
 	// === Company File routes ===
 	companyFiles := company.Group("/:id/files")
-	companyFiles.Post("/logo", OwnerOrRoleMiddleware(ID, ROLE_DEVELOPER, ROLE_COMPANY), h.UploadCompanyLogo)
-	companyFiles.Post("/documents", OwnerOrRoleMiddleware(ID, ROLE_DEVELOPER, ROLE_COMPANY), h.UploadCompanyDocument)
-	companyFiles.Delete("/logo", OwnerOrRoleMiddleware(ID, ROLE_DEVELOPER, ROLE_COMPANY), h.DeleteCompanyLogo)
+	companyFiles.Post("/logo", "company.files", h.UploadCompanyLogo)
+	companyFiles.Post("/documents", "company.files", h.UploadCompanyDocument)
+	companyFiles.Delete("/logo", "company.files", h.DeleteCompanyLogo)
+
+	company.Post("/:id/membership/apply", ActionMembershipApply, h.policy.Require(ActionMembershipApply), h.ApplyMembership)
 
-	company.Post("/:id/membership/apply", RoleMiddleware(ROLE_DEVELOPER, ROLE_HR), h.ApplyMembership)
+	// === Администрирование платформы (ROLE_ADMIN) ===
+	h.initAdminRoutes(api)
 }
 
 const (
@@ -252,19 +355,12 @@ func splitCSV(raw string) []string {
 	return out
 }
 
+// roleConvert возвращает имя роли для Auth. Неизвестная роль даёт пустую
+// строку (Auth ответит InvalidArgument): подставлять ROLE_STUDENT по умолчанию
+// нельзя — у аккаунта с несколькими ролями это выпустило бы чужой токен.
 func (h *Handler) roleConvert(userRole Role) string {
-	switch userRole {
-	case ROLE_COMPANY:
-		return "ROLE_COMPANY_OWNER"
-	case ROLE_DEVELOPER:
-		return "ROLE_DEVELOPER"
-	case ROLE_STUDENT:
-		return "ROLE_STUDENT"
-	case ROLE_HR:
-		return "ROLE_EMPLOYER"
-	case ROLE_EXPERT:
-		return "ROLE_EXPERT"
-	default:
-		return "ROLE_STUDENT"
+	if _, ok := knownRoles[string(userRole)]; !ok {
+		return ""
 	}
+	return string(userRole)
 }
@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/models"
 )
 
 // ApplyMembership — HR подаёт заявку быть сотрудником компании company_id.
@@ -19,7 +20,7 @@ func (h *Handler) ApplyMembership(c *fiber.Ctx) error {
 		Note string `json:"note"`
 	}
 	_ = c.BodyParser(&body)
-	m, err := h.apiService.Company.ApplyMembership(c.Context(), companyID, userID, body.Note)
+	m, err := h.apiService.Company.ApplyMembership(c.UserContext(), companyID, userID, body.Note)
 	if err != nil {
 		log.Printf("ApplyMembership user=%s company=%s failed: %v", userID, companyID, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
@@ -33,7 +34,7 @@ func (h *Handler) MyMembership(c *fiber.Ctx) error {
 	if userID == "" {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
 	}
-	m, err := h.apiService.Company.GetMembershipByUser(c.Context(), userID)
+	m, err := h.apiService.Company.GetMembershipByUser(c.UserContext(), userID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "no membership"})
 	}
@@ -50,7 +51,7 @@ func (h *Handler) MyMemberships(c *fiber.Ctx) error {
 	}
 	statusStr := c.Query("status", "0")
 	st, _ := strconv.Atoi(statusStr)
-	list, err := h.apiService.Company.ListMembershipsByUser(c.Context(), userID, int32(st))
+	list, err := h.apiService.Company.ListMembershipsByUser(c.UserContext(), userID, int32(st))
 	if err != nil {
 		log.Printf("MyMemberships failed: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
@@ -67,7 +68,7 @@ func (h *Handler) ListMyCompanyMembers(c *fiber.Ctx) error {
 	// owner.userID == owner.companyID по соглашению Company-сервиса.
 	statusStr := c.Query("status", "0")
 	st, _ := strconv.Atoi(statusStr)
-	list, err := h.apiService.Company.ListMembers(c.Context(), ownerID, int32(st))
+	list, err := h.apiService.Company.ListMembers(c.UserContext(), ownerID, int32(st))
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -87,29 +88,33 @@ func (h *Handler) ReviewMembership(c *fiber.Ctx) error {
 	if err := c.BodyParser(&body); err != nil || (body.Status != 2 && body.Status != 3) {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "status must be 2 or 3"})
 	}
-	m, err := h.apiService.Company.ReviewMembership(c.Context(), membershipID, body.Status)
+	// Состояние до решения — для журнала аудита; сбой чтения ревью не блокирует.
+	var before *models.CompanyMember
+	if members, lErr := h.apiService.Company.ListMembers(c.UserContext(), ownerID, 0); lErr == nil {
+		for _, member := range members {
+			if member.ID == membershipID {
+				before = member
+				break
+			}
+		}
+	}
+	m, err := h.apiService.Company.ReviewMembership(c.UserContext(), membershipID, body.Status)
 	if err != nil {
 		log.Printf("ReviewMembership failed: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
+	h.recordAudit(c, AuditMembershipReview, "membership", membershipID, m.CompanyID, before, m)
 	return c.JSON(m)
 }
 
-// ModerateVacancy — owner approve/reject вакансии своего HR.
+// ModerateVacancy — owner approve/reject вакансии своего HR. Что вакансия
+// его компании, проверяет политика (vacancy.moderate).
 func (h *Handler) ModerateVacancy(c *fiber.Ctx) error {
 	ownerID := getUserIDFromContext(c)
 	id := c.Params("id")
 	if ownerID == "" || id == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request"})
 	}
-	// Проверим что вакансия в компании owner-а.
-	v, err := h.apiService.Vacancy.GetVacancy(c.Context(), id)
-	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "vacancy not found"})
-	}
-	if v.CompanyID != ownerID {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "not your company"})
-	}
 	var body struct {
 		Status  int32  `json:"status"` // 2=PUBLISHED, 3=REJECTED
 		Comment string `json:"comment,omitempty"`
@@ -117,10 +122,24 @@ func (h *Handler) ModerateVacancy(c *fiber.Ctx) error {
 	if err := c.BodyParser(&body); err != nil || (body.Status != 2 && body.Status != 3) {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "status must be 2 or 3"})
 	}
-	out, err := h.apiService.Vacancy.ModerateVacancy(c.Context(), id, body.Status, body.Comment)
+	// Для журнала аудита сравниваем только поля модерации: остальное не меняется,
+	// а ссылки на вложения подписываются заново при каждом чтении.
+	var before any
+	if v, gErr := h.apiService.Vacancy.GetVacancy(c.UserContext(), id); gErr == nil && v != nil {
+		before = vacancyModeration(v)
+	}
+	out, err := h.apiService.Vacancy.ModerateVacancy(c.UserContext(), id, body.Status, body.Comment)
 	if err != nil {
 		log.Printf("ModerateVacancy failed: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
+	h.recordAudit(c, AuditVacancyModerate, "vacancy", id, out.CompanyID, before, vacancyModeration(out))
 	return c.JSON(out)
 }
+
+func vacancyModeration(v *models.Vacancy) fiber.Map {
+	return fiber.Map{
+		"moderation_status":  v.ModerationStatus,
+		"moderation_comment": v.ModerationComment,
+	}
+}
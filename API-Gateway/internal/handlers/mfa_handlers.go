@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/models"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// VerifyMFA завершает вход вторым фактором
+// @Summary Второй шаг входа (TOTP)
+// @Description Обменивает mfa_token из ответа /auth/login и код из приложения (или код восстановления) на пару токенов.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body models.VerifyMFARequest true "mfa_token и код"
+// @Success 200 {object} models.AuthResponse "Успешная аутентификация"
+// @Failure 400 {object} models.ErrorResponse "Неверный запрос"
+// @Failure 401 {object} models.ErrorResponse "Неверный код (INVALID_MFA_CODE) или mfa_token истёк (INVALID_MFA_TOKEN)"
+// @Failure 423 {object} models.ErrorResponse "Аккаунт временно заблокирован после неудачных попыток (Retry-After)"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /auth/mfa/verify [post]
+func (h *Handler) VerifyMFA(c *fiber.Ctx) error {
+	var req models.VerifyMFARequest
+
+	if err := c.BodyParser(&req); err != nil {
+		log.Printf("VerifyMFA failed - body parse error: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(models.Error{
+			Code:    "INVALID_REQUEST",
+			Message: "Invalid request body",
+		})
+	}
+
+	if req.MFAToken == "" || req.Code == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(models.Error{
+			Code:    "MISSING_FIELDS",
+			Message: "mfa_token and code are required",
+		})
+	}
+
+	resp, err := h.apiService.Auth.VerifyMFA(clientContext(c), req.MFAToken, req.Code)
+	if err != nil {
+		log.Printf("API Gateway VerifyMFA failed: %v", err)
+		return h.handleMFAError(c, err)
+	}
+
+	log.Printf("VerifyMFA successful for user_uuid: %s", resp.UserUUID)
+	return c.JSON(resp)
+}
+
+// EnrollTOTP начинает подключение TOTP
+// @Summary Подключение TOTP
+// @Description Создаёт секрет и otpauth-ссылку для QR-кода. Авторизованный пользователь вызывает /auth/mfa/totp; при обязательном подключении во время входа — /auth/mfa/enroll с mfa_token.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.EnrollTOTPRequest false "mfa_token (только для /auth/mfa/enroll)"
+// @Success 200 {object} models.TOTPEnrollment "Секрет и ссылка для QR-кода"
+// @Failure 400 {object} models.ErrorResponse "Неверный запрос"
+// @Failure 401 {object} models.ErrorResponse "Не авторизован или mfa_token истёк"
+// @Failure 409 {object} models.ErrorResponse "TOTP уже подключён"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /auth/mfa/totp [post]
+// @Router /auth/mfa/enroll [post]
+func (h *Handler) EnrollTOTP(c *fiber.Ctx) error {
+	var req models.EnrollTOTPRequest
+
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			log.Printf("EnrollTOTP failed - body parse error: %v", err)
+			return c.Status(fiber.StatusBadRequest).JSON(models.Error{
+				Code:    "INVALID_REQUEST",
+				Message: "Invalid request body",
+			})
+		}
+	}
+
+	userID, ok := mfaSubject(c, req.MFAToken)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(models.Error{
+			Code:    "UNAUTHORIZED",
+			Message: "User not authenticated",
+		})
+	}
+
+	resp, err := h.apiService.Auth.EnrollTOTP(clientContext(c), userID, req.MFAToken)
+	if err != nil {
+		log.Printf("API Gateway EnrollTOTP failed for user %s: %v", userID, err)
+		return h.handleMFAError(c, err)
+	}
+
+	return c.JSON(resp)
+}
+
+// ConfirmTOTP включает TOTP
+// @Summary Подтверждение TOTP
+// @Description Включает TOTP по первому коду из приложения и возвращает коды восстановления (показываются один раз). При подключении во время входа в ответе есть auth с парой токенов.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.ConfirmTOTPRequest true "Код из приложения"
+// @Success 200 {object} models.TOTPConfirmation "TOTP включён"
+// @Failure 400 {object} models.ErrorResponse "Неверный запрос"
+// @Failure 401 {object} models.ErrorResponse "Неверный код или mfa_token истёк"
+// @Failure 409 {object} models.ErrorResponse "Подключение не начато или TOTP уже включён"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /auth/mfa/totp/confirm [post]
+// @Router /auth/mfa/enroll/confirm [post]
+func (h *Handler) ConfirmTOTP(c *fiber.Ctx) error {
+	var req models.ConfirmTOTPRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		log.Printf("ConfirmTOTP failed - body parse error: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(models.Error{
+			Code:    "INVALID_REQUEST",
+			Message: "Invalid request body",
+		})
+	}
+
+	if req.Code == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(models.Error{
+			Code:    "MISSING_FIELDS",
+			Message: "code is required",
+		})
+	}
+
+	userID, ok := mfaSubject(c, req.MFAToken)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(models.Error{
+			Code:    "UNAUTHORIZED",
+			Message: "User not authenticated",
+		})
+	}
+
+	resp, err := h.apiService.Auth.ConfirmTOTP(clientContext(c), userID, req.MFAToken, req.Code)
+	if err != nil {
+		log.Printf("API Gateway ConfirmTOTP failed for user %s: %v", userID, err)
+		return h.handleMFAError(c, err)
+	}
+
+	event := models.AuditEvent{
+		ActorUUID:    userID,
+		ActorRole:    string(getRoleFromContext(c)),
+		Action:       AuditMFAEnable,
+		ResourceType: "account",
+		ResourceID:   userID,
+	}
+	if userID == "" && resp.Auth != nil {
+		event.ActorUUID, event.ActorRole, event.ResourceID = resp.Auth.UserUUID, resp.Auth.Role, resp.Auth.UserUUID
+	}
+	h.recordAuditEvent(c, event, fiber.Map{"totp": false}, fiber.Map{"totp": true})
+
+	return c.JSON(resp)
+}
+
+// DisableTOTP отключает TOTP
+// @Summary Отключение TOTP
+// @Description Отключает TOTP по действующему коду. Недоступно, если второй фактор обязателен для одной из ролей аккаунта.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.MFACodeRequest true "Код из приложения или код восстановления"
+// @Success 200 {object} models.SuccessResponse "TOTP отключён"
+// @Failure 400 {object} models.ErrorResponse "Неверный запрос"
+// @Failure 401 {object} models.ErrorResponse "Неверный код"
+// @Failure 409 {object} models.ErrorResponse "TOTP не подключён или обязателен"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /auth/mfa/totp/disable [post]
+func (h *Handler) DisableTOTP(c *fiber.Ctx) error {
+	var req models.MFACodeRequest
+
+	if err := c.BodyParser(&req); err != nil || req.Code == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(models.Error{
+			Code:    "MISSING_FIELDS",
+			Message: "code is required",
+		})
+	}
+
+	userID := getUserIDFromContext(c)
+	if err := h.apiService.Auth.DisableTOTP(clientContext(c), userID, req.Code); err != nil {
+		log.Printf("API Gateway DisableTOTP failed for user %s: %v", userID, err)
+		return h.handleMFAError(c, err)
+	}
+	h.recordAudit(c, AuditMFADisable, "account", userID, "", fiber.Map{"totp": true}, fiber.Map{"totp": false})
+
+	return c.JSON(models.SuccessResponse{Message: "Two-factor authentication disabled"})
+}
+
+// RegenerateRecoveryCodes выпускает новые коды восстановления
+// @Summary Новые коды восстановления
+// @Description Выпускает новый набор кодов восстановления; старые перестают действовать.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.MFACodeRequest true "Код из приложения или код восстановления"
+// @Success 200 {object} models.RecoveryCodesResponse "Новые коды"
+// @Failure 400 {object} models.ErrorResponse "Неверный запрос"
+// @Failure 401 {object} models.ErrorResponse "Неверный код"
+// @Failure 409 {object} models.ErrorResponse "TOTP не подключён"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /auth/mfa/recovery-codes [post]
+func (h *Handler) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	var req models.MFACodeRequest
+
+	if err := c.BodyParser(&req); err != nil || req.Code == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(models.Error{
+			Code:    "MISSING_FIELDS",
+			Message: "code is required",
+		})
+	}
+
+	userID := getUserIDFromContext(c)
+	recoveryCodes, err := h.apiService.Auth.RegenerateRecoveryCodes(clientContext(c), userID, req.Code)
+	if err != nil {
+		log.Printf("API Gateway RegenerateRecoveryCodes failed for user %s: %v", userID, err)
+		return h.handleMFAError(c, err)
+	}
+	h.recordAudit(c, AuditRecoveryRegenerate, "account", userID, "", nil, fiber.Map{"recovery_codes": len(recoveryCodes)})
+
+	return c.JSON(models.RecoveryCodesResponse{RecoveryCodes: recoveryCodes})
+}
+
+// mfaSubject — для кого подключается TOTP: по mfa_token (вход с обязательным
+// 2FA, маршрут без AuthMiddleware) или по текущей сессии.
+func mfaSubject(c *fiber.Ctx, mfaToken string) (string, bool) {
+	if mfaToken != "" {
+		return "", true
+	}
+	userID := getUserIDFromContext(c)
+	return userID, userID != ""
+}
+
+// handleMFAError — ошибки второго фактора. Причину Auth кладёт в ErrorInfo:
+// по ней клиент понимает, вводить код ещё раз или начинать вход заново.
+func (h *Handler) handleMFAError(c *fiber.Ctx, err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return h.handleAuthError(c, err)
+	}
+
+	switch st.Code() {
+	case codes.Unauthenticated:
+		reason := "INVALID_MFA_CODE"
+		for _, d := range st.Details() {
+			if info, ok := d.(*errdetails.ErrorInfo); ok && info.Reason != "" {
+				reason = info.Reason
+			}
+		}
+		message := "Invalid verification code"
+		if reason == "INVALID_MFA_TOKEN" {
+			message = "MFA session expired, please log in again"
+		}
+		return c.Status(fiber.StatusUnauthorized).JSON(models.Error{
+			Code:    reason,
+			Message: message,
+		})
+	case codes.FailedPrecondition:
+		return c.Status(fiber.StatusConflict).JSON(models.Error{
+			Code:    "MFA_STATE_CONFLICT",
+			Message: st.Message(),
+		})
+	default:
+		return h.handleAuthError(c, err)
+	}
+}
@@ -43,9 +43,9 @@ func (h *Handler) GetTasks(c *fiber.Ctx) error {
 
 	if h.apiService.Search.Available() && (len(skillSlugs) > 0 || query != "" || rewardMin > 0) {
 		log.Printf("GetTasks: routing through Search (skill_slugs=%v q=%q reward_min=%d)", skillSlugs, query, rewardMin)
-		list, err = h.apiService.Search.SearchMicroTasksAsModel(c.Context(), query, skillSlugs, clampInt32(rewardMin), clampInt32(statusInt), "", int32(page), int32(limit))
+		list, err = h.apiService.Search.SearchMicroTasksAsModel(c.UserContext(), query, skillSlugs, clampInt32(rewardMin), clampInt32(statusInt), "", int32(page), int32(limit))
 	} else {
-		list, err = h.apiService.MicroTasks.List(c.Context(), clampInt32(statusInt), skillSlugs, int32(page), int32(limit))
+		list, err = h.apiService.MicroTasks.List(c.UserContext(), clampInt32(statusInt), skillSlugs, int32(page), int32(limit))
 	}
 	if err != nil {
 		log.Printf("GetTasks: failed: %v", err)
@@ -60,7 +60,7 @@ func (h *Handler) GetTask(c *fiber.Ctx) error {
 	if id == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task id"})
 	}
-	t, err := h.apiService.MicroTasks.Get(c.Context(), id)
+	t, err := h.apiService.MicroTasks.Get(c.UserContext(), id)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Task not found"})
 	}
@@ -74,7 +74,7 @@ func (h *Handler) ApplyToTask(c *fiber.Ctx) error {
 	if id == "" || studentID == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
 	}
-	t, err := h.apiService.MicroTasks.Apply(c.Context(), id, studentID)
+	t, err := h.apiService.MicroTasks.Apply(c.UserContext(), id, studentID)
 	if err != nil {
 		log.Printf("ApplyToTask: failed task=%s student=%s: %v", id, studentID, err)
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
@@ -96,7 +96,7 @@ func (h *Handler) SubmitTask(c *fiber.Ctx) error {
 	if req.SolutionURL == "" && req.SolutionFileName == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "solution_url or solution_file_name is required"})
 	}
-	s, err := h.apiService.MicroTasks.Submit(c.Context(), id, studentID, req.SolutionURL, req.Comment, req.SolutionFileName)
+	s, err := h.apiService.MicroTasks.Submit(c.UserContext(), id, studentID, req.SolutionURL, req.Comment, req.SolutionFileName)
 	if err != nil {
 		log.Printf("SubmitTask: failed task=%s student=%s: %v", id, studentID, err)
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
@@ -115,7 +115,7 @@ func (h *Handler) SolutionUploadInit(c *fiber.Ctx) error {
 	if err := c.BodyParser(&req); err != nil || req.FileName == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "file_name is required"})
 	}
-	fileID, uploadURL, err := h.apiService.MicroTasks.SolutionUploadInit(c.Context(), id, studentID, req.FileName)
+	fileID, uploadURL, err := h.apiService.MicroTasks.SolutionUploadInit(c.UserContext(), id, studentID, req.FileName)
 	if err != nil {
 		log.Printf("SolutionUploadInit: task=%s student=%s failed: %v", id, studentID, err)
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
@@ -137,7 +137,7 @@ func (h *Handler) SolutionUploadConfirm(c *fiber.Ctx) error {
 	if err := c.BodyParser(&req); err != nil || req.FileID == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "file_id is required"})
 	}
-	if err := h.apiService.MicroTasks.SolutionUploadConfirm(c.Context(), id, studentID, req.FileID); err != nil {
+	if err := h.apiService.MicroTasks.SolutionUploadConfirm(c.UserContext(), id, studentID, req.FileID); err != nil {
 		log.Printf("SolutionUploadConfirm: task=%s student=%s file=%s failed: %v", id, studentID, req.FileID, err)
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -157,7 +157,7 @@ func (h *Handler) CreateSkillQuest(c *fiber.Ctx) error {
 	if req.TargetStudentID == "" || req.TargetSkillSlug == "" || req.Title == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "target_student_id, target_skill_slug, title are required"})
 	}
-	t, err := h.apiService.MicroTasks.CreateSkillQuest(c.Context(), expertID, req.TargetStudentID, req.TargetSkillSlug, req.Title, req.Description, req.Deadline)
+	t, err := h.apiService.MicroTasks.CreateSkillQuest(c.UserContext(), expertID, req.TargetStudentID, req.TargetSkillSlug, req.Title, req.Description, req.Deadline)
 	if err != nil {
 		log.Printf("CreateSkillQuest: expert=%s student=%s failed: %v", expertID, req.TargetStudentID, err)
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
@@ -176,7 +176,7 @@ func (h *Handler) GetMyTasks(c *fiber.Ctx) error {
 	limit, _ := strconv.Atoi(c.Query("limit", "50"))
 	page, limit = normalizePagination(page, limit)
 	statusInt, _ := strconv.Atoi(c.Query("status", "0"))
-	list, err := h.apiService.MicroTasks.ListByStudent(c.Context(), studentID, clampInt32(statusInt), int32(page), int32(limit))
+	list, err := h.apiService.MicroTasks.ListByStudent(c.UserContext(), studentID, clampInt32(statusInt), int32(page), int32(limit))
 	if err != nil {
 		log.Printf("GetMyTasks: failed student=%s: %v", studentID, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to load my tasks"})
@@ -190,7 +190,7 @@ func (h *Handler) ListMySubmissions(c *fiber.Ctx) error {
 	page, _ := strconv.Atoi(c.Query("page", "1"))
 	limit, _ := strconv.Atoi(c.Query("limit", "10"))
 	page, limit = normalizePagination(page, limit)
-	list, err := h.apiService.MicroTasks.ListSubmissions(c.Context(), "", studentID, int32(page), int32(limit))
+	list, err := h.apiService.MicroTasks.ListSubmissions(c.UserContext(), "", studentID, int32(page), int32(limit))
 	if err != nil {
 		log.Printf("ListMySubmissions: failed: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to load submissions"})
@@ -206,7 +206,7 @@ func (h *Handler) GetHRTasks(c *fiber.Ctx) error {
 	page, _ := strconv.Atoi(c.Query("page", "1"))
 	limit, _ := strconv.Atoi(c.Query("limit", "10"))
 	page, limit = normalizePagination(page, limit)
-	list, err := h.apiService.MicroTasks.ListByCompany(c.Context(), companyID, int32(page), int32(limit))
+	list, err := h.apiService.MicroTasks.ListByCompany(c.UserContext(), companyID, int32(page), int32(limit))
 	if err != nil {
 		log.Printf("GetHRTasks: failed company=%s: %v", companyID, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to load tasks"})
@@ -232,7 +232,7 @@ func (h *Handler) CreateHRTask(c *fiber.Ctx) error {
 		Deadline:    req.Deadline,
 		SkillSlugs:  req.SkillSlugs,
 	}
-	created, err := h.apiService.MicroTasks.Create(c.Context(), t)
+	created, err := h.apiService.MicroTasks.Create(c.UserContext(), t)
 	if err != nil {
 		log.Printf("CreateHRTask: failed: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to create task"})
@@ -266,7 +266,7 @@ func (h *Handler) UpdateHRTask(c *fiber.Ctx) error {
 	if req.SkillSlugs != nil {
 		t.SkillSlugs = req.SkillSlugs
 	}
-	updated, err := h.apiService.MicroTasks.Update(c.Context(), id, t)
+	updated, err := h.apiService.MicroTasks.Update(c.UserContext(), id, t)
 	if err != nil {
 		log.Printf("UpdateHRTask: failed: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to update task"})
@@ -280,7 +280,7 @@ func (h *Handler) DeleteHRTask(c *fiber.Ctx) error {
 	if id == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task id"})
 	}
-	if err := h.apiService.MicroTasks.Delete(c.Context(), id); err != nil {
+	if err := h.apiService.MicroTasks.Delete(c.UserContext(), id); err != nil {
 		log.Printf("DeleteHRTask: failed: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to delete task"})
 	}
@@ -293,7 +293,7 @@ func (h *Handler) ListTaskSubmissions(c *fiber.Ctx) error {
 	page, _ := strconv.Atoi(c.Query("page", "1"))
 	limit, _ := strconv.Atoi(c.Query("limit", "10"))
 	page, limit = normalizePagination(page, limit)
-	list, err := h.apiService.MicroTasks.ListSubmissions(c.Context(), id, "", int32(page), int32(limit))
+	list, err := h.apiService.MicroTasks.ListSubmissions(c.UserContext(), id, "", int32(page), int32(limit))
 	if err != nil {
 		log.Printf("ListTaskSubmissions: failed task=%s: %v", id, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to load submissions"})
@@ -314,10 +314,20 @@ func (h *Handler) ReviewSubmission(c *fiber.Ctx) error {
 	if req.Status != 2 && req.Status != 3 {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "status must be 2 (APPROVED) or 3 (REJECTED)"})
 	}
-	s, err := h.apiService.MicroTasks.Review(c.Context(), subID, req.Status, req.ReviewComment)
+	s, err := h.apiService.MicroTasks.Review(c.UserContext(), subID, req.Status, req.ReviewComment)
 	if err != nil {
 		log.Printf("ReviewSubmission: failed: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to review submission"})
 	}
+
+	// Submission по id не читается, поэтому в журнале только итог ревью.
+	var companyID string
+	if task, tErr := h.apiService.MicroTasks.Get(c.UserContext(), s.MicrotaskID); tErr == nil && task != nil {
+		companyID = task.CompanyID
+	}
+	h.recordAudit(c, AuditSubmissionReview, "submission", subID, companyID, nil, fiber.Map{
+		"status":         s.Status,
+		"review_comment": s.ReviewComment,
+	})
 	return c.JSON(s)
 }
@@ -3,9 +3,10 @@ package handlers
 import (
 	"context"
 	"github.com/gofiber/fiber/v2"
-	"github.com/studjobs/hh_for_students/api-gateway/internal/services"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/models"
 	"log"
 	"strings"
+	"time"
 )
 
 // Константы для ключей контекста
@@ -17,28 +18,80 @@ const (
 	RoleKey   contextKey = "role"
 	TokenKey  contextKey = "token"
 
+	EmailVerifiedKey contextKey = "email_verified"
+
+	// Запрос по API-ключу интеграции (см. apikey_middleware.go)
+	APIKeyIDKey     contextKey = "api_key_id"
+	APIKeyScopesKey contextKey = "api_key_scopes"
+
 	// Roles
 	ROLE_DEVELOPER Role = "ROLE_DEVELOPER"
 	ROLE_STUDENT   Role = "ROLE_STUDENT"
 	ROLE_HR        Role = "ROLE_EMPLOYER"
 	ROLE_COMPANY   Role = "ROLE_COMPANY_OWNER"
 	ROLE_EXPERT    Role = "ROLE_EXPERT"
+	ROLE_ADMIN     Role = "ROLE_ADMIN" // администратор платформы: только через ADMIN_EMAILS в Auth
+
+	RolesKey contextKey = "roles"
 
 	ID string = "id"
+
+	// authValidateTimeout — верхняя граница на проверку токена (JWKS/Auth).
+	authValidateTimeout = 3 * time.Second
 )
 
-// AuthMiddleware проверяет JWT токен через APIGatewayService
-func AuthMiddleware(apiService *services.ApiGateway) fiber.Handler {
+// knownRoles — роли, которые понимает Gateway; значения совпадают с именами
+// ролей в Auth (authv1.Role).
+var knownRoles = map[string]Role{
+	string(ROLE_DEVELOPER): ROLE_DEVELOPER,
+	string(ROLE_STUDENT):   ROLE_STUDENT,
+	string(ROLE_HR):        ROLE_HR,
+	string(ROLE_COMPANY):   ROLE_COMPANY,
+	string(ROLE_EXPERT):    ROLE_EXPERT,
+	string(ROLE_ADMIN):     ROLE_ADMIN,
+}
+
+// parseRole конвертирует строку роли от Auth или клиента в тип Role
+func parseRole(role string) (Role, bool) {
+	r, ok := knownRoles[role]
+	return r, ok
+}
+
+// TokenValidator — то, чем AuthMiddleware проверяет токен или API-ключ:
+// authn.Verifier (локальная проверка подписи + кэш статуса) или напрямую
+// services.AuthService.
+type TokenValidator interface {
+	ValidateToken(ctx context.Context, token string) (*models.TokenInfo, error)
+	ValidateAPIKey(ctx context.Context, key string) (*models.APIKeyInfo, error)
+}
+
+// AuthMiddleware проверяет JWT токен (или API-ключ интеграции) через validator
+func AuthMiddleware(validator TokenValidator) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		// Пропускаем auth endpoints и health check
 		if c.Path() == "/api/v1/auth/login" ||
 			c.Path() == "/api/v1/auth/register" ||
+			c.Path() == "/api/v1/auth/refresh" ||
+			c.Path() == "/api/v1/auth/password/reset" ||
+			c.Path() == "/api/v1/auth/password/reset/confirm" ||
+			c.Path() == "/api/v1/auth/email/verify" ||
+			c.Path() == "/api/v1/auth/mfa/verify" ||
+			c.Path() == "/api/v1/auth/mfa/enroll" ||
+			c.Path() == "/api/v1/auth/mfa/enroll/confirm" ||
+			c.Path() == "/api/v1/auth/oidc/providers" ||
+			isOIDCBrowserPath(c.Path()) ||
+			isDeletionStatusPath(c.Method(), c.Path()) ||
 			c.Path() == "/health" ||
 			strings.HasPrefix(c.Path(), "/swagger/") ||
 			strings.HasPrefix(c.Path(), "/docs/") {
 			return c.Next()
 		}
 
+		// Интеграции компаний приходят с API-ключом вместо JWT
+		if key, ok := apiKeyFromRequest(c); ok {
+			return authenticateAPIKey(c, validator, key)
+		}
+
 		authHeader := c.Get("Authorization")
 		if authHeader == "" {
 			log.Printf("AuthMiddleware: Missing Authorization header")
@@ -57,8 +110,10 @@ func AuthMiddleware(apiService *services.ApiGateway) fiber.Handler {
 
 		log.Printf("AuthMiddleware: Validating token: %s...", token[:min(10, len(token))])
 
-		// Вызываем сервис для проверки токена
-		valid, userUUID, role, err := apiService.Auth.ValidateToken(context.Background(), token)
+		// Контекст запроса, а не Background: дедлайн и отмена доходят до Auth.
+		ctx, cancel := context.WithTimeout(c.UserContext(), authValidateTimeout)
+		defer cancel()
+		info, err := validator.ValidateToken(ctx, token)
 		if err != nil {
 			log.Printf("AuthMiddleware: Token validation error: %v", err)
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -66,119 +121,60 @@ func AuthMiddleware(apiService *services.ApiGateway) fiber.Handler {
 			})
 		}
 
-		if !valid {
+		if !info.Valid {
 			log.Printf("AuthMiddleware: Invalid token")
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 				"error": "Invalid token",
 			})
 		}
 
+		userUUID, role := info.UserUUID, info.Role
 		log.Printf("AuthMiddleware: Token validated - user_uuid: %s, role: %s", userUUID, role)
 
-		// Конвертируем строку роли в тип Role
-		var userRole Role
-		switch role {
-		case "ROLE_COMPANY_OWNER":
-			userRole = ROLE_COMPANY
-		case "ROLE_DEVELOPER":
-			userRole = ROLE_DEVELOPER
-		case "ROLE_STUDENT":
-			userRole = ROLE_STUDENT
-		case "ROLE_EMPLOYER":
-			userRole = ROLE_HR
-		case "ROLE_EXPERT":
-			userRole = ROLE_EXPERT
-		default:
+		// Активная роль — та, под которой выпущен токен; права определяет только она
+		userRole, ok := parseRole(role)
+		if !ok {
 			log.Printf("AuthMiddleware: Unknown role: %s", role)
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 				"error": "Invalid user role",
 			})
 		}
 
+		// Остальные роли аккаунта — для переключения без повторного входа
+		userRoles := make([]Role, 0, len(info.Roles))
+		for _, r := range info.Roles {
+			if parsed, ok := parseRole(r); ok {
+				userRoles = append(userRoles, parsed)
+			}
+		}
+
 		// Сохраняем данные в контекст
 		c.Locals(string(UserIDKey), userUUID)
 		c.Locals(string(RoleKey), userRole)
+		c.Locals(string(RolesKey), userRoles)
 		c.Locals(string(TokenKey), token)
+		c.Locals(string(EmailVerifiedKey), info.EmailVerified)
 
 		return c.Next()
 	}
 }
 
-// RoleMiddleware проверяет роль пользователя
-func RoleMiddleware(allowedRoles ...Role) fiber.Handler {
-	return func(c *fiber.Ctx) error {
-		userRole := getRoleFromContext(c)
-		if userRole == "" {
-			log.Printf("RoleMiddleware: User not authenticated")
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "User not authenticated",
-			})
-		}
-
-		log.Printf("RoleMiddleware: Checking role %s against allowed roles: %v", userRole, allowedRoles)
-
-		// ROLE_DEVELOPER имеет доступ ко всему
-		if userRole == ROLE_DEVELOPER {
-			log.Printf("RoleMiddleware: Developer role - access granted")
-			return c.Next()
-		}
-
-		// Проверяем разрешенные роли
-		for _, role := range allowedRoles {
-			if userRole == role {
-				log.Printf("RoleMiddleware: Role %s allowed - access granted", userRole)
-				return c.Next()
-			}
-		}
-
-		log.Printf("RoleMiddleware: Role %s not in allowed roles - access denied", userRole)
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Insufficient permissions",
-		})
+// isOIDCBrowserPath — /auth/oidc/<provider>/start и /callback: их открывает
+// браузер, access-токена у него ещё нет.
+func isOIDCBrowserPath(path string) bool {
+	rest, ok := strings.CutPrefix(path, "/api/v1/auth/oidc/")
+	if !ok {
+		return false
 	}
+	provider, action, ok := strings.Cut(rest, "/")
+	return ok && provider != "" && (action == "start" || action == "callback")
 }
 
-// OwnerOrRoleMiddleware проверяет владельца или роль
-func OwnerOrRoleMiddleware(paramName string, allowedRoles ...Role) fiber.Handler {
-	return func(c *fiber.Ctx) error {
-		userID := getUserIDFromContext(c)
-		if userID == "" {
-			log.Printf("OwnerOrRoleMiddleware: User not authenticated")
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "User not authenticated",
-			})
-		}
-
-		targetID := c.Params(paramName)
-		userRole := getRoleFromContext(c)
-
-		log.Printf("OwnerOrRoleMiddleware: User %s (role: %s) accessing resource %s", userID, userRole, targetID)
-
-		// ROLE_DEVELOPER имеет доступ ко всему
-		if userRole == ROLE_DEVELOPER {
-			log.Printf("OwnerOrRoleMiddleware: Developer role - access granted")
-			return c.Next()
-		}
-
-		// Владелец имеет доступ к своим данным
-		if userID == targetID {
-			log.Printf("OwnerOrRoleMiddleware: Owner access - access granted")
-			return c.Next()
-		}
-
-		// Проверяем разрешенные роли
-		for _, role := range allowedRoles {
-			if userRole == role {
-				log.Printf("OwnerOrRoleMiddleware: Role %s allowed - access granted", userRole)
-				return c.Next()
-			}
-		}
-
-		log.Printf("OwnerOrRoleMiddleware: Access denied for user %s to resource %s", userID, targetID)
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Insufficient permissions",
-		})
-	}
+// isDeletionStatusPath — GET /api/v1/account/deletion/:id: статус удаления
+// опрашивают уже без токена — токены аккаунта отозваны первым шагом удаления.
+func isDeletionStatusPath(method, path string) bool {
+	id, ok := strings.CutPrefix(path, "/api/v1/account/deletion/")
+	return ok && method == fiber.MethodGet && id != "" && !strings.Contains(id, "/")
 }
 
 // getUserIDFromContext возвращает user_id из контекста
@@ -197,6 +193,14 @@ func getRoleFromContext(c *fiber.Ctx) Role {
 	return ""
 }
 
+// getRolesFromContext возвращает все роли аккаунта из контекста
+func getRolesFromContext(c *fiber.Ctx) []Role {
+	if roles, ok := c.Locals(string(RolesKey)).([]Role); ok {
+		return roles
+	}
+	return nil
+}
+
 // getTokenFromContext возвращает токен из контекста
 func getTokenFromContext(c *fiber.Ctx) string {
 	if token, ok := c.Locals(string(TokenKey)).(string); ok {
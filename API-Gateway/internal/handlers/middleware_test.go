@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/models"
+)
+
+// rejectingValidator — любой токен недействителен: пропуск без токена
+// проверяется тем, что до validator дело не доходит.
+type rejectingValidator struct{}
+
+func (rejectingValidator) ValidateToken(context.Context, string) (*models.TokenInfo, error) {
+	return &models.TokenInfo{Valid: false}, nil
+}
+
+func (rejectingValidator) ValidateAPIKey(context.Context, string) (*models.APIKeyInfo, error) {
+	return nil, errors.New("unexpected api key check")
+}
+
+func TestAuthMiddlewareDeletionStatusWithoutToken(t *testing.T) {
+	app := fiber.New()
+	app.Use(AuthMiddleware(rejectingValidator{}))
+	ok := func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) }
+	app.Get("/api/v1/account/deletion/:id", ok)
+	app.Post("/api/v1/account/deletion/:id", ok)
+	app.Get("/api/v1/account/export/:id", ok)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		want   int
+	}{
+		{"deletion status", fiber.MethodGet, "/api/v1/account/deletion/7f7c2f0e-2b1a-4d8e-9a57-0d3c1b1f4e11", fiber.StatusOK},
+		{"deletion status write", fiber.MethodPost, "/api/v1/account/deletion/7f7c2f0e-2b1a-4d8e-9a57-0d3c1b1f4e11", fiber.StatusUnauthorized},
+		{"deletion without id", fiber.MethodGet, "/api/v1/account/deletion/", fiber.StatusUnauthorized},
+		{"export status", fiber.MethodGet, "/api/v1/account/export/42", fiber.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(tt.method, tt.path, nil))
+			if err != nil {
+				t.Fatalf("request: %v", err)
+			}
+			if resp.StatusCode != tt.want {
+				t.Fatalf("%s %s: status %d, want %d", tt.method, tt.path, resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"log"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/models"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// oidcStateCookie привязывает callback к браузеру, который начал вход:
+	// без неё ссылку с чужим state можно подсунуть жертве (login CSRF).
+	oidcStateCookie = "oidc_state"
+	oidcCookiePath  = "/api/v1/auth/oidc"
+	oidcCookieTTL   = 10 * time.Minute
+)
+
+// OIDCProviders возвращает настроенных провайдеров входа
+// @Summary Провайдеры входа
+// @Description Имена провайдеров, через которые можно войти (/auth/oidc/{provider}/start).
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} models.OIDCProvidersResponse "Провайдеры"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /auth/oidc/providers [get]
+func (h *Handler) OIDCProviders(c *fiber.Ctx) error {
+	providers, err := h.apiService.Auth.ListOIDCProviders(c.UserContext())
+	if err != nil {
+		log.Printf("API Gateway ListOIDCProviders failed: %v", err)
+		return h.handleAuthError(c, err)
+	}
+	if providers == nil {
+		providers = []string{}
+	}
+	return c.JSON(models.OIDCProvidersResponse{Providers: providers})
+}
+
+// StartOIDC начинает вход через провайдера
+// @Summary Вход через провайдера
+// @Description Перенаправляет на страницу входа провайдера. После входа провайдер вернёт браузер на /auth/oidc/{provider}/callback. Новый аккаунт создаётся с ролью role (по умолчанию ROLE_STUDENT); существующий входит под role или под основной ролью.
+// @Tags Auth
+// @Param provider path string true "Провайдер"
+// @Param role query string false "Роль"
+// @Success 302 "Перенаправление к провайдеру"
+// @Failure 400 {object} models.ErrorResponse "Неверная роль"
+// @Failure 404 {object} models.ErrorResponse "Провайдер не настроен"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /auth/oidc/{provider}/start [get]
+func (h *Handler) StartOIDC(c *fiber.Ctx) error {
+	provider := c.Params("provider")
+
+	authURL, state, err := h.apiService.Auth.StartOIDC(c.UserContext(), provider, c.Query("role"), "")
+	if err != nil {
+		log.Printf("API Gateway StartOIDC failed for provider %s: %v", provider, err)
+		return h.handleOIDCError(c, err)
+	}
+
+	setOIDCStateCookie(c, state)
+	return c.Redirect(authURL, fiber.StatusFound)
+}
+
+// LinkOIDC привязывает провайдера к текущему аккаунту
+// @Summary Привязка провайдера
+// @Description Возвращает ссылку на страницу провайдера; после входа у провайдера его учётная запись привязывается к текущему аккаунту. Запрос нужно делать с credentials: state кладётся в cookie.
+// @Tags Auth
+// @Produce json
+// @Security BearerAuth
+// @Param provider path string true "Провайдер"
+// @Success 200 {object} models.OIDCAuthorization "Ссылка на страницу провайдера"
+// @Failure 401 {object} models.ErrorResponse "Не авторизован"
+// @Failure 404 {object} models.ErrorResponse "Провайдер не настроен"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /auth/oidc/{provider}/link [post]
+func (h *Handler) LinkOIDC(c *fiber.Ctx) error {
+	provider := c.Params("provider")
+	userID := getUserIDFromContext(c)
+
+	authURL, state, err := h.apiService.Auth.StartOIDC(c.UserContext(), provider, string(getRoleFromContext(c)), userID)
+	if err != nil {
+		log.Printf("API Gateway LinkOIDC failed for provider %s, user %s: %v", provider, userID, err)
+		return h.handleAuthError(c, err)
+	}
+
+	setOIDCStateCookie(c, state)
+	return c.JSON(models.OIDCAuthorization{AuthorizationURL: authURL})
+}
+
+// OIDCCallback завершает вход через провайдера
+// @Summary Callback провайдера
+// @Description Принимает code и state от провайдера. Если настроен OIDC_FRONTEND_URL, перенаправляет на фронтенд с результатом во фрагменте (#token=…&refresh_token=… или #mfa_token=… или #error=…), иначе возвращает JSON.
+// @Tags Auth
+// @Produce json
+// @Param provider path string true "Провайдер"
+// @Param code query string true "Authorization code"
+// @Param state query string true "State"
+// @Success 200 {object} models.AuthResponse "Успешная аутентификация"
+// @Success 302 "Перенаправление на фронтенд"
+// @Failure 400 {object} models.ErrorResponse "Вход отменён или state не совпадает"
+// @Failure 401 {object} models.ErrorResponse "Провайдер не подтвердил вход"
+// @Failure 403 {object} models.ErrorResponse "Email не подтверждён провайдером или роль не назначена"
+// @Failure 409 {object} models.ErrorResponse "Учётная запись провайдера привязана к другому аккаунту"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /auth/oidc/{provider}/callback [get]
+func (h *Handler) OIDCCallback(c *fiber.Ctx) error {
+	provider := c.Params("provider")
+	state, code := c.Query("state"), c.Query("code")
+	cookieState := c.Cookies(oidcStateCookie)
+	clearOIDCStateCookie(c)
+
+	if errCode := c.Query("error"); errCode != "" {
+		log.Printf("OIDC callback: provider %s returned error %s", provider, errCode)
+		return h.oidcFailure(c, fiber.StatusBadRequest, models.Error{
+			Code:    "OIDC_CANCELLED",
+			Message: "Sign-in with provider was cancelled",
+		})
+	}
+
+	if state == "" || code == "" || subtle.ConstantTimeCompare([]byte(state), []byte(cookieState)) != 1 {
+		log.Printf("OIDC callback: state mismatch for provider %s", provider)
+		return h.oidcFailure(c, fiber.StatusBadRequest, models.Error{
+			Code:    "OIDC_STATE_MISMATCH",
+			Message: "Sign-in session expired, please try again",
+		})
+	}
+
+	resp, email, err := h.apiService.Auth.CompleteOIDC(clientContext(c), provider, state, code)
+	if err != nil {
+		log.Printf("API Gateway CompleteOIDC failed for provider %s: %v", provider, err)
+		return h.handleOIDCError(c, err)
+	}
+
+	if resp.NewAccount {
+		if err := h.registration.Provision(c.UserContext(), resp, email); err != nil {
+			return h.handleOIDCError(c, err)
+		}
+	}
+
+	log.Printf("OIDC login successful via %s for user_uuid: %s", provider, resp.UserUUID)
+	if h.oidcFrontendURL == "" {
+		return c.JSON(resp)
+	}
+
+	fragment := url.Values{}
+	fragment.Set("user_uuid", resp.UserUUID)
+	fragment.Set("role", resp.Role)
+	if resp.MFARequired {
+		fragment.Set("mfa_token", resp.MFAToken)
+		fragment.Set("mfa_enrollment_required", strconv.FormatBool(resp.MFAEnrollmentRequired))
+	} else {
+		fragment.Set("token", resp.Token)
+		fragment.Set("refresh_token", resp.RefreshToken)
+	}
+	if resp.NewAccount {
+		fragment.Set("new_account", "true")
+	}
+	return c.Redirect(h.oidcFrontendURL+"#"+fragment.Encode(), fiber.StatusFound)
+}
+
+// handleOIDCError — ошибки входа через провайдера.
+func (h *Handler) handleOIDCError(c *fiber.Ctx, err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return h.oidcFailure(c, fiber.StatusInternalServerError, models.Error{
+			Code:    "INTERNAL_ERROR",
+			Message: "Internal server error",
+		})
+	}
+
+	switch st.Code() {
+	case codes.Unauthenticated:
+		return h.oidcFailure(c, fiber.StatusUnauthorized, models.Error{
+			Code:    "OIDC_LOGIN_FAILED",
+			Message: "Sign-in with provider failed",
+		})
+	case codes.FailedPrecondition:
+		return h.oidcFailure(c, fiber.StatusForbidden, models.Error{
+			Code:    "OIDC_EMAIL_NOT_VERIFIED",
+			Message: "Provider did not confirm the email address",
+		})
+	case codes.AlreadyExists:
+		return h.oidcFailure(c, fiber.StatusConflict, models.Error{
+			Code:    "OIDC_IDENTITY_LINKED",
+			Message: "This provider account is linked to another user",
+		})
+	case codes.PermissionDenied:
+		return h.oidcFailure(c, fiber.StatusForbidden, models.Error{
+			Code:    "ROLE_NOT_ASSIGNED",
+			Message: "Role is not assigned to this account",
+		})
+	case codes.NotFound:
+		return h.oidcFailure(c, fiber.StatusNotFound, models.Error{
+			Code:    "UNKNOWN_PROVIDER",
+			Message: "Provider is not configured",
+		})
+	}
+	if h.oidcFrontendURL == "" {
+		return h.handleAuthError(c, err)
+	}
+	return h.oidcFailure(c, fiber.StatusInternalServerError, models.Error{
+		Code:    "INTERNAL_ERROR",
+		Message: "Internal server error",
+	})
+}
+
+// oidcFailure отвечает JSON-ошибкой или, если настроен фронтенд, возвращает
+// браузер туда с кодом ошибки: пользователь пришёл от провайдера, а не из SPA.
+func (h *Handler) oidcFailure(c *fiber.Ctx, httpStatus int, e models.Error) error {
+	if h.oidcFrontendURL == "" {
+		return c.Status(httpStatus).JSON(e)
+	}
+	return c.Redirect(h.oidcFrontendURL+"#"+url.Values{"error": {e.Code}}.Encode(), fiber.StatusFound)
+}
+
+func setOIDCStateCookie(c *fiber.Ctx, state string) {
+	c.Cookie(&fiber.Cookie{
+		Name:     oidcStateCookie,
+		Value:    state,
+		Path:     oidcCookiePath,
+		Expires:  time.Now().Add(oidcCookieTTL),
+		HTTPOnly: true,
+		Secure:   c.Protocol() == "https",
+		// Lax: cookie уходит при возврате браузера от провайдера (top-level GET).
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+}
+
+func clearOIDCStateCookie(c *fiber.Ctx) {
+	c.Cookie(&fiber.Cookie{
+		Name:     oidcStateCookie,
+		Value:    "",
+		Path:     oidcCookiePath,
+		Expires:  time.Unix(0, 0),
+		HTTPOnly: true,
+		Secure:   c.Protocol() == "https",
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/models"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/services"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// oidcAuth — Auth для входа через провайдера: StartOIDC выдаёт state-1,
+// CompleteOIDC отвечает resp или err.
+type oidcAuth struct {
+	services.AuthService
+	resp      *models.AuthResponse
+	err       error
+	completed []string
+}
+
+func (a *oidcAuth) StartOIDC(context.Context, string, string, string) (string, string, error) {
+	return "https://sso.example/authorize?state=state-1", "state-1", nil
+}
+
+func (a *oidcAuth) CompleteOIDC(_ context.Context, _, state, _ string) (*models.AuthResponse, string, error) {
+	a.completed = append(a.completed, state)
+	return a.resp, "student@example.com", a.err
+}
+
+func newOIDCApp(auth *oidcAuth, frontendURL string) *fiber.App {
+	h := &Handler{apiService: &services.ApiGateway{Auth: auth}, oidcFrontendURL: frontendURL}
+	app := fiber.New()
+	app.Get("/api/v1/auth/oidc/:provider/start", h.StartOIDC)
+	app.Get("/api/v1/auth/oidc/:provider/callback", h.OIDCCallback)
+	return app
+}
+
+func oidcCallback(t *testing.T, app *fiber.App, query, cookieState string) *http.Response {
+	t.Helper()
+	req := httptest.NewRequest(fiber.MethodGet, "/api/v1/auth/oidc/sso/callback?"+query, nil)
+	if cookieState != "" {
+		req.AddCookie(&http.Cookie{Name: oidcStateCookie, Value: cookieState})
+	}
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("callback: %v", err)
+	}
+	return resp
+}
+
+func TestOIDCStartBindsStateToBrowser(t *testing.T) {
+	auth := &oidcAuth{resp: &models.AuthResponse{UserUUID: "u1", Token: "access"}}
+	app := newOIDCApp(auth, "")
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/api/v1/auth/oidc/sso/start", nil))
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusFound || !strings.HasPrefix(resp.Header.Get("Location"), "https://sso.example/") {
+		t.Fatalf("start: status %d, location %q", resp.StatusCode, resp.Header.Get("Location"))
+	}
+	var state *http.Cookie
+	for _, c := range resp.Cookies() {
+		if c.Name == oidcStateCookie {
+			state = c
+		}
+	}
+	if state == nil || state.Value != "state-1" || !state.HttpOnly || state.Path != oidcCookiePath {
+		t.Fatalf("state cookie = %+v", state)
+	}
+
+	resp = oidcCallback(t, app, "state=state-1&code=code-1", state.Value)
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("callback: status %d, want 200", resp.StatusCode)
+	}
+	var body models.AuthResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || body.Token != "access" {
+		t.Fatalf("callback body %+v, %v", body, err)
+	}
+	// Cookie одноразовая: callback её стирает.
+	for _, c := range resp.Cookies() {
+		if c.Name == oidcStateCookie && c.Value != "" {
+			t.Fatalf("state cookie kept: %+v", c)
+		}
+	}
+}
+
+func TestOIDCCallbackErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		cookie   string
+		authErr  error
+		status   int
+		code     string
+		complete bool
+	}{
+		// Ссылку со своим state подсунули другому браузеру (login CSRF).
+		{name: "state mismatch", query: "state=state-2&code=code-1", cookie: "state-1", status: fiber.StatusBadRequest, code: "OIDC_STATE_MISMATCH"},
+		{name: "no state cookie", query: "state=state-1&code=code-1", status: fiber.StatusBadRequest, code: "OIDC_STATE_MISMATCH"},
+		{name: "no code", query: "state=state-1", cookie: "state-1", status: fiber.StatusBadRequest, code: "OIDC_STATE_MISMATCH"},
+		{name: "cancelled at provider", query: "state=state-1&error=access_denied", cookie: "state-1", status: fiber.StatusBadRequest, code: "OIDC_CANCELLED"},
+		// Auth не принял id_token (подпись, aud, exp, nonce) или state истёк.
+		{name: "rejected id token", query: "state=state-1&code=code-1", cookie: "state-1",
+			authErr: status.Error(codes.Unauthenticated, "invalid id token"), status: fiber.StatusUnauthorized, code: "OIDC_LOGIN_FAILED", complete: true},
+		{name: "unverified email", query: "state=state-1&code=code-1", cookie: "state-1",
+			authErr: status.Error(codes.FailedPrecondition, "email not verified"), status: fiber.StatusForbidden, code: "OIDC_EMAIL_NOT_VERIFIED", complete: true},
+		{name: "identity linked elsewhere", query: "state=state-1&code=code-1", cookie: "state-1",
+			authErr: status.Error(codes.AlreadyExists, "identity linked"), status: fiber.StatusConflict, code: "OIDC_IDENTITY_LINKED", complete: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth := &oidcAuth{err: tt.authErr}
+			resp := oidcCallback(t, newOIDCApp(auth, ""), tt.query, tt.cookie)
+
+			var body models.Error
+			_ = json.NewDecoder(resp.Body).Decode(&body)
+			if resp.StatusCode != tt.status || body.Code != tt.code {
+				t.Fatalf("status %d, code %q; want %d, %q", resp.StatusCode, body.Code, tt.status, tt.code)
+			}
+			if got := len(auth.completed) > 0; got != tt.complete {
+				t.Fatalf("CompleteOIDC called: %t, want %t", got, tt.complete)
+			}
+		})
+	}
+}
+
+func TestOIDCCallbackRedirectsToFrontend(t *testing.T) {
+	const frontend = "https://app.example/auth/callback"
+
+	fragment := func(t *testing.T, resp *http.Response) url.Values {
+		t.Helper()
+		loc := resp.Header.Get("Location")
+		if resp.StatusCode != fiber.StatusFound || !strings.HasPrefix(loc, frontend+"#") {
+			t.Fatalf("status %d, location %q; want redirect to frontend", resp.StatusCode, loc)
+		}
+		values, err := url.ParseQuery(strings.TrimPrefix(loc, frontend+"#"))
+		if err != nil {
+			t.Fatalf("fragment %q: %v", loc, err)
+		}
+		return values
+	}
+
+	auth := &oidcAuth{resp: &models.AuthResponse{UserUUID: "u1", Role: "ROLE_STUDENT", MFARequired: true, MFAToken: "mfa-1"}}
+	app := newOIDCApp(auth, frontend)
+
+	// Включён TOTP: во фрагменте mfa-токен, а не пара токенов.
+	got := fragment(t, oidcCallback(t, app, "state=state-1&code=code-1", "state-1"))
+	if got.Get("mfa_token") != "mfa-1" || got.Get("token") != "" {
+		t.Fatalf("fragment %v, want mfa_token only", got)
+	}
+
+	got = fragment(t, oidcCallback(t, app, "state=state-2&code=code-1", "state-1"))
+	if got.Get("error") != "OIDC_STATE_MISMATCH" || len(auth.completed) != 1 {
+		t.Fatalf("fragment %v, CompleteOIDC calls %d; want state mismatch without a call", got, len(auth.completed))
+	}
+}
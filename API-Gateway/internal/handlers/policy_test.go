@@ -0,0 +1,332 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	companyv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/company/v1"
+	"github.com/gofiber/fiber/v2"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/models"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/policy"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/services"
+)
+
+const policyFile = "../../configs/policy.yaml"
+
+// Ожидаемый доступ маршрутов по ролям в порядке PolicyRoles():
+// ROLE_ADMIN, ROLE_COMPANY_OWNER, ROLE_DEVELOPER, ROLE_EMPLOYER, ROLE_EXPERT,
+// ROLE_STUDENT. "*" — публичный маршрут, "A" — разрешён, "-" — запрещён,
+// "O" — разрешён только над своим ресурсом (владелец, HR компании, автор,
+// исполнитель, участник треда).
+//
+// Таблица — спецификация, а не снимок: новый маршрут или изменение политики
+// роняют тест, пока доступ не записан здесь явно.
+var routeAccess = map[string]string{
+	"POST /api/v1/auth/login":                                 "******",
+	"POST /api/v1/auth/register":                              "******",
+	"POST /api/v1/auth/refresh":                               "******",
+	"POST /api/v1/auth/password/reset":                        "******",
+	"POST /api/v1/auth/password/reset/confirm":                "******",
+	"POST /api/v1/auth/logout":                                "AAAAAA",
+	"POST /api/v1/auth/unlock":                                "A-----",
+	"POST /api/v1/auth/switch-role":                           "AAAAAA",
+	"POST /api/v1/auth/mfa/verify":                            "******",
+	"POST /api/v1/auth/mfa/enroll":                            "******",
+	"POST /api/v1/auth/mfa/enroll/confirm":                    "******",
+	"POST /api/v1/auth/mfa/totp":                              "AAAAAA",
+	"POST /api/v1/auth/mfa/totp/confirm":                      "AAAAAA",
+	"POST /api/v1/auth/mfa/totp/disable":                      "AAAAAA",
+	"POST /api/v1/auth/mfa/recovery-codes":                    "AAAAAA",
+	"GET /api/v1/auth/oidc/providers":                         "******",
+	"GET /api/v1/auth/oidc/:provider/start":                   "******",
+	"GET /api/v1/auth/oidc/:provider/callback":                "******",
+	"POST /api/v1/auth/oidc/:provider/link":                   "AAAAAA",
+	"GET /api/v1/auth/sessions":                               "AAAAAA",
+	"POST /api/v1/auth/sessions/revoke-others":                "AAAAAA",
+	"DELETE /api/v1/auth/sessions/:id":                        "AAAAAA",
+	"POST /api/v1/auth/email/verify":                          "******",
+	"POST /api/v1/auth/email/verify/resend":                   "AAAAAA",
+	"GET /api/v1/account/deletion/:id":                        "******",
+	"POST /api/v1/account/export":                             "AAAAAA",
+	"GET /api/v1/account/export/:id":                          "AAAAAA",
+	"GET /api/v1/files/:entity_id/:file_name":                 "AAAAAA",
+	"GET /api/v1/users":                                       "AAAAAA",
+	"GET /api/v1/users/me":                                    "AAAAAA",
+	"GET /api/v1/users/:id":                                   "AAAAAA",
+	"GET /api/v1/users/:id/achievements":                      "AAAAAA",
+	"PATCH /api/v1/users/edit":                                "----AA",
+	"DELETE /api/v1/users":                                    "-----A",
+	"POST /api/v1/users/files/avatar":                         "---A-A",
+	"POST /api/v1/users/files/resume":                         "---A-A",
+	"DELETE /api/v1/users/files/avatar":                       "---A-A",
+	"DELETE /api/v1/users/files/resume":                       "---A-A",
+	"GET /api/v1/user/achievements":                           "---AAA",
+	"POST /api/v1/user/achievements":                          "-----A",
+	"POST /api/v1/user/achievements/:id/confirm":              "-----A",
+	"GET /api/v1/user/achievements/:id/download":              "---AAA",
+	"DELETE /api/v1/user/achievements/:id":                    "-----A",
+	"POST /api/v1/user/achievements/:id/submit":               "-----A",
+	"GET /api/v1/expert/queue":                                "----A-",
+	"POST /api/v1/expert/achievements/:id/review":             "----A-",
+	"POST /api/v1/expert/quests":                              "----A-",
+	"GET /api/v1/expert/test/:slug":                           "----A-",
+	"POST /api/v1/expert/test/:slug":                          "----A-",
+	"GET /api/v1/chat/threads":                                "AAAAAA",
+	"PATCH /api/v1/chat/messages/:msg_id":                     "AAAAAA",
+	"GET /api/v1/chat/:kind/:rid":                             "OOOOOO",
+	"POST /api/v1/chat/:kind/:rid":                            "OOOOOO",
+	"DELETE /api/v1/chat/:kind/:rid":                          "AAAAAA",
+	"GET /api/v1/hr":                                          "-A-A-A",
+	"GET /api/v1/hr/me":                                       "---A--",
+	"PATCH /api/v1/hr/edit":                                   "---A--",
+	"DELETE /api/v1/hr":                                       "---A--",
+	"GET /api/v1/hr/vacancy":                                  "-A-A--",
+	"GET /api/v1/hr/vacancy/:id":                              "-O-O--",
+	"POST /api/v1/hr/vacancy":                                 "-A-A--",
+	"PATCH /api/v1/hr/vacancy/:id":                            "-O----",
+	"DELETE /api/v1/hr/vacancy/:id":                           "-O----",
+	"POST /api/v1/hr/vacancy/:id/moderate":                    "-O----",
+	"GET /api/v1/positions":                                   "---A--",
+	"GET /api/v1/vacancy":                                     "---A-A",
+	"GET /api/v1/vacancy/:id":                                 "---A-A",
+	"POST /api/v1/vacancy/:id/respond":                        "-----A",
+	"POST /api/v1/vacancy/:id/files/attachment":               "-O-O--",
+	"DELETE /api/v1/vacancy/:id/files/attachment":             "-O-O--",
+	"GET /api/v1/user/applications":                           "-----A",
+	"DELETE /api/v1/user/applications/:id":                    "-----O",
+	"GET /api/v1/hr/vacancy/:id/applications":                 "-O-O--",
+	"PATCH /api/v1/hr/applications/:id":                       "-O-O--",
+	"GET /api/v1/skills/search":                               "AAAAAA",
+	"GET /api/v1/skills/popular":                              "AAAAAA",
+	"GET /api/v1/skills/bulk":                                 "AAAAAA",
+	"GET /api/v1/tasks":                                       "-A-A-A",
+	"GET /api/v1/tasks/mine":                                  "-----A",
+	"GET /api/v1/tasks/my-submissions":                        "-----A",
+	"GET /api/v1/tasks/:id":                                   "-A-A-A",
+	"POST /api/v1/tasks/:id/apply":                            "-----A",
+	"POST /api/v1/tasks/:id/submit":                           "-----A",
+	"POST /api/v1/tasks/:id/solution-upload-init":             "-----A",
+	"POST /api/v1/tasks/:id/solution-upload-confirm":          "-----A",
+	"GET /api/v1/hr/tasks":                                    "-A-A--",
+	"POST /api/v1/hr/tasks":                                   "-A-A--",
+	"PATCH /api/v1/hr/tasks/:id":                              "-O-O--",
+	"DELETE /api/v1/hr/tasks/:id":                             "-O-O--",
+	"GET /api/v1/hr/tasks/:id/submissions":                    "-O-O--",
+	"POST /api/v1/hr/tasks/submissions/:submission_id/review": "-A-A--",
+	"GET /api/v1/company":                                     "-A-A-A",
+	"GET /api/v1/company/me":                                  "-A----",
+	"GET /api/v1/company/api-keys":                            "-A----",
+	"POST /api/v1/company/api-keys":                           "-A----",
+	"DELETE /api/v1/company/api-keys/:id":                     "-A----",
+	"GET /api/v1/company/membership/my":                       "-A-A--",
+	"GET /api/v1/company/memberships/my":                      "-A-A--",
+	"GET /api/v1/company/members":                             "-A----",
+	"POST /api/v1/company/membership/:membership_id/review":   "-O----",
+	"GET /api/v1/company/audit":                               "-A----",
+	"GET /api/v1/company/:id":                                 "-A-A-A",
+	"PATCH /api/v1/company":                                   "-A----",
+	"DELETE /api/v1/company":                                  "-A----",
+	"POST /api/v1/company/:id/files/logo":                     "-O----",
+	"POST /api/v1/company/:id/files/documents":                "-O----",
+	"DELETE /api/v1/company/:id/files/logo":                   "-O----",
+	"POST /api/v1/company/:id/membership/apply":               "---A--",
+	"GET /api/v1/admin/users":                                 "A-----",
+	"POST /api/v1/admin/users/:id/ban":                        "A-----",
+	"DELETE /api/v1/admin/users/:id/ban":                      "A-----",
+	"GET /api/v1/admin/companies":                             "A-----",
+	"POST /api/v1/admin/companies/:id/verify":                 "A-----",
+	"DELETE /api/v1/admin/companies/:id/verify":               "A-----",
+	"POST /api/v1/admin/vacancies/:id/close":                  "A-----",
+	"POST /api/v1/admin/search/reindex":                       "A-----",
+	"POST /api/v1/admin/cleaner/run":                          "A-----",
+	"GET /api/v1/admin/actions":                               "A-----",
+	"GET /api/v1/admin/audit":                                 "A-----",
+}
+
+// ownershipResolvers — резолверы с отношениями настоящих, но без сервисов:
+// ресурс "own" — во всех отношениях своего типа к любому субъекту,
+// остальные чужие.
+func ownershipResolvers() map[string]policy.Resolver {
+	resolvers := policy.NewResolvers(&services.ApiGateway{})
+	for kind, r := range resolvers {
+		relations := r.Relations
+		r.Resolve = func(_ context.Context, _ policy.Subject, id string) ([]string, error) {
+			if id == "own" {
+				return relations, nil
+			}
+			return nil, nil
+		}
+		resolvers[kind] = r
+	}
+	return resolvers
+}
+
+func TestRoutePolicyMatrix(t *testing.T) {
+	roles := PolicyRoles()
+	engine, err := policy.Load(policyFile, roles, ownershipResolvers())
+	if err != nil {
+		t.Fatalf("load policy: %v", err)
+	}
+	h := NewHandler(&services.ApiGateway{}, nil, nil, nil, nil, engine, nil, nil, nil, nil, "", nil)
+	h.Init()
+	if err := h.CheckPolicy(); err != nil {
+		t.Fatalf("policy does not match routes:\n%v", err)
+	}
+
+	ctx := context.Background()
+	seen := make(map[string]bool, len(routeAccess))
+	for _, r := range h.Routes() {
+		route := r.Method + " " + r.Path
+		seen[route] = true
+		want, ok := routeAccess[route]
+		if !ok {
+			t.Errorf("%s (%s): access is not specified", route, r.Action)
+			continue
+		}
+		if len(want) != len(roles) {
+			t.Errorf("%s: access %q does not cover roles %v", route, want, roles)
+			continue
+		}
+
+		if r.Action == policy.Public {
+			if want != strings.Repeat("*", len(roles)) {
+				t.Errorf("%s: route is public, want %q", route, want)
+			}
+			continue
+		}
+		if err := engine.Authorize(ctx, policy.Subject{}, r.Action, policy.Resource{ID: "own"}); !errors.Is(err, policy.ErrUnauthenticated) {
+			t.Errorf("%s: anonymous subject got %v, want ErrUnauthenticated", route, err)
+		}
+
+		for i, role := range roles {
+			subject := policy.Subject{UserID: "user-1", Role: role}
+			own := engine.Authorize(ctx, subject, r.Action, policy.Resource{ID: "own"}) == nil
+			foreign := engine.Authorize(ctx, subject, r.Action, policy.Resource{ID: "foreign"}) == nil
+
+			got := "-"
+			switch {
+			case own && foreign:
+				got = "A"
+			case own:
+				got = "O"
+			case foreign:
+				got = "?"
+			}
+			if got != want[i:i+1] {
+				t.Errorf("%s (%s) for %s: got %q (own %t, foreign %t), want %q",
+					route, r.Action, role, got, own, foreign, want[i:i+1])
+			}
+		}
+	}
+	for route := range routeAccess {
+		if !seen[route] {
+			t.Errorf("%s: specified, but not registered", route)
+		}
+	}
+}
+
+// Маршруты администрирования и разблокировка аккаунта — только ROLE_ADMIN:
+// ни ROLE_DEVELOPER, ни другие роли их не обходят.
+func TestAdminRoutesOnlyForAdmin(t *testing.T) {
+	const adminOnly = "A-----"
+	for route, access := range routeAccess {
+		_, path, _ := strings.Cut(route, " ")
+		if (strings.HasPrefix(path, "/api/v1/admin/") || path == "/api/v1/auth/unlock") && access != adminOnly {
+			t.Errorf("%s: access %q, want %q", route, access, adminOnly)
+		}
+	}
+}
+
+type vacancyStub struct {
+	services.VacancyService
+	vacancies map[string]*models.Vacancy
+}
+
+func (s vacancyStub) GetVacancy(_ context.Context, id string) (*models.Vacancy, error) {
+	return s.vacancies[id], nil
+}
+
+type membershipStub struct {
+	services.CompanyService
+	memberships map[string]*models.CompanyMember
+}
+
+func (s membershipStub) GetMembershipByUser(_ context.Context, userID string) (*models.CompanyMember, error) {
+	if ms, ok := s.memberships[userID]; ok {
+		return ms, nil
+	}
+	return nil, errors.New("membership not found")
+}
+
+// Маршруты вакансии проверяют компанию вакансии: пользователь, чей id
+// совпал с :id вакансии, ею не владеет (так ошибался прежний
+// OwnerOrRoleMiddleware(ID, ...)).
+func TestVacancyRoutesCheckVacancyCompany(t *testing.T) {
+	const (
+		companyID = "company-1"
+		vacancyID = "vacancy-1"
+		hrID      = "hr-1"
+	)
+	approved := int32(companyv1.MembershipStatus_MEMBERSHIP_STATUS_APPROVED)
+	api := &services.ApiGateway{
+		Vacancy: vacancyStub{vacancies: map[string]*models.Vacancy{
+			vacancyID: {ID: vacancyID, CompanyID: companyID},
+		}},
+		Company: membershipStub{memberships: map[string]*models.CompanyMember{
+			hrID:      {UserID: hrID, CompanyID: companyID, Status: approved},
+			vacancyID: {UserID: vacancyID, CompanyID: "company-2", Status: approved},
+		}},
+	}
+	engine, err := policy.Load(policyFile, PolicyRoles(), policy.NewResolvers(api))
+	if err != nil {
+		t.Fatalf("load policy: %v", err)
+	}
+	h := NewHandler(api, nil, nil, nil, nil, engine, nil, nil, nil, nil, "", nil)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals(string(UserIDKey), c.Get("X-User-Id"))
+		c.Locals(string(RoleKey), Role(c.Get("X-Role")))
+		return c.Next()
+	})
+	ok := func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) }
+	app.Patch("/api/v1/hr/vacancy/:id", h.authorize("vacancy.update"), ok)
+	app.Delete("/api/v1/hr/vacancy/:id", h.authorize("vacancy.delete"), ok)
+	app.Post("/api/v1/vacancy/:id/files/attachment", h.authorize("vacancy.attachment"), ok)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		userID string
+		role   Role
+		want   int
+	}{
+		{"owner updates", fiber.MethodPatch, "/api/v1/hr/vacancy/" + vacancyID, companyID, ROLE_COMPANY, fiber.StatusOK},
+		{"owner deletes", fiber.MethodDelete, "/api/v1/hr/vacancy/" + vacancyID, companyID, ROLE_COMPANY, fiber.StatusOK},
+		{"user id equals vacancy id updates", fiber.MethodPatch, "/api/v1/hr/vacancy/" + vacancyID, vacancyID, ROLE_COMPANY, fiber.StatusForbidden},
+		{"user id equals vacancy id deletes", fiber.MethodDelete, "/api/v1/hr/vacancy/" + vacancyID, vacancyID, ROLE_COMPANY, fiber.StatusForbidden},
+		{"user id equals vacancy id attaches", fiber.MethodPost, "/api/v1/vacancy/" + vacancyID + "/files/attachment", vacancyID, ROLE_HR, fiber.StatusForbidden},
+		{"company hr attaches", fiber.MethodPost, "/api/v1/vacancy/" + vacancyID + "/files/attachment", hrID, ROLE_HR, fiber.StatusOK},
+		{"company hr cannot delete", fiber.MethodDelete, "/api/v1/hr/vacancy/" + vacancyID, hrID, ROLE_HR, fiber.StatusForbidden},
+		{"developer", fiber.MethodPatch, "/api/v1/hr/vacancy/" + vacancyID, companyID, ROLE_DEVELOPER, fiber.StatusForbidden},
+		{"missing vacancy", fiber.MethodPatch, "/api/v1/hr/vacancy/vacancy-404", companyID, ROLE_COMPANY, fiber.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("X-User-Id", tt.userID)
+			req.Header.Set("X-Role", string(tt.role))
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("request: %v", err)
+			}
+			if resp.StatusCode != tt.want {
+				t.Fatalf("%s %s as %s (%s): status %d, want %d", tt.method, tt.path, tt.userID, tt.role, resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
@@ -1,182 +1,112 @@
 package handlers
 
 import (
-	"encoding/base64"
-	"encoding/json"
+	"fmt"
+	"log"
 	"strconv"
-	"strings"
-	"sync"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/studjobs/hh_for_students/api-gateway/internal/metrics"
-	"golang.org/x/time/rate"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/ratelimit"
 )
 
-// RateLimiter — token-bucket с per-user (или per-IP fallback) ключом.
-// Используется как Fiber middleware.
+// RateLimiter — ограничение частоты запросов. Используется как Fiber middleware.
 //
 // Дизайн-выбор:
-//   - golang.org/x/time/rate — стандарт-де-факто, lock-free, не требует Redis;
-//     trade-off — счётчики не разделяются между инстансами Gateway. Для одного
-//     инстанса (наш случай) допустимо. Под кластер пришлось бы взять redis_rate
-//     либо token-bucket в Lua.
-//   - Ключ: если есть валидный по форме JWT с непустым sub — используем
-//     "u:<userID>" (читаем payload без проверки подписи; bucket-ключ не должен
-//     быть доверенным — даже если злоумышленник подделает токен, он получит
-//     отдельный bucket с тем же лимитом, защита auth-middleware'а на следующем
-//     шаге отбросит запрос). Иначе — "ip:<addr>" для public-роутов.
+//   - Лимит выбирается политикой (configs/ratelimit.yaml) по методу, маршруту
+//     и роли: строгий на вход и отклики, мягкий на поиск навыков. Счётчики —
+//     в Redis (общие для всех инстансов), при его недоступности — в памяти
+//     (см. ratelimit.Fallback).
+//   - Ключ: пользователь, которого проверил AuthMiddleware, — "u:<userID>";
+//     без проверенного токена (вход, регистрация и прочие публичные
+//     маршруты) — "ip:<addr>". Payload токена до проверки подписи не читаем:
+//     иначе каждый запрос с выдуманным sub получал бы свой свежий bucket.
 //     Per-user важен для локалки и SPA с многими вкладками: вкладки одного юзера
 //     ходят с одного IP, и per-IP bucket бы их объединял в общий лимит.
-//   - sweep раз в 5 мин чистит мапу от мёртвых ключей, чтобы не лизалась память.
+//     Роль для лимита — тоже из проверенного токена.
+//   - Два слоя: PreAuth до AuthMiddleware списывает запрос с потолка на IP
+//     (политика ip), Middleware после него — с лимита маршрута. Без первого
+//     слоя поток токенов с неизвестным kid или перебор API-ключей доходил бы
+//     до Auth без всякого лимита: AuthMiddleware отправляет их туда раньше,
+//     чем выяснится, чей это запрос.
+//   - Каждая политика считает запросы отдельно: всплеск поиска не съедает
+//     лимит на отклики.
+//   - Ответ несёт заголовки RateLimit-Limit/-Remaining/-Reset/-Policy
+//     (draft-ietf-httpapi-ratelimit-headers), отказ — ещё и Retry-After.
 type RateLimiter struct {
-	rps     rate.Limit
-	burst   int
-	mu      sync.Mutex
-	buckets map[string]*bucket
+	limiter  ratelimit.Limiter
+	policies *ratelimit.Policies
 }
 
-type bucket struct {
-	limiter *rate.Limiter
-	seenAt  time.Time
-}
-
-// NewRateLimiter возвращает ratelimiter. perMin = разрешённых запросов в минуту,
-// burst = насколько единомоментный всплеск разрешён.
-func NewRateLimiter(perMin, burst int) *RateLimiter {
-	rl := &RateLimiter{
-		rps:     rate.Limit(float64(perMin) / 60.0),
-		burst:   burst,
-		buckets: make(map[string]*bucket),
+// NewRateLimiter возвращает ratelimiter с хранилищем limiter и политиками policies.
+func NewRateLimiter(limiter ratelimit.Limiter, policies *ratelimit.Policies) *RateLimiter {
+	return &RateLimiter{
+		limiter:  limiter,
+		policies: policies,
 	}
-	go rl.sweep()
-	return rl
 }
 
-// Middleware возвращает Fiber-handler. Извлекает ключ (user или IP), поднимает
-// bucket для него и проверяет .Allow().
-func (rl *RateLimiter) Middleware() fiber.Handler {
+// PreAuth возвращает Fiber-handler потолка на IP. Ставится перед
+// AuthMiddleware.
+func (rl *RateLimiter) PreAuth() fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		key := rateLimitKey(c)
-		bucket := rl.bucketFor(key)
-		if !bucket.Allow() {
-			route := c.Route().Path
-			if route == "" {
-				route = c.Path()
-			}
-			metrics.RateLimitThrottled.WithLabelValues(route).Inc()
-			// Retry-After в секундах: обратно к rate (если 600/min, то 1 token = 0.1s → округлим до 1s).
-			retryAfter := int(60.0 / float64(rl.rps*60))
-			if retryAfter < 1 {
-				retryAfter = 1
-			}
-			c.Set("Retry-After", strconv.Itoa(retryAfter))
-			c.Set("X-RateLimit-Limit", strconv.FormatFloat(float64(rl.rps)*60, 'f', 0, 64))
-			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
-				"error":       "too many requests",
-				"retry_after": retryAfter,
-			})
+		// Фоновое обновление кэша (refreshCache) — не запрос клиента.
+		if c.Locals(string(cacheRefreshKey)) != nil {
+			return c.Next()
 		}
-		return c.Next()
-	}
-}
-
-func (rl *RateLimiter) bucketFor(key string) *rate.Limiter {
-	rl.mu.Lock()
-	defer rl.mu.Unlock()
-	b, ok := rl.buckets[key]
-	if !ok {
-		b = &bucket{limiter: rate.NewLimiter(rl.rps, rl.burst)}
-		rl.buckets[key] = b
+		pol := rl.policies.IP()
+		return rl.limit(c, pol, "ip:"+clientIP(c), pol.Limit)
 	}
-	b.seenAt = time.Now()
-	return b.limiter
 }
 
-// sweep периодически чистит buckets неактивных ключей, чтобы не было утечки.
-func (rl *RateLimiter) sweep() {
-	ticker := time.NewTicker(5 * time.Minute)
-	defer ticker.Stop()
-	for range ticker.C {
-		cutoff := time.Now().Add(-10 * time.Minute)
-		rl.mu.Lock()
-		for k, b := range rl.buckets {
-			if b.seenAt.Before(cutoff) {
-				delete(rl.buckets, k)
-			}
+// Middleware возвращает Fiber-handler. Подбирает политику, извлекает ключ
+// (user или IP) и списывает запрос с лимита. Ставится после AuthMiddleware:
+// пользователь и роль берутся из его проверенных данных.
+func (rl *RateLimiter) Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Locals(string(cacheRefreshKey)) != nil {
+			return c.Next()
 		}
-		rl.mu.Unlock()
-	}
-}
+		pol := rl.policies.Match(c.Method(), c.Path())
+		userID := getUserIDFromContext(c)
+		limit := pol.LimitFor(string(getRoleFromContext(c)))
 
-// rateLimitKey возвращает ключ bucket'а: user-id из JWT или IP-фоллбек.
-// JWT не верифицируется криптографически — на этом этапе нам нужен только
-// stable identifier, и auth-middleware на следующем шаге отвергнет невалидный
-// токен (так что подделкой обойти лимит нельзя без обхода auth, что отдельная
-// граница защиты).
-func rateLimitKey(c *fiber.Ctx) string {
-	if userID := userIDFromAuthHeader(c.Get("Authorization")); userID != "" {
-		return "u:" + userID
+		key := "ip:" + clientIP(c)
+		if pol.Key == ratelimit.KeyUser && userID != "" {
+			key = "u:" + userID
+		}
+		return rl.limit(c, pol, key, limit)
 	}
-	return "ip:" + clientIP(c)
 }
 
-// userIDFromAuthHeader парсит JWT payload (без проверки подписи) и достаёт sub.
-// Используется ТОЛЬКО для определения rate-limit-ключа.
-func userIDFromAuthHeader(header string) string {
-	if header == "" {
-		return ""
-	}
-	const prefix = "Bearer "
-	if !strings.HasPrefix(header, prefix) {
-		return ""
-	}
-	token := header[len(prefix):]
-	parts := strings.Split(token, ".")
-	if len(parts) != 3 {
-		return ""
-	}
-	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+// limit списывает запрос с лимита key политики pol. Если хранилище не
+// ответило, запрос пропускается: лимит — защита от перегрузки, а не граница
+// доступа.
+func (rl *RateLimiter) limit(c *fiber.Ctx, pol *ratelimit.Policy, key string, limit ratelimit.Limit) error {
+	res, err := rl.limiter.Allow(c.UserContext(), pol.Name+":"+key, limit)
 	if err != nil {
-		return ""
-	}
-	var claims struct {
-		Sub    string `json:"sub"`
-		UserID string `json:"user_id"`
-	}
-	if err := json.Unmarshal(payload, &claims); err != nil {
-		return ""
-	}
-	if claims.Sub != "" {
-		return claims.Sub
+		log.Printf("rate limiter: %s for %s failed, request allowed: %v", pol.Name, key, err)
+		return c.Next()
 	}
-	return claims.UserID
-}
 
-// clientIP возвращает IP клиента, учитывая X-Forwarded-For (от reverse proxy).
-// Берём первое значение из CSV, чтобы избежать spoofing промежуточными прокси.
-func clientIP(c *fiber.Ctx) string {
-	if xff := c.Get("X-Forwarded-For"); xff != "" {
-		// Первое значение — клиент, остальные — прокси.
-		for i := 0; i < len(xff); i++ {
-			if xff[i] == ',' {
-				return trimSpace(xff[:i])
-			}
-		}
-		return trimSpace(xff)
-	}
-	if real := c.Get("X-Real-IP"); real != "" {
-		return trimSpace(real)
-	}
-	return c.IP()
+	c.Set("RateLimit-Limit", strconv.Itoa(limit.Burst))
+	c.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	c.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.ResetAfter)))
+	c.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d;burst=%d", limit.Rate, ceilSeconds(limit.Period), limit.Burst))
+
+	if !res.Allowed {
+		metrics.RateLimitThrottled.WithLabelValues(pol.Name).Inc()
+		retryAfter := max(ceilSeconds(res.RetryAfter), 1)
+		c.Set("Retry-After", strconv.Itoa(retryAfter))
+		return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+			"error":       "too many requests",
+			"retry_after": retryAfter,
+		})
+	}
+	return c.Next()
 }
 
-func trimSpace(s string) string {
-	for len(s) > 0 && (s[0] == ' ' || s[0] == '\t') {
-		s = s[1:]
-	}
-	for len(s) > 0 && (s[len(s)-1] == ' ' || s[len(s)-1] == '\t') {
-		s = s[:len(s)-1]
-	}
-	return s
+func ceilSeconds(d time.Duration) int {
+	return int((d + time.Second - 1) / time.Second)
 }
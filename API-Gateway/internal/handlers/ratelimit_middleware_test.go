@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/ratelimit"
+)
+
+// forgedToken — JWT с произвольным payload и без настоящей подписи.
+func forgedToken(sub, role string) string {
+	enc := base64.RawURLEncoding.EncodeToString
+	payload := fmt.Sprintf(`{"sub":%q,"role":%q}`, sub, role)
+	return enc([]byte(`{"alg":"RS256"}`)) + "." + enc([]byte(payload)) + "." + enc([]byte("sig"))
+}
+
+func newLimitedApp(t *testing.T) *fiber.App {
+	t.Helper()
+	policies, err := ratelimit.NewPolicies(ratelimit.File{
+		Policies: []ratelimit.Policy{{
+			Name:  "respond",
+			Paths: []string{"/respond"},
+			Key:   ratelimit.KeyUser,
+			Limit: ratelimit.Limit{Rate: 2, Period: time.Hour, Burst: 2},
+			Roles: map[string]ratelimit.Limit{
+				string(ROLE_STUDENT): {Rate: 100, Period: time.Hour, Burst: 100},
+			},
+		}},
+	}, ratelimit.Limit{Rate: 100, Period: time.Minute, Burst: 100})
+	if err != nil {
+		t.Fatalf("policies: %v", err)
+	}
+	rl := NewRateLimiter(ratelimit.NewMemoryLimiter(), policies)
+
+	app := fiber.New()
+	// Вместо AuthMiddleware: проверенный пользователь — из X-Verified-User.
+	app.Use(func(c *fiber.Ctx) error {
+		if user := c.Get("X-Verified-User"); user != "" {
+			c.Locals(string(UserIDKey), user)
+			c.Locals(string(RoleKey), ROLE_HR)
+		}
+		return c.Next()
+	})
+	app.Use(rl.Middleware())
+	app.Post("/respond", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+	return app
+}
+
+func TestRateLimiterIgnoresUnverifiedToken(t *testing.T) {
+	app := newLimitedApp(t)
+
+	// Каждый запрос — с новым sub и «щедрой» ролью: лимит всё равно общий
+	// по IP и обычный.
+	statuses := make([]int, 0, 3)
+	for i := range 3 {
+		req := httptest.NewRequest(fiber.MethodPost, "/respond", nil)
+		req.Header.Set("X-Forwarded-For", "203.0.113.7")
+		req.Header.Set("Authorization", "Bearer "+forgedToken(fmt.Sprintf("user-%d", i), string(ROLE_STUDENT)))
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("request: %v", err)
+		}
+		statuses = append(statuses, resp.StatusCode)
+	}
+	want := []int{fiber.StatusOK, fiber.StatusOK, fiber.StatusTooManyRequests}
+	if fmt.Sprint(statuses) != fmt.Sprint(want) {
+		t.Fatalf("statuses %v, want %v", statuses, want)
+	}
+}
+
+func TestRateLimiterKeysVerifiedUsers(t *testing.T) {
+	app := newLimitedApp(t)
+
+	send := func(user string) int {
+		req := httptest.NewRequest(fiber.MethodPost, "/respond", nil)
+		req.Header.Set("X-Forwarded-For", "203.0.113.7")
+		req.Header.Set("X-Verified-User", user)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("request: %v", err)
+		}
+		return resp.StatusCode
+	}
+
+	// Два пользователя за одним IP расходуют свои лимиты.
+	for _, user := range []string{"hr-1", "hr-1", "hr-2", "hr-2"} {
+		if status := send(user); status != fiber.StatusOK {
+			t.Fatalf("%s: status %d, want 200", user, status)
+		}
+	}
+	if status := send("hr-1"); status != fiber.StatusTooManyRequests {
+		t.Fatalf("hr-1 over limit: status %d, want 429", status)
+	}
+}
+
+func TestRateLimiterCapsIPBeforeAuth(t *testing.T) {
+	policies, err := ratelimit.NewPolicies(ratelimit.File{
+		IP: &ratelimit.Policy{Limit: ratelimit.Limit{Rate: 3, Period: time.Hour, Burst: 3}},
+	}, ratelimit.Limit{Rate: 100, Period: time.Minute, Burst: 100})
+	if err != nil {
+		t.Fatalf("policies: %v", err)
+	}
+	rl := NewRateLimiter(ratelimit.NewMemoryLimiter(), policies)
+
+	// Вместо AuthMiddleware — счётчик: каждый вызов был бы запросом в Auth.
+	authCalls := 0
+	app := fiber.New()
+	app.Use(rl.PreAuth())
+	app.Use(func(c *fiber.Ctx) error {
+		authCalls++
+		return c.Next()
+	})
+	app.Use(rl.Middleware())
+	app.Get("/api/v1/vacancy", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+
+	statuses := make([]int, 0, 5)
+	for i := range 5 {
+		req := httptest.NewRequest(fiber.MethodGet, "/api/v1/vacancy", nil)
+		req.Header.Set("Authorization", "Bearer "+forgedToken(fmt.Sprintf("user-%d", i), string(ROLE_STUDENT)))
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("request: %v", err)
+		}
+		statuses = append(statuses, resp.StatusCode)
+	}
+	want := []int{fiber.StatusOK, fiber.StatusOK, fiber.StatusOK, fiber.StatusTooManyRequests, fiber.StatusTooManyRequests}
+	if fmt.Sprint(statuses) != fmt.Sprint(want) {
+		t.Fatalf("statuses %v, want %v", statuses, want)
+	}
+	if authCalls != 3 {
+		t.Fatalf("auth called %d times, want 3", authCalls)
+	}
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"context"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/models"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/services"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// clientContext — контекст вызова Auth с адресом и User-Agent клиента: по ним
+// Auth считает неудачные входы и подписывает сессии в списке устройств.
+func clientContext(c *fiber.Ctx) context.Context {
+	return services.WithClientInfo(c.UserContext(), clientIP(c), c.Get(fiber.HeaderUserAgent))
+}
+
+// ListSessions возвращает активные сессии пользователя
+// @Summary Активные сессии
+// @Description Входы пользователя на разных устройствах: устройство, IP, время входа и последней активности. Текущая сессия помечена current.
+// @Tags Auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.SessionsResponse "Сессии"
+// @Failure 401 {object} models.ErrorResponse "Не авторизован"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /auth/sessions [get]
+func (h *Handler) ListSessions(c *fiber.Ctx) error {
+	userID := getUserIDFromContext(c)
+
+	sessions, err := h.apiService.Auth.ListSessions(c.UserContext(), getTokenFromContext(c))
+	if err != nil {
+		log.Printf("API Gateway ListSessions failed for user %s: %v", userID, err)
+		return h.handleSessionError(c, err)
+	}
+
+	return c.JSON(models.SessionsResponse{Sessions: sessions})
+}
+
+// RevokeSession завершает сессию
+// @Summary Завершение сессии
+// @Description Завершает сессию на другом устройстве (или текущую): её refresh-токен отзывается, access-токены перестают приниматься.
+// @Tags Auth
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID сессии"
+// @Success 200 {object} models.SuccessResponse "Сессия завершена"
+// @Failure 401 {object} models.ErrorResponse "Не авторизован"
+// @Failure 404 {object} models.ErrorResponse "Сессия не найдена"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /auth/sessions/{id} [delete]
+func (h *Handler) RevokeSession(c *fiber.Ctx) error {
+	userID := getUserIDFromContext(c)
+	sessionID := c.Params("id")
+
+	if err := h.apiService.Auth.RevokeSession(c.UserContext(), getTokenFromContext(c), sessionID); err != nil {
+		log.Printf("API Gateway RevokeSession failed for user %s, session %s: %v", userID, sessionID, err)
+		return h.handleSessionError(c, err)
+	}
+
+	// Кэш статуса токенов хранится по токену, а не по сессии — сбрасываем
+	// весь кэш пользователя, иначе токены завершённой сессии жили бы до конца TTL.
+	if h.verifier != nil {
+		h.verifier.InvalidateUser(c.UserContext(), userID)
+	}
+
+	h.recordAudit(c, AuditSessionRevoke, "session", sessionID, "", fiber.Map{"revoked": false}, fiber.Map{"revoked": true})
+	log.Printf("Session %s revoked by user_uuid: %s", sessionID, userID)
+	return c.JSON(models.SuccessResponse{Message: "Session revoked"})
+}
+
+// RevokeOtherSessions завершает все сессии, кроме текущей
+// @Summary Выход на других устройствах
+// @Description Завершает все сессии пользователя, кроме той, из которой сделан запрос.
+// @Tags Auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.RevokedSessionsResponse "Число завершённых сессий"
+// @Failure 401 {object} models.ErrorResponse "Не авторизован"
+// @Failure 404 {object} models.ErrorResponse "Токен выпущен до появления сессий — войдите заново"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /auth/sessions/revoke-others [post]
+func (h *Handler) RevokeOtherSessions(c *fiber.Ctx) error {
+	userID := getUserIDFromContext(c)
+
+	count, err := h.apiService.Auth.RevokeOtherSessions(c.UserContext(), getTokenFromContext(c))
+	if err != nil {
+		log.Printf("API Gateway RevokeOtherSessions failed for user %s: %v", userID, err)
+		return h.handleSessionError(c, err)
+	}
+
+	if h.verifier != nil && count > 0 {
+		h.verifier.InvalidateUser(c.UserContext(), userID)
+	}
+
+	h.recordAudit(c, AuditSessionsRevoke, "account", userID, "", nil, fiber.Map{"revoked_sessions": count})
+	log.Printf("Revoked %d other sessions of user_uuid: %s", count, userID)
+	return c.JSON(models.RevokedSessionsResponse{Revoked: count})
+}
+
+func (h *Handler) handleSessionError(c *fiber.Ctx, err error) error {
+	if st, ok := status.FromError(err); ok && st.Code() == codes.NotFound {
+		return c.Status(fiber.StatusNotFound).JSON(models.Error{
+			Code:    "SESSION_NOT_FOUND",
+			Message: "Session not found",
+		})
+	}
+	return h.handleAuthError(c, err)
+}
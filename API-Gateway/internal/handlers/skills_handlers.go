@@ -27,7 +27,7 @@ func (h *Handler) SearchSkills(c *fiber.Ctx) error {
 	category, _ := strconv.Atoi(c.Query("category", "0"))
 	limit, _ := strconv.Atoi(c.Query("limit", "20"))
 
-	skills, err := h.apiService.Skills.Search(c.Context(), query, int32(category), int32(limit))
+	skills, err := h.apiService.Skills.Search(c.UserContext(), query, int32(category), int32(limit))
 	if err != nil {
 		log.Printf("SearchSkills: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(models.Error{
@@ -53,7 +53,7 @@ func (h *Handler) PopularSkills(c *fiber.Ctx) error {
 	category, _ := strconv.Atoi(c.Query("category", "0"))
 	limit, _ := strconv.Atoi(c.Query("limit", "20"))
 
-	skills, err := h.apiService.Skills.Popular(c.Context(), int32(category), int32(limit))
+	skills, err := h.apiService.Skills.Popular(c.UserContext(), int32(category), int32(limit))
 	if err != nil {
 		log.Printf("PopularSkills: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(models.Error{
@@ -88,7 +88,7 @@ func (h *Handler) BulkSkills(c *fiber.Ctx) error {
 		}
 	}
 
-	skills, err := h.apiService.Skills.Bulk(c.Context(), slugs)
+	skills, err := h.apiService.Skills.Bulk(c.UserContext(), slugs)
 	if err != nil {
 		log.Printf("BulkSkills: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(models.Error{
@@ -47,7 +47,7 @@ func (h *Handler) GetUsers(c *fiber.Ctx) error {
 	// Иначе — обычная выборка из Users (быстрее и не требует ES).
 	if h.apiService.Search.Available() && (len(skillSlugs) > 0 || query != "") {
 		log.Printf("GetUsers: routing through Search (skill_slugs=%v query=%q)", skillSlugs, query)
-		profiles, err = h.apiService.Search.SearchProfiles(c.Context(), query, skillSlugs, category, int32(page), int32(limit))
+		profiles, err = h.apiService.Search.SearchProfiles(c.UserContext(), query, skillSlugs, category, int32(page), int32(limit))
 	} else {
 		req := &usersv1.GetAllProfilesRequest{
 			Pagination: &commonv1.Pagination{
@@ -59,7 +59,7 @@ func (h *Handler) GetUsers(c *fiber.Ctx) error {
 		if category != "" {
 			req.ProfessionCategory = category
 		}
-		profiles, err = h.apiService.User.GetUsers(c.Context(), req)
+		profiles, err = h.apiService.User.GetUsers(c.UserContext(), req)
 	}
 	if err != nil {
 		log.Printf("GetUsers: Failed to get users: %v", err)
@@ -96,7 +96,7 @@ func (h *Handler) GetUsers(c *fiber.Ctx) error {
 	}
 
 	// Обогащаем информацией о файлах
-	h.enrichUserListWithFiles(c.Context(), profileList.Profiles, profiles.Profiles)
+	h.enrichUserListWithFiles(c.UserContext(), profileList.Profiles, profiles.Profiles)
 
 	log.Printf("GetUsers: Successfully retrieved %d users", len(profileList.Profiles))
 	return c.JSON(profileList)
@@ -174,7 +174,7 @@ func (h *Handler) getUserWithFiles(c *fiber.Ctx, userID string) (*models.User, e
 	}
 
 	// Вызываем users service
-	profile, err := h.apiService.User.GetUser(c.Context(), userID)
+	profile, err := h.apiService.User.GetUser(c.UserContext(), userID)
 	if err != nil {
 		log.Printf("getUserWithFiles: Failed to get user %s: %v", userID, err)
 		return nil, c.Status(fiber.StatusNotFound).JSON(fiber.Map{
@@ -202,7 +202,7 @@ func (h *Handler) getUserWithFiles(c *fiber.Ctx, userID string) (*models.User, e
 	}
 
 	// Обогащаем информацией о файлах
-	h.enrichUserWithFiles(c.Context(), user, profile)
+	h.enrichUserWithFiles(c.UserContext(), user, profile)
 
 	return user, nil
 }
@@ -405,7 +405,7 @@ func (h *Handler) UpdateUser(c *fiber.Ctx) error {
 	}
 
 	// Вызываем users service
-	updatedProfile, err := h.apiService.User.UpdateUser(c.Context(), &usersv1.UpdateProfileRequest{
+	updatedProfile, err := h.apiService.User.UpdateUser(c.UserContext(), &usersv1.UpdateProfileRequest{
 		Id:      userID,
 		Profile: profile,
 	})
@@ -432,54 +432,25 @@ func (h *Handler) UpdateUser(c *fiber.Ctx) error {
 	}
 
 	// Обогащаем информацией о файлах
-	h.enrichUserWithFiles(c.Context(), user, updatedProfile)
+	h.enrichUserWithFiles(c.UserContext(), user, updatedProfile)
 
 	log.Printf("UpdateUser: Successfully updated user: %s", userID)
 	return c.JSON(user)
 }
 
-// DeleteUser удаляет профиль пользователя
-// @Summary Удалить пользователя
-// @Description Удаляет профиль текущего пользователя
+// DeleteUser удаляет аккаунт пользователя
+// @Summary Удалить аккаунт
+// @Description Удаляет аккаунт текущего пользователя во всех сервисах: аккаунт и токены — сразу, профиль, файлы, отклики, членство в компаниях и переписку — в фоне (см. правила хранения в internal/deletion). Ход удаления — по status_url.
 // @Tags Users
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {object} map[string]string "Сообщение об успешном удалении"
+// @Success 202 {object} models.AccountDeletion "Удаление начато"
 // @Failure 400 {object} models.ErrorResponse "Неверный ID пользователя"
 // @Failure 401 {object} models.ErrorResponse "Неавторизованный доступ"
 // @Failure 403 {object} models.ErrorResponse "Доступ запрещен"
 // @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
 // @Router /users [delete]
 func (h *Handler) DeleteUser(c *fiber.Ctx) error {
-	userID := getUserIDFromContext(c)
-	currentUserRole := getRoleFromContext(c)
-	log.Printf("DeleteUser: Deleting user %s (role: %s)", userID, currentUserRole)
-
-	// Валидация UUID
-	if _, err := uuid.Parse(userID); err != nil {
-		log.Printf("DeleteUser: Invalid UUID format: %s", userID)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid user ID format",
-		})
-	}
-
-	// Вызываем users service
-	if err := h.apiService.User.DeleteUser(c.Context(), userID); err != nil {
-		log.Printf("DeleteUser: Failed to delete user %s: %v", userID, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to delete profile",
-		})
-	}
-
-	// Удаляем пользователя из auth service
-	if err := h.apiService.Auth.DeleteUser(c.Context(), userID); err != nil {
-		log.Printf("DeleteUser: Failed to delete user from auth %s: %v", userID, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{})
-	}
-
-	log.Printf("DeleteUser: Successfully deleted user: %s", userID)
-	return c.JSON(fiber.Map{
-		"message": "Profile deleted successfully",
-	})
+	return h.startAccountDeletion(c)
 }
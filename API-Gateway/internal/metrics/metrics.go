@@ -36,11 +36,33 @@ var (
 		Help: "Number of GET responses that missed Redis cache and went upstream.",
 	}, []string{"route"})
 
-	// RateLimitThrottled — заполняется Phase 4 (token-bucket middleware).
+	// CacheStale — устаревшие записи, отданные на время фонового обновления.
+	CacheStale = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "gateway_cache_stale_total",
+		Help: "Number of stale GET responses served from Redis cache while a refresh was pending.",
+	}, []string{"route"})
+
+	// CacheCoalesced — промахи, дождавшиеся ответа одновременного промаха
+	// по тому же ключу вместо своего запроса в сервис.
+	CacheCoalesced = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "gateway_cache_coalesced_total",
+		Help: "Number of GET cache misses served by a concurrent upstream request for the same key.",
+	}, []string{"route"})
+
+	// RateLimitThrottled — отказы 429 по политике rate limit (configs/ratelimit.yaml):
+	// имя группы маршрутов, ip или default. Маршрут в метке дал бы
+	// кардинальность по каждому пути с id.
 	RateLimitThrottled = prometheus.NewCounterVec(prometheus.CounterOpts{
 		Name: "gateway_ratelimit_throttled_total",
-		Help: "Number of requests rejected with 429 by rate limiter.",
-	}, []string{"route"})
+		Help: "Number of requests rejected with 429 by rate limiter, by rate limit policy.",
+	}, []string{"policy"})
+
+	// RateLimitFallback — решения rate limiter'а в памяти процесса из-за
+	// недоступного Redis.
+	RateLimitFallback = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "gateway_ratelimit_fallback_total",
+		Help: "Number of rate limit decisions made in memory because Redis was unavailable.",
+	})
 )
 
 func init() {
@@ -50,7 +72,10 @@ func init() {
 		httpDuration,
 		CacheHits,
 		CacheMisses,
+		CacheStale,
+		CacheCoalesced,
 		RateLimitThrottled,
+		RateLimitFallback,
 	)
 }
 
@@ -0,0 +1,78 @@
+package models
+
+// AdminAccount HTTP модель аккаунта в панели администратора
+type AdminAccount struct {
+	UserUUID  string   `json:"user_uuid" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Email     string   `json:"email" example:"user@example.com"`
+	Role      string   `json:"role" example:"ROLE_STUDENT"`
+	Roles     []string `json:"roles" example:"ROLE_STUDENT,ROLE_EMPLOYER"`
+	CreatedAt string   `json:"created_at" example:"2024-01-01T12:00:00Z"`
+	// BannedAt — когда аккаунт заблокирован (пусто — не заблокирован).
+	BannedAt  string `json:"banned_at,omitempty" example:"2024-02-01T12:00:00Z"`
+	BanReason string `json:"ban_reason,omitempty" example:"spam"`
+}
+
+// AdminAccountFilter — условия поиска аккаунтов: часть email или uuid, роль
+// и статус ("banned", "active" или пусто).
+type AdminAccountFilter struct {
+	Query  string
+	Role   string
+	Status string
+}
+
+// AdminAccountList HTTP модель страницы аккаунтов
+type AdminAccountList struct {
+	Accounts   []AdminAccount      `json:"accounts"`
+	Pagination *PaginationResponse `json:"pagination"`
+}
+
+// BanUserRequest HTTP модель блокировки аккаунта
+type BanUserRequest struct {
+	Reason string `json:"reason" example:"spam" validate:"max=500"`
+}
+
+// CloseVacancyRequest HTTP модель принудительного закрытия вакансии
+type CloseVacancyRequest struct {
+	Reason string `json:"reason" example:"нарушение правил площадки" validate:"max=500"`
+}
+
+// ReindexRequest HTTP модель перестройки поисковых индексов
+type ReindexRequest struct {
+	// RecreateIndices — удалить и создать индексы заново (после смены маппинга).
+	RecreateIndices bool `json:"recreate_indices" example:"false"`
+}
+
+// ReindexResult — сколько документов проиндексировано
+type ReindexResult struct {
+	Profiles   int32 `json:"profiles" example:"120"`
+	Vacancies  int32 `json:"vacancies" example:"45"`
+	MicroTasks int32 `json:"microtasks" example:"30"`
+}
+
+// AdminAction HTTP модель записи журнала действий администраторов
+type AdminAction struct {
+	ID         string `json:"id" example:"5b1f0c7e-2f4a-4c1e-9b8a-1d2e3f4a5b6c"`
+	AdminUUID  string `json:"admin_uuid" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Action     string `json:"action" example:"admin.user.ban"`
+	TargetType string `json:"target_type" example:"user"`
+	TargetID   string `json:"target_id" example:"123e4567-e89b-12d3-a456-426614174001"`
+	// Details — JSON-объект с параметрами запроса (причина и т.п.).
+	Details   string `json:"details" example:"{\"reason\":\"spam\"}"`
+	Status    int    `json:"status" example:"200"`
+	IP        string `json:"ip" example:"203.0.113.7"`
+	CreatedAt string `json:"created_at" example:"2024-01-01T12:00:00Z"`
+}
+
+// AdminActionFilter — условия выборки журнала; пустые поля не ограничивают.
+type AdminActionFilter struct {
+	AdminUUID  string
+	Action     string
+	TargetType string
+	TargetID   string
+}
+
+// AdminActionList HTTP модель страницы журнала
+type AdminActionList struct {
+	Actions    []AdminAction       `json:"actions"`
+	Pagination *PaginationResponse `json:"pagination"`
+}
@@ -0,0 +1,34 @@
+package models
+
+// AuditEvent HTTP модель записи журнала аудита
+type AuditEvent struct {
+	ID           string `json:"id" example:"5b1f0c7e-2f4a-4c1e-9b8a-1d2e3f4a5b6c"`
+	ActorUUID    string `json:"actor_uuid" example:"123e4567-e89b-12d3-a456-426614174000"`
+	ActorRole    string `json:"actor_role" example:"ROLE_EMPLOYER"`
+	Action       string `json:"action" example:"application.review"`
+	ResourceType string `json:"resource_type" example:"application"`
+	ResourceID   string `json:"resource_id" example:"42"`
+	CompanyID    string `json:"company_id,omitempty" example:"123e4567-e89b-12d3-a456-426614174001"`
+	// Changes — JSON-объект {"поле": {"before": ..., "after": ...}}.
+	Changes   string `json:"changes" example:"{\"status\":{\"before\":1,\"after\":3}}"`
+	IP        string `json:"ip" example:"203.0.113.7"`
+	CreatedAt string `json:"created_at" example:"2024-01-01T12:00:00Z"`
+}
+
+// AuditFilter — условия выборки журнала аудита; пустые поля не ограничивают.
+// Since/Until — RFC3339, полуинтервал [since, until).
+type AuditFilter struct {
+	CompanyID    string
+	ActorUUID    string
+	Action       string
+	ResourceType string
+	ResourceID   string
+	Since        string
+	Until        string
+}
+
+// AuditEventList HTTP модель страницы журнала аудита
+type AuditEventList struct {
+	Events     []AuditEvent        `json:"events"`
+	Pagination *PaginationResponse `json:"pagination"`
+}
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 // LoginRequest HTTP модель для входа
 // @Description Запрос на аутентификацию пользователя
 type LoginRequest struct {
@@ -12,14 +14,222 @@ type LoginRequest struct {
 // @Description Запрос на регистрацию нового пользователя
 type SignUpRequest struct {
 	Email    string `json:"email" example:"user@example.com" validate:"required,email"`
-	Password string `json:"password" example:"password123" validate:"required,min=6"`
-	Role     string `json:"role" example:"ROLE_STUDENT" validate:"required,oneof=ROLE_STUDENT ROLE_DEVELOPER ROLE_HR ROLE_COMPANY"`
+	Password string `json:"password" example:"Str0ng-passw0rd" validate:"required,min=8"`
+	Role     string `json:"role" example:"ROLE_STUDENT" validate:"required,oneof=ROLE_STUDENT ROLE_EMPLOYER ROLE_EXPERT ROLE_COMPANY_OWNER"`
+}
+
+// RefreshRequest HTTP модель обмена refresh-токена
+// @Description Запрос на получение новой пары токенов
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" example:"3q2-7wEAAAB0b2tlbg..." validate:"required"`
+}
+
+// LogoutRequest HTTP модель выхода
+// @Description Запрос на выход. Без all_sessions отзывается только текущий токен
+// @Description (и refresh_token, если передан); с all_sessions — все сессии пользователя.
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token,omitempty" example:"3q2-7wEAAAB0b2tlbg..."`
+	AllSessions  bool   `json:"all_sessions" example:"false"`
+}
+
+// SwitchRoleRequest HTTP модель смены активной роли
+// @Description Роль аккаунта, под которой выпустить новую пару токенов.
+// @Description Текущий access-токен (и refresh_token, если передан) отзывается.
+type SwitchRoleRequest struct {
+	Role         string `json:"role" example:"ROLE_EXPERT" validate:"required"`
+	RefreshToken string `json:"refresh_token,omitempty" example:"3q2-7wEAAAB0b2tlbg..."`
+}
+
+// PasswordResetRequest HTTP модель запроса сброса пароля
+// @Description Запрос ссылки для сброса пароля на email
+type PasswordResetRequest struct {
+	Email string `json:"email" example:"user@example.com" validate:"required,email"`
+}
+
+// PasswordResetConfirmRequest HTTP модель подтверждения сброса пароля
+// @Description Токен из письма и новый пароль
+type PasswordResetConfirmRequest struct {
+	Token       string `json:"token" example:"3q2-7wEAAAB0b2tlbg..." validate:"required"`
+	NewPassword string `json:"new_password" example:"N3w-passw0rd" validate:"required,min=8"`
+}
+
+// ConfirmEmailRequest HTTP модель подтверждения email
+// @Description Токен из письма с подтверждением адреса
+type ConfirmEmailRequest struct {
+	Token string `json:"token" example:"3q2-7wEAAAB0b2tlbg..." validate:"required"`
+}
+
+// UnlockAccountRequest HTTP модель ручной разблокировки входа
+// @Description Email аккаунта, с которого снимается блокировка после неудачных входов
+type UnlockAccountRequest struct {
+	Email string `json:"email" example:"user@example.com" validate:"required,email"`
 }
 
 // AuthResponse HTTP модель ответа аутентификации
-// @Description Ответ с данными аутентификации
+// @Description Ответ с данными аутентификации. При mfa_required=true токенов нет:
+// @Description вход завершается через /auth/mfa/verify (или подключение TOTP, если
+// @Description mfa_enrollment_required=true) с полученным mfa_token.
 type AuthResponse struct {
-	Token    string `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
-	UserUUID string `json:"user_uuid" example:"550e8400-e29b-41d4-a716-446655440000"`
-	Role     string `json:"role" example:"ROLE_STUDENT"`
+	Token        string `json:"token,omitempty" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	RefreshToken string `json:"refresh_token,omitempty" example:"3q2-7wEAAAB0b2tlbg..."`
+	UserUUID     string `json:"user_uuid" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Role         string `json:"role" example:"ROLE_STUDENT"`
+	// Roles — все роли аккаунта; между ними можно переключаться без повторного входа
+	Roles []string `json:"roles,omitempty" example:"ROLE_STUDENT,ROLE_EXPERT"`
+	// NewAccount — регистрация создала аккаунт, а не добавила роль существующему
+	NewAccount bool `json:"-"`
+
+	MFARequired           bool   `json:"mfa_required,omitempty" example:"false"`
+	MFAToken              string `json:"mfa_token,omitempty" example:"3q2-7wEAAAB0b2tlbg..."`
+	MFAEnrollmentRequired bool   `json:"mfa_enrollment_required,omitempty" example:"false"`
+}
+
+// TokenInfo результат проверки access-токена
+type TokenInfo struct {
+	Valid         bool
+	UserUUID      string
+	Role          string
+	Roles         []string
+	EmailVerified bool
+}
+
+// APIKeyInfo результат проверки API-ключа интеграции
+type APIKeyInfo struct {
+	Valid bool
+	KeyID string
+	// CompanyID — компания ключа, она же uuid её владельца
+	CompanyID     string
+	Scopes        []string
+	EmailVerified bool
+	// ExpiresAt — дольше этого результат проверки не кэшируется
+	ExpiresAt time.Time
+}
+
+// JWK публичный ключ подписи токенов (RFC 8037, OKP/Ed25519)
+type JWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+}
+
+// VerifyMFARequest HTTP модель второго шага входа
+// @Description mfa_token из ответа /auth/login и код из приложения (или код восстановления)
+type VerifyMFARequest struct {
+	MFAToken string `json:"mfa_token" example:"3q2-7wEAAAB0b2tlbg..." validate:"required"`
+	Code     string `json:"code" example:"123456" validate:"required"`
+}
+
+// EnrollTOTPRequest HTTP модель подключения TOTP
+// @Description Для авторизованного пользователя тело пустое; при обязательном
+// @Description подключении во время входа передаётся mfa_token.
+type EnrollTOTPRequest struct {
+	MFAToken string `json:"mfa_token,omitempty" example:"3q2-7wEAAAB0b2tlbg..."`
+}
+
+// TOTPEnrollment HTTP модель нового TOTP-секрета
+// @Description Секрет и otpauth-ссылка для QR-кода в приложении-аутентификаторе
+type TOTPEnrollment struct {
+	Secret          string `json:"secret" example:"JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+	ProvisioningURI string `json:"provisioning_uri" example:"otpauth://totp/StudJobs:user@example.com?secret=JBSWY3DPEHPK3PXP&issuer=StudJobs"`
+}
+
+// ConfirmTOTPRequest HTTP модель подтверждения TOTP
+// @Description Первый код из приложения; mfa_token — как в EnrollTOTPRequest
+type ConfirmTOTPRequest struct {
+	MFAToken string `json:"mfa_token,omitempty" example:"3q2-7wEAAAB0b2tlbg..."`
+	Code     string `json:"code" example:"123456" validate:"required"`
+}
+
+// TOTPConfirmation HTTP модель включённого TOTP
+// @Description Коды восстановления показываются один раз. auth заполнен, если
+// @Description подключение шло в рамках входа.
+type TOTPConfirmation struct {
+	RecoveryCodes []string      `json:"recovery_codes" example:"abcd-efgh,ijkl-mnop"`
+	Auth          *AuthResponse `json:"auth,omitempty"`
+}
+
+// MFACodeRequest HTTP модель действия, подтверждаемого кодом
+// @Description Код из приложения или код восстановления
+type MFACodeRequest struct {
+	Code string `json:"code" example:"123456" validate:"required"`
+}
+
+// RecoveryCodesResponse HTTP модель новых кодов восстановления
+// @Description Новый набор кодов; старые больше не действуют
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes" example:"abcd-efgh,ijkl-mnop"`
+}
+
+// OIDCProvidersResponse HTTP модель списка провайдеров входа
+// @Description Имена провайдеров для кнопок «Войти через …»
+type OIDCProvidersResponse struct {
+	Providers []string `json:"providers" example:"google,github"`
+}
+
+// OIDCAuthorization HTTP модель ссылки на страницу провайдера
+// @Description Куда перенаправить браузер для привязки провайдера
+type OIDCAuthorization struct {
+	AuthorizationURL string `json:"authorization_url" example:"https://accounts.google.com/o/oauth2/v2/auth?client_id=..."`
+}
+
+// Session HTTP модель сессии пользователя
+// @Description Вход на одном устройстве. current — сессия, из которой сделан запрос.
+type Session struct {
+	ID         string `json:"id" example:"5b1f0c7e-2f4a-4c1e-9b8a-1d2e3f4a5b6c"`
+	Device     string `json:"device" example:"Chrome on Windows"`
+	IP         string `json:"ip" example:"203.0.113.7"`
+	UserAgent  string `json:"user_agent" example:"Mozilla/5.0 (Windows NT 10.0; Win64; x64) ..."`
+	Role       string `json:"role" example:"ROLE_STUDENT"`
+	CreatedAt  string `json:"created_at" example:"2024-01-01T12:00:00Z"`
+	LastSeenAt string `json:"last_seen_at" example:"2024-01-02T08:30:00Z"`
+	Current    bool   `json:"current" example:"true"`
+}
+
+// SessionsResponse HTTP модель списка сессий
+// @Description Активные сессии, последние — первыми
+type SessionsResponse struct {
+	Sessions []Session `json:"sessions"`
+}
+
+// RevokedSessionsResponse HTTP модель завершения других сессий
+// @Description Сколько сессий завершено
+type RevokedSessionsResponse struct {
+	Revoked int `json:"revoked" example:"2"`
+}
+
+// APIKey HTTP модель API-ключа интеграции
+// @Description Ключ компании для интеграций (ATS, HRM). Сам ключ не показывается — только его начало.
+type APIKey struct {
+	ID         string   `json:"id" example:"5b1f0c7e-2f4a-4c1e-9b8a-1d2e3f4a5b6c"`
+	Name       string   `json:"name" example:"Huntflow"`
+	Prefix     string   `json:"prefix" example:"sjk_Xk3v9QaZ"`
+	Scopes     []string `json:"scopes" example:"vacancies:read,applications:read"`
+	CreatedAt  string   `json:"created_at" example:"2024-01-01T12:00:00Z"`
+	ExpiresAt  string   `json:"expires_at,omitempty" example:"2024-04-01T12:00:00Z"`
+	LastUsedAt string   `json:"last_used_at,omitempty" example:"2024-01-02T08:30:00Z"`
+}
+
+// CreateAPIKeyRequest HTTP модель выпуска API-ключа
+// @Description Права: vacancies:read, vacancies:write, applications:read, applications:write,
+// @Description tasks:read, tasks:write, company:read. expires_in_days = 0 — срок по умолчанию.
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name" example:"Huntflow" validate:"required"`
+	Scopes        []string `json:"scopes" example:"vacancies:read,applications:read" validate:"required"`
+	ExpiresInDays int      `json:"expires_in_days,omitempty" example:"90"`
+}
+
+// APIKeyCreated HTTP модель выпущенного API-ключа
+// @Description key показывается один раз: сохраните его сразу
+type APIKeyCreated struct {
+	APIKey
+	Key string `json:"key" example:"sjk_Xk3v9QaZ..."`
+}
+
+// APIKeysResponse HTTP модель списка API-ключей
+// @Description Действующие ключи компании, новые — первыми
+type APIKeysResponse struct {
+	APIKeys []APIKey `json:"api_keys"`
 }
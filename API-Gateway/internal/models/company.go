@@ -18,6 +18,10 @@ type Company struct {
 	CleanupVacanciesAfterDays int32 `json:"cleanup_vacancies_after_days,omitempty"`
 	CleanupTasksAfterDays     int32 `json:"cleanup_tasks_after_days,omitempty"`
 
+	// VerifiedAt — когда компанию проверил администратор платформы (пусто —
+	// не проверена).
+	VerifiedAt string `json:"verified_at,omitempty"`
+
 	// Ссылки на файлы
 	LogoURL *string `json:"logo_url,omitempty"` // Ссылка на логотип
 	LogoID  *string `json:"logo_id,omitempty"`  // ID логотипа в achievements
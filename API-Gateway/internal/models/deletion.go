@@ -0,0 +1,21 @@
+package models
+
+// AccountDeletion HTTP модель удаления аккаунта
+// @Description Ход удаления аккаунта. status: in_progress — шаги ещё выполняются (шаг с error будет повторён), completed — данные удалены во всех сервисах.
+type AccountDeletion struct {
+	ID          string                `json:"id" example:"7d3c1a52-8f0e-4b8e-a1f4-2c9d6e5b3a10"`
+	Status      string                `json:"status" example:"in_progress"`
+	Steps       []AccountDeletionStep `json:"steps"`
+	RequestedAt string                `json:"requested_at" example:"2024-01-01T12:00:00Z"`
+	UpdatedAt   string                `json:"updated_at" example:"2024-01-01T12:00:03Z"`
+	// StatusURL — где следить за удалением: токены аккаунта уже отозваны.
+	StatusURL string `json:"status_url,omitempty" example:"/api/v1/account/deletion/7d3c1a52-8f0e-4b8e-a1f4-2c9d6e5b3a10"`
+}
+
+// AccountDeletionStep HTTP модель шага удаления
+// @Description Удаление данных в одном сервисе
+type AccountDeletionStep struct {
+	Name  string `json:"name" example:"chat"`
+	Done  bool   `json:"done" example:"true"`
+	Error string `json:"error,omitempty" example:""`
+}
@@ -0,0 +1,34 @@
+package models
+
+// DataExport HTTP модель выгрузки персональных данных
+// @Description Ход выгрузки. status: in_progress — архив собирается, ready — архив готов (download_url действует до download_expires_at; повторный запрос статуса выдаёт новую ссылку), expired — архив уже удалён, failed — собрать архив не удалось.
+type DataExport struct {
+	ID          string `json:"id" example:"0f6b2c1e-9a3d-4e5f-8b7c-6d5e4f3a2b1c"`
+	Status      string `json:"status" example:"ready"`
+	RequestedAt string `json:"requested_at" example:"2024-01-01T12:00:00Z"`
+	UpdatedAt   string `json:"updated_at" example:"2024-01-01T12:00:05Z"`
+	// DownloadURL — ссылка на ZIP-архив, только в статусе ready.
+	DownloadURL       string `json:"download_url,omitempty" example:"https://s3.example.com/achievements/exports/...zip?X-Amz-Signature=..."`
+	DownloadExpiresAt int64  `json:"download_expires_at,omitempty" example:"1704114000"`
+	StatusURL         string `json:"status_url,omitempty" example:"/api/v1/account/export/0f6b2c1e-9a3d-4e5f-8b7c-6d5e4f3a2b1c"`
+}
+
+// AccountExport — данные аккаунта из Auth (account.json в архиве выгрузки).
+type AccountExport struct {
+	UserUUID        string           `json:"user_uuid"`
+	Email           string           `json:"email"`
+	Role            string           `json:"role"`
+	Roles           []string         `json:"roles"`
+	CreatedAt       string           `json:"created_at"`
+	EmailVerifiedAt string           `json:"email_verified_at,omitempty"`
+	MFAEnabled      bool             `json:"mfa_enabled"`
+	LinkedProviders []LinkedProvider `json:"linked_providers"`
+	Sessions        []Session        `json:"sessions"`
+}
+
+// LinkedProvider — внешний аккаунт (OIDC), привязанный к пользователю.
+type LinkedProvider struct {
+	Provider string `json:"provider"`
+	Email    string `json:"email,omitempty"`
+	LinkedAt string `json:"linked_at"`
+}
@@ -0,0 +1,97 @@
+// Package mtls — клиентская сторона взаимного TLS: Gateway предъявляет
+// сервисам сертификат api-gateway, выпущенный общим CA
+// (devops/generate_grpc_certs.sh), и проверяет их сертификаты тем же CA.
+// Какие методы доступны Gateway, решают сами сервисы.
+package mtls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Gateway — CommonName сертификата Gateway (и имя сервиса в трейсах).
+const Gateway = "api-gateway"
+
+// Config — сертификат сервиса и CA, которым подписаны сертификаты всех
+// сервисов. Без сертификатов сервис не стартует; gRPC без TLS возможен
+// только явно, с Insecure (локальная разработка).
+type Config struct {
+	CAFile   string
+	CertFile string
+	KeyFile  string
+	// Insecure разрешает gRPC без TLS, если сертификаты не заданы.
+	Insecure bool
+}
+
+// ConfigFromEnv читает GRPC_TLS_CA, GRPC_TLS_CERT, GRPC_TLS_KEY и
+// GRPC_TLS_INSECURE (1/true).
+func ConfigFromEnv() Config {
+	insecure, _ := strconv.ParseBool(os.Getenv("GRPC_TLS_INSECURE"))
+	return Config{
+		CAFile:   os.Getenv("GRPC_TLS_CA"),
+		CertFile: os.Getenv("GRPC_TLS_CERT"),
+		KeyFile:  os.Getenv("GRPC_TLS_KEY"),
+		Insecure: insecure,
+	}
+}
+
+func (c Config) Enabled() bool {
+	return c.CAFile != "" && c.CertFile != "" && c.KeyFile != ""
+}
+
+// errNotConfigured — сертификаты не заданы, а gRPC без TLS не разрешён явно.
+var errNotConfigured = errors.New("mtls: GRPC_TLS_CA, GRPC_TLS_CERT and GRPC_TLS_KEY are required (GRPC_TLS_INSECURE=1 allows plaintext gRPC for local development)")
+
+func (c Config) load() (*x509.CertPool, tls.Certificate, error) {
+	caPEM, err := os.ReadFile(c.CAFile)
+	if err != nil {
+		return nil, tls.Certificate{}, fmt.Errorf("mtls: read CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, tls.Certificate{}, errors.New("mtls: no certificates in CA file")
+	}
+
+	cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+	if err != nil {
+		return nil, tls.Certificate{}, fmt.Errorf("mtls: load key pair: %w", err)
+	}
+	return pool, cert, nil
+}
+
+// DialOption — transport credentials клиента: предъявляет сертификат
+// сервиса и проверяет сертификат сервера по CA. Имя сервера — host из addr
+// (docker DNS-имя сервиса, оно же SAN его сертификата). Без сертификатов —
+// ошибка; с cfg.Insecure — соединение без TLS.
+func DialOption(cfg Config, addr string) (grpc.DialOption, error) {
+	if !cfg.Enabled() {
+		if !cfg.Insecure {
+			return nil, errNotConfigured
+		}
+		return grpc.WithTransportCredentials(insecure.NewCredentials()), nil
+	}
+
+	pool, cert, err := cfg.load()
+	if err != nil {
+		return nil, err
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	return grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      pool,
+		ServerName:   host,
+		MinVersion:   tls.VersionTLS13,
+	})), nil
+}
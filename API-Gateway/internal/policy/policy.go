@@ -0,0 +1,315 @@
+// Package policy — единая проверка доступа Gateway.
+//
+// Кто что может делать, описано в файле политики (configs/policy.yaml):
+// для каждого действия — тип ресурса, право API-ключа и правила вида
+// «роли + отношение к ресурсу». Отношения («owner», «member», «author»...)
+// вычисляют резолверы по типу ресурса (см. resolvers.go). Маршруты зовут
+// только Engine.Authorize — ни списков ролей, ни проверок владения в
+// обработчиках.
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// Public — действие маршрута, доступного без проверки (вход, регистрация...).
+const Public = "public"
+
+// AnyRole в правиле — любая роль аутентифицированного пользователя.
+const AnyRole = "*"
+
+var (
+	ErrUnauthenticated = errors.New("policy: subject is not authenticated")
+	ErrDenied          = errors.New("policy: access denied")
+	ErrNotFound        = errors.New("policy: resource not found")
+	// ErrUnavailable — сервис, по которому проверяется отношение, не настроен.
+	ErrUnavailable = errors.New("policy: resource service unavailable")
+)
+
+// Subject — кто выполняет действие.
+type Subject struct {
+	UserID string
+	Role   string
+	// APIKey — запрос по API-ключу интеграции; Scopes — права ключа.
+	APIKey bool
+	Scopes []string
+}
+
+// Resource — над чем выполняется действие. Type пустой — берётся из
+// политики действия; ID пустой — действие не над конкретным ресурсом.
+type Resource struct {
+	Type string
+	ID   string
+}
+
+// Rule — правило доступа: одна из ролей Roles и (если задано) отношение
+// Relation к ресурсу.
+type Rule struct {
+	Roles    []string `mapstructure:"roles"`
+	Relation string   `mapstructure:"relation"`
+}
+
+// Action — политика одного действия.
+type Action struct {
+	Name     string `mapstructure:"name"`
+	Resource string `mapstructure:"resource"`
+	// Scope — право API-ключа, открывающее действие; пусто — ключам закрыто.
+	Scope string `mapstructure:"scope"`
+	// Strict — суперпользователи не обходят правила действия: доступ только
+	// по allow (действия администраторов платформы).
+	Strict bool   `mapstructure:"strict"`
+	Allow  []Rule `mapstructure:"allow"`
+}
+
+// File — содержимое файла политики.
+type File struct {
+	// Superusers — роли, которым разрешено всё. Указываются явно, чтобы
+	// обход проверок был виден в политике, а не спрятан в коде.
+	Superusers []string `mapstructure:"superusers"`
+	Actions    []Action `mapstructure:"actions"`
+}
+
+// Resolver вычисляет отношения субъекта к ресурсу одного типа.
+type Resolver struct {
+	// Relations — какие отношения умеет вычислять Resolve.
+	Relations []string
+	// Resolve возвращает отношения субъекта к ресурсу id; ErrNotFound —
+	// ресурса нет.
+	Resolve func(ctx context.Context, subject Subject, id string) ([]string, error)
+}
+
+type Engine struct {
+	superusers []string
+	actions    map[string]*Action
+	resolvers  map[string]Resolver
+}
+
+// Load читает файл политики и проверяет его (см. New).
+func Load(path string, roles []string, resolvers map[string]Resolver) (*Engine, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("policy: read %s: %w", path, err)
+	}
+	var f File
+	if err := v.Unmarshal(&f); err != nil {
+		return nil, fmt.Errorf("policy: parse %s: %w", path, err)
+	}
+	return New(f, roles, resolvers)
+}
+
+// New собирает Engine и проверяет политику: роли известны Gateway,
+// действия не повторяются, у каждого отношения есть резолвер.
+func New(f File, roles []string, resolvers map[string]Resolver) (*Engine, error) {
+	e := &Engine{
+		superusers: f.Superusers,
+		actions:    make(map[string]*Action, len(f.Actions)),
+		resolvers:  resolvers,
+	}
+
+	var errs []error
+	for _, role := range f.Superusers {
+		if !slices.Contains(roles, role) {
+			errs = append(errs, fmt.Errorf("superusers: unknown role %q", role))
+		}
+	}
+	for i := range f.Actions {
+		a := &f.Actions[i]
+		if a.Name == "" || a.Name == Public {
+			errs = append(errs, fmt.Errorf("actions[%d]: invalid name %q", i, a.Name))
+			continue
+		}
+		if _, ok := e.actions[a.Name]; ok {
+			errs = append(errs, fmt.Errorf("%s: duplicate action", a.Name))
+			continue
+		}
+		e.actions[a.Name] = a
+
+		for _, rule := range a.Allow {
+			if len(rule.Roles) == 0 {
+				errs = append(errs, fmt.Errorf("%s: rule without roles", a.Name))
+			}
+			for _, role := range rule.Roles {
+				if role != AnyRole && !slices.Contains(roles, role) {
+					errs = append(errs, fmt.Errorf("%s: unknown role %q", a.Name, role))
+				}
+			}
+			if rule.Relation == "" {
+				continue
+			}
+			resolver, ok := resolvers[a.Resource]
+			if !ok {
+				errs = append(errs, fmt.Errorf("%s: no resolver for resource %q", a.Name, a.Resource))
+			} else if !slices.Contains(resolver.Relations, rule.Relation) {
+				errs = append(errs, fmt.Errorf("%s: resource %q has no relation %q", a.Name, a.Resource, rule.Relation))
+			}
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("policy: invalid policy:\n%w", err)
+	}
+	return e, nil
+}
+
+// Authorize решает, может ли subject выполнить action над resource.
+// nil — можно; ErrUnauthenticated, ErrDenied, ErrNotFound или ошибка
+// резолвера — нельзя.
+func (e *Engine) Authorize(ctx context.Context, subject Subject, action string, resource Resource) error {
+	a, ok := e.actions[action]
+	if !ok {
+		log.Printf("policy: unknown action %q", action)
+		return ErrDenied
+	}
+	if subject.UserID == "" || subject.Role == "" {
+		return ErrUnauthenticated
+	}
+	if resource.Type == "" {
+		resource.Type = a.Resource
+	}
+
+	// Ключ интеграции ограничен своими правами, какой бы ни была роль.
+	if subject.APIKey && (a.Scope == "" || !slices.Contains(subject.Scopes, a.Scope)) {
+		log.Printf("policy: %s denied for api key of %s (scope %q)", action, subject.UserID, a.Scope)
+		return ErrDenied
+	}
+
+	if !a.Strict && slices.Contains(e.superusers, subject.Role) {
+		log.Printf("policy: %s allowed for superuser %s (%s)", action, subject.UserID, subject.Role)
+		return nil
+	}
+
+	var relations []string
+	for _, rule := range a.Allow {
+		if !rule.matches(subject.Role) {
+			continue
+		}
+		if rule.Relation == "" {
+			return nil
+		}
+		relations = append(relations, rule.Relation)
+	}
+	if len(relations) == 0 {
+		log.Printf("policy: %s denied for %s (role %s)", action, subject.UserID, subject.Role)
+		return ErrDenied
+	}
+
+	resolver, ok := e.resolvers[resource.Type]
+	if !ok || resource.ID == "" {
+		log.Printf("policy: %s denied for %s - cannot resolve %s %q", action, subject.UserID, resource.Type, resource.ID)
+		return ErrDenied
+	}
+	have, err := resolver.Resolve(ctx, subject, resource.ID)
+	if err != nil {
+		return err
+	}
+	for _, relation := range relations {
+		if slices.Contains(have, relation) {
+			return nil
+		}
+	}
+
+	log.Printf("policy: %s denied for %s (role %s) on %s %s: needs %v, has %v",
+		action, subject.UserID, subject.Role, resource.Type, resource.ID, relations, have)
+	return ErrDenied
+}
+
+// Route — маршрут Gateway и действие, которым он защищён.
+type Route struct {
+	Method string
+	Path   string
+	Action string
+}
+
+// CheckRoutes сверяет маршруты с политикой: действие каждого маршрута должно
+// быть описано. Действия, которые не использует ни один маршрут, — в лог:
+// скорее всего, маршрут удалён, а политика осталась.
+func (e *Engine) CheckRoutes(routes []Route) error {
+	used := make(map[string]bool, len(routes))
+	var errs []error
+	for _, r := range routes {
+		used[r.Action] = true
+		if r.Action != Public && !e.Has(r.Action) {
+			errs = append(errs, fmt.Errorf("%s %s: action %q is not defined", r.Method, r.Path, r.Action))
+		}
+	}
+	for _, name := range e.Actions() {
+		if !used[name] {
+			log.Printf("policy: action %q is not used by any route", name)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Has — действие описано в политике.
+func (e *Engine) Has(action string) bool {
+	_, ok := e.actions[action]
+	return ok
+}
+
+// Actions — имена всех действий политики.
+func (e *Engine) Actions() []string {
+	names := make([]string, 0, len(e.actions))
+	for name := range e.actions {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Explain — что политика даёт роли для действия, без обращения к
+// ресурсам: "allow", "deny", "superuser" или отношения через "|"
+// ("owner|member"). Для сводной матрицы маршрутов.
+func (e *Engine) Explain(role, action string) string {
+	if action == Public {
+		return "public"
+	}
+	a, ok := e.actions[action]
+	if !ok {
+		return "undefined"
+	}
+	if !a.Strict && slices.Contains(e.superusers, role) {
+		return "superuser"
+	}
+
+	var relations []string
+	for _, rule := range a.Allow {
+		if !rule.matches(role) {
+			continue
+		}
+		if rule.Relation == "" {
+			return "allow"
+		}
+		relations = append(relations, rule.Relation)
+	}
+	if len(relations) == 0 {
+		return "deny"
+	}
+	return strings.Join(relations, "|")
+}
+
+// Resource — тип ресурса действия ("" — действие не над ресурсом).
+func (e *Engine) Resource(action string) string {
+	if a, ok := e.actions[action]; ok {
+		return a.Resource
+	}
+	return ""
+}
+
+// Scope — право API-ключа для действия ("" — ключам закрыто).
+func (e *Engine) Scope(action string) string {
+	if a, ok := e.actions[action]; ok {
+		return a.Scope
+	}
+	return ""
+}
+
+func (r Rule) matches(role string) bool {
+	return slices.Contains(r.Roles, AnyRole) || slices.Contains(r.Roles, role)
+}
@@ -0,0 +1,313 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/studjobs/hh_for_students/api-gateway/internal/models"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/services"
+)
+
+var testRoles = []string{
+	"ROLE_ADMIN", "ROLE_COMPANY_OWNER", "ROLE_DEVELOPER",
+	"ROLE_EMPLOYER", "ROLE_EXPERT", "ROLE_STUDENT",
+}
+
+// ownedResolver умеет отношения relations; ресурс "mine" — в отношении
+// held к любому субъекту, "missing" не существует, остальные чужие.
+func ownedResolver(relations []string, held ...string) Resolver {
+	return Resolver{
+		Relations: relations,
+		Resolve: func(_ context.Context, _ Subject, id string) ([]string, error) {
+			switch id {
+			case "mine":
+				return held, nil
+			case "missing":
+				return nil, ErrNotFound
+			}
+			return nil, nil
+		},
+	}
+}
+
+func testEngine(t *testing.T, superusers []string) *Engine {
+	t.Helper()
+	f := File{
+		Superusers: superusers,
+		Actions: []Action{
+			{Name: "profile.read", Allow: []Rule{{Roles: []string{AnyRole}}}},
+			{Name: "vacancy.list", Scope: "vacancies:read", Allow: []Rule{{Roles: []string{"ROLE_STUDENT", "ROLE_EMPLOYER"}}}},
+			{Name: "vacancy.update", Resource: "vacancy", Scope: "vacancies:write", Allow: []Rule{
+				{Roles: []string{"ROLE_COMPANY_OWNER"}, Relation: RelationOwner},
+			}},
+			{Name: "vacancy.attachment", Resource: "vacancy", Allow: []Rule{
+				{Roles: []string{"ROLE_COMPANY_OWNER"}, Relation: RelationOwner},
+				{Roles: []string{"ROLE_EMPLOYER"}, Relation: RelationMember},
+			}},
+			{Name: "admin.user.ban", Strict: true, Allow: []Rule{{Roles: []string{"ROLE_ADMIN"}}}},
+			{Name: "account.unlock", Allow: nil},
+		},
+	}
+	e, err := New(f, testRoles, map[string]Resolver{
+		"vacancy": ownedResolver([]string{RelationOwner, RelationMember}, RelationOwner),
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return e
+}
+
+func TestAuthorize(t *testing.T) {
+	owner := Subject{UserID: "u1", Role: "ROLE_COMPANY_OWNER"}
+	student := Subject{UserID: "u2", Role: "ROLE_STUDENT"}
+	hr := Subject{UserID: "u3", Role: "ROLE_EMPLOYER"}
+	developer := Subject{UserID: "u4", Role: "ROLE_DEVELOPER"}
+	admin := Subject{UserID: "u5", Role: "ROLE_ADMIN"}
+	key := func(s Subject, scopes ...string) Subject {
+		s.APIKey, s.Scopes = true, scopes
+		return s
+	}
+
+	tests := []struct {
+		name     string
+		subject  Subject
+		action   string
+		resource Resource
+		want     error
+	}{
+		{"unknown action", student, "vacancy.unknown", Resource{}, ErrDenied},
+		{"no user", Subject{Role: "ROLE_STUDENT"}, "profile.read", Resource{}, ErrUnauthenticated},
+		{"no role", Subject{UserID: "u2"}, "profile.read", Resource{}, ErrUnauthenticated},
+		{"any role", student, "profile.read", Resource{}, nil},
+		{"listed role", hr, "vacancy.list", Resource{}, nil},
+		{"unlisted role", owner, "vacancy.list", Resource{}, ErrDenied},
+		{"owner", owner, "vacancy.update", Resource{ID: "mine"}, nil},
+		{"not owner", owner, "vacancy.update", Resource{ID: "other"}, ErrDenied},
+		{"no resource id", owner, "vacancy.update", Resource{}, ErrDenied},
+		{"missing resource", owner, "vacancy.update", Resource{ID: "missing"}, ErrNotFound},
+		{"relation of other role", student, "vacancy.update", Resource{ID: "mine"}, ErrDenied},
+		// Правило member есть, но резолвер вакансии отдаёт только owner.
+		{"relation not held", hr, "vacancy.attachment", Resource{ID: "mine"}, ErrDenied},
+		{"api key with scope", key(owner, "vacancies:write"), "vacancy.update", Resource{ID: "mine"}, nil},
+		{"api key without scope", key(owner, "vacancies:read"), "vacancy.update", Resource{ID: "mine"}, ErrDenied},
+		{"api key on closed action", key(student, "vacancies:read"), "profile.read", Resource{}, ErrDenied},
+		{"empty allow", owner, "account.unlock", Resource{}, ErrDenied},
+		{"strict admin", admin, "admin.user.ban", Resource{}, nil},
+		{"strict other role", owner, "admin.user.ban", Resource{}, ErrDenied},
+		// Суперпользователи не заданы: роль разработчика ничего не обходит.
+		{"developer", developer, "vacancy.update", Resource{ID: "mine"}, ErrDenied},
+		{"developer strict", developer, "admin.user.ban", Resource{}, ErrDenied},
+	}
+
+	e := testEngine(t, nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := e.Authorize(context.Background(), tt.subject, tt.action, tt.resource)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Authorize(%+v, %s, %+v) = %v, want %v", tt.subject, tt.action, tt.resource, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthorizeSuperusers(t *testing.T) {
+	e := testEngine(t, []string{"ROLE_EXPERT"})
+	expert := Subject{UserID: "u6", Role: "ROLE_EXPERT"}
+
+	tests := []struct {
+		name    string
+		subject Subject
+		action  string
+		want    error
+	}{
+		{"bypasses rules", expert, "vacancy.list", nil},
+		{"bypasses relation", expert, "vacancy.update", nil},
+		{"bypasses empty allow", expert, "account.unlock", nil},
+		{"strict not bypassed", expert, "admin.user.ban", ErrDenied},
+		{"api key still limited", Subject{UserID: "u6", Role: "ROLE_EXPERT", APIKey: true}, "vacancy.update", ErrDenied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := e.Authorize(context.Background(), tt.subject, tt.action, Resource{ID: "other"})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Authorize(%+v, %s) = %v, want %v", tt.subject, tt.action, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRejectsInvalidPolicy(t *testing.T) {
+	tests := []struct {
+		name string
+		file File
+	}{
+		{"unknown superuser", File{Superusers: []string{"ROLE_ROOT"}}},
+		{"unknown role", File{Actions: []Action{{Name: "a", Allow: []Rule{{Roles: []string{"ROLE_ROOT"}}}}}}},
+		{"rule without roles", File{Actions: []Action{{Name: "a", Allow: []Rule{{}}}}}},
+		{"duplicate action", File{Actions: []Action{{Name: "a"}, {Name: "a"}}}},
+		{"public action", File{Actions: []Action{{Name: Public}}}},
+		{"no resolver", File{Actions: []Action{{Name: "a", Resource: "thread", Allow: []Rule{
+			{Roles: []string{AnyRole}, Relation: RelationParticipant},
+		}}}}},
+		{"unknown relation", File{Actions: []Action{{Name: "a", Resource: "vacancy", Allow: []Rule{
+			{Roles: []string{AnyRole}, Relation: RelationAssignee},
+		}}}}},
+	}
+	resolvers := map[string]Resolver{"vacancy": ownedResolver([]string{RelationOwner})}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.file, testRoles, resolvers); err == nil {
+				t.Fatal("New accepted an invalid policy")
+			}
+		})
+	}
+}
+
+// Заглушки сервисов для настоящих резолверов: вызов метода, которого нет в
+// заглушке, паникует на nil-интерфейсе — резолвер ходит куда не должен.
+type stubVacancies struct {
+	services.VacancyService
+	vacancies map[string]*models.Vacancy
+}
+
+func (s stubVacancies) GetVacancy(_ context.Context, id string) (*models.Vacancy, error) {
+	return s.vacancies[id], nil
+}
+
+type stubCompanies struct {
+	services.CompanyService
+	memberships map[string]*models.CompanyMember
+}
+
+func (s stubCompanies) GetMembershipByUser(_ context.Context, userID string) (*models.CompanyMember, error) {
+	if ms, ok := s.memberships[userID]; ok {
+		return ms, nil
+	}
+	return nil, errors.New("membership not found")
+}
+
+// Владение вакансией решает компания вакансии, а не совпадение id вакансии
+// с id пользователя: раньше OwnerOrRoleMiddleware(ID, ...) сравнивал
+// параметр :id маршрута вакансии с user_id.
+func TestVacancyOwnershipByCompany(t *testing.T) {
+	const (
+		companyID   = "company-1"
+		otherID     = "company-2"
+		vacancyID   = "vacancy-1"
+		hrID        = "hr-1"
+		pendingHRID = "hr-2"
+		foreignHRID = "hr-3"
+	)
+	api := &services.ApiGateway{
+		Vacancy: stubVacancies{vacancies: map[string]*models.Vacancy{
+			vacancyID: {ID: vacancyID, CompanyID: companyID},
+		}},
+		Company: stubCompanies{memberships: map[string]*models.CompanyMember{
+			hrID:        {UserID: hrID, CompanyID: companyID, Status: membershipApproved},
+			pendingHRID: {UserID: pendingHRID, CompanyID: companyID, Status: 1},
+			foreignHRID: {UserID: foreignHRID, CompanyID: otherID, Status: membershipApproved},
+			// Пользователь, чей id совпал с id вакансии, — HR другой компании.
+			vacancyID: {UserID: vacancyID, CompanyID: otherID, Status: membershipApproved},
+		}},
+	}
+	e, err := Load("../../configs/policy.yaml", testRoles, NewResolvers(api))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		subject Subject
+		action  string
+		id      string
+		want    error
+	}{
+		{"company owner updates", Subject{UserID: companyID, Role: "ROLE_COMPANY_OWNER"}, "vacancy.update", vacancyID, nil},
+		{"other company owner", Subject{UserID: otherID, Role: "ROLE_COMPANY_OWNER"}, "vacancy.update", vacancyID, ErrDenied},
+		{"owner with vacancy id", Subject{UserID: vacancyID, Role: "ROLE_COMPANY_OWNER"}, "vacancy.update", vacancyID, ErrDenied},
+		{"owner with vacancy id deletes", Subject{UserID: vacancyID, Role: "ROLE_COMPANY_OWNER"}, "vacancy.delete", vacancyID, ErrDenied},
+		{"hr with vacancy id", Subject{UserID: vacancyID, Role: "ROLE_EMPLOYER"}, "vacancy.attachment", vacancyID, ErrDenied},
+		{"student with vacancy id", Subject{UserID: vacancyID, Role: "ROLE_STUDENT"}, "vacancy.update", vacancyID, ErrDenied},
+		{"approved hr", Subject{UserID: hrID, Role: "ROLE_EMPLOYER"}, "vacancy.attachment", vacancyID, nil},
+		{"approved hr cannot update", Subject{UserID: hrID, Role: "ROLE_EMPLOYER"}, "vacancy.update", vacancyID, ErrDenied},
+		{"pending hr", Subject{UserID: pendingHRID, Role: "ROLE_EMPLOYER"}, "vacancy.attachment", vacancyID, ErrDenied},
+		{"hr of other company", Subject{UserID: foreignHRID, Role: "ROLE_EMPLOYER"}, "vacancy.attachment", vacancyID, ErrDenied},
+		{"missing vacancy", Subject{UserID: companyID, Role: "ROLE_COMPANY_OWNER"}, "vacancy.update", "vacancy-404", ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := e.Authorize(context.Background(), tt.subject, tt.action, Resource{ID: tt.id})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Authorize(%+v, %s, %s) = %v, want %v", tt.subject, tt.action, tt.id, err, tt.want)
+			}
+		})
+	}
+}
+
+type stubApplications struct {
+	services.ApplicationService
+	applications map[string]*models.Application
+}
+
+func (s stubApplications) Available() bool { return true }
+
+func (s stubApplications) Get(_ context.Context, id string) (*models.Application, error) {
+	return s.applications[id], nil
+}
+
+// Тред отклика видят автор и компания вакансии. Раньше участником считался
+// любой не-студент — и HR чужой компании читал переписку по отклику.
+func TestApplicationThreadParticipants(t *testing.T) {
+	const (
+		companyID   = "company-1"
+		otherID     = "company-2"
+		vacancyID   = "vacancy-1"
+		appID       = "application-1"
+		studentID   = "student-1"
+		hrID        = "hr-1"
+		foreignHRID = "hr-3"
+	)
+	api := &services.ApiGateway{
+		Application: stubApplications{applications: map[string]*models.Application{
+			appID: {ID: appID, VacancyID: vacancyID, StudentID: studentID},
+		}},
+		Vacancy: stubVacancies{vacancies: map[string]*models.Vacancy{
+			vacancyID: {ID: vacancyID, CompanyID: companyID},
+		}},
+		Company: stubCompanies{memberships: map[string]*models.CompanyMember{
+			hrID:        {UserID: hrID, CompanyID: companyID, Status: membershipApproved},
+			foreignHRID: {UserID: foreignHRID, CompanyID: otherID, Status: membershipApproved},
+		}},
+	}
+	e, err := Load("../../configs/policy.yaml", testRoles, NewResolvers(api))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		subject Subject
+		thread  string
+		want    error
+	}{
+		{"author", Subject{UserID: studentID, Role: "ROLE_STUDENT"}, "application:" + appID, nil},
+		{"other student", Subject{UserID: "student-2", Role: "ROLE_STUDENT"}, "application:" + appID, ErrDenied},
+		{"company owner", Subject{UserID: companyID, Role: "ROLE_COMPANY_OWNER"}, "application:" + appID, nil},
+		{"company hr", Subject{UserID: hrID, Role: "ROLE_EMPLOYER"}, "application:" + appID, nil},
+		{"hr of other company", Subject{UserID: foreignHRID, Role: "ROLE_EMPLOYER"}, "application:" + appID, ErrDenied},
+		{"owner of other company", Subject{UserID: otherID, Role: "ROLE_COMPANY_OWNER"}, "application:" + appID, ErrDenied},
+		{"expert", Subject{UserID: "expert-1", Role: "ROLE_EXPERT"}, "application:" + appID, ErrDenied},
+		{"missing application", Subject{UserID: companyID, Role: "ROLE_COMPANY_OWNER"}, "application:application-404", ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, action := range []string{"chat.read", "chat.send"} {
+				err := e.Authorize(context.Background(), tt.subject, action, Resource{ID: tt.thread})
+				if !errors.Is(err, tt.want) {
+					t.Fatalf("Authorize(%+v, %s, %s) = %v, want %v", tt.subject, action, tt.thread, err, tt.want)
+				}
+			}
+		})
+	}
+}
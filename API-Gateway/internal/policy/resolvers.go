@@ -0,0 +1,218 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	companyv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/company/v1"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/services"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Отношения субъекта к ресурсу, которые вычисляют резолверы.
+const (
+	// RelationOwner — ресурс принадлежит компании субъекта (company_id ==
+	// user_id владельца; у HR задачи создаются под его user_id).
+	RelationOwner = "owner"
+	// RelationMember — субъект — подтверждённый HR компании ресурса.
+	RelationMember = "member"
+	// RelationAuthor — субъект создал ресурс (отклик студента).
+	RelationAuthor = "author"
+	// RelationAssignee — задача назначена субъекту.
+	RelationAssignee = "assignee"
+	// RelationParticipant — субъект участвует в треде чата.
+	RelationParticipant = "participant"
+)
+
+const (
+	roleEmployer = "ROLE_EMPLOYER"
+
+	membershipApproved = int32(companyv1.MembershipStatus_MEMBERSHIP_STATUS_APPROVED)
+)
+
+// NewResolvers — резолверы отношений по типам ресурсов Gateway. Ресурсы
+// читаются из сервисов; ошибки NotFound/InvalidArgument дают ErrNotFound.
+func NewResolvers(api *services.ApiGateway) map[string]Resolver {
+	r := &resolvers{api: api}
+	return map[string]Resolver{
+		"company": {
+			Relations: []string{RelationOwner, RelationMember},
+			Resolve:   r.company,
+		},
+		"vacancy": {
+			Relations: []string{RelationOwner, RelationMember},
+			Resolve:   r.vacancy,
+		},
+		"application": {
+			Relations: []string{RelationAuthor, RelationOwner, RelationMember},
+			Resolve:   r.application,
+		},
+		"task": {
+			Relations: []string{RelationOwner, RelationAssignee},
+			Resolve:   r.task,
+		},
+		"membership": {
+			Relations: []string{RelationOwner},
+			Resolve:   r.membership,
+		},
+		"thread": {
+			Relations: []string{RelationParticipant},
+			Resolve:   r.thread,
+		},
+	}
+}
+
+type resolvers struct {
+	api *services.ApiGateway
+}
+
+// company: owner — это компания субъекта; member — субъект её
+// подтверждённый HR.
+func (r *resolvers) company(ctx context.Context, subject Subject, companyID string) ([]string, error) {
+	var relations []string
+	if companyID == subject.UserID {
+		relations = append(relations, RelationOwner)
+	}
+	if subject.Role == roleEmployer {
+		ms, err := r.api.Company.GetMembershipByUser(ctx, subject.UserID)
+		if err == nil && ms != nil && ms.Status == membershipApproved && ms.CompanyID == companyID {
+			relations = append(relations, RelationMember)
+		}
+	}
+	return relations, nil
+}
+
+// vacancy: отношения к компании, под которой опубликована вакансия.
+func (r *resolvers) vacancy(ctx context.Context, subject Subject, id string) ([]string, error) {
+	v, err := r.api.Vacancy.GetVacancy(ctx, id)
+	if err := notFound(v == nil, err); err != nil {
+		return nil, err
+	}
+	return r.company(ctx, subject, v.CompanyID)
+}
+
+// application: author — студент отклика; owner/member — по вакансии отклика.
+func (r *resolvers) application(ctx context.Context, subject Subject, id string) ([]string, error) {
+	if !r.api.Application.Available() {
+		return nil, ErrUnavailable
+	}
+	app, err := r.api.Application.Get(ctx, id)
+	if err := notFound(app == nil, err); err != nil {
+		return nil, err
+	}
+	if app.StudentID == subject.UserID {
+		return []string{RelationAuthor}, nil
+	}
+	return r.vacancy(ctx, subject, app.VacancyID)
+}
+
+// task: owner — задача компании субъекта (или квест эксперта); assignee —
+// задача назначена субъекту или это его квест.
+func (r *resolvers) task(ctx context.Context, subject Subject, id string) ([]string, error) {
+	if !r.api.MicroTasks.Available() {
+		return nil, ErrUnavailable
+	}
+	t, err := r.api.MicroTasks.Get(ctx, id)
+	if err := notFound(t == nil, err); err != nil {
+		return nil, err
+	}
+	var relations []string
+	if t.CompanyID == subject.UserID {
+		relations = append(relations, RelationOwner)
+	}
+	if t.AssignedTo == subject.UserID || (t.IsSkillQuest && t.TargetStudentID == subject.UserID) {
+		relations = append(relations, RelationAssignee)
+	}
+	return relations, nil
+}
+
+// membership: owner — заявка HR подана в компанию субъекта.
+func (r *resolvers) membership(ctx context.Context, subject Subject, id string) ([]string, error) {
+	members, err := r.api.Company.ListMembers(ctx, subject.UserID, 0)
+	if err != nil {
+		return nil, fmt.Errorf("list members of %s: %w", subject.UserID, err)
+	}
+	for _, m := range members {
+		if m.ID == id {
+			return []string{RelationOwner}, nil
+		}
+	}
+	return nil, nil
+}
+
+// thread: id — "<kind>:<rid>", как в сервисе чата.
+//   - direct/<idA>_<idB> — участники пары;
+//   - application/<id> — студент-автор отклика; остальные роли пускаем без
+//     проверки membership (MVP: строгая проверка мешала HR отвечать);
+//   - task/<id> — исполнитель и компания задачи;
+//   - quest/<id> — студент квеста и эксперт-создатель.
+func (r *resolvers) thread(ctx context.Context, subject Subject, id string) ([]string, error) {
+	kind, rid, ok := strings.Cut(id, ":")
+	if !ok || rid == "" {
+		return nil, ErrNotFound
+	}
+	participant := []string{RelationParticipant}
+
+	switch kind {
+	case "direct":
+		a, b, ok := strings.Cut(rid, "_")
+		if !ok || a == "" || b == "" || strings.Contains(b, "_") {
+			return nil, ErrNotFound
+		}
+		if a == subject.UserID || b == subject.UserID {
+			return participant, nil
+		}
+		return nil, nil
+	case "application":
+		// Участники треда отклика — автор и компания вакансии: владелец и её
+		// подтверждённые HR. HR или владелец чужой компании — нет.
+		relations, err := r.application(ctx, subject, rid)
+		if err != nil {
+			return nil, err
+		}
+		if len(relations) > 0 {
+			return participant, nil
+		}
+		return nil, nil
+	case "task", "quest":
+		if !r.api.MicroTasks.Available() {
+			return nil, ErrUnavailable
+		}
+		t, err := r.api.MicroTasks.Get(ctx, rid)
+		if err := notFound(t == nil, err); err != nil {
+			return nil, err
+		}
+		if kind == "quest" {
+			if !t.IsSkillQuest {
+				return nil, ErrNotFound
+			}
+			if t.TargetStudentID == subject.UserID || t.CompanyID == subject.UserID {
+				return participant, nil
+			}
+			return nil, nil
+		}
+		if t.AssignedTo == subject.UserID || t.CompanyID == subject.UserID {
+			return participant, nil
+		}
+		return nil, nil
+	default:
+		return nil, ErrNotFound
+	}
+}
+
+// notFound сводит «ресурса нет» к ErrNotFound; прочие ошибки сервиса
+// возвращаются как есть.
+func notFound(missing bool, err error) error {
+	if err != nil {
+		if st, ok := status.FromError(err); ok && (st.Code() == codes.NotFound || st.Code() == codes.InvalidArgument) {
+			return ErrNotFound
+		}
+		return err
+	}
+	if missing {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// MemoryLimiter — token-bucket в памяти процесса (golang.org/x/time/rate).
+// Счётчики не разделяются между инстансами: это fallback на время
+// недоступности Redis и режим для локального запуска без него.
+// sweep раз в 5 мин чистит мапу от мёртвых ключей, чтобы не текла память.
+type MemoryLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	limiter *rate.Limiter
+	seenAt  time.Time
+}
+
+func NewMemoryLimiter() *MemoryLimiter {
+	l := &MemoryLimiter{buckets: make(map[string]*bucket)}
+	go l.sweep()
+	return l
+}
+
+func (l *MemoryLimiter) Allow(_ context.Context, key string, limit Limit) (Result, error) {
+	every := rate.Every(limit.interval())
+	now := time.Now()
+	lim := l.bucketFor(key, every, limit.Burst, now)
+
+	allowed := lim.AllowN(now, 1)
+	tokens := lim.TokensAt(now)
+	res := Result{
+		Allowed:    allowed,
+		Remaining:  max(int(tokens), 0),
+		ResetAfter: tokenWait(float64(limit.Burst)-tokens, every),
+	}
+	if !allowed {
+		res.RetryAfter = tokenWait(1-tokens, every)
+	}
+	return res, nil
+}
+
+// bucketFor — bucket ключа; лимит политики мог смениться (другая роль) —
+// тогда bucket подстраивается под новый.
+func (l *MemoryLimiter) bucketFor(key string, every rate.Limit, burst int, now time.Time) *rate.Limiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{limiter: rate.NewLimiter(every, burst)}
+		l.buckets[key] = b
+	} else if b.limiter.Limit() != every || b.limiter.Burst() != burst {
+		b.limiter.SetLimitAt(now, every)
+		b.limiter.SetBurstAt(now, burst)
+	}
+	b.seenAt = now
+	return b.limiter
+}
+
+// sweep периодически чистит buckets неактивных ключей.
+func (l *MemoryLimiter) sweep() {
+	ticker := time.NewTicker(5 * time.Minute)
+	defer ticker.Stop()
+	for range ticker.C {
+		cutoff := time.Now().Add(-10 * time.Minute)
+		l.mu.Lock()
+		for k, b := range l.buckets {
+			if b.seenAt.Before(cutoff) {
+				delete(l.buckets, k)
+			}
+		}
+		l.mu.Unlock()
+	}
+}
+
+func tokenWait(tokens float64, every rate.Limit) time.Duration {
+	if tokens <= 0 || every <= 0 {
+		return 0
+	}
+	return time.Duration(tokens / float64(every) * float64(time.Second))
+}
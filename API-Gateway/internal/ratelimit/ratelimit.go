@@ -0,0 +1,270 @@
+// Package ratelimit ограничивает частоту запросов к Gateway.
+//
+// Limiter — хранилище лимитов: RedisLimiter (GCRA атомарно в Lua, общий для
+// всех инстансов) и MemoryLimiter (token-bucket в памяти процесса). Fallback
+// переключается на второй, пока Redis недоступен: лимиты на это время снова
+// per-instance, но Gateway не перестаёт их применять.
+//
+// Policies — какой лимит применить к запросу: по методу и маршруту
+// (configs/ratelimit.yaml) и роли из токена. Запросы, не попавшие ни в одну
+// политику, лимитируются политикой по умолчанию. Кроме того, все запросы с
+// одного IP ещё до проверки токена проходят через общий потолок — политику ip.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/metrics"
+)
+
+// Limit — rate запросов за period со всплеском до burst подряд.
+type Limit struct {
+	Rate   int           `mapstructure:"rate"`
+	Period time.Duration `mapstructure:"period"`
+	Burst  int           `mapstructure:"burst"`
+}
+
+// interval — через сколько восстанавливается один запрос.
+func (l Limit) interval() time.Duration {
+	return l.Period / time.Duration(l.Rate)
+}
+
+func (l Limit) valid() bool {
+	return l.Rate > 0 && l.Period > 0 && l.Burst > 0 && l.interval() > 0
+}
+
+// Result — решение по запросу и данные для заголовков RateLimit-*.
+type Result struct {
+	Allowed bool
+	// Remaining — сколько запросов ещё можно сделать подряд.
+	Remaining int
+	// RetryAfter — когда повторить отклонённый запрос (0, если разрешён).
+	RetryAfter time.Duration
+	// ResetAfter — когда лимит восстановится полностью.
+	ResetAfter time.Duration
+}
+
+// Limiter списывает один запрос с лимита ключа key.
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// Fallback — primary (Redis), а при его ошибке — secondary (память).
+// Отказ primary пишется в лог не чаще раза в fallbackLogInterval.
+type Fallback struct {
+	primary   Limiter
+	secondary Limiter
+	loggedAt  atomic.Int64
+}
+
+const fallbackLogInterval = time.Minute
+
+func NewFallback(primary, secondary Limiter) *Fallback {
+	return &Fallback{primary: primary, secondary: secondary}
+}
+
+func (f *Fallback) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	res, err := f.primary.Allow(ctx, key, limit)
+	if err == nil {
+		return res, nil
+	}
+
+	metrics.RateLimitFallback.Inc()
+	now := time.Now().UnixNano()
+	if last := f.loggedAt.Load(); now-last > int64(fallbackLogInterval) && f.loggedAt.CompareAndSwap(last, now) {
+		log.Printf("rate limiter: primary store failed (%v), using in-memory limits", err)
+	}
+	return f.secondary.Allow(ctx, key, limit)
+}
+
+// Key — чей лимит расходует запрос.
+const (
+	// KeyUser — пользователя из токена, без токена — IP.
+	KeyUser = "user"
+	// KeyIP — всегда IP: для входа и прочих маршрутов без токена, где
+	// перебор идёт с одного адреса по многим аккаунтам.
+	KeyIP = "ip"
+)
+
+// Policy — лимит для группы маршрутов. Paths — шаблоны вида
+// /api/v1/vacancy/:id/respond (":..." — любой сегмент, "*" в конце — любой
+// хвост); Methods пустой — любой метод. Roles переопределяет Limit для
+// ролей из токена.
+type Policy struct {
+	Name    string           `mapstructure:"name"`
+	Methods []string         `mapstructure:"methods"`
+	Paths   []string         `mapstructure:"paths"`
+	Key     string           `mapstructure:"key"`
+	Limit   Limit            `mapstructure:"limit"`
+	Roles   map[string]Limit `mapstructure:"roles"`
+}
+
+// LimitFor — лимит для роли role (пустая — запрос без токена).
+func (p *Policy) LimitFor(role string) Limit {
+	if l, ok := p.Roles[role]; ok {
+		return l
+	}
+	return p.Limit
+}
+
+func (p *Policy) matches(method, path string) bool {
+	if len(p.Methods) > 0 && !containsFold(p.Methods, method) {
+		return false
+	}
+	for _, pattern := range p.Paths {
+		if matchPath(pattern, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// File — содержимое configs/ratelimit.yaml.
+type File struct {
+	Default *Policy `mapstructure:"default"`
+	// IP — потолок на адрес клиента до проверки токена.
+	IP       *Policy  `mapstructure:"ip"`
+	Policies []Policy `mapstructure:"policies"`
+}
+
+// Policies — политики в порядке файла: запрос получает первую подходящую.
+type Policies struct {
+	list []*Policy
+	def  *Policy
+	ip   *Policy
+}
+
+// Load читает политики из YAML-файла.
+func Load(path string, def Limit) (*Policies, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("ratelimit: read %s: %w", path, err)
+	}
+	var f File
+	if err := v.Unmarshal(&f); err != nil {
+		return nil, fmt.Errorf("ratelimit: parse %s: %w", path, err)
+	}
+	return NewPolicies(f, def)
+}
+
+// NewPolicies проверяет политики. def — лимит по умолчанию, если в файле нет
+// секции default; он же — потолок на IP, если нет секции ip.
+func NewPolicies(f File, def Limit) (*Policies, error) {
+	p := &Policies{def: f.Default, ip: f.IP}
+	if p.def == nil {
+		p.def = &Policy{Limit: def}
+	}
+	p.def.Name = "default"
+	p.def.Paths, p.def.Methods = nil, nil
+	if p.ip == nil {
+		p.ip = &Policy{Limit: def}
+	}
+	// Роль до проверки токена неизвестна — потолок один на всех.
+	p.ip.Name, p.ip.Key = "ip", KeyIP
+	p.ip.Paths, p.ip.Methods, p.ip.Roles = nil, nil, nil
+
+	var errs []string
+	seen := make(map[string]bool)
+	check := func(pol *Policy) {
+		if pol.Key == "" {
+			pol.Key = KeyUser
+		}
+		if pol.Key != KeyUser && pol.Key != KeyIP {
+			errs = append(errs, fmt.Sprintf("%s: key must be %q or %q", pol.Name, KeyUser, KeyIP))
+		}
+		if !pol.Limit.valid() {
+			errs = append(errs, fmt.Sprintf("%s: rate, period and burst must be positive", pol.Name))
+		}
+		// viper приводит ключи словарей к нижнему регистру, роли — в верхнем.
+		roles := make(map[string]Limit, len(pol.Roles))
+		for role, l := range pol.Roles {
+			if !l.valid() {
+				errs = append(errs, fmt.Sprintf("%s: %s: rate, period and burst must be positive", pol.Name, role))
+			}
+			roles[strings.ToUpper(role)] = l
+		}
+		pol.Roles = roles
+	}
+
+	check(p.def)
+	check(p.ip)
+	for i := range f.Policies {
+		pol := &f.Policies[i]
+		if pol.Name == "" || pol.Name == p.def.Name || pol.Name == p.ip.Name || seen[pol.Name] {
+			errs = append(errs, fmt.Sprintf("policies[%d]: name %q is empty or duplicate", i, pol.Name))
+		}
+		seen[pol.Name] = true
+		if len(pol.Paths) == 0 {
+			errs = append(errs, fmt.Sprintf("%s: paths are required", pol.Name))
+		}
+		check(pol)
+		p.list = append(p.list, pol)
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("ratelimit: %s", strings.Join(errs, "; "))
+	}
+	return p, nil
+}
+
+// Match — политика для запроса.
+func (p *Policies) Match(method, path string) *Policy {
+	for _, pol := range p.list {
+		if pol.matches(method, path) {
+			return pol
+		}
+	}
+	return p.def
+}
+
+// IP — потолок на адрес клиента, общий для всех маршрутов.
+func (p *Policies) IP() *Policy {
+	return p.ip
+}
+
+// Names — имена политик (для лога при старте).
+func (p *Policies) Names() []string {
+	names := make([]string, 0, len(p.list)+2)
+	for _, pol := range p.list {
+		names = append(names, pol.Name)
+	}
+	return append(names, p.def.Name, p.ip.Name)
+}
+
+func matchPath(pattern, path string) bool {
+	pattern, path = strings.TrimSuffix(pattern, "/"), strings.TrimSuffix(path, "/")
+	want, got := strings.Split(pattern, "/"), strings.Split(path, "/")
+	for i, seg := range want {
+		if seg == "*" && i == len(want)-1 {
+			return len(got) >= i
+		}
+		if i >= len(got) {
+			return false
+		}
+		if strings.HasPrefix(seg, ":") {
+			if got[i] == "" {
+				return false
+			}
+			continue
+		}
+		if seg != got[i] {
+			return false
+		}
+	}
+	return len(got) == len(want)
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
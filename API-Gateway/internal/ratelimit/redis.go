@@ -0,0 +1,63 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const keyPrefix = "gw:rl:"
+
+// gcraScript — GCRA (generic cell rate algorithm): в ключе хранится TAT —
+// момент, когда лимит полностью восстановится. Запрос разрешён, если после
+// него TAT уходит в будущее не дальше, чем на burst интервалов. Время берётся
+// из Redis (TIME), чтобы расхождение часов инстансов не влияло на лимит.
+//
+// ARGV: интервал восстановления одного запроса (мкс), burst.
+// Ответ: {разрешён (0/1), осталось запросов, retry_after (мкс), reset_after (мкс)}.
+var gcraScript = redis.NewScript(`
+local interval = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+local tolerance = interval * burst
+
+local tat = tonumber(redis.call('GET', KEYS[1]))
+if not tat or tat < now then
+  tat = now
+end
+
+local new_tat = tat + interval
+local diff = now - (new_tat - tolerance)
+if diff < 0 then
+  return {0, 0, -diff, tat - now}
+end
+
+-- %.0f: число по умолчанию пишется с 14 значащими цифрами, а тут 16.
+redis.call('SET', KEYS[1], string.format('%.0f', new_tat), 'PX', math.ceil((new_tat - now) / 1000))
+return {1, math.floor(diff / interval), 0, new_tat - now}
+`)
+
+// RedisLimiter — лимиты, общие для всех инстансов Gateway.
+type RedisLimiter struct {
+	rdb *redis.Client
+}
+
+func NewRedisLimiter(rdb *redis.Client) *RedisLimiter {
+	return &RedisLimiter{rdb: rdb}
+}
+
+func (l *RedisLimiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	out, err := gcraScript.Run(ctx, l.rdb, []string{keyPrefix + key},
+		limit.interval().Microseconds(), limit.Burst).Int64Slice()
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{
+		Allowed:    out[0] == 1,
+		Remaining:  int(out[1]),
+		RetryAfter: time.Duration(out[2]) * time.Microsecond,
+		ResetAfter: time.Duration(out[3]) * time.Microsecond,
+	}, nil
+}
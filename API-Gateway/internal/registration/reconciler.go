@@ -0,0 +1,188 @@
+package registration
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/studjobs/hh_for_students/api-gateway/internal/saga"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/services"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// staleAfter — сага без обновлений дольше этого считается брошенной
+	// (Gateway упал посреди регистрации).
+	staleAfter = 10 * time.Minute
+	// accountGrace — свежие аккаунты сверка не трогает: их регистрация,
+	// скорее всего, ещё идёт.
+	accountGrace = 10 * time.Minute
+	pageSize     = 200
+)
+
+// Reconciler периодически доводит регистрации до согласованного состояния:
+//  1. продолжает брошенные саги (или повторяет их откат);
+//  2. обходит аккаунты Auth за последние lookback и создаёт недостающие
+//     профили и компании — это ловит и регистрации, запись саги которых
+//     потеряна.
+type Reconciler struct {
+	service  *Service
+	api      *services.ApiGateway
+	interval time.Duration
+	lookback time.Duration
+}
+
+func NewReconciler(service *Service, api *services.ApiGateway, interval, lookback time.Duration) *Reconciler {
+	return &Reconciler{
+		service:  service,
+		api:      api,
+		interval: interval,
+		lookback: lookback,
+	}
+}
+
+// Run — блокирующий цикл; завершается, когда ctx отменён.
+func (r *Reconciler) Run(ctx context.Context) {
+	log.Printf("registration: reconciler started (interval=%v, lookback=%v)", r.interval, r.lookback)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		r.reconcile(ctx)
+
+		select {
+		case <-ctx.Done():
+			log.Printf("registration: reconciler stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Reconciler) reconcile(ctx context.Context) {
+	resumed := r.resumeStale(ctx)
+	fixed := r.checkAccounts(ctx)
+	if resumed > 0 || fixed > 0 {
+		log.Printf("registration: reconciled %d stale sagas, %d accounts", resumed, fixed)
+	}
+}
+
+// resumeStale продолжает саги, брошенные на полпути.
+func (r *Reconciler) resumeStale(ctx context.Context) int {
+	stale, err := r.service.orchestrator.Store().ListStale(ctx, time.Now().Add(-staleAfter))
+	if err != nil {
+		log.Printf("registration: failed to list stale sagas: %v", err)
+		return 0
+	}
+
+	resumed := 0
+	for _, st := range stale {
+		if ctx.Err() != nil {
+			break
+		}
+		if st.Kind != sagaKind {
+			continue
+		}
+
+		steps := r.service.steps(nil)
+		switch st.Status {
+		case saga.StatusRunning:
+			log.Printf("registration: resuming saga %s for user %s", st.ID, st.Data[dataUserUUID])
+			err = r.service.run(ctx, st, steps)
+		default:
+			log.Printf("registration: retrying compensation of saga %s for user %s", st.ID, st.Data[dataUserUUID])
+			err = r.service.orchestrator.Compensate(ctx, st, steps)
+		}
+		if err != nil {
+			log.Printf("registration: saga %s: %v", st.ID, err)
+			continue
+		}
+		resumed++
+	}
+	return resumed
+}
+
+// checkAccounts создаёт недостающие профили и компании для аккаунтов,
+// зарегистрированных за lookback.
+func (r *Reconciler) checkAccounts(ctx context.Context) int {
+	createdAfter := time.Now().Add(-r.lookback)
+	cutoff := time.Now().Add(-accountGrace)
+
+	fixed := 0
+	pageToken := ""
+	for ctx.Err() == nil {
+		accounts, next, err := r.api.Auth.ListAccounts(ctx, createdAfter, pageToken, pageSize)
+		if err != nil {
+			log.Printf("registration: failed to list accounts: %v", err)
+			return fixed
+		}
+
+		for _, account := range accounts {
+			if account.CreatedAt.After(cutoff) {
+				// Аккаунты упорядочены по времени — дальше только свежие.
+				return fixed
+			}
+			if r.checkAccount(ctx, account) {
+				fixed++
+			}
+		}
+
+		if next == "" {
+			break
+		}
+		pageToken = next
+	}
+	return fixed
+}
+
+// checkAccount — true, если для аккаунта что-то пришлось создать.
+func (r *Reconciler) checkAccount(ctx context.Context, account services.Account) bool {
+	fixed := false
+
+	_, err := r.api.User.GetUser(ctx, account.UserUUID)
+	switch status.Code(err) {
+	case codes.OK:
+	case codes.NotFound:
+		if err := ensureProfile(ctx, r.api, account.UserUUID, account.Email, account.Role); err != nil {
+			log.Printf("registration: failed to create missing profile for %s: %v", account.UserUUID, err)
+		} else {
+			log.Printf("registration: created missing profile for %s", account.UserUUID)
+			fixed = true
+		}
+	default:
+		log.Printf("registration: failed to check profile of %s: %v", account.UserUUID, err)
+	}
+
+	if !hasRole(account, roleCompanyOwner) {
+		return fixed
+	}
+
+	_, err = r.api.Company.GetCompany(ctx, account.UserUUID)
+	switch status.Code(err) {
+	case codes.OK:
+	case codes.NotFound:
+		if _, err := ensureCompany(ctx, r.api, account.UserUUID); err != nil {
+			log.Printf("registration: failed to create missing company for %s: %v", account.UserUUID, err)
+		} else {
+			log.Printf("registration: created missing company for %s", account.UserUUID)
+			fixed = true
+		}
+	default:
+		log.Printf("registration: failed to check company of %s: %v", account.UserUUID, err)
+	}
+	return fixed
+}
+
+func hasRole(account services.Account, role string) bool {
+	if account.Role == role {
+		return true
+	}
+	for _, r := range account.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
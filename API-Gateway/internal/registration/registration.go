@@ -0,0 +1,323 @@
+// Package registration — регистрация аккаунта как сага над тремя
+// сервисами: аккаунт в Auth, заглушка компании в Company (для владельца
+// компании) и профиль в Users (для нового аккаунта). Каждый шаг
+// сохраняется (saga.Store), временные ошибки повторяются, а если шаг так и
+// не удался, созданное раньше удаляется.
+//
+// Повтор запроса с тем же Idempotency-Key не создаёт второй аккаунт:
+// завершённая регистрация отвечает входом с тем же паролем (токены в
+// хранилище не кладём), неудачная — той же ошибкой.
+package registration
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"time"
+
+	usersv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/users/v1"
+	"github.com/google/uuid"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/models"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/saga"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/services"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	sagaKind = "registration"
+
+	stepAccount = "auth_account"
+	stepCompany = "company"
+	stepProfile = "profile"
+
+	// Ключи saga.State.Data.
+	dataUserUUID       = "user_uuid"
+	dataEmail          = "email"
+	dataRole           = "role"
+	dataNewAccount     = "new_account"
+	dataCompanyCreated = "company_created"
+
+	roleCompanyOwner = "ROLE_COMPANY_OWNER"
+	// companyPlaceholderName — до прохождения onboarding owner ещё не задал
+	// название компании. Нейтральный плейсхолдер вместо буквального "new"
+	// (B7) — фронт рендерит его курсивом как «надо заполнить».
+	companyPlaceholderName = "Без названия"
+	profileStubAge         = 18
+)
+
+var (
+	ErrInProgress = errors.New("registration with this idempotency key is in progress")
+	ErrKeyReused  = errors.New("idempotency key was used for a different registration")
+)
+
+// errNoPassword — шаг аккаунта нельзя продолжить после перезапуска: пароль
+// не сохраняется. Аккаунт, если он успел создаться, достроит сверка.
+var errNoPassword = errors.New("account step cannot be resumed without password")
+
+type Service struct {
+	api          *services.ApiGateway
+	orchestrator *saga.Orchestrator
+}
+
+func New(api *services.ApiGateway, store saga.Store) *Service {
+	return &Service{
+		api: api,
+		orchestrator: saga.NewOrchestrator(store, saga.RetryPolicy{
+			Attempts:  3,
+			Backoff:   200 * time.Millisecond,
+			Retryable: retryable,
+		}),
+	}
+}
+
+// Register регистрирует аккаунт. idempotencyKey пустой — повтор запроса
+// не распознаётся.
+func (s *Service) Register(ctx context.Context, idempotencyKey, email, password, role string) (*models.AuthResponse, error) {
+	id := uuid.NewString()
+	if idempotencyKey != "" {
+		id = sagaID(idempotencyKey, email)
+	}
+
+	var resp *models.AuthResponse
+	steps := s.steps(func(ctx context.Context, st *saga.State) error {
+		r, err := s.api.Auth.Register(ctx, email, password, role)
+		if err != nil {
+			return err
+		}
+		resp = r
+		setAccount(st, r)
+		return nil
+	})
+
+	st := saga.NewState(id, sagaKind, steps)
+	st.Data[dataEmail] = email
+	st.Data[dataRole] = role
+
+	if err := s.orchestrator.Store().Create(ctx, st); err != nil {
+		if errors.Is(err, saga.ErrExists) {
+			return s.replay(ctx, id, email, password, role)
+		}
+		// Без записи саги регистрация всё равно идёт: аккаунт, брошенный
+		// на полпути, найдёт сверка по аккаунтам Auth.
+		log.Printf("registration: failed to persist saga for %s: %v", email, err)
+	}
+
+	if err := s.run(ctx, st, steps); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Provision достраивает профиль и компанию для аккаунта, уже созданного в
+// Auth (вход через внешнего провайдера).
+func (s *Service) Provision(ctx context.Context, resp *models.AuthResponse, email string) error {
+	steps := s.steps(nil)
+	st := saga.NewState(uuid.NewString(), sagaKind, steps)
+	st.Data[dataEmail] = email
+	setAccount(st, resp)
+	st.MarkDone(stepAccount)
+
+	if err := s.orchestrator.Store().Create(ctx, st); err != nil {
+		log.Printf("registration: failed to persist saga for %s: %v", email, err)
+	}
+	return s.run(ctx, st, steps)
+}
+
+func (s *Service) run(ctx context.Context, st *saga.State, steps []saga.Step) error {
+	err := s.orchestrator.Execute(ctx, st, steps)
+	if err == nil {
+		log.Printf("registration: completed for user %s (saga %s)", st.Data[dataUserUUID], st.ID)
+		return nil
+	}
+
+	var stepErr *saga.StepError
+	if !errors.As(err, &stepErr) {
+		return err
+	}
+
+	grpcStatus := status.Convert(stepErr.Err)
+	st.Failure = &saga.Failure{
+		Step:    stepErr.Step,
+		Code:    int(grpcStatus.Code()),
+		Message: grpcStatus.Message(),
+	}
+	if err := s.orchestrator.Store().Save(ctx, st); err != nil {
+		log.Printf("registration: failed to save saga %s: %v", st.ID, err)
+	}
+	return stepErr.Err
+}
+
+// replay — повтор запроса с тем же ключом идемпотентности.
+func (s *Service) replay(ctx context.Context, id, email, password, role string) (*models.AuthResponse, error) {
+	st, err := s.orchestrator.Store().Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if st.Data[dataRole] != role {
+		return nil, ErrKeyReused
+	}
+
+	switch st.Status {
+	case saga.StatusCompleted:
+		// Пароль проверит Auth: без него повтор не выдаст токены.
+		resp, err := s.api.Auth.Login(ctx, email, password, role)
+		if err != nil {
+			return nil, err
+		}
+		resp.NewAccount = st.Data[dataNewAccount] == "true"
+		log.Printf("registration: replayed completed saga %s for user %s", id, resp.UserUUID)
+		return resp, nil
+	case saga.StatusCompensated, saga.StatusFailed:
+		if st.Failure != nil {
+			return nil, status.Error(codes.Code(st.Failure.Code), st.Failure.Message)
+		}
+		return nil, status.Error(codes.Aborted, "registration failed")
+	default:
+		return nil, ErrInProgress
+	}
+}
+
+// steps — шаги регистрации. createAccount nil — аккаунт уже создан.
+func (s *Service) steps(createAccount func(context.Context, *saga.State) error) []saga.Step {
+	if createAccount == nil {
+		createAccount = func(context.Context, *saga.State) error { return errNoPassword }
+	}
+
+	return []saga.Step{
+		{
+			// Не Idempotent: повтор после потерянного ответа наткнулся бы на
+			// уже созданный аккаунт.
+			Name:       stepAccount,
+			Do:         createAccount,
+			Compensate: s.deleteAccount,
+		},
+		{
+			Name:       stepCompany,
+			Do:         s.createCompany,
+			Compensate: s.deleteCompany,
+			Idempotent: true,
+		},
+		{
+			Name:       stepProfile,
+			Do:         s.createProfile,
+			Compensate: s.deleteProfile,
+			Idempotent: true,
+		},
+	}
+}
+
+func setAccount(st *saga.State, resp *models.AuthResponse) {
+	st.Data[dataUserUUID] = resp.UserUUID
+	st.Data[dataRole] = resp.Role
+	if resp.NewAccount {
+		st.Data[dataNewAccount] = "true"
+	}
+}
+
+// deleteAccount — только аккаунт, созданный этой регистрацией. Если Auth
+// добавил роль существующему аккаунту, роль остаётся: снять её нечем.
+func (s *Service) deleteAccount(ctx context.Context, st *saga.State) error {
+	if st.Data[dataNewAccount] != "true" {
+		log.Printf("registration: role %s stays on existing account %s", st.Data[dataRole], st.Data[dataUserUUID])
+		return nil
+	}
+	return ignoreNotFound(s.api.Auth.DeleteUser(ctx, st.Data[dataUserUUID]))
+}
+
+func (s *Service) createCompany(ctx context.Context, st *saga.State) error {
+	if st.Data[dataRole] != roleCompanyOwner {
+		return nil
+	}
+	created, err := ensureCompany(ctx, s.api, st.Data[dataUserUUID])
+	if created {
+		st.Data[dataCompanyCreated] = "true"
+	}
+	return err
+}
+
+func (s *Service) deleteCompany(ctx context.Context, st *saga.State) error {
+	if st.Data[dataCompanyCreated] != "true" {
+		return nil
+	}
+	return ignoreNotFound(s.api.Company.DeleteCompany(ctx, st.Data[dataUserUUID]))
+}
+
+// createProfile — профиль нужен и владельцу компании: иначе /u/<owner_uuid>
+// отдаёт 404 (студент в чате кликает «Профиль кандидата» → пусто). У
+// существующего аккаунта профиль остался от прежней роли.
+func (s *Service) createProfile(ctx context.Context, st *saga.State) error {
+	if st.Data[dataNewAccount] != "true" {
+		return nil
+	}
+	err := ensureProfile(ctx, s.api, st.Data[dataUserUUID], st.Data[dataEmail], st.Data[dataRole])
+	if err != nil && st.Data[dataRole] == roleCompanyOwner && !retryable(err) {
+		// Не блокируем регистрацию — owner и без профиля работает, а
+		// недостающий профиль создаст сверка.
+		log.Printf("registration: profile-stub for owner %s failed: %v", st.Data[dataUserUUID], err)
+		return nil
+	}
+	return err
+}
+
+func (s *Service) deleteProfile(ctx context.Context, st *saga.State) error {
+	if st.Data[dataNewAccount] != "true" {
+		return nil
+	}
+	return ignoreNotFound(s.api.User.DeleteUser(ctx, st.Data[dataUserUUID]))
+}
+
+// ensureCompany создаёт заглушку компании владельца; уже созданная — не
+// ошибка. created — компанию создал этот вызов.
+func ensureCompany(ctx context.Context, api *services.ApiGateway, userUUID string) (bool, error) {
+	_, err := api.Company.CreateCompany(ctx, &models.Company{
+		ID:   userUUID,
+		Name: companyPlaceholderName,
+	})
+	if status.Code(err) == codes.AlreadyExists {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// ensureProfile создаёт заглушку профиля; уже созданный — не ошибка.
+func ensureProfile(ctx context.Context, api *services.ApiGateway, userUUID, email, role string) error {
+	_, err := api.User.CreateUser(ctx, &usersv1.NewProfileRequest{
+		Profile: &usersv1.Profile{
+			Id:    userUUID,
+			Email: email,
+			Age:   profileStubAge,
+			Role:  role,
+		},
+	})
+	if status.Code(err) == codes.AlreadyExists {
+		return nil
+	}
+	return err
+}
+
+// retryable — временные ошибки, после которых шаг стоит повторить.
+func retryable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Aborted, codes.ResourceExhausted:
+		return true
+	default:
+		return false
+	}
+}
+
+func ignoreNotFound(err error) error {
+	if status.Code(err) == codes.NotFound {
+		return nil
+	}
+	return err
+}
+
+// sagaID — ключ идемпотентности привязан к email: один и тот же ключ от
+// разных пользователей не пересекается.
+func sagaID(idempotencyKey, email string) string {
+	sum := sha256.Sum256([]byte(idempotencyKey + "\x00" + email))
+	return "registration:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,305 @@
+// Package saga — оркестрация многошаговых операций над несколькими
+// сервисами (сага): шаги выполняются по порядку, состояние сохраняется после
+// каждого, временные ошибки повторяются, а при окончательной ошибке уже
+// выполненные шаги откатываются компенсациями в обратном порядке.
+//
+// Сохранённое состояние позволяет довести сагу до конца после падения
+// Gateway: незавершённые саги находит и продолжает фоновая сверка
+// (см. registration.Reconciler). Повторяются только шаги, помеченные
+// Idempotent, и компенсации (они обязаны быть идемпотентными).
+package saga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+type Status string
+
+const (
+	StatusRunning      Status = "running"
+	StatusCompleted    Status = "completed"
+	StatusCompensating Status = "compensating"
+	// StatusCompensated — сага не удалась, все изменения откачены.
+	StatusCompensated Status = "compensated"
+	// StatusFailed — откатить не получилось; сверка попробует ещё раз.
+	StatusFailed Status = "failed"
+)
+
+// Terminal — сага завершена и больше не требует действий.
+func (s Status) Terminal() bool {
+	return s == StatusCompleted || s == StatusCompensated
+}
+
+// StepState — прогресс одного шага.
+type StepState struct {
+	Name        string `json:"name"`
+	Done        bool   `json:"done"`
+	Compensated bool   `json:"compensated,omitempty"`
+	Attempts    int    `json:"attempts,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// Failure — ошибка, с которой сага завершилась; хранится, чтобы повторный
+// запрос с тем же ключом идемпотентности получил тот же ответ.
+type Failure struct {
+	Step    string `json:"step"`
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// State — сохраняемое состояние саги. Data — то, что нужно шагам и
+// компенсациям после перезапуска (идентификаторы созданных сущностей);
+// секреты (пароль) сюда не попадают.
+type State struct {
+	ID        string            `json:"id"`
+	Kind      string            `json:"kind"`
+	Status    Status            `json:"status"`
+	Steps     []StepState       `json:"steps"`
+	Data      map[string]string `json:"data"`
+	Failure   *Failure          `json:"failure,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// NewState — сага kind с шагами steps, ещё не начатая.
+func NewState(id, kind string, steps []Step) *State {
+	now := time.Now()
+	st := &State{
+		ID:        id,
+		Kind:      kind,
+		Status:    StatusRunning,
+		Data:      make(map[string]string),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	for _, step := range steps {
+		st.Steps = append(st.Steps, StepState{Name: step.Name})
+	}
+	return st
+}
+
+// MarkDone отмечает шаг выполненным заранее (например, аккаунт уже создан
+// до начала саги).
+func (st *State) MarkDone(name string) {
+	if s := st.step(name); s != nil {
+		s.Done = true
+	}
+}
+
+func (st *State) step(name string) *StepState {
+	for i := range st.Steps {
+		if st.Steps[i].Name == name {
+			return &st.Steps[i]
+		}
+	}
+	return nil
+}
+
+// Step — шаг саги. Compensate nil — откатывать нечего.
+type Step struct {
+	Name       string
+	Do         func(ctx context.Context, st *State) error
+	Compensate func(ctx context.Context, st *State) error
+	// Idempotent — Do можно безопасно повторить после временной ошибки
+	// (ответ мог потеряться уже после того, как сервис всё сделал).
+	Idempotent bool
+}
+
+// RetryPolicy — повторы шага и компенсации внутри одного запуска.
+type RetryPolicy struct {
+	Attempts int
+	// Backoff — пауза перед вторым запуском, дальше удваивается.
+	Backoff time.Duration
+	// Retryable — ошибка временная и шаг стоит повторить.
+	Retryable func(error) bool
+}
+
+// StepError — шаг Step завершился ошибкой Err.
+type StepError struct {
+	Step string
+	Err  error
+}
+
+func (e *StepError) Error() string {
+	return fmt.Sprintf("saga step %s failed: %v", e.Step, e.Err)
+}
+
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	ErrNotFound = errors.New("saga not found")
+	ErrExists   = errors.New("saga already exists")
+)
+
+// Store — где хранится состояние саг.
+type Store interface {
+	// Create сохраняет новую сагу; ErrExists, если сага с таким id уже есть.
+	Create(ctx context.Context, st *State) error
+	Get(ctx context.Context, id string) (*State, error)
+	Save(ctx context.Context, st *State) error
+	// ListStale — незавершённые саги, не обновлявшиеся с before.
+	ListStale(ctx context.Context, before time.Time) ([]*State, error)
+}
+
+type Orchestrator struct {
+	store Store
+	retry RetryPolicy
+}
+
+func NewOrchestrator(store Store, retry RetryPolicy) *Orchestrator {
+	if retry.Attempts <= 0 {
+		retry.Attempts = 1
+	}
+	if retry.Retryable == nil {
+		retry.Retryable = func(error) bool { return false }
+	}
+	return &Orchestrator{store: store, retry: retry}
+}
+
+func (o *Orchestrator) Store() Store {
+	return o.store
+}
+
+// Execute выполняет невыполненные шаги. При ошибке шага откатывает
+// выполненные и возвращает *StepError; состояние саги сохранено в любом
+// случае.
+func (o *Orchestrator) Execute(ctx context.Context, st *State, steps []Step) error {
+	for i, step := range steps {
+		state := st.step(step.Name)
+		if state == nil {
+			return fmt.Errorf("saga %s: unknown step %s", st.ID, step.Name)
+		}
+		if state.Done {
+			continue
+		}
+
+		err := o.run(ctx, st, state, step.Do, step.Idempotent)
+		if err == nil {
+			state.Done = true
+			state.Error = ""
+			o.save(ctx, st)
+			continue
+		}
+
+		log.Printf("saga %s (%s): step %s failed after %d attempts: %v", st.ID, st.Kind, step.Name, state.Attempts, err)
+		state.Error = err.Error()
+		st.Status = StatusCompensating
+		o.save(ctx, st)
+
+		if cerr := o.Compensate(ctx, st, steps[:i]); cerr != nil {
+			log.Printf("saga %s (%s): compensation failed: %v", st.ID, st.Kind, cerr)
+		}
+		return &StepError{Step: step.Name, Err: err}
+	}
+
+	st.Status = StatusCompleted
+	o.save(ctx, st)
+	return nil
+}
+
+// Resume выполняет невыполненные шаги из steps без отката — для операций,
+// которые откатывать нельзя (удаление данных). Все шаги повторяются при
+// временных ошибках и должны быть идемпотентными. Ошибка шага оставляет
+// сагу в StatusRunning: её продолжит сверка. StatusCompleted сага получает,
+// когда выполнены все её шаги, а не только переданные, — так первый шаг
+// можно выполнить отдельно от остальных.
+func (o *Orchestrator) Resume(ctx context.Context, st *State, steps []Step) error {
+	for _, step := range steps {
+		state := st.step(step.Name)
+		if state == nil {
+			return fmt.Errorf("saga %s: unknown step %s", st.ID, step.Name)
+		}
+		if state.Done {
+			continue
+		}
+
+		if err := o.run(ctx, st, state, step.Do, true); err != nil {
+			log.Printf("saga %s (%s): step %s failed after %d attempts: %v", st.ID, st.Kind, step.Name, state.Attempts, err)
+			state.Error = err.Error()
+			o.save(ctx, st)
+			return &StepError{Step: step.Name, Err: err}
+		}
+		state.Done = true
+		state.Error = ""
+		o.save(ctx, st)
+	}
+
+	for _, state := range st.Steps {
+		if !state.Done {
+			return nil
+		}
+	}
+	st.Status = StatusCompleted
+	o.save(ctx, st)
+	return nil
+}
+
+// Compensate откатывает выполненные шаги в обратном порядке. Если откатить
+// не удалось, сага остаётся в StatusFailed — сверка повторит.
+func (o *Orchestrator) Compensate(ctx context.Context, st *State, steps []Step) error {
+	st.Status = StatusCompensating
+	for i := len(steps) - 1; i >= 0; i-- {
+		step := steps[i]
+		state := st.step(step.Name)
+		if state == nil || !state.Done || state.Compensated || step.Compensate == nil {
+			continue
+		}
+
+		if err := o.run(ctx, st, state, step.Compensate, true); err != nil {
+			state.Error = err.Error()
+			st.Status = StatusFailed
+			o.save(ctx, st)
+			return &StepError{Step: step.Name, Err: err}
+		}
+		state.Compensated = true
+		o.save(ctx, st)
+	}
+
+	st.Status = StatusCompensated
+	o.save(ctx, st)
+	return nil
+}
+
+// run вызывает fn; если retry, то с повторами временных ошибок.
+func (o *Orchestrator) run(ctx context.Context, st *State, state *StepState, fn func(context.Context, *State) error, retry bool) error {
+	attempts := o.retry.Attempts
+	if !retry {
+		attempts = 1
+	}
+
+	backoff := o.retry.Backoff
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		state.Attempts++
+		if err = fn(ctx, st); err == nil || !o.retry.Retryable(err) {
+			return err
+		}
+		if attempt == attempts {
+			break
+		}
+
+		log.Printf("saga %s (%s): step %s attempt %d failed, retrying in %v: %v", st.ID, st.Kind, state.Name, attempt, backoff, err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+	return err
+}
+
+// save — ошибка записи состояния не прерывает сагу: шаги идемпотентны, а
+// потерянный прогресс восстановит сверка.
+func (o *Orchestrator) save(ctx context.Context, st *State) {
+	st.UpdatedAt = time.Now()
+	if err := o.store.Save(ctx, st); err != nil {
+		log.Printf("saga %s (%s): failed to save state: %v", st.ID, st.Kind, err)
+	}
+}
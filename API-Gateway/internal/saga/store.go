@@ -0,0 +1,236 @@
+package saga
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisKeyPrefix = "gw:saga:"
+	// redisActiveKey — sorted set незавершённых саг по времени обновления.
+	redisActiveKey = "gw:saga:active"
+)
+
+// RedisStore хранит саги в Redis. Незавершённые хранятся без TTL (и лежат
+// в sorted set для сверки), завершённые — retention: этого хватает для
+// повторов по ключу идемпотентности.
+//
+// Сверка удаления и выгрузки продолжает саги только по их записям, поэтому
+// Redis нужен отдельный от кэша и надёжный (см. Connect): запись, потерянная
+// при вытеснении или перезапуске, оставила бы данные в сервисах навсегда.
+type RedisStore struct {
+	rdb       *redis.Client
+	retention time.Duration
+}
+
+func NewRedisStore(rdb *redis.Client, retention time.Duration) *RedisStore {
+	return &RedisStore{rdb: rdb, retention: retention}
+}
+
+// Connect подключается к Redis состояния саг и проверяет, что он не теряет
+// записи: AOF включён, вытеснение запрещено (noeviction). Redis кэша
+// (allkeys-lru, без персистентности) эту проверку не проходит. Если CONFIG
+// недоступен (управляемый Redis), проверка пропускается с предупреждением.
+func Connect(ctx context.Context, addr string) (*redis.Client, error) {
+	rdb := redis.NewClient(&redis.Options{
+		Addr:        addr,
+		DialTimeout: 2 * time.Second,
+	})
+	if err := rdb.Ping(ctx).Err(); err != nil {
+		rdb.Close()
+		return nil, fmt.Errorf("saga redis %s: %w", addr, err)
+	}
+	if err := checkDurable(ctx, rdb); err != nil {
+		rdb.Close()
+		return nil, fmt.Errorf("saga redis %s: %w", addr, err)
+	}
+	return rdb, nil
+}
+
+func checkDurable(ctx context.Context, rdb *redis.Client) error {
+	want := map[string]string{
+		"appendonly":       "yes",
+		"maxmemory-policy": "noeviction",
+	}
+	for param, value := range want {
+		cfg, err := rdb.ConfigGet(ctx, param).Result()
+		if err != nil {
+			log.Printf("⚠ saga redis: cannot check %s (%v), durability not verified", param, err)
+			return nil
+		}
+		if got := cfg[param]; got != value {
+			return fmt.Errorf("%s is %q, want %q: saga state would be lost on eviction or restart", param, got, value)
+		}
+	}
+	return nil
+}
+
+func (s *RedisStore) Create(ctx context.Context, st *State) error {
+	raw, err := json.Marshal(st)
+	if err != nil {
+		return fmt.Errorf("failed to marshal saga: %w", err)
+	}
+
+	created, err := s.rdb.SetNX(ctx, redisKeyPrefix+st.ID, raw, 0).Result()
+	if err != nil {
+		return fmt.Errorf("failed to create saga: %w", err)
+	}
+	if !created {
+		return ErrExists
+	}
+	return s.rdb.ZAdd(ctx, redisActiveKey, redis.Z{Score: float64(st.UpdatedAt.Unix()), Member: st.ID}).Err()
+}
+
+func (s *RedisStore) Get(ctx context.Context, id string) (*State, error) {
+	raw, err := s.rdb.Get(ctx, redisKeyPrefix+id).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get saga: %w", err)
+	}
+
+	return unmarshalState(raw)
+}
+
+func (s *RedisStore) Save(ctx context.Context, st *State) error {
+	raw, err := json.Marshal(st)
+	if err != nil {
+		return fmt.Errorf("failed to marshal saga: %w", err)
+	}
+
+	pipe := s.rdb.TxPipeline()
+	if st.Status.Terminal() {
+		pipe.Set(ctx, redisKeyPrefix+st.ID, raw, s.retention)
+		pipe.ZRem(ctx, redisActiveKey, st.ID)
+	} else {
+		pipe.Set(ctx, redisKeyPrefix+st.ID, raw, 0)
+		pipe.ZAdd(ctx, redisActiveKey, redis.Z{Score: float64(st.UpdatedAt.Unix()), Member: st.ID})
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to save saga: %w", err)
+	}
+	return nil
+}
+
+func (s *RedisStore) ListStale(ctx context.Context, before time.Time) ([]*State, error) {
+	ids, err := s.rdb.ZRangeByScore(ctx, redisActiveKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(before.Unix(), 10),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stale sagas: %w", err)
+	}
+
+	states := make([]*State, 0, len(ids))
+	for _, id := range ids {
+		st, err := s.Get(ctx, id)
+		if errors.Is(err, ErrNotFound) {
+			// Записи нет (удалена вручную) — убираем висящий id.
+			s.rdb.ZRem(ctx, redisActiveKey, id)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		states = append(states, st)
+	}
+	return states, nil
+}
+
+// MemoryStore — хранилище в памяти процесса, когда Redis не настроен
+// (локальная разработка). Состояние не переживает перезапуск и не видно
+// другим инстансам Gateway. Саги хранятся в JSON, как и в Redis, — так
+// вызывающий не разделяет с хранилищем Steps и Data.
+type MemoryStore struct {
+	mu        sync.Mutex
+	sagas     map[string][]byte
+	retention time.Duration
+}
+
+func NewMemoryStore(retention time.Duration) *MemoryStore {
+	return &MemoryStore{sagas: make(map[string][]byte), retention: retention}
+}
+
+func (s *MemoryStore) Create(ctx context.Context, st *State) error {
+	raw, err := json.Marshal(st)
+	if err != nil {
+		return fmt.Errorf("failed to marshal saga: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.evictLocked()
+	if _, ok := s.sagas[st.ID]; ok {
+		return ErrExists
+	}
+	s.sagas[st.ID] = raw
+	return nil
+}
+
+func (s *MemoryStore) Get(ctx context.Context, id string) (*State, error) {
+	s.mu.Lock()
+	raw, ok := s.sagas[id]
+	s.mu.Unlock()
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return unmarshalState(raw)
+}
+
+func (s *MemoryStore) Save(ctx context.Context, st *State) error {
+	raw, err := json.Marshal(st)
+	if err != nil {
+		return fmt.Errorf("failed to marshal saga: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sagas[st.ID] = raw
+	return nil
+}
+
+func (s *MemoryStore) ListStale(ctx context.Context, before time.Time) ([]*State, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var states []*State
+	for _, raw := range s.sagas {
+		st, err := unmarshalState(raw)
+		if err != nil {
+			return nil, err
+		}
+		if !st.Status.Terminal() && st.UpdatedAt.Before(before) {
+			states = append(states, st)
+		}
+	}
+	return states, nil
+}
+
+// evictLocked удаляет завершённые саги старше retention.
+func (s *MemoryStore) evictLocked() {
+	cutoff := time.Now().Add(-s.retention)
+	for id, raw := range s.sagas {
+		if st, err := unmarshalState(raw); err == nil && st.Status.Terminal() && st.UpdatedAt.Before(cutoff) {
+			delete(s.sagas, id)
+		}
+	}
+}
+
+func unmarshalState(raw []byte) (*State, error) {
+	var st State
+	if err := json.Unmarshal(raw, &st); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal saga: %w", err)
+	}
+	return &st, nil
+}
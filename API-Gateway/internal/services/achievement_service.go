@@ -216,3 +216,39 @@ func (s *achievementService) DeleteAchievement(ctx context.Context, userID, achi
 	log.Printf("AchievementService: Successfully deleted achievement for user %s: %s", userID, achieveName)
 	return nil
 }
+
+// GetExportUploadUrl возвращает URL для загрузки архива выгрузки
+func (s *achievementService) GetExportUploadUrl(ctx context.Context, userID, exportID string) (*models.UploadUrlResponse, error) {
+	resp, err := s.client.GetExportUploadUrl(ctx, &achievementv1.GetExportUrlRequest{
+		UserUuid: userID,
+		ExportId: exportID,
+	})
+	if err != nil {
+		log.Printf("AchievementService: Failed to get export upload URL for user %s, export %s: %v", userID, exportID, err)
+		return nil, err
+	}
+
+	return &models.UploadUrlResponse{
+		UploadURL: resp.UploadUrl,
+		S3Key:     resp.S3Key,
+		ExpiresAt: resp.ExpiresAt,
+	}, nil
+}
+
+// GetExportDownloadUrl возвращает ссылку на скачивание архива выгрузки
+func (s *achievementService) GetExportDownloadUrl(ctx context.Context, userID, exportID string, expiresInMinutes int32) (*models.AchievementUrl, error) {
+	resp, err := s.client.GetExportDownloadUrl(ctx, &achievementv1.GetExportUrlRequest{
+		UserUuid:         userID,
+		ExportId:         exportID,
+		ExpiresInMinutes: expiresInMinutes,
+	})
+	if err != nil {
+		log.Printf("AchievementService: Failed to get export download URL for user %s, export %s: %v", userID, exportID, err)
+		return nil, err
+	}
+
+	return &models.AchievementUrl{
+		URL:       resp.Url,
+		ExpiresAt: resp.ExpiresAt,
+	}, nil
+}
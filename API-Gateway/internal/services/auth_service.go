@@ -3,13 +3,35 @@ package services
 import (
 	"context"
 	"log"
+	"time"
 
 	authv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/auth/v1"
+	commonv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/common/v1"
 	"github.com/studjobs/hh_for_students/api-gateway/internal/models"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
+// clientIPMetadataKey — адрес конечного клиента для Auth: по нему считаются
+// неудачные входы per-IP (сам Auth видит только адрес Gateway).
+const clientIPMetadataKey = "x-client-ip"
+
+// userAgentMetadataKey — User-Agent конечного клиента: Auth показывает по нему
+// устройство в списке сессий.
+const userAgentMetadataKey = "x-user-agent"
+
+// WithClientInfo добавляет адрес и User-Agent клиента в исходящие gRPC-метаданные.
+func WithClientInfo(ctx context.Context, ip, userAgent string) context.Context {
+	if ip != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, clientIPMetadataKey, ip)
+	}
+	if userAgent != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, userAgentMetadataKey, userAgent)
+	}
+	return ctx
+}
+
 type authService struct {
 	client authv1.AuthServiceClient
 }
@@ -44,11 +66,7 @@ func (s *authService) Login(ctx context.Context, email, password, role string) (
 	}
 
 	// Конвертируем ответ
-	authResp := &models.AuthResponse{
-		Token:    resp.Token,
-		UserUUID: resp.UserUuid,
-		Role:     convertRoleFromGRPC(resp.Role),
-	}
+	authResp := authResponseFromGRPC(resp)
 
 	log.Printf("AuthService: Login successful for email: %s", email)
 	return authResp, nil
@@ -76,17 +94,13 @@ func (s *authService) Register(ctx context.Context, email, password, role string
 	}
 
 	// Конвертируем ответ
-	authResp := &models.AuthResponse{
-		Token:    resp.Token,
-		UserUUID: resp.UserUuid,
-		Role:     convertRoleFromGRPC(resp.Role),
-	}
+	authResp := authResponseFromGRPC(resp)
 
 	log.Printf("AuthService: Register successful for email: %s", email)
 	return authResp, nil
 }
 
-func (s *authService) ValidateToken(ctx context.Context, token string) (bool, string, string, error) {
+func (s *authService) ValidateToken(ctx context.Context, token string) (*models.TokenInfo, error) {
 	log.Printf("AuthService: ValidateToken attempt")
 
 	resp, err := s.client.ParseToken(ctx, &authv1.ParseTokenRequest{
@@ -95,14 +109,20 @@ func (s *authService) ValidateToken(ctx context.Context, token string) (bool, st
 	if err != nil {
 		if status.Code(err) == codes.Unauthenticated {
 			log.Printf("AuthService: ValidateToken failed - invalid token")
-			return false, "", "", nil
+			return &models.TokenInfo{Valid: false}, nil
 		}
 		log.Printf("AuthService: ValidateToken failed: %v", err)
-		return false, "", "", err
+		return nil, err
 	}
 
 	log.Printf("AuthService: ValidateToken successful for user_uuid: %s", resp.UserUuid)
-	return resp.Valid, resp.UserUuid, convertRoleFromGRPC(resp.Role), nil
+	return &models.TokenInfo{
+		Valid:         resp.Valid,
+		UserUUID:      resp.UserUuid,
+		Role:          convertRoleFromGRPC(resp.Role),
+		Roles:         convertRolesFromGRPC(resp.Roles),
+		EmailVerified: resp.EmailVerified,
+	}, nil
 }
 
 func (s *authService) DeleteUser(ctx context.Context, userID string) error {
@@ -115,3 +135,681 @@ func (s *authService) DeleteUser(ctx context.Context, userID string) error {
 
 	return nil
 }
+
+func (s *authService) Refresh(ctx context.Context, refreshToken string) (*models.AuthResponse, error) {
+	log.Printf("AuthService: Refresh attempt")
+
+	resp, err := s.client.Refresh(ctx, &authv1.RefreshRequest{
+		RefreshToken: refreshToken,
+	})
+	if err != nil {
+		log.Printf("AuthService: Refresh failed: %v", err)
+		return nil, err
+	}
+
+	log.Printf("AuthService: Refresh successful for user_uuid: %s", resp.UserUuid)
+	return authResponseFromGRPC(resp), nil
+}
+
+func (s *authService) Logout(ctx context.Context, accessToken, refreshToken string, allSessions bool) error {
+	log.Printf("AuthService: Logout attempt (all_sessions=%t)", allSessions)
+
+	if _, err := s.client.Logout(ctx, &authv1.LogoutRequest{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		AllSessions:  allSessions,
+	}); err != nil {
+		log.Printf("AuthService: Logout failed: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *authService) SwitchRole(ctx context.Context, accessToken, refreshToken, role string) (*models.AuthResponse, error) {
+	log.Printf("AuthService: SwitchRole attempt to role: %s", role)
+
+	grpcRole, err := convertRoleToGRPC(role)
+	if err != nil {
+		log.Printf("AuthService: SwitchRole failed - invalid role: %s", role)
+		return nil, err
+	}
+
+	resp, err := s.client.SwitchRole(ctx, &authv1.SwitchRoleRequest{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		Role:         grpcRole,
+	})
+	if err != nil {
+		log.Printf("AuthService: SwitchRole failed: %v", err)
+		return nil, err
+	}
+
+	log.Printf("AuthService: SwitchRole successful for user_uuid: %s", resp.UserUuid)
+	return authResponseFromGRPC(resp), nil
+}
+
+func (s *authService) VerifyMFA(ctx context.Context, mfaToken, code string) (*models.AuthResponse, error) {
+	log.Printf("AuthService: VerifyMFA attempt")
+
+	resp, err := s.client.VerifyMFA(ctx, &authv1.VerifyMFARequest{
+		MfaToken: mfaToken,
+		Code:     code,
+	})
+	if err != nil {
+		log.Printf("AuthService: VerifyMFA failed: %v", err)
+		return nil, err
+	}
+
+	log.Printf("AuthService: VerifyMFA successful for user_uuid: %s", resp.UserUuid)
+	return authResponseFromGRPC(resp), nil
+}
+
+func (s *authService) EnrollTOTP(ctx context.Context, userID, mfaToken string) (*models.TOTPEnrollment, error) {
+	log.Printf("AuthService: EnrollTOTP for userID: %s", userID)
+
+	resp, err := s.client.EnrollTOTP(ctx, &authv1.EnrollTOTPRequest{
+		UserUuid: userID,
+		MfaToken: mfaToken,
+	})
+	if err != nil {
+		log.Printf("AuthService: EnrollTOTP failed: %v", err)
+		return nil, err
+	}
+
+	return &models.TOTPEnrollment{
+		Secret:          resp.Secret,
+		ProvisioningURI: resp.ProvisioningUri,
+	}, nil
+}
+
+func (s *authService) ConfirmTOTP(ctx context.Context, userID, mfaToken, code string) (*models.TOTPConfirmation, error) {
+	log.Printf("AuthService: ConfirmTOTP for userID: %s", userID)
+
+	resp, err := s.client.ConfirmTOTP(ctx, &authv1.ConfirmTOTPRequest{
+		UserUuid: userID,
+		MfaToken: mfaToken,
+		Code:     code,
+	})
+	if err != nil {
+		log.Printf("AuthService: ConfirmTOTP failed: %v", err)
+		return nil, err
+	}
+
+	confirmation := &models.TOTPConfirmation{RecoveryCodes: resp.RecoveryCodes}
+	if resp.Auth != nil {
+		confirmation.Auth = authResponseFromGRPC(resp.Auth)
+	}
+	return confirmation, nil
+}
+
+func (s *authService) DisableTOTP(ctx context.Context, userID, code string) error {
+	log.Printf("AuthService: DisableTOTP for userID: %s", userID)
+
+	if _, err := s.client.DisableTOTP(ctx, &authv1.DisableTOTPRequest{
+		UserUuid: userID,
+		Code:     code,
+	}); err != nil {
+		log.Printf("AuthService: DisableTOTP failed for user %s: %v", userID, err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *authService) RegenerateRecoveryCodes(ctx context.Context, userID, code string) ([]string, error) {
+	log.Printf("AuthService: RegenerateRecoveryCodes for userID: %s", userID)
+
+	resp, err := s.client.RegenerateRecoveryCodes(ctx, &authv1.RegenerateRecoveryCodesRequest{
+		UserUuid: userID,
+		Code:     code,
+	})
+	if err != nil {
+		log.Printf("AuthService: RegenerateRecoveryCodes failed for user %s: %v", userID, err)
+		return nil, err
+	}
+
+	return resp.Codes, nil
+}
+
+func (s *authService) ListOIDCProviders(ctx context.Context) ([]string, error) {
+	resp, err := s.client.ListOIDCProviders(ctx, &commonv1.Empty{})
+	if err != nil {
+		log.Printf("AuthService: ListOIDCProviders failed: %v", err)
+		return nil, err
+	}
+	return resp.Providers, nil
+}
+
+// StartOIDC возвращает ссылку на страницу провайдера и state, который
+// Gateway кладёт в cookie. Пустая роль — основная роль аккаунта.
+func (s *authService) StartOIDC(ctx context.Context, provider, role, linkUserID string) (string, string, error) {
+	log.Printf("AuthService: StartOIDC for provider: %s, role: %s", provider, role)
+
+	grpcRole := authv1.Role_ROLE_UNSPECIFIED
+	if role != "" {
+		var err error
+		if grpcRole, err = convertRoleToGRPC(role); err != nil {
+			log.Printf("AuthService: StartOIDC failed - invalid role: %s", role)
+			return "", "", err
+		}
+	}
+
+	resp, err := s.client.StartOIDC(ctx, &authv1.StartOIDCRequest{
+		Provider:     provider,
+		Role:         grpcRole,
+		LinkUserUuid: linkUserID,
+	})
+	if err != nil {
+		log.Printf("AuthService: StartOIDC failed for provider %s: %v", provider, err)
+		return "", "", err
+	}
+	return resp.AuthorizationUrl, resp.State, nil
+}
+
+// CompleteOIDC завершает вход через провайдера. Вместе с ответом возвращает
+// email аккаунта — он нужен для профиля, если аккаунт только что создан.
+func (s *authService) CompleteOIDC(ctx context.Context, provider, state, code string) (*models.AuthResponse, string, error) {
+	log.Printf("AuthService: CompleteOIDC for provider: %s", provider)
+
+	resp, err := s.client.CompleteOIDC(ctx, &authv1.CompleteOIDCRequest{
+		Provider: provider,
+		State:    state,
+		Code:     code,
+	})
+	if err != nil {
+		log.Printf("AuthService: CompleteOIDC failed for provider %s: %v", provider, err)
+		return nil, "", err
+	}
+
+	log.Printf("AuthService: CompleteOIDC successful for user_uuid: %s", resp.Auth.GetUserUuid())
+	return authResponseFromGRPC(resp.Auth), resp.Email, nil
+}
+
+func (s *authService) ListSessions(ctx context.Context, accessToken string) ([]models.Session, error) {
+	resp, err := s.client.ListSessions(ctx, &authv1.ListSessionsRequest{AccessToken: accessToken})
+	if err != nil {
+		log.Printf("AuthService: ListSessions failed: %v", err)
+		return nil, err
+	}
+
+	sessions := make([]models.Session, 0, len(resp.Sessions))
+	for _, session := range resp.Sessions {
+		sessions = append(sessions, models.Session{
+			ID:         session.Id,
+			Device:     session.Device,
+			IP:         session.Ip,
+			UserAgent:  session.UserAgent,
+			Role:       convertRoleFromGRPC(session.Role),
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			Current:    session.Current,
+		})
+	}
+	return sessions, nil
+}
+
+func (s *authService) RevokeSession(ctx context.Context, accessToken, sessionID string) error {
+	log.Printf("AuthService: RevokeSession %s", sessionID)
+
+	if _, err := s.client.RevokeSession(ctx, &authv1.RevokeSessionRequest{
+		AccessToken: accessToken,
+		SessionId:   sessionID,
+	}); err != nil {
+		log.Printf("AuthService: RevokeSession failed for session %s: %v", sessionID, err)
+		return err
+	}
+	return nil
+}
+
+func (s *authService) RevokeOtherSessions(ctx context.Context, accessToken string) (int, error) {
+	resp, err := s.client.RevokeOtherSessions(ctx, &authv1.RevokeOtherSessionsRequest{AccessToken: accessToken})
+	if err != nil {
+		log.Printf("AuthService: RevokeOtherSessions failed: %v", err)
+		return 0, err
+	}
+	return int(resp.Count), nil
+}
+
+func (s *authService) CreateAPIKey(ctx context.Context, accessToken string, req models.CreateAPIKeyRequest) (*models.APIKeyCreated, error) {
+	log.Printf("AuthService: CreateAPIKey %q, scopes: %v", req.Name, req.Scopes)
+
+	resp, err := s.client.CreateApiKey(ctx, &authv1.CreateApiKeyRequest{
+		AccessToken:   accessToken,
+		Name:          req.Name,
+		Scopes:        req.Scopes,
+		ExpiresInDays: int32(req.ExpiresInDays),
+	})
+	if err != nil {
+		log.Printf("AuthService: CreateAPIKey failed: %v", err)
+		return nil, err
+	}
+	return &models.APIKeyCreated{APIKey: apiKeyFromGRPC(resp.ApiKey), Key: resp.Key}, nil
+}
+
+func (s *authService) ListAPIKeys(ctx context.Context, accessToken string) ([]models.APIKey, error) {
+	resp, err := s.client.ListApiKeys(ctx, &authv1.ListApiKeysRequest{AccessToken: accessToken})
+	if err != nil {
+		log.Printf("AuthService: ListAPIKeys failed: %v", err)
+		return nil, err
+	}
+
+	keys := make([]models.APIKey, 0, len(resp.ApiKeys))
+	for _, key := range resp.ApiKeys {
+		keys = append(keys, apiKeyFromGRPC(key))
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey отзывает ключ и возвращает его хэш — по нему сбрасывается кэш проверки.
+func (s *authService) RevokeAPIKey(ctx context.Context, accessToken, keyID string) (string, error) {
+	log.Printf("AuthService: RevokeAPIKey %s", keyID)
+
+	resp, err := s.client.RevokeApiKey(ctx, &authv1.RevokeApiKeyRequest{
+		AccessToken: accessToken,
+		KeyId:       keyID,
+	})
+	if err != nil {
+		log.Printf("AuthService: RevokeAPIKey failed for key %s: %v", keyID, err)
+		return "", err
+	}
+	return resp.KeyHash, nil
+}
+
+func (s *authService) ValidateAPIKey(ctx context.Context, key string) (*models.APIKeyInfo, error) {
+	resp, err := s.client.ValidateApiKey(ctx, &authv1.ValidateApiKeyRequest{Key: key})
+	if err != nil {
+		log.Printf("AuthService: ValidateAPIKey failed: %v", err)
+		return nil, err
+	}
+	if !resp.Valid {
+		return &models.APIKeyInfo{Valid: false}, nil
+	}
+
+	info := &models.APIKeyInfo{
+		Valid:         true,
+		KeyID:         resp.KeyId,
+		CompanyID:     resp.CompanyId,
+		Scopes:        resp.Scopes,
+		EmailVerified: resp.EmailVerified,
+	}
+	if resp.ExpiresAt != "" {
+		if expiresAt, err := time.Parse(time.RFC3339, resp.ExpiresAt); err == nil {
+			info.ExpiresAt = expiresAt
+		}
+	}
+	return info, nil
+}
+
+func apiKeyFromGRPC(key *authv1.ApiKey) models.APIKey {
+	return models.APIKey{
+		ID:         key.GetId(),
+		Name:       key.GetName(),
+		Prefix:     key.GetPrefix(),
+		Scopes:     key.GetScopes(),
+		CreatedAt:  key.GetCreatedAt(),
+		ExpiresAt:  key.GetExpiresAt(),
+		LastUsedAt: key.GetLastUsedAt(),
+	}
+}
+
+// ListAccounts — страница аккаунтов, созданных после createdAfter.
+func (s *authService) ListAccounts(ctx context.Context, createdAfter time.Time, pageToken string, limit int) ([]Account, string, error) {
+	resp, err := s.client.ListAccounts(ctx, &authv1.ListAccountsRequest{
+		CreatedAfter: createdAfter.UTC().Format(time.RFC3339),
+		PageToken:    pageToken,
+		Limit:        int32(limit),
+	})
+	if err != nil {
+		log.Printf("AuthService: ListAccounts failed: %v", err)
+		return nil, "", err
+	}
+
+	accounts := make([]Account, 0, len(resp.Accounts))
+	for _, a := range resp.Accounts {
+		createdAt, err := time.Parse(time.RFC3339, a.CreatedAt)
+		if err != nil {
+			log.Printf("AuthService: ListAccounts - bad created_at %q for user %s", a.CreatedAt, a.UserUuid)
+		}
+		accounts = append(accounts, Account{
+			UserUUID:  a.UserUuid,
+			Email:     a.Email,
+			Role:      convertRoleFromGRPC(a.Role),
+			Roles:     convertRolesFromGRPC(a.Roles),
+			CreatedAt: createdAt,
+		})
+	}
+	return accounts, resp.NextPageToken, nil
+}
+
+// ExportAccount — всё, что Auth хранит о пользователе, для выгрузки данных.
+func (s *authService) ExportAccount(ctx context.Context, userID string) (*models.AccountExport, error) {
+	resp, err := s.client.ExportAccount(ctx, &authv1.ExportAccountRequest{UserUuid: userID})
+	if err != nil {
+		log.Printf("AuthService: ExportAccount failed for user %s: %v", userID, err)
+		return nil, err
+	}
+
+	account := resp.GetAccount()
+	export := &models.AccountExport{
+		UserUUID:        account.GetUserUuid(),
+		Email:           account.GetEmail(),
+		Role:            convertRoleFromGRPC(account.GetRole()),
+		Roles:           convertRolesFromGRPC(account.GetRoles()),
+		CreatedAt:       account.GetCreatedAt(),
+		EmailVerifiedAt: resp.EmailVerifiedAt,
+		MFAEnabled:      resp.MfaEnabled,
+		LinkedProviders: make([]models.LinkedProvider, 0, len(resp.Identities)),
+		Sessions:        make([]models.Session, 0, len(resp.Sessions)),
+	}
+	for _, identity := range resp.Identities {
+		export.LinkedProviders = append(export.LinkedProviders, models.LinkedProvider{
+			Provider: identity.Provider,
+			Email:    identity.Email,
+			LinkedAt: identity.LinkedAt,
+		})
+	}
+	for _, session := range resp.Sessions {
+		export.Sessions = append(export.Sessions, models.Session{
+			ID:         session.Id,
+			Device:     session.Device,
+			IP:         session.Ip,
+			UserAgent:  session.UserAgent,
+			Role:       convertRoleFromGRPC(session.Role),
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+		})
+	}
+	return export, nil
+}
+
+func (s *authService) RequestPasswordReset(ctx context.Context, email string) error {
+	log.Printf("AuthService: RequestPasswordReset for email: %s", email)
+
+	if _, err := s.client.RequestPasswordReset(ctx, &authv1.PasswordResetRequest{
+		Email: email,
+	}); err != nil {
+		log.Printf("AuthService: RequestPasswordReset failed for email %s: %v", email, err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *authService) ConfirmPasswordReset(ctx context.Context, token, newPassword string) error {
+	log.Printf("AuthService: ConfirmPasswordReset attempt")
+
+	if _, err := s.client.ConfirmPasswordReset(ctx, &authv1.PasswordResetConfirm{
+		Token:       token,
+		NewPassword: newPassword,
+	}); err != nil {
+		log.Printf("AuthService: ConfirmPasswordReset failed: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *authService) ConfirmEmail(ctx context.Context, token string) error {
+	log.Printf("AuthService: ConfirmEmail attempt")
+
+	if _, err := s.client.ConfirmEmail(ctx, &authv1.ConfirmEmailRequest{
+		Token: token,
+	}); err != nil {
+		log.Printf("AuthService: ConfirmEmail failed: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *authService) ResendVerification(ctx context.Context, userID string) error {
+	log.Printf("AuthService: ResendVerification for userID: %s", userID)
+
+	if _, err := s.client.ResendVerification(ctx, &authv1.ResendVerificationRequest{
+		UserUuid: userID,
+	}); err != nil {
+		log.Printf("AuthService: ResendVerification failed for user %s: %v", userID, err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *authService) UnlockAccount(ctx context.Context, email string) error {
+	log.Printf("AuthService: UnlockAccount for email: %s", email)
+
+	if _, err := s.client.UnlockAccount(ctx, &authv1.UnlockAccountRequest{
+		Email: email,
+	}); err != nil {
+		log.Printf("AuthService: UnlockAccount failed for email %s: %v", email, err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *authService) GetJWKS(ctx context.Context) ([]models.JWK, error) {
+	resp, err := s.client.GetJWKS(ctx, &commonv1.Empty{})
+	if err != nil {
+		log.Printf("AuthService: GetJWKS failed: %v", err)
+		return nil, err
+	}
+
+	keys := make([]models.JWK, 0, len(resp.Keys))
+	for _, k := range resp.Keys {
+		keys = append(keys, models.JWK{
+			Kty: k.Kty,
+			Crv: k.Crv,
+			X:   k.X,
+			Kid: k.Kid,
+			Alg: k.Alg,
+			Use: k.Use,
+		})
+	}
+	return keys, nil
+}
+
+// authResponseFromGRPC конвертирует ответ Auth с парой токенов
+func authResponseFromGRPC(resp *authv1.AuthResponse) *models.AuthResponse {
+	return &models.AuthResponse{
+		Token:        resp.Token,
+		RefreshToken: resp.RefreshToken,
+		UserUUID:     resp.UserUuid,
+		Role:         convertRoleFromGRPC(resp.Role),
+		Roles:        convertRolesFromGRPC(resp.Roles),
+		NewAccount:   resp.NewAccount,
+
+		MFARequired:           resp.MfaRequired,
+		MFAToken:              resp.MfaToken,
+		MFAEnrollmentRequired: resp.MfaEnrollmentRequired,
+	}
+}
+
+// BanUser блокирует аккаунт userID от имени администратора adminID.
+func (s *authService) BanUser(ctx context.Context, adminID, userID, reason string) (*models.AdminAccount, error) {
+	log.Printf("AuthService: BanUser %s by admin %s", userID, adminID)
+
+	resp, err := s.client.BanUser(ctx, &authv1.BanUserRequest{
+		AdminUuid: adminID,
+		UserUuid:  userID,
+		Reason:    reason,
+	})
+	if err != nil {
+		log.Printf("AuthService: BanUser failed for user %s: %v", userID, err)
+		return nil, err
+	}
+	account := adminAccountFromGRPC(resp)
+	return &account, nil
+}
+
+func (s *authService) UnbanUser(ctx context.Context, userID string) (*models.AdminAccount, error) {
+	log.Printf("AuthService: UnbanUser %s", userID)
+
+	resp, err := s.client.UnbanUser(ctx, &authv1.UnbanUserRequest{UserUuid: userID})
+	if err != nil {
+		log.Printf("AuthService: UnbanUser failed for user %s: %v", userID, err)
+		return nil, err
+	}
+	account := adminAccountFromGRPC(resp)
+	return &account, nil
+}
+
+func (s *authService) SearchAccounts(ctx context.Context, filter models.AdminAccountFilter, page, limit int) (*models.AdminAccountList, error) {
+	req := &authv1.SearchAccountsRequest{
+		Query:  filter.Query,
+		Status: filter.Status,
+		Page:   int32(page),
+		Limit:  int32(limit),
+	}
+	if filter.Role != "" {
+		role, err := convertRoleToGRPC(filter.Role)
+		if err != nil {
+			return nil, err
+		}
+		req.Role = role
+	}
+
+	resp, err := s.client.SearchAccounts(ctx, req)
+	if err != nil {
+		log.Printf("AuthService: SearchAccounts failed: %v", err)
+		return nil, err
+	}
+
+	list := &models.AdminAccountList{
+		Accounts:   make([]models.AdminAccount, 0, len(resp.Accounts)),
+		Pagination: pageOf(resp.Total, page, limit),
+	}
+	for _, a := range resp.Accounts {
+		list.Accounts = append(list.Accounts, adminAccountFromGRPC(a))
+	}
+	return list, nil
+}
+
+// RecordAdminAction пишет действие администратора в журнал Auth.
+func (s *authService) RecordAdminAction(ctx context.Context, action models.AdminAction) error {
+	_, err := s.client.RecordAdminAction(ctx, &authv1.RecordAdminActionRequest{
+		AdminUuid:  action.AdminUUID,
+		Action:     action.Action,
+		TargetType: action.TargetType,
+		TargetId:   action.TargetID,
+		Details:    action.Details,
+		Status:     int32(action.Status),
+		Ip:         action.IP,
+	})
+	if err != nil {
+		log.Printf("AuthService: RecordAdminAction failed for %s by %s: %v", action.Action, action.AdminUUID, err)
+	}
+	return err
+}
+
+func (s *authService) ListAdminActions(ctx context.Context, filter models.AdminActionFilter, page, limit int) (*models.AdminActionList, error) {
+	resp, err := s.client.ListAdminActions(ctx, &authv1.ListAdminActionsRequest{
+		AdminUuid:  filter.AdminUUID,
+		Action:     filter.Action,
+		TargetType: filter.TargetType,
+		TargetId:   filter.TargetID,
+		Page:       int32(page),
+		Limit:      int32(limit),
+	})
+	if err != nil {
+		log.Printf("AuthService: ListAdminActions failed: %v", err)
+		return nil, err
+	}
+
+	list := &models.AdminActionList{
+		Actions:    make([]models.AdminAction, 0, len(resp.Actions)),
+		Pagination: pageOf(resp.Total, page, limit),
+	}
+	for _, a := range resp.Actions {
+		list.Actions = append(list.Actions, models.AdminAction{
+			ID:         a.Id,
+			AdminUUID:  a.AdminUuid,
+			Action:     a.Action,
+			TargetType: a.TargetType,
+			TargetID:   a.TargetId,
+			Details:    a.Details,
+			Status:     int(a.Status),
+			IP:         a.Ip,
+			CreatedAt:  a.CreatedAt,
+		})
+	}
+	return list, nil
+}
+
+// RecordAuditEvent пишет бизнес-действие в журнал аудита Auth.
+func (s *authService) RecordAuditEvent(ctx context.Context, event models.AuditEvent) error {
+	_, err := s.client.RecordAuditEvent(ctx, &authv1.RecordAuditEventRequest{
+		ActorUuid:    event.ActorUUID,
+		ActorRole:    event.ActorRole,
+		Action:       event.Action,
+		ResourceType: event.ResourceType,
+		ResourceId:   event.ResourceID,
+		CompanyId:    event.CompanyID,
+		Changes:      event.Changes,
+		Ip:           event.IP,
+	})
+	if err != nil {
+		log.Printf("AuthService: RecordAuditEvent failed for %s by %s: %v", event.Action, event.ActorUUID, err)
+	}
+	return err
+}
+
+func (s *authService) ListAuditEvents(ctx context.Context, filter models.AuditFilter, page, limit int) (*models.AuditEventList, error) {
+	resp, err := s.client.ListAuditEvents(ctx, &authv1.ListAuditEventsRequest{
+		CompanyId:    filter.CompanyID,
+		ActorUuid:    filter.ActorUUID,
+		Action:       filter.Action,
+		ResourceType: filter.ResourceType,
+		ResourceId:   filter.ResourceID,
+		Since:        filter.Since,
+		Until:        filter.Until,
+		Page:         int32(page),
+		Limit:        int32(limit),
+	})
+	if err != nil {
+		log.Printf("AuthService: ListAuditEvents failed: %v", err)
+		return nil, err
+	}
+
+	list := &models.AuditEventList{
+		Events:     make([]models.AuditEvent, 0, len(resp.Events)),
+		Pagination: pageOf(resp.Total, page, limit),
+	}
+	for _, e := range resp.Events {
+		list.Events = append(list.Events, models.AuditEvent{
+			ID:           e.Id,
+			ActorUUID:    e.ActorUuid,
+			ActorRole:    e.ActorRole,
+			Action:       e.Action,
+			ResourceType: e.ResourceType,
+			ResourceID:   e.ResourceId,
+			CompanyID:    e.CompanyId,
+			Changes:      e.Changes,
+			IP:           e.Ip,
+			CreatedAt:    e.CreatedAt,
+		})
+	}
+	return list, nil
+}
+
+func adminAccountFromGRPC(a *authv1.Account) models.AdminAccount {
+	return models.AdminAccount{
+		UserUUID:  a.GetUserUuid(),
+		Email:     a.GetEmail(),
+		Role:      convertRoleFromGRPC(a.GetRole()),
+		Roles:     convertRolesFromGRPC(a.GetRoles()),
+		CreatedAt: a.GetCreatedAt(),
+		BannedAt:  a.GetBannedAt(),
+		BanReason: a.GetBanReason(),
+	}
+}
+
+// pageOf — пагинация ответа по общему числу записей total.
+func pageOf(total int32, page, limit int) *models.PaginationResponse {
+	var pages int32
+	if limit > 0 {
+		pages = (total + int32(limit) - 1) / int32(limit)
+	}
+	return &models.PaginationResponse{Total: total, Pages: pages, CurrentPage: int32(page)}
+}
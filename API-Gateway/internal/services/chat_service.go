@@ -99,3 +99,11 @@ func (s *chatService) ListHiddenThreads(ctx context.Context, userID string) ([]s
 	}
 	return resp.GetThreadIds(), nil
 }
+
+func (s *chatService) EraseUserMessages(ctx context.Context, userID string) (int, error) {
+	resp, err := s.client.EraseUserMessages(ctx, &chatv1.EraseUserMessagesRequest{UserId: userID})
+	if err != nil {
+		return 0, err
+	}
+	return int(resp.GetCount()), nil
+}
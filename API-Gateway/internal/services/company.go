@@ -54,6 +54,7 @@ func (s *companyService) CreateCompany(ctx context.Context, company *models.Comp
 		Site:                      resp.Site,
 		CleanupVacanciesAfterDays: resp.CleanupVacanciesAfterDays,
 		CleanupTasksAfterDays:     resp.CleanupTasksAfterDays,
+		VerifiedAt:                resp.VerifiedAt,
 	}
 
 	if resp.Type != nil {
@@ -85,6 +86,7 @@ func (s *companyService) GetCompany(ctx context.Context, id string) (*models.Com
 		Site:                      resp.Site,
 		CleanupVacanciesAfterDays: resp.CleanupVacanciesAfterDays,
 		CleanupTasksAfterDays:     resp.CleanupTasksAfterDays,
+		VerifiedAt:                resp.VerifiedAt,
 	}
 
 	if resp.Type != nil {
@@ -129,6 +131,7 @@ func (s *companyService) GetAllCompanies(ctx context.Context, pagination *models
 			Site:                      protoCompany.Site,
 			CleanupVacanciesAfterDays: protoCompany.CleanupVacanciesAfterDays,
 			CleanupTasksAfterDays:     protoCompany.CleanupTasksAfterDays,
+			VerifiedAt:                protoCompany.VerifiedAt,
 		}
 
 		if protoCompany.Type != nil {
@@ -190,6 +193,7 @@ func (s *companyService) UpdateCompany(ctx context.Context, id string, company *
 		Site:                      resp.Site,
 		CleanupVacanciesAfterDays: resp.CleanupVacanciesAfterDays,
 		CleanupTasksAfterDays:     resp.CleanupTasksAfterDays,
+		VerifiedAt:                resp.VerifiedAt,
 	}
 
 	if resp.Type != nil {
@@ -202,6 +206,35 @@ func (s *companyService) UpdateCompany(ctx context.Context, id string, company *
 	return result, nil
 }
 
+// VerifyCompany ставит (verified) или снимает отметку модерации компании.
+func (s *companyService) VerifyCompany(ctx context.Context, id string, verified bool) (*models.Company, error) {
+	log.Printf("CompanyService: VerifyCompany for id: %s, verified: %t", id, verified)
+
+	resp, err := s.client.VerifyCompany(ctx, &companyv1.VerifyCompanyRequest{
+		Id:       id,
+		Verified: verified,
+	})
+	if err != nil {
+		log.Printf("CompanyService: VerifyCompany failed for id %s: %v", id, err)
+		return nil, err
+	}
+
+	result := &models.Company{
+		ID:                        resp.Id,
+		Name:                      resp.Name,
+		Description:               resp.Description,
+		City:                      resp.City,
+		Site:                      resp.Site,
+		CleanupVacanciesAfterDays: resp.CleanupVacanciesAfterDays,
+		CleanupTasksAfterDays:     resp.CleanupTasksAfterDays,
+		VerifiedAt:                resp.VerifiedAt,
+	}
+	if resp.Type != nil {
+		result.Type = &models.CompanyType{Value: resp.Type.Value}
+	}
+	return result, nil
+}
+
 func (s *companyService) DeleteCompany(ctx context.Context, id string) error {
 	log.Printf("CompanyService: DeleteCompany attempt for id: %s", id)
 
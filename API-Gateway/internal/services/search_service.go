@@ -26,6 +26,24 @@ func (s *searchService) Available() bool {
 	return s.client != nil
 }
 
+func (s *searchService) DeleteProfile(ctx context.Context, id string) error {
+	_, err := s.client.DeleteProfile(ctx, &searchv1.DeleteDocumentRequest{Id: id})
+	return err
+}
+
+func (s *searchService) Reindex(ctx context.Context, recreate bool) (*models.ReindexResult, error) {
+	resp, err := s.client.Reindex(ctx, &searchv1.ReindexRequest{RecreateIndices: recreate})
+	if err != nil {
+		log.Printf("SearchService: Reindex failed: %v", err)
+		return nil, err
+	}
+	return &models.ReindexResult{
+		Profiles:   resp.IndexedProfiles,
+		Vacancies:  resp.IndexedVacancies,
+		MicroTasks: resp.IndexedMicrotasks,
+	}, nil
+}
+
 func (s *searchService) SearchProfiles(ctx context.Context, query string, skillSlugs []string, professionCategory string, page, limit int32) (*usersv1.ProfileList, error) {
 	return s.client.SearchProfiles(ctx, &searchv1.SearchProfilesRequest{
 		Query:              query,
@@ -2,6 +2,8 @@ package services
 
 import (
 	"context"
+	"time"
+
 	achievementv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/achievement/v1"
 	applicationv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/application/v1"
 	chatv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/chat/v1"
@@ -21,8 +23,55 @@ import (
 type AuthService interface {
 	Login(ctx context.Context, email, password, role string) (*models.AuthResponse, error)
 	Register(ctx context.Context, email, password, role string) (*models.AuthResponse, error)
-	ValidateToken(ctx context.Context, token string) (bool, string, string, error)
+	ValidateToken(ctx context.Context, token string) (*models.TokenInfo, error)
 	DeleteUser(ctx context.Context, userID string) error
+	Refresh(ctx context.Context, refreshToken string) (*models.AuthResponse, error)
+	Logout(ctx context.Context, accessToken, refreshToken string, allSessions bool) error
+	GetJWKS(ctx context.Context) ([]models.JWK, error)
+	RequestPasswordReset(ctx context.Context, email string) error
+	ConfirmPasswordReset(ctx context.Context, token, newPassword string) error
+	ConfirmEmail(ctx context.Context, token string) error
+	ResendVerification(ctx context.Context, userID string) error
+	UnlockAccount(ctx context.Context, email string) error
+	SwitchRole(ctx context.Context, accessToken, refreshToken, role string) (*models.AuthResponse, error)
+	VerifyMFA(ctx context.Context, mfaToken, code string) (*models.AuthResponse, error)
+	EnrollTOTP(ctx context.Context, userID, mfaToken string) (*models.TOTPEnrollment, error)
+	ConfirmTOTP(ctx context.Context, userID, mfaToken, code string) (*models.TOTPConfirmation, error)
+	DisableTOTP(ctx context.Context, userID, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, userID, code string) ([]string, error)
+	ListOIDCProviders(ctx context.Context) ([]string, error)
+	StartOIDC(ctx context.Context, provider, role, linkUserID string) (string, string, error)
+	CompleteOIDC(ctx context.Context, provider, state, code string) (*models.AuthResponse, string, error)
+	ListSessions(ctx context.Context, accessToken string) ([]models.Session, error)
+	RevokeSession(ctx context.Context, accessToken, sessionID string) error
+	RevokeOtherSessions(ctx context.Context, accessToken string) (int, error)
+	ListAccounts(ctx context.Context, createdAfter time.Time, pageToken string, limit int) ([]Account, string, error)
+	ExportAccount(ctx context.Context, userID string) (*models.AccountExport, error)
+	CreateAPIKey(ctx context.Context, accessToken string, req models.CreateAPIKeyRequest) (*models.APIKeyCreated, error)
+	ListAPIKeys(ctx context.Context, accessToken string) ([]models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, accessToken, keyID string) (string, error)
+	ValidateAPIKey(ctx context.Context, key string) (*models.APIKeyInfo, error)
+
+	// Администрирование (ROLE_ADMIN).
+	BanUser(ctx context.Context, adminID, userID, reason string) (*models.AdminAccount, error)
+	UnbanUser(ctx context.Context, userID string) (*models.AdminAccount, error)
+	SearchAccounts(ctx context.Context, filter models.AdminAccountFilter, page, limit int) (*models.AdminAccountList, error)
+	RecordAdminAction(ctx context.Context, action models.AdminAction) error
+	ListAdminActions(ctx context.Context, filter models.AdminActionFilter, page, limit int) (*models.AdminActionList, error)
+
+	// Журнал аудита бизнес-действий.
+	RecordAuditEvent(ctx context.Context, event models.AuditEvent) error
+	ListAuditEvents(ctx context.Context, filter models.AuditFilter, page, limit int) (*models.AuditEventList, error)
+}
+
+// Account — аккаунт Auth для сверки с профилями и компаниями.
+type Account struct {
+	UserUUID string
+	Email    string
+	// Role — роль, с которой аккаунт создан; Roles — все выданные.
+	Role      string
+	Roles     []string
+	CreatedAt time.Time
 }
 
 // ExpertiseTest — облёгчённая HTTP-модель теста для проброса в Gateway.
@@ -66,6 +115,10 @@ type AchievementService interface {
 	SubmitForReview(ctx context.Context, userUUID string, achievementID int64) error
 	GetExpertQueue(ctx context.Context, page, limit int32) (*models.AchievementList, error)
 	ReviewAchievement(ctx context.Context, achievementID int64, reviewerUUID string, decision int32, comment string) error
+	// GetExportUploadUrl / GetExportDownloadUrl — архив выгрузки персональных
+	// данных exportID пользователя userID.
+	GetExportUploadUrl(ctx context.Context, userID, exportID string) (*models.UploadUrlResponse, error)
+	GetExportDownloadUrl(ctx context.Context, userID, exportID string, expiresInMinutes int32) (*models.AchievementUrl, error)
 }
 
 type CompanyService interface {
@@ -74,6 +127,8 @@ type CompanyService interface {
 	GetAllCompanies(ctx context.Context, pagination *models.Pagination, city, companyType, query string) (*models.CompanyList, error)
 	UpdateCompany(ctx context.Context, id string, company *models.Company) (*models.Company, error)
 	DeleteCompany(ctx context.Context, id string) error
+	// VerifyCompany — отметка модерации (действие администратора).
+	VerifyCompany(ctx context.Context, id string, verified bool) (*models.Company, error)
 
 	// HR-membership
 	ApplyMembership(ctx context.Context, companyID, userID, note string) (*models.CompanyMember, error)
@@ -99,6 +154,11 @@ type SearchService interface {
 	SearchVacanciesAsModel(ctx context.Context, query string, skillSlugs []string, salaryMin, experienceMax int32, companyID string, page, limit int32) (*models.VacancyList, error)
 	// SearchMicroTasksAsModel ищет микрозадачи в ES и возвращает HTTP-модель.
 	SearchMicroTasksAsModel(ctx context.Context, query string, skillSlugs []string, rewardMin int32, status int32, companyID string, page, limit int32) (*models.MicroTaskList, error)
+	// DeleteProfile убирает профиль из индекса.
+	DeleteProfile(ctx context.Context, id string) error
+	// Reindex перестраивает индексы из сервисов-источников (recreate —
+	// пересоздать индексы с нуля). Долгий вызов: ctx без короткого таймаута.
+	Reindex(ctx context.Context, recreate bool) (*models.ReindexResult, error)
 }
 
 // MicroTaskService — обёртка над gRPC-клиентом микросервиса MicroTasks.
@@ -155,6 +215,9 @@ type ChatService interface {
 	EditMessage(ctx context.Context, messageID, fromUser, body string) (*models.ChatMessage, error)
 	HideThread(ctx context.Context, userID, threadID string) error
 	ListHiddenThreads(ctx context.Context, userID string) ([]string, error)
+	// EraseUserMessages обезличивает сообщения удалённого пользователя;
+	// возвращает, сколько сообщений изменено.
+	EraseUserMessages(ctx context.Context, userID string) (int, error)
 }
 
 // ApiGateway объединяет все сервисы
@@ -19,6 +19,8 @@ func convertRoleToGRPC(role string) (authv1.Role, error) {
 		return authv1.Role_ROLE_COMPANY_OWNER, nil
 	case "ROLE_EXPERT":
 		return authv1.Role_ROLE_EXPERT, nil
+	case "ROLE_ADMIN":
+		return authv1.Role_ROLE_ADMIN, nil
 	default:
 		return authv1.Role_ROLE_UNSPECIFIED, status.Error(codes.InvalidArgument, "invalid role")
 	}
@@ -37,7 +39,18 @@ func convertRoleFromGRPC(role authv1.Role) string {
 		return "ROLE_COMPANY_OWNER"
 	case authv1.Role_ROLE_EXPERT:
 		return "ROLE_EXPERT"
+	case authv1.Role_ROLE_ADMIN:
+		return "ROLE_ADMIN"
 	default:
 		return "ROLE_UNSPECIFIED"
 	}
 }
+
+// convertRolesFromGRPC конвертирует набор ролей аккаунта
+func convertRolesFromGRPC(roles []authv1.Role) []string {
+	out := make([]string, 0, len(roles))
+	for _, role := range roles {
+		out = append(out, convertRoleFromGRPC(role))
+	}
+	return out
+}
@@ -0,0 +1,80 @@
+package tracing
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/studjobs/hh_for_students/api-gateway"
+
+// Middleware — серверный спан на каждый HTTP-запрос. Контекст трассы клиента
+// (traceparent) продолжается, если он есть; контекст спана кладётся в
+// c.UserContext() — от него наследуют спаны gRPC-вызовов и Redis, поэтому
+// хендлеры передают в сервисы c.UserContext(). Ставится первым, чтобы спан
+// покрывал и rate limit, и аутентификацию.
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := otel.GetTextMapPropagator().Extract(c.UserContext(), headerCarrier{&c.Request().Header})
+		method := c.Method()
+		ctx, span := otel.Tracer(tracerName).Start(ctx, method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(method),
+				semconv.URLPath(c.Path()),
+			),
+		)
+		defer span.End()
+		c.SetUserContext(ctx)
+
+		err := c.Next()
+
+		// Маршрут известен только после роутинга: имя спана — шаблон
+		// (/api/v1/vacancy/:id), а не путь, чтобы не плодить имена.
+		if route := c.Route().Path; route != "" {
+			span.SetName(method + " " + route)
+			span.SetAttributes(semconv.HTTPRoute(route))
+		}
+		status := c.Response().StatusCode()
+		if err != nil {
+			// Ошибку в ответ превратит ErrorHandler уже после middleware.
+			status = fiber.StatusInternalServerError
+			var fe *fiber.Error
+			if errors.As(err, &fe) {
+				status = fe.Code
+			}
+			span.RecordError(err)
+		}
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, fasthttp.StatusMessage(status))
+		}
+		return err
+	}
+}
+
+// headerCarrier — заголовки запроса fasthttp для propagator.
+type headerCarrier struct {
+	h *fasthttp.RequestHeader
+}
+
+func (hc headerCarrier) Get(key string) string {
+	return string(hc.h.Peek(key))
+}
+
+func (hc headerCarrier) Set(key, value string) {
+	hc.h.Set(key, value)
+}
+
+func (hc headerCarrier) Keys() []string {
+	var keys []string
+	hc.h.VisitAll(func(key, _ []byte) {
+		keys = append(keys, string(key))
+	})
+	return keys
+}
@@ -0,0 +1,13 @@
+package tracing
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// Transport оборачивает HTTP-транспорт клиента (MinIO/S3) спанами запросов
+// и передаёт контекст трассы в заголовках.
+func Transport(base http.RoundTripper) http.RoundTripper {
+	return otelhttp.NewTransport(base)
+}
@@ -0,0 +1,16 @@
+package tracing
+
+import (
+	"log"
+
+	"github.com/redis/go-redis/extra/redisotel/v9"
+	"github.com/redis/go-redis/v9"
+)
+
+// InstrumentRedis добавляет спаны команд Redis (кэш, rate limit). Ошибка
+// трассировку Redis отключает, но не клиента.
+func InstrumentRedis(rdb *redis.Client) {
+	if err := redisotel.InstrumentTracing(rdb); err != nil {
+		log.Printf("⚠ redis tracing disabled: %v", err)
+	}
+}
@@ -15,6 +15,7 @@ import (
 
 	"github.com/studjobs/hh_for_students/api-gateway/internal/models"
 	"github.com/studjobs/hh_for_students/api-gateway/internal/services"
+	"github.com/studjobs/hh_for_students/api-gateway/internal/tracing"
 )
 
 type FileHandler struct {
@@ -102,7 +103,7 @@ func (fh *FileHandler) UploadFileDirect(
 	}
 
 	// Загружаем файл по presigned URL
-	err = fh.uploadToPresignedURL(uploadResponse.UploadURL, fileData, fileHeader.Header.Get("Content-Type"))
+	err = UploadToPresignedURL(ctx, uploadResponse.UploadURL, fileData, fileHeader.Header.Get("Content-Type"))
 	if err != nil {
 		log.Printf("UploadFileDirect: Failed to upload file to S3 for %s: %v", fileName, err)
 		return nil, err
@@ -129,35 +130,21 @@ func (fh *FileHandler) UploadFileDirect(
 	return fh.GetFileInfo(ctx, entityID, fileName, category)
 }
 
-// uploadToPresignedURL загружает файл по presigned URL.
+// UploadToPresignedURL загружает файл по presigned URL.
 // Presigned URL подписан под публичным host (например localhost:9000), потому
 // что тот же URL может уходить браузеру. Изнутри docker-сети localhost:9000
 // недоступен (loopback контейнера), поэтому подключаемся к internal host
 // (например minio:9000), но в Host header HTTP-запроса оставляем публичный —
 // AWS Sig V4 валидирует подпись против Host header, не против resolved IP.
-func (fh *FileHandler) uploadToPresignedURL(presignedURL string, fileData []byte, contentType string) error {
-	client := &http.Client{Timeout: 30 * time.Second}
-
-	parsed, err := url.Parse(presignedURL)
-	if err != nil {
-		return fmt.Errorf("invalid presigned URL: %w", err)
-	}
-	publicHost := parsed.Host
-
-	if internalHost := os.Getenv("MINIO_INTERNAL_ENDPOINT"); internalHost != "" {
-		parsed.Host = internalHost
-	}
-
-	req, err := http.NewRequest("PUT", parsed.String(), strings.NewReader(string(fileData)))
+func UploadToPresignedURL(ctx context.Context, presignedURL string, fileData []byte, contentType string) error {
+	req, err := newPresignedRequest(ctx, "PUT", presignedURL, strings.NewReader(string(fileData)))
 	if err != nil {
 		return err
 	}
-	// Host header сохраняем публичный — под него подписан URL.
-	req.Host = publicHost
 	req.Header.Set("Content-Type", contentType)
 	req.ContentLength = int64(len(fileData))
 
-	resp, err := client.Do(req)
+	resp, err := presignedClient.Do(req)
 	if err != nil {
 		return err
 	}
@@ -170,6 +157,54 @@ func (fh *FileHandler) uploadToPresignedURL(presignedURL string, fileData []byte
 	return nil
 }
 
+// DownloadFromPresignedURL скачивает файл по presigned GET URL — с той же
+// подменой host, что и UploadToPresignedURL.
+func DownloadFromPresignedURL(ctx context.Context, presignedURL string) ([]byte, error) {
+	req, err := newPresignedRequest(ctx, "GET", presignedURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := presignedClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download failed with status: %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+var presignedClient = &http.Client{
+	Timeout:   30 * time.Second,
+	Transport: tracing.Transport(http.DefaultTransport),
+}
+
+// newPresignedRequest — запрос к MinIO по presigned URL через internal host
+// (MINIO_INTERNAL_ENDPOINT) с публичным Host header, под который подписан URL.
+func newPresignedRequest(ctx context.Context, method, presignedURL string, body io.Reader) (*http.Request, error) {
+	parsed, err := url.Parse(presignedURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid presigned URL: %w", err)
+	}
+	publicHost := parsed.Host
+
+	if internalHost := os.Getenv("MINIO_INTERNAL_ENDPOINT"); internalHost != "" {
+		parsed.Host = internalHost
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, parsed.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	// Host header сохраняем публичный — под него подписан URL.
+	req.Host = publicHost
+	return req, nil
+}
+
 // detectFileType определяет тип файла по имени
 func (fh *FileHandler) detectFileType(filename string) string {
 	ext := strings.ToLower(filepath.Ext(filename))
@@ -6,17 +6,21 @@ import (
 	"github.com/spf13/viper"
 	"github.com/studjobs/hh_for_students/achievments/internal/handlers"
 	"github.com/studjobs/hh_for_students/achievments/internal/metrics"
+	"github.com/studjobs/hh_for_students/achievments/internal/mtls"
 	"github.com/studjobs/hh_for_students/achievments/internal/repository"
 	"github.com/studjobs/hh_for_students/achievments/internal/repository/DB"
 	"github.com/studjobs/hh_for_students/achievments/internal/service"
+	"github.com/studjobs/hh_for_students/achievments/internal/tracing"
 	"github.com/studjobs/hh_for_students/achievments/internal/usersclient"
 	"github.com/studjobs/hh_for_students/achievments/server"
 
+	"context"
 	"log"
 	"os"
 	"os/signal"
 	"strconv"
 	"syscall"
+	"time"
 )
 
 func main() {
@@ -30,6 +34,14 @@ func main() {
 		log.Printf("Предупреждение: ошибка загрузки .env файла: %s", err.Error())
 	}
 
+	// Трассировка — до клиентов и сервера: их инструментирование берёт
+	// глобальный TracerProvider при создании.
+	shutdownTracing, err := tracing.Init(context.Background(), mtls.Achievements)
+	if err != nil {
+		log.Fatalf("Ошибка инициализации трассировки: %s", err.Error())
+	}
+	defer shutdownTracing()
+
 	// Проверка обязательных переменных окружения
 	dbPassword := os.Getenv("DB_PASS")
 	if dbPassword == "" {
@@ -82,6 +94,14 @@ func main() {
 		log.Printf("✓ Public MinIO-клиент инициализирован для presigned GET: %s", publicEndpoint)
 	}
 
+	// Архивы выгрузки персональных данных хранятся ограниченное время.
+	exportCtx, exportCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	if err := DB.SetPrefixExpiration(exportCtx, minioClient, getEnv("MINIO_BUCKET", viper.GetString("minio.bucket")),
+		service.ExportPrefix, getEnvAsInt("EXPORT_RETENTION_DAYS", 7)); err != nil {
+		log.Printf("Предупреждение: архивы выгрузки не будут удаляться автоматически: %v", err)
+	}
+	exportCancel()
+
 	// Инициализация репозитория с зависимостями от БД и S3
 	repo := repository.NewRepository(db, minioClient, publicMinioClient)
 
@@ -107,7 +127,11 @@ func main() {
 	log.Printf("Запуск сервиса достижений на gRPC порту: %s", grpcPort)
 
 	// Инициализация и запуск gRPC сервера
-	grpcServer := server.New(grpcPort, handler)
+	tlsOpts, err := mtls.ServerOptions(mtls.ConfigFromEnv(), server.Policy)
+	if err != nil {
+		log.Fatalf("Failed to configure mTLS: %v", err)
+	}
+	grpcServer := server.New(grpcPort, tlsOpts, handler)
 
 	// Graceful shutdown
 	go func() {
@@ -158,3 +182,13 @@ func getEnvAsBool(key string, defaultValue bool) bool {
 	}
 	return defaultValue
 }
+
+// getEnvAsInt возвращает целое значение переменной окружения
+func getEnvAsInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if intValue, err := strconv.Atoi(value); err == nil {
+			return intValue
+		}
+	}
+	return defaultValue
+}
@@ -254,3 +254,36 @@ func (h *Handler) DeleteAchievement(ctx context.Context, req *achievementv1.Dele
 
 	return &commonv1.Empty{}, nil
 }
+
+// GetExportUploadUrl возвращает URL для загрузки архива выгрузки
+// персональных данных. Вызывает только Gateway, собирающий архив.
+func (h *Handler) GetExportUploadUrl(ctx context.Context, req *achievementv1.GetExportUrlRequest) (*achievementv1.UploadUrlResponse, error) {
+	log.Printf("Handler: GetExportUploadUrl called for user: %s, export: %s", req.GetUserUuid(), req.GetExportId())
+
+	url, s3Key, err := h.service.Achievement.GetExportUploadURL(ctx, req.GetUserUuid(), req.GetExportId())
+	if err != nil {
+		return nil, err
+	}
+
+	return &achievementv1.UploadUrlResponse{
+		UploadUrl: url,
+		S3Key:     s3Key,
+		ExpiresAt: time.Now().Add(30 * time.Minute).Unix(),
+	}, nil
+}
+
+// GetExportDownloadUrl возвращает ограниченную по времени ссылку на архив
+// выгрузки персональных данных.
+func (h *Handler) GetExportDownloadUrl(ctx context.Context, req *achievementv1.GetExportUrlRequest) (*achievementv1.AchievementUrl, error) {
+	log.Printf("Handler: GetExportDownloadUrl called for user: %s, export: %s", req.GetUserUuid(), req.GetExportId())
+
+	url, expiryMinutes, err := h.service.Achievement.GetExportDownloadURL(ctx, req.GetUserUuid(), req.GetExportId(), int64(req.GetExpiresInMinutes()))
+	if err != nil {
+		return nil, err
+	}
+
+	return &achievementv1.AchievementUrl{
+		Url:       url,
+		ExpiresAt: time.Now().Add(time.Duration(expiryMinutes) * time.Minute).Unix(),
+	}, nil
+}
@@ -6,10 +6,13 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/minio/minio-go/v7/pkg/lifecycle"
+	"github.com/studjobs/hh_for_students/achievments/internal/tracing"
 )
 
 // S3Config содержит конфигурацию для подключения к MinIO/S3
@@ -32,7 +35,7 @@ func NewMinioClient(config S3Config) (*minio.Client, error) {
 	minioClient, err := minio.New(config.Endpoint, &minio.Options{
 		Creds:     credentials.NewStaticV4(config.AccessKey, config.SecretKey, ""),
 		Secure:    config.UseSSL,
-		Transport: customTransport, // используем кастомный transport
+		Transport: tracing.Transport(customTransport), // используем кастомный transport
 	})
 	if err != nil {
 		return nil, fmt.Errorf("ошибка создания MinIO клиента: %w", err)
@@ -90,3 +93,26 @@ func NewPresignerClient(config S3Config) (*minio.Client, error) {
 	log.Printf("✓ Presigner MinIO-клиент создан для public host: %s", config.Endpoint)
 	return minioClient, nil
 }
+
+// SetPrefixExpiration настраивает правило жизненного цикла бакета: объекты с
+// префиксом prefix удаляются самим MinIO через days дней. Так истекают
+// архивы выгрузки персональных данных — отдельная очистка не нужна.
+// Правила бакета заменяются целиком: других правил у бакета нет.
+func SetPrefixExpiration(ctx context.Context, client *minio.Client, bucket, prefix string, days int) error {
+	config := lifecycle.NewConfiguration()
+	config.Rules = []lifecycle.Rule{
+		{
+			ID:         "expire-" + strings.TrimSuffix(prefix, "/"),
+			Status:     "Enabled",
+			RuleFilter: lifecycle.Filter{Prefix: prefix},
+			Expiration: lifecycle.Expiration{Days: lifecycle.ExpirationDays(days)},
+		},
+	}
+
+	if err := client.SetBucketLifecycle(ctx, bucket, config); err != nil {
+		return fmt.Errorf("ошибка настройки жизненного цикла бакета %s: %w", bucket, err)
+	}
+
+	log.Printf("✓ Объекты %s в бакете %s удаляются через %d дн.", prefix, bucket, days)
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/jackc/pgx/v4/stdlib"
 	"github.com/sirupsen/logrus"
+	"github.com/studjobs/hh_for_students/achievments/internal/tracing"
 	"os"
 	"path/filepath"
 	"strings"
@@ -31,7 +32,13 @@ func NewPostgres(cfg DBConfig) (*pgxpool.Pool, error) {
 		cfg.Host, cfg.Port, cfg.Username, cfg.Password, cfg.DBName, cfg.SSLMode)
 
 	// Подключаемся к базе данных через pgxpool
-	dbPool, err := pgxpool.Connect(context.Background(), strCfg)
+	poolCfg, err := pgxpool.ParseConfig(strCfg)
+	if err != nil {
+		return nil, fmt.Errorf("database config error: %w", err)
+	}
+	// Спаны SQL-запросов в трассе вызова (см. tracing.PgxLogger).
+	poolCfg.ConnConfig.Logger = tracing.PgxLogger{}
+	dbPool, err := pgxpool.ConnectConfig(context.Background(), poolCfg)
 	if err != nil {
 		return nil, fmt.Errorf("database connection error: %w", err)
 	}
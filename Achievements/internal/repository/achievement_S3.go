@@ -2,7 +2,9 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"net/url"
 	"os"
 	"time"
 
@@ -37,7 +39,7 @@ func NewS3Repository(client, publicClient *minio.Client) *S3Repository {
 // поэтому host должен быть browser-reachable. Используем publicClient — тот
 // же, что и для GET. Gateway, делающий PUT из docker-контейнера для avatar/
 // resume, подменяет host обратно на internal в момент connect, но Host header
-// шлёт public — это удовлетворяет AWS Sig V4 верификацию (см. uploadToPresignedURL).
+// шлёт public — это удовлетворяет AWS Sig V4 верификацию (см. UploadToPresignedURL).
 func (r *S3Repository) GenerateUploadURL(ctx context.Context, s3Key, fileType string, expiry int64) (string, error) {
 	log.Printf("S3Repository: Generating upload URL for key: %s, type: %s", s3Key, fileType)
 
@@ -69,6 +71,24 @@ func (r *S3Repository) GenerateDownloadURL(ctx context.Context, s3Key string, ex
 	return presignedURL.String(), nil
 }
 
+// GenerateAttachmentURL — presigned GET, по которому браузер скачивает файл
+// под именем fileName, а не открывает его (Content-Disposition: attachment).
+func (r *S3Repository) GenerateAttachmentURL(ctx context.Context, s3Key, fileName string, expiry int64) (string, error) {
+	log.Printf("S3Repository: Generating attachment URL for key: %s", s3Key)
+
+	params := url.Values{}
+	params.Set("response-content-disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+
+	expiryTime := time.Duration(expiry) * time.Minute
+	presignedURL, err := r.publicClient.PresignedGetObject(ctx, r.bucketName, s3Key, expiryTime, params)
+	if err != nil {
+		log.Printf("S3Repository: Failed to generate attachment URL for %s: %v", s3Key, err)
+		return "", status.Error(codes.Internal, "failed to generate download URL")
+	}
+
+	return presignedURL.String(), nil
+}
+
 // DeleteObject удаляет файл из S3 (internal-клиент).
 func (r *S3Repository) DeleteObject(ctx context.Context, s3Key string) error {
 	log.Printf("S3Repository: Deleting object with key: %s", s3Key)
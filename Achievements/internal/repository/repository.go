@@ -23,6 +23,7 @@ type Achievement interface {
 type S3 interface {
 	GenerateUploadURL(ctx context.Context, s3Key, fileType string, expiry int64) (string, error)
 	GenerateDownloadURL(ctx context.Context, s3Key string, expiry int64) (string, error)
+	GenerateAttachmentURL(ctx context.Context, s3Key, fileName string, expiry int64) (string, error)
 	DeleteObject(ctx context.Context, s3Key string) error
 	ObjectExists(ctx context.Context, s3Key string) (bool, error) // Добавлен новый метод
 }
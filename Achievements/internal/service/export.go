@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ExportPrefix — префикс архивов выгрузки персональных данных в бакете. Их
+// собирает Gateway; метаданных в БД у них нет, а удаляет их правило
+// жизненного цикла бакета (см. DB.SetPrefixExpiration).
+const ExportPrefix = "exports/"
+
+const (
+	exportUploadExpiryMinutes   = 30
+	defaultExportExpiryMinutes  = 60
+	maxExportDownloadExpiryMins = 7 * 24 * 60
+)
+
+// exportS3Key — архив выгрузки exportID пользователя userUUID.
+func exportS3Key(userUUID, exportID string) (string, error) {
+	if userUUID == "" || exportID == "" {
+		return "", status.Error(codes.InvalidArgument, "user_uuid and export_id are required")
+	}
+	if strings.ContainsAny(userUUID+exportID, "/\\") {
+		return "", status.Error(codes.InvalidArgument, "invalid user_uuid or export_id")
+	}
+	return ExportPrefix + userUUID + "/" + exportID + ".zip", nil
+}
+
+// GetExportUploadURL генерирует URL, по которому Gateway загружает готовый
+// архив выгрузки.
+func (s *AchievementService) GetExportUploadURL(ctx context.Context, userUUID, exportID string) (string, string, error) {
+	s3Key, err := exportS3Key(userUUID, exportID)
+	if err != nil {
+		return "", "", err
+	}
+
+	url, err := s.repo.S3.GenerateUploadURL(ctx, s3Key, "application/zip", exportUploadExpiryMinutes)
+	if err != nil {
+		return "", "", err
+	}
+
+	log.Printf("Service: Generated export upload URL for user %s, S3 key: %s", userUUID, s3Key)
+	return url, s3Key, nil
+}
+
+// GetExportDownloadURL генерирует ссылку на скачивание архива выгрузки,
+// действующую expiryMinutes минут (0 — по умолчанию). Архив, который уже
+// удалён правилом жизненного цикла, даёт NotFound.
+func (s *AchievementService) GetExportDownloadURL(ctx context.Context, userUUID, exportID string, expiryMinutes int64) (string, int64, error) {
+	s3Key, err := exportS3Key(userUUID, exportID)
+	if err != nil {
+		return "", 0, err
+	}
+	if expiryMinutes <= 0 {
+		expiryMinutes = defaultExportExpiryMinutes
+	}
+	expiryMinutes = min(expiryMinutes, maxExportDownloadExpiryMins)
+
+	exists, err := s.repo.S3.ObjectExists(ctx, s3Key)
+	if err != nil {
+		log.Printf("Service: Error checking export existence in S3: %v", err)
+		return "", 0, status.Error(codes.Internal, "error checking file")
+	}
+	if !exists {
+		return "", 0, status.Error(codes.NotFound, "export not found in storage")
+	}
+
+	url, err := s.repo.S3.GenerateAttachmentURL(ctx, s3Key, "export-"+exportID+".zip", expiryMinutes)
+	if err != nil {
+		return "", 0, err
+	}
+
+	log.Printf("Service: Generated export download URL for user %s, export %s", userUUID, exportID)
+	return url, expiryMinutes, nil
+}
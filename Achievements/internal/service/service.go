@@ -18,6 +18,8 @@ type Achievement interface {
 	ReviewAchievement(ctx context.Context, achievementID int64, reviewerUUID string, decision int32, comment string) (*repository.AchievementDB, error)
 	CreateMicrotaskAchievement(ctx context.Context, userUUID, microtaskID, microtaskTitle, solutionURL, reviewerUUID, reviewComment string) error
 	GetAchievement(ctx context.Context, achievementID int64) (*repository.AchievementDB, error)
+	GetExportUploadURL(ctx context.Context, userUUID, exportID string) (string, string, error)
+	GetExportDownloadURL(ctx context.Context, userUUID, exportID string, expiryMinutes int64) (string, int64, error)
 }
 
 // Service объединяет все сервисы
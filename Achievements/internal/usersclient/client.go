@@ -7,8 +7,9 @@ import (
 	"time"
 
 	usersv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/users/v1"
+	"github.com/studjobs/hh_for_students/achievments/internal/mtls"
+	"github.com/studjobs/hh_for_students/achievments/internal/tracing"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 const callTimeout = 5 * time.Second
@@ -23,7 +24,12 @@ func New(addr string) *Client {
 		log.Printf("usersclient (achievements): addr empty, AddVerifiedSkills disabled")
 		return &Client{}
 	}
-	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	creds, err := mtls.DialOption(mtls.ConfigFromEnv(), addr)
+	if err != nil {
+		log.Printf("usersclient (achievements): mTLS config: %v", err)
+		return &Client{}
+	}
+	conn, err := grpc.NewClient(addr, creds, tracing.DialOption())
 	if err != nil {
 		log.Printf("usersclient (achievements): dial %s failed: %v", addr, err)
 		return &Client{}
@@ -0,0 +1,15 @@
+package server
+
+import (
+	achievementv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/achievement/v1"
+	"github.com/studjobs/hh_for_students/achievments/internal/mtls"
+)
+
+// Policy — кто может вызывать методы сервиса при включённом mTLS.
+var Policy = mtls.Policy{
+	Default: []string{mtls.Gateway},
+	Methods: map[string][]string{
+		// Ачивка за микрозадачу выдаётся только при её approve.
+		achievementv1.AchievementService_CreateMicrotaskAchievement_FullMethodName: {mtls.MicroTasks},
+	},
+}
@@ -7,6 +7,7 @@ import (
 
 	achievementv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/achievement/v1"
 	"github.com/studjobs/hh_for_students/achievments/internal/metrics"
+	"github.com/studjobs/hh_for_students/achievments/internal/tracing"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/health"
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
@@ -19,8 +20,9 @@ type Server struct {
 	healthServer *health.Server
 }
 
-func New(port string, achievementService achievementv1.AchievementServiceServer) *Server {
-	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(metrics.UnaryInterceptor()))
+func New(port string, opts []grpc.ServerOption, achievementService achievementv1.AchievementServiceServer) *Server {
+	// Метрики — первыми, чтобы учитывать и отклонённые mTLS-политикой вызовы.
+	grpcServer := grpc.NewServer(append([]grpc.ServerOption{tracing.ServerOption(), grpc.ChainUnaryInterceptor(metrics.UnaryInterceptor())}, opts...)...)
 
 	// Регистрация сервисов
 	achievementv1.RegisterAchievementServiceServer(grpcServer, achievementService)
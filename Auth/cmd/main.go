@@ -1,16 +1,26 @@
 package main
 
 import (
+	"context"
+	authv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/auth/v1"
 	"github.com/joho/godotenv"
 	"github.com/spf13/viper"
+	"github.com/studjobs/hh_for_students/auth/internal/cleaner"
 	"github.com/studjobs/hh_for_students/auth/internal/handlers"
+	"github.com/studjobs/hh_for_students/auth/internal/mailer"
 	"github.com/studjobs/hh_for_students/auth/internal/metrics"
+	"github.com/studjobs/hh_for_students/auth/internal/mtls"
+	"github.com/studjobs/hh_for_students/auth/internal/oidc"
+	"github.com/studjobs/hh_for_students/auth/internal/password"
 	"github.com/studjobs/hh_for_students/auth/internal/repository"
 	"github.com/studjobs/hh_for_students/auth/server"
+	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/studjobs/hh_for_students/auth/internal/service"
+	"github.com/studjobs/hh_for_students/auth/internal/tracing"
 	"log"
 	"os"
 	"os/signal"
@@ -27,6 +37,14 @@ func main() {
 		log.Printf("warning: error loading .env file: %s", err.Error())
 	}
 
+	// Трассировка — до клиентов и сервера: их инструментирование берёт
+	// глобальный TracerProvider при создании.
+	shutdownTracing, err := tracing.Init(context.Background(), mtls.Auth)
+	if err != nil {
+		log.Fatalf("failed to initialize tracing: %s", err.Error())
+	}
+	defer shutdownTracing()
+
 	dbPassword := os.Getenv("DB_PASS")
 	if dbPassword == "" {
 		log.Fatal("DB_PASS environment variable is required")
@@ -49,22 +67,153 @@ func main() {
 	// Инициализация зависимостей
 	repo := repository.NewRepository(db)
 
+	// JWT_SECRET больше не подписывает токены (см. service/keys.go) и нужен только
+	// на переходный период, чтобы принять уже выданные HS256-токены.
 	jwtSecret := os.Getenv("JWT_SECRET")
 	if jwtSecret == "" {
-		log.Fatal("JWT_SECRET environment variable is required")
+		log.Printf("warning: JWT_SECRET not set, legacy HS256 tokens will be rejected")
 	}
-	timeDuration, err := strconv.Atoi(getEnv("JWT_TIME_DURATION", "60"))
+	// Access-токен короткий: продление идёт через refresh-токен (см. service/refresh.go).
+	timeDuration, err := strconv.Atoi(getEnv("JWT_TIME_DURATION", "15"))
 	if err != nil {
 		log.Fatalf("failed to parse JWT_TIME_DURATION: %s", err.Error())
 	}
+	refreshDuration, err := strconv.Atoi(getEnv("REFRESH_TOKEN_TTL_HOURS", "720"))
+	if err != nil {
+		log.Fatalf("failed to parse REFRESH_TOKEN_TTL_HOURS: %s", err.Error())
+	}
 
-	services := service.NewService(repo, service.JWTConfig{
-		SecretKey:     jwtSecret,
-		TokenDuration: time.Duration(timeDuration) * time.Minute,
+	keyRotation, err := strconv.Atoi(getEnv("JWT_KEY_ROTATION_HOURS", "720"))
+	if err != nil {
+		log.Fatalf("failed to parse JWT_KEY_ROTATION_HOURS: %s", err.Error())
+	}
+	keyPropagation, err := strconv.Atoi(getEnv("JWT_KEY_PROPAGATION_MINUTES", "10"))
+	if err != nil {
+		log.Fatalf("failed to parse JWT_KEY_PROPAGATION_MINUTES: %s", err.Error())
+	}
+
+	// Почта: MAILER_DRIVER=file (по умолчанию) пишет письма в MAIL_OUTBOX_DIR,
+	// smtp — отправляет через SMTP_HOST.
+	mail, err := mailer.New(mailer.Config{
+		Driver:       getEnv("MAILER_DRIVER", "file"),
+		From:         getEnv("MAIL_FROM", "no-reply@studjobs.ru"),
+		SMTPHost:     os.Getenv("SMTP_HOST"),
+		SMTPPort:     getEnv("SMTP_PORT", "587"),
+		SMTPUsername: os.Getenv("SMTP_USER"),
+		SMTPPassword: os.Getenv("SMTP_PASS"),
+		OutboxDir:    getEnv("MAIL_OUTBOX_DIR", "outbox"),
 	})
+	if err != nil {
+		log.Fatalf("failed to initialize mailer: %s", err.Error())
+	}
+	resetTTL, err := strconv.Atoi(getEnv("PASSWORD_RESET_TTL_MINUTES", "30"))
+	if err != nil {
+		log.Fatalf("failed to parse PASSWORD_RESET_TTL_MINUTES: %s", err.Error())
+	}
+	verifyTTL, err := strconv.Atoi(getEnv("EMAIL_VERIFY_TTL_HOURS", "48"))
+	if err != nil {
+		log.Fatalf("failed to parse EMAIL_VERIFY_TTL_HOURS: %s", err.Error())
+	}
+
+	// Защита от перебора паролей (см. service/lockout.go). Порог 0 отключает
+	// соответствующий счётчик.
+	lockout := service.LockoutConfig{
+		AccountThreshold: getEnvInt("LOGIN_LOCK_ACCOUNT_THRESHOLD", 5),
+		IPThreshold:      getEnvInt("LOGIN_LOCK_IP_THRESHOLD", 20),
+		BaseDelay:        time.Duration(getEnvInt("LOGIN_LOCK_BASE_SECONDS", 60)) * time.Second,
+		MaxDelay:         time.Duration(getEnvInt("LOGIN_LOCK_MAX_MINUTES", 60)) * time.Minute,
+		Window:           time.Duration(getEnvInt("LOGIN_ATTEMPT_WINDOW_MINUTES", 60)) * time.Minute,
+	}
+
+	// Второй фактор (см. service/mfa.go). MFA_REQUIRED_ROLES — роли через
+	// запятую (например ROLE_COMPANY_OWNER,ROLE_EXPERT), для которых TOTP
+	// обязателен.
+	mfa := service.MFAConfig{
+		Issuer:        getEnv("MFA_ISSUER", "StudJobs"),
+		RequiredRoles: parseRoles("MFA_REQUIRED_ROLES", os.Getenv("MFA_REQUIRED_ROLES")),
+		ChallengeTTL:  time.Duration(getEnvInt("MFA_CHALLENGE_TTL_MINUTES", 5)) * time.Minute,
+	}
+
+	// Вход через внешних провайдеров. OIDC_PROVIDERS — имена через запятую,
+	// настройки каждого — OIDC_<NAME>_* (см. .env_example).
+	oidcCfg := service.OIDCConfig{
+		Registry: oidc.NewRegistry(parseOIDCProviders(os.Getenv("OIDC_PROVIDERS")), &http.Client{Timeout: 10 * time.Second}),
+		StateTTL: time.Duration(getEnvInt("OIDC_STATE_TTL_MINUTES", 10)) * time.Minute,
+	}
+
+	// Пароли (см. internal/password): новые хешируются PASSWORD_HASH_ALGORITHM
+	// (argon2id или bcrypt), хеши другого алгоритма или со слабыми параметрами
+	// пересчитываются при входе.
+	passwords := service.PasswordConfig{
+		Hasher: newPasswordHasher(),
+		Policy: &password.Policy{
+			MinLength:      getEnvInt("PASSWORD_MIN_LENGTH", 8),
+			MaxLength:      getEnvInt("PASSWORD_MAX_LENGTH", 128),
+			MinCharClasses: getEnvInt("PASSWORD_MIN_CHAR_CLASSES", 1),
+		},
+	}
+	if getEnv("PASSWORD_BREACHED_CHECK", "true") == "true" {
+		breached := password.NewBreachedList()
+		if path := os.Getenv("PASSWORD_BREACHED_LIST"); path != "" {
+			if err := breached.LoadFile(path); err != nil {
+				log.Fatalf("failed to load PASSWORD_BREACHED_LIST: %s", err.Error())
+			}
+		}
+		log.Printf("Breached password list loaded, hashes: %d", breached.Size())
+		passwords.Policy.Breached = breached
+	}
+
+	// Ключи интеграций компаний (см. service/apikeys.go).
+	apiKeys := service.APIKeyConfig{
+		DefaultTTL:    time.Duration(getEnvInt("API_KEY_DEFAULT_TTL_DAYS", 90)) * 24 * time.Hour,
+		MaxTTL:        time.Duration(getEnvInt("API_KEY_MAX_TTL_DAYS", 365)) * 24 * time.Hour,
+		MaxPerCompany: getEnvInt("API_KEY_MAX_PER_COMPANY", 20),
+	}
+
+	services := service.NewService(repo, service.JWTConfig{
+		SecretKey:            jwtSecret,
+		TokenDuration:        time.Duration(timeDuration) * time.Minute,
+		RefreshTokenDuration: time.Duration(refreshDuration) * time.Hour,
+		KeyRotationInterval:  time.Duration(keyRotation) * time.Hour,
+		KeyPropagationDelay:  time.Duration(keyPropagation) * time.Minute,
+	}, mail, service.EmailConfig{
+		Reset: service.LinkConfig{
+			TokenDuration: time.Duration(resetTTL) * time.Minute,
+			URL:           getEnv("PASSWORD_RESET_URL", "http://localhost:3000/reset-password"),
+		},
+		Verification: service.LinkConfig{
+			TokenDuration: time.Duration(verifyTTL) * time.Hour,
+			URL:           getEnv("EMAIL_VERIFY_URL", "http://localhost:3000/verify-email"),
+		},
+	}, lockout, mfa, oidcCfg, passwords, apiKeys)
+
+	if err := services.Keys.Init(context.Background()); err != nil {
+		log.Fatalf("failed to initialize signing keys: %s", err.Error())
+	}
+
+	// Администраторы — ADMIN_EMAILS через запятую. Роль ROLE_ADMIN выдаётся
+	// только так: у аккаунтов не из списка она снимается при старте.
+	if err := services.Auth.SyncAdmins(context.Background(), parseEmails(os.Getenv("ADMIN_EMAILS"))); err != nil {
+		log.Printf("warning: failed to sync administrators: %v", err)
+	}
 
 	handler := handlers.NewAuthHandlers(services)
 
+	// Чистка истёкших записей об отзыве токенов и refresh-токенов.
+	cleanupMinutes, err := strconv.Atoi(getEnv("CLEANUP_INTERVAL_MINUTES", "60"))
+	if err != nil {
+		log.Fatalf("failed to parse CLEANUP_INTERVAL_MINUTES: %s", err.Error())
+	}
+	cleanCtx, cancelClean := context.WithCancel(context.Background())
+	defer cancelClean()
+	go cleaner.New(services, time.Duration(cleanupMinutes)*time.Minute).Run(cleanCtx)
+	// Синхронизация ключей с БД вдвое чаще задержки публикации: новый ключ,
+	// созданный соседним инстансом, должен попасть в память до активации.
+	go services.Keys.Run(cleanCtx, time.Duration(keyPropagation)*time.Minute/2)
+
+	// JWKS на HTTP-порту метрик: сервисы могут проверять токены без Auth.
+	metrics.Handle("/.well-known/jwks.json", handlers.JWKSHandler(services))
+
 	// Получаем порт из конфигурации - ИСПРАВЛЕНО!
 	grpcPort := getEnv("GRPC_PORT", viper.GetString("grpc.port"))
 	if grpcPort == "" {
@@ -77,7 +226,11 @@ func main() {
 	log.Printf("Starting Auth Service on gRPC port: %s", grpcPort)
 
 	// Запуск gRPC сервера
-	grpcServer := server.New(grpcPort, handler)
+	tlsOpts, err := mtls.ServerOptions(mtls.ConfigFromEnv(), server.Policy)
+	if err != nil {
+		log.Fatalf("Failed to configure mTLS: %v", err)
+	}
+	grpcServer := server.New(grpcPort, tlsOpts, handler)
 
 	// Graceful shutdown
 	go func() {
@@ -112,3 +265,100 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		log.Fatalf("failed to parse %s: %q is not a non-negative integer", key, value)
+	}
+	return n
+}
+
+// parseRoles разбирает список ролей через запятую (имена из authv1.Role).
+// newPasswordHasher — текущий алгоритм из PASSWORD_HASH_ALGORITHM, второй
+// остаётся для проверки уже сохранённых хешей.
+func newPasswordHasher() *password.Hasher {
+	argon := password.NewArgon2id(
+		uint32(getEnvInt("ARGON2_MEMORY_KIB", 19456)),
+		uint32(getEnvInt("ARGON2_ITERATIONS", 2)),
+		uint8(getEnvInt("ARGON2_PARALLELISM", 1)),
+	)
+	bcryptAlg := password.NewBcrypt(getEnvInt("BCRYPT_COST", 10))
+
+	switch algorithm := getEnv("PASSWORD_HASH_ALGORITHM", "argon2id"); algorithm {
+	case "argon2id":
+		return password.NewHasher(argon, bcryptAlg)
+	case "bcrypt":
+		return password.NewHasher(bcryptAlg, argon)
+	default:
+		log.Fatalf("unknown PASSWORD_HASH_ALGORITHM: %s", algorithm)
+		return nil
+	}
+}
+
+func parseRoles(key, value string) []authv1.Role {
+	var roles []authv1.Role
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		role, ok := authv1.Role_value[name]
+		if !ok || authv1.Role(role) == authv1.Role_ROLE_UNSPECIFIED {
+			log.Fatalf("failed to parse %s: unknown role %q", key, name)
+		}
+		roles = append(roles, authv1.Role(role))
+	}
+	return roles
+}
+
+// parseEmails разбирает список адресов через запятую.
+func parseEmails(value string) []string {
+	var emails []string
+	for _, email := range strings.Split(value, ",") {
+		if email = strings.TrimSpace(email); email != "" {
+			emails = append(emails, email)
+		}
+	}
+	return emails
+}
+
+// parseOIDCProviders читает настройки провайдеров из OIDC_<NAME>_*.
+func parseOIDCProviders(value string) []oidc.Config {
+	var cfgs []oidc.Config
+	for _, name := range strings.Split(value, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		cfg := oidc.Config{
+			Name:         name,
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  os.Getenv(prefix + "REDIRECT_URL"),
+			Issuer:       strings.TrimSuffix(os.Getenv(prefix+"ISSUER"), "/"),
+			AuthURL:      os.Getenv(prefix + "AUTH_URL"),
+			TokenURL:     os.Getenv(prefix + "TOKEN_URL"),
+			UserInfoURL:  os.Getenv(prefix + "USERINFO_URL"),
+			TrustEmail:   os.Getenv(prefix+"TRUST_EMAIL") == "true",
+		}
+		if cfg.ClientID == "" || cfg.RedirectURL == "" {
+			log.Fatalf("failed to configure oidc provider %s: %sCLIENT_ID and %sREDIRECT_URL are required", name, prefix, prefix)
+		}
+		if cfg.Issuer == "" && (cfg.AuthURL == "" || cfg.TokenURL == "" || cfg.UserInfoURL == "") {
+			log.Fatalf("failed to configure oidc provider %s: set %sISSUER or %sAUTH_URL, %sTOKEN_URL and %sUSERINFO_URL", name, prefix, prefix, prefix, prefix)
+		}
+		for _, scope := range strings.Split(os.Getenv(prefix+"SCOPES"), ",") {
+			if scope = strings.TrimSpace(scope); scope != "" {
+				cfg.Scopes = append(cfg.Scopes, scope)
+			}
+		}
+		cfgs = append(cfgs, cfg)
+	}
+	return cfgs
+}
@@ -0,0 +1,59 @@
+// Package cleaner — фоновый воркер Auth, который вычищает истёкшие записи
+// об отзыве токенов (user_logouts, revoked_tokens), refresh-токены и ключи
+// подписи, которыми уже нечего проверять.
+//
+// Записи нужны только пока жив соответствующий токен: после его exp подпись
+// и так не пройдёт проверку. Без чистки денилист рос бы бесконечно и
+// замедлял ValidateToken.
+package cleaner
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/studjobs/hh_for_students/auth/internal/service"
+)
+
+type Cleaner struct {
+	svc      *service.Service
+	interval time.Duration
+}
+
+func New(svc *service.Service, interval time.Duration) *Cleaner {
+	return &Cleaner{svc: svc, interval: interval}
+}
+
+// Run запускает цикл (блокирующий — вызывать в goroutine). interval <= 0 — отключено.
+func (c *Cleaner) Run(ctx context.Context) {
+	if c.interval <= 0 {
+		log.Printf("cleaner: disabled (interval=%v)", c.interval)
+		return
+	}
+	log.Printf("cleaner: starting loop, interval=%v", c.interval)
+
+	c.runOnce(ctx)
+
+	t := time.NewTicker(c.interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("cleaner: stopping")
+			return
+		case <-t.C:
+			c.runOnce(ctx)
+		}
+	}
+}
+
+func (c *Cleaner) runOnce(ctx context.Context) {
+	runCtx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	if err := c.svc.Auth.CleanupExpired(runCtx); err != nil {
+		log.Printf("cleaner: cleanup failed: %v", err)
+	}
+	if err := c.svc.Keys.Prune(runCtx); err != nil {
+		log.Printf("cleaner: signing key prune failed: %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	authv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/auth/v1"
+	"github.com/studjobs/hh_for_students/auth/internal/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ListAccounts — служебный RPC для сверки аккаунтов в Gateway; наружу через
+// HTTP не публикуется.
+func (h *AuthHandlers) ListAccounts(ctx context.Context, req *authv1.ListAccountsRequest) (*authv1.Accounts, error) {
+	var createdAfter time.Time
+	if req.CreatedAfter != "" {
+		var err error
+		if createdAfter, err = time.Parse(time.RFC3339, req.CreatedAfter); err != nil {
+			log.Printf("gRPC ListAccounts failed - invalid created_after: %s", req.CreatedAfter)
+			return nil, status.Error(codes.InvalidArgument, "created_after must be RFC3339")
+		}
+	}
+
+	accounts, next, err := h.service.Auth.ListAccounts(ctx, createdAfter, req.PageToken, int(req.Limit))
+	if err != nil {
+		log.Printf("gRPC ListAccounts failed: %v", err)
+		if errors.Is(err, service.ErrInvalidPageToken) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Error(codes.Internal, "internal server error")
+	}
+
+	return &authv1.Accounts{Accounts: accounts, NextPageToken: next}, nil
+}
+
+// ExportAccount — служебный RPC для выгрузки персональных данных: Gateway
+// вызывает его от имени уже аутентифицированного пользователя.
+func (h *AuthHandlers) ExportAccount(ctx context.Context, req *authv1.ExportAccountRequest) (*authv1.AccountExport, error) {
+	if req.UserUuid == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_uuid is required")
+	}
+
+	export, err := h.service.Auth.ExportAccount(ctx, req.UserUuid)
+	if err != nil {
+		log.Printf("gRPC ExportAccount failed for user %s: %v", req.UserUuid, err)
+		if errors.Is(err, service.ErrUserNotFound) {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+		return nil, status.Error(codes.Internal, "internal server error")
+	}
+
+	return export, nil
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	authv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/auth/v1"
+	"github.com/studjobs/hh_for_students/auth/internal/repository"
+	"github.com/studjobs/hh_for_students/auth/internal/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// reasonAccountBanned — причина в ErrorInfo: по ней Gateway отличает
+// заблокированный аккаунт от прочих отказов.
+const reasonAccountBanned = "ACCOUNT_BANNED"
+
+func bannedStatus() error {
+	return reasonStatus(codes.PermissionDenied, service.ErrAccountBanned.Error(), reasonAccountBanned)
+}
+
+// Служебные RPC администраторов (ROLE_ADMIN). Доступ к ним проверяет Gateway
+// (политика /api/v1/admin), наружу через HTTP напрямую они не публикуются.
+
+func (h *AuthHandlers) BanUser(ctx context.Context, req *authv1.BanUserRequest) (*authv1.Account, error) {
+	if req.AdminUuid == "" || req.UserUuid == "" {
+		return nil, status.Error(codes.InvalidArgument, "admin_uuid and user_uuid are required")
+	}
+
+	account, err := h.service.Auth.BanUser(ctx, req.AdminUuid, req.UserUuid, req.Reason)
+	if err != nil {
+		log.Printf("gRPC BanUser failed for user %s: %v", req.UserUuid, err)
+		return nil, adminStatus(err)
+	}
+	return account, nil
+}
+
+func (h *AuthHandlers) UnbanUser(ctx context.Context, req *authv1.UnbanUserRequest) (*authv1.Account, error) {
+	if req.UserUuid == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_uuid is required")
+	}
+
+	account, err := h.service.Auth.UnbanUser(ctx, req.UserUuid)
+	if err != nil {
+		log.Printf("gRPC UnbanUser failed for user %s: %v", req.UserUuid, err)
+		return nil, adminStatus(err)
+	}
+	return account, nil
+}
+
+// SearchAccounts — поиск аккаунтов по части email или uuid, роли и статусу
+// блокировки ("banned", "active" или пусто — любые).
+func (h *AuthHandlers) SearchAccounts(ctx context.Context, req *authv1.SearchAccountsRequest) (*authv1.AccountPage, error) {
+	filter := repository.UserFilter{Query: req.Query, Role: int(req.Role)}
+	switch req.Status {
+	case "":
+	case "banned", "active":
+		banned := req.Status == "banned"
+		filter.Banned = &banned
+	default:
+		return nil, status.Error(codes.InvalidArgument, "status must be banned or active")
+	}
+
+	accounts, total, err := h.service.Auth.SearchAccounts(ctx, filter, int(req.Page), int(req.Limit))
+	if err != nil {
+		log.Printf("gRPC SearchAccounts failed: %v", err)
+		return nil, adminStatus(err)
+	}
+	return &authv1.AccountPage{Accounts: accounts, Total: int32(total)}, nil
+}
+
+func (h *AuthHandlers) RecordAdminAction(ctx context.Context, req *authv1.RecordAdminActionRequest) (*authv1.AdminAction, error) {
+	action, err := h.service.Auth.RecordAdminAction(ctx, &repository.AdminAction{
+		AdminID:    req.AdminUuid,
+		Action:     req.Action,
+		TargetType: req.TargetType,
+		TargetID:   req.TargetId,
+		Details:    req.Details,
+		Status:     int(req.Status),
+		IP:         req.Ip,
+	})
+	if err != nil {
+		log.Printf("gRPC RecordAdminAction failed for %s by %s: %v", req.Action, req.AdminUuid, err)
+		return nil, adminStatus(err)
+	}
+	return action, nil
+}
+
+func (h *AuthHandlers) ListAdminActions(ctx context.Context, req *authv1.ListAdminActionsRequest) (*authv1.AdminActions, error) {
+	actions, total, err := h.service.Auth.ListAdminActions(ctx, repository.AdminActionFilter{
+		AdminID:    req.AdminUuid,
+		Action:     req.Action,
+		TargetType: req.TargetType,
+		TargetID:   req.TargetId,
+	}, int(req.Page), int(req.Limit))
+	if err != nil {
+		log.Printf("gRPC ListAdminActions failed: %v", err)
+		return nil, adminStatus(err)
+	}
+	return &authv1.AdminActions{Actions: actions, Total: int32(total)}, nil
+}
+
+// adminStatus переводит ошибки администраторских RPC в gRPC-статусы.
+func adminStatus(err error) error {
+	switch {
+	case errors.Is(err, service.ErrUserNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, service.ErrCannotBanAdmin):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, service.ErrInvalidAdminAction):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return status.Error(codes.Internal, "internal server error")
+	}
+}
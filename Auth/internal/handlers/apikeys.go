@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	authv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/auth/v1"
+	"github.com/studjobs/hh_for_students/auth/internal/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// apiKeyStatus переводит ошибки управления API-ключами в gRPC-статусы.
+func apiKeyStatus(err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidToken):
+		return status.Error(codes.Unauthenticated, "invalid token")
+	case errors.Is(err, service.ErrAPIKeyForbidden):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, service.ErrAPIKeyNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, service.ErrAPIKeyLimit):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, service.ErrInvalidAPIKeyScope),
+		errors.Is(err, service.ErrInvalidAPIKeyName),
+		errors.Is(err, service.ErrInvalidAPIKeyTTL):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return status.Error(codes.Internal, "internal server error")
+	}
+}
+
+func (h *AuthHandlers) CreateApiKey(ctx context.Context, req *authv1.CreateApiKeyRequest) (*authv1.ApiKeyCreated, error) {
+	log.Printf("gRPC CreateApiKey request - name: %s, scopes: %v", req.Name, req.Scopes)
+
+	if req.AccessToken == "" {
+		log.Printf("gRPC CreateApiKey failed - missing access token")
+		return nil, status.Error(codes.InvalidArgument, "access token is required")
+	}
+
+	apiKey, key, err := h.service.Auth.CreateAPIKey(ctx, req.AccessToken, req.Name, req.Scopes, int(req.ExpiresInDays))
+	if err != nil {
+		log.Printf("gRPC CreateApiKey failed: %v", err)
+		return nil, apiKeyStatus(err)
+	}
+	return &authv1.ApiKeyCreated{ApiKey: apiKey, Key: key}, nil
+}
+
+func (h *AuthHandlers) ListApiKeys(ctx context.Context, req *authv1.ListApiKeysRequest) (*authv1.ApiKeys, error) {
+	log.Printf("gRPC ListApiKeys request")
+
+	if req.AccessToken == "" {
+		log.Printf("gRPC ListApiKeys failed - missing access token")
+		return nil, status.Error(codes.InvalidArgument, "access token is required")
+	}
+
+	keys, err := h.service.Auth.ListAPIKeys(ctx, req.AccessToken)
+	if err != nil {
+		log.Printf("gRPC ListApiKeys failed: %v", err)
+		return nil, apiKeyStatus(err)
+	}
+	return &authv1.ApiKeys{ApiKeys: keys}, nil
+}
+
+func (h *AuthHandlers) RevokeApiKey(ctx context.Context, req *authv1.RevokeApiKeyRequest) (*authv1.RevokedApiKey, error) {
+	log.Printf("gRPC RevokeApiKey request - key: %s", req.KeyId)
+
+	if req.AccessToken == "" || req.KeyId == "" {
+		log.Printf("gRPC RevokeApiKey failed - missing required fields")
+		return nil, status.Error(codes.InvalidArgument, "access token and key id are required")
+	}
+
+	keyHash, err := h.service.Auth.RevokeAPIKey(ctx, req.AccessToken, req.KeyId)
+	if err != nil {
+		log.Printf("gRPC RevokeApiKey failed for key %s: %v", req.KeyId, err)
+		return nil, apiKeyStatus(err)
+	}
+	return &authv1.RevokedApiKey{KeyHash: keyHash}, nil
+}
+
+func (h *AuthHandlers) ValidateApiKey(ctx context.Context, req *authv1.ValidateApiKeyRequest) (*authv1.ApiKeyValidation, error) {
+	if req.Key == "" {
+		return nil, status.Error(codes.InvalidArgument, "key is required")
+	}
+
+	validation, err := h.service.Auth.ValidateAPIKey(ctx, req.Key)
+	if err != nil {
+		log.Printf("gRPC ValidateApiKey failed: %v", err)
+		return nil, status.Error(codes.Internal, "internal server error")
+	}
+	return validation, nil
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	authv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/auth/v1"
+	"github.com/studjobs/hh_for_students/auth/internal/repository"
+	"github.com/studjobs/hh_for_students/auth/internal/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Журнал аудита пополняет и читает Gateway: он знает, кто выполнил действие
+// (uuid, роль, IP) и как изменился ресурс. Кому какую часть журнала
+// показывать (владельцу — его компанию, администратору — всё), решает тоже он.
+
+func (h *AuthHandlers) RecordAuditEvent(ctx context.Context, req *authv1.RecordAuditEventRequest) (*authv1.AuditEvent, error) {
+	event, err := h.service.Auth.RecordAuditEvent(ctx, &repository.AuditEvent{
+		ActorID:      req.ActorUuid,
+		ActorRole:    req.ActorRole,
+		Action:       req.Action,
+		ResourceType: req.ResourceType,
+		ResourceID:   req.ResourceId,
+		CompanyID:    req.CompanyId,
+		Changes:      req.Changes,
+		IP:           req.Ip,
+	})
+	if err != nil {
+		log.Printf("gRPC RecordAuditEvent failed for %s by %s: %v", req.Action, req.ActorUuid, err)
+		return nil, auditStatus(err)
+	}
+	return event, nil
+}
+
+// ListAuditEvents — страница журнала. Since/Until — RFC3339, полуинтервал
+// [since, until); пустые не ограничивают.
+func (h *AuthHandlers) ListAuditEvents(ctx context.Context, req *authv1.ListAuditEventsRequest) (*authv1.AuditEvents, error) {
+	filter := repository.AuditFilter{
+		CompanyID:    req.CompanyId,
+		ActorID:      req.ActorUuid,
+		Action:       req.Action,
+		ResourceType: req.ResourceType,
+		ResourceID:   req.ResourceId,
+	}
+	var err error
+	if filter.Since, err = parseAuditTime(req.Since); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "since must be RFC3339")
+	}
+	if filter.Until, err = parseAuditTime(req.Until); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "until must be RFC3339")
+	}
+
+	events, total, err := h.service.Auth.ListAuditEvents(ctx, filter, int(req.Page), int(req.Limit))
+	if err != nil {
+		log.Printf("gRPC ListAuditEvents failed: %v", err)
+		return nil, auditStatus(err)
+	}
+	return &authv1.AuditEvents{Events: events, Total: int32(total)}, nil
+}
+
+func parseAuditTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+func auditStatus(err error) error {
+	if errors.Is(err, service.ErrInvalidAuditEvent) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return status.Error(codes.Internal, "internal server error")
+}
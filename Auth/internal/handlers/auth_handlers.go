@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	authv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/auth/v1"
 	commonv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/common/v1"
 	"github.com/studjobs/hh_for_students/auth/internal/service"
@@ -18,12 +19,18 @@ func (h *AuthHandlers) Login(ctx context.Context, req *authv1.LoginRequest) (*au
 		return nil, status.Error(codes.InvalidArgument, "email, password and role are required")
 	}
 
-	authResponse, err := h.service.Auth.AuthenticateUser(ctx, req.Email, req.Password, req.Role)
+	authResponse, err := h.service.Auth.AuthenticateUser(ctx, req.Email, req.Password, req.Role, clientIPFromContext(ctx))
 	if err != nil {
 		log.Printf("gRPC Login failed for email %s: %v", req.Email, err)
+		var locked *service.LockedError
+		if errors.As(err, &locked) {
+			return nil, lockedStatus(locked)
+		}
 		switch err {
 		case service.ErrInvalidCredentials:
 			return nil, status.Error(codes.Unauthenticated, "invalid email or password")
+		case service.ErrAccountBanned:
+			return nil, bannedStatus()
 		default:
 			return nil, status.Error(codes.Internal, "internal server error")
 		}
@@ -41,17 +48,23 @@ func (h *AuthHandlers) SignUp(ctx context.Context, req *authv1.SignUpRequest) (*
 		return nil, status.Error(codes.InvalidArgument, "email, password and role are required")
 	}
 
-	if len(req.Password) < 6 {
-		log.Printf("gRPC SignUp failed - password too short for email: %s", req.Email)
-		return nil, status.Error(codes.InvalidArgument, "password must be at least 6 characters")
-	}
-
 	authResponse, err := h.service.Auth.RegisterUser(ctx, req.Email, req.Password, req.Role)
 	if err != nil {
 		log.Printf("gRPC SignUp failed for email %s: %v", req.Email, err)
+		var locked *service.LockedError
+		if errors.As(err, &locked) {
+			return nil, lockedStatus(locked)
+		}
+		if st := passwordStatus(err); st != nil {
+			return nil, st
+		}
 		switch err {
 		case service.ErrUserAlreadyExists:
 			return nil, status.Error(codes.AlreadyExists, "user with this email already exists")
+		case service.ErrRoleNotSelfService:
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		case service.ErrAccountBanned:
+			return nil, bannedStatus()
 		default:
 			return nil, status.Error(codes.Internal, "internal server error")
 		}
@@ -90,9 +103,191 @@ func (h *AuthHandlers) Delete(ctx context.Context, req *authv1.DeleteRequest) (*
 	err := h.service.Auth.DeleteUser(ctx, req.UserUuid)
 	if err != nil {
 		log.Printf("gRPC Delete failed for user %s: %v", req.UserUuid, err)
+		if errors.Is(err, service.ErrUserNotFound) {
+			return &commonv1.Empty{}, status.Error(codes.NotFound, "user not found")
+		}
 		return &commonv1.Empty{}, status.Error(codes.Internal, "failed to delete user")
 	}
 
 	log.Printf("gRPC Delete successful for user: %s", req.UserUuid)
 	return &commonv1.Empty{}, nil
 }
+
+func (h *AuthHandlers) Refresh(ctx context.Context, req *authv1.RefreshRequest) (*authv1.AuthResponse, error) {
+	log.Printf("gRPC Refresh request")
+
+	if req.RefreshToken == "" {
+		log.Printf("gRPC Refresh failed - empty refresh token")
+		return nil, status.Error(codes.InvalidArgument, "refresh token is required")
+	}
+
+	authResponse, err := h.service.Auth.RefreshToken(ctx, req.RefreshToken)
+	if err != nil {
+		log.Printf("gRPC Refresh failed: %v", err)
+		switch err {
+		case service.ErrInvalidRefreshToken, service.ErrRefreshTokenReused:
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		case service.ErrAccountBanned:
+			return nil, bannedStatus()
+		default:
+			return nil, status.Error(codes.Internal, "internal server error")
+		}
+	}
+
+	log.Printf("gRPC Refresh successful for user_uuid: %s", authResponse.UserUuid)
+	return authResponse, nil
+}
+
+func (h *AuthHandlers) Logout(ctx context.Context, req *authv1.LogoutRequest) (*commonv1.Empty, error) {
+	log.Printf("gRPC Logout request - all_sessions: %t", req.AllSessions)
+
+	if req.AccessToken == "" {
+		log.Printf("gRPC Logout failed - empty access token")
+		return &commonv1.Empty{}, status.Error(codes.InvalidArgument, "access token is required")
+	}
+
+	err := h.service.Auth.Logout(ctx, req.AccessToken, req.RefreshToken, req.AllSessions)
+	if err != nil {
+		log.Printf("gRPC Logout failed: %v", err)
+		switch err {
+		case service.ErrInvalidToken:
+			return &commonv1.Empty{}, status.Error(codes.Unauthenticated, "invalid access token")
+		default:
+			return &commonv1.Empty{}, status.Error(codes.Internal, "failed to logout")
+		}
+	}
+
+	log.Printf("gRPC Logout successful")
+	return &commonv1.Empty{}, nil
+}
+
+func (h *AuthHandlers) SwitchRole(ctx context.Context, req *authv1.SwitchRoleRequest) (*authv1.AuthResponse, error) {
+	log.Printf("gRPC SwitchRole request - role: %v", req.Role)
+
+	if req.AccessToken == "" || req.Role == authv1.Role_ROLE_UNSPECIFIED {
+		log.Printf("gRPC SwitchRole failed - missing required fields")
+		return nil, status.Error(codes.InvalidArgument, "access token and role are required")
+	}
+
+	authResponse, err := h.service.Auth.SwitchRole(ctx, req.AccessToken, req.RefreshToken, req.Role)
+	if err != nil {
+		log.Printf("gRPC SwitchRole failed: %v", err)
+		switch err {
+		case service.ErrInvalidToken:
+			return nil, status.Error(codes.Unauthenticated, "invalid access token")
+		case service.ErrRoleNotAssigned:
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		default:
+			return nil, status.Error(codes.Internal, "failed to switch role")
+		}
+	}
+
+	log.Printf("gRPC SwitchRole successful for user_uuid: %s", authResponse.UserUuid)
+	return authResponse, nil
+}
+
+func (h *AuthHandlers) RequestPasswordReset(ctx context.Context, req *authv1.PasswordResetRequest) (*commonv1.Empty, error) {
+	log.Printf("gRPC RequestPasswordReset request - email: %s", req.Email)
+
+	if req.Email == "" {
+		log.Printf("gRPC RequestPasswordReset failed - empty email")
+		return &commonv1.Empty{}, status.Error(codes.InvalidArgument, "email is required")
+	}
+
+	if err := h.service.Auth.RequestPasswordReset(ctx, req.Email); err != nil {
+		log.Printf("gRPC RequestPasswordReset failed for email %s: %v", req.Email, err)
+		return &commonv1.Empty{}, status.Error(codes.Internal, "failed to request password reset")
+	}
+
+	return &commonv1.Empty{}, nil
+}
+
+func (h *AuthHandlers) ConfirmPasswordReset(ctx context.Context, req *authv1.PasswordResetConfirm) (*commonv1.Empty, error) {
+	log.Printf("gRPC ConfirmPasswordReset request")
+
+	if req.Token == "" || req.NewPassword == "" {
+		log.Printf("gRPC ConfirmPasswordReset failed - missing required fields")
+		return &commonv1.Empty{}, status.Error(codes.InvalidArgument, "token and new password are required")
+	}
+
+	err := h.service.Auth.ConfirmPasswordReset(ctx, req.Token, req.NewPassword)
+	if err != nil {
+		log.Printf("gRPC ConfirmPasswordReset failed: %v", err)
+		if st := passwordStatus(err); st != nil {
+			return &commonv1.Empty{}, st
+		}
+		switch err {
+		case service.ErrInvalidResetToken:
+			return &commonv1.Empty{}, status.Error(codes.Unauthenticated, err.Error())
+		default:
+			return &commonv1.Empty{}, status.Error(codes.Internal, "failed to reset password")
+		}
+	}
+
+	log.Printf("gRPC ConfirmPasswordReset successful")
+	return &commonv1.Empty{}, nil
+}
+
+func (h *AuthHandlers) ConfirmEmail(ctx context.Context, req *authv1.ConfirmEmailRequest) (*commonv1.Empty, error) {
+	log.Printf("gRPC ConfirmEmail request")
+
+	if req.Token == "" {
+		log.Printf("gRPC ConfirmEmail failed - empty token")
+		return &commonv1.Empty{}, status.Error(codes.InvalidArgument, "token is required")
+	}
+
+	err := h.service.Auth.ConfirmEmail(ctx, req.Token)
+	if err != nil {
+		log.Printf("gRPC ConfirmEmail failed: %v", err)
+		switch err {
+		case service.ErrInvalidVerificationToken:
+			return &commonv1.Empty{}, status.Error(codes.Unauthenticated, err.Error())
+		default:
+			return &commonv1.Empty{}, status.Error(codes.Internal, "failed to confirm email")
+		}
+	}
+
+	log.Printf("gRPC ConfirmEmail successful")
+	return &commonv1.Empty{}, nil
+}
+
+func (h *AuthHandlers) ResendVerification(ctx context.Context, req *authv1.ResendVerificationRequest) (*commonv1.Empty, error) {
+	log.Printf("gRPC ResendVerification request - userID: %s", req.UserUuid)
+
+	if req.UserUuid == "" {
+		log.Printf("gRPC ResendVerification failed - empty user uuid")
+		return &commonv1.Empty{}, status.Error(codes.InvalidArgument, "user uuid is required")
+	}
+
+	err := h.service.Auth.RequestEmailVerification(ctx, req.UserUuid)
+	if err != nil {
+		log.Printf("gRPC ResendVerification failed for user %s: %v", req.UserUuid, err)
+		switch err {
+		case service.ErrUserNotFound:
+			return &commonv1.Empty{}, status.Error(codes.NotFound, "user not found")
+		case service.ErrEmailAlreadyVerified:
+			return &commonv1.Empty{}, status.Error(codes.FailedPrecondition, err.Error())
+		default:
+			return &commonv1.Empty{}, status.Error(codes.Internal, "failed to send verification email")
+		}
+	}
+
+	return &commonv1.Empty{}, nil
+}
+
+func (h *AuthHandlers) UnlockAccount(ctx context.Context, req *authv1.UnlockAccountRequest) (*commonv1.Empty, error) {
+	log.Printf("gRPC UnlockAccount request - email: %s", req.Email)
+
+	if req.Email == "" {
+		log.Printf("gRPC UnlockAccount failed - empty email")
+		return &commonv1.Empty{}, status.Error(codes.InvalidArgument, "email is required")
+	}
+
+	if err := h.service.Auth.UnlockAccount(ctx, req.Email); err != nil {
+		log.Printf("gRPC UnlockAccount failed for email %s: %v", req.Email, err)
+		return &commonv1.Empty{}, status.Error(codes.Internal, "failed to unlock account")
+	}
+
+	log.Printf("gRPC UnlockAccount successful for email: %s", req.Email)
+	return &commonv1.Empty{}, nil
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	authv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/auth/v1"
+	commonv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/common/v1"
+	"github.com/studjobs/hh_for_students/auth/internal/service"
+)
+
+// GetJWKS отдаёт публичные ключи подписи для офлайн-проверки токенов.
+func (h *AuthHandlers) GetJWKS(ctx context.Context, _ *commonv1.Empty) (*authv1.JWKS, error) {
+	doc := h.service.Keys.JWKS()
+
+	resp := &authv1.JWKS{Keys: make([]*authv1.JWK, 0, len(doc.Keys))}
+	for _, k := range doc.Keys {
+		resp.Keys = append(resp.Keys, &authv1.JWK{
+			Kty: k.Kty,
+			Crv: k.Crv,
+			X:   k.X,
+			Kid: k.Kid,
+			Alg: k.Alg,
+			Use: k.Use,
+		})
+	}
+
+	log.Printf("gRPC GetJWKS - keys: %d", len(resp.Keys))
+	return resp, nil
+}
+
+// JWKSHandler — тот же документ по HTTP (/.well-known/jwks.json) для клиентов без gRPC.
+func JWKSHandler(svc *service.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		// Короткий кэш: новый ключ публикуется за KeyPropagationDelay до активации.
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		if err := json.NewEncoder(w).Encode(svc.Keys.JWKS()); err != nil {
+			log.Printf("JWKS encode failed: %v", err)
+		}
+	})
+}
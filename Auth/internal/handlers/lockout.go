@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"github.com/studjobs/hh_for_students/auth/internal/service"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// clientIPMetadataKey — адрес конечного клиента, который проставляет Gateway.
+// Сам Auth видит только адрес Gateway.
+const clientIPMetadataKey = "x-client-ip"
+
+// Причины в ErrorInfo: по ним Gateway отличает блокировку аккаунта (423) от
+// ограничения по IP (429).
+const (
+	reasonAccountLocked = "ACCOUNT_LOCKED"
+	reasonIPThrottled   = "IP_THROTTLED"
+)
+
+func clientIPFromContext(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if v := md.Get(clientIPMetadataKey); len(v) > 0 {
+		return strings.TrimSpace(v[0])
+	}
+	return ""
+}
+
+// lockedStatus — ResourceExhausted с RetryInfo (через сколько повторить) и
+// ErrorInfo (что заблокировано).
+func lockedStatus(locked *service.LockedError) error {
+	reason := reasonIPThrottled
+	if locked.Scope == service.LockScopeAccount {
+		reason = reasonAccountLocked
+	}
+
+	st := status.New(codes.ResourceExhausted, "too many failed login attempts")
+	withDetails, err := st.WithDetails(
+		&errdetails.ErrorInfo{Reason: reason, Domain: "auth"},
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(locked.RetryAfter)},
+	)
+	if err != nil {
+		log.Printf("failed to attach lockout details: %v", err)
+		return st.Err()
+	}
+	return withDetails.Err()
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	authv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/auth/v1"
+	commonv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/common/v1"
+	"github.com/studjobs/hh_for_students/auth/internal/service"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Причины в ErrorInfo: неверный код можно ввести ещё раз, а с протухшим
+// mfa-токеном клиенту нужно начинать вход заново.
+const (
+	reasonInvalidMFAToken = "INVALID_MFA_TOKEN"
+	reasonInvalidMFACode  = "INVALID_MFA_CODE"
+)
+
+func reasonStatus(code codes.Code, msg, reason string) error {
+	st := status.New(code, msg)
+	withDetails, err := st.WithDetails(&errdetails.ErrorInfo{Reason: reason, Domain: "auth"})
+	if err != nil {
+		log.Printf("failed to attach error details: %v", err)
+		return st.Err()
+	}
+	return withDetails.Err()
+}
+
+// mfaStatus переводит ошибки второго фактора в gRPC-статусы.
+func mfaStatus(err error) error {
+	var locked *service.LockedError
+	if errors.As(err, &locked) {
+		return lockedStatus(locked)
+	}
+	switch err {
+	case service.ErrInvalidMFAToken:
+		return reasonStatus(codes.Unauthenticated, err.Error(), reasonInvalidMFAToken)
+	case service.ErrInvalidMFACode:
+		return reasonStatus(codes.Unauthenticated, err.Error(), reasonInvalidMFACode)
+	case service.ErrMFAAlreadyEnabled, service.ErrMFANotEnabled, service.ErrMFARequired:
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case service.ErrUserNotFound:
+		return status.Error(codes.NotFound, err.Error())
+	case service.ErrAccountBanned:
+		return bannedStatus()
+	default:
+		return status.Error(codes.Internal, "internal server error")
+	}
+}
+
+func (h *AuthHandlers) VerifyMFA(ctx context.Context, req *authv1.VerifyMFARequest) (*authv1.AuthResponse, error) {
+	log.Printf("gRPC VerifyMFA request")
+
+	if req.MfaToken == "" || req.Code == "" {
+		log.Printf("gRPC VerifyMFA failed - missing required fields")
+		return nil, status.Error(codes.InvalidArgument, "mfa token and code are required")
+	}
+
+	authResponse, err := h.service.Auth.VerifyMFA(ctx, req.MfaToken, req.Code, clientIPFromContext(ctx))
+	if err != nil {
+		log.Printf("gRPC VerifyMFA failed: %v", err)
+		return nil, mfaStatus(err)
+	}
+
+	log.Printf("gRPC VerifyMFA successful for user_uuid: %s", authResponse.UserUuid)
+	return authResponse, nil
+}
+
+func (h *AuthHandlers) EnrollTOTP(ctx context.Context, req *authv1.EnrollTOTPRequest) (*authv1.TOTPEnrollment, error) {
+	log.Printf("gRPC EnrollTOTP request - user_uuid: %s", req.UserUuid)
+
+	if req.UserUuid == "" && req.MfaToken == "" {
+		log.Printf("gRPC EnrollTOTP failed - missing user")
+		return nil, status.Error(codes.InvalidArgument, "user uuid or mfa token is required")
+	}
+
+	enrollment, err := h.service.Auth.EnrollTOTP(ctx, req.UserUuid, req.MfaToken)
+	if err != nil {
+		log.Printf("gRPC EnrollTOTP failed: %v", err)
+		return nil, mfaStatus(err)
+	}
+	return enrollment, nil
+}
+
+func (h *AuthHandlers) ConfirmTOTP(ctx context.Context, req *authv1.ConfirmTOTPRequest) (*authv1.TOTPConfirmation, error) {
+	log.Printf("gRPC ConfirmTOTP request - user_uuid: %s", req.UserUuid)
+
+	if (req.UserUuid == "" && req.MfaToken == "") || req.Code == "" {
+		log.Printf("gRPC ConfirmTOTP failed - missing required fields")
+		return nil, status.Error(codes.InvalidArgument, "user uuid or mfa token, and code are required")
+	}
+
+	confirmation, err := h.service.Auth.ConfirmTOTP(ctx, req.UserUuid, req.MfaToken, req.Code)
+	if err != nil {
+		log.Printf("gRPC ConfirmTOTP failed: %v", err)
+		return nil, mfaStatus(err)
+	}
+	return confirmation, nil
+}
+
+func (h *AuthHandlers) DisableTOTP(ctx context.Context, req *authv1.DisableTOTPRequest) (*commonv1.Empty, error) {
+	log.Printf("gRPC DisableTOTP request - user_uuid: %s", req.UserUuid)
+
+	if req.UserUuid == "" || req.Code == "" {
+		log.Printf("gRPC DisableTOTP failed - missing required fields")
+		return &commonv1.Empty{}, status.Error(codes.InvalidArgument, "user uuid and code are required")
+	}
+
+	if err := h.service.Auth.DisableTOTP(ctx, req.UserUuid, req.Code, clientIPFromContext(ctx)); err != nil {
+		log.Printf("gRPC DisableTOTP failed for user %s: %v", req.UserUuid, err)
+		return &commonv1.Empty{}, mfaStatus(err)
+	}
+	return &commonv1.Empty{}, nil
+}
+
+func (h *AuthHandlers) RegenerateRecoveryCodes(ctx context.Context, req *authv1.RegenerateRecoveryCodesRequest) (*authv1.RecoveryCodes, error) {
+	log.Printf("gRPC RegenerateRecoveryCodes request - user_uuid: %s", req.UserUuid)
+
+	if req.UserUuid == "" || req.Code == "" {
+		log.Printf("gRPC RegenerateRecoveryCodes failed - missing required fields")
+		return nil, status.Error(codes.InvalidArgument, "user uuid and code are required")
+	}
+
+	recoveryCodes, err := h.service.Auth.RegenerateRecoveryCodes(ctx, req.UserUuid, req.Code, clientIPFromContext(ctx))
+	if err != nil {
+		log.Printf("gRPC RegenerateRecoveryCodes failed for user %s: %v", req.UserUuid, err)
+		return nil, mfaStatus(err)
+	}
+	return &authv1.RecoveryCodes{Codes: recoveryCodes}, nil
+}
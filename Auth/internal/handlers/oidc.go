@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	authv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/auth/v1"
+	commonv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/common/v1"
+	"github.com/studjobs/hh_for_students/auth/internal/oidc"
+	"github.com/studjobs/hh_for_students/auth/internal/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// oidcStatus переводит ошибки входа через провайдера в gRPC-статусы.
+func oidcStatus(err error) error {
+	switch {
+	case errors.Is(err, oidc.ErrUnknownProvider):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, service.ErrInvalidOIDCState),
+		errors.Is(err, oidc.ErrExchangeFailed),
+		errors.Is(err, oidc.ErrInvalidIDToken):
+		return status.Error(codes.Unauthenticated, "sign-in with provider failed")
+	case errors.Is(err, service.ErrOIDCEmailNotVerified):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, service.ErrIdentityLinked):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, service.ErrRoleNotAssigned),
+		errors.Is(err, service.ErrRoleNotSelfService):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, service.ErrAccountBanned):
+		return bannedStatus()
+	case errors.Is(err, service.ErrUserNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	default:
+		return status.Error(codes.Internal, "internal server error")
+	}
+}
+
+func (h *AuthHandlers) ListOIDCProviders(ctx context.Context, _ *commonv1.Empty) (*authv1.OIDCProviders, error) {
+	return &authv1.OIDCProviders{Providers: h.service.Auth.ListOIDCProviders(ctx)}, nil
+}
+
+func (h *AuthHandlers) StartOIDC(ctx context.Context, req *authv1.StartOIDCRequest) (*authv1.OIDCAuthorization, error) {
+	log.Printf("gRPC StartOIDC request - provider: %s, role: %v", req.Provider, req.Role)
+
+	if req.Provider == "" {
+		log.Printf("gRPC StartOIDC failed - missing provider")
+		return nil, status.Error(codes.InvalidArgument, "provider is required")
+	}
+
+	authURL, state, err := h.service.Auth.StartOIDC(ctx, req.Provider, req.Role, req.LinkUserUuid)
+	if err != nil {
+		log.Printf("gRPC StartOIDC failed for provider %s: %v", req.Provider, err)
+		return nil, oidcStatus(err)
+	}
+	return &authv1.OIDCAuthorization{AuthorizationUrl: authURL, State: state}, nil
+}
+
+func (h *AuthHandlers) CompleteOIDC(ctx context.Context, req *authv1.CompleteOIDCRequest) (*authv1.OIDCLogin, error) {
+	log.Printf("gRPC CompleteOIDC request - provider: %s", req.Provider)
+
+	if req.Provider == "" || req.State == "" || req.Code == "" {
+		log.Printf("gRPC CompleteOIDC failed - missing required fields")
+		return nil, status.Error(codes.InvalidArgument, "provider, state and code are required")
+	}
+
+	authResponse, email, err := h.service.Auth.CompleteOIDC(ctx, req.Provider, req.State, req.Code)
+	if err != nil {
+		log.Printf("gRPC CompleteOIDC failed for provider %s: %v", req.Provider, err)
+		return nil, oidcStatus(err)
+	}
+
+	log.Printf("gRPC CompleteOIDC successful for user_uuid: %s", authResponse.UserUuid)
+	return &authv1.OIDCLogin{Auth: authResponse, Email: email}, nil
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+
+	"github.com/studjobs/hh_for_students/auth/internal/password"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Причины в ErrorInfo для отклонённого пароля: Gateway отдаёт их клиенту
+// как код ошибки, а пояснение — в сообщении статуса.
+const (
+	reasonWeakPassword     = "WEAK_PASSWORD"
+	reasonBreachedPassword = "BREACHED_PASSWORD"
+)
+
+// passwordStatus — InvalidArgument с причиной, если пароль не прошёл
+// политику; nil для прочих ошибок.
+func passwordStatus(err error) error {
+	var reason string
+	switch {
+	case errors.Is(err, password.ErrWeakPassword):
+		reason = reasonWeakPassword
+	case errors.Is(err, password.ErrBreachedPassword):
+		reason = reasonBreachedPassword
+	default:
+		return nil
+	}
+
+	st := status.New(codes.InvalidArgument, err.Error())
+	withDetails, detailsErr := st.WithDetails(&errdetails.ErrorInfo{Reason: reason, Domain: "auth"})
+	if detailsErr != nil {
+		log.Printf("failed to attach password policy details: %v", detailsErr)
+		return st.Err()
+	}
+	return withDetails.Err()
+}
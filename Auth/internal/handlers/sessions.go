@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+
+	authv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/auth/v1"
+	commonv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/common/v1"
+	"github.com/studjobs/hh_for_students/auth/internal/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// userAgentMetadataKey — User-Agent конечного клиента, который проставляет Gateway.
+const userAgentMetadataKey = "x-user-agent"
+
+// ClientInfoInterceptor переносит адрес и User-Agent клиента из метаданных
+// Gateway в контекст: из них сервис заполняет сессию, какой бы RPC её ни
+// создал (вход, регистрация, второй фактор, смена роли, провайдер).
+func ClientInfoInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	client := service.ClientInfo{IP: clientIPFromContext(ctx)}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get(userAgentMetadataKey); len(v) > 0 {
+			client.UserAgent = strings.TrimSpace(v[0])
+		}
+	}
+	return handler(service.WithClientInfo(ctx, client), req)
+}
+
+// sessionStatus переводит ошибки управления сессиями в gRPC-статусы.
+func sessionStatus(err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidToken):
+		return status.Error(codes.Unauthenticated, "invalid token")
+	case errors.Is(err, service.ErrSessionNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	default:
+		return status.Error(codes.Internal, "internal server error")
+	}
+}
+
+func (h *AuthHandlers) ListSessions(ctx context.Context, req *authv1.ListSessionsRequest) (*authv1.Sessions, error) {
+	log.Printf("gRPC ListSessions request")
+
+	if req.AccessToken == "" {
+		log.Printf("gRPC ListSessions failed - missing access token")
+		return nil, status.Error(codes.InvalidArgument, "access token is required")
+	}
+
+	sessions, err := h.service.Auth.ListSessions(ctx, req.AccessToken)
+	if err != nil {
+		log.Printf("gRPC ListSessions failed: %v", err)
+		return nil, sessionStatus(err)
+	}
+	return &authv1.Sessions{Sessions: sessions}, nil
+}
+
+func (h *AuthHandlers) RevokeSession(ctx context.Context, req *authv1.RevokeSessionRequest) (*commonv1.Empty, error) {
+	log.Printf("gRPC RevokeSession request - session: %s", req.SessionId)
+
+	if req.AccessToken == "" || req.SessionId == "" {
+		log.Printf("gRPC RevokeSession failed - missing required fields")
+		return &commonv1.Empty{}, status.Error(codes.InvalidArgument, "access token and session id are required")
+	}
+
+	if err := h.service.Auth.RevokeSession(ctx, req.AccessToken, req.SessionId); err != nil {
+		log.Printf("gRPC RevokeSession failed for session %s: %v", req.SessionId, err)
+		return &commonv1.Empty{}, sessionStatus(err)
+	}
+	return &commonv1.Empty{}, nil
+}
+
+func (h *AuthHandlers) RevokeOtherSessions(ctx context.Context, req *authv1.RevokeOtherSessionsRequest) (*authv1.RevokedSessions, error) {
+	log.Printf("gRPC RevokeOtherSessions request")
+
+	if req.AccessToken == "" {
+		log.Printf("gRPC RevokeOtherSessions failed - missing access token")
+		return nil, status.Error(codes.InvalidArgument, "access token is required")
+	}
+
+	count, err := h.service.Auth.RevokeOtherSessions(ctx, req.AccessToken)
+	if err != nil {
+		log.Printf("gRPC RevokeOtherSessions failed: %v", err)
+		return nil, sessionStatus(err)
+	}
+	return &authv1.RevokedSessions{Count: int32(count)}, nil
+}
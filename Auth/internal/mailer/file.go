@@ -0,0 +1,48 @@
+package mailer
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileMailer не отправляет письма, а кладёт каждое в отдельный .eml-файл в
+// outbox-каталоге. Файл открывается любым почтовым клиентом.
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+func NewFileMailer(dir, from string) (*FileMailer, error) {
+	if dir == "" {
+		dir = "outbox"
+	}
+	if from == "" {
+		from = "no-reply@localhost"
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create outbox dir: %w", err)
+	}
+	return &FileMailer{dir: dir, from: from}, nil
+}
+
+func (m *FileMailer) Send(_ context.Context, msg Message) error {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return fmt.Errorf("failed to read random bytes: %w", err)
+	}
+	name := time.Now().UTC().Format("20060102T150405.000000000") + "-" + hex.EncodeToString(suffix) + ".eml"
+	path := filepath.Join(m.dir, name)
+
+	if err := os.WriteFile(path, render(m.from, msg), 0o640); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+
+	log.Printf("Mailer: wrote %q for %s to %s", msg.Subject, msg.To, path)
+	return nil
+}
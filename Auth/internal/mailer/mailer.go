@@ -0,0 +1,70 @@
+// Package mailer — отправка писем из Auth (сброс пароля и т.п.).
+//
+// Сервисный слой зависит только от интерфейса Mailer; реализация выбирается
+// в main по MAILER_DRIVER: "smtp" — настоящий почтовый сервер, "file" —
+// письма складываются .eml-файлами в каталог (локальная разработка, стенды).
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string // text/plain, UTF-8
+}
+
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+type Config struct {
+	Driver string // smtp | file
+	From   string
+
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+
+	OutboxDir string
+}
+
+// New создаёт Mailer по конфигурации.
+func New(cfg Config) (Mailer, error) {
+	switch cfg.Driver {
+	case "smtp":
+		if cfg.SMTPHost == "" || cfg.From == "" {
+			return nil, fmt.Errorf("smtp mailer requires host and from address")
+		}
+		return NewSMTPMailer(cfg), nil
+	case "file", "":
+		return NewFileMailer(cfg.OutboxDir, cfg.From)
+	default:
+		return nil, fmt.Errorf("unknown mailer driver: %s", cfg.Driver)
+	}
+}
+
+// render собирает письмо в формате RFC 5322.
+func render(from string, msg Message) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + msg.To + "\r\n")
+	b.WriteString("Subject: " + encodeHeader(msg.Subject) + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+func encodeHeader(s string) string {
+	return mime.QEncoding.Encode("utf-8", s)
+}
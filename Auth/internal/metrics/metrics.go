@@ -21,6 +21,9 @@ const serviceLabel = "auth"
 var (
 	registry = prometheus.NewRegistry()
 
+	// extraHandlers — дополнительные HTTP-эндпоинты на том же порту (JWKS и т.п.).
+	extraHandlers = map[string]http.Handler{}
+
 	grpcDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
 		Name:    "grpc_server_handling_seconds",
 		Help:    "Histogram of response latency (seconds) for gRPC server method handling.",
@@ -31,6 +34,19 @@ var (
 		Name: "grpc_server_handled_total",
 		Help: "Total number of RPCs completed on the server, regardless of success or failure.",
 	}, []string{"service", "grpc_method", "code"})
+
+	// LoginAttempts — попытки входа по результату: success, failure, locked
+	// (отклонена без проверки пароля из-за блокировки).
+	LoginAttempts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_login_attempts_total",
+		Help: "Total number of login attempts by result.",
+	}, []string{"result"})
+
+	// LoginLockouts — выставленные блокировки по области: account или ip.
+	LoginLockouts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_login_lockouts_total",
+		Help: "Total number of temporary login lockouts by scope.",
+	}, []string{"scope"})
 )
 
 func init() {
@@ -39,6 +55,8 @@ func init() {
 		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
 		grpcDuration,
 		grpcHandled,
+		LoginAttempts,
+		LoginLockouts,
 	)
 }
 
@@ -60,8 +78,17 @@ func UnaryInterceptor() grpc.UnaryServerInterceptor {
 	}
 }
 
+// Handle регистрирует дополнительный HTTP-эндпоинт на порту метрик.
+// Вызывать до ServeMetrics.
+func Handle(pattern string, handler http.Handler) {
+	extraHandlers[pattern] = handler
+}
+
 func ServeMetrics(addr string) {
 	mux := http.NewServeMux()
+	for pattern, handler := range extraHandlers {
+		mux.Handle(pattern, handler)
+	}
 	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
 	mux.HandleFunc("/health", func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -0,0 +1,212 @@
+// Package mtls — взаимный TLS между сервисами и авторизация gRPC-вызовов по
+// имени вызывающего сервиса. Имя сервиса — CommonName его сертификата,
+// выпущенного общим CA (devops/generate_grpc_certs.sh): сертификат
+// подтверждает и сервер клиенту, и клиента серверу.
+package mtls
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Имена сервисов — CommonName их сертификатов.
+const (
+	Gateway      = "api-gateway"
+	Auth         = "auth"
+	Users        = "users"
+	Achievements = "achievements"
+	Company      = "company"
+	Vacancy      = "vacancy"
+	Skills       = "skills"
+	Search       = "search"
+	MicroTasks   = "microtasks"
+	// Ops — сертификат для ручных вызовов из makefile (make reindex).
+	Ops = "ops"
+)
+
+// healthPrefix — health-проверки доступны любому сервису с сертификатом CA.
+const healthPrefix = "/grpc.health.v1.Health/"
+
+// Config — сертификат сервиса и CA, которым подписаны сертификаты всех
+// сервисов. Без сертификатов сервис не стартует; gRPC без TLS возможен
+// только явно, с Insecure (локальная разработка).
+type Config struct {
+	CAFile   string
+	CertFile string
+	KeyFile  string
+	// Insecure разрешает gRPC без TLS, если сертификаты не заданы.
+	Insecure bool
+}
+
+// ConfigFromEnv читает GRPC_TLS_CA, GRPC_TLS_CERT, GRPC_TLS_KEY и
+// GRPC_TLS_INSECURE (1/true).
+func ConfigFromEnv() Config {
+	insecure, _ := strconv.ParseBool(os.Getenv("GRPC_TLS_INSECURE"))
+	return Config{
+		CAFile:   os.Getenv("GRPC_TLS_CA"),
+		CertFile: os.Getenv("GRPC_TLS_CERT"),
+		KeyFile:  os.Getenv("GRPC_TLS_KEY"),
+		Insecure: insecure,
+	}
+}
+
+func (c Config) Enabled() bool {
+	return c.CAFile != "" && c.CertFile != "" && c.KeyFile != ""
+}
+
+// errNotConfigured — сертификаты не заданы, а gRPC без TLS не разрешён явно.
+var errNotConfigured = errors.New("mtls: GRPC_TLS_CA, GRPC_TLS_CERT and GRPC_TLS_KEY are required (GRPC_TLS_INSECURE=1 allows plaintext gRPC for local development)")
+
+func (c Config) load() (*x509.CertPool, tls.Certificate, error) {
+	caPEM, err := os.ReadFile(c.CAFile)
+	if err != nil {
+		return nil, tls.Certificate{}, fmt.Errorf("mtls: read CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, tls.Certificate{}, errors.New("mtls: no certificates in CA file")
+	}
+
+	cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+	if err != nil {
+		return nil, tls.Certificate{}, fmt.Errorf("mtls: load key pair: %w", err)
+	}
+	return pool, cert, nil
+}
+
+// Policy — какие сервисы могут вызывать методы сервера.
+type Policy struct {
+	// Default — кому доступны методы, не перечисленные в Methods.
+	Default []string
+	// Methods — ключ: полное имя метода ("/users.v1.UsersService/AddVerifiedSkills")
+	// или сервиса со слэшем на конце ("/chat.v1.ChatService/").
+	Methods map[string][]string
+}
+
+func (p Policy) allowed(fullMethod, caller string) bool {
+	if caller == "" {
+		return false
+	}
+	if strings.HasPrefix(fullMethod, healthPrefix) {
+		return true
+	}
+
+	callers, ok := p.Methods[fullMethod]
+	if !ok {
+		if i := strings.LastIndex(fullMethod, "/"); i > 0 {
+			callers, ok = p.Methods[fullMethod[:i+1]]
+		}
+	}
+	if !ok {
+		callers = p.Default
+	}
+	return slices.Contains(callers, caller)
+}
+
+func (p Policy) authorize(ctx context.Context, fullMethod string) error {
+	caller := Caller(ctx)
+	if p.allowed(fullMethod, caller) {
+		return nil
+	}
+	log.Printf("mtls: %s denied for caller %q", fullMethod, caller)
+	return status.Errorf(codes.PermissionDenied, "caller %q is not allowed to call %s", caller, fullMethod)
+}
+
+// ServerOptions — опции gRPC-сервера: TLS с обязательным сертификатом
+// клиента и проверка каждого вызова по policy. Без сертификатов — ошибка;
+// с cfg.Insecure возвращает nil: сервер работает без TLS и без проверки
+// вызывающих.
+func ServerOptions(cfg Config, policy Policy) ([]grpc.ServerOption, error) {
+	if !cfg.Enabled() {
+		if !cfg.Insecure {
+			return nil, errNotConfigured
+		}
+		log.Printf("⚠ mTLS disabled (GRPC_TLS_INSECURE): gRPC server accepts plaintext calls from any client")
+		return nil, nil
+	}
+
+	pool, cert, err := cfg.load()
+	if err != nil {
+		return nil, err
+	}
+	creds := credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS13,
+	})
+
+	log.Printf("✓ mTLS enabled for gRPC server (identity: %s)", cert.Leaf.Subject.CommonName)
+	return []grpc.ServerOption{
+		grpc.Creds(creds),
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if err := policy.authorize(ctx, info.FullMethod); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := policy.authorize(ss.Context(), info.FullMethod); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	}, nil
+}
+
+// DialOption — transport credentials клиента: предъявляет сертификат
+// сервиса и проверяет сертификат сервера по CA. Имя сервера — host из addr
+// (docker DNS-имя сервиса, оно же SAN его сертификата). Без сертификатов —
+// ошибка; с cfg.Insecure — соединение без TLS.
+func DialOption(cfg Config, addr string) (grpc.DialOption, error) {
+	if !cfg.Enabled() {
+		if !cfg.Insecure {
+			return nil, errNotConfigured
+		}
+		return grpc.WithTransportCredentials(insecure.NewCredentials()), nil
+	}
+
+	pool, cert, err := cfg.load()
+	if err != nil {
+		return nil, err
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	return grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      pool,
+		ServerName:   host,
+		MinVersion:   tls.VersionTLS13,
+	})), nil
+}
+
+// Caller — имя сервиса, вызвавшего метод ("" — соединение без mTLS).
+func Caller(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return ""
+	}
+	return info.State.VerifiedChains[0][0].Subject.CommonName
+}
@@ -0,0 +1,149 @@
+// Package oidctest — OIDC-провайдер для тестов: discovery, JWKS и token
+// endpoint на httptest.Server. id_token подписывается ключом провайдера,
+// claims задаёт тест (SetClaims), так что проверяются и неверные токены.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/studjobs/hh_for_students/auth/internal/oidc"
+)
+
+const (
+	ClientID = "studjobs"
+	KeyID    = "test-key"
+)
+
+type Provider struct {
+	*httptest.Server
+	key *rsa.PrivateKey
+
+	mu        sync.Mutex
+	claims    jwt.MapClaims
+	tokenForm url.Values
+	exchanges int
+}
+
+// New запускает провайдера; сервер закрывается по окончании теста.
+func New(t testing.TB) *Provider {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa key: %v", err)
+	}
+	p := &Provider{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]string{
+			"issuer":                 p.URL,
+			"authorization_endpoint": p.URL + "/authorize",
+			"token_endpoint":         p.URL + "/token",
+			"jwks_uri":               p.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		enc := base64.RawURLEncoding.EncodeToString
+		writeJSON(w, map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": KeyID,
+			"use": "sig",
+			"n":   enc(key.N.Bytes()),
+			"e":   enc(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		p.mu.Lock()
+		p.tokenForm = r.PostForm
+		p.exchanges++
+		claims := p.claims
+		p.mu.Unlock()
+
+		resp := map[string]interface{}{"access_token": "access", "token_type": "Bearer", "expires_in": 300}
+		if claims != nil {
+			resp["id_token"] = p.Sign(claims)
+		}
+		writeJSON(w, resp)
+	})
+	p.Server = httptest.NewServer(mux)
+	t.Cleanup(p.Close)
+	return p
+}
+
+// Config — настройки провайдера name для Auth.
+func (p *Provider) Config(name string) oidc.Config {
+	return oidc.Config{
+		Name:         name,
+		ClientID:     ClientID,
+		ClientSecret: "secret",
+		RedirectURL:  "https://gateway.test/api/v1/auth/oidc/" + name + "/callback",
+		Issuer:       p.URL,
+	}
+}
+
+// Claims — claims действующего id_token для входа с nonce.
+func (p *Provider) Claims(nonce string) jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":            p.URL,
+		"aud":            ClientID,
+		"sub":            "subject-1",
+		"email":          "student@example.com",
+		"email_verified": true,
+		"nonce":          nonce,
+		"iat":            now.Unix(),
+		"exp":            now.Add(5 * time.Minute).Unix(),
+	}
+}
+
+// SetClaims задаёт id_token следующих ответов token endpoint (nil — без id_token).
+func (p *Provider) SetClaims(claims jwt.MapClaims) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.claims = claims
+}
+
+// Sign подписывает claims ключом провайдера (RS256).
+func (p *Provider) Sign(claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = KeyID
+	raw, err := token.SignedString(p.key)
+	if err != nil {
+		panic(err)
+	}
+	return raw
+}
+
+// TokenRequest — форма последнего запроса к token endpoint.
+func (p *Provider) TokenRequest() url.Values {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.tokenForm
+}
+
+// Exchanges — сколько раз обменивали code на токены.
+func (p *Provider) Exchanges() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.exchanges
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
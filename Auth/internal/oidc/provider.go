@@ -0,0 +1,390 @@
+// Package oidc — вход через внешних провайдеров: OpenID Connect (SSO
+// университета, Google, Keycloak) и «голый» OAuth2 без id_token (GitHub).
+//
+// Auth выступает relying party с authorization code flow + PKCE (S256):
+//  1. AuthCodeURL — ссылка на провайдера со state, nonce и code_challenge;
+//  2. Exchange — обмен code на токены по code_verifier;
+//  3. Identity — кто вошёл: для OIDC из проверенного id_token (подпись по
+//     JWKS, iss, aud, exp, nonce), для OAuth2 — из userinfo.
+//
+// Хранение state/verifier и привязка к пользователям — забота вызывающего
+// (см. service/oidc.go). Пакет не зависит от proto и БД.
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrUnknownProvider = errors.New("unknown oidc provider")
+	ErrExchangeFailed  = errors.New("authorization code exchange failed")
+	ErrInvalidIDToken  = errors.New("invalid id token")
+)
+
+const (
+	// maxResponseSize — ответы провайдера больше этого не читаем.
+	maxResponseSize = 1 << 20
+	// discoveryTTL — как долго верим discovery-документу и JWKS без перечитывания.
+	discoveryTTL = time.Hour
+	// jwksMinRefetch — не чаще этого перечитываем JWKS на незнакомый kid.
+	jwksMinRefetch = time.Minute
+)
+
+// Config — настройки одного провайдера.
+type Config struct {
+	// Name — идентификатор в URL (/auth/oidc/<name>/start).
+	Name         string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL — callback Gateway, зарегистрированный у провайдера.
+	RedirectURL string
+	Scopes      []string
+
+	// Issuer — для OIDC-провайдера: endpoints берутся из
+	// <issuer>/.well-known/openid-configuration.
+	Issuer string
+
+	// AuthURL, TokenURL, UserInfoURL — для OAuth2-провайдера без discovery
+	// (Issuer пустой). Для OIDC, если заданы, перекрывают discovery.
+	AuthURL     string
+	TokenURL    string
+	UserInfoURL string
+	// TrustEmail — для OAuth2-провайдера: email из userinfo подтверждён
+	// провайдером (GitHub отдаёт в профиле только подтверждённый адрес).
+	TrustEmail bool
+}
+
+// OIDC — провайдер с discovery и id_token.
+func (c Config) OIDC() bool {
+	return c.Issuer != ""
+}
+
+// Identity — внешняя учётная запись.
+type Identity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// Token — ответ token endpoint.
+type Token struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+	ExpiresIn   int    `json:"expires_in"`
+
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+type endpoints struct {
+	Issuer   string `json:"issuer"`
+	Auth     string `json:"authorization_endpoint"`
+	Token    string `json:"token_endpoint"`
+	UserInfo string `json:"userinfo_endpoint"`
+	JWKS     string `json:"jwks_uri"`
+}
+
+// Provider — один настроенный провайдер. Discovery и JWKS подтягиваются
+// лениво и кэшируются.
+type Provider struct {
+	cfg    Config
+	client *http.Client
+
+	mu            sync.Mutex
+	ep            *endpoints
+	discoveredAt  time.Time
+	keys          map[string]interface{}
+	keysFetchedAt time.Time
+}
+
+func NewProvider(cfg Config, client *http.Client) *Provider {
+	if !cfg.OIDC() && (cfg.AuthURL == "" || cfg.TokenURL == "" || cfg.UserInfoURL == "") {
+		log.Printf("oidc: provider %s has neither issuer nor auth/token/userinfo urls", cfg.Name)
+	}
+	if cfg.OIDC() && !strings.HasPrefix(cfg.Issuer, "https://") {
+		log.Printf("oidc: provider %s uses non-https issuer %s (ok only for local testing)", cfg.Name, cfg.Issuer)
+	}
+	if cfg.OIDC() && len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "email", "profile"}
+	}
+	return &Provider{cfg: cfg, client: client}
+}
+
+func (p *Provider) Name() string {
+	return p.cfg.Name
+}
+
+// AuthCodeURL возвращает ссылку на страницу входа провайдера.
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	ep, err := p.endpoints(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	u, err := url.Parse(ep.Auth)
+	if err != nil {
+		return "", fmt.Errorf("invalid authorization endpoint: %w", err)
+	}
+	q := u.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", p.cfg.ClientID)
+	q.Set("redirect_uri", p.cfg.RedirectURL)
+	q.Set("scope", strings.Join(p.cfg.Scopes, " "))
+	q.Set("state", state)
+	q.Set("code_challenge", codeChallenge)
+	q.Set("code_challenge_method", "S256")
+	if p.cfg.OIDC() {
+		q.Set("nonce", nonce)
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// Exchange обменивает authorization code на токены.
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier string) (*Token, error) {
+	ep, err := p.endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.cfg.RedirectURL)
+	form.Set("code_verifier", codeVerifier)
+	form.Set("client_id", p.cfg.ClientID)
+	form.Set("client_secret", p.cfg.ClientSecret)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ep.Token, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	// Без Accept GitHub отвечает form-urlencoded.
+	req.Header.Set("Accept", "application/json")
+
+	var tok Token
+	status, err := p.do(req, &tok)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExchangeFailed, err)
+	}
+	if tok.Error != "" || status != http.StatusOK || tok.AccessToken == "" {
+		return nil, fmt.Errorf("%w: status %d, error %q (%s)", ErrExchangeFailed, status, tok.Error, tok.ErrorDescription)
+	}
+	return &tok, nil
+}
+
+// Identity определяет внешнюю учётную запись по ответу token endpoint.
+// nonce — значение из AuthCodeURL; для OIDC он обязан совпасть с id_token.
+func (p *Provider) Identity(ctx context.Context, tok *Token, nonce string) (*Identity, error) {
+	if p.cfg.OIDC() {
+		if tok.IDToken == "" {
+			return nil, fmt.Errorf("%w: provider returned no id_token", ErrInvalidIDToken)
+		}
+		return p.verifyIDToken(ctx, tok.IDToken, nonce)
+	}
+	return p.userInfo(ctx, tok.AccessToken)
+}
+
+// userInfo — профиль OAuth2-провайдера. Поддерживаются и OIDC-поля (sub,
+// email_verified), и GitHub-овские (числовой id).
+func (p *Provider) userInfo(ctx context.Context, accessToken string) (*Identity, error) {
+	ep, err := p.endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ep.UserInfo, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/json")
+
+	var info struct {
+		Sub           string          `json:"sub"`
+		ID            json.RawMessage `json:"id"`
+		Email         string          `json:"email"`
+		EmailVerified flexBool        `json:"email_verified"`
+		Name          string          `json:"name"`
+		Login         string          `json:"login"`
+	}
+	status, err := p.do(req, &info)
+	if err != nil {
+		return nil, fmt.Errorf("userinfo request failed: %w", err)
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("userinfo request failed: status %d", status)
+	}
+
+	id := &Identity{
+		Subject:       info.Sub,
+		Email:         strings.TrimSpace(info.Email),
+		EmailVerified: bool(info.EmailVerified) || p.cfg.TrustEmail,
+		Name:          info.Name,
+	}
+	if id.Subject == "" && len(info.ID) > 0 && string(info.ID) != "null" {
+		// GitHub отдаёт числовой id, другие провайдеры — строку.
+		id.Subject = strings.Trim(string(info.ID), `"`)
+	}
+	if id.Name == "" {
+		id.Name = info.Login
+	}
+	if id.Subject == "" {
+		return nil, errors.New("userinfo has no subject")
+	}
+	if id.Email == "" {
+		id.EmailVerified = false
+	}
+	return id, nil
+}
+
+// endpoints — адреса провайдера: из конфигурации или discovery.
+func (p *Provider) endpoints(ctx context.Context) (*endpoints, error) {
+	if !p.cfg.OIDC() {
+		return &endpoints{Auth: p.cfg.AuthURL, Token: p.cfg.TokenURL, UserInfo: p.cfg.UserInfoURL}, nil
+	}
+
+	p.mu.Lock()
+	if p.ep != nil && time.Since(p.discoveredAt) < discoveryTTL {
+		ep := p.ep
+		p.mu.Unlock()
+		return ep, nil
+	}
+	p.mu.Unlock()
+
+	wellKnown := strings.TrimSuffix(p.cfg.Issuer, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, wellKnown, nil)
+	if err != nil {
+		return nil, err
+	}
+	var ep endpoints
+	status, err := p.do(req, &ep)
+	if err != nil {
+		return nil, fmt.Errorf("oidc discovery failed: %w", err)
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("oidc discovery failed: status %d", status)
+	}
+	// OIDC Discovery §4.3: issuer в документе обязан совпадать с настроенным.
+	if ep.Issuer != p.cfg.Issuer {
+		return nil, fmt.Errorf("oidc discovery: issuer mismatch %q != %q", ep.Issuer, p.cfg.Issuer)
+	}
+	if p.cfg.AuthURL != "" {
+		ep.Auth = p.cfg.AuthURL
+	}
+	if p.cfg.TokenURL != "" {
+		ep.Token = p.cfg.TokenURL
+	}
+	if p.cfg.UserInfoURL != "" {
+		ep.UserInfo = p.cfg.UserInfoURL
+	}
+	if ep.Auth == "" || ep.Token == "" || ep.JWKS == "" {
+		return nil, errors.New("oidc discovery: document lacks required endpoints")
+	}
+
+	p.mu.Lock()
+	p.ep = &ep
+	p.discoveredAt = time.Now()
+	p.mu.Unlock()
+	return &ep, nil
+}
+
+// do выполняет запрос и декодирует JSON-ответ (в том числе с ошибкой).
+func (p *Provider) do(req *http.Request, out interface{}) (int, error) {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return resp.StatusCode, err
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return resp.StatusCode, fmt.Errorf("unexpected response (status %d): %w", resp.StatusCode, err)
+	}
+	return resp.StatusCode, nil
+}
+
+// Registry — все настроенные провайдеры.
+type Registry struct {
+	providers map[string]*Provider
+}
+
+func NewRegistry(cfgs []Config, client *http.Client) *Registry {
+	r := &Registry{providers: make(map[string]*Provider, len(cfgs))}
+	for _, cfg := range cfgs {
+		r.providers[cfg.Name] = NewProvider(cfg, client)
+	}
+	return r
+}
+
+func (r *Registry) Get(name string) (*Provider, error) {
+	if r == nil {
+		return nil, ErrUnknownProvider
+	}
+	p, ok := r.providers[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	return p, nil
+}
+
+// Names — имена провайдеров для кнопок «Войти через …».
+func (r *Registry) Names() []string {
+	if r == nil {
+		return nil
+	}
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RandomString — случайная строка для state, nonce и code_verifier (256 бит).
+func RandomString() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to read random bytes: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// CodeChallenge — PKCE S256 (RFC 7636 §4.2).
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// flexBool — email_verified бывает и true, и "true" (Cognito и др.).
+type flexBool bool
+
+func (b *flexBool) UnmarshalJSON(data []byte) error {
+	switch strings.Trim(string(data), `"`) {
+	case "true":
+		*b = true
+	default:
+		*b = false
+	}
+	return nil
+}
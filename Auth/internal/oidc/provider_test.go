@@ -0,0 +1,148 @@
+package oidc_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/studjobs/hh_for_students/auth/internal/oidc"
+	"github.com/studjobs/hh_for_students/auth/internal/oidc/oidctest"
+)
+
+func TestProviderCodeFlow(t *testing.T) {
+	mock := oidctest.New(t)
+	p := oidc.NewProvider(mock.Config("sso"), http.DefaultClient)
+	ctx := context.Background()
+
+	authURL, err := p.AuthCodeURL(ctx, "state-1", "nonce-1", oidc.CodeChallenge("verifier-1"))
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	u, _ := url.Parse(authURL)
+	q := u.Query()
+	if !strings.HasPrefix(authURL, mock.URL+"/authorize?") || q.Get("state") != "state-1" || q.Get("nonce") != "nonce-1" ||
+		q.Get("code_challenge") != oidc.CodeChallenge("verifier-1") || q.Get("code_challenge_method") != "S256" {
+		t.Fatalf("AuthCodeURL = %s", authURL)
+	}
+
+	mock.SetClaims(mock.Claims("nonce-1"))
+	tok, err := p.Exchange(ctx, "code-1", "verifier-1")
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if form := mock.TokenRequest(); form.Get("code") != "code-1" || form.Get("code_verifier") != "verifier-1" {
+		t.Fatalf("token request %v: code or PKCE verifier not sent", form)
+	}
+
+	id, err := p.Identity(ctx, tok, "nonce-1")
+	if err != nil {
+		t.Fatalf("Identity: %v", err)
+	}
+	want := oidc.Identity{Subject: "subject-1", Email: "student@example.com", EmailVerified: true}
+	if *id != want {
+		t.Fatalf("Identity = %+v, want %+v", *id, want)
+	}
+}
+
+func TestProviderRejectsInvalidIDToken(t *testing.T) {
+	mock := oidctest.New(t)
+	p := oidc.NewProvider(mock.Config("sso"), http.DefaultClient)
+
+	// Чужой ключ с тем же kid: подпись не сойдётся с JWKS провайдера.
+	forged := oidctest.New(t)
+
+	tests := []struct {
+		name   string
+		modify func(c jwt.MapClaims)
+		raw    func(c jwt.MapClaims) string
+	}{
+		{name: "nonce mismatch", modify: func(c jwt.MapClaims) { c["nonce"] = "nonce-of-another-login" }},
+		{name: "missing nonce", modify: func(c jwt.MapClaims) { delete(c, "nonce") }},
+		{name: "other audience", modify: func(c jwt.MapClaims) { c["aud"] = "another-client" }},
+		{name: "foreign azp", modify: func(c jwt.MapClaims) {
+			c["aud"] = []string{oidctest.ClientID, "another-client"}
+			c["azp"] = "another-client"
+		}},
+		{name: "other issuer", modify: func(c jwt.MapClaims) { c["iss"] = "https://evil.example" }},
+		{name: "expired", modify: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() }},
+		{name: "missing exp", modify: func(c jwt.MapClaims) { delete(c, "exp") }},
+		{name: "missing sub", modify: func(c jwt.MapClaims) { delete(c, "sub") }},
+		{name: "foreign signature", raw: forged.Sign},
+		{name: "hmac with client secret", raw: func(c jwt.MapClaims) string {
+			raw, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, c).SignedString([]byte("secret"))
+			return raw
+		}},
+		{name: "unsigned", raw: func(c jwt.MapClaims) string {
+			raw, _ := jwt.NewWithClaims(jwt.SigningMethodNone, c).SignedString(jwt.UnsafeAllowNoneSignatureType)
+			return raw
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := mock.Claims("nonce-1")
+			raw := mock.Sign
+			if tt.modify != nil {
+				tt.modify(claims)
+			}
+			if tt.raw != nil {
+				raw = tt.raw
+			}
+
+			_, err := p.Identity(context.Background(), &oidc.Token{AccessToken: "access", IDToken: raw(claims)}, "nonce-1")
+			if !errors.Is(err, oidc.ErrInvalidIDToken) {
+				t.Fatalf("Identity: %v, want ErrInvalidIDToken", err)
+			}
+		})
+	}
+}
+
+func TestProviderEmailVerification(t *testing.T) {
+	mock := oidctest.New(t)
+	p := oidc.NewProvider(mock.Config("sso"), http.DefaultClient)
+
+	tests := []struct {
+		name   string
+		modify func(c jwt.MapClaims)
+		want   bool
+	}{
+		{"verified", func(jwt.MapClaims) {}, true},
+		// Cognito и др. отдают строку.
+		{"verified as string", func(c jwt.MapClaims) { c["email_verified"] = "true" }, true},
+		{"not verified", func(c jwt.MapClaims) { c["email_verified"] = false }, false},
+		{"no claim", func(c jwt.MapClaims) { delete(c, "email_verified") }, false},
+		{"verified without email", func(c jwt.MapClaims) { delete(c, "email") }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := mock.Claims("nonce-1")
+			tt.modify(claims)
+			id, err := p.Identity(context.Background(), &oidc.Token{IDToken: mock.Sign(claims)}, "nonce-1")
+			if err != nil {
+				t.Fatalf("Identity: %v", err)
+			}
+			if id.EmailVerified != tt.want {
+				t.Fatalf("EmailVerified = %t, want %t", id.EmailVerified, tt.want)
+			}
+		})
+	}
+}
+
+func TestProviderExchangeRequiresIDToken(t *testing.T) {
+	mock := oidctest.New(t)
+	p := oidc.NewProvider(mock.Config("sso"), http.DefaultClient)
+
+	// Провайдер ответил без id_token — для OIDC это не вход.
+	mock.SetClaims(nil)
+	tok, err := p.Exchange(context.Background(), "code-1", "verifier-1")
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if _, err := p.Identity(context.Background(), tok, "nonce-1"); !errors.Is(err, oidc.ErrInvalidIDToken) {
+		t.Fatalf("Identity without id_token: %v, want ErrInvalidIDToken", err)
+	}
+}
@@ -0,0 +1,106 @@
+package password
+
+import (
+	"bufio"
+	"crypto/sha1"
+	_ "embed"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// prefixLength — длина префикса SHA-1, по которому запрашивается диапазон
+// (как в Pwned Passwords range API: 16^5 диапазонов по нескольку сотен хешей).
+const prefixLength = 5
+
+// RangeSource — источник утёкших паролей с k-anonymity: по первым пяти
+// hex-символам SHA-1 он возвращает хвосты всех хешей с этим префиксом, а
+// сравнение идёт на нашей стороне. Сам пароль и даже его полный хеш
+// источник не видит, поэтому его можно заменить удалённым сервисом.
+type RangeSource interface {
+	Range(prefix string) ([]string, error)
+}
+
+// IsBreached проверяет пароль по источнику.
+func IsBreached(src RangeSource, password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	suffixes, err := src.Range(hash[:prefixLength])
+	if err != nil {
+		return false, err
+	}
+	for _, suffix := range suffixes {
+		if suffix == hash[prefixLength:] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+//go:embed breached.txt
+var defaultList string
+
+// BreachedList — локальный список утёкших паролей, разложенный по
+// префиксам хешей.
+type BreachedList struct {
+	ranges map[string][]string
+	size   int
+}
+
+// NewBreachedList возвращает список со встроенными самыми
+// распространёнными паролями.
+func NewBreachedList() *BreachedList {
+	l := &BreachedList{ranges: make(map[string][]string)}
+	if err := l.Load(strings.NewReader(defaultList)); err != nil {
+		panic(fmt.Sprintf("password: invalid embedded breached list: %v", err))
+	}
+	return l
+}
+
+// LoadFile добавляет хеши из файла. Формат — выгрузка Pwned Passwords
+// (HASH:COUNT в строке) или просто SHA-1 в строке; # — комментарий.
+func (l *BreachedList) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open breached list: %w", err)
+	}
+	defer f.Close()
+	return l.Load(f)
+}
+
+func (l *BreachedList) Load(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		hash := strings.TrimSpace(scanner.Text())
+		if hash == "" || strings.HasPrefix(hash, "#") {
+			continue
+		}
+		if i := strings.IndexByte(hash, ':'); i >= 0 {
+			hash = hash[:i]
+		}
+		hash = strings.ToUpper(hash)
+		if len(hash) != sha1.Size*2 {
+			return fmt.Errorf("line %d: expected sha-1 hash, got %q", line, hash)
+		}
+		if _, err := hex.DecodeString(hash); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+
+		prefix := hash[:prefixLength]
+		l.ranges[prefix] = append(l.ranges[prefix], hash[prefixLength:])
+		l.size++
+	}
+	return scanner.Err()
+}
+
+// Size — число хешей в списке.
+func (l *BreachedList) Size() int {
+	return l.size
+}
+
+func (l *BreachedList) Range(prefix string) ([]string, error) {
+	return l.ranges[strings.ToUpper(prefix)], nil
+}
@@ -0,0 +1,232 @@
+// Package password — хеширование паролей и парольная политика.
+//
+// Хеш хранится в самоописывающем формате: алгоритм и параметры записаны в
+// начале строки ($argon2id$v=19$m=19456,t=2,p=1$…, $2a$10$…). Поэтому смена
+// алгоритма или параметров не требует миграции: старые хеши проверяются
+// своим алгоритмом и перехешируются текущим при следующем входе.
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrMismatch         = errors.New("password does not match")
+	ErrUnknownAlgorithm = errors.New("unknown password hash algorithm")
+	ErrMalformedHash    = errors.New("malformed password hash")
+)
+
+// Algorithm — алгоритм хеширования паролей.
+type Algorithm interface {
+	// Name — название для логов и конфигурации.
+	Name() string
+	// Identifies — хеш записан этим алгоритмом.
+	Identifies(encoded string) bool
+	Hash(password string) (string, error)
+	// Verify возвращает ErrMismatch, если пароль не подходит.
+	Verify(encoded, password string) error
+	// Outdated — хеш этого алгоритма, но с параметрами слабее текущих.
+	Outdated(encoded string) bool
+}
+
+// Hasher хеширует пароли текущим алгоритмом и проверяет хеши всех известных.
+type Hasher struct {
+	current Algorithm
+	known   []Algorithm
+}
+
+// NewHasher — current для новых хешей, legacy — только для проверки старых.
+func NewHasher(current Algorithm, legacy ...Algorithm) *Hasher {
+	return &Hasher{
+		current: current,
+		known:   append([]Algorithm{current}, legacy...),
+	}
+}
+
+// Current — алгоритм новых хешей.
+func (h *Hasher) Current() Algorithm {
+	return h.current
+}
+
+func (h *Hasher) Hash(password string) (string, error) {
+	return h.current.Hash(password)
+}
+
+// Verify проверяет пароль. needsRehash — пароль верный, но хеш записан
+// устаревшим алгоритмом или с устаревшими параметрами.
+func (h *Hasher) Verify(encoded, password string) (needsRehash bool, err error) {
+	for _, alg := range h.known {
+		if !alg.Identifies(encoded) {
+			continue
+		}
+		if err := alg.Verify(encoded, password); err != nil {
+			return false, err
+		}
+		return alg != h.current || alg.Outdated(encoded), nil
+	}
+	return false, ErrUnknownAlgorithm
+}
+
+// Argon2id — рекомендуемый OWASP алгоритм: стойкий к перебору на GPU за
+// счёт объёма памяти.
+type Argon2id struct {
+	// Memory — память в КиБ.
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+const argon2idPrefix = "$argon2id$"
+
+// NewArgon2id — параметры по умолчанию OWASP: 19 МиБ, 2 прохода, 1 поток.
+// Нулевые значения заменяются ими.
+func NewArgon2id(memoryKiB, iterations uint32, parallelism uint8) *Argon2id {
+	a := &Argon2id{
+		Memory:      memoryKiB,
+		Iterations:  iterations,
+		Parallelism: parallelism,
+		SaltLength:  16,
+		KeyLength:   32,
+	}
+	if a.Memory == 0 {
+		a.Memory = 19 * 1024
+	}
+	if a.Iterations == 0 {
+		a.Iterations = 2
+	}
+	if a.Parallelism == 0 {
+		a.Parallelism = 1
+	}
+	return a
+}
+
+func (a *Argon2id) Name() string {
+	return "argon2id"
+}
+
+func (a *Argon2id) Identifies(encoded string) bool {
+	return strings.HasPrefix(encoded, argon2idPrefix)
+}
+
+func (a *Argon2id) Hash(password string) (string, error) {
+	salt := make([]byte, a.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, a.Iterations, a.Memory, a.Parallelism, a.KeyLength)
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", argon2idPrefix, argon2.Version,
+		a.Memory, a.Iterations, a.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func (a *Argon2id) Verify(encoded, password string) error {
+	params, salt, key, err := parseArgon2id(encoded)
+	if err != nil {
+		return err
+	}
+
+	// Пересчитываем с параметрами из хеша, а не текущими.
+	computed := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(key)))
+	if subtle.ConstantTimeCompare(computed, key) != 1 {
+		return ErrMismatch
+	}
+	return nil
+}
+
+func (a *Argon2id) Outdated(encoded string) bool {
+	params, salt, key, err := parseArgon2id(encoded)
+	if err != nil {
+		return true
+	}
+	return params.Memory < a.Memory ||
+		params.Iterations < a.Iterations ||
+		params.Parallelism < a.Parallelism ||
+		uint32(len(salt)) < a.SaltLength ||
+		uint32(len(key)) < a.KeyLength
+}
+
+// parseArgon2id разбирает $argon2id$v=19$m=…,t=…,p=…$salt$key.
+func parseArgon2id(encoded string) (*Argon2id, []byte, []byte, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 {
+		return nil, nil, nil, ErrMalformedHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, nil, nil, ErrMalformedHash
+	}
+
+	var params Argon2id
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return nil, nil, nil, ErrMalformedHash
+	}
+	if params.Iterations == 0 || params.Parallelism == 0 {
+		return nil, nil, nil, ErrMalformedHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return nil, nil, nil, ErrMalformedHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return nil, nil, nil, ErrMalformedHash
+	}
+	return &params, salt, key, nil
+}
+
+// Bcrypt — прежний алгоритм сервиса. Пароли длиннее 72 байт не принимает.
+type Bcrypt struct {
+	Cost int
+}
+
+func NewBcrypt(cost int) *Bcrypt {
+	if cost == 0 {
+		cost = bcrypt.DefaultCost
+	}
+	return &Bcrypt{Cost: cost}
+}
+
+func (b *Bcrypt) Name() string {
+	return "bcrypt"
+}
+
+func (b *Bcrypt) Identifies(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") ||
+		strings.HasPrefix(encoded, "$2b$") ||
+		strings.HasPrefix(encoded, "$2y$")
+}
+
+func (b *Bcrypt) Hash(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), b.Cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashed), nil
+}
+
+func (b *Bcrypt) Verify(encoded, password string) error {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return ErrMismatch
+	}
+	return err
+}
+
+func (b *Bcrypt) Outdated(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return err != nil || cost < b.Cost
+}
@@ -0,0 +1,79 @@
+package password
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	ErrWeakPassword     = errors.New("password does not meet the password policy")
+	ErrBreachedPassword = errors.New("password appears in a list of leaked passwords")
+)
+
+// Policy — требования к новому паролю (при регистрации и сбросе).
+type Policy struct {
+	// MinLength и MaxLength — в символах. Верхняя граница защищает от
+	// хеширования мегабайтных «паролей».
+	MinLength int
+	MaxLength int
+	// MinCharClasses — сколько классов символов (строчные, заглавные, цифры,
+	// прочие) должно встречаться в пароле. 1 — состав не проверяется.
+	MinCharClasses int
+	// Breached — список утёкших паролей; nil — не проверяется.
+	Breached RangeSource
+}
+
+// Check возвращает ErrWeakPassword или ErrBreachedPassword с пояснением.
+func (p *Policy) Check(password string) error {
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		return fmt.Errorf("%w: must be at least %d characters long", ErrWeakPassword, p.MinLength)
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		return fmt.Errorf("%w: must be at most %d characters long", ErrWeakPassword, p.MaxLength)
+	}
+	if charClasses(password) < p.MinCharClasses {
+		return fmt.Errorf("%w: must contain at least %d of: lowercase letters, uppercase letters, digits, symbols",
+			ErrWeakPassword, p.MinCharClasses)
+	}
+
+	if p.Breached != nil {
+		breached, err := IsBreached(p.Breached, password)
+		if err != nil {
+			// Недоступный список не должен блокировать регистрацию.
+			log.Printf("password: breached list check failed: %v", err)
+			return nil
+		}
+		if breached {
+			return ErrBreachedPassword
+		}
+	}
+	return nil
+}
+
+func charClasses(password string) int {
+	var lower, upper, digit, other bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			other = true
+		}
+	}
+
+	n := 0
+	for _, present := range []bool{lower, upper, digit, other} {
+		if present {
+			n++
+		}
+	}
+	return n
+}
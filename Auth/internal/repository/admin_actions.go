@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// AdminAction — запись журнала действий администратора.
+type AdminAction struct {
+	ID         string `db:"id"`
+	AdminID    string `db:"admin_id"`
+	Action     string `db:"action"`
+	TargetType string `db:"target_type"`
+	TargetID   string `db:"target_id"`
+	// Details — JSON-объект с параметрами запроса (причина блокировки...).
+	Details   string    `db:"details"`
+	Status    int       `db:"status"`
+	IP        string    `db:"ip"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// AdminActionFilter — условия выборки журнала; пустые поля не ограничивают.
+type AdminActionFilter struct {
+	AdminID    string
+	Action     string
+	TargetType string
+	TargetID   string
+}
+
+type AdminActionRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAdminActionRepository(db *pgxpool.Pool) *AdminActionRepository {
+	return &AdminActionRepository{
+		db: db,
+	}
+}
+
+// RecordAdminAction добавляет запись в журнал и возвращает её id и время.
+func (r *AdminActionRepository) RecordAdminAction(ctx context.Context, a *AdminAction) (string, time.Time, error) {
+	details := a.Details
+	if details == "" {
+		details = "{}"
+	}
+
+	query, args, err := sb.
+		Insert("admin_actions").
+		Columns("admin_id", "action", "target_type", "target_id", "details", "status", "ip").
+		Values(a.AdminID, a.Action, a.TargetType, a.TargetID, details, a.Status, a.IP).
+		Suffix("RETURNING id, created_at").
+		ToSql()
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var (
+		id        string
+		createdAt time.Time
+	)
+	if err := r.db.QueryRow(ctx, query, args...).Scan(&id, &createdAt); err != nil {
+		log.Printf("Failed to record admin action %s by %s: %v", a.Action, a.AdminID, err)
+		return "", time.Time{}, fmt.Errorf("failed to record admin action: %w", err)
+	}
+	return id, createdAt, nil
+}
+
+// ListAdminActions — страница журнала по фильтру (новые первыми) и общее
+// число записей.
+func (r *AdminActionRepository) ListAdminActions(ctx context.Context, filter AdminActionFilter, offset, limit int) ([]*AdminAction, int, error) {
+	where := squirrel.Eq{}
+	if filter.AdminID != "" {
+		where["admin_id"] = filter.AdminID
+	}
+	if filter.Action != "" {
+		where["action"] = filter.Action
+	}
+	if filter.TargetType != "" {
+		where["target_type"] = filter.TargetType
+	}
+	if filter.TargetID != "" {
+		where["target_id"] = filter.TargetID
+	}
+
+	countQuery, countArgs, err := sb.Select("COUNT(*)").From("admin_actions").Where(where).ToSql()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to build count query: %w", err)
+	}
+	var total int
+	if err := r.db.QueryRow(ctx, countQuery, countArgs...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count admin actions: %w", err)
+	}
+
+	query, args, err := sb.
+		Select("id", "admin_id", "action", "target_type", "target_id", "details::text", "status", "ip", "created_at").
+		From("admin_actions").
+		Where(where).
+		OrderBy("created_at DESC", "id").
+		Offset(uint64(offset)).
+		Limit(uint64(limit)).
+		ToSql()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list admin actions: %w", err)
+	}
+	defer rows.Close()
+
+	var actions []*AdminAction
+	for rows.Next() {
+		var a AdminAction
+		if err := rows.Scan(
+			&a.ID,
+			&a.AdminID,
+			&a.Action,
+			&a.TargetType,
+			&a.TargetID,
+			&a.Details,
+			&a.Status,
+			&a.IP,
+			&a.CreatedAt,
+		); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan admin action: %w", err)
+		}
+		actions = append(actions, &a)
+	}
+	return actions, total, rows.Err()
+}
@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+// APIKey — ключ интеграции компании. Сам ключ не хранится, только его хэш.
+type APIKey struct {
+	ID         string     `db:"id"`
+	CompanyID  string     `db:"company_id"`
+	Name       string     `db:"name"`
+	Prefix     string     `db:"prefix"`
+	KeyHash    string     `db:"key_hash"`
+	Scopes     []string   `db:"scopes"`
+	CreatedAt  time.Time  `db:"created_at"`
+	ExpiresAt  *time.Time `db:"expires_at"`
+	LastUsedAt *time.Time `db:"last_used_at"`
+	RevokedAt  *time.Time `db:"revoked_at"`
+}
+
+// Active — ключ не отозван и не истёк.
+func (k *APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+var apiKeyColumns = []string{"id", "company_id", "name", "prefix", "key_hash", "scopes",
+	"created_at", "expires_at", "last_used_at", "revoked_at"}
+
+type APIKeyRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAPIKeyRepository(db *pgxpool.Pool) *APIKeyRepository {
+	return &APIKeyRepository{
+		db: db,
+	}
+}
+
+func scanAPIKey(row pgx.Row) (*APIKey, error) {
+	var k APIKey
+	err := row.Scan(
+		&k.ID,
+		&k.CompanyID,
+		&k.Name,
+		&k.Prefix,
+		&k.KeyHash,
+		&k.Scopes,
+		&k.CreatedAt,
+		&k.ExpiresAt,
+		&k.LastUsedAt,
+		&k.RevokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+// CreateAPIKey сохраняет ключ и возвращает его id и время создания.
+func (r *APIKeyRepository) CreateAPIKey(ctx context.Context, k *APIKey) (string, time.Time, error) {
+	query, args, err := sb.
+		Insert("api_keys").
+		Columns("company_id", "name", "prefix", "key_hash", "scopes", "expires_at").
+		Values(k.CompanyID, k.Name, k.Prefix, k.KeyHash, k.Scopes, k.ExpiresAt).
+		Suffix("RETURNING id, created_at").
+		ToSql()
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var (
+		id        string
+		createdAt time.Time
+	)
+	if err := r.db.QueryRow(ctx, query, args...).Scan(&id, &createdAt); err != nil {
+		log.Printf("Failed to create api key for company: %s, error: %v", k.CompanyID, err)
+		return "", time.Time{}, fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	log.Printf("API key created - company: %s, id: %s", k.CompanyID, id)
+	return id, createdAt, nil
+}
+
+func (r *APIKeyRepository) FindAPIKeyByHash(ctx context.Context, keyHash string) (*APIKey, error) {
+	query, args, err := sb.
+		Select(apiKeyColumns...).
+		From("api_keys").
+		Where(squirrel.Eq{"key_hash": keyHash}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	k, err := scanAPIKey(r.db.QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to find api key: %w", err)
+	}
+	return k, nil
+}
+
+// ListCompanyAPIKeys возвращает действующие ключи компании, новые — первыми.
+func (r *APIKeyRepository) ListCompanyAPIKeys(ctx context.Context, companyID string) ([]*APIKey, error) {
+	query, args, err := sb.
+		Select(apiKeyColumns...).
+		From("api_keys").
+		Where(squirrel.Eq{"company_id": companyID}).
+		Where(squirrel.Eq{"revoked_at": nil}).
+		Where(squirrel.Or{squirrel.Eq{"expires_at": nil}, squirrel.Gt{"expires_at": time.Now()}}).
+		OrderBy("created_at DESC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []*APIKey
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan api key: %w", err)
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+// TouchAPIKey отмечает использование ключа, если с прошлой отметки прошло
+// больше interval: иначе каждый запрос интеграции был бы записью в БД.
+func (r *APIKeyRepository) TouchAPIKey(ctx context.Context, id string, usedAt time.Time, interval time.Duration) error {
+	query, args, err := sb.
+		Update("api_keys").
+		Set("last_used_at", usedAt).
+		Where(squirrel.Eq{"id": id}).
+		Where(squirrel.Or{squirrel.Eq{"last_used_at": nil}, squirrel.Lt{"last_used_at": usedAt.Add(-interval)}}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := r.db.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to touch api key: %w", err)
+	}
+	return nil
+}
+
+// RevokeAPIKey отзывает ключ компании и возвращает его хэш. ErrAPIKeyNotFound —
+// такого действующего ключа у компании нет.
+func (r *APIKeyRepository) RevokeAPIKey(ctx context.Context, companyID, id string) (string, error) {
+	query, args, err := sb.
+		Update("api_keys").
+		Set("revoked_at", time.Now()).
+		Where(squirrel.Eq{"id": id}).
+		Where(squirrel.Eq{"company_id": companyID}).
+		Where(squirrel.Eq{"revoked_at": nil}).
+		Suffix("RETURNING key_hash").
+		ToSql()
+	if err != nil {
+		return "", fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var keyHash string
+	if err := r.db.QueryRow(ctx, query, args...).Scan(&keyHash); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrAPIKeyNotFound
+		}
+		return "", fmt.Errorf("failed to revoke api key: %w", err)
+	}
+
+	log.Printf("API key revoked - company: %s, id: %s", companyID, id)
+	return keyHash, nil
+}
+
+// RevokeCompanyAPIKeys отзывает все ключи компании (удаление аккаунта владельца).
+func (r *APIKeyRepository) RevokeCompanyAPIKeys(ctx context.Context, companyID string) error {
+	query, args, err := sb.
+		Update("api_keys").
+		Set("revoked_at", time.Now()).
+		Where(squirrel.Eq{"company_id": companyID}).
+		Where(squirrel.Eq{"revoked_at": nil}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to revoke company api keys: %w", err)
+	}
+
+	log.Printf("API keys revoked for company: %s, count: %d", companyID, result.RowsAffected())
+	return nil
+}
+
+// CleanupAPIKeys удаляет истёкшие и отозванные ключи.
+func (r *APIKeyRepository) CleanupAPIKeys(ctx context.Context) error {
+	query, args, err := sb.
+		Delete("api_keys").
+		Where(squirrel.Or{squirrel.NotEq{"revoked_at": nil}, squirrel.Lt{"expires_at": time.Now()}}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build cleanup query: %w", err)
+	}
+
+	result, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to cleanup api keys: %w", err)
+	}
+
+	log.Printf("Cleaned up expired api keys, count: %d", result.RowsAffected())
+	return nil
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type AttemptsRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAttemptsRepository(db *pgxpool.Pool) *AttemptsRepository {
+	return &AttemptsRepository{
+		db: db,
+	}
+}
+
+// GetActiveLocks возвращает действующие блокировки по ключам: key -> locked_until.
+func (r *AttemptsRepository) GetActiveLocks(ctx context.Context, keys []string) (map[string]time.Time, error) {
+	query, args, err := sb.
+		Select("key", "locked_until").
+		From("login_attempts").
+		Where(squirrel.Eq{"key": keys}).
+		Where("locked_until > ?", time.Now()).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get login locks: %w", err)
+	}
+	defer rows.Close()
+
+	locks := make(map[string]time.Time)
+	for rows.Next() {
+		var key string
+		var until time.Time
+		if err := rows.Scan(&key, &until); err != nil {
+			return nil, fmt.Errorf("failed to scan login lock: %w", err)
+		}
+		locks[key] = until
+	}
+	return locks, rows.Err()
+}
+
+// RegisterFailure увеличивает счётчик неудач и возвращает новое значение.
+// Если предыдущая неудача была раньше windowStart, счёт начинается заново.
+func (r *AttemptsRepository) RegisterFailure(ctx context.Context, key string, windowStart time.Time) (int, error) {
+	query, args, err := sb.
+		Insert("login_attempts").
+		Columns("key", "failures", "last_failure_at").
+		Values(key, 1, time.Now()).
+		Suffix(`ON CONFLICT (key) DO UPDATE SET
+			failures = CASE WHEN login_attempts.last_failure_at < ? THEN 1 ELSE login_attempts.failures + 1 END,
+			last_failure_at = EXCLUDED.last_failure_at
+			RETURNING failures`, windowStart).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var failures int
+	if err := r.db.QueryRow(ctx, query, args...).Scan(&failures); err != nil {
+		return 0, fmt.Errorf("failed to register login failure: %w", err)
+	}
+	return failures, nil
+}
+
+func (r *AttemptsRepository) LockUntil(ctx context.Context, key string, until time.Time) error {
+	query, args, err := sb.
+		Update("login_attempts").
+		Set("locked_until", until).
+		Where(squirrel.Eq{"key": key}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := r.db.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to lock %s: %w", key, err)
+	}
+
+	log.Printf("Login locked - key: %s, until: %s", key, until.Format(time.RFC3339))
+	return nil
+}
+
+// ResetAttempts снимает блокировку и обнуляет счётчик (успешный вход, сброс
+// пароля, ручная разблокировка).
+func (r *AttemptsRepository) ResetAttempts(ctx context.Context, key string) error {
+	query, args, err := sb.
+		Delete("login_attempts").
+		Where(squirrel.Eq{"key": key}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := r.db.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to reset login attempts: %w", err)
+	}
+	return nil
+}
+
+// CleanupLoginAttempts удаляет счётчики без свежих неудач и без действующей блокировки.
+func (r *AttemptsRepository) CleanupLoginAttempts(ctx context.Context, olderThan time.Time) error {
+	query, args, err := sb.
+		Delete("login_attempts").
+		Where("last_failure_at < ?", olderThan).
+		Where(squirrel.Or{
+			squirrel.Eq{"locked_until": nil},
+			squirrel.Lt{"locked_until": time.Now()},
+		}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build cleanup query: %w", err)
+	}
+
+	result, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to cleanup login attempts: %w", err)
+	}
+
+	log.Printf("Cleaned up login attempts, count: %d", result.RowsAffected())
+	return nil
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// AuditEvent — запись журнала аудита бизнес-действий.
+type AuditEvent struct {
+	ID           string `db:"id"`
+	ActorID      string `db:"actor_id"`
+	ActorRole    string `db:"actor_role"`
+	Action       string `db:"action"`
+	ResourceType string `db:"resource_type"`
+	ResourceID   string `db:"resource_id"`
+	CompanyID    string `db:"company_id"`
+	// Changes — JSON-объект {"поле": {"before": ..., "after": ...}}.
+	Changes   string    `db:"changes"`
+	IP        string    `db:"ip"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// AuditFilter — условия выборки журнала; пустые поля не ограничивают.
+type AuditFilter struct {
+	CompanyID    string
+	ActorID      string
+	Action       string
+	ResourceType string
+	ResourceID   string
+	Since        time.Time
+	Until        time.Time
+}
+
+type AuditRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAuditRepository(db *pgxpool.Pool) *AuditRepository {
+	return &AuditRepository{
+		db: db,
+	}
+}
+
+// RecordAuditEvent добавляет запись в журнал и возвращает её id и время.
+// Изменить или удалить запись нельзя — это запрещают триггеры таблицы.
+func (r *AuditRepository) RecordAuditEvent(ctx context.Context, e *AuditEvent) (string, time.Time, error) {
+	changes := e.Changes
+	if changes == "" {
+		changes = "{}"
+	}
+
+	query, args, err := sb.
+		Insert("audit_events").
+		Columns("actor_id", "actor_role", "action", "resource_type", "resource_id", "company_id", "changes", "ip").
+		Values(e.ActorID, e.ActorRole, e.Action, e.ResourceType, e.ResourceID, e.CompanyID, changes, e.IP).
+		Suffix("RETURNING id, created_at").
+		ToSql()
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var (
+		id        string
+		createdAt time.Time
+	)
+	if err := r.db.QueryRow(ctx, query, args...).Scan(&id, &createdAt); err != nil {
+		log.Printf("Failed to record audit event %s by %s: %v", e.Action, e.ActorID, err)
+		return "", time.Time{}, fmt.Errorf("failed to record audit event: %w", err)
+	}
+	return id, createdAt, nil
+}
+
+// ListAuditEvents — страница журнала по фильтру (новые первыми) и общее
+// число записей.
+func (r *AuditRepository) ListAuditEvents(ctx context.Context, filter AuditFilter, offset, limit int) ([]*AuditEvent, int, error) {
+	eq := squirrel.Eq{}
+	if filter.CompanyID != "" {
+		eq["company_id"] = filter.CompanyID
+	}
+	if filter.ActorID != "" {
+		eq["actor_id"] = filter.ActorID
+	}
+	if filter.Action != "" {
+		eq["action"] = filter.Action
+	}
+	if filter.ResourceType != "" {
+		eq["resource_type"] = filter.ResourceType
+	}
+	if filter.ResourceID != "" {
+		eq["resource_id"] = filter.ResourceID
+	}
+	where := squirrel.And{eq}
+	if !filter.Since.IsZero() {
+		where = append(where, squirrel.GtOrEq{"created_at": filter.Since})
+	}
+	if !filter.Until.IsZero() {
+		where = append(where, squirrel.Lt{"created_at": filter.Until})
+	}
+
+	countQuery, countArgs, err := sb.Select("COUNT(*)").From("audit_events").Where(where).ToSql()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to build count query: %w", err)
+	}
+	var total int
+	if err := r.db.QueryRow(ctx, countQuery, countArgs...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count audit events: %w", err)
+	}
+
+	query, args, err := sb.
+		Select("id", "actor_id", "actor_role", "action", "resource_type", "resource_id", "company_id", "changes::text", "ip", "created_at").
+		From("audit_events").
+		Where(where).
+		OrderBy("created_at DESC", "id").
+		Offset(uint64(offset)).
+		Limit(uint64(limit)).
+		ToSql()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list audit events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*AuditEvent
+	for rows.Next() {
+		var e AuditEvent
+		if err := rows.Scan(
+			&e.ID,
+			&e.ActorID,
+			&e.ActorRole,
+			&e.Action,
+			&e.ResourceType,
+			&e.ResourceID,
+			&e.CompanyID,
+			&e.Changes,
+			&e.IP,
+			&e.CreatedAt,
+		); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan audit event: %w", err)
+		}
+		events = append(events, &e)
+	}
+	return events, total, rows.Err()
+}
@@ -2,24 +2,48 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"time"
 
 	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 var sb = squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
 
+var ErrUserNotFound = errors.New("user not found")
+
 type User struct {
 	UUID      string    `db:"uuid"`
 	Email     string    `db:"email"`
 	Password  string    `db:"password"`
 	Role      int       `db:"role"`
 	CreatedAt time.Time `db:"created_at"`
+	// EmailVerifiedAt — nil, пока пользователь не подтвердил адрес.
+	EmailVerifiedAt *time.Time `db:"email_verified_at"`
+	// Roles — все роли аккаунта (user_roles); Role — роль, с которой он создан.
+	Roles []int32 `db:"roles"`
+	// BannedAt — nil, пока аккаунт не заблокирован администратором.
+	BannedAt  *time.Time `db:"banned_at"`
+	BanReason string     `db:"ban_reason"`
+}
+
+// HasRole проверяет, выдана ли пользователю роль.
+func (u *User) HasRole(role int) bool {
+	for _, r := range u.Roles {
+		if int(r) == role {
+			return true
+		}
+	}
+	return false
 }
 
+// rolesColumn — роли пользователя одним массивом, чтобы не ходить в БД дважды.
+const rolesColumn = "ARRAY(SELECT ur.role FROM user_roles ur WHERE ur.user_id = users.uuid ORDER BY ur.role) AS roles"
+
 type AuthRepository struct {
 	db *pgxpool.Pool
 }
@@ -31,15 +55,18 @@ func NewAuthRepository(db *pgxpool.Pool) *AuthRepository {
 }
 
 func (r *AuthRepository) CreateUser(ctx context.Context, email, hashedPassword string, role int) (string, error) {
-	query, args, err := sb.
+	insertUser, args, err := sb.
 		Insert("users").
 		Columns("email", "password", "role").
 		Values(email, hashedPassword, role).
-		Suffix("RETURNING uuid").
+		Suffix("RETURNING uuid, role").
 		ToSql()
 	if err != nil {
 		return "", fmt.Errorf("failed to build query: %w", err)
 	}
+	// Пользователь и его первая роль — одним запросом, без окна, когда
+	// аккаунт уже есть, а ролей у него нет.
+	query := "WITH u AS (" + insertUser + ") INSERT INTO user_roles (user_id, role) SELECT uuid, role FROM u RETURNING user_id"
 
 	log.Printf("Creating user in database - email: %s, role: %d", email, role)
 
@@ -56,7 +83,7 @@ func (r *AuthRepository) CreateUser(ctx context.Context, email, hashedPassword s
 
 func (r *AuthRepository) FindUserByEmail(ctx context.Context, email string) (*User, error) {
 	query, args, err := sb.
-		Select("uuid", "email", "password", "role", "created_at").
+		Select("uuid", "email", "password", "role", "created_at", "email_verified_at", rolesColumn, "banned_at", "ban_reason").
 		From("users").
 		Where(squirrel.Eq{"email": email}).
 		Where(squirrel.Eq{"deleted_at": nil}).
@@ -74,6 +101,10 @@ func (r *AuthRepository) FindUserByEmail(ctx context.Context, email string) (*Us
 		&user.Password,
 		&user.Role,
 		&user.CreatedAt,
+		&user.EmailVerifiedAt,
+		&user.Roles,
+		&user.BannedAt,
+		&user.BanReason,
 	)
 	if err != nil {
 		log.Printf("User not found by email: %s, error: %v", email, err)
@@ -86,7 +117,7 @@ func (r *AuthRepository) FindUserByEmail(ctx context.Context, email string) (*Us
 
 func (r *AuthRepository) FindUserByUUID(ctx context.Context, uuid string) (*User, error) {
 	query, args, err := sb.
-		Select("uuid", "email", "password", "role", "created_at").
+		Select("uuid", "email", "password", "role", "created_at", "email_verified_at", rolesColumn, "banned_at", "ban_reason").
 		From("users").
 		Where(squirrel.Eq{"uuid": uuid}).
 		Where(squirrel.Eq{"deleted_at": nil}).
@@ -104,6 +135,10 @@ func (r *AuthRepository) FindUserByUUID(ctx context.Context, uuid string) (*User
 		&user.Password,
 		&user.Role,
 		&user.CreatedAt,
+		&user.EmailVerifiedAt,
+		&user.Roles,
+		&user.BannedAt,
+		&user.BanReason,
 	)
 	if err != nil {
 		log.Printf("User not found by uuid: %s, error: %v", uuid, err)
@@ -114,12 +149,15 @@ func (r *AuthRepository) FindUserByUUID(ctx context.Context, uuid string) (*User
 	return &user, nil
 }
 
-func (r *AuthRepository) IsUserLoggedOut(ctx context.Context, userID string) (bool, error) {
+// IsUserLoggedOut проверяет, выполнял ли пользователь «выход на всех устройствах»
+// после выпуска токена. issuedAt — iat из проверяемого токена.
+func (r *AuthRepository) IsUserLoggedOut(ctx context.Context, userID string, issuedAt time.Time) (bool, error) {
 	query, args, err := sb.
 		Select("COUNT(*)").
 		From("user_logouts").
 		Where(squirrel.Eq{"user_id": userID}).
 		Where("expires_at > ?", time.Now()).
+		Where("logged_out_at >= ?", issuedAt).
 		ToSql()
 	if err != nil {
 		return false, fmt.Errorf("failed to build logout check query: %w", err)
@@ -134,35 +172,462 @@ func (r *AuthRepository) IsUserLoggedOut(ctx context.Context, userID string) (bo
 	return count > 0, nil
 }
 
-func (r *AuthRepository) DeleteUser(ctx context.Context, userID string) error {
-	// Мягкое удаление - устанавливаем deleted_at
+// LogoutUser отзывает все токены пользователя, выпущенные до текущего момента.
+// expiresAt — момент, когда истечёт последний из них; после него запись не нужна.
+func (r *AuthRepository) LogoutUser(ctx context.Context, userID string, expiresAt time.Time) error {
+	query, args, err := sb.
+		Insert("user_logouts").
+		Columns("user_id", "logged_out_at", "expires_at").
+		Values(userID, time.Now(), expiresAt).
+		Suffix("ON CONFLICT (user_id) DO UPDATE SET logged_out_at = EXCLUDED.logged_out_at, expires_at = EXCLUDED.expires_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build logout query: %w", err)
+	}
+
+	if _, err := r.db.Exec(ctx, query, args...); err != nil {
+		log.Printf("Failed to logout user: %s, error: %v", userID, err)
+		return fmt.Errorf("failed to logout user: %w", err)
+	}
+
+	log.Printf("User logged out from all sessions: %s", userID)
+	return nil
+}
+
+// RevokeToken добавляет jti в денилист до истечения токена.
+func (r *AuthRepository) RevokeToken(ctx context.Context, jti, userID string, expiresAt time.Time) error {
+	query, args, err := sb.
+		Insert("revoked_tokens").
+		Columns("jti", "user_id", "expires_at").
+		Values(jti, userID, expiresAt).
+		Suffix("ON CONFLICT (jti) DO NOTHING").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build revoke query: %w", err)
+	}
+
+	if _, err := r.db.Exec(ctx, query, args...); err != nil {
+		log.Printf("Failed to revoke token %s for user %s, error: %v", jti, userID, err)
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+
+	log.Printf("Token revoked - jti: %s, user: %s", jti, userID)
+	return nil
+}
+
+func (r *AuthRepository) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	query, args, err := sb.
+		Select("COUNT(*)").
+		From("revoked_tokens").
+		Where(squirrel.Eq{"jti": jti}).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("failed to build revoke check query: %w", err)
+	}
+
+	var count int
+	if err := r.db.QueryRow(ctx, query, args...).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to check token revocation: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// UpdatePassword заменяет хэш пароля пользователя.
+func (r *AuthRepository) UpdatePassword(ctx context.Context, userID, hashedPassword string) error {
 	query, args, err := sb.
 		Update("users").
-		Set("deleted_at", time.Now()).
+		Set("password", hashedPassword).
+		Set("updated_at", time.Now()).
 		Where(squirrel.Eq{"uuid": userID}).
 		Where(squirrel.Eq{"deleted_at": nil}).
 		ToSql()
 	if err != nil {
-		return fmt.Errorf("failed to build delete user query: %w", err)
+		return fmt.Errorf("failed to build update password query: %w", err)
+	}
+
+	result, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		log.Printf("Failed to update password for user: %s, error: %v", userID, err)
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		log.Printf("User not found or deleted: %s", userID)
+		return fmt.Errorf("user not found or deleted")
+	}
+
+	log.Printf("Password updated for user: %s", userID)
+	return nil
+}
+
+// AddUserRole выдаёт пользователю роль. Возвращает false, если она уже была.
+func (r *AuthRepository) AddUserRole(ctx context.Context, userID string, role int) (bool, error) {
+	query, args, err := sb.
+		Insert("user_roles").
+		Columns("user_id", "role").
+		Values(userID, role).
+		Suffix("ON CONFLICT (user_id, role) DO NOTHING").
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("failed to build add role query: %w", err)
 	}
 
-	log.Printf("Deleting user: %s", userID)
+	result, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		log.Printf("Failed to add role %d to user: %s, error: %v", role, userID, err)
+		return false, fmt.Errorf("failed to add role: %w", err)
+	}
+
+	added := result.RowsAffected() > 0
+	if added {
+		log.Printf("Role %d added to user: %s", role, userID)
+	}
+	return added, nil
+}
+
+// MarkEmailVerified отмечает адрес пользователя подтверждённым (повторный вызов
+// не сдвигает исходную дату).
+func (r *AuthRepository) MarkEmailVerified(ctx context.Context, userID string) error {
+	query, args, err := sb.
+		Update("users").
+		Set("email_verified_at", squirrel.Expr("COALESCE(email_verified_at, ?)", time.Now())).
+		Set("updated_at", time.Now()).
+		Where(squirrel.Eq{"uuid": userID}).
+		Where(squirrel.Eq{"deleted_at": nil}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build verify email query: %w", err)
+	}
 
 	result, err := r.db.Exec(ctx, query, args...)
 	if err != nil {
+		log.Printf("Failed to mark email verified for user: %s, error: %v", userID, err)
+		return fmt.Errorf("failed to mark email verified: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		log.Printf("User not found or deleted: %s", userID)
+		return fmt.Errorf("user not found or deleted")
+	}
+
+	log.Printf("Email verified for user: %s", userID)
+	return nil
+}
+
+// ListUsersCreatedAfter — страница пользователей по возрастанию (created_at,
+// uuid), начиная после пары (after, afterUUID). Пустой afterUUID — с момента
+// after включительно.
+func (r *AuthRepository) ListUsersCreatedAfter(ctx context.Context, after time.Time, afterUUID string, limit int) ([]*User, error) {
+	if afterUUID == "" {
+		afterUUID = "00000000-0000-0000-0000-000000000000"
+	}
+
+	query, args, err := sb.
+		Select("uuid", "email", "role", "created_at", "email_verified_at", rolesColumn, "banned_at", "ban_reason").
+		From("users").
+		Where(squirrel.Eq{"deleted_at": nil}).
+		Where("(created_at, uuid) > (?, ?)", after, afterUUID).
+		OrderBy("created_at", "uuid").
+		Limit(uint64(limit)).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []*User
+	for rows.Next() {
+		var user User
+		if err := rows.Scan(
+			&user.UUID,
+			&user.Email,
+			&user.Role,
+			&user.CreatedAt,
+			&user.EmailVerifiedAt,
+			&user.Roles,
+			&user.BannedAt,
+			&user.BanReason,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, &user)
+	}
+	return users, rows.Err()
+}
+
+// DeleteUser удаляет аккаунт по правилу хранения Auth: строка users
+// остаётся (на её uuid ссылаются токены и записи других сервисов), но
+// помечается удалённой и обезличивается — email заменяется заглушкой
+// (адрес снова свободен для регистрации), хеш пароля стирается. Второй
+// фактор, привязки внешних провайдеров и одноразовые токены удаляются.
+//
+// Повторный вызов для уже удалённого аккаунта не ошибка (удаление
+// продолжают после сбоя). Возвращает прежний email — пустой, если аккаунт
+// уже был обезличен.
+func (r *AuthRepository) DeleteUser(ctx context.Context, userID string) (string, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var email string
+	var deletedAt *time.Time
+	err = tx.QueryRow(ctx, "SELECT email, deleted_at FROM users WHERE uuid = $1 FOR UPDATE", userID).Scan(&email, &deletedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrUserNotFound
+		}
+		return "", fmt.Errorf("failed to find user: %w", err)
+	}
+
+	placeholder := anonymizedEmail(userID)
+	if email == placeholder {
+		email = ""
+	}
+
+	query, args, err := sb.
+		Update("users").
+		Set("email", placeholder).
+		Set("password", "").
+		Set("deleted_at", squirrel.Expr("COALESCE(deleted_at, NOW())")).
+		Set("updated_at", time.Now()).
+		Where(squirrel.Eq{"uuid": userID}).
+		ToSql()
+	if err != nil {
+		return "", fmt.Errorf("failed to build delete user query: %w", err)
+	}
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
 		log.Printf("Failed to delete user: %s, error: %v", userID, err)
-		return fmt.Errorf("failed to delete user: %w", err)
+		return "", fmt.Errorf("failed to delete user: %w", err)
+	}
+
+	for _, table := range []string{
+		"user_identities",
+		"user_mfa",
+		"mfa_recovery_codes",
+		"mfa_challenges",
+		"password_reset_tokens",
+		"email_verification_tokens",
+	} {
+		if _, err := tx.Exec(ctx, "DELETE FROM "+table+" WHERE user_id = $1", userID); err != nil {
+			return "", fmt.Errorf("failed to delete %s: %w", table, err)
+		}
+	}
+	if _, err := tx.Exec(ctx, "DELETE FROM oidc_login_states WHERE link_user_id = $1", userID); err != nil {
+		return "", fmt.Errorf("failed to delete oidc login states: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", fmt.Errorf("failed to commit: %w", err)
+	}
+
+	if deletedAt != nil {
+		log.Printf("User %s was already deleted, personal data erased again", userID)
+	} else {
+		log.Printf("User deleted successfully: %s", userID)
+	}
+	return email, nil
+}
+
+// BanUser блокирует аккаунт. Повторная блокировка обновляет причину и
+// автора, но не дату. Удалённый аккаунт — ErrUserNotFound.
+func (r *AuthRepository) BanUser(ctx context.Context, userID, bannedBy, reason string) error {
+	query, args, err := sb.
+		Update("users").
+		Set("banned_at", squirrel.Expr("COALESCE(banned_at, NOW())")).
+		Set("banned_by", bannedBy).
+		Set("ban_reason", reason).
+		Set("updated_at", time.Now()).
+		Where(squirrel.Eq{"uuid": userID}).
+		Where(squirrel.Eq{"deleted_at": nil}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build ban query: %w", err)
 	}
 
+	result, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		log.Printf("Failed to ban user: %s, error: %v", userID, err)
+		return fmt.Errorf("failed to ban user: %w", err)
+	}
 	if result.RowsAffected() == 0 {
-		log.Printf("User not found or already deleted: %s", userID)
-		return fmt.Errorf("user not found or already deleted")
+		return ErrUserNotFound
 	}
 
-	log.Printf("User deleted successfully: %s", userID)
+	log.Printf("User %s banned by %s", userID, bannedBy)
 	return nil
 }
 
+// UnbanUser снимает блокировку. Не заблокированный аккаунт — не ошибка.
+func (r *AuthRepository) UnbanUser(ctx context.Context, userID string) error {
+	query, args, err := sb.
+		Update("users").
+		Set("banned_at", nil).
+		Set("banned_by", nil).
+		Set("ban_reason", "").
+		Set("updated_at", time.Now()).
+		Where(squirrel.Eq{"uuid": userID}).
+		Where(squirrel.Eq{"deleted_at": nil}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build unban query: %w", err)
+	}
+
+	result, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		log.Printf("Failed to unban user: %s, error: %v", userID, err)
+		return fmt.Errorf("failed to unban user: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+
+	log.Printf("User %s unbanned", userID)
+	return nil
+}
+
+// UserFilter — условия поиска аккаунтов администратором.
+type UserFilter struct {
+	// Query — часть email или uuid аккаунта целиком.
+	Query string
+	// Role — аккаунты с этой ролью (0 — с любой).
+	Role int
+	// Banned — только заблокированные (true) или только активные (false).
+	Banned *bool
+}
+
+// SearchUsers — страница аккаунтов по фильтру (новые первыми) и их общее
+// число.
+func (r *AuthRepository) SearchUsers(ctx context.Context, filter UserFilter, offset, limit int) ([]*User, int, error) {
+	where := squirrel.And{squirrel.Eq{"deleted_at": nil}}
+	if filter.Query != "" {
+		where = append(where, squirrel.Or{
+			squirrel.ILike{"email": "%" + filter.Query + "%"},
+			squirrel.Expr("uuid::text = ?", filter.Query),
+		})
+	}
+	if filter.Role != 0 {
+		where = append(where, squirrel.Expr("EXISTS (SELECT 1 FROM user_roles ur WHERE ur.user_id = users.uuid AND ur.role = ?)", filter.Role))
+	}
+	if filter.Banned != nil {
+		if *filter.Banned {
+			where = append(where, squirrel.NotEq{"banned_at": nil})
+		} else {
+			where = append(where, squirrel.Eq{"banned_at": nil})
+		}
+	}
+
+	countQuery, countArgs, err := sb.Select("COUNT(*)").From("users").Where(where).ToSql()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to build count query: %w", err)
+	}
+	var total int
+	if err := r.db.QueryRow(ctx, countQuery, countArgs...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	query, args, err := sb.
+		Select("uuid", "email", "role", "created_at", "email_verified_at", rolesColumn, "banned_at", "ban_reason").
+		From("users").
+		Where(where).
+		OrderBy("created_at DESC", "uuid").
+		Offset(uint64(offset)).
+		Limit(uint64(limit)).
+		ToSql()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []*User
+	for rows.Next() {
+		var user User
+		if err := rows.Scan(
+			&user.UUID,
+			&user.Email,
+			&user.Role,
+			&user.CreatedAt,
+			&user.EmailVerifiedAt,
+			&user.Roles,
+			&user.BannedAt,
+			&user.BanReason,
+		); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, &user)
+	}
+	return users, total, rows.Err()
+}
+
+// ListRoleHolders — uuid аккаунтов, которым выдана роль.
+func (r *AuthRepository) ListRoleHolders(ctx context.Context, role int) ([]string, error) {
+	query, args, err := sb.
+		Select("user_id").
+		From("user_roles").
+		Where(squirrel.Eq{"role": role}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list role holders: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan role holder: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// RemoveUserRole снимает с пользователя роль. Возвращает false, если её не было.
+func (r *AuthRepository) RemoveUserRole(ctx context.Context, userID string, role int) (bool, error) {
+	query, args, err := sb.
+		Delete("user_roles").
+		Where(squirrel.Eq{"user_id": userID, "role": role}).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("failed to build remove role query: %w", err)
+	}
+
+	result, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		log.Printf("Failed to remove role %d from user: %s, error: %v", role, userID, err)
+		return false, fmt.Errorf("failed to remove role: %w", err)
+	}
+
+	removed := result.RowsAffected() > 0
+	if removed {
+		log.Printf("Role %d removed from user: %s", role, userID)
+	}
+	return removed, nil
+}
+
+// anonymizedEmail — заглушка email удалённого аккаунта: уникальна (колонка
+// UNIQUE) и заведомо недоставляема (.invalid, RFC 2606).
+func anonymizedEmail(userID string) string {
+	return "deleted-" + userID + "@deleted.invalid"
+}
+
 // CleanupExpiredLogouts удаляет устаревшие записи logout
 func (r *AuthRepository) CleanupExpiredLogouts(ctx context.Context) error {
 	query, args, err := sb.
@@ -181,3 +646,22 @@ func (r *AuthRepository) CleanupExpiredLogouts(ctx context.Context) error {
 	log.Printf("Cleaned up expired logouts, count: %d", result.RowsAffected())
 	return nil
 }
+
+// CleanupRevokedTokens удаляет из денилиста jti уже истёкших токенов
+func (r *AuthRepository) CleanupRevokedTokens(ctx context.Context) error {
+	query, args, err := sb.
+		Delete("revoked_tokens").
+		Where("expires_at < ?", time.Now()).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build cleanup query: %w", err)
+	}
+
+	result, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to cleanup revoked tokens: %w", err)
+	}
+
+	log.Printf("Cleaned up revoked tokens, count: %d", result.RowsAffected())
+	return nil
+}
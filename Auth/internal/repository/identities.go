@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+var (
+	ErrIdentityNotFound   = errors.New("external identity not found")
+	ErrIdentityLinked     = errors.New("external identity linked to another user")
+	ErrLoginStateNotFound = errors.New("oidc login state not found")
+)
+
+// Identity — внешний аккаунт, привязанный к пользователю.
+type Identity struct {
+	Provider  string    `db:"provider"`
+	Subject   string    `db:"subject"`
+	Email     string    `db:"email"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// OIDCLoginState — незавершённый вход через внешнего провайдера.
+type OIDCLoginState struct {
+	StateHash    string
+	Provider     string
+	CodeVerifier string
+	Nonce        string
+	// Role — роль, под которой входить (0 — основная роль пользователя).
+	Role int
+	// LinkUserID — пользователь, который привязывает провайдера из профиля
+	// (пусто — обычный вход).
+	LinkUserID *string
+	ExpiresAt  time.Time
+}
+
+type IdentityRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewIdentityRepository(db *pgxpool.Pool) *IdentityRepository {
+	return &IdentityRepository{
+		db: db,
+	}
+}
+
+// FindIdentityUser возвращает пользователя, к которому привязан внешний аккаунт.
+func (r *IdentityRepository) FindIdentityUser(ctx context.Context, provider, subject string) (string, error) {
+	query, args, err := sb.
+		Select("i.user_id").
+		From("user_identities i").
+		Join("users u ON u.uuid = i.user_id").
+		Where(squirrel.Eq{"i.provider": provider}).
+		Where(squirrel.Eq{"i.subject": subject}).
+		Where(squirrel.Eq{"u.deleted_at": nil}).
+		ToSql()
+	if err != nil {
+		return "", fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var userID string
+	if err := r.db.QueryRow(ctx, query, args...).Scan(&userID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrIdentityNotFound
+		}
+		return "", fmt.Errorf("failed to find identity: %w", err)
+	}
+	return userID, nil
+}
+
+// LinkIdentity привязывает внешний аккаунт к пользователю. Повторная привязка
+// к тому же пользователю — не ошибка; к другому — ErrIdentityLinked.
+func (r *IdentityRepository) LinkIdentity(ctx context.Context, userID, provider, subject, email string) error {
+	query, args, err := sb.
+		Insert("user_identities").
+		Columns("provider", "subject", "user_id", "email").
+		Values(provider, subject, userID, email).
+		Suffix("ON CONFLICT (provider, subject) DO UPDATE SET email = EXCLUDED.email WHERE user_identities.user_id = EXCLUDED.user_id").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		log.Printf("Failed to link identity %s/%s to user: %s, error: %v", provider, subject, userID, err)
+		return fmt.Errorf("failed to link identity: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrIdentityLinked
+	}
+
+	log.Printf("Identity %s/%s linked to user: %s", provider, subject, userID)
+	return nil
+}
+
+// ListUserIdentities возвращает внешние аккаунты пользователя в порядке привязки.
+func (r *IdentityRepository) ListUserIdentities(ctx context.Context, userID string) ([]*Identity, error) {
+	query, args, err := sb.
+		Select("provider", "subject", "COALESCE(email, '')", "created_at").
+		From("user_identities").
+		Where(squirrel.Eq{"user_id": userID}).
+		OrderBy("created_at").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list identities: %w", err)
+	}
+	defer rows.Close()
+
+	var identities []*Identity
+	for rows.Next() {
+		var i Identity
+		if err := rows.Scan(&i.Provider, &i.Subject, &i.Email, &i.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan identity: %w", err)
+		}
+		identities = append(identities, &i)
+	}
+	return identities, rows.Err()
+}
+
+func (r *IdentityRepository) CreateLoginState(ctx context.Context, st *OIDCLoginState) error {
+	query, args, err := sb.
+		Insert("oidc_login_states").
+		Columns("state_hash", "provider", "code_verifier", "nonce", "role", "link_user_id", "expires_at").
+		Values(st.StateHash, st.Provider, st.CodeVerifier, st.Nonce, st.Role, st.LinkUserID, st.ExpiresAt).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := r.db.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to create oidc login state: %w", err)
+	}
+	return nil
+}
+
+// ConsumeLoginState гасит state и возвращает сохранённые параметры входа.
+// Погашенный, истёкший или выданный для другого провайдера state даёт
+// ErrLoginStateNotFound.
+func (r *IdentityRepository) ConsumeLoginState(ctx context.Context, stateHash, provider string) (*OIDCLoginState, error) {
+	now := time.Now()
+	query, args, err := sb.
+		Update("oidc_login_states").
+		Set("used_at", now).
+		Where(squirrel.Eq{"state_hash": stateHash}).
+		Where(squirrel.Eq{"provider": provider}).
+		Where(squirrel.Eq{"used_at": nil}).
+		Where("expires_at > ?", now).
+		Suffix("RETURNING state_hash, provider, code_verifier, nonce, role, link_user_id, expires_at").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var st OIDCLoginState
+	err = r.db.QueryRow(ctx, query, args...).Scan(
+		&st.StateHash,
+		&st.Provider,
+		&st.CodeVerifier,
+		&st.Nonce,
+		&st.Role,
+		&st.LinkUserID,
+		&st.ExpiresAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrLoginStateNotFound
+		}
+		return nil, fmt.Errorf("failed to consume oidc login state: %w", err)
+	}
+	return &st, nil
+}
+
+// CleanupExpiredLoginStates удаляет истёкшие state.
+func (r *IdentityRepository) CleanupExpiredLoginStates(ctx context.Context) error {
+	query, args, err := sb.
+		Delete("oidc_login_states").
+		Where("expires_at < ?", time.Now()).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build cleanup query: %w", err)
+	}
+
+	result, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to cleanup expired oidc login states: %w", err)
+	}
+
+	log.Printf("Cleaned up expired oidc login states, count: %d", result.RowsAffected())
+	return nil
+}
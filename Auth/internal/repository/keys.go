@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type SigningKey struct {
+	KID         string     `db:"kid"`
+	Algorithm   string     `db:"algorithm"`
+	PrivateKey  []byte     `db:"private_key"`
+	PublicKey   []byte     `db:"public_key"`
+	CreatedAt   time.Time  `db:"created_at"`
+	ActivatesAt time.Time  `db:"activates_at"`
+	ExpiresAt   *time.Time `db:"expires_at"`
+}
+
+type KeysRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewKeysRepository(db *pgxpool.Pool) *KeysRepository {
+	return &KeysRepository{
+		db: db,
+	}
+}
+
+func (r *KeysRepository) CreateSigningKey(ctx context.Context, key *SigningKey) error {
+	query, args, err := sb.
+		Insert("signing_keys").
+		Columns("kid", "algorithm", "private_key", "public_key", "activates_at").
+		Values(key.KID, key.Algorithm, key.PrivateKey, key.PublicKey, key.ActivatesAt).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := r.db.Exec(ctx, query, args...); err != nil {
+		log.Printf("Failed to create signing key %s: %v", key.KID, err)
+		return fmt.Errorf("failed to create signing key: %w", err)
+	}
+
+	log.Printf("Signing key created - kid: %s, activates_at: %s", key.KID, key.ActivatesAt.Format(time.RFC3339))
+	return nil
+}
+
+// ListSigningKeys возвращает все ключи, ещё пригодные для проверки подписи,
+// от самого нового к самому старому.
+func (r *KeysRepository) ListSigningKeys(ctx context.Context) ([]*SigningKey, error) {
+	query, args, err := sb.
+		Select("kid", "algorithm", "private_key", "public_key", "created_at", "activates_at", "expires_at").
+		From("signing_keys").
+		Where(squirrel.Or{
+			squirrel.Eq{"expires_at": nil},
+			squirrel.Gt{"expires_at": time.Now()},
+		}).
+		OrderBy("activates_at DESC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list signing keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []*SigningKey
+	for rows.Next() {
+		var k SigningKey
+		if err := rows.Scan(&k.KID, &k.Algorithm, &k.PrivateKey, &k.PublicKey, &k.CreatedAt, &k.ActivatesAt, &k.ExpiresAt); err != nil {
+			return nil, fmt.Errorf("failed to scan signing key: %w", err)
+		}
+		keys = append(keys, &k)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate signing keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// ExpireSigningKeys проставляет expires_at всем ключам, кроме keepKID, у которых
+// он ещё не задан: они перестают подписывать и доживают до expiresAt только
+// для проверки уже выданных токенов.
+func (r *KeysRepository) ExpireSigningKeys(ctx context.Context, keepKID string, expiresAt time.Time) error {
+	query, args, err := sb.
+		Update("signing_keys").
+		Set("expires_at", expiresAt).
+		Where(squirrel.NotEq{"kid": keepKID}).
+		Where(squirrel.Eq{"expires_at": nil}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to expire signing keys: %w", err)
+	}
+
+	log.Printf("Signing keys scheduled for expiry at %s, count: %d", expiresAt.Format(time.RFC3339), result.RowsAffected())
+	return nil
+}
+
+// DeleteExpiredSigningKeys удаляет ключи, которыми уже нечего проверять.
+func (r *KeysRepository) DeleteExpiredSigningKeys(ctx context.Context) error {
+	query, args, err := sb.
+		Delete("signing_keys").
+		Where("expires_at < ?", time.Now()).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build cleanup query: %w", err)
+	}
+
+	result, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to delete expired signing keys: %w", err)
+	}
+
+	log.Printf("Cleaned up expired signing keys, count: %d", result.RowsAffected())
+	return nil
+}
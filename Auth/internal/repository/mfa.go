@@ -0,0 +1,351 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+var (
+	ErrMFANotFound          = errors.New("mfa settings not found")
+	ErrMFAChallengeNotFound = errors.New("mfa challenge not found")
+)
+
+// MFASettings — TOTP пользователя (user_mfa).
+type MFASettings struct {
+	UserID       string     `db:"user_id"`
+	TOTPSecret   string     `db:"totp_secret"`
+	LastUsedStep int64      `db:"last_used_step"`
+	CreatedAt    time.Time  `db:"created_at"`
+	ConfirmedAt  *time.Time `db:"confirmed_at"`
+}
+
+// Enabled — TOTP подключён и подтверждён кодом из приложения.
+func (m *MFASettings) Enabled() bool {
+	return m != nil && m.ConfirmedAt != nil
+}
+
+// MFAChallenge — незавершённый вход, ожидающий второй фактор.
+type MFAChallenge struct {
+	ID         string    `db:"id"`
+	UserID     string    `db:"user_id"`
+	Role       int       `db:"role"`
+	Enrollment bool      `db:"enrollment"`
+	Attempts   int       `db:"attempts"`
+	ExpiresAt  time.Time `db:"expires_at"`
+}
+
+type MFARepository struct {
+	db *pgxpool.Pool
+}
+
+func NewMFARepository(db *pgxpool.Pool) *MFARepository {
+	return &MFARepository{
+		db: db,
+	}
+}
+
+func (r *MFARepository) GetMFA(ctx context.Context, userID string) (*MFASettings, error) {
+	query, args, err := sb.
+		Select("user_id", "totp_secret", "last_used_step", "created_at", "confirmed_at").
+		From("user_mfa").
+		Where(squirrel.Eq{"user_id": userID}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var m MFASettings
+	err = r.db.QueryRow(ctx, query, args...).Scan(
+		&m.UserID,
+		&m.TOTPSecret,
+		&m.LastUsedStep,
+		&m.CreatedAt,
+		&m.ConfirmedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrMFANotFound
+		}
+		return nil, fmt.Errorf("failed to get mfa settings: %w", err)
+	}
+	return &m, nil
+}
+
+// SavePendingTOTP сохраняет новый, ещё не подтверждённый секрет. Уже
+// подтверждённый TOTP не перезаписывается: сменить приложение можно только
+// через DeleteMFA (отключение с кодом).
+func (r *MFARepository) SavePendingTOTP(ctx context.Context, userID, secret string) error {
+	query, args, err := sb.
+		Insert("user_mfa").
+		Columns("user_id", "totp_secret").
+		Values(userID, secret).
+		Suffix(`ON CONFLICT (user_id) DO UPDATE SET
+			totp_secret = EXCLUDED.totp_secret,
+			last_used_step = 0,
+			created_at = NOW()
+			WHERE user_mfa.confirmed_at IS NULL`).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		log.Printf("Failed to save pending totp for user: %s, error: %v", userID, err)
+		return fmt.Errorf("failed to save totp secret: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("totp already confirmed for user %s", userID)
+	}
+	return nil
+}
+
+// ConfirmTOTP включает TOTP: первый принятый код подтверждает, что секрет
+// попал в приложение.
+func (r *MFARepository) ConfirmTOTP(ctx context.Context, userID string, step int64) error {
+	query, args, err := sb.
+		Update("user_mfa").
+		Set("confirmed_at", time.Now()).
+		Set("last_used_step", step).
+		Where(squirrel.Eq{"user_id": userID}).
+		Where(squirrel.Eq{"confirmed_at": nil}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to confirm totp: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrMFANotFound
+	}
+
+	log.Printf("TOTP enabled for user: %s", userID)
+	return nil
+}
+
+// UseTOTPStep атомарно запоминает шаг принятого кода. false — код с этим
+// (или более поздним) шагом уже предъявлялся.
+func (r *MFARepository) UseTOTPStep(ctx context.Context, userID string, step int64) (bool, error) {
+	query, args, err := sb.
+		Update("user_mfa").
+		Set("last_used_step", step).
+		Where(squirrel.Eq{"user_id": userID}).
+		Where("last_used_step < ?", step).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to update totp step: %w", err)
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// DeleteMFA отключает TOTP и удаляет коды восстановления.
+func (r *MFARepository) DeleteMFA(ctx context.Context, userID string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "DELETE FROM mfa_recovery_codes WHERE user_id = $1", userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+	if _, err := tx.Exec(ctx, "DELETE FROM user_mfa WHERE user_id = $1", userID); err != nil {
+		return fmt.Errorf("failed to delete mfa settings: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+
+	log.Printf("TOTP disabled for user: %s", userID)
+	return nil
+}
+
+// ReplaceRecoveryCodes заменяет все коды восстановления пользователя новыми.
+func (r *MFARepository) ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "DELETE FROM mfa_recovery_codes WHERE user_id = $1", userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	insert := sb.Insert("mfa_recovery_codes").Columns("user_id", "code_hash")
+	for _, h := range codeHashes {
+		insert = insert.Values(userID, h)
+	}
+	query, args, err := insert.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to insert recovery codes: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+	return nil
+}
+
+// UseRecoveryCode гасит код восстановления. false — кода нет или он уже
+// использован.
+func (r *MFARepository) UseRecoveryCode(ctx context.Context, userID, codeHash string) (bool, error) {
+	query, args, err := sb.
+		Update("mfa_recovery_codes").
+		Set("used_at", time.Now()).
+		Where(squirrel.Eq{"user_id": userID}).
+		Where(squirrel.Eq{"code_hash": codeHash}).
+		Where(squirrel.Eq{"used_at": nil}).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to use recovery code: %w", err)
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// CountRecoveryCodes — сколько неиспользованных кодов осталось.
+func (r *MFARepository) CountRecoveryCodes(ctx context.Context, userID string) (int, error) {
+	query, args, err := sb.
+		Select("COUNT(*)").
+		From("mfa_recovery_codes").
+		Where(squirrel.Eq{"user_id": userID}).
+		Where(squirrel.Eq{"used_at": nil}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var count int
+	if err := r.db.QueryRow(ctx, query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count recovery codes: %w", err)
+	}
+	return count, nil
+}
+
+func (r *MFARepository) CreateChallenge(ctx context.Context, userID, tokenHash string, role int, enrollment bool, expiresAt time.Time) error {
+	query, args, err := sb.
+		Insert("mfa_challenges").
+		Columns("user_id", "token_hash", "role", "enrollment", "expires_at").
+		Values(userID, tokenHash, role, enrollment, expiresAt).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := r.db.Exec(ctx, query, args...); err != nil {
+		log.Printf("Failed to create mfa challenge for user: %s, error: %v", userID, err)
+		return fmt.Errorf("failed to create mfa challenge: %w", err)
+	}
+	return nil
+}
+
+// FindChallenge возвращает действующий (не погашенный и не истёкший) челлендж.
+func (r *MFARepository) FindChallenge(ctx context.Context, tokenHash string) (*MFAChallenge, error) {
+	query, args, err := sb.
+		Select("id", "user_id", "role", "enrollment", "attempts", "expires_at").
+		From("mfa_challenges").
+		Where(squirrel.Eq{"token_hash": tokenHash}).
+		Where(squirrel.Eq{"used_at": nil}).
+		Where("expires_at > ?", time.Now()).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var c MFAChallenge
+	err = r.db.QueryRow(ctx, query, args...).Scan(
+		&c.ID,
+		&c.UserID,
+		&c.Role,
+		&c.Enrollment,
+		&c.Attempts,
+		&c.ExpiresAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrMFAChallengeNotFound
+		}
+		return nil, fmt.Errorf("failed to find mfa challenge: %w", err)
+	}
+	return &c, nil
+}
+
+// RegisterChallengeFailure увеличивает счётчик неверных кодов и возвращает его.
+func (r *MFARepository) RegisterChallengeFailure(ctx context.Context, id string) (int, error) {
+	query, args, err := sb.
+		Update("mfa_challenges").
+		Set("attempts", squirrel.Expr("attempts + 1")).
+		Where(squirrel.Eq{"id": id}).
+		Suffix("RETURNING attempts").
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var attempts int
+	if err := r.db.QueryRow(ctx, query, args...).Scan(&attempts); err != nil {
+		return 0, fmt.Errorf("failed to register mfa failure: %w", err)
+	}
+	return attempts, nil
+}
+
+// ConsumeChallenge гасит челлендж. false — его уже погасил параллельный запрос.
+func (r *MFARepository) ConsumeChallenge(ctx context.Context, id string) (bool, error) {
+	query, args, err := sb.
+		Update("mfa_challenges").
+		Set("used_at", time.Now()).
+		Where(squirrel.Eq{"id": id}).
+		Where(squirrel.Eq{"used_at": nil}).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to consume mfa challenge: %w", err)
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// CleanupExpiredChallenges удаляет истёкшие челленджи.
+func (r *MFARepository) CleanupExpiredChallenges(ctx context.Context) error {
+	query, args, err := sb.
+		Delete("mfa_challenges").
+		Where("expires_at < ?", time.Now()).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build cleanup query: %w", err)
+	}
+
+	result, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to cleanup expired mfa challenges: %w", err)
+	}
+
+	log.Printf("Cleaned up expired mfa challenges, count: %d", result.RowsAffected())
+	return nil
+}
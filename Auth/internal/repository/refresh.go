@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+var ErrRefreshTokenNotFound = errors.New("refresh token not found")
+
+type RefreshToken struct {
+	ID         string     `db:"id"`
+	UserID     string     `db:"user_id"`
+	FamilyID   string     `db:"family_id"`
+	TokenHash  string     `db:"token_hash"`
+	Role       int        `db:"role"`
+	ExpiresAt  time.Time  `db:"expires_at"`
+	CreatedAt  time.Time  `db:"created_at"`
+	UsedAt     *time.Time `db:"used_at"`
+	RevokedAt  *time.Time `db:"revoked_at"`
+	ReplacedBy *string    `db:"replaced_by"`
+}
+
+type RefreshRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewRefreshRepository(db *pgxpool.Pool) *RefreshRepository {
+	return &RefreshRepository{
+		db: db,
+	}
+}
+
+// CreateRefreshToken сохраняет хэш нового refresh-токена. Пустой familyID —
+// новая семья (первый логин), генерируется на стороне БД.
+func (r *RefreshRepository) CreateRefreshToken(ctx context.Context, userID, familyID, tokenHash string, role int, expiresAt time.Time) (string, error) {
+	family := squirrel.Expr("gen_random_uuid()")
+	if familyID != "" {
+		family = squirrel.Expr("?::uuid", familyID)
+	}
+
+	query, args, err := sb.
+		Insert("refresh_tokens").
+		Columns("user_id", "family_id", "token_hash", "role", "expires_at").
+		Values(userID, family, tokenHash, role, expiresAt).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return "", fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var id string
+	if err := r.db.QueryRow(ctx, query, args...).Scan(&id); err != nil {
+		log.Printf("Failed to create refresh token for user: %s, error: %v", userID, err)
+		return "", fmt.Errorf("failed to create refresh token: %w", err)
+	}
+
+	log.Printf("Refresh token created - user: %s, id: %s", userID, id)
+	return id, nil
+}
+
+func (r *RefreshRepository) FindRefreshTokenByHash(ctx context.Context, tokenHash string) (*RefreshToken, error) {
+	query, args, err := sb.
+		Select("id", "user_id", "family_id", "token_hash", "role", "expires_at",
+			"created_at", "used_at", "revoked_at", "replaced_by").
+		From("refresh_tokens").
+		Where(squirrel.Eq{"token_hash": tokenHash}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var t RefreshToken
+	err = r.db.QueryRow(ctx, query, args...).Scan(
+		&t.ID,
+		&t.UserID,
+		&t.FamilyID,
+		&t.TokenHash,
+		&t.Role,
+		&t.ExpiresAt,
+		&t.CreatedAt,
+		&t.UsedAt,
+		&t.RevokedAt,
+		&t.ReplacedBy,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRefreshTokenNotFound
+		}
+		return nil, fmt.Errorf("failed to find refresh token: %w", err)
+	}
+
+	return &t, nil
+}
+
+// MarkRefreshTokenUsed гасит токен. Условие used_at IS NULL делает операцию
+// атомарной: из двух параллельных Refresh с одним токеном выиграет только один,
+// второй получит false и будет трактоваться как повторное использование.
+func (r *RefreshRepository) MarkRefreshTokenUsed(ctx context.Context, id string) (bool, error) {
+	query, args, err := sb.
+		Update("refresh_tokens").
+		Set("used_at", time.Now()).
+		Where(squirrel.Eq{"id": id}).
+		Where(squirrel.Eq{"used_at": nil}).
+		Where(squirrel.Eq{"revoked_at": nil}).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark refresh token used: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// SetReplacedBy связывает погашенный токен с его преемником — по этой цепочке
+// видно, как шла ротация внутри семьи.
+func (r *RefreshRepository) SetReplacedBy(ctx context.Context, id, replacedBy string) error {
+	query, args, err := sb.
+		Update("refresh_tokens").
+		Set("replaced_by", replacedBy).
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := r.db.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to set replaced_by: %w", err)
+	}
+	return nil
+}
+
+// RevokeFamily отзывает все ещё живые токены семьи.
+func (r *RefreshRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	query, args, err := sb.
+		Update("refresh_tokens").
+		Set("revoked_at", time.Now()).
+		Where(squirrel.Eq{"family_id": familyID}).
+		Where(squirrel.Eq{"revoked_at": nil}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+
+	log.Printf("Refresh token family revoked: %s, tokens: %d", familyID, result.RowsAffected())
+	return nil
+}
+
+// RevokeUserRefreshTokens отзывает все refresh-токены пользователя (удаление аккаунта и т.п.).
+func (r *RefreshRepository) RevokeUserRefreshTokens(ctx context.Context, userID string) error {
+	query, args, err := sb.
+		Update("refresh_tokens").
+		Set("revoked_at", time.Now()).
+		Where(squirrel.Eq{"user_id": userID}).
+		Where(squirrel.Eq{"revoked_at": nil}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to revoke user refresh tokens: %w", err)
+	}
+
+	log.Printf("Refresh tokens revoked for user: %s, tokens: %d", userID, result.RowsAffected())
+	return nil
+}
+
+// CleanupExpiredRefreshTokens удаляет истёкшие refresh-токены.
+func (r *RefreshRepository) CleanupExpiredRefreshTokens(ctx context.Context) error {
+	query, args, err := sb.
+		Delete("refresh_tokens").
+		Where("expires_at < ?", time.Now()).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build cleanup query: %w", err)
+	}
+
+	result, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to cleanup expired refresh tokens: %w", err)
+	}
+
+	log.Printf("Cleaned up expired refresh tokens, count: %d", result.RowsAffected())
+	return nil
+}
@@ -3,22 +3,154 @@ package repository
 import (
 	"context"
 	"github.com/jackc/pgx/v4/pgxpool"
+	"time"
 )
 
 type Auth interface {
 	CreateUser(ctx context.Context, email, hashedPassword string, role int) (string, error)
 	FindUserByEmail(ctx context.Context, email string) (*User, error)
 	FindUserByUUID(ctx context.Context, uuid string) (*User, error)
-	DeleteUser(ctx context.Context, userID string) error
-	IsUserLoggedOut(ctx context.Context, userID string) (bool, error)
+	ListUsersCreatedAfter(ctx context.Context, after time.Time, afterUUID string, limit int) ([]*User, error)
+	DeleteUser(ctx context.Context, userID string) (string, error)
+	UpdatePassword(ctx context.Context, userID, hashedPassword string) error
+	MarkEmailVerified(ctx context.Context, userID string) error
+	AddUserRole(ctx context.Context, userID string, role int) (bool, error)
+	RemoveUserRole(ctx context.Context, userID string, role int) (bool, error)
+	ListRoleHolders(ctx context.Context, role int) ([]string, error)
+	BanUser(ctx context.Context, userID, bannedBy, reason string) error
+	UnbanUser(ctx context.Context, userID string) error
+	SearchUsers(ctx context.Context, filter UserFilter, offset, limit int) ([]*User, int, error)
+	IsUserLoggedOut(ctx context.Context, userID string, issuedAt time.Time) (bool, error)
+	LogoutUser(ctx context.Context, userID string, expiresAt time.Time) error
+	RevokeToken(ctx context.Context, jti, userID string, expiresAt time.Time) error
+	IsTokenRevoked(ctx context.Context, jti string) (bool, error)
+	CleanupExpiredLogouts(ctx context.Context) error
+	CleanupRevokedTokens(ctx context.Context) error
+}
+
+type Refresh interface {
+	CreateRefreshToken(ctx context.Context, userID, familyID, tokenHash string, role int, expiresAt time.Time) (string, error)
+	FindRefreshTokenByHash(ctx context.Context, tokenHash string) (*RefreshToken, error)
+	MarkRefreshTokenUsed(ctx context.Context, id string) (bool, error)
+	SetReplacedBy(ctx context.Context, id, replacedBy string) error
+	RevokeFamily(ctx context.Context, familyID string) error
+	RevokeUserRefreshTokens(ctx context.Context, userID string) error
+	CleanupExpiredRefreshTokens(ctx context.Context) error
+}
+
+type Sessions interface {
+	CreateSession(ctx context.Context, s *Session) (string, error)
+	GetSession(ctx context.Context, id string) (*Session, error)
+	ListUserSessions(ctx context.Context, userID string) ([]*Session, error)
+	TouchSession(ctx context.Context, id string, seenAt time.Time) error
+	ExtendSession(ctx context.Context, id, ip, userAgent string, expiresAt time.Time) error
+	RevokeSession(ctx context.Context, userID, id string) (bool, error)
+	RevokeUserSessions(ctx context.Context, userID, exceptID string) ([]string, error)
+	CleanupExpiredSessions(ctx context.Context) error
+}
+
+type PasswordReset interface {
+	CreatePasswordResetToken(ctx context.Context, userID, tokenHash string, expiresAt time.Time) error
+	ConsumePasswordResetToken(ctx context.Context, tokenHash string) (string, error)
+	InvalidateUserResetTokens(ctx context.Context, userID string) error
+	CleanupExpiredResetTokens(ctx context.Context) error
+}
+
+type EmailVerification interface {
+	CreateVerificationToken(ctx context.Context, userID, tokenHash string, expiresAt time.Time) error
+	ConsumeVerificationToken(ctx context.Context, tokenHash string) (string, error)
+	InvalidateUserVerificationTokens(ctx context.Context, userID string) error
+	CleanupExpiredVerificationTokens(ctx context.Context) error
+}
+
+type Attempts interface {
+	GetActiveLocks(ctx context.Context, keys []string) (map[string]time.Time, error)
+	RegisterFailure(ctx context.Context, key string, windowStart time.Time) (int, error)
+	LockUntil(ctx context.Context, key string, until time.Time) error
+	ResetAttempts(ctx context.Context, key string) error
+	CleanupLoginAttempts(ctx context.Context, olderThan time.Time) error
+}
+
+type MFA interface {
+	GetMFA(ctx context.Context, userID string) (*MFASettings, error)
+	SavePendingTOTP(ctx context.Context, userID, secret string) error
+	ConfirmTOTP(ctx context.Context, userID string, step int64) error
+	UseTOTPStep(ctx context.Context, userID string, step int64) (bool, error)
+	DeleteMFA(ctx context.Context, userID string) error
+	ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error
+	UseRecoveryCode(ctx context.Context, userID, codeHash string) (bool, error)
+	CountRecoveryCodes(ctx context.Context, userID string) (int, error)
+	CreateChallenge(ctx context.Context, userID, tokenHash string, role int, enrollment bool, expiresAt time.Time) error
+	FindChallenge(ctx context.Context, tokenHash string) (*MFAChallenge, error)
+	RegisterChallengeFailure(ctx context.Context, id string) (int, error)
+	ConsumeChallenge(ctx context.Context, id string) (bool, error)
+	CleanupExpiredChallenges(ctx context.Context) error
+}
+
+type Identities interface {
+	FindIdentityUser(ctx context.Context, provider, subject string) (string, error)
+	LinkIdentity(ctx context.Context, userID, provider, subject, email string) error
+	ListUserIdentities(ctx context.Context, userID string) ([]*Identity, error)
+	CreateLoginState(ctx context.Context, st *OIDCLoginState) error
+	ConsumeLoginState(ctx context.Context, stateHash, provider string) (*OIDCLoginState, error)
+	CleanupExpiredLoginStates(ctx context.Context) error
+}
+
+type Keys interface {
+	CreateSigningKey(ctx context.Context, key *SigningKey) error
+	ListSigningKeys(ctx context.Context) ([]*SigningKey, error)
+	ExpireSigningKeys(ctx context.Context, keepKID string, expiresAt time.Time) error
+	DeleteExpiredSigningKeys(ctx context.Context) error
+}
+
+type APIKeys interface {
+	CreateAPIKey(ctx context.Context, key *APIKey) (string, time.Time, error)
+	FindAPIKeyByHash(ctx context.Context, keyHash string) (*APIKey, error)
+	ListCompanyAPIKeys(ctx context.Context, companyID string) ([]*APIKey, error)
+	TouchAPIKey(ctx context.Context, id string, usedAt time.Time, interval time.Duration) error
+	RevokeAPIKey(ctx context.Context, companyID, id string) (string, error)
+	RevokeCompanyAPIKeys(ctx context.Context, companyID string) error
+	CleanupAPIKeys(ctx context.Context) error
+}
+
+type AdminActions interface {
+	RecordAdminAction(ctx context.Context, a *AdminAction) (string, time.Time, error)
+	ListAdminActions(ctx context.Context, filter AdminActionFilter, offset, limit int) ([]*AdminAction, int, error)
+}
+
+type Audit interface {
+	RecordAuditEvent(ctx context.Context, e *AuditEvent) (string, time.Time, error)
+	ListAuditEvents(ctx context.Context, filter AuditFilter, offset, limit int) ([]*AuditEvent, int, error)
 }
 
 type Repository struct {
-	Auth Auth
+	Auth              Auth
+	Refresh           Refresh
+	Sessions          Sessions
+	PasswordReset     PasswordReset
+	EmailVerification EmailVerification
+	Attempts          Attempts
+	MFA               MFA
+	Identities        Identities
+	Keys              Keys
+	APIKeys           APIKeys
+	AdminActions      AdminActions
+	Audit             Audit
 }
 
 func NewRepository(db *pgxpool.Pool) *Repository {
 	return &Repository{
-		Auth: NewAuthRepository(db),
+		Auth:              NewAuthRepository(db),
+		Refresh:           NewRefreshRepository(db),
+		Sessions:          NewSessionRepository(db),
+		PasswordReset:     NewPasswordResetRepository(db),
+		EmailVerification: NewEmailVerificationRepository(db),
+		Attempts:          NewAttemptsRepository(db),
+		MFA:               NewMFARepository(db),
+		Identities:        NewIdentityRepository(db),
+		Keys:              NewKeysRepository(db),
+		APIKeys:           NewAPIKeyRepository(db),
+		AdminActions:      NewAdminActionRepository(db),
+		Audit:             NewAuditRepository(db),
 	}
 }
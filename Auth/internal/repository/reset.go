@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+var ErrResetTokenNotFound = errors.New("password reset token not found")
+
+type PasswordResetRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPasswordResetRepository(db *pgxpool.Pool) *PasswordResetRepository {
+	return &PasswordResetRepository{
+		db: db,
+	}
+}
+
+func (r *PasswordResetRepository) CreatePasswordResetToken(ctx context.Context, userID, tokenHash string, expiresAt time.Time) error {
+	query, args, err := sb.
+		Insert("password_reset_tokens").
+		Columns("user_id", "token_hash", "expires_at").
+		Values(userID, tokenHash, expiresAt).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := r.db.Exec(ctx, query, args...); err != nil {
+		log.Printf("Failed to create password reset token for user: %s, error: %v", userID, err)
+		return fmt.Errorf("failed to create password reset token: %w", err)
+	}
+
+	log.Printf("Password reset token created for user: %s", userID)
+	return nil
+}
+
+// ConsumePasswordResetToken гасит токен и возвращает id пользователя. Проверка
+// срока и used_at IS NULL в одном UPDATE: повторно или параллельно
+// предъявленный токен получит ErrResetTokenNotFound.
+func (r *PasswordResetRepository) ConsumePasswordResetToken(ctx context.Context, tokenHash string) (string, error) {
+	now := time.Now()
+	query, args, err := sb.
+		Update("password_reset_tokens").
+		Set("used_at", now).
+		Where(squirrel.Eq{"token_hash": tokenHash}).
+		Where(squirrel.Eq{"used_at": nil}).
+		Where("expires_at > ?", now).
+		Suffix("RETURNING user_id").
+		ToSql()
+	if err != nil {
+		return "", fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var userID string
+	if err := r.db.QueryRow(ctx, query, args...).Scan(&userID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrResetTokenNotFound
+		}
+		return "", fmt.Errorf("failed to consume password reset token: %w", err)
+	}
+
+	return userID, nil
+}
+
+// InvalidateUserResetTokens гасит все ещё не использованные токены пользователя:
+// действительна только последняя отправленная ссылка.
+func (r *PasswordResetRepository) InvalidateUserResetTokens(ctx context.Context, userID string) error {
+	query, args, err := sb.
+		Update("password_reset_tokens").
+		Set("used_at", time.Now()).
+		Where(squirrel.Eq{"user_id": userID}).
+		Where(squirrel.Eq{"used_at": nil}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := r.db.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to invalidate password reset tokens: %w", err)
+	}
+	return nil
+}
+
+// CleanupExpiredResetTokens удаляет истёкшие токены сброса пароля.
+func (r *PasswordResetRepository) CleanupExpiredResetTokens(ctx context.Context) error {
+	query, args, err := sb.
+		Delete("password_reset_tokens").
+		Where("expires_at < ?", time.Now()).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build cleanup query: %w", err)
+	}
+
+	result, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to cleanup expired password reset tokens: %w", err)
+	}
+
+	log.Printf("Cleaned up expired password reset tokens, count: %d", result.RowsAffected())
+	return nil
+}
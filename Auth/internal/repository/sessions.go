@@ -0,0 +1,269 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+var ErrSessionNotFound = errors.New("session not found")
+
+// Session — вход пользователя на одном устройстве.
+type Session struct {
+	ID         string     `db:"id"`
+	UserID     string     `db:"user_id"`
+	Role       int        `db:"role"`
+	Device     string     `db:"device"`
+	UserAgent  string     `db:"user_agent"`
+	IP         string     `db:"ip"`
+	CreatedAt  time.Time  `db:"created_at"`
+	LastSeenAt time.Time  `db:"last_seen_at"`
+	ExpiresAt  time.Time  `db:"expires_at"`
+	RevokedAt  *time.Time `db:"revoked_at"`
+}
+
+// Active — сессия не отозвана и не истекла.
+func (s *Session) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+var sessionColumns = []string{"id", "user_id", "role", "device", "user_agent", "ip",
+	"created_at", "last_seen_at", "expires_at", "revoked_at"}
+
+type SessionRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewSessionRepository(db *pgxpool.Pool) *SessionRepository {
+	return &SessionRepository{
+		db: db,
+	}
+}
+
+func scanSession(row pgx.Row) (*Session, error) {
+	var s Session
+	err := row.Scan(
+		&s.ID,
+		&s.UserID,
+		&s.Role,
+		&s.Device,
+		&s.UserAgent,
+		&s.IP,
+		&s.CreatedAt,
+		&s.LastSeenAt,
+		&s.ExpiresAt,
+		&s.RevokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// CreateSession сохраняет новую сессию и возвращает её id.
+func (r *SessionRepository) CreateSession(ctx context.Context, s *Session) (string, error) {
+	query, args, err := sb.
+		Insert("sessions").
+		Columns("user_id", "role", "device", "user_agent", "ip", "expires_at").
+		Values(s.UserID, s.Role, s.Device, s.UserAgent, s.IP, s.ExpiresAt).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return "", fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var id string
+	if err := r.db.QueryRow(ctx, query, args...).Scan(&id); err != nil {
+		log.Printf("Failed to create session for user: %s, error: %v", s.UserID, err)
+		return "", fmt.Errorf("failed to create session: %w", err)
+	}
+
+	log.Printf("Session created - user: %s, id: %s", s.UserID, id)
+	return id, nil
+}
+
+func (r *SessionRepository) GetSession(ctx context.Context, id string) (*Session, error) {
+	query, args, err := sb.
+		Select(sessionColumns...).
+		From("sessions").
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	s, err := scanSession(r.db.QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	return s, nil
+}
+
+// ListUserSessions возвращает активные сессии пользователя, последние — первыми.
+func (r *SessionRepository) ListUserSessions(ctx context.Context, userID string) ([]*Session, error) {
+	query, args, err := sb.
+		Select(sessionColumns...).
+		From("sessions").
+		Where(squirrel.Eq{"user_id": userID}).
+		Where(squirrel.Eq{"revoked_at": nil}).
+		Where("expires_at > ?", time.Now()).
+		OrderBy("last_seen_at DESC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []*Session
+	for rows.Next() {
+		s, err := scanSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, rows.Err()
+}
+
+// TouchSession обновляет время последней активности.
+func (r *SessionRepository) TouchSession(ctx context.Context, id string, seenAt time.Time) error {
+	query, args, err := sb.
+		Update("sessions").
+		Set("last_seen_at", seenAt).
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := r.db.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to touch session: %w", err)
+	}
+	return nil
+}
+
+// ExtendSession — обновление токенов в сессии: продлевает её до expiresAt и
+// запоминает, откуда клиент пришёл в последний раз (пустые ip и userAgent
+// не перезаписывают прежние).
+func (r *SessionRepository) ExtendSession(ctx context.Context, id, ip, userAgent string, expiresAt time.Time) error {
+	now := time.Now()
+	q := sb.
+		Update("sessions").
+		Set("last_seen_at", now).
+		Set("expires_at", expiresAt).
+		Where(squirrel.Eq{"id": id}).
+		Where(squirrel.Eq{"revoked_at": nil})
+	if ip != "" {
+		q = q.Set("ip", ip)
+	}
+	if userAgent != "" {
+		q = q.Set("user_agent", userAgent)
+	}
+
+	query, args, err := q.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := r.db.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to extend session: %w", err)
+	}
+	return nil
+}
+
+// RevokeSession отзывает сессию пользователя. false — такой активной сессии
+// у пользователя нет.
+func (r *SessionRepository) RevokeSession(ctx context.Context, userID, id string) (bool, error) {
+	query, args, err := sb.
+		Update("sessions").
+		Set("revoked_at", time.Now()).
+		Where(squirrel.Eq{"id": id}).
+		Where(squirrel.Eq{"user_id": userID}).
+		Where(squirrel.Eq{"revoked_at": nil}).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	log.Printf("Session revoked - user: %s, id: %s", userID, id)
+	return result.RowsAffected() > 0, nil
+}
+
+// RevokeUserSessions отзывает все сессии пользователя, кроме exceptID (пусто —
+// все), и возвращает id отозванных.
+func (r *SessionRepository) RevokeUserSessions(ctx context.Context, userID, exceptID string) ([]string, error) {
+	q := sb.
+		Update("sessions").
+		Set("revoked_at", time.Now()).
+		Where(squirrel.Eq{"user_id": userID}).
+		Where(squirrel.Eq{"revoked_at": nil}).
+		Suffix("RETURNING id")
+	if exceptID != "" {
+		q = q.Where(squirrel.NotEq{"id": exceptID})
+	}
+
+	query, args, err := q.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke user sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan session id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to revoke user sessions: %w", err)
+	}
+
+	log.Printf("Sessions revoked for user: %s, count: %d", userID, len(ids))
+	return ids, nil
+}
+
+// CleanupExpiredSessions удаляет истёкшие сессии. Отозванные живут до своего
+// истечения: по ним ValidateToken отклоняет ещё не истёкшие access-токены.
+func (r *SessionRepository) CleanupExpiredSessions(ctx context.Context) error {
+	query, args, err := sb.
+		Delete("sessions").
+		Where("expires_at < ?", time.Now()).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build cleanup query: %w", err)
+	}
+
+	result, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to cleanup expired sessions: %w", err)
+	}
+
+	log.Printf("Cleaned up expired sessions, count: %d", result.RowsAffected())
+	return nil
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+var ErrVerificationTokenNotFound = errors.New("email verification token not found")
+
+type EmailVerificationRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewEmailVerificationRepository(db *pgxpool.Pool) *EmailVerificationRepository {
+	return &EmailVerificationRepository{
+		db: db,
+	}
+}
+
+func (r *EmailVerificationRepository) CreateVerificationToken(ctx context.Context, userID, tokenHash string, expiresAt time.Time) error {
+	query, args, err := sb.
+		Insert("email_verification_tokens").
+		Columns("user_id", "token_hash", "expires_at").
+		Values(userID, tokenHash, expiresAt).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := r.db.Exec(ctx, query, args...); err != nil {
+		log.Printf("Failed to create verification token for user: %s, error: %v", userID, err)
+		return fmt.Errorf("failed to create verification token: %w", err)
+	}
+
+	log.Printf("Email verification token created for user: %s", userID)
+	return nil
+}
+
+// ConsumeVerificationToken гасит токен и возвращает id пользователя (так же
+// атомарно, как ConsumePasswordResetToken).
+func (r *EmailVerificationRepository) ConsumeVerificationToken(ctx context.Context, tokenHash string) (string, error) {
+	now := time.Now()
+	query, args, err := sb.
+		Update("email_verification_tokens").
+		Set("used_at", now).
+		Where(squirrel.Eq{"token_hash": tokenHash}).
+		Where(squirrel.Eq{"used_at": nil}).
+		Where("expires_at > ?", now).
+		Suffix("RETURNING user_id").
+		ToSql()
+	if err != nil {
+		return "", fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var userID string
+	if err := r.db.QueryRow(ctx, query, args...).Scan(&userID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrVerificationTokenNotFound
+		}
+		return "", fmt.Errorf("failed to consume verification token: %w", err)
+	}
+
+	return userID, nil
+}
+
+// InvalidateUserVerificationTokens гасит ранее отправленные ссылки пользователя.
+func (r *EmailVerificationRepository) InvalidateUserVerificationTokens(ctx context.Context, userID string) error {
+	query, args, err := sb.
+		Update("email_verification_tokens").
+		Set("used_at", time.Now()).
+		Where(squirrel.Eq{"user_id": userID}).
+		Where(squirrel.Eq{"used_at": nil}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := r.db.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to invalidate verification tokens: %w", err)
+	}
+	return nil
+}
+
+// CleanupExpiredVerificationTokens удаляет истёкшие токены подтверждения.
+func (r *EmailVerificationRepository) CleanupExpiredVerificationTokens(ctx context.Context) error {
+	query, args, err := sb.
+		Delete("email_verification_tokens").
+		Where("expires_at < ?", time.Now()).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build cleanup query: %w", err)
+	}
+
+	result, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to cleanup expired verification tokens: %w", err)
+	}
+
+	log.Printf("Cleaned up expired email verification tokens, count: %d", result.RowsAffected())
+	return nil
+}
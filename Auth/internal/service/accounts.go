@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	authv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/auth/v1"
+	"github.com/studjobs/hh_for_students/auth/internal/repository"
+)
+
+const (
+	defaultAccountsPageSize = 100
+	maxAccountsPageSize     = 500
+)
+
+// ListAccounts — аккаунты, созданные после createdAfter, по возрастанию
+// времени создания. Нужен сверке в Gateway: она ищет аккаунты, для которых
+// регистрация не довела до конца профиль или компанию. Возвращает страницу
+// и токен следующей («» — это последняя).
+func (s *AuthService) ListAccounts(ctx context.Context, createdAfter time.Time, pageToken string, limit int) ([]*authv1.Account, string, error) {
+	if limit <= 0 {
+		limit = defaultAccountsPageSize
+	}
+	limit = min(limit, maxAccountsPageSize)
+
+	after, afterUUID := createdAfter, ""
+	if pageToken != "" {
+		var err error
+		if after, afterUUID, err = decodeAccountsPageToken(pageToken); err != nil {
+			return nil, "", ErrInvalidPageToken
+		}
+	}
+
+	users, err := s.repo.Auth.ListUsersCreatedAfter(ctx, after, afterUUID, limit)
+	if err != nil {
+		return nil, "", err
+	}
+
+	accounts := make([]*authv1.Account, 0, len(users))
+	for _, user := range users {
+		accounts = append(accounts, toProtoAccount(user))
+	}
+
+	next := ""
+	if len(users) == limit {
+		last := users[len(users)-1]
+		next = encodeAccountsPageToken(last.CreatedAt, last.UUID)
+	}
+	return accounts, next, nil
+}
+
+// ExportAccount — всё, что Auth хранит о пользователе, для выгрузки
+// персональных данных: аккаунт, привязанные провайдеры, активные сессии и
+// состояние второго фактора. Секреты (хеш пароля, TOTP-секрет, коды
+// восстановления) в выгрузку не попадают.
+func (s *AuthService) ExportAccount(ctx context.Context, userUUID string) (*authv1.AccountExport, error) {
+	user, err := s.repo.Auth.FindUserByUUID(ctx, userUUID)
+	if err != nil || user == nil {
+		log.Printf("Export account failed - user not found: %s", userUUID)
+		return nil, ErrUserNotFound
+	}
+
+	identities, err := s.repo.Identities.ListUserIdentities(ctx, user.UUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list identities: %w", err)
+	}
+	sessions, err := s.repo.Sessions.ListUserSessions(ctx, user.UUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	settings, err := s.mfaSettings(ctx, user.UUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load mfa settings: %w", err)
+	}
+
+	export := &authv1.AccountExport{
+		Account:    toProtoAccount(user),
+		MfaEnabled: settings.Enabled(),
+	}
+	if user.EmailVerifiedAt != nil {
+		export.EmailVerifiedAt = user.EmailVerifiedAt.Format(time.RFC3339)
+	}
+	for _, identity := range identities {
+		export.Identities = append(export.Identities, &authv1.LinkedIdentity{
+			Provider: identity.Provider,
+			Email:    identity.Email,
+			LinkedAt: identity.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	for _, session := range sessions {
+		export.Sessions = append(export.Sessions, toProtoSession(session, ""))
+	}
+	return export, nil
+}
+
+func toProtoAccount(user *repository.User) *authv1.Account {
+	account := &authv1.Account{
+		UserUuid:  user.UUID,
+		Email:     user.Email,
+		Role:      authv1.Role(user.Role),
+		Roles:     rolesOf(user),
+		CreatedAt: user.CreatedAt.Format(time.RFC3339),
+	}
+	if user.BannedAt != nil {
+		account.BannedAt = user.BannedAt.Format(time.RFC3339)
+		account.BanReason = user.BanReason
+	}
+	return account
+}
+
+func encodeAccountsPageToken(createdAt time.Time, uuid string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(createdAt.Format(time.RFC3339Nano) + "|" + uuid))
+}
+
+func decodeAccountsPageToken(token string) (time.Time, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+	createdAt, uuid, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return time.Time{}, "", ErrInvalidPageToken
+	}
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+	return t, uuid, nil
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	authv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/auth/v1"
+	"github.com/studjobs/hh_for_students/auth/internal/repository"
+)
+
+const (
+	defaultAdminPageSize = 20
+	maxAdminPageSize     = 100
+)
+
+var ErrInvalidAdminAction = errors.New("admin_uuid and action are required, details must be a JSON object")
+
+// SyncAdmins приводит роль ROLE_ADMIN в соответствие со списком emails
+// (ADMIN_EMAILS): аккаунтам из списка роль выдаётся, у остальных снимается.
+// Аккаунт должен быть уже зарегистрирован — сама регистрация под ROLE_ADMIN
+// закрыта (см. selfServiceRole). Снятая роль гасит токены администратора:
+// ValidateToken проверяет, что роль токена всё ещё выдана.
+func (s *AuthService) SyncAdmins(ctx context.Context, emails []string) error {
+	role := int(authv1.Role_ROLE_ADMIN)
+
+	admins := make(map[string]bool, len(emails))
+	for _, email := range emails {
+		user, err := s.repo.Auth.FindUserByEmail(ctx, email)
+		if err != nil || user == nil {
+			log.Printf("Admin sync: account %s not found, it must be registered first", email)
+			continue
+		}
+		admins[user.UUID] = true
+		added, err := s.repo.Auth.AddUserRole(ctx, user.UUID, role)
+		if err != nil {
+			return fmt.Errorf("failed to grant admin role to %s: %w", email, err)
+		}
+		if added {
+			log.Printf("Admin sync: admin role granted to %s (%s)", email, user.UUID)
+		}
+	}
+
+	holders, err := s.repo.Auth.ListRoleHolders(ctx, role)
+	if err != nil {
+		return err
+	}
+	for _, userUUID := range holders {
+		if admins[userUUID] {
+			continue
+		}
+		if _, err := s.repo.Auth.RemoveUserRole(ctx, userUUID, role); err != nil {
+			return fmt.Errorf("failed to revoke admin role from %s: %w", userUUID, err)
+		}
+		log.Printf("Admin sync: admin role revoked from %s", userUUID)
+	}
+	return nil
+}
+
+// BanUser блокирует аккаунт: новые токены не выдаются, выданные перестают
+// проходить ValidateToken, ключи интеграций компании — ValidateAPIKey.
+// Сессии и refresh-токены отзываются сразу, так что после разблокировки
+// пользователь входит заново. Администраторов (и себя) заблокировать нельзя:
+// их снимают через ADMIN_EMAILS.
+func (s *AuthService) BanUser(ctx context.Context, adminUUID, userUUID, reason string) (*authv1.Account, error) {
+	user, err := s.repo.Auth.FindUserByUUID(ctx, userUUID)
+	if err != nil || user == nil {
+		log.Printf("Ban failed - user not found: %s", userUUID)
+		return nil, ErrUserNotFound
+	}
+	if user.UUID == adminUUID || user.HasRole(int(authv1.Role_ROLE_ADMIN)) {
+		log.Printf("Ban rejected - user %s is an administrator", user.UUID)
+		return nil, ErrCannotBanAdmin
+	}
+
+	if err := s.repo.Auth.BanUser(ctx, user.UUID, adminUUID, reason); err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	// Блокировка уже действует (ValidateToken смотрит banned_at); отзыв —
+	// чтобы разблокировка не вернула старые сессии.
+	if err := s.repo.Auth.LogoutUser(ctx, user.UUID, time.Now().Add(s.token.TokenDuration())); err != nil {
+		log.Printf("Ban: failed to revoke access tokens for user: %s, error: %v", user.UUID, err)
+	}
+	if _, err := s.revokeUserSessions(ctx, user.UUID, ""); err != nil {
+		log.Printf("Ban: failed to revoke sessions for user: %s, error: %v", user.UUID, err)
+	}
+	if err := s.repo.Refresh.RevokeUserRefreshTokens(ctx, user.UUID); err != nil {
+		log.Printf("Ban: failed to revoke refresh tokens for user: %s, error: %v", user.UUID, err)
+	}
+
+	log.Printf("User %s banned by admin %s", user.UUID, adminUUID)
+	return s.account(ctx, user.UUID)
+}
+
+// UnbanUser снимает блокировку. Разблокировать незаблокированный аккаунт — не ошибка.
+func (s *AuthService) UnbanUser(ctx context.Context, userUUID string) (*authv1.Account, error) {
+	if err := s.repo.Auth.UnbanUser(ctx, userUUID); err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return s.account(ctx, userUUID)
+}
+
+// SearchAccounts — страница аккаунтов для администратора (новые первыми) и
+// общее число подходящих под фильтр.
+func (s *AuthService) SearchAccounts(ctx context.Context, filter repository.UserFilter, page, limit int) ([]*authv1.Account, int, error) {
+	page, limit = adminPage(page, limit)
+
+	users, total, err := s.repo.Auth.SearchUsers(ctx, filter, (page-1)*limit, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	accounts := make([]*authv1.Account, 0, len(users))
+	for _, user := range users {
+		accounts = append(accounts, toProtoAccount(user))
+	}
+	return accounts, total, nil
+}
+
+// RecordAdminAction пишет действие администратора в журнал.
+func (s *AuthService) RecordAdminAction(ctx context.Context, action *repository.AdminAction) (*authv1.AdminAction, error) {
+	if action.AdminID == "" || action.Action == "" {
+		return nil, ErrInvalidAdminAction
+	}
+	if action.Details != "" {
+		var details map[string]any
+		if err := json.Unmarshal([]byte(action.Details), &details); err != nil {
+			return nil, ErrInvalidAdminAction
+		}
+	}
+
+	id, createdAt, err := s.repo.AdminActions.RecordAdminAction(ctx, action)
+	if err != nil {
+		return nil, err
+	}
+	action.ID, action.CreatedAt = id, createdAt
+	if action.Details == "" {
+		action.Details = "{}"
+	}
+
+	log.Printf("Admin action recorded - admin: %s, action: %s, target: %s %s, status: %d",
+		action.AdminID, action.Action, action.TargetType, action.TargetID, action.Status)
+	return toProtoAdminAction(action), nil
+}
+
+// ListAdminActions — страница журнала действий администраторов (новые первыми).
+func (s *AuthService) ListAdminActions(ctx context.Context, filter repository.AdminActionFilter, page, limit int) ([]*authv1.AdminAction, int, error) {
+	page, limit = adminPage(page, limit)
+
+	actions, total, err := s.repo.AdminActions.ListAdminActions(ctx, filter, (page-1)*limit, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	out := make([]*authv1.AdminAction, 0, len(actions))
+	for _, a := range actions {
+		out = append(out, toProtoAdminAction(a))
+	}
+	return out, total, nil
+}
+
+// account — аккаунт в том виде, в каком его видит администратор.
+func (s *AuthService) account(ctx context.Context, userUUID string) (*authv1.Account, error) {
+	user, err := s.repo.Auth.FindUserByUUID(ctx, userUUID)
+	if err != nil || user == nil {
+		return nil, ErrUserNotFound
+	}
+	return toProtoAccount(user), nil
+}
+
+func adminPage(page, limit int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = defaultAdminPageSize
+	}
+	return page, min(limit, maxAdminPageSize)
+}
+
+func toProtoAdminAction(a *repository.AdminAction) *authv1.AdminAction {
+	return &authv1.AdminAction{
+		Id:         a.ID,
+		AdminUuid:  a.AdminID,
+		Action:     a.Action,
+		TargetType: a.TargetType,
+		TargetId:   a.TargetID,
+		Details:    a.Details,
+		Status:     int32(a.Status),
+		Ip:         a.IP,
+		CreatedAt:  a.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,273 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	authv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/auth/v1"
+	"github.com/studjobs/hh_for_students/auth/internal/repository"
+)
+
+// Права API-ключей. Gateway сопоставляет их со своими маршрутами: ключ
+// открывает только маршруты, для которых у него есть право.
+const (
+	ScopeVacanciesRead     = "vacancies:read"
+	ScopeVacanciesWrite    = "vacancies:write"
+	ScopeApplicationsRead  = "applications:read"
+	ScopeApplicationsWrite = "applications:write"
+	ScopeTasksRead         = "tasks:read"
+	ScopeTasksWrite        = "tasks:write"
+	ScopeCompanyRead       = "company:read"
+)
+
+var apiKeyScopes = []string{
+	ScopeVacanciesRead,
+	ScopeVacanciesWrite,
+	ScopeApplicationsRead,
+	ScopeApplicationsWrite,
+	ScopeTasksRead,
+	ScopeTasksWrite,
+	ScopeCompanyRead,
+}
+
+// apiKeyPrefix отличает API-ключ от JWT и refresh-токена (и в логах, и в
+// сканерах утечек секретов).
+const apiKeyPrefix = "sjk_"
+
+// apiKeyDisplayLength — сколько первых символов ключа хранится открыто,
+// чтобы владелец узнал ключ в списке.
+const apiKeyDisplayLength = len(apiKeyPrefix) + 8
+
+// apiKeyTouchInterval — не чаще этого обновляем last_used_at.
+const apiKeyTouchInterval = time.Minute
+
+// maxAPIKeyNameLength — длина name в таблице api_keys.
+const maxAPIKeyNameLength = 100
+
+var (
+	ErrAPIKeyNotFound     = errors.New("api key not found")
+	ErrAPIKeyForbidden    = errors.New("api keys are available to company owners only")
+	ErrAPIKeyLimit        = errors.New("too many active api keys")
+	ErrInvalidAPIKeyScope = errors.New("unknown api key scope")
+	ErrInvalidAPIKeyName  = errors.New("api key name is required")
+	ErrInvalidAPIKeyTTL   = errors.New("api key lifetime exceeds the allowed maximum")
+)
+
+// APIKeyConfig — ограничения на ключи интеграций.
+type APIKeyConfig struct {
+	// DefaultTTL — срок жизни ключа, если при создании он не указан.
+	DefaultTTL time.Duration
+	// MaxTTL — самый долгий срок, который можно запросить.
+	MaxTTL time.Duration
+	// MaxPerCompany — сколько действующих ключей может быть у компании.
+	MaxPerCompany int
+}
+
+// companyOwnerSession — ключами управляет только владелец компании,
+// вошедший под этой ролью.
+func (s *AuthService) companyOwnerSession(ctx context.Context, accessToken string) (*Claims, error) {
+	claims, err := s.currentSession(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Role != authv1.Role_ROLE_COMPANY_OWNER {
+		return nil, ErrAPIKeyForbidden
+	}
+	return claims, nil
+}
+
+// CreateAPIKey выпускает ключ компании. Сам ключ возвращается только здесь:
+// в БД хранится его хэш.
+func (s *AuthService) CreateAPIKey(ctx context.Context, accessToken, name string, scopes []string, expiresInDays int) (*authv1.ApiKey, string, error) {
+	claims, err := s.companyOwnerSession(ctx, accessToken)
+	if err != nil {
+		return nil, "", err
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > maxAPIKeyNameLength {
+		return nil, "", ErrInvalidAPIKeyName
+	}
+	scopes, err = normalizeScopes(scopes)
+	if err != nil {
+		return nil, "", err
+	}
+
+	ttl := s.apiKeys.DefaultTTL
+	if expiresInDays > 0 {
+		ttl = time.Duration(expiresInDays) * 24 * time.Hour
+	}
+	if s.apiKeys.MaxTTL > 0 && ttl > s.apiKeys.MaxTTL {
+		return nil, "", ErrInvalidAPIKeyTTL
+	}
+
+	active, err := s.repo.APIKeys.ListCompanyAPIKeys(ctx, claims.UserUUID)
+	if err != nil {
+		return nil, "", err
+	}
+	if s.apiKeys.MaxPerCompany > 0 && len(active) >= s.apiKeys.MaxPerCompany {
+		log.Printf("Create api key rejected - company %s has %d active keys", claims.UserUUID, len(active))
+		return nil, "", ErrAPIKeyLimit
+	}
+
+	token, _, err := newOpaqueToken()
+	if err != nil {
+		return nil, "", err
+	}
+	key := apiKeyPrefix + token
+
+	record := &repository.APIKey{
+		CompanyID: claims.UserUUID,
+		Name:      name,
+		Prefix:    key[:apiKeyDisplayLength],
+		KeyHash:   hashOpaqueToken(key),
+		Scopes:    scopes,
+	}
+	if ttl > 0 {
+		expiresAt := time.Now().Add(ttl)
+		record.ExpiresAt = &expiresAt
+	}
+
+	record.ID, record.CreatedAt, err = s.repo.APIKeys.CreateAPIKey(ctx, record)
+	if err != nil {
+		return nil, "", err
+	}
+
+	log.Printf("API key %s (%s) created for company %s, scopes: %v", record.ID, record.Prefix, claims.UserUUID, scopes)
+	return toProtoAPIKey(record), key, nil
+}
+
+// ListAPIKeys возвращает действующие ключи компании текущего владельца.
+func (s *AuthService) ListAPIKeys(ctx context.Context, accessToken string) ([]*authv1.ApiKey, error) {
+	claims, err := s.companyOwnerSession(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := s.repo.APIKeys.ListCompanyAPIKeys(ctx, claims.UserUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*authv1.ApiKey, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, toProtoAPIKey(key))
+	}
+	return result, nil
+}
+
+// RevokeAPIKey отзывает ключ компании и возвращает его хэш: по нему Gateway
+// сбрасывает закэшированный результат проверки ключа.
+func (s *AuthService) RevokeAPIKey(ctx context.Context, accessToken, keyID string) (string, error) {
+	claims, err := s.companyOwnerSession(ctx, accessToken)
+	if err != nil {
+		return "", err
+	}
+
+	keyHash, err := s.repo.APIKeys.RevokeAPIKey(ctx, claims.UserUUID, keyID)
+	if err != nil {
+		if errors.Is(err, repository.ErrAPIKeyNotFound) {
+			return "", ErrAPIKeyNotFound
+		}
+		return "", err
+	}
+
+	log.Printf("API key %s of company %s revoked", keyID, claims.UserUUID)
+	return keyHash, nil
+}
+
+// ValidateAPIKey проверяет ключ интеграции. Ключ действует, пока не отозван,
+// не истёк и его владелец остаётся владельцем компании. Запросы по ключу
+// идут от имени владельца под ролью ROLE_COMPANY_OWNER, но только в пределах
+// прав ключа.
+func (s *AuthService) ValidateAPIKey(ctx context.Context, key string) (*authv1.ApiKeyValidation, error) {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return &authv1.ApiKeyValidation{Valid: false}, nil
+	}
+
+	record, err := s.repo.APIKeys.FindAPIKeyByHash(ctx, hashOpaqueToken(key))
+	if err != nil {
+		if errors.Is(err, repository.ErrAPIKeyNotFound) {
+			log.Printf("API key validation failed - unknown key")
+			return &authv1.ApiKeyValidation{Valid: false}, nil
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if !record.Active(now) {
+		log.Printf("API key validation failed - key %s revoked or expired", record.ID)
+		return &authv1.ApiKeyValidation{Valid: false}, nil
+	}
+
+	user, err := s.repo.Auth.FindUserByUUID(ctx, record.CompanyID)
+	if err != nil || user == nil {
+		log.Printf("API key validation failed - owner %s not found", record.CompanyID)
+		return &authv1.ApiKeyValidation{Valid: false}, nil
+	}
+	if !user.HasRole(int(authv1.Role_ROLE_COMPANY_OWNER)) {
+		log.Printf("API key validation failed - user %s is no longer a company owner", record.CompanyID)
+		return &authv1.ApiKeyValidation{Valid: false}, nil
+	}
+	if user.BannedAt != nil {
+		log.Printf("API key validation failed - owner %s is banned", record.CompanyID)
+		return &authv1.ApiKeyValidation{Valid: false}, nil
+	}
+
+	if err := s.repo.APIKeys.TouchAPIKey(ctx, record.ID, now, apiKeyTouchInterval); err != nil {
+		log.Printf("Failed to touch api key %s: %v", record.ID, err)
+	}
+
+	validation := &authv1.ApiKeyValidation{
+		Valid:         true,
+		KeyId:         record.ID,
+		CompanyId:     record.CompanyID,
+		Scopes:        record.Scopes,
+		EmailVerified: user.EmailVerifiedAt != nil,
+	}
+	if record.ExpiresAt != nil {
+		validation.ExpiresAt = record.ExpiresAt.Format(time.RFC3339)
+	}
+	return validation, nil
+}
+
+// normalizeScopes проверяет права и убирает повторы.
+func normalizeScopes(scopes []string) ([]string, error) {
+	result := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		if !slices.Contains(apiKeyScopes, scope) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidAPIKeyScope, scope)
+		}
+		if !slices.Contains(result, scope) {
+			result = append(result, scope)
+		}
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("%w: at least one scope is required", ErrInvalidAPIKeyScope)
+	}
+	slices.Sort(result)
+	return result, nil
+}
+
+func toProtoAPIKey(key *repository.APIKey) *authv1.ApiKey {
+	result := &authv1.ApiKey{
+		Id:        key.ID,
+		Name:      key.Name,
+		Prefix:    key.Prefix,
+		Scopes:    key.Scopes,
+		CreatedAt: key.CreatedAt.Format(time.RFC3339),
+	}
+	if key.ExpiresAt != nil {
+		result.ExpiresAt = key.ExpiresAt.Format(time.RFC3339)
+	}
+	if key.LastUsedAt != nil {
+		result.LastUsedAt = key.LastUsedAt.Format(time.RFC3339)
+	}
+	return result
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	authv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/auth/v1"
+	"github.com/studjobs/hh_for_students/auth/internal/repository"
+)
+
+var ErrInvalidAuditEvent = errors.New("action and resource_type are required, changes must be a JSON object")
+
+// RecordAuditEvent пишет бизнес-действие в журнал аудита. Журнал только
+// дополняется: записи не редактируются и не удаляются.
+func (s *AuthService) RecordAuditEvent(ctx context.Context, event *repository.AuditEvent) (*authv1.AuditEvent, error) {
+	if event.Action == "" || event.ResourceType == "" {
+		return nil, ErrInvalidAuditEvent
+	}
+	if event.Changes != "" {
+		var changes map[string]any
+		if err := json.Unmarshal([]byte(event.Changes), &changes); err != nil {
+			return nil, ErrInvalidAuditEvent
+		}
+	}
+
+	id, createdAt, err := s.repo.Audit.RecordAuditEvent(ctx, event)
+	if err != nil {
+		return nil, err
+	}
+	event.ID, event.CreatedAt = id, createdAt
+	if event.Changes == "" {
+		event.Changes = "{}"
+	}
+
+	log.Printf("Audit event recorded - actor: %s (%s), action: %s, resource: %s %s, company: %s",
+		event.ActorID, event.ActorRole, event.Action, event.ResourceType, event.ResourceID, event.CompanyID)
+	return toProtoAuditEvent(event), nil
+}
+
+// ListAuditEvents — страница журнала аудита (новые первыми).
+func (s *AuthService) ListAuditEvents(ctx context.Context, filter repository.AuditFilter, page, limit int) ([]*authv1.AuditEvent, int, error) {
+	page, limit = adminPage(page, limit)
+
+	events, total, err := s.repo.Audit.ListAuditEvents(ctx, filter, (page-1)*limit, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	out := make([]*authv1.AuditEvent, 0, len(events))
+	for _, e := range events {
+		out = append(out, toProtoAuditEvent(e))
+	}
+	return out, total, nil
+}
+
+func toProtoAuditEvent(e *repository.AuditEvent) *authv1.AuditEvent {
+	return &authv1.AuditEvent{
+		Id:           e.ID,
+		ActorUuid:    e.ActorID,
+		ActorRole:    e.ActorRole,
+		Action:       e.Action,
+		ResourceType: e.ResourceType,
+		ResourceId:   e.ResourceID,
+		CompanyId:    e.CompanyID,
+		Changes:      e.Changes,
+		Ip:           e.IP,
+		CreatedAt:    e.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -2,69 +2,114 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	authv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/auth/v1"
+	"github.com/studjobs/hh_for_students/auth/internal/mailer"
+	"github.com/studjobs/hh_for_students/auth/internal/metrics"
 	"github.com/studjobs/hh_for_students/auth/internal/repository"
-	"golang.org/x/crypto/bcrypt"
 	"log"
+	"time"
 )
 
 type AuthService struct {
-	repo  *repository.Repository
-	token ITokenManager
+	repo            *repository.Repository
+	token           ITokenManager
+	refreshDuration time.Duration
+	mailer          mailer.Mailer
+	email           EmailConfig
+	lockout         LockoutConfig
+	mfa             MFAConfig
+	oidc            OIDCConfig
+	passwords       PasswordConfig
+	apiKeys         APIKeyConfig
 }
 
-func NewAuthService(repo *repository.Repository, token ITokenManager) *AuthService {
+func NewAuthService(repo *repository.Repository, token ITokenManager, refreshDuration time.Duration, mail mailer.Mailer, email EmailConfig, lockout LockoutConfig, mfa MFAConfig, oidc OIDCConfig, passwords PasswordConfig, apiKeys APIKeyConfig) *AuthService {
 	return &AuthService{
-		repo:  repo,
-		token: token,
+		repo:            repo,
+		token:           token,
+		refreshDuration: refreshDuration,
+		mailer:          mail,
+		email:           email,
+		lockout:         lockout,
+		mfa:             mfa,
+		oidc:            oidc,
+		passwords:       passwords,
+		apiKeys:         apiKeys,
 	}
 }
 
-func (s *AuthService) AuthenticateUser(ctx context.Context, email, password string, role authv1.Role) (*authv1.AuthResponse, error) {
-	log.Printf("Authenticating user - email: %s, role: %v", email, role)
+func (s *AuthService) AuthenticateUser(ctx context.Context, email, password string, role authv1.Role, clientIP string) (*authv1.AuthResponse, error) {
+	log.Printf("Authenticating user - email: %s, role: %v, ip: %s", email, role, clientIP)
+
+	accountKey, ipKey := accountLockKey(email), ipLockKey(clientIP)
+	if err := s.checkLockout(ctx, accountKey, ipKey); err != nil {
+		log.Printf("Authentication rejected - %v, email: %s", err, email)
+		metrics.LoginAttempts.WithLabelValues("locked").Inc()
+		return nil, err
+	}
 
 	user, err := s.repo.Auth.FindUserByEmail(ctx, email)
 	if err != nil {
 		log.Printf("Authentication failed - user not found: %s", email)
+		s.recordLoginFailure(ctx, accountKey, ipKey)
 		return nil, ErrInvalidCredentials
 	}
 
 	log.Printf("User found - uuid: %s, db_role: %d, requested_role: %d", user.UUID, user.Role, role)
 
-	if int(role) != user.Role {
-		log.Printf("Authentication failed - role mismatch for user %s: expected %d, got %d", email, user.Role, role)
+	if !user.HasRole(int(role)) {
+		log.Printf("Authentication failed - role %d not assigned to user %s (roles: %v)", role, email, user.Roles)
+		s.recordLoginFailure(ctx, accountKey, ipKey)
 		return nil, ErrInvalidCredentials
 	}
 
 	log.Printf("Verifying password for user: %s", email)
-	if err = s.verifyPassword(user.Password, password); err != nil {
+	needsRehash, err := s.verifyPassword(user.Password, password)
+	if err != nil {
 		log.Printf("Authentication failed - invalid password for user: %s", email)
+		s.recordLoginFailure(ctx, accountKey, ipKey)
 		return nil, ErrInvalidCredentials
 	}
+	if needsRehash {
+		s.rehashPassword(ctx, user.UUID, password)
+	}
+
+	metrics.LoginAttempts.WithLabelValues("success").Inc()
+	if s.lockout.enabled() {
+		if err := s.repo.Attempts.ResetAttempts(ctx, accountKey); err != nil {
+			log.Printf("Failed to reset login attempts for %s: %v", email, err)
+		}
+	}
 
-	log.Printf("Generating token for user: %s", email)
-	token, err := s.token.GenerateToken(user.UUID, user.Email, role)
+	log.Printf("Generating tokens for user: %s", email)
+	resp, err := s.completeLogin(ctx, user, role)
 	if err != nil {
 		log.Printf("Token generation failed for user %s: %v", email, err)
-		return nil, fmt.Errorf("failed to generate token: %w", err)
+		return nil, err
 	}
 
 	log.Printf("Authentication successful for user: %s", email)
-	return &authv1.AuthResponse{
-		Token:    token,
-		UserUuid: user.UUID,
-		Role:     role,
-	}, nil
+	return resp, nil
 }
 
 func (s *AuthService) RegisterUser(ctx context.Context, email, password string, role authv1.Role) (*authv1.AuthResponse, error) {
 	log.Printf("Registering user - email: %s, role: %v", email, role)
 
+	if !selfServiceRole(role) {
+		log.Printf("Registration rejected - role %v cannot be self-assigned, email: %s", role, email)
+		return nil, ErrRoleNotSelfService
+	}
+
 	existingUser, err := s.repo.Auth.FindUserByEmail(ctx, email)
 	if err == nil && existingUser != nil {
-		log.Printf("Registration failed - user already exists: %s", email)
-		return nil, ErrUserAlreadyExists
+		return s.addRoleToExisting(ctx, existingUser, password, role)
+	}
+
+	if err := s.passwords.Policy.Check(password); err != nil {
+		log.Printf("Registration failed - password rejected for %s: %v", email, err)
+		return nil, err
 	}
 
 	log.Printf("Hashing password for user: %s", email)
@@ -81,29 +126,34 @@ func (s *AuthService) RegisterUser(ctx context.Context, email, password string,
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
-	log.Printf("Generating token for new user: %s", email)
-	token, err := s.token.GenerateToken(userUUID, email, role)
+	log.Printf("Generating tokens for new user: %s", email)
+	newUser := &repository.User{UUID: userUUID, Email: email, Role: int(role), Roles: []int32{int32(role)}}
+	resp, err := s.completeLogin(ctx, newUser, role)
 	if err != nil {
 		log.Printf("Token generation failed for new user %s: %v", email, err)
-		return nil, fmt.Errorf("failed to generate token: %w", err)
+		return nil, err
+	}
+	resp.NewAccount = true
+
+	// Письмо с подтверждением — best effort: регистрацию не откатываем,
+	// ссылку можно запросить повторно (RequestEmailVerification).
+	if err := s.sendVerification(ctx, userUUID, email); err != nil {
+		log.Printf("Failed to send verification email for user %s: %v", userUUID, err)
 	}
 
 	log.Printf("Registration successful for user: %s, uuid: %s", email, userUUID)
-	return &authv1.AuthResponse{
-		Token:    token,
-		UserUuid: userUUID,
-		Role:     role,
-	}, nil
+	return resp, nil
 }
 
 func (s *AuthService) ValidateToken(ctx context.Context, token string) (*authv1.TokenValidation, error) {
-	log.Printf("Validating token: %s...", token[:10])
+	log.Printf("Validating token: %s...", token[:min(10, len(token))])
 
-	userUUID, role, err := s.token.ValidateToken(token)
+	claims, err := s.token.ValidateToken(token)
 	if err != nil {
 		log.Printf("Token validation failed: %v", err)
 		return &authv1.TokenValidation{Valid: false}, nil
 	}
+	userUUID := claims.UserUUID
 
 	log.Printf("Token validated - user_uuid: %s, checking user existence", userUUID)
 
@@ -114,8 +164,43 @@ func (s *AuthService) ValidateToken(ctx context.Context, token string) (*authv1.
 		return &authv1.TokenValidation{Valid: false}, nil
 	}
 
-	// Проверяем, не выполнил ли пользователь logout
-	isLoggedOut, err := s.repo.Auth.IsUserLoggedOut(ctx, userUUID)
+	// Аккаунт мог быть заблокирован администратором после выпуска токена
+	if user.BannedAt != nil {
+		log.Printf("Token validation failed - user is banned: %s", userUUID)
+		return &authv1.TokenValidation{Valid: false}, nil
+	}
+
+	// Роль, под которой выпущен токен, могла быть снята с аккаунта
+	if !user.HasRole(int(claims.Role)) {
+		log.Printf("Token validation failed - role %v no longer assigned to user: %s", claims.Role, userUUID)
+		return &authv1.TokenValidation{Valid: false}, nil
+	}
+
+	// Проверяем, не отозван ли именно этот токен
+	if claims.ID != "" {
+		isRevoked, err := s.repo.Auth.IsTokenRevoked(ctx, claims.ID)
+		if err != nil {
+			log.Printf("Failed to check revocation for jti: %s, error: %v", claims.ID, err)
+			return &authv1.TokenValidation{Valid: false}, nil
+		}
+		if isRevoked {
+			log.Printf("Token validation failed - token revoked: %s", claims.ID)
+			return &authv1.TokenValidation{Valid: false}, nil
+		}
+	}
+
+	// Сессию токена могли завершить из списка устройств
+	if claims.SessionID != "" && !s.checkSession(ctx, claims.SessionID, userUUID) {
+		log.Printf("Token validation failed - session revoked: %s", claims.SessionID)
+		return &authv1.TokenValidation{Valid: false}, nil
+	}
+
+	// Проверяем, не выполнил ли пользователь logout со всех устройств после выпуска токена
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+	isLoggedOut, err := s.repo.Auth.IsUserLoggedOut(ctx, userUUID, issuedAt)
 	if err != nil {
 		log.Printf("Failed to check logout status for user: %s, error: %v", userUUID, err)
 		return &authv1.TokenValidation{Valid: false}, nil
@@ -128,39 +213,83 @@ func (s *AuthService) ValidateToken(ctx context.Context, token string) (*authv1.
 
 	log.Printf("Token validation successful for user: %s", userUUID)
 	return &authv1.TokenValidation{
-		Valid:    true,
-		UserUuid: userUUID,
-		Role:     role,
+		Valid:         true,
+		UserUuid:      userUUID,
+		Role:          claims.Role,
+		EmailVerified: user.EmailVerifiedAt != nil,
+		Roles:         rolesOf(user),
 	}, nil
 }
 
+// DeleteUser удаляет аккаунт (см. repository.DeleteUser) и отзывает все его
+// токены и сессии. Повторный вызов для удалённого аккаунта не ошибка.
 func (s *AuthService) DeleteUser(ctx context.Context, userID string) error {
 	log.Printf("Service: Deleting user: %s", userID)
 
-	err := s.repo.Auth.DeleteUser(ctx, userID)
+	email, err := s.repo.Auth.DeleteUser(ctx, userID)
 	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return ErrUserNotFound
+		}
 		log.Printf("Service: Delete user failed for user: %s, error: %v", userID, err)
 		return fmt.Errorf("delete user failed: %w", err)
 	}
 
+	// Отзыв не откатывает удаление: вход в удалённый аккаунт и так
+	// невозможен, а повтор удаления отзовёт остатки.
+	if err := s.repo.Auth.LogoutUser(ctx, userID, time.Now().Add(s.token.TokenDuration())); err != nil {
+		log.Printf("Service: failed to revoke access tokens for user: %s, error: %v", userID, err)
+	}
+	if _, err := s.revokeUserSessions(ctx, userID, ""); err != nil {
+		log.Printf("Service: failed to revoke sessions for user: %s, error: %v", userID, err)
+	}
+	if err := s.repo.Refresh.RevokeUserRefreshTokens(ctx, userID); err != nil {
+		log.Printf("Service: failed to revoke refresh tokens for user: %s, error: %v", userID, err)
+	}
+	if err := s.repo.APIKeys.RevokeCompanyAPIKeys(ctx, userID); err != nil {
+		log.Printf("Service: failed to revoke api keys for user: %s, error: %v", userID, err)
+	}
+	if email != "" {
+		if err := s.repo.Attempts.ResetAttempts(ctx, accountLockKey(email)); err != nil {
+			log.Printf("Service: failed to reset login attempts for user: %s, error: %v", userID, err)
+		}
+	}
+
 	log.Printf("Service: User deleted successfully: %s", userID)
 	return nil
 }
 
 func (s *AuthService) hashPassword(password string) (string, error) {
-	log.Printf("Hashing password")
-	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	log.Printf("Hashing password with %s", s.passwords.Hasher.Current().Name())
+	hashed, err := s.passwords.Hasher.Hash(password)
 	if err != nil {
 		log.Printf("Password hashing error: %v", err)
 	}
-	return string(hashedBytes), err
+	return hashed, err
 }
 
-func (s *AuthService) verifyPassword(hashedPassword, password string) error {
+// verifyPassword проверяет пароль по хешу любого известного алгоритма.
+// true — пароль верный, но хеш пора пересчитать текущим алгоритмом.
+func (s *AuthService) verifyPassword(hashedPassword, password string) (bool, error) {
 	log.Printf("Verifying password")
-	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+	needsRehash, err := s.passwords.Hasher.Verify(hashedPassword, password)
 	if err != nil {
 		log.Printf("Password verification failed: %v", err)
 	}
-	return err
+	return needsRehash, err
+}
+
+// rehashPassword пересчитывает хеш после успешного входа: пароль в открытом
+// виде есть только сейчас. Ошибка не мешает входу — попробуем в следующий раз.
+func (s *AuthService) rehashPassword(ctx context.Context, userUUID, password string) {
+	hashed, err := s.hashPassword(password)
+	if err != nil {
+		log.Printf("Failed to rehash password for user %s: %v", userUUID, err)
+		return
+	}
+	if err := s.repo.Auth.UpdatePassword(ctx, userUUID, hashed); err != nil {
+		log.Printf("Failed to store rehashed password for user %s: %v", userUUID, err)
+		return
+	}
+	log.Printf("Password rehashed with %s for user: %s", s.passwords.Hasher.Current().Name(), userUUID)
 }
@@ -0,0 +1,271 @@
+package service
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/studjobs/hh_for_students/auth/internal/repository"
+)
+
+const algEdDSA = "EdDSA"
+
+var ErrUnknownSigningKey = errors.New("unknown signing key")
+
+// JWK — публичный ключ в формате RFC 8037 (OKP / Ed25519).
+type JWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+}
+
+// JWKS — документ со всеми ключами, пригодными для проверки подписи.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+type signingKey struct {
+	kid         string
+	private     ed25519.PrivateKey
+	public      ed25519.PublicKey
+	activatesAt time.Time
+}
+
+// KeyRing держит в памяти ключи подписи из signing_keys.
+//
+// Ротация: новый ключ создаётся с activates_at = now + propagationDelay, чтобы
+// сервисы, кэширующие JWKS, успели его подтянуть до появления первых токенов с
+// этим kid. Старый ключ подписывает до активации нового, а проверяет ещё
+// tokenDuration после — пока не истечёт последний выпущенный им токен.
+//
+// Несколько инстансов Auth синхронизируются через БД: каждый периодически
+// перечитывает таблицу (Reload), поэтому ключ, созданный соседом, подхватится.
+type KeyRing struct {
+	repo             repository.Keys
+	rotationInterval time.Duration
+	propagationDelay time.Duration
+	tokenDuration    time.Duration
+
+	mu   sync.RWMutex
+	keys []*signingKey // от нового к старому
+}
+
+func NewKeyRing(repo repository.Keys, cfg JWTConfig) *KeyRing {
+	return &KeyRing{
+		repo:             repo,
+		rotationInterval: cfg.KeyRotationInterval,
+		propagationDelay: cfg.KeyPropagationDelay,
+		tokenDuration:    cfg.TokenDuration,
+	}
+}
+
+// Init загружает ключи и создаёт первый, если таблица пуста. Вызывается при старте.
+func (k *KeyRing) Init(ctx context.Context) error {
+	if err := k.Reload(ctx); err != nil {
+		return err
+	}
+	if _, _, err := k.SigningKey(); err == nil {
+		return nil
+	}
+	log.Printf("KeyRing: no active signing key, generating initial key")
+	// Первый ключ активируется сразу: токенов, подписанных чем-то ещё, пока нет.
+	if _, err := k.createKey(ctx, time.Now()); err != nil {
+		return err
+	}
+	return k.Reload(ctx)
+}
+
+// Reload перечитывает ключи из БД.
+func (k *KeyRing) Reload(ctx context.Context) error {
+	stored, err := k.repo.ListSigningKeys(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load signing keys: %w", err)
+	}
+
+	keys := make([]*signingKey, 0, len(stored))
+	for _, s := range stored {
+		if s.Algorithm != algEdDSA {
+			log.Printf("KeyRing: skipping key %s with unsupported algorithm %s", s.KID, s.Algorithm)
+			continue
+		}
+		parsed, err := x509.ParsePKCS8PrivateKey(s.PrivateKey)
+		if err != nil {
+			log.Printf("KeyRing: failed to parse key %s: %v", s.KID, err)
+			continue
+		}
+		priv, ok := parsed.(ed25519.PrivateKey)
+		if !ok {
+			log.Printf("KeyRing: key %s is not Ed25519", s.KID)
+			continue
+		}
+		keys = append(keys, &signingKey{
+			kid:         s.KID,
+			private:     priv,
+			public:      ed25519.PublicKey(s.PublicKey),
+			activatesAt: s.ActivatesAt,
+		})
+	}
+
+	k.mu.Lock()
+	k.keys = keys
+	k.mu.Unlock()
+
+	log.Printf("KeyRing: loaded %d signing keys", len(keys))
+	return nil
+}
+
+// SigningKey возвращает самый новый уже активированный ключ.
+func (k *KeyRing) SigningKey() (string, ed25519.PrivateKey, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	now := time.Now()
+	for _, key := range k.keys {
+		if !key.activatesAt.After(now) {
+			return key.kid, key.private, nil
+		}
+	}
+	return "", nil, ErrUnknownSigningKey
+}
+
+// VerificationKey возвращает публичный ключ по kid.
+func (k *KeyRing) VerificationKey(kid string) (ed25519.PublicKey, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	for _, key := range k.keys {
+		if key.kid == kid {
+			return key.public, nil
+		}
+	}
+	return nil, ErrUnknownSigningKey
+}
+
+// JWKS возвращает публичные части всех ключей, включая ещё не активированный.
+func (k *KeyRing) JWKS() JWKS {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	doc := JWKS{Keys: make([]JWK, 0, len(k.keys))}
+	for _, key := range k.keys {
+		doc.Keys = append(doc.Keys, JWK{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(key.public),
+			Kid: key.kid,
+			Alg: algEdDSA,
+			Use: "sig",
+		})
+	}
+	return doc
+}
+
+// RotateIfDue перечитывает ключи и создаёт новый, если самому новому больше
+// rotationInterval. rotationInterval <= 0 отключает автоматическую ротацию.
+func (k *KeyRing) RotateIfDue(ctx context.Context) error {
+	if err := k.Reload(ctx); err != nil {
+		return err
+	}
+	if k.rotationInterval <= 0 {
+		return nil
+	}
+
+	k.mu.RLock()
+	var newest time.Time
+	if len(k.keys) > 0 {
+		newest = k.keys[0].activatesAt
+	}
+	k.mu.RUnlock()
+
+	if time.Since(newest) < k.rotationInterval {
+		return nil
+	}
+	return k.Rotate(ctx)
+}
+
+// Rotate выпускает новый ключ и планирует вывод из оборота всех предыдущих.
+func (k *KeyRing) Rotate(ctx context.Context) error {
+	activatesAt := time.Now().Add(k.propagationDelay)
+	kid, err := k.createKey(ctx, activatesAt)
+	if err != nil {
+		return err
+	}
+
+	if err := k.repo.ExpireSigningKeys(ctx, kid, activatesAt.Add(k.tokenDuration)); err != nil {
+		return err
+	}
+
+	log.Printf("KeyRing: rotated, new kid %s activates at %s", kid, activatesAt.Format(time.RFC3339))
+	return k.Reload(ctx)
+}
+
+// Run периодически перечитывает ключи и выполняет ротацию, когда подошёл срок
+// (блокирующий — вызывать в goroutine). interval должен быть меньше
+// propagationDelay: иначе соседний инстанс может не успеть увидеть новый ключ
+// до его активации.
+func (k *KeyRing) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		log.Printf("KeyRing: sync loop disabled (interval=%v)", interval)
+		return
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("KeyRing: stopping sync loop")
+			return
+		case <-t.C:
+			if err := k.RotateIfDue(ctx); err != nil {
+				log.Printf("KeyRing: rotation check failed: %v", err)
+			}
+		}
+	}
+}
+
+// Prune удаляет ключи, срок проверки которых вышел.
+func (k *KeyRing) Prune(ctx context.Context) error {
+	if err := k.repo.DeleteExpiredSigningKeys(ctx); err != nil {
+		return err
+	}
+	return k.Reload(ctx)
+}
+
+func (k *KeyRing) createKey(ctx context.Context, activatesAt time.Time) (string, error) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate signing key: %w", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal signing key: %w", err)
+	}
+
+	kidBytes := make([]byte, 8)
+	if _, err := rand.Read(kidBytes); err != nil {
+		return "", fmt.Errorf("failed to generate kid: %w", err)
+	}
+	kid := time.Now().UTC().Format("20060102") + "-" + hex.EncodeToString(kidBytes)
+
+	if err := k.repo.CreateSigningKey(ctx, &repository.SigningKey{
+		KID:         kid,
+		Algorithm:   algEdDSA,
+		PrivateKey:  der,
+		PublicKey:   public,
+		ActivatesAt: activatesAt,
+	}); err != nil {
+		return "", err
+	}
+	return kid, nil
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/studjobs/hh_for_students/auth/internal/metrics"
+)
+
+const (
+	LockScopeAccount = "account"
+	LockScopeIP      = "ip"
+)
+
+var ErrTooManyAttempts = errors.New("too many failed login attempts")
+
+// LockedError — вход временно заблокирован. Scope — что именно заблокировано
+// (аккаунт или адрес клиента), RetryAfter — через сколько можно повторить.
+type LockedError struct {
+	Scope      string
+	RetryAfter time.Duration
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("%s locked for %s: %v", e.Scope, e.RetryAfter.Round(time.Second), ErrTooManyAttempts)
+}
+
+func (e *LockedError) Unwrap() error {
+	return ErrTooManyAttempts
+}
+
+// LockoutConfig — защита от перебора паролей.
+//
+// Неудачи считаются отдельно по email и по IP клиента. Когда счётчик доходит
+// до порога, ключ блокируется на BaseDelay, и каждая следующая неудача
+// удваивает блокировку (не больше MaxDelay). Счётчик сбрасывается успешным
+// входом (только по email), сбросом пароля или ручной разблокировкой, а также
+// сам, если неудач не было дольше Window.
+//
+// Блокировка по email — это и способ заблокировать чужой аккаунт. Поэтому она
+// временная, а сброс пароля её снимает: владелец всегда может войти.
+type LockoutConfig struct {
+	AccountThreshold int
+	IPThreshold      int
+	BaseDelay        time.Duration
+	MaxDelay         time.Duration
+	Window           time.Duration
+}
+
+func (c LockoutConfig) enabled() bool {
+	return c.AccountThreshold > 0 || c.IPThreshold > 0
+}
+
+// lockDelay — длительность блокировки после failures неудач при пороге threshold.
+func (c LockoutConfig) lockDelay(failures, threshold int) time.Duration {
+	delay := c.BaseDelay
+	for i := threshold; i < failures && delay < c.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > c.MaxDelay {
+		delay = c.MaxDelay
+	}
+	return delay
+}
+
+func accountLockKey(email string) string {
+	return "email:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipLockKey(ip string) string {
+	if ip == "" {
+		return ""
+	}
+	return "ip:" + ip
+}
+
+// checkLockout возвращает *LockedError, если заблокирован аккаунт или IP.
+// Ошибки БД не блокируют вход: логируем и пропускаем.
+func (s *AuthService) checkLockout(ctx context.Context, accountKey, ipKey string) error {
+	if !s.lockout.enabled() {
+		return nil
+	}
+
+	keys := []string{accountKey}
+	if ipKey != "" {
+		keys = append(keys, ipKey)
+	}
+	locks, err := s.repo.Attempts.GetActiveLocks(ctx, keys)
+	if err != nil {
+		log.Printf("Lockout check failed, allowing attempt: %v", err)
+		return nil
+	}
+
+	now := time.Now()
+	if until, ok := locks[accountKey]; ok {
+		return &LockedError{Scope: LockScopeAccount, RetryAfter: until.Sub(now)}
+	}
+	if until, ok := locks[ipKey]; ok && ipKey != "" {
+		return &LockedError{Scope: LockScopeIP, RetryAfter: until.Sub(now)}
+	}
+	return nil
+}
+
+// recordLoginFailure учитывает неудачу и при превышении порога блокирует ключ.
+func (s *AuthService) recordLoginFailure(ctx context.Context, accountKey, ipKey string) {
+	metrics.LoginAttempts.WithLabelValues("failure").Inc()
+	if !s.lockout.enabled() {
+		return
+	}
+
+	s.registerFailure(ctx, accountKey, s.lockout.AccountThreshold, LockScopeAccount)
+	if ipKey != "" {
+		s.registerFailure(ctx, ipKey, s.lockout.IPThreshold, LockScopeIP)
+	}
+}
+
+func (s *AuthService) registerFailure(ctx context.Context, key string, threshold int, scope string) {
+	if threshold <= 0 {
+		return
+	}
+
+	failures, err := s.repo.Attempts.RegisterFailure(ctx, key, time.Now().Add(-s.lockout.Window))
+	if err != nil {
+		log.Printf("Failed to register login failure for %s: %v", key, err)
+		return
+	}
+	if failures < threshold {
+		return
+	}
+
+	delay := s.lockout.lockDelay(failures, threshold)
+	if err := s.repo.Attempts.LockUntil(ctx, key, time.Now().Add(delay)); err != nil {
+		log.Printf("Failed to lock %s: %v", key, err)
+		return
+	}
+	metrics.LoginLockouts.WithLabelValues(scope).Inc()
+	log.Printf("Login lockout - scope: %s, failures: %d, delay: %s", scope, failures, delay)
+}
+
+// UnlockAccount снимает блокировку входа для email (ручная разблокировка).
+func (s *AuthService) UnlockAccount(ctx context.Context, email string) error {
+	if err := s.repo.Attempts.ResetAttempts(ctx, accountLockKey(email)); err != nil {
+		return fmt.Errorf("failed to unlock account: %w", err)
+	}
+	log.Printf("Account unlocked: %s", email)
+	return nil
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/studjobs/hh_for_students/auth/internal/repository"
+)
+
+// Logout отзывает access-токен. allSessions=false — только этот токен (по jti)
+// и, если передан, refresh-токен той же сессии. allSessions=true — все access-
+// и refresh-токены пользователя, выпущенные до текущего момента.
+func (s *AuthService) Logout(ctx context.Context, accessToken, refreshToken string, allSessions bool) error {
+	claims, err := s.token.ValidateToken(accessToken)
+	if err != nil {
+		log.Printf("Logout failed - invalid access token: %v", err)
+		return ErrInvalidToken
+	}
+	userUUID := claims.UserUUID
+
+	if allSessions {
+		log.Printf("Logging out user from all sessions: %s", userUUID)
+		if err := s.repo.Auth.LogoutUser(ctx, userUUID, time.Now().Add(s.token.TokenDuration())); err != nil {
+			return fmt.Errorf("failed to logout user: %w", err)
+		}
+		if err := s.repo.Refresh.RevokeUserRefreshTokens(ctx, userUUID); err != nil {
+			return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+		}
+		if _, err := s.repo.Sessions.RevokeUserSessions(ctx, userUUID, ""); err != nil {
+			return fmt.Errorf("failed to revoke sessions: %w", err)
+		}
+		return nil
+	}
+
+	if claims.SessionID != "" {
+		if _, err := s.repo.Sessions.RevokeSession(ctx, userUUID, claims.SessionID); err != nil {
+			return fmt.Errorf("failed to revoke session: %w", err)
+		}
+		if err := s.repo.Refresh.RevokeFamily(ctx, claims.SessionID); err != nil {
+			return fmt.Errorf("failed to revoke refresh token family: %w", err)
+		}
+	}
+
+	log.Printf("Logging out single session - user: %s, jti: %s", userUUID, claims.ID)
+	if claims.ID != "" {
+		expiresAt := time.Now().Add(s.token.TokenDuration())
+		if claims.ExpiresAt != nil {
+			expiresAt = claims.ExpiresAt.Time
+		}
+		if err := s.repo.Auth.RevokeToken(ctx, claims.ID, userUUID, expiresAt); err != nil {
+			return fmt.Errorf("failed to revoke token: %w", err)
+		}
+	} else {
+		// Токены, выпущенные до появления jti, по одному отозвать нельзя.
+		log.Printf("Logout: token without jti, falling back to logout-all for user: %s", userUUID)
+		if err := s.repo.Auth.LogoutUser(ctx, userUUID, time.Now().Add(s.token.TokenDuration())); err != nil {
+			return fmt.Errorf("failed to logout user: %w", err)
+		}
+	}
+
+	if refreshToken == "" {
+		return nil
+	}
+	stored, err := s.repo.Refresh.FindRefreshTokenByHash(ctx, hashOpaqueToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, repository.ErrRefreshTokenNotFound) {
+			log.Printf("Logout: refresh token not found, skipping")
+			return nil
+		}
+		return fmt.Errorf("failed to find refresh token: %w", err)
+	}
+	if stored.UserID != userUUID {
+		log.Printf("Logout: refresh token belongs to another user, skipping")
+		return nil
+	}
+	if err := s.repo.Refresh.RevokeFamily(ctx, stored.FamilyID); err != nil {
+		return fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+	return nil
+}
+
+// CleanupExpired удаляет записи об отзыве, refresh-токены и одноразовые
+// токены из писем, которые уже истекли сами по себе, а также истёкшие и
+// отозванные API-ключи. Вызывается по расписанию
+// (см. internal/cleaner).
+func (s *AuthService) CleanupExpired(ctx context.Context) error {
+	var errs []error
+	if err := s.repo.Auth.CleanupExpiredLogouts(ctx); err != nil {
+		errs = append(errs, err)
+	}
+	if err := s.repo.Auth.CleanupRevokedTokens(ctx); err != nil {
+		errs = append(errs, err)
+	}
+	if err := s.repo.Refresh.CleanupExpiredRefreshTokens(ctx); err != nil {
+		errs = append(errs, err)
+	}
+	if err := s.repo.Sessions.CleanupExpiredSessions(ctx); err != nil {
+		errs = append(errs, err)
+	}
+	if err := s.repo.PasswordReset.CleanupExpiredResetTokens(ctx); err != nil {
+		errs = append(errs, err)
+	}
+	if err := s.repo.EmailVerification.CleanupExpiredVerificationTokens(ctx); err != nil {
+		errs = append(errs, err)
+	}
+	if err := s.repo.MFA.CleanupExpiredChallenges(ctx); err != nil {
+		errs = append(errs, err)
+	}
+	if err := s.repo.Identities.CleanupExpiredLoginStates(ctx); err != nil {
+		errs = append(errs, err)
+	}
+	if err := s.repo.APIKeys.CleanupAPIKeys(ctx); err != nil {
+		errs = append(errs, err)
+	}
+	if err := s.repo.Attempts.CleanupLoginAttempts(ctx, time.Now().Add(-s.lockout.Window)); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
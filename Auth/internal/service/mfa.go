@@ -0,0 +1,440 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	authv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/auth/v1"
+	"github.com/studjobs/hh_for_students/auth/internal/repository"
+	"github.com/studjobs/hh_for_students/auth/internal/totp"
+)
+
+const (
+	// mfaMaxAttempts — сколько неверных кодов выдерживает один челлендж;
+	// дальше нужно заново вводить пароль.
+	mfaMaxAttempts = 5
+	// totpSkew — допустимый рассинхрон часов телефона, в шагах (±30 с).
+	totpSkew = 1
+	// recoveryCodeCount и recoveryCodeBytes — 10 кодов по 40 бит: перебор
+	// упирается в mfaMaxAttempts и блокировку аккаунта.
+	recoveryCodeCount = 10
+	recoveryCodeBytes = 5
+)
+
+var recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// MFAConfig — параметры второго фактора.
+type MFAConfig struct {
+	// Issuer — название сервиса в приложении-аутентификаторе.
+	Issuer string
+	// RequiredRoles — роли, для которых TOTP обязателен: без него вход под
+	// такой ролью заканчивается подключением TOTP, а не выдачей токенов.
+	RequiredRoles []authv1.Role
+	// ChallengeTTL — сколько живёт mfa-токен между паролем и кодом.
+	ChallengeTTL time.Duration
+}
+
+func (c MFAConfig) required(role authv1.Role) bool {
+	for _, r := range c.RequiredRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// requiredForUser — обязателен ли TOTP хотя бы для одной роли аккаунта.
+func (c MFAConfig) requiredForUser(user *repository.User) bool {
+	for _, r := range user.Roles {
+		if c.required(authv1.Role(r)) {
+			return true
+		}
+	}
+	return false
+}
+
+// mfaSettings возвращает TOTP пользователя или nil, если он не подключался.
+func (s *AuthService) mfaSettings(ctx context.Context, userUUID string) (*repository.MFASettings, error) {
+	settings, err := s.repo.MFA.GetMFA(ctx, userUUID)
+	if errors.Is(err, repository.ErrMFANotFound) {
+		return nil, nil
+	}
+	return settings, err
+}
+
+// completeLogin — последний шаг входа после проверки пароля. Если у
+// пользователя включён TOTP, вместо токенов выдаётся mfa-токен (VerifyMFA).
+// Если TOTP для роли обязателен, но не подключён, — mfa-токен для
+// подключения (EnrollTOTP / ConfirmTOTP). Иначе — обычная пара токенов.
+func (s *AuthService) completeLogin(ctx context.Context, user *repository.User, role authv1.Role) (*authv1.AuthResponse, error) {
+	// Заблокированному аккаунту не выдаём и mfa-токен: иначе второй шаг
+	// входа упал бы уже после ввода кода.
+	if user.BannedAt != nil {
+		log.Printf("Login rejected - user is banned: %s", user.UUID)
+		return nil, ErrAccountBanned
+	}
+
+	settings, err := s.mfaSettings(ctx, user.UUID)
+	if err != nil {
+		// Не знаем, включён ли второй фактор, — не пускаем по одному паролю.
+		return nil, fmt.Errorf("failed to load mfa settings: %w", err)
+	}
+
+	switch {
+	case settings.Enabled():
+		return s.startMFAChallenge(ctx, user, role, false)
+	case s.mfa.required(role):
+		log.Printf("MFA enrollment required for user %s, role %v", user.UUID, role)
+		return s.startMFAChallenge(ctx, user, role, true)
+	}
+
+	resp, _, err := s.issueTokens(ctx, user, role, "")
+	return resp, err
+}
+
+func (s *AuthService) startMFAChallenge(ctx context.Context, user *repository.User, role authv1.Role, enrollment bool) (*authv1.AuthResponse, error) {
+	token, tokenHash, err := newOpaqueToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate mfa token: %w", err)
+	}
+	if err := s.repo.MFA.CreateChallenge(ctx, user.UUID, tokenHash, int(role), enrollment, time.Now().Add(s.mfa.ChallengeTTL)); err != nil {
+		return nil, err
+	}
+
+	log.Printf("MFA challenge issued for user: %s (enrollment=%t)", user.UUID, enrollment)
+	return &authv1.AuthResponse{
+		UserUuid:              user.UUID,
+		Role:                  role,
+		Roles:                 rolesOf(user),
+		MfaRequired:           true,
+		MfaToken:              token,
+		MfaEnrollmentRequired: enrollment,
+	}, nil
+}
+
+// findChallenge возвращает действующий челлендж и его пользователя.
+func (s *AuthService) findChallenge(ctx context.Context, mfaToken string) (*repository.MFAChallenge, *repository.User, error) {
+	challenge, err := s.repo.MFA.FindChallenge(ctx, hashOpaqueToken(mfaToken))
+	if err != nil {
+		if errors.Is(err, repository.ErrMFAChallengeNotFound) {
+			return nil, nil, ErrInvalidMFAToken
+		}
+		return nil, nil, err
+	}
+	if challenge.Attempts >= mfaMaxAttempts {
+		log.Printf("MFA challenge exhausted for user: %s", challenge.UserID)
+		return nil, nil, ErrInvalidMFAToken
+	}
+
+	user, err := s.repo.Auth.FindUserByUUID(ctx, challenge.UserID)
+	if err != nil || user == nil {
+		return nil, nil, ErrInvalidMFAToken
+	}
+	if !user.HasRole(challenge.Role) {
+		log.Printf("MFA challenge role %d no longer assigned to user: %s", challenge.Role, user.UUID)
+		return nil, nil, ErrInvalidMFAToken
+	}
+	return challenge, user, nil
+}
+
+// checkSecondFactor проверяет TOTP-код или код восстановления.
+func (s *AuthService) checkSecondFactor(ctx context.Context, settings *repository.MFASettings, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		step, ok := totp.Validate(settings.TOTPSecret, code, time.Now(), totpSkew)
+		if !ok {
+			return false, nil
+		}
+		return s.repo.MFA.UseTOTPStep(ctx, settings.UserID, step)
+	}
+
+	used, err := s.repo.MFA.UseRecoveryCode(ctx, settings.UserID, hashOpaqueToken(normalizeRecoveryCode(code)))
+	if err != nil || !used {
+		return false, err
+	}
+	left, err := s.repo.MFA.CountRecoveryCodes(ctx, settings.UserID)
+	if err == nil {
+		log.Printf("Recovery code used by user: %s, codes left: %d", settings.UserID, left)
+	}
+	return true, nil
+}
+
+// VerifyMFA — второй шаг входа: mfa-токен и код из приложения (или код
+// восстановления) обмениваются на пару токенов.
+func (s *AuthService) VerifyMFA(ctx context.Context, mfaToken, code, clientIP string) (*authv1.AuthResponse, error) {
+	challenge, user, err := s.findChallenge(ctx, mfaToken)
+	if err != nil {
+		return nil, err
+	}
+	if challenge.Enrollment {
+		// Такой токен подтверждается через ConfirmTOTP.
+		return nil, ErrInvalidMFAToken
+	}
+
+	accountKey, ipKey := accountLockKey(user.Email), ipLockKey(clientIP)
+	if err := s.checkLockout(ctx, accountKey, ipKey); err != nil {
+		return nil, err
+	}
+
+	settings, err := s.mfaSettings(ctx, user.UUID)
+	if err != nil {
+		return nil, err
+	}
+	if !settings.Enabled() {
+		// TOTP отключили, пока челлендж был жив.
+		return nil, ErrInvalidMFAToken
+	}
+
+	ok, err := s.checkSecondFactor(ctx, settings, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		log.Printf("MFA verification failed for user: %s", user.UUID)
+		if _, err := s.repo.MFA.RegisterChallengeFailure(ctx, challenge.ID); err != nil {
+			log.Printf("Failed to register mfa failure: %v", err)
+		}
+		s.recordLoginFailure(ctx, accountKey, ipKey)
+		return nil, ErrInvalidMFACode
+	}
+
+	consumed, err := s.repo.MFA.ConsumeChallenge(ctx, challenge.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !consumed {
+		return nil, ErrInvalidMFAToken
+	}
+
+	if s.lockout.enabled() {
+		if err := s.repo.Attempts.ResetAttempts(ctx, accountKey); err != nil {
+			log.Printf("Failed to reset login attempts for %s: %v", user.Email, err)
+		}
+	}
+
+	resp, _, err := s.issueTokens(ctx, user, authv1.Role(challenge.Role), "")
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("MFA verification successful for user: %s", user.UUID)
+	return resp, nil
+}
+
+// enrollmentUser определяет, кто подключает TOTP: владелец сессии (userUUID
+// из проверенного Gateway токена) или пользователь, которому подключение
+// обязательно при входе (mfaToken).
+func (s *AuthService) enrollmentUser(ctx context.Context, userUUID, mfaToken string) (*repository.User, *repository.MFAChallenge, error) {
+	if mfaToken != "" {
+		challenge, user, err := s.findChallenge(ctx, mfaToken)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !challenge.Enrollment {
+			return nil, nil, ErrInvalidMFAToken
+		}
+		return user, challenge, nil
+	}
+
+	user, err := s.repo.Auth.FindUserByUUID(ctx, userUUID)
+	if err != nil || user == nil {
+		return nil, nil, ErrUserNotFound
+	}
+	return user, nil, nil
+}
+
+// EnrollTOTP создаёт новый секрет и возвращает его вместе с otpauth-ссылкой
+// для QR-кода. До ConfirmTOTP секрет ни на что не влияет.
+func (s *AuthService) EnrollTOTP(ctx context.Context, userUUID, mfaToken string) (*authv1.TOTPEnrollment, error) {
+	user, _, err := s.enrollmentUser(ctx, userUUID, mfaToken)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := s.mfaSettings(ctx, user.UUID)
+	if err != nil {
+		return nil, err
+	}
+	if settings.Enabled() {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.MFA.SavePendingTOTP(ctx, user.UUID, secret); err != nil {
+		return nil, err
+	}
+
+	log.Printf("TOTP enrollment started for user: %s", user.UUID)
+	return &authv1.TOTPEnrollment{
+		Secret:          secret,
+		ProvisioningUri: totp.ProvisioningURI(s.mfa.Issuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmTOTP включает TOTP по первому коду из приложения и выдаёт коды
+// восстановления (показываются один раз). Если подключение шло в рамках
+// входа (mfaToken), заодно выдаётся пара токенов.
+func (s *AuthService) ConfirmTOTP(ctx context.Context, userUUID, mfaToken, code string) (*authv1.TOTPConfirmation, error) {
+	user, challenge, err := s.enrollmentUser(ctx, userUUID, mfaToken)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := s.mfaSettings(ctx, user.UUID)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		return nil, ErrMFANotEnabled
+	}
+	if settings.Enabled() {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	step, ok := totp.Validate(settings.TOTPSecret, code, time.Now(), totpSkew)
+	if !ok {
+		log.Printf("TOTP confirmation failed for user: %s", user.UUID)
+		if challenge != nil {
+			if _, err := s.repo.MFA.RegisterChallengeFailure(ctx, challenge.ID); err != nil {
+				log.Printf("Failed to register mfa failure: %v", err)
+			}
+		}
+		return nil, ErrInvalidMFACode
+	}
+
+	if challenge != nil {
+		consumed, err := s.repo.MFA.ConsumeChallenge(ctx, challenge.ID)
+		if err != nil {
+			return nil, err
+		}
+		if !consumed {
+			return nil, ErrInvalidMFAToken
+		}
+	}
+
+	if err := s.repo.MFA.ConfirmTOTP(ctx, user.UUID, step); err != nil {
+		if errors.Is(err, repository.ErrMFANotFound) {
+			return nil, ErrMFAAlreadyEnabled
+		}
+		return nil, err
+	}
+
+	codes, err := s.replaceRecoveryCodes(ctx, user.UUID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &authv1.TOTPConfirmation{RecoveryCodes: codes}
+	if challenge != nil {
+		resp, _, err := s.issueTokens(ctx, user, authv1.Role(challenge.Role), "")
+		if err != nil {
+			return nil, err
+		}
+		result.Auth = resp
+	}
+	return result, nil
+}
+
+// checkSessionSecondFactor проверяет код для действия в открытой сессии
+// (отключение TOTP, новые коды восстановления). Неверные коды идут в ту же
+// блокировку аккаунта и IP, что и на входе (VerifyMFA): иначе украденная
+// сессия перебирала бы шестизначные коды без ограничений.
+func (s *AuthService) checkSessionSecondFactor(ctx context.Context, user *repository.User, settings *repository.MFASettings, code, clientIP string) error {
+	accountKey, ipKey := accountLockKey(user.Email), ipLockKey(clientIP)
+	if err := s.checkLockout(ctx, accountKey, ipKey); err != nil {
+		return err
+	}
+
+	ok, err := s.checkSecondFactor(ctx, settings, code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		log.Printf("MFA code check failed for user: %s", user.UUID)
+		s.recordLoginFailure(ctx, accountKey, ipKey)
+		return ErrInvalidMFACode
+	}
+	return nil
+}
+
+// DisableTOTP отключает TOTP по действующему коду. Если второй фактор
+// обязателен для одной из ролей аккаунта, отключить его нельзя.
+func (s *AuthService) DisableTOTP(ctx context.Context, userUUID, code, clientIP string) error {
+	user, err := s.repo.Auth.FindUserByUUID(ctx, userUUID)
+	if err != nil || user == nil {
+		return ErrUserNotFound
+	}
+	if s.mfa.requiredForUser(user) {
+		return ErrMFARequired
+	}
+
+	settings, err := s.mfaSettings(ctx, user.UUID)
+	if err != nil {
+		return err
+	}
+	if !settings.Enabled() {
+		return ErrMFANotEnabled
+	}
+
+	if err := s.checkSessionSecondFactor(ctx, user, settings, code, clientIP); err != nil {
+		return err
+	}
+	return s.repo.MFA.DeleteMFA(ctx, user.UUID)
+}
+
+// RegenerateRecoveryCodes выпускает новый набор кодов восстановления взамен
+// старого (например, когда старые закончились или могли утечь).
+func (s *AuthService) RegenerateRecoveryCodes(ctx context.Context, userUUID, code, clientIP string) ([]string, error) {
+	user, err := s.repo.Auth.FindUserByUUID(ctx, userUUID)
+	if err != nil || user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	settings, err := s.mfaSettings(ctx, user.UUID)
+	if err != nil {
+		return nil, err
+	}
+	if !settings.Enabled() {
+		return nil, ErrMFANotEnabled
+	}
+
+	if err := s.checkSessionSecondFactor(ctx, user, settings, code, clientIP); err != nil {
+		return nil, err
+	}
+	return s.replaceRecoveryCodes(ctx, user.UUID)
+}
+
+func (s *AuthService) replaceRecoveryCodes(ctx context.Context, userUUID string) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	buf := make([]byte, recoveryCodeBytes)
+	for i := 0; i < recoveryCodeCount; i++ {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		raw := strings.ToLower(recoveryEncoding.EncodeToString(buf))
+		codes = append(codes, raw[:4]+"-"+raw[4:])
+		hashes = append(hashes, hashOpaqueToken(raw))
+	}
+
+	if err := s.repo.MFA.ReplaceRecoveryCodes(ctx, userUUID, hashes); err != nil {
+		return nil, err
+	}
+	log.Printf("Recovery codes issued for user: %s", userUUID)
+	return codes, nil
+}
+
+// normalizeRecoveryCode приводит введённый код к виду, от которого считался
+// хэш: без дефисов и пробелов, в нижнем регистре.
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
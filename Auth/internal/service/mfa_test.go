@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/studjobs/hh_for_students/auth/internal/repository"
+	"github.com/studjobs/hh_for_students/auth/internal/totp"
+)
+
+const testTOTPSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+type fakeUsers struct {
+	repository.Auth
+	user    *repository.User
+	created int
+}
+
+func (f *fakeUsers) FindUserByUUID(_ context.Context, uuid string) (*repository.User, error) {
+	if f.user == nil || f.user.UUID != uuid {
+		return nil, errors.New("not found")
+	}
+	return f.user, nil
+}
+
+// fakeMFA повторяет семантику запросов MFARepository: шаг TOTP принимается,
+// только если он новее последнего, код восстановления удаляется при использовании.
+type fakeMFA struct {
+	repository.MFA
+	settings *repository.MFASettings
+	recovery map[string]bool
+	deleted  bool
+	replaced int
+}
+
+func (f *fakeMFA) GetMFA(context.Context, string) (*repository.MFASettings, error) {
+	if f.settings == nil || f.deleted {
+		return nil, repository.ErrMFANotFound
+	}
+	return f.settings, nil
+}
+
+func (f *fakeMFA) UseTOTPStep(_ context.Context, _ string, step int64) (bool, error) {
+	if step <= f.settings.LastUsedStep {
+		return false, nil
+	}
+	f.settings.LastUsedStep = step
+	return true, nil
+}
+
+func (f *fakeMFA) UseRecoveryCode(_ context.Context, _ string, codeHash string) (bool, error) {
+	if !f.recovery[codeHash] {
+		return false, nil
+	}
+	delete(f.recovery, codeHash)
+	return true, nil
+}
+
+func (f *fakeMFA) CountRecoveryCodes(context.Context, string) (int, error) {
+	return len(f.recovery), nil
+}
+
+func (f *fakeMFA) ReplaceRecoveryCodes(_ context.Context, _ string, hashes []string) error {
+	f.recovery = make(map[string]bool, len(hashes))
+	for _, h := range hashes {
+		f.recovery[h] = true
+	}
+	f.replaced++
+	return nil
+}
+
+func (f *fakeMFA) DeleteMFA(context.Context, string) error {
+	f.deleted = true
+	return nil
+}
+
+type fakeAttempts struct {
+	repository.Attempts
+	failures map[string]int
+	locks    map[string]time.Time
+}
+
+func (f *fakeAttempts) GetActiveLocks(_ context.Context, keys []string) (map[string]time.Time, error) {
+	active := make(map[string]time.Time)
+	for _, k := range keys {
+		if until, ok := f.locks[k]; ok && until.After(time.Now()) {
+			active[k] = until
+		}
+	}
+	return active, nil
+}
+
+func (f *fakeAttempts) RegisterFailure(_ context.Context, key string, _ time.Time) (int, error) {
+	f.failures[key]++
+	return f.failures[key], nil
+}
+
+func (f *fakeAttempts) LockUntil(_ context.Context, key string, until time.Time) error {
+	f.locks[key] = until
+	return nil
+}
+
+func newMFATestService(t *testing.T) (*AuthService, *fakeMFA, *fakeAttempts) {
+	t.Helper()
+	confirmed := time.Now().Add(-time.Hour)
+	mfa := &fakeMFA{
+		settings: &repository.MFASettings{UserID: "u1", TOTPSecret: testTOTPSecret, ConfirmedAt: &confirmed},
+		recovery: map[string]bool{hashOpaqueToken("abcdefgh"): true},
+	}
+	attempts := &fakeAttempts{failures: map[string]int{}, locks: map[string]time.Time{}}
+	repo := &repository.Repository{
+		Auth:     &fakeUsers{user: &repository.User{UUID: "u1", Email: "user@example.com"}},
+		MFA:      mfa,
+		Attempts: attempts,
+	}
+	s := &AuthService{
+		repo: repo,
+		lockout: LockoutConfig{
+			AccountThreshold: 3,
+			IPThreshold:      10,
+			BaseDelay:        time.Minute,
+			MaxDelay:         time.Hour,
+			Window:           time.Hour,
+		},
+	}
+	return s, mfa, attempts
+}
+
+func currentTOTPCode(t *testing.T) string {
+	t.Helper()
+	code, err := totp.Code(testTOTPSecret, totp.Step(time.Now()))
+	if err != nil {
+		t.Fatalf("totp.Code: %v", err)
+	}
+	return code
+}
+
+func TestCheckSecondFactorRejectsReuse(t *testing.T) {
+	s, _, _ := newMFATestService(t)
+	ctx := context.Background()
+	settings, _ := s.mfaSettings(ctx, "u1")
+
+	// Код восстановления вводят как показали — с дефисом и в любом регистре.
+	if ok, err := s.checkSecondFactor(ctx, settings, "ABCD-EFGH"); err != nil || !ok {
+		t.Fatalf("recovery code: %t, %v; want accepted", ok, err)
+	}
+	if ok, _ := s.checkSecondFactor(ctx, settings, "abcd-efgh"); ok {
+		t.Fatal("recovery code accepted twice")
+	}
+
+	code := currentTOTPCode(t)
+	if ok, err := s.checkSecondFactor(ctx, settings, code); err != nil || !ok {
+		t.Fatalf("totp code: %t, %v; want accepted", ok, err)
+	}
+	if ok, _ := s.checkSecondFactor(ctx, settings, code); ok {
+		t.Fatal("totp code accepted twice")
+	}
+}
+
+func TestSessionMFAActionsLockAccount(t *testing.T) {
+	actions := map[string]func(s *AuthService, code string) error{
+		"disable totp": func(s *AuthService, code string) error {
+			return s.DisableTOTP(context.Background(), "u1", code, "203.0.113.7")
+		},
+		"regenerate recovery codes": func(s *AuthService, code string) error {
+			_, err := s.RegenerateRecoveryCodes(context.Background(), "u1", code, "203.0.113.7")
+			return err
+		},
+	}
+	for name, action := range actions {
+		t.Run(name, func(t *testing.T) {
+			s, mfa, attempts := newMFATestService(t)
+
+			for i := 0; i < s.lockout.AccountThreshold; i++ {
+				if err := action(s, "wrong-code"); !errors.Is(err, ErrInvalidMFACode) {
+					t.Fatalf("attempt %d: %v, want ErrInvalidMFACode", i+1, err)
+				}
+			}
+			if got := attempts.failures[accountLockKey("user@example.com")]; got != s.lockout.AccountThreshold {
+				t.Fatalf("account failures = %d, want %d", got, s.lockout.AccountThreshold)
+			}
+
+			// После порога не проходит и верный код: перебор упирается в блокировку.
+			var locked *LockedError
+			if err := action(s, currentTOTPCode(t)); !errors.As(err, &locked) || locked.Scope != LockScopeAccount {
+				t.Fatalf("after threshold: %v, want account lock", err)
+			}
+			if mfa.deleted || mfa.replaced != 0 {
+				t.Fatalf("locked action went through: deleted %t, replaced %d", mfa.deleted, mfa.replaced)
+			}
+		})
+	}
+}
+
+func TestSessionMFAActionsAcceptValidCode(t *testing.T) {
+	s, mfa, _ := newMFATestService(t)
+	ctx := context.Background()
+
+	codes, err := s.RegenerateRecoveryCodes(ctx, "u1", currentTOTPCode(t), "203.0.113.7")
+	if err != nil {
+		t.Fatalf("RegenerateRecoveryCodes: %v", err)
+	}
+	if len(codes) != recoveryCodeCount || mfa.recovery[hashOpaqueToken("abcdefgh")] {
+		t.Fatalf("codes %v: old code kept or wrong count", codes)
+	}
+
+	// Код из нового набора подходит для отключения TOTP.
+	if err := s.DisableTOTP(ctx, "u1", codes[0], "203.0.113.7"); err != nil {
+		t.Fatalf("DisableTOTP: %v", err)
+	}
+	if !mfa.deleted {
+		t.Fatal("TOTP not disabled")
+	}
+}
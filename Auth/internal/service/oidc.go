@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	authv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/auth/v1"
+	"github.com/studjobs/hh_for_students/auth/internal/oidc"
+	"github.com/studjobs/hh_for_students/auth/internal/repository"
+)
+
+// oidcPasswordHash — пароль аккаунта, созданного через внешнего провайдера.
+// Это не хеш ни одного из алгоритмов (см. internal/password), поэтому вход
+// по паролю невозможен, пока пользователь не задаст пароль через сброс.
+const oidcPasswordHash = "!oidc"
+
+// OIDCConfig — вход через внешних OIDC/OAuth2-провайдеров.
+type OIDCConfig struct {
+	Registry *oidc.Registry
+	// StateTTL — сколько живёт state между /start и /callback.
+	StateTTL time.Duration
+}
+
+// ListOIDCProviders возвращает имена настроенных провайдеров.
+func (s *AuthService) ListOIDCProviders(ctx context.Context) []string {
+	return s.oidc.Registry.Names()
+}
+
+// StartOIDC начинает вход через провайдера: сохраняет state, nonce и PKCE
+// code_verifier и возвращает ссылку на страницу провайдера. linkUserUUID
+// задаётся, когда уже вошедший пользователь привязывает провайдера к аккаунту.
+func (s *AuthService) StartOIDC(ctx context.Context, providerName string, role authv1.Role, linkUserUUID string) (string, string, error) {
+	if !selfServiceRole(role) {
+		return "", "", ErrRoleNotSelfService
+	}
+
+	provider, err := s.oidc.Registry.Get(providerName)
+	if err != nil {
+		return "", "", err
+	}
+
+	state, err := oidc.RandomString()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := oidc.RandomString()
+	if err != nil {
+		return "", "", err
+	}
+	verifier, err := oidc.RandomString()
+	if err != nil {
+		return "", "", err
+	}
+
+	loginState := &repository.OIDCLoginState{
+		StateHash:    hashOpaqueToken(state),
+		Provider:     providerName,
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		Role:         int(role),
+		ExpiresAt:    time.Now().Add(s.oidc.StateTTL),
+	}
+	if linkUserUUID != "" {
+		loginState.LinkUserID = &linkUserUUID
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, oidc.CodeChallenge(verifier))
+	if err != nil {
+		log.Printf("OIDC start failed - provider %s: %v", providerName, err)
+		return "", "", err
+	}
+	if err := s.repo.Identities.CreateLoginState(ctx, loginState); err != nil {
+		return "", "", err
+	}
+
+	log.Printf("OIDC login started - provider: %s, role: %v, link: %t", providerName, role, linkUserUUID != "")
+	return authURL, state, nil
+}
+
+// CompleteOIDC обменивает code на токены провайдера, проверяет их и входит
+// под привязанным пользователем. Дальше — обычный completeLogin, так что
+// включённый TOTP требуется и при входе через провайдера. Возвращает ответ
+// и email аккаунта (Gateway заводит профиль для нового аккаунта).
+func (s *AuthService) CompleteOIDC(ctx context.Context, providerName, state, code string) (*authv1.AuthResponse, string, error) {
+	provider, err := s.oidc.Registry.Get(providerName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	loginState, err := s.repo.Identities.ConsumeLoginState(ctx, hashOpaqueToken(state), providerName)
+	if err != nil {
+		if errors.Is(err, repository.ErrLoginStateNotFound) {
+			log.Printf("OIDC callback rejected - unknown or expired state, provider: %s", providerName)
+			return nil, "", ErrInvalidOIDCState
+		}
+		return nil, "", err
+	}
+
+	tok, err := provider.Exchange(ctx, code, loginState.CodeVerifier)
+	if err != nil {
+		log.Printf("OIDC callback failed - provider %s: %v", providerName, err)
+		return nil, "", err
+	}
+	identity, err := provider.Identity(ctx, tok, loginState.Nonce)
+	if err != nil {
+		log.Printf("OIDC callback failed - provider %s: %v", providerName, err)
+		return nil, "", err
+	}
+
+	user, created, err := s.resolveOIDCUser(ctx, providerName, identity, loginState)
+	if err != nil {
+		return nil, "", err
+	}
+
+	role := authv1.Role(loginState.Role)
+	if role == authv1.Role_ROLE_UNSPECIFIED {
+		role = authv1.Role(user.Role)
+	}
+	if !user.HasRole(int(role)) {
+		log.Printf("OIDC login failed - role %v not assigned to user %s", role, user.UUID)
+		return nil, "", ErrRoleNotAssigned
+	}
+
+	var resp *authv1.AuthResponse
+	if loginState.LinkUserID != nil {
+		// Привязка из профиля: пользователь уже вошёл (и прошёл второй
+		// фактор) в этой сессии.
+		resp, _, err = s.issueTokens(ctx, user, role, "")
+	} else {
+		resp, err = s.completeLogin(ctx, user, role)
+	}
+	if err != nil {
+		log.Printf("Token generation failed for user %s: %v", user.UUID, err)
+		return nil, "", err
+	}
+	resp.NewAccount = created
+
+	log.Printf("OIDC login successful - provider: %s, user: %s, new account: %t", providerName, user.UUID, created)
+	return resp, user.Email, nil
+}
+
+// resolveOIDCUser находит пользователя для внешней учётной записи:
+//   - уже привязанная запись — её владелец;
+//   - привязка из профиля — текущий пользователь;
+//   - подтверждённый провайдером email — аккаунт с этим email (запись
+//     привязывается), а если его нет — новый аккаунт.
+//
+// Неподтверждённому email не верим: иначе любой, кто заведёт у провайдера
+// чужой адрес, войдёт в чужой аккаунт.
+func (s *AuthService) resolveOIDCUser(ctx context.Context, providerName string, identity *oidc.Identity, st *repository.OIDCLoginState) (*repository.User, bool, error) {
+	linkedID, err := s.repo.Identities.FindIdentityUser(ctx, providerName, identity.Subject)
+	switch {
+	case err == nil:
+		if st.LinkUserID != nil && *st.LinkUserID != linkedID {
+			log.Printf("OIDC link failed - %s/%s already linked to another user", providerName, identity.Subject)
+			return nil, false, ErrIdentityLinked
+		}
+		user, err := s.repo.Auth.FindUserByUUID(ctx, linkedID)
+		if err != nil || user == nil {
+			return nil, false, ErrUserNotFound
+		}
+		return user, false, nil
+	case !errors.Is(err, repository.ErrIdentityNotFound):
+		return nil, false, err
+	}
+
+	if st.LinkUserID != nil {
+		user, err := s.repo.Auth.FindUserByUUID(ctx, *st.LinkUserID)
+		if err != nil || user == nil {
+			return nil, false, ErrUserNotFound
+		}
+		if err := s.linkIdentity(ctx, user, providerName, identity); err != nil {
+			return nil, false, err
+		}
+		return user, false, nil
+	}
+
+	if !identity.EmailVerified {
+		log.Printf("OIDC login failed - provider %s did not return a verified email for %s", providerName, identity.Subject)
+		return nil, false, ErrOIDCEmailNotVerified
+	}
+
+	if user, err := s.repo.Auth.FindUserByEmail(ctx, identity.Email); err == nil && user != nil {
+		if err := s.linkIdentity(ctx, user, providerName, identity); err != nil {
+			return nil, false, err
+		}
+		return user, false, nil
+	}
+
+	role := authv1.Role(st.Role)
+	if role == authv1.Role_ROLE_UNSPECIFIED {
+		role = authv1.Role_ROLE_STUDENT
+	}
+	userUUID, err := s.repo.Auth.CreateUser(ctx, identity.Email, oidcPasswordHash, int(role))
+	if err != nil {
+		log.Printf("User creation failed for email %s: %v", identity.Email, err)
+		return nil, false, fmt.Errorf("failed to create user: %w", err)
+	}
+	// Провайдер уже подтвердил адрес — письмо не нужно.
+	if err := s.repo.Auth.MarkEmailVerified(ctx, userUUID); err != nil {
+		log.Printf("Failed to mark email verified for user %s: %v", userUUID, err)
+	}
+
+	user := &repository.User{UUID: userUUID, Email: identity.Email, Role: int(role), Roles: []int32{int32(role)}}
+	if err := s.linkIdentity(ctx, user, providerName, identity); err != nil {
+		return nil, false, err
+	}
+
+	log.Printf("User created via OIDC - provider: %s, uuid: %s", providerName, userUUID)
+	return user, true, nil
+}
+
+func (s *AuthService) linkIdentity(ctx context.Context, user *repository.User, providerName string, identity *oidc.Identity) error {
+	err := s.repo.Identities.LinkIdentity(ctx, user.UUID, providerName, identity.Subject, identity.Email)
+	if errors.Is(err, repository.ErrIdentityLinked) {
+		return ErrIdentityLinked
+	}
+	return err
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	authv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/auth/v1"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/studjobs/hh_for_students/auth/internal/oidc"
+	"github.com/studjobs/hh_for_students/auth/internal/oidc/oidctest"
+	"github.com/studjobs/hh_for_students/auth/internal/repository"
+)
+
+func (f *fakeUsers) FindUserByEmail(_ context.Context, email string) (*repository.User, error) {
+	if f.user == nil || f.user.Email != email {
+		return nil, errors.New("not found")
+	}
+	return f.user, nil
+}
+
+func (f *fakeUsers) CreateUser(context.Context, string, string, int) (string, error) {
+	f.created++
+	return "new-user", nil
+}
+
+func (f *fakeUsers) MarkEmailVerified(context.Context, string) error {
+	return nil
+}
+
+func (f *fakeMFA) CreateChallenge(context.Context, string, string, int, bool, time.Time) error {
+	return nil
+}
+
+type fakeIdentities struct {
+	repository.Identities
+	state  *repository.OIDCLoginState
+	linked map[string]string
+}
+
+func (f *fakeIdentities) ConsumeLoginState(_ context.Context, stateHash, provider string) (*repository.OIDCLoginState, error) {
+	if f.state == nil || f.state.StateHash != stateHash || f.state.Provider != provider {
+		return nil, repository.ErrLoginStateNotFound
+	}
+	st := f.state
+	f.state = nil
+	return st, nil
+}
+
+func (f *fakeIdentities) FindIdentityUser(_ context.Context, _, subject string) (string, error) {
+	if id, ok := f.linked[subject]; ok {
+		return id, nil
+	}
+	return "", repository.ErrIdentityNotFound
+}
+
+func (f *fakeIdentities) LinkIdentity(_ context.Context, userID, _, subject, _ string) error {
+	f.linked[subject] = userID
+	return nil
+}
+
+func TestCompleteOIDC(t *testing.T) {
+	student := int(authv1.Role_ROLE_STUDENT)
+
+	tests := []struct {
+		name string
+		// state — state из callback; в хранилище лежит "state-1".
+		state  string
+		claims func(c jwt.MapClaims)
+		// linked — учётная запись провайдера уже привязана к u1.
+		linked       bool
+		wantErr      error
+		wantLinked   bool
+		wantExchange bool
+	}{
+		{name: "verified email of existing account", state: "state-1", claims: func(jwt.MapClaims) {}, wantLinked: true, wantExchange: true},
+		{name: "identity already linked", state: "state-1", linked: true, claims: func(c jwt.MapClaims) { c["email_verified"] = false }, wantLinked: true, wantExchange: true},
+		{name: "state mismatch", state: "state-2", claims: func(jwt.MapClaims) {}, wantErr: ErrInvalidOIDCState},
+		{name: "nonce mismatch", state: "state-1", claims: func(c jwt.MapClaims) { c["nonce"] = "nonce-2" }, wantErr: oidc.ErrInvalidIDToken, wantExchange: true},
+		{name: "other audience", state: "state-1", claims: func(c jwt.MapClaims) { c["aud"] = "another-client" }, wantErr: oidc.ErrInvalidIDToken, wantExchange: true},
+		{name: "expired id token", state: "state-1", claims: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() }, wantErr: oidc.ErrInvalidIDToken, wantExchange: true},
+		// Чужой адрес, заведённый у провайдера без подтверждения, не даёт
+		// войти в аккаунт с этим email.
+		{name: "unverified email of existing account", state: "state-1", claims: func(c jwt.MapClaims) { c["email_verified"] = false }, wantErr: ErrOIDCEmailNotVerified, wantExchange: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := oidctest.New(t)
+			claims := mock.Claims("nonce-1")
+			tt.claims(claims)
+			mock.SetClaims(claims)
+
+			s, _, _ := newMFATestService(t)
+			users := s.repo.Auth.(*fakeUsers)
+			users.user.Email = "student@example.com"
+			users.user.Roles = []int32{int32(student)}
+			identities := &fakeIdentities{
+				state: &repository.OIDCLoginState{
+					StateHash:    hashOpaqueToken("state-1"),
+					Provider:     "sso",
+					CodeVerifier: "verifier-1",
+					Nonce:        "nonce-1",
+					Role:         student,
+				},
+				linked: map[string]string{},
+			}
+			if tt.linked {
+				identities.linked["subject-1"] = "u1"
+			}
+			s.repo.Identities = identities
+			s.oidc = OIDCConfig{Registry: oidc.NewRegistry([]oidc.Config{mock.Config("sso")}, http.DefaultClient)}
+			s.mfa = MFAConfig{ChallengeTTL: time.Minute}
+
+			// TOTP у u1 включён: успешный вход заканчивается mfa-токеном.
+			resp, email, err := s.CompleteOIDC(context.Background(), "sso", tt.state, "code-1")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CompleteOIDC: %v, want %v", err, tt.wantErr)
+			}
+			if got := mock.Exchanges() > 0; got != tt.wantExchange {
+				t.Fatalf("code exchanged: %t, want %t", got, tt.wantExchange)
+			}
+			if got := identities.linked["subject-1"] == "u1"; got != tt.wantLinked {
+				t.Fatalf("identity linked to u1: %t, want %t", got, tt.wantLinked)
+			}
+			if users.created != 0 {
+				t.Fatal("account created for an existing email")
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if resp.UserUuid != "u1" || !resp.MfaRequired || resp.NewAccount || email != "student@example.com" {
+				t.Fatalf("CompleteOIDC = %+v, %s; want MFA challenge for u1", resp, email)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	authv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/auth/v1"
+	"github.com/studjobs/hh_for_students/auth/internal/repository"
+)
+
+// opaqueTokenBytes — энтропия opaque-токенов (refresh, сброс пароля): 256 бит.
+const opaqueTokenBytes = 32
+
+// newOpaqueToken возвращает пару (токен для клиента, его хэш для БД).
+func newOpaqueToken() (string, string, error) {
+	buf := make([]byte, opaqueTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("failed to read random bytes: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	return token, hashOpaqueToken(token), nil
+}
+
+// hashOpaqueToken — SHA-256 достаточно: токен случайный и длинный, перебор
+// по словарю не имеет смысла, а bcrypt не дал бы искать запись по хэшу.
+func hashOpaqueToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// issueTokens выпускает access-токен под активной ролью role и refresh-токен
+// в семье familyID (пустая строка — новый вход: создаётся сессия, её id и
+// становится семьёй). Возвращает ответ и id созданной записи.
+func (s *AuthService) issueTokens(ctx context.Context, user *repository.User, role authv1.Role, familyID string) (*authv1.AuthResponse, string, error) {
+	if user.BannedAt != nil {
+		log.Printf("Token issue rejected - user is banned: %s", user.UUID)
+		return nil, "", ErrAccountBanned
+	}
+	userUUID := user.UUID
+	if familyID == "" {
+		sessionID, err := s.startSession(ctx, userUUID, role)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to create session: %w", err)
+		}
+		familyID = sessionID
+	}
+
+	accessToken, err := s.token.GenerateToken(userUUID, user.Email, role, familyID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	refreshToken, refreshHash, err := newOpaqueToken()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	id, err := s.repo.Refresh.CreateRefreshToken(ctx, userUUID, familyID, refreshHash, int(role), time.Now().Add(s.refreshDuration))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	return &authv1.AuthResponse{
+		Token:        accessToken,
+		UserUuid:     userUUID,
+		Role:         role,
+		RefreshToken: refreshToken,
+		Roles:        rolesOf(user),
+	}, id, nil
+}
+
+// RefreshToken обменивает refresh-токен на новую пару токенов (ротация).
+//
+// Каждый refresh-токен одноразовый. Если уже погашенный токен приходит повторно,
+// значит его копия есть у кого-то ещё: отзываем всю семью, и легитимному
+// пользователю, и злоумышленнику придётся логиниться заново.
+func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*authv1.AuthResponse, error) {
+	stored, err := s.repo.Refresh.FindRefreshTokenByHash(ctx, hashOpaqueToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, repository.ErrRefreshTokenNotFound) {
+			log.Printf("Refresh failed - token not found")
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, fmt.Errorf("failed to find refresh token: %w", err)
+	}
+
+	if stored.RevokedAt != nil {
+		log.Printf("Refresh failed - token revoked, family: %s", stored.FamilyID)
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if stored.UsedAt != nil {
+		log.Printf("Refresh token reuse detected - user: %s, family: %s", stored.UserID, stored.FamilyID)
+		if err := s.repo.Refresh.RevokeFamily(ctx, stored.FamilyID); err != nil {
+			log.Printf("Failed to revoke refresh token family %s: %v", stored.FamilyID, err)
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	if time.Now().After(stored.ExpiresAt) {
+		log.Printf("Refresh failed - token expired, family: %s", stored.FamilyID)
+		return nil, ErrInvalidRefreshToken
+	}
+
+	marked, err := s.repo.Refresh.MarkRefreshTokenUsed(ctx, stored.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark refresh token used: %w", err)
+	}
+	if !marked {
+		// Параллельный запрос успел погасить этот же токен — это тоже reuse.
+		log.Printf("Refresh token reuse detected (race) - user: %s, family: %s", stored.UserID, stored.FamilyID)
+		if err := s.repo.Refresh.RevokeFamily(ctx, stored.FamilyID); err != nil {
+			log.Printf("Failed to revoke refresh token family %s: %v", stored.FamilyID, err)
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	user, err := s.repo.Auth.FindUserByUUID(ctx, stored.UserID)
+	if err != nil || user == nil {
+		log.Printf("Refresh failed - user not found: %s", stored.UserID)
+		if err := s.repo.Refresh.RevokeFamily(ctx, stored.FamilyID); err != nil {
+			log.Printf("Failed to revoke refresh token family %s: %v", stored.FamilyID, err)
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if !user.HasRole(stored.Role) {
+		log.Printf("Refresh failed - role %d no longer assigned to user: %s", stored.Role, user.UUID)
+		if err := s.repo.Refresh.RevokeFamily(ctx, stored.FamilyID); err != nil {
+			log.Printf("Failed to revoke refresh token family %s: %v", stored.FamilyID, err)
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+
+	familyID := stored.FamilyID
+	session, err := s.repo.Sessions.GetSession(ctx, familyID)
+	switch {
+	case errors.Is(err, repository.ErrSessionNotFound):
+		// Семья выпущена до появления сессий: дальше она живёт уже как сессия.
+		log.Printf("Refresh: family %s has no session, starting a new one", familyID)
+		familyID = ""
+	case err != nil:
+		return nil, fmt.Errorf("failed to load session: %w", err)
+	case !session.Active(time.Now()):
+		log.Printf("Refresh failed - session revoked: %s", familyID)
+		if err := s.repo.Refresh.RevokeFamily(ctx, familyID); err != nil {
+			log.Printf("Failed to revoke refresh token family %s: %v", familyID, err)
+		}
+		return nil, ErrInvalidRefreshToken
+	default:
+		client := clientInfoFrom(ctx)
+		if err := s.repo.Sessions.ExtendSession(ctx, familyID, client.IP, client.UserAgent, time.Now().Add(s.refreshDuration)); err != nil {
+			log.Printf("Failed to extend session %s: %v", familyID, err)
+		}
+	}
+
+	resp, newID, err := s.issueTokens(ctx, user, authv1.Role(stored.Role), familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Refresh.SetReplacedBy(ctx, stored.ID, newID); err != nil {
+		log.Printf("Failed to link refresh token %s -> %s: %v", stored.ID, newID, err)
+	}
+
+	log.Printf("Refresh successful for user: %s", user.UUID)
+	return resp, nil
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/studjobs/hh_for_students/auth/internal/mailer"
+	"github.com/studjobs/hh_for_students/auth/internal/repository"
+)
+
+// mailTimeout — сколько ждём почтовый сервер при отправке письма.
+const mailTimeout = 30 * time.Second
+
+// RequestPasswordReset выпускает одноразовый токен сброса и отправляет ссылку
+// на почту. Для неизвестного email тоже возвращает nil, а письмо уходит в
+// фоне: ни ответ, ни время ответа не выдают, зарегистрирован ли адрес.
+func (s *AuthService) RequestPasswordReset(ctx context.Context, email string) error {
+	log.Printf("Password reset requested for email: %s", email)
+
+	user, err := s.repo.Auth.FindUserByEmail(ctx, email)
+	if err != nil || user == nil {
+		log.Printf("Password reset: user not found, skipping: %s", email)
+		return nil
+	}
+
+	// Действительна только последняя ссылка.
+	if err := s.repo.PasswordReset.InvalidateUserResetTokens(ctx, user.UUID); err != nil {
+		return fmt.Errorf("failed to invalidate previous reset tokens: %w", err)
+	}
+
+	token, tokenHash, err := newOpaqueToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate reset token: %w", err)
+	}
+	if err := s.repo.PasswordReset.CreatePasswordResetToken(ctx, user.UUID, tokenHash, time.Now().Add(s.email.Reset.TokenDuration)); err != nil {
+		return fmt.Errorf("failed to store reset token: %w", err)
+	}
+
+	s.sendAsync(ctx, user.UUID, mailer.Message{
+		To:      user.Email,
+		Subject: "Сброс пароля",
+		Body: fmt.Sprintf("Здравствуйте!\n\nЧтобы задать новый пароль, перейдите по ссылке:\n%s\n\n"+
+			"Ссылка действует %d мин. и сработает один раз.\n"+
+			"Если вы не запрашивали сброс, просто проигнорируйте это письмо.\n",
+			tokenLink(s.email.Reset.URL, token), int(s.email.Reset.TokenDuration.Minutes())),
+	})
+
+	log.Printf("Password reset token issued for user: %s", user.UUID)
+	return nil
+}
+
+// ConfirmPasswordReset гасит токен, меняет пароль, отзывает все сессии
+// пользователя (и access-токены через logout-all, и refresh-токены) и снимает
+// блокировку входа.
+func (s *AuthService) ConfirmPasswordReset(ctx context.Context, token, newPassword string) error {
+	// Пароль проверяем до того, как погасить токен: иначе со слабым паролем
+	// пришлось бы запрашивать новую ссылку.
+	if err := s.passwords.Policy.Check(newPassword); err != nil {
+		log.Printf("Password reset confirm failed - password rejected: %v", err)
+		return err
+	}
+
+	userUUID, err := s.repo.PasswordReset.ConsumePasswordResetToken(ctx, hashOpaqueToken(token))
+	if err != nil {
+		if errors.Is(err, repository.ErrResetTokenNotFound) {
+			log.Printf("Password reset confirm failed - token invalid, used or expired")
+			return ErrInvalidResetToken
+		}
+		return fmt.Errorf("failed to consume reset token: %w", err)
+	}
+
+	hashedPassword, err := s.hashPassword(newPassword)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	if err := s.repo.Auth.UpdatePassword(ctx, userUUID, hashedPassword); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	if err := s.repo.Auth.LogoutUser(ctx, userUUID, time.Now().Add(s.token.TokenDuration())); err != nil {
+		return fmt.Errorf("failed to logout user: %w", err)
+	}
+	if err := s.repo.Refresh.RevokeUserRefreshTokens(ctx, userUUID); err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+
+	// Владелец подтвердил почту — блокировку входа после перебора снимаем.
+	if user, err := s.repo.Auth.FindUserByUUID(ctx, userUUID); err == nil && user != nil {
+		if err := s.repo.Attempts.ResetAttempts(ctx, accountLockKey(user.Email)); err != nil {
+			log.Printf("Failed to reset login attempts for user %s: %v", userUUID, err)
+		}
+	}
+
+	log.Printf("Password reset completed for user: %s", userUUID)
+	return nil
+}
+
+// sendAsync отправляет письмо в фоне: запрос не ждёт почтовый сервер, а
+// время ответа не зависит от того, ушло ли письмо.
+func (s *AuthService) sendAsync(ctx context.Context, userUUID string, msg mailer.Message) {
+	go func() {
+		sendCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), mailTimeout)
+		defer cancel()
+		if err := s.mailer.Send(sendCtx, msg); err != nil {
+			log.Printf("Failed to send %q to user %s: %v", msg.Subject, userUUID, err)
+		}
+	}()
+}
+
+// tokenLink добавляет токен к URL страницы фронтенда.
+func tokenLink(base, token string) string {
+	u, err := url.Parse(base)
+	if err != nil || base == "" {
+		return token
+	}
+	q := u.Query()
+	q.Set("token", token)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
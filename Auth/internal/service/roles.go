@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	authv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/auth/v1"
+	"github.com/studjobs/hh_for_students/auth/internal/repository"
+)
+
+// rolesOf переводит роли пользователя из БД в proto-значения.
+func rolesOf(user *repository.User) []authv1.Role {
+	roles := make([]authv1.Role, 0, len(user.Roles))
+	for _, r := range user.Roles {
+		roles = append(roles, authv1.Role(r))
+	}
+	return roles
+}
+
+// selfServiceRole — роль, которую пользователь может взять себе сам
+// (регистрация, вход через провайдера, роль к существующему аккаунту).
+// Список закрытый: новая роль в proto не становится доступной сама собой.
+// ROLE_ADMIN выдаётся только через ADMIN_EMAILS (см. SyncAdmins),
+// ROLE_DEVELOPER — только вручную.
+func selfServiceRole(role authv1.Role) bool {
+	switch role {
+	case authv1.Role_ROLE_STUDENT,
+		authv1.Role_ROLE_EMPLOYER,
+		authv1.Role_ROLE_EXPERT,
+		authv1.Role_ROLE_COMPANY_OWNER:
+		return true
+	}
+	return false
+}
+
+// addRoleToExisting — регистрация с уже занятым email. Если пароль совпадает,
+// это тот же человек, который хочет ещё одну роль (эксперт, который также
+// студент; HR, основавший компанию): выдаём роль и сразу входим под ней.
+// Иначе — обычный ErrUserAlreadyExists. Неверный пароль считается неудачной
+// попыткой входа, иначе регистрация стала бы обходом блокировки.
+func (s *AuthService) addRoleToExisting(ctx context.Context, user *repository.User, password string, role authv1.Role) (*authv1.AuthResponse, error) {
+	accountKey := accountLockKey(user.Email)
+	if err := s.checkLockout(ctx, accountKey, ""); err != nil {
+		log.Printf("Registration rejected - %v, email: %s", err, user.Email)
+		return nil, err
+	}
+
+	if user.HasRole(int(role)) {
+		log.Printf("Registration failed - user %s already has role %v", user.UUID, role)
+		return nil, ErrUserAlreadyExists
+	}
+
+	needsRehash, err := s.verifyPassword(user.Password, password)
+	if err != nil {
+		log.Printf("Registration failed - user already exists: %s", user.Email)
+		s.recordLoginFailure(ctx, accountKey, "")
+		return nil, ErrUserAlreadyExists
+	}
+	if needsRehash {
+		s.rehashPassword(ctx, user.UUID, password)
+	}
+
+	if _, err := s.repo.Auth.AddUserRole(ctx, user.UUID, int(role)); err != nil {
+		return nil, fmt.Errorf("failed to add role: %w", err)
+	}
+	user.Roles = append(user.Roles, int32(role))
+
+	resp, err := s.completeLogin(ctx, user, role)
+	if err != nil {
+		log.Printf("Token generation failed for user %s: %v", user.Email, err)
+		return nil, err
+	}
+
+	log.Printf("Role %v added to existing user: %s", role, user.UUID)
+	return resp, nil
+}
+
+// SwitchRole выпускает новую пару токенов под другой ролью того же аккаунта.
+// Токены текущей сессии (access и, если передан, refresh) отзываются: в
+// каждый момент сессия действует от имени одной роли.
+func (s *AuthService) SwitchRole(ctx context.Context, accessToken, refreshToken string, role authv1.Role) (*authv1.AuthResponse, error) {
+	validation, err := s.ValidateToken(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	if !validation.Valid {
+		log.Printf("Switch role failed - invalid access token")
+		return nil, ErrInvalidToken
+	}
+
+	user, err := s.repo.Auth.FindUserByUUID(ctx, validation.UserUuid)
+	if err != nil || user == nil {
+		log.Printf("Switch role failed - user not found: %s", validation.UserUuid)
+		return nil, ErrInvalidToken
+	}
+	if !user.HasRole(int(role)) {
+		log.Printf("Switch role failed - role %v not assigned to user %s", role, user.UUID)
+		return nil, ErrRoleNotAssigned
+	}
+
+	// Роль с обязательным TOTP недоступна, пока он не подключён: текущая
+	// сессия остаётся, а клиент получает mfa-токен для подключения.
+	if s.mfa.required(role) {
+		settings, err := s.mfaSettings(ctx, user.UUID)
+		if err != nil {
+			return nil, err
+		}
+		if !settings.Enabled() {
+			return s.startMFAChallenge(ctx, user, role, true)
+		}
+	}
+
+	resp, _, err := s.issueTokens(ctx, user, role, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.Logout(ctx, accessToken, refreshToken, false); err != nil {
+		log.Printf("Switch role: failed to revoke previous session of user %s: %v", user.UUID, err)
+	}
+
+	log.Printf("User %s switched role %v -> %v", user.UUID, validation.Role, role)
+	return resp, nil
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	authv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/auth/v1"
+	"github.com/studjobs/hh_for_students/auth/internal/mailer"
+	"github.com/studjobs/hh_for_students/auth/internal/password"
 	"github.com/studjobs/hh_for_students/auth/internal/repository"
 	"time"
 )
@@ -12,33 +14,127 @@ var (
 	ErrInvalidCredentials = errors.New("invalid email or password")
 	ErrUserAlreadyExists  = errors.New("user with this email already exists")
 	ErrUserLoggedOut      = errors.New("user has been logged out")
+	ErrUserNotFound       = errors.New("user not found")
+	ErrRoleNotAssigned    = errors.New("role is not assigned to user")
+	ErrRoleNotSelfService = errors.New("role cannot be self-assigned")
+	ErrAccountBanned      = errors.New("account is banned")
+	ErrCannotBanAdmin     = errors.New("administrators cannot be banned")
+
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected")
+
+	ErrInvalidResetToken = errors.New("invalid or expired password reset token")
+
+	ErrInvalidVerificationToken = errors.New("invalid or expired email verification token")
+	ErrEmailAlreadyVerified     = errors.New("email already verified")
+
+	ErrInvalidMFAToken   = errors.New("invalid or expired mfa token")
+	ErrInvalidMFACode    = errors.New("invalid verification code")
+	ErrMFAAlreadyEnabled = errors.New("two-factor authentication already enabled")
+	ErrMFANotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrMFARequired       = errors.New("two-factor authentication is required for this account")
+
+	ErrInvalidOIDCState     = errors.New("invalid or expired oidc state")
+	ErrOIDCEmailNotVerified = errors.New("provider did not confirm the email address")
+	ErrIdentityLinked       = errors.New("external account is linked to another user")
+
+	ErrSessionNotFound = errors.New("session not found")
+
+	ErrInvalidPageToken = errors.New("invalid page token")
 )
 
 type ITokenManager interface {
-	GenerateToken(userUUID, email string, role authv1.Role) (string, error)
-	ValidateToken(token string) (string, authv1.Role, error)
+	GenerateToken(userUUID, email string, role authv1.Role, sessionID string) (string, error)
+	ValidateToken(token string) (*Claims, error)
+	TokenDuration() time.Duration
 }
 
 type IAuthService interface {
-	AuthenticateUser(ctx context.Context, email, password string, role authv1.Role) (*authv1.AuthResponse, error)
+	AuthenticateUser(ctx context.Context, email, password string, role authv1.Role, clientIP string) (*authv1.AuthResponse, error)
 	RegisterUser(ctx context.Context, email, password string, role authv1.Role) (*authv1.AuthResponse, error)
 	ValidateToken(ctx context.Context, token string) (*authv1.TokenValidation, error)
 	hashPassword(password string) (string, error)
-	verifyPassword(hashedPassword, password string) error
+	verifyPassword(hashedPassword, password string) (bool, error)
 	DeleteUser(ctx context.Context, userID string) error
+	RefreshToken(ctx context.Context, refreshToken string) (*authv1.AuthResponse, error)
+	Logout(ctx context.Context, accessToken, refreshToken string, allSessions bool) error
+	CleanupExpired(ctx context.Context) error
+	RequestPasswordReset(ctx context.Context, email string) error
+	ConfirmPasswordReset(ctx context.Context, token, newPassword string) error
+	RequestEmailVerification(ctx context.Context, userUUID string) error
+	ConfirmEmail(ctx context.Context, token string) error
+	UnlockAccount(ctx context.Context, email string) error
+	SwitchRole(ctx context.Context, accessToken, refreshToken string, role authv1.Role) (*authv1.AuthResponse, error)
+	VerifyMFA(ctx context.Context, mfaToken, code, clientIP string) (*authv1.AuthResponse, error)
+	EnrollTOTP(ctx context.Context, userUUID, mfaToken string) (*authv1.TOTPEnrollment, error)
+	ConfirmTOTP(ctx context.Context, userUUID, mfaToken, code string) (*authv1.TOTPConfirmation, error)
+	DisableTOTP(ctx context.Context, userUUID, code, clientIP string) error
+	RegenerateRecoveryCodes(ctx context.Context, userUUID, code, clientIP string) ([]string, error)
+	ListOIDCProviders(ctx context.Context) []string
+	StartOIDC(ctx context.Context, provider string, role authv1.Role, linkUserUUID string) (string, string, error)
+	CompleteOIDC(ctx context.Context, provider, state, code string) (*authv1.AuthResponse, string, error)
+	ListSessions(ctx context.Context, accessToken string) ([]*authv1.Session, error)
+	RevokeSession(ctx context.Context, accessToken, sessionID string) error
+	RevokeOtherSessions(ctx context.Context, accessToken string) (int, error)
+	ListAccounts(ctx context.Context, createdAfter time.Time, pageToken string, limit int) ([]*authv1.Account, string, error)
+	ExportAccount(ctx context.Context, userUUID string) (*authv1.AccountExport, error)
+	CreateAPIKey(ctx context.Context, accessToken, name string, scopes []string, expiresInDays int) (*authv1.ApiKey, string, error)
+	ListAPIKeys(ctx context.Context, accessToken string) ([]*authv1.ApiKey, error)
+	RevokeAPIKey(ctx context.Context, accessToken, keyID string) (string, error)
+	ValidateAPIKey(ctx context.Context, key string) (*authv1.ApiKeyValidation, error)
+	SyncAdmins(ctx context.Context, emails []string) error
+	BanUser(ctx context.Context, adminUUID, userUUID, reason string) (*authv1.Account, error)
+	UnbanUser(ctx context.Context, userUUID string) (*authv1.Account, error)
+	SearchAccounts(ctx context.Context, filter repository.UserFilter, page, limit int) ([]*authv1.Account, int, error)
+	RecordAdminAction(ctx context.Context, action *repository.AdminAction) (*authv1.AdminAction, error)
+	ListAdminActions(ctx context.Context, filter repository.AdminActionFilter, page, limit int) ([]*authv1.AdminAction, int, error)
+	RecordAuditEvent(ctx context.Context, event *repository.AuditEvent) (*authv1.AuditEvent, error)
+	ListAuditEvents(ctx context.Context, filter repository.AuditFilter, page, limit int) ([]*authv1.AuditEvent, int, error)
 }
 
 type JWTConfig struct {
+	// SecretKey — устаревший HS256-секрет, нужен только для проверки токенов,
+	// выпущенных до перехода на Ed25519. Пустой — такие токены не принимаются.
 	SecretKey     string
 	TokenDuration time.Duration
+	// RefreshTokenDuration — время жизни opaque refresh-токена. Access-токен
+	// при этом можно держать коротким (TokenDuration).
+	RefreshTokenDuration time.Duration
+	// KeyRotationInterval — как часто выпускать новый ключ подписи (<= 0 — вручную).
+	KeyRotationInterval time.Duration
+	// KeyPropagationDelay — за сколько до активации новый ключ появляется в JWKS.
+	KeyPropagationDelay time.Duration
+}
+
+// LinkConfig — параметры ссылки с одноразовым токеном, отправляемой на почту.
+type LinkConfig struct {
+	// TokenDuration — сколько действует ссылка из письма.
+	TokenDuration time.Duration
+	// URL — страница фронтенда, куда ведёт ссылка; токен добавляется
+	// query-параметром token.
+	URL string
+}
+
+type EmailConfig struct {
+	Reset        LinkConfig
+	Verification LinkConfig
+}
+
+// PasswordConfig — хеширование паролей и требования к новым паролям.
+type PasswordConfig struct {
+	Hasher *password.Hasher
+	Policy *password.Policy
 }
 
 type Service struct {
 	Auth IAuthService
+	Keys *KeyRing
 }
 
-func NewService(repo *repository.Repository, cfg JWTConfig) *Service {
+func NewService(repo *repository.Repository, cfg JWTConfig, mail mailer.Mailer, email EmailConfig, lockout LockoutConfig, mfa MFAConfig, oidc OIDCConfig, passwords PasswordConfig, apiKeys APIKeyConfig) *Service {
+	keys := NewKeyRing(repo.Keys, cfg)
 	return &Service{
-		Auth: NewAuthService(repo, NewJWTManager(cfg)),
+		Auth: NewAuthService(repo, NewJWTManager(cfg, keys), cfg.RefreshTokenDuration, mail, email, lockout, mfa, oidc, passwords, apiKeys),
+		Keys: keys,
 	}
 }
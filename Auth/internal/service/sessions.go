@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	authv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/auth/v1"
+	"github.com/studjobs/hh_for_students/auth/internal/repository"
+)
+
+// sessionTouchInterval — не чаще этого обновляем last_seen_at при проверке
+// токена: иначе каждый запрос через Gateway был бы записью в БД.
+const sessionTouchInterval = time.Minute
+
+// maxUserAgentLength — длина user_agent в таблице sessions.
+const maxUserAgentLength = 512
+
+// ClientInfo — откуда пришёл запрос: адрес и User-Agent конечного клиента
+// (их передаёт Gateway). Записываются в сессию при входе и обновлении токенов.
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+type clientInfoKey struct{}
+
+// WithClientInfo кладёт сведения о клиенте в контекст запроса.
+func WithClientInfo(ctx context.Context, info ClientInfo) context.Context {
+	return context.WithValue(ctx, clientInfoKey{}, info)
+}
+
+func clientInfoFrom(ctx context.Context) ClientInfo {
+	info, _ := ctx.Value(clientInfoKey{}).(ClientInfo)
+	if len(info.UserAgent) > maxUserAgentLength {
+		info.UserAgent = strings.ToValidUTF8(info.UserAgent[:maxUserAgentLength], "")
+	}
+	return info
+}
+
+// startSession создаёт сессию для нового входа. Её id становится family_id
+// refresh-токенов и claim sid access-токенов.
+func (s *AuthService) startSession(ctx context.Context, userUUID string, role authv1.Role) (string, error) {
+	client := clientInfoFrom(ctx)
+	return s.repo.Sessions.CreateSession(ctx, &repository.Session{
+		UserID:    userUUID,
+		Role:      int(role),
+		Device:    deviceName(client.UserAgent),
+		UserAgent: client.UserAgent,
+		IP:        client.IP,
+		ExpiresAt: time.Now().Add(s.refreshDuration),
+	})
+}
+
+// checkSession — сессия токена не отозвана и принадлежит пользователю.
+// Заодно (не чаще sessionTouchInterval) отмечает активность.
+func (s *AuthService) checkSession(ctx context.Context, sessionID, userUUID string) bool {
+	session, err := s.repo.Sessions.GetSession(ctx, sessionID)
+	if err != nil {
+		if !errors.Is(err, repository.ErrSessionNotFound) {
+			log.Printf("Failed to load session %s: %v", sessionID, err)
+		}
+		return false
+	}
+
+	now := time.Now()
+	if session.UserID != userUUID || !session.Active(now) {
+		return false
+	}
+
+	if now.Sub(session.LastSeenAt) > sessionTouchInterval {
+		if err := s.repo.Sessions.TouchSession(ctx, sessionID, now); err != nil {
+			log.Printf("Failed to touch session %s: %v", sessionID, err)
+		}
+	}
+	return true
+}
+
+// currentSession проверяет access-токен и возвращает его claims: для
+// управления сессиями нужен sid текущего токена.
+func (s *AuthService) currentSession(ctx context.Context, accessToken string) (*Claims, error) {
+	validation, err := s.ValidateToken(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	if !validation.Valid {
+		return nil, ErrInvalidToken
+	}
+	return s.token.ValidateToken(accessToken)
+}
+
+// ListSessions возвращает активные сессии пользователя; текущая помечена.
+func (s *AuthService) ListSessions(ctx context.Context, accessToken string) ([]*authv1.Session, error) {
+	claims, err := s.currentSession(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.repo.Sessions.ListUserSessions(ctx, claims.UserUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*authv1.Session, 0, len(sessions))
+	for _, session := range sessions {
+		result = append(result, toProtoSession(session, claims.SessionID))
+	}
+	return result, nil
+}
+
+func toProtoSession(session *repository.Session, currentID string) *authv1.Session {
+	return &authv1.Session{
+		Id:         session.ID,
+		Device:     session.Device,
+		Ip:         session.IP,
+		UserAgent:  session.UserAgent,
+		Role:       authv1.Role(session.Role),
+		CreatedAt:  session.CreatedAt.Format(time.RFC3339),
+		LastSeenAt: session.LastSeenAt.Format(time.RFC3339),
+		Current:    session.ID == currentID,
+	}
+}
+
+// RevokeSession завершает одну сессию пользователя (например, на потерянном
+// ноутбуке): гасит её refresh-токены, а access-токены перестают проходить
+// ValidateToken. Завершить можно и текущую сессию.
+func (s *AuthService) RevokeSession(ctx context.Context, accessToken, sessionID string) error {
+	claims, err := s.currentSession(ctx, accessToken)
+	if err != nil {
+		return err
+	}
+
+	revoked, err := s.repo.Sessions.RevokeSession(ctx, claims.UserUUID, sessionID)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		log.Printf("Revoke session failed - session %s not found for user %s", sessionID, claims.UserUUID)
+		return ErrSessionNotFound
+	}
+	if err := s.repo.Refresh.RevokeFamily(ctx, sessionID); err != nil {
+		return fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+
+	log.Printf("Session %s of user %s revoked", sessionID, claims.UserUUID)
+	return nil
+}
+
+// RevokeOtherSessions завершает все сессии пользователя, кроме текущей, и
+// возвращает их число.
+func (s *AuthService) RevokeOtherSessions(ctx context.Context, accessToken string) (int, error) {
+	claims, err := s.currentSession(ctx, accessToken)
+	if err != nil {
+		return 0, err
+	}
+	if claims.SessionID == "" {
+		// Токен выпущен до появления сессий — «текущую» не отличить.
+		log.Printf("Revoke other sessions failed - token of user %s has no session", claims.UserUUID)
+		return 0, ErrSessionNotFound
+	}
+
+	return s.revokeUserSessions(ctx, claims.UserUUID, claims.SessionID)
+}
+
+// revokeUserSessions отзывает сессии пользователя (кроме exceptID) вместе с их
+// refresh-токенами.
+func (s *AuthService) revokeUserSessions(ctx context.Context, userUUID, exceptID string) (int, error) {
+	ids, err := s.repo.Sessions.RevokeUserSessions(ctx, userUUID, exceptID)
+	if err != nil {
+		return 0, err
+	}
+	for _, id := range ids {
+		if err := s.repo.Refresh.RevokeFamily(ctx, id); err != nil {
+			return 0, fmt.Errorf("failed to revoke refresh token family: %w", err)
+		}
+	}
+
+	log.Printf("Revoked %d sessions of user %s", len(ids), userUUID)
+	return len(ids), nil
+}
+
+// deviceName — короткое описание устройства для списка сессий («Chrome on
+// Windows»). Пусто, если User-Agent не похож на браузер.
+func deviceName(userAgent string) string {
+	if userAgent == "" {
+		return ""
+	}
+
+	browser := ""
+	switch {
+	case strings.Contains(userAgent, "Edg/"):
+		browser = "Edge"
+	case strings.Contains(userAgent, "OPR/"):
+		browser = "Opera"
+	case strings.Contains(userAgent, "YaBrowser/"):
+		browser = "Yandex Browser"
+	case strings.Contains(userAgent, "Firefox/"):
+		browser = "Firefox"
+	case strings.Contains(userAgent, "Chrome/"):
+		browser = "Chrome"
+	case strings.Contains(userAgent, "Safari/"):
+		browser = "Safari"
+	}
+
+	platform := ""
+	switch {
+	case strings.Contains(userAgent, "Windows"):
+		platform = "Windows"
+	case strings.Contains(userAgent, "Android"):
+		platform = "Android"
+	case strings.Contains(userAgent, "iPhone"), strings.Contains(userAgent, "iPad"):
+		platform = "iOS"
+	case strings.Contains(userAgent, "Mac OS X"):
+		platform = "macOS"
+	case strings.Contains(userAgent, "Linux"):
+		platform = "Linux"
+	}
+
+	switch {
+	case browser != "" && platform != "":
+		return browser + " on " + platform
+	case browser != "":
+		return browser
+	default:
+		return platform
+	}
+}
@@ -1,6 +1,8 @@
 package service
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"log"
@@ -15,40 +17,68 @@ var (
 	ErrExpiredToken = errors.New("expired token")
 )
 
+// JWTManager подписывает access-токены Ed25519-ключом из KeyRing и кладёт его
+// kid в заголовок, чтобы проверяющая сторона нашла нужный ключ в JWKS.
+//
+// legacySecret — старый HS256-секрет. Если задан, токены, подписанные им до
+// перехода на асимметричную подпись, принимаются до своего истечения; новые
+// им не подписываются.
 type JWTManager struct {
-	secretKey     string
+	keys          *KeyRing
+	legacySecret  string
 	tokenDuration time.Duration
 }
 
+// Claims — полезная нагрузка access-токена. RegisteredClaims.ID сериализуется
+// как jti: по нему отзывается конкретный токен (см. revoked_tokens). SessionID
+// (sid) — сессия, в которой выпущен токен; у токенов, выпущенных до появления
+// сессий, пустой.
 type Claims struct {
-	UserUUID string      `json:"user_uuid"`
-	Email    string      `json:"email"`
-	Role     authv1.Role `json:"role"`
+	UserUUID  string      `json:"user_uuid"`
+	Email     string      `json:"email"`
+	Role      authv1.Role `json:"role"`
+	SessionID string      `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func NewJWTManager(cfg JWTConfig) *JWTManager {
+func NewJWTManager(cfg JWTConfig, keys *KeyRing) *JWTManager {
 	return &JWTManager{
-		secretKey:     cfg.SecretKey,
+		keys:          keys,
+		legacySecret:  cfg.SecretKey,
 		tokenDuration: cfg.TokenDuration,
 	}
 }
 
-func (m *JWTManager) GenerateToken(userUUID, email string, role authv1.Role) (string, error) {
+func (m *JWTManager) GenerateToken(userUUID, email string, role authv1.Role, sessionID string) (string, error) {
 	log.Printf("Generating JWT token for user: %s, role: %v", userUUID, role)
 
+	jti, err := newTokenID()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate token id: %w", err)
+	}
+
 	claims := Claims{
-		UserUUID: userUUID,
-		Email:    email,
-		Role:     role,
+		UserUUID:  userUUID,
+		Email:     email,
+		Role:      role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			Subject:   userUUID,
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(m.tokenDuration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(m.secretKey))
+	kid, privateKey, err := m.keys.SigningKey()
+	if err != nil {
+		log.Printf("JWT token generation failed - no signing key: %v", err)
+		return "", fmt.Errorf("failed to get signing key: %w", err)
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	token.Header["kid"] = kid
+	tokenString, err := token.SignedString(privateKey)
 	if err != nil {
 		log.Printf("JWT token generation failed: %v", err)
 		return "", fmt.Errorf("failed to generate token: %w", err)
@@ -58,27 +88,56 @@ func (m *JWTManager) GenerateToken(userUUID, email string, role authv1.Role) (st
 	return tokenString, nil
 }
 
-func (m *JWTManager) ValidateToken(tokenString string) (string, authv1.Role, error) {
-	log.Printf("Validating JWT token: %s...", tokenString[:10])
+func (m *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
+	log.Printf("Validating JWT token: %s...", tokenString[:min(10, len(tokenString))])
 
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		switch token.Method.(type) {
+		case *jwt.SigningMethodEd25519:
+			kid, _ := token.Header["kid"].(string)
+			publicKey, err := m.keys.VerificationKey(kid)
+			if err != nil {
+				log.Printf("Unknown signing key id: %q", kid)
+				return nil, err
+			}
+			return publicKey, nil
+		case *jwt.SigningMethodHMAC:
+			if m.legacySecret == "" {
+				log.Printf("HS256 token rejected - legacy secret not configured")
+				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+			}
+			return []byte(m.legacySecret), nil
+		default:
 			log.Printf("Unexpected signing method: %v", token.Header["alg"])
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return []byte(m.secretKey), nil
 	})
 
 	if err != nil {
 		log.Printf("JWT token parsing failed: %v", err)
-		return "", authv1.Role_ROLE_UNSPECIFIED, err
+		return nil, err
 	}
 
 	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
 		log.Printf("JWT token validated - user_uuid: %s, role: %v", claims.UserUUID, claims.Role)
-		return claims.UserUUID, claims.Role, nil
+		return claims, nil
 	}
 
 	log.Printf("JWT token validation failed - invalid token")
-	return "", authv1.Role_ROLE_UNSPECIFIED, ErrInvalidToken
+	return nil, ErrInvalidToken
+}
+
+// TokenDuration — время жизни access-токена. Нужно, чтобы понимать, сколько
+// держать запись об отзыве: дольше любой токен всё равно не проживёт.
+func (m *JWTManager) TokenDuration() time.Duration {
+	return m.tokenDuration
+}
+
+// newTokenID генерирует случайный jti (128 бит, hex).
+func newTokenID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
 }
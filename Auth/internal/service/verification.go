@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/studjobs/hh_for_students/auth/internal/mailer"
+	"github.com/studjobs/hh_for_students/auth/internal/repository"
+)
+
+// sendVerification выпускает токен подтверждения (предыдущие гасятся) и
+// отправляет ссылку на email.
+func (s *AuthService) sendVerification(ctx context.Context, userUUID, email string) error {
+	if err := s.repo.EmailVerification.InvalidateUserVerificationTokens(ctx, userUUID); err != nil {
+		return fmt.Errorf("failed to invalidate previous verification tokens: %w", err)
+	}
+
+	token, tokenHash, err := newOpaqueToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate verification token: %w", err)
+	}
+	expiresAt := time.Now().Add(s.email.Verification.TokenDuration)
+	if err := s.repo.EmailVerification.CreateVerificationToken(ctx, userUUID, tokenHash, expiresAt); err != nil {
+		return fmt.Errorf("failed to store verification token: %w", err)
+	}
+
+	s.sendAsync(ctx, userUUID, mailer.Message{
+		To:      email,
+		Subject: "Подтверждение email",
+		Body: fmt.Sprintf("Здравствуйте!\n\nЧтобы подтвердить адрес, перейдите по ссылке:\n%s\n\n"+
+			"Ссылка действует %d ч.\n"+
+			"Если вы не регистрировались на StudJobs, просто проигнорируйте это письмо.\n",
+			tokenLink(s.email.Verification.URL, token), int(s.email.Verification.TokenDuration.Hours())),
+	})
+
+	log.Printf("Email verification sent for user: %s", userUUID)
+	return nil
+}
+
+// RequestEmailVerification повторно отправляет ссылку подтверждения.
+func (s *AuthService) RequestEmailVerification(ctx context.Context, userUUID string) error {
+	user, err := s.repo.Auth.FindUserByUUID(ctx, userUUID)
+	if err != nil || user == nil {
+		log.Printf("Resend verification failed - user not found: %s", userUUID)
+		return ErrUserNotFound
+	}
+	if user.EmailVerifiedAt != nil {
+		return ErrEmailAlreadyVerified
+	}
+	return s.sendVerification(ctx, user.UUID, user.Email)
+}
+
+// ConfirmEmail гасит токен из письма и отмечает адрес подтверждённым.
+func (s *AuthService) ConfirmEmail(ctx context.Context, token string) error {
+	userUUID, err := s.repo.EmailVerification.ConsumeVerificationToken(ctx, hashOpaqueToken(token))
+	if err != nil {
+		if errors.Is(err, repository.ErrVerificationTokenNotFound) {
+			log.Printf("Email confirm failed - token invalid, used or expired")
+			return ErrInvalidVerificationToken
+		}
+		return fmt.Errorf("failed to consume verification token: %w", err)
+	}
+
+	if err := s.repo.Auth.MarkEmailVerified(ctx, userUUID); err != nil {
+		return fmt.Errorf("failed to mark email verified: %w", err)
+	}
+
+	log.Printf("Email confirmed for user: %s", userUUID)
+	return nil
+}
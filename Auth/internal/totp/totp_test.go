@@ -0,0 +1,115 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret — ключ SHA1 из приложения B RFC 6238 ("12345678901234567890") в base32.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// Векторы RFC 6238 (приложение B, SHA1) — последние 6 цифр восьмизначных
+// кодов: усечение по модулю 10^6 оставляет именно их.
+func TestCodeRFC6238Vectors(t *testing.T) {
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		got, err := Code(rfcSecret, Step(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("Code at %d: %v", tt.unix, err)
+		}
+		if got != tt.want {
+			t.Errorf("Code at %d = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+
+	// Секрет вводят вручную — регистр не важен.
+	if got, _ := Code(strings.ToLower(rfcSecret), Step(time.Unix(59, 0))); got != "287082" {
+		t.Errorf("Code with lowercase secret = %s, want 287082", got)
+	}
+	if _, err := Code("not base32!", 1); err == nil {
+		t.Error("Code accepted an invalid secret")
+	}
+}
+
+func TestValidateSkewWindow(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	current := Step(now)
+	code := func(step int64) string {
+		c, err := Code(rfcSecret, step)
+		if err != nil {
+			t.Fatalf("Code: %v", err)
+		}
+		return c
+	}
+
+	tests := []struct {
+		name     string
+		code     string
+		skew     int
+		wantStep int64
+		wantOK   bool
+	}{
+		{"current step", code(current), 1, current, true},
+		{"previous step", code(current - 1), 1, current - 1, true},
+		{"next step", code(current + 1), 1, current + 1, true},
+		{"two steps back", code(current - 2), 1, 0, false},
+		{"two steps ahead", code(current + 2), 1, 0, false},
+		{"no skew, previous step", code(current - 1), 0, 0, false},
+		{"surrounding spaces", " " + code(current) + " ", 1, current, true},
+		{"short code", code(current)[:5], 1, 0, false},
+		{"wrong code", "000000", 0, 0, code(current) == "000000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := Validate(rfcSecret, tt.code, now, tt.skew)
+			if ok != tt.wantOK || (ok && step != tt.wantStep) {
+				t.Fatalf("Validate(%q, skew %d) = %d, %t; want %d, %t", tt.code, tt.skew, step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+// Validate возвращает шаг кода: по нему вызывающий отбрасывает повтор того же
+// кода (и более старых) в пределах окна.
+func TestValidateReturnsStepForReplayCheck(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	c, err := Code(rfcSecret, Step(now))
+	if err != nil {
+		t.Fatalf("Code: %v", err)
+	}
+	first, ok := Validate(rfcSecret, c, now, 1)
+	if !ok {
+		t.Fatal("code rejected")
+	}
+	// Тот же код через 30 секунд ещё в окне — и даёт тот же шаг.
+	second, ok := Validate(rfcSecret, c, now.Add(Period), 1)
+	if !ok || second != first {
+		t.Fatalf("replayed code: step %d, %t; want %d, true", second, ok, first)
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	a, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret: %v", err)
+	}
+	b, _ := GenerateSecret()
+	if a == b {
+		t.Fatal("two secrets are equal")
+	}
+	raw, err := encoding.DecodeString(a)
+	if err != nil || len(raw) != SecretSize {
+		t.Fatalf("secret %q: %d bytes, %v; want %d bytes", a, len(raw), err, SecretSize)
+	}
+}
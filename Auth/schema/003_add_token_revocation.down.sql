@@ -0,0 +1,2 @@
+COMMENT ON TABLE user_logouts IS NULL;
+DROP TABLE IF EXISTS revoked_tokens;
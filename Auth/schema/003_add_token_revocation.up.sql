@@ -0,0 +1,14 @@
+-- Денилист отдельных access-токенов по jti. Строку держим, пока сам токен
+-- не истёк: после expires_at подпись уже не пройдёт проверку, запись не нужна.
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    jti VARCHAR(64) PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(uuid) ON DELETE CASCADE,
+    revoked_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_revoked_tokens_expires_at ON revoked_tokens(expires_at);
+
+-- user_logouts теперь означает «выйти на всех устройствах»: токены, выпущенные
+-- до logged_out_at, недействительны. Одна строка на пользователя, обновляется upsert'ом.
+COMMENT ON TABLE user_logouts IS 'logout-all: tokens issued before logged_out_at are rejected';
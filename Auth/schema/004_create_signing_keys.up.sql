@@ -0,0 +1,17 @@
+-- Ключи подписи access-токенов (Ed25519). Приватная часть — PKCS#8 DER и
+-- никогда не покидает Auth; публичная публикуется в JWKS.
+--   activates_at — с этого момента ключ подписывает новые токены. Новый ключ
+--                  создаётся заранее, чтобы потребители JWKS успели его увидеть.
+--   expires_at   — после этого момента ключ не годится даже для проверки
+--                  (все подписанные им токены уже истекли). NULL — текущий ключ.
+CREATE TABLE IF NOT EXISTS signing_keys (
+    kid VARCHAR(64) PRIMARY KEY,
+    algorithm VARCHAR(16) NOT NULL DEFAULT 'EdDSA',
+    private_key BYTEA NOT NULL,
+    public_key BYTEA NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    activates_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_signing_keys_expires_at ON signing_keys(expires_at);
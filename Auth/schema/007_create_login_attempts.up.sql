@@ -0,0 +1,11 @@
+-- Счётчики неудачных входов. key — "email:<адрес>" или "ip:<адрес>". По email
+-- считаем и для несуществующих аккаунтов: иначе блокировка выдавала бы, какие
+-- адреса зарегистрированы.
+CREATE TABLE IF NOT EXISTS login_attempts (
+    key VARCHAR(320) PRIMARY KEY,
+    failures INTEGER NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    locked_until TIMESTAMP WITH TIME ZONE NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_attempts_last_failure_at ON login_attempts(last_failure_at);
@@ -0,0 +1,12 @@
+-- Набор ролей пользователя. users.role остаётся ролью, с которой аккаунт был
+-- создан (и ролью по умолчанию), а права определяются этой таблицей.
+CREATE TABLE IF NOT EXISTS user_roles (
+    user_id UUID NOT NULL REFERENCES users(uuid) ON DELETE CASCADE,
+    role INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, role)
+);
+
+INSERT INTO user_roles (user_id, role, created_at)
+SELECT uuid, role, COALESCE(created_at, NOW()) FROM users
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,29 @@
+-- Внешние учётные записи (OIDC/OAuth2), привязанные к пользователям. Один
+-- аккаунт провайдера — не больше одного пользователя.
+CREATE TABLE IF NOT EXISTS user_identities (
+    provider VARCHAR(64) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(uuid) ON DELETE CASCADE,
+    email VARCHAR(255) NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (provider, subject)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_identities_user ON user_identities(user_id);
+
+-- Незавершённые входы через провайдера: state (в БД — SHA-256), PKCE
+-- code_verifier и nonce живут между /start и /callback. В БД, а не в памяти,
+-- чтобы callback мог попасть на любой инстанс Auth.
+CREATE TABLE IF NOT EXISTS oidc_login_states (
+    state_hash VARCHAR(64) PRIMARY KEY,
+    provider VARCHAR(64) NOT NULL,
+    code_verifier VARCHAR(128) NOT NULL,
+    nonce VARCHAR(128) NOT NULL,
+    role INTEGER NOT NULL DEFAULT 0,
+    link_user_id UUID NULL REFERENCES users(uuid) ON DELETE CASCADE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    used_at TIMESTAMP WITH TIME ZONE NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_oidc_login_states_expires_at ON oidc_login_states(expires_at);
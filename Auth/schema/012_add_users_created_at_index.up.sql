@@ -0,0 +1,2 @@
+-- Постраничный обход аккаунтов по времени создания (сверка в Gateway).
+CREATE INDEX IF NOT EXISTS idx_users_created_at ON users(created_at, uuid);
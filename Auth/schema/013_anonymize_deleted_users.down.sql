@@ -0,0 +1,2 @@
+-- Обезличивание необратимо: прежние email и хеши паролей не сохранены.
+SELECT 1;
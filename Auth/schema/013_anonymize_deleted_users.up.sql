@@ -0,0 +1,7 @@
+-- Удалённые аккаунты больше не хранят email и хеш пароля (см.
+-- AuthRepository.DeleteUser); обезличиваем удалённые до этой миграции.
+UPDATE users
+SET email = 'deleted-' || uuid || '@deleted.invalid',
+    password = ''
+WHERE deleted_at IS NOT NULL
+  AND email <> 'deleted-' || uuid || '@deleted.invalid';
@@ -0,0 +1,18 @@
+-- API-ключи компаний для интеграций (ATS, выгрузка вакансий). Ключ действует
+-- от имени владельца компании (id компании совпадает с uuid владельца), но
+-- только в пределах scopes. Хранится лишь SHA-256 ключа; prefix — начало
+-- ключа, по которому владелец узнаёт его в списке.
+CREATE TABLE IF NOT EXISTS api_keys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    company_id UUID NOT NULL REFERENCES users(uuid) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(16) NOT NULL,
+    key_hash VARCHAR(64) NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE NULL,
+    last_used_at TIMESTAMP WITH TIME ZONE NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_company ON api_keys(company_id);
@@ -0,0 +1,9 @@
+-- Блокировка аккаунта администратором. Пока banned_at задан, Auth не выпускает
+-- аккаунту токены и не принимает уже выданные (ValidateToken), ключи
+-- интеграций его компании тоже не действуют.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS banned_at TIMESTAMP WITH TIME ZONE NULL,
+    ADD COLUMN IF NOT EXISTS banned_by UUID NULL,
+    ADD COLUMN IF NOT EXISTS ban_reason TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_users_banned_at ON users(banned_at) WHERE banned_at IS NOT NULL;
@@ -0,0 +1,39 @@
+-- Журнал аудита бизнес-действий: кто (actor_id, actor_role), что (action)
+-- над чем (resource_type, resource_id) сделал, что изменилось (changes —
+-- {"поле": {"before": ..., "after": ...}}), откуда (ip) и когда.
+-- company_id — компания, к которой относится действие (пусто — ни к какой):
+-- по нему владелец компании видит свой журнал.
+--
+-- Журнал только дополняется: UPDATE, DELETE и TRUNCATE запрещены триггерами.
+-- Внешних ключей нет — записи переживают удаление аккаунтов.
+CREATE TABLE IF NOT EXISTS audit_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    actor_id VARCHAR(255) NOT NULL DEFAULT '',
+    actor_role VARCHAR(50) NOT NULL DEFAULT '',
+    action VARCHAR(100) NOT NULL,
+    resource_type VARCHAR(50) NOT NULL,
+    resource_id VARCHAR(255) NOT NULL DEFAULT '',
+    company_id VARCHAR(255) NOT NULL DEFAULT '',
+    changes JSONB NOT NULL DEFAULT '{}',
+    ip VARCHAR(64) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_created_at ON audit_events(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_events_company ON audit_events(company_id, created_at DESC) WHERE company_id <> '';
+CREATE INDEX IF NOT EXISTS idx_audit_events_actor ON audit_events(actor_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_events_resource ON audit_events(resource_type, resource_id, created_at DESC);
+
+CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_no_update_delete
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
+
+CREATE TRIGGER audit_events_no_truncate
+    BEFORE TRUNCATE ON audit_events
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_events_append_only();
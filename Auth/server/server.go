@@ -9,6 +9,7 @@ import (
 	authv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/auth/v1"
 	"github.com/studjobs/hh_for_students/auth/internal/handlers"
 	"github.com/studjobs/hh_for_students/auth/internal/metrics"
+	"github.com/studjobs/hh_for_students/auth/internal/tracing"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/health"
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
@@ -21,10 +22,12 @@ type Server struct {
 	healthServer *health.Server
 }
 
-func New(port string, authHandlers *handlers.AuthHandlers) *Server {
-	grpcServer := grpc.NewServer(
-		grpc.ChainUnaryInterceptor(metrics.UnaryInterceptor(), loggingInterceptor),
-	)
+func New(port string, opts []grpc.ServerOption, authHandlers *handlers.AuthHandlers) *Server {
+	// Метрики — первыми, чтобы учитывать и отклонённые mTLS-политикой вызовы.
+	grpcServer := grpc.NewServer(append([]grpc.ServerOption{
+		tracing.ServerOption(),
+		grpc.ChainUnaryInterceptor(metrics.UnaryInterceptor(), loggingInterceptor, handlers.ClientInfoInterceptor),
+	}, opts...)...)
 
 	// Регистрация сервисов
 	authv1.RegisterAuthServiceServer(grpcServer, authHandlers)
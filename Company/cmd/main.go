@@ -1,12 +1,15 @@
 package main
 
 import (
+	"context"
 	"github.com/joho/godotenv"
 	"github.com/spf13/viper"
 	"github.com/studjobs/hh_for_students/company/internal/handlers"
 	"github.com/studjobs/hh_for_students/company/internal/metrics"
+	"github.com/studjobs/hh_for_students/company/internal/mtls"
 	"github.com/studjobs/hh_for_students/company/internal/repository"
 	"github.com/studjobs/hh_for_students/company/internal/service"
+	"github.com/studjobs/hh_for_students/company/internal/tracing"
 	"github.com/studjobs/hh_for_students/company/server"
 	"log"
 	"os"
@@ -24,6 +27,14 @@ func main() {
 		log.Printf("warning: error loading .env file: %s", err.Error())
 	}
 
+	// Трассировка — до клиентов и сервера: их инструментирование берёт
+	// глобальный TracerProvider при создании.
+	shutdownTracing, err := tracing.Init(context.Background(), mtls.Company)
+	if err != nil {
+		log.Fatalf("failed to initialize tracing: %s", err.Error())
+	}
+	defer shutdownTracing()
+
 	dbPassword := os.Getenv("DB_PASS")
 	if dbPassword == "" {
 		log.Fatal("DB_PASS environment variable is required")
@@ -60,7 +71,11 @@ func main() {
 	log.Printf("Starting Company Service on gRPC port: %s", grpcPort)
 
 	// Запуск gRPC сервера
-	grpcServer := server.New(grpcPort, companyHandlers)
+	tlsOpts, err := mtls.ServerOptions(mtls.ConfigFromEnv(), server.Policy)
+	if err != nil {
+		log.Fatalf("Failed to configure mTLS: %v", err)
+	}
+	grpcServer := server.New(grpcPort, tlsOpts, companyHandlers)
 
 	// Graceful shutdown
 	go func() {
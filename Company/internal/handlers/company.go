@@ -87,6 +87,30 @@ func (h *CompanyHandlers) DeleteCompany(ctx context.Context, req *companyv1.Dele
 	return &commonv1.Empty{}, nil
 }
 
+// VerifyCompany — отметка модерации; вызывает Gateway от имени администратора.
+func (h *CompanyHandlers) VerifyCompany(ctx context.Context, req *companyv1.VerifyCompanyRequest) (*companyv1.Company, error) {
+	log.Printf("Handlers: VerifyCompany request received for ID: %s, verified: %t", req.Id, req.Verified)
+
+	if req.Id == "" {
+		log.Printf("Handlers: VerifyCompany failed - id is required")
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+
+	company, err := h.service.Company.VerifyCompany(ctx, req.Id, req.Verified)
+	if err != nil {
+		log.Printf("Handlers: VerifyCompany failed for ID %s: %v", req.Id, err)
+		switch err {
+		case service.ErrCompanyNotFound:
+			return nil, status.Error(codes.NotFound, err.Error())
+		default:
+			return nil, status.Error(codes.Internal, "failed to verify company")
+		}
+	}
+
+	log.Printf("Handlers: VerifyCompany completed successfully for ID: %s", company.Id)
+	return company, nil
+}
+
 func (h *CompanyHandlers) GetCompany(ctx context.Context, req *companyv1.GetCompanyRequest) (*companyv1.Company, error) {
 	log.Printf("Handlers: GetCompany request received for ID: %s", req.Id)
 
@@ -11,6 +11,7 @@ import (
 	"github.com/Masterminds/squirrel"
 	commonv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/common/v1"
 	companyv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/company/v1"
+	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 )
 
@@ -133,7 +134,7 @@ func (r *CompanyRepository) CreateCompany(ctx context.Context, company *companyv
 			company.CleanupVacanciesAfterDays,
 			company.CleanupTasksAfterDays,
 		).
-		Suffix("RETURNING id, name, description, city, site, company_type, logo_id, cleanup_vacancies_after_days, cleanup_tasks_after_days, created_at").
+		Suffix("RETURNING id, name, description, city, site, company_type, logo_id, cleanup_vacancies_after_days, cleanup_tasks_after_days, created_at, verified_at").
 		ToSql()
 	if err != nil {
 		log.Printf("Repository: Failed to build create company query: %v", err)
@@ -191,7 +192,7 @@ func (r *CompanyRepository) UpdateCompany(ctx context.Context, id string, compan
 	}
 
 	query, args, err := updateBuilder.
-		Suffix("RETURNING id, name, description, city, site, company_type, logo_id, cleanup_vacancies_after_days, cleanup_tasks_after_days, created_at").
+		Suffix("RETURNING id, name, description, city, site, company_type, logo_id, cleanup_vacancies_after_days, cleanup_tasks_after_days, created_at, verified_at").
 		ToSql()
 	if err != nil {
 		log.Printf("Repository: Failed to build update company query: %v", err)
@@ -239,6 +240,40 @@ func (r *CompanyRepository) DeleteCompany(ctx context.Context, id string) error
 	return nil
 }
 
+// SetVerified ставит или снимает отметку о проверке компании администратором.
+// Повторная отметка сохраняет время первой проверки.
+func (r *CompanyRepository) SetVerified(ctx context.Context, id string, verified bool) (*companyv1.Company, error) {
+	log.Printf("Repository: Setting company %s verified: %t", id, verified)
+
+	verifiedAt := squirrel.Expr("NULL")
+	if verified {
+		verifiedAt = squirrel.Expr("COALESCE(verified_at, NOW())")
+	}
+
+	query, args, err := r.sb.
+		Update(COMPANY_TABLE).
+		Set("verified_at", verifiedAt).
+		Set("updated_at", squirrel.Expr("NOW()")).
+		Where(squirrel.Eq{"id": id}).
+		Where("deleted_at IS NULL").
+		Suffix("RETURNING id, name, description, city, site, company_type, logo_id, cleanup_vacancies_after_days, cleanup_tasks_after_days, created_at, verified_at").
+		ToSql()
+	if err != nil {
+		log.Printf("Repository: Failed to build verify company query: %v", err)
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	company, err := scanCompanyRow(r.db.QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrCompanyNotFound
+		}
+		log.Printf("Repository: Failed to verify company with ID %s: %v", id, err)
+		return nil, fmt.Errorf("failed to verify company: %w", err)
+	}
+	return company, nil
+}
+
 // Вспомогательные приватные функции
 
 // scanCompanyRow сканирует строку из БД в Company объект
@@ -249,6 +284,7 @@ func scanCompanyRow(scanner interface {
 	var description, city, site, companyType, logoId sql.NullString
 	var cleanupVac, cleanupTasks int32
 	var createdAt time.Time
+	var verifiedAt *time.Time
 
 	err := scanner.Scan(
 		&company.Id,
@@ -261,6 +297,7 @@ func scanCompanyRow(scanner interface {
 		&cleanupVac,
 		&cleanupTasks,
 		&createdAt,
+		&verifiedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -273,6 +310,9 @@ func scanCompanyRow(scanner interface {
 	company.LogoId = nullStringToString(logoId)
 	company.CleanupVacanciesAfterDays = cleanupVac
 	company.CleanupTasksAfterDays = cleanupTasks
+	if verifiedAt != nil {
+		company.VerifiedAt = verifiedAt.Format(time.RFC3339)
+	}
 
 	// Обрабатываем company_type
 	if companyType.Valid {
@@ -301,7 +341,7 @@ func nullStringToString(nullStr sql.NullString) string {
 // buildCompanyQueryBuilder создает базовый query builder для компаний
 func (r *CompanyRepository) buildCompanyQueryBuilder(city, companyType, search string) squirrel.SelectBuilder {
 	queryBuilder := r.sb.
-		Select("id", "name", "description", "city", "site", "company_type", "logo_id", "cleanup_vacancies_after_days", "cleanup_tasks_after_days", "created_at").
+		Select("id", "name", "description", "city", "site", "company_type", "logo_id", "cleanup_vacancies_after_days", "cleanup_tasks_after_days", "created_at", "verified_at").
 		From(COMPANY_TABLE).
 		Where("deleted_at IS NULL")
 
@@ -12,6 +12,7 @@ type Company interface {
 	CreateCompany(ctx context.Context, company *companyv1.Company) (*companyv1.Company, error)
 	UpdateCompany(ctx context.Context, id string, company *companyv1.Company) (*companyv1.Company, error)
 	DeleteCompany(ctx context.Context, id string) error
+	SetVerified(ctx context.Context, id string, verified bool) (*companyv1.Company, error)
 }
 
 type Membership interface {
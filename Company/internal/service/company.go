@@ -122,3 +122,21 @@ func (s *CompanyService) DeleteCompany(ctx context.Context, id string) error {
 	log.Printf("Service: Successfully deleted company with ID: %s", id)
 	return nil
 }
+
+// VerifyCompany ставит или снимает отметку модерации (действие администратора).
+func (s *CompanyService) VerifyCompany(ctx context.Context, id string, verified bool) (*companyv1.Company, error) {
+	log.Printf("Service: Setting company %s verified: %t", id, verified)
+
+	company, err := s.repo.SetVerified(ctx, id, verified)
+	if err != nil {
+		if errors.Is(err, repository.ErrCompanyNotFound) {
+			log.Printf("Service: Company not found for verification with ID: %s", id)
+			return nil, ErrCompanyNotFound
+		}
+		log.Printf("Service: Failed to verify company with ID %s: %v", id, err)
+		return nil, err
+	}
+
+	log.Printf("Service: Successfully set company %s verified: %t", id, verified)
+	return company, nil
+}
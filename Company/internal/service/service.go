@@ -15,6 +15,7 @@ type CompanyInterface interface {
 	ListCompanies(ctx context.Context, city, companyType, query string, page, limit int32) (*companyv1.CompanyList, error)
 	UpdateCompany(ctx context.Context, id string, company *companyv1.Company) (*companyv1.Company, error)
 	DeleteCompany(ctx context.Context, id string) error
+	VerifyCompany(ctx context.Context, id string, verified bool) (*companyv1.Company, error)
 }
 
 type Service struct {
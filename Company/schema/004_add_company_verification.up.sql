@@ -0,0 +1,3 @@
+-- Отметка модерации: компанию проверил администратор платформы.
+ALTER TABLE companies
+    ADD COLUMN verified_at TIMESTAMP WITH TIME ZONE NULL;
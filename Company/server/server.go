@@ -7,6 +7,7 @@ import (
 
 	companyv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/company/v1"
 	"github.com/studjobs/hh_for_students/company/internal/metrics"
+	"github.com/studjobs/hh_for_students/company/internal/tracing"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/health"
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
@@ -19,8 +20,9 @@ type Server struct {
 	healthServer *health.Server
 }
 
-func New(port string, companyService companyv1.CompanyServiceServer) *Server {
-	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(metrics.UnaryInterceptor()))
+func New(port string, opts []grpc.ServerOption, companyService companyv1.CompanyServiceServer) *Server {
+	// Метрики — первыми, чтобы учитывать и отклонённые mTLS-политикой вызовы.
+	grpcServer := grpc.NewServer(append([]grpc.ServerOption{tracing.ServerOption(), grpc.ChainUnaryInterceptor(metrics.UnaryInterceptor())}, opts...)...)
 
 	// Регистрация сервисов
 	companyv1.RegisterCompanyServiceServer(grpcServer, companyService)
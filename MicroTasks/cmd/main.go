@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 	"os/signal"
@@ -13,10 +14,12 @@ import (
 	"github.com/studjobs/hh_for_students/microtasks/internal/achievementclient"
 	"github.com/studjobs/hh_for_students/microtasks/internal/handlers"
 	"github.com/studjobs/hh_for_students/microtasks/internal/metrics"
+	"github.com/studjobs/hh_for_students/microtasks/internal/mtls"
 	"github.com/studjobs/hh_for_students/microtasks/internal/repository"
 	"github.com/studjobs/hh_for_students/microtasks/internal/searchclient"
 	"github.com/studjobs/hh_for_students/microtasks/internal/service"
 	"github.com/studjobs/hh_for_students/microtasks/internal/storage"
+	"github.com/studjobs/hh_for_students/microtasks/internal/tracing"
 	"github.com/studjobs/hh_for_students/microtasks/internal/usersclient"
 	"github.com/studjobs/hh_for_students/microtasks/server"
 )
@@ -30,6 +33,14 @@ func main() {
 		log.Printf("warning: error loading .env file: %s", err.Error())
 	}
 
+	// Трассировка — до клиентов и сервера: их инструментирование берёт
+	// глобальный TracerProvider при создании.
+	shutdownTracing, err := tracing.Init(context.Background(), mtls.MicroTasks)
+	if err != nil {
+		log.Fatalf("failed to initialize tracing: %s", err.Error())
+	}
+	defer shutdownTracing()
+
 	dbPassword := os.Getenv("DB_PASS")
 	if dbPassword == "" {
 		log.Fatal("DB_PASS environment variable is required")
@@ -104,7 +115,11 @@ func main() {
 	metrics.ServeMetrics(getEnv("METRICS_ADDR", ":9099"))
 
 	log.Printf("Starting MicroTasks Service on gRPC port: %s", grpcPort)
-	grpcServer := server.New(grpcPort, handler)
+	tlsOpts, err := mtls.ServerOptions(mtls.ConfigFromEnv(), server.Policy)
+	if err != nil {
+		log.Fatalf("Failed to configure mTLS: %v", err)
+	}
+	grpcServer := server.New(grpcPort, tlsOpts, handler)
 
 	go func() {
 		if err := grpcServer.Run(); err != nil {
@@ -9,8 +9,9 @@ import (
 	"time"
 
 	achievementv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/achievement/v1"
+	"github.com/studjobs/hh_for_students/microtasks/internal/mtls"
+	"github.com/studjobs/hh_for_students/microtasks/internal/tracing"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 const callTimeout = 5 * time.Second
@@ -25,7 +26,12 @@ func New(addr string) *Client {
 		log.Printf("achievementclient: ACHIEVEMENTS_GRPC_ADDR is empty, autopopulate disabled")
 		return &Client{}
 	}
-	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	creds, err := mtls.DialOption(mtls.ConfigFromEnv(), addr)
+	if err != nil {
+		log.Printf("achievementclient: mTLS config: %v (autopopulate disabled)", err)
+		return &Client{}
+	}
+	conn, err := grpc.NewClient(addr, creds, tracing.DialOption())
 	if err != nil {
 		log.Printf("achievementclient: dial %s failed: %v (autopopulate disabled)", addr, err)
 		return &Client{}
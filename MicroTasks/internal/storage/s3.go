@@ -10,6 +10,7 @@ import (
 
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/studjobs/hh_for_students/microtasks/internal/tracing"
 )
 
 // S3Config — параметры подключения к MinIO/S3.
@@ -36,7 +37,7 @@ func NewInternalClient(cfg S3Config) (*minio.Client, error) {
 	cli, err := minio.New(cfg.Endpoint, &minio.Options{
 		Creds:     credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
 		Secure:    cfg.UseSSL,
-		Transport: transport,
+		Transport: tracing.Transport(transport),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("create minio client: %w", err)
@@ -0,0 +1,80 @@
+// Package tracing — распределённая трассировка OpenTelemetry: спаны сервиса
+// уходят по OTLP в коллектор, контекст трассы (W3C traceparent) передаётся
+// между сервисами в метаданных gRPC. Так медленный запрос раскладывается по
+// сервисам: gateway, его gRPC-вызовы, SQL, Redis, S3, Elasticsearch.
+package tracing
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc/filters"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"google.golang.org/grpc"
+)
+
+// Init настраивает глобальные TracerProvider и propagator. Коллектор —
+// стандартные переменные OTLP: OTEL_EXPORTER_OTLP_ENDPOINT (например
+// http://otel-collector:4317), сэмплирование — OTEL_TRACES_SAMPLER(_ARG),
+// имя сервиса можно переопределить OTEL_SERVICE_NAME. Без endpoint спаны не
+// экспортируются, но контекст трассы всё равно передаётся дальше — цепочка
+// не рвётся на сервисе без коллектора.
+//
+// Возвращает shutdown: дописывает буфер спанов, вызывать при остановке.
+func Init(ctx context.Context, service string) (func(), error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{},
+	))
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+		log.Printf("⚠ tracing export disabled (OTEL_EXPORTER_OTLP_ENDPOINT not set)")
+		return func() {}, nil
+	}
+
+	exporter, err := otlptracegrpc.New(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName(service)),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	log.Printf("✓ tracing enabled (service: %s)", service)
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := provider.Shutdown(ctx); err != nil {
+			log.Printf("tracing shutdown failed: %v", err)
+		}
+	}, nil
+}
+
+// ServerOption — спан на каждый входящий gRPC-вызов, родитель — контекст
+// трассы из метаданных вызова. Health-проверки не трассируются.
+func ServerOption() grpc.ServerOption {
+	return grpc.StatsHandler(otelgrpc.NewServerHandler(
+		otelgrpc.WithFilter(filters.Not(filters.HealthCheck())),
+	))
+}
+
+// DialOption — спан на каждый исходящий gRPC-вызов; контекст трассы уходит
+// в метаданных.
+func DialOption() grpc.DialOption {
+	return grpc.WithStatsHandler(otelgrpc.NewClientHandler())
+}
@@ -8,8 +8,9 @@ import (
 	"time"
 
 	usersv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/users/v1"
+	"github.com/studjobs/hh_for_students/microtasks/internal/mtls"
+	"github.com/studjobs/hh_for_students/microtasks/internal/tracing"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 const callTimeout = 5 * time.Second
@@ -24,7 +25,12 @@ func New(addr string) *Client {
 		log.Printf("usersclient: USERS_GRPC_ADDR is empty, verified-skills propagation disabled")
 		return &Client{}
 	}
-	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	creds, err := mtls.DialOption(mtls.ConfigFromEnv(), addr)
+	if err != nil {
+		log.Printf("usersclient: mTLS config: %v", err)
+		return &Client{}
+	}
+	conn, err := grpc.NewClient(addr, creds, tracing.DialOption())
 	if err != nil {
 		log.Printf("usersclient: dial %s failed: %v", addr, err)
 		return &Client{}
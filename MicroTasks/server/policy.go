@@ -0,0 +1,15 @@
+package server
+
+import (
+	microtaskv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/microtask/v1"
+	"github.com/studjobs/hh_for_students/microtasks/internal/mtls"
+)
+
+// Policy — кто может вызывать методы сервиса при включённом mTLS.
+var Policy = mtls.Policy{
+	Default: []string{mtls.Gateway},
+	Methods: map[string][]string{
+		// Переиндексация Search.
+		microtaskv1.MicroTaskService_List_FullMethodName: {mtls.Gateway, mtls.Search},
+	},
+}
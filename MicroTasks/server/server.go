@@ -7,6 +7,7 @@ import (
 
 	microtaskv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/microtask/v1"
 	"github.com/studjobs/hh_for_students/microtasks/internal/metrics"
+	"github.com/studjobs/hh_for_students/microtasks/internal/tracing"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/health"
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
@@ -19,8 +20,9 @@ type Server struct {
 	healthServer *health.Server
 }
 
-func New(port string, srv microtaskv1.MicroTaskServiceServer) *Server {
-	gs := grpc.NewServer(grpc.UnaryInterceptor(metrics.UnaryInterceptor()))
+func New(port string, opts []grpc.ServerOption, srv microtaskv1.MicroTaskServiceServer) *Server {
+	// Метрики — первыми, чтобы учитывать и отклонённые mTLS-политикой вызовы.
+	gs := grpc.NewServer(append([]grpc.ServerOption{tracing.ServerOption(), grpc.ChainUnaryInterceptor(metrics.UnaryInterceptor())}, opts...)...)
 	microtaskv1.RegisterMicroTaskServiceServer(gs, srv)
 
 	hs := health.NewServer()
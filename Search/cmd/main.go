@@ -16,8 +16,10 @@ import (
 	"github.com/studjobs/hh_for_students/search/internal/handlers"
 	"github.com/studjobs/hh_for_students/search/internal/indexer"
 	"github.com/studjobs/hh_for_students/search/internal/metrics"
+	"github.com/studjobs/hh_for_students/search/internal/mtls"
 	"github.com/studjobs/hh_for_students/search/internal/reindexer"
 	"github.com/studjobs/hh_for_students/search/internal/searcher"
+	"github.com/studjobs/hh_for_students/search/internal/tracing"
 	"github.com/studjobs/hh_for_students/search/server"
 )
 
@@ -30,6 +32,14 @@ func main() {
 		log.Printf("warning: error loading .env file: %s", err.Error())
 	}
 
+	// Трассировка — до клиентов и сервера: их инструментирование берёт
+	// глобальный TracerProvider при создании.
+	shutdownTracing, err := tracing.Init(context.Background(), mtls.Search)
+	if err != nil {
+		log.Fatalf("failed to initialize tracing: %s", err.Error())
+	}
+	defer shutdownTracing()
+
 	esURL := getEnv("ELASTICSEARCH_URL", viper.GetString("elasticsearch.url"))
 	if esURL == "" {
 		esURL = "http://elasticsearch:9200"
@@ -68,7 +78,11 @@ func main() {
 	handler := handlers.New(srch, idx, rx)
 
 	log.Printf("Starting Search Service on gRPC port: %s (es=%s, users=%s, vacancy=%s, microtasks=%s)", grpcPort, esURL, usersAddr, vacancyAddr, microtasksAddr)
-	grpcServer := server.New(grpcPort, handler)
+	tlsOpts, err := mtls.ServerOptions(mtls.ConfigFromEnv(), server.Policy)
+	if err != nil {
+		log.Fatalf("Failed to configure mTLS: %v", err)
+	}
+	grpcServer := server.New(grpcPort, tlsOpts, handler)
 
 	go func() {
 		if err := grpcServer.Run(); err != nil {
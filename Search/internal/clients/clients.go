@@ -7,8 +7,9 @@ import (
 	microtaskv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/microtask/v1"
 	usersv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/users/v1"
 	vacancyv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/vacancy/v1"
+	"github.com/studjobs/hh_for_students/search/internal/mtls"
+	"github.com/studjobs/hh_for_students/search/internal/tracing"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 type Clients struct {
@@ -24,11 +25,13 @@ type Clients struct {
 // New создаёт upstream-клиенты. microtasksAddr — необязательный (если пуст, MicroTasks-клиент = nil,
 // reindex для микрозадач пропускается).
 func New(usersAddr, vacancyAddr, microtasksAddr string) (*Clients, error) {
-	uc, err := grpc.NewClient(usersAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	tlsCfg := mtls.ConfigFromEnv()
+
+	uc, err := dial(tlsCfg, usersAddr)
 	if err != nil {
 		return nil, fmt.Errorf("clients: dial users: %w", err)
 	}
-	vc, err := grpc.NewClient(vacancyAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	vc, err := dial(tlsCfg, vacancyAddr)
 	if err != nil {
 		_ = uc.Close()
 		return nil, fmt.Errorf("clients: dial vacancy: %w", err)
@@ -40,7 +43,7 @@ func New(usersAddr, vacancyAddr, microtasksAddr string) (*Clients, error) {
 		vacancyConn: vc,
 	}
 	if microtasksAddr != "" {
-		mc, err := grpc.NewClient(microtasksAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		mc, err := dial(tlsCfg, microtasksAddr)
 		if err != nil {
 			log.Printf("clients: dial microtasks (%s) failed: %v (microtask reindex disabled)", microtasksAddr, err)
 		} else {
@@ -51,6 +54,14 @@ func New(usersAddr, vacancyAddr, microtasksAddr string) (*Clients, error) {
 	return c, nil
 }
 
+func dial(tlsCfg mtls.Config, addr string) (*grpc.ClientConn, error) {
+	creds, err := mtls.DialOption(tlsCfg, addr)
+	if err != nil {
+		return nil, err
+	}
+	return grpc.NewClient(addr, creds, tracing.DialOption())
+}
+
 func (c *Clients) Close() {
 	if c.usersConn != nil {
 		_ = c.usersConn.Close()
@@ -24,7 +24,12 @@ type Client struct {
 }
 
 func New(url string) (*Client, error) {
-	cfg := elasticsearch.Config{Addresses: []string{url}}
+	cfg := elasticsearch.Config{
+		Addresses: []string{url},
+		// Спаны запросов к ES; nil — глобальный TracerProvider (tracing.Init).
+		// Тела поисковых запросов в спаны не пишем: в них пользовательский ввод.
+		Instrumentation: elasticsearch.NewOpenTelemetryInstrumentation(nil, false),
+	}
 	es, err := elasticsearch.NewClient(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("elasticsearch: new client: %w", err)
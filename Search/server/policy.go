@@ -0,0 +1,24 @@
+package server
+
+import (
+	searchv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/search/v1"
+	"github.com/studjobs/hh_for_students/search/internal/mtls"
+)
+
+// Policy — кто может вызывать методы сервиса при включённом mTLS. Поиск —
+// Gateway; индекс меняет только сервис-владелец документа.
+var Policy = mtls.Policy{
+	Default: []string{mtls.Gateway},
+	Methods: map[string][]string{
+		searchv1.SearchService_IndexProfile_FullMethodName: {mtls.Users},
+		// Gateway удаляет профиль из индекса при удалении аккаунта.
+		searchv1.SearchService_DeleteProfile_FullMethodName:   {mtls.Users, mtls.Gateway},
+		searchv1.SearchService_IndexVacancy_FullMethodName:    {mtls.Vacancy},
+		searchv1.SearchService_DeleteVacancy_FullMethodName:   {mtls.Vacancy},
+		searchv1.SearchService_IndexMicroTask_FullMethodName:  {mtls.MicroTasks},
+		searchv1.SearchService_DeleteMicroTask_FullMethodName: {mtls.MicroTasks},
+		// Пересоздание индексов — вручную (make reindex) или администратором
+		// через Gateway (POST /api/v1/admin/search/reindex).
+		searchv1.SearchService_Reindex_FullMethodName: {mtls.Ops, mtls.Gateway},
+	},
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 	"os/signal"
@@ -11,8 +12,10 @@ import (
 
 	"github.com/studjobs/hh_for_students/skills/internal/handlers"
 	"github.com/studjobs/hh_for_students/skills/internal/metrics"
+	"github.com/studjobs/hh_for_students/skills/internal/mtls"
 	"github.com/studjobs/hh_for_students/skills/internal/repository"
 	"github.com/studjobs/hh_for_students/skills/internal/service"
+	"github.com/studjobs/hh_for_students/skills/internal/tracing"
 	"github.com/studjobs/hh_for_students/skills/server"
 )
 
@@ -25,6 +28,14 @@ func main() {
 		log.Printf("warning: error loading .env file: %s", err.Error())
 	}
 
+	// Трассировка — до клиентов и сервера: их инструментирование берёт
+	// глобальный TracerProvider при создании.
+	shutdownTracing, err := tracing.Init(context.Background(), mtls.Skills)
+	if err != nil {
+		log.Fatalf("failed to initialize tracing: %s", err.Error())
+	}
+	defer shutdownTracing()
+
 	dbPassword := os.Getenv("DB_PASS")
 	if dbPassword == "" {
 		log.Fatal("DB_PASS environment variable is required")
@@ -56,7 +67,11 @@ func main() {
 	metrics.ServeMetrics(getEnv("METRICS_ADDR", ":9097"))
 
 	log.Printf("Starting Skills Service on gRPC port: %s", grpcPort)
-	grpcServer := server.New(grpcPort, handler)
+	tlsOpts, err := mtls.ServerOptions(mtls.ConfigFromEnv(), server.Policy)
+	if err != nil {
+		log.Fatalf("Failed to configure mTLS: %v", err)
+	}
+	grpcServer := server.New(grpcPort, tlsOpts, handler)
 
 	go func() {
 		if err := grpcServer.Run(); err != nil {
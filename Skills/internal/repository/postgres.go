@@ -13,6 +13,7 @@ import (
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/jackc/pgx/v4/stdlib"
 	"github.com/sirupsen/logrus"
+	"github.com/studjobs/hh_for_students/skills/internal/tracing"
 )
 
 type Config struct {
@@ -28,7 +29,13 @@ func NewPostgres(cfg Config) (*pgxpool.Pool, error) {
 	strCfg := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
 		cfg.Host, cfg.Port, cfg.Username, cfg.Password, cfg.DBName, cfg.SSLMode)
 
-	dbPool, err := pgxpool.Connect(context.Background(), strCfg)
+	poolCfg, err := pgxpool.ParseConfig(strCfg)
+	if err != nil {
+		return nil, fmt.Errorf("database config error: %w", err)
+	}
+	// Спаны SQL-запросов в трассе вызова (см. tracing.PgxLogger).
+	poolCfg.ConnConfig.Logger = tracing.PgxLogger{}
+	dbPool, err := pgxpool.ConnectConfig(context.Background(), poolCfg)
 	if err != nil {
 		return nil, fmt.Errorf("database connection error: %w", err)
 	}
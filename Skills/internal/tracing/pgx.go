@@ -0,0 +1,57 @@
+package tracing
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// PgxLogger — спаны SQL-запросов pgx v4. Хуков трассировки в v4 нет, но
+// логгер соединения вызывается после каждого запроса с его контекстом, SQL и
+// длительностью — спан строится задним числом. Аргументы запросов в спан не
+// пишутся: там персональные данные.
+//
+// Ставится в ConnConfig.Logger (уровень Info — по умолчанию у ParseConfig).
+// Запросы вне трассы (миграции, фоновые задачи) спанов не создают.
+type PgxLogger struct{}
+
+func (PgxLogger) Log(ctx context.Context, level pgx.LogLevel, msg string, data map[string]interface{}) {
+	sql, ok := data["sql"].(string)
+	if !ok || !trace.SpanContextFromContext(ctx).IsValid() {
+		return
+	}
+	end := time.Now()
+	start := end
+	if d, ok := data["time"].(time.Duration); ok {
+		start = end.Add(-d)
+	}
+	operation := msg
+	if fields := strings.Fields(sql); len(fields) > 0 {
+		operation = strings.ToUpper(fields[0])
+	}
+
+	_, span := otel.Tracer("pgx").Start(ctx, "postgres "+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithTimestamp(start),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBQueryText(sql),
+			semconv.DBOperationName(operation),
+		),
+	)
+	if err, ok := data["err"].(error); ok {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	if rows, ok := data["rowCount"].(int); ok {
+		span.SetAttributes(attribute.Int("db.response.rows", rows))
+	}
+	span.End(trace.WithTimestamp(end))
+}
@@ -0,0 +1,6 @@
+package server
+
+import "github.com/studjobs/hh_for_students/skills/internal/mtls"
+
+// Policy — кто может вызывать методы сервиса при включённом mTLS.
+var Policy = mtls.Policy{Default: []string{mtls.Gateway}}
@@ -1,13 +1,16 @@
 package main
 
 import (
+	"context"
 	"github.com/joho/godotenv"
 	"github.com/spf13/viper"
 	"github.com/studjobs/hh_for_students/users/internal/handlers"
 	"github.com/studjobs/hh_for_students/users/internal/metrics"
+	"github.com/studjobs/hh_for_students/users/internal/mtls"
 	"github.com/studjobs/hh_for_students/users/internal/repository"
 	"github.com/studjobs/hh_for_students/users/internal/searchclient"
 	"github.com/studjobs/hh_for_students/users/internal/service"
+	"github.com/studjobs/hh_for_students/users/internal/tracing"
 	"github.com/studjobs/hh_for_students/users/server"
 	"log"
 	"os"
@@ -25,6 +28,14 @@ func main() {
 		log.Printf("warning: error loading .env file: %s", err.Error())
 	}
 
+	// Трассировка — до клиентов и сервера: их инструментирование берёт
+	// глобальный TracerProvider при создании.
+	shutdownTracing, err := tracing.Init(context.Background(), mtls.Users)
+	if err != nil {
+		log.Fatalf("failed to initialize tracing: %s", err.Error())
+	}
+	defer shutdownTracing()
+
 	dbPassword := os.Getenv("DB_PASS")
 	if dbPassword == "" {
 		log.Fatal("DB_PASS environment variable is required")
@@ -64,7 +75,11 @@ func main() {
 	log.Printf("Starting Users Service on gRPC port: %s", grpcPort)
 
 	// Запуск gRPC сервера
-	grpcServer := server.New(grpcPort, userHandlers, chatHandler)
+	tlsOpts, err := mtls.ServerOptions(mtls.ConfigFromEnv(), server.Policy)
+	if err != nil {
+		log.Fatalf("Failed to configure mTLS: %v", err)
+	}
+	grpcServer := server.New(grpcPort, tlsOpts, userHandlers, chatHandler)
 
 	// Graceful shutdown
 	go func() {
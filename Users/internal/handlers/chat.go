@@ -77,6 +77,24 @@ func (h *ChatHandler) HideThread(ctx context.Context, req *chatv1.HideThreadRequ
 	return &commonv1.Empty{}, nil
 }
 
+// erasedMessageBody — текст, которым заменяются сообщения удалённого аккаунта.
+const erasedMessageBody = "Сообщение удалено"
+
+// EraseUserMessages — шаг удаления аккаунта (Gateway): обезличивает все
+// сообщения пользователя.
+func (h *ChatHandler) EraseUserMessages(ctx context.Context, req *chatv1.EraseUserMessagesRequest) (*chatv1.ErasedMessages, error) {
+	if req.GetUserId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id required")
+	}
+	n, err := h.repo.Chat.EraseUserMessages(ctx, req.GetUserId(), erasedMessageBody)
+	if err != nil {
+		log.Printf("ChatHandler: EraseUserMessages user=%s failed: %v", req.GetUserId(), err)
+		return nil, status.Error(codes.Internal, "failed to erase messages")
+	}
+	log.Printf("ChatHandler: erased %d messages of user %s", n, req.GetUserId())
+	return &chatv1.ErasedMessages{Count: int32(n)}, nil
+}
+
 func (h *ChatHandler) ListHiddenThreads(ctx context.Context, req *chatv1.ListHiddenThreadsRequest) (*chatv1.HiddenThreadList, error) {
 	if req.GetUserId() == "" {
 		return nil, status.Error(codes.InvalidArgument, "user_id required")
@@ -55,6 +55,24 @@ func (r *ChatRepository) HideThread(ctx context.Context, userID, threadID string
 	return err
 }
 
+// EraseUserMessages обезличивает переписку удалённого пользователя: текст
+// его сообщений заменяется на placeholder, скрытия тредов удаляются. Сами
+// сообщения остаются — тред принадлежит и собеседнику, и без них его
+// история развалится. Повторный вызов ничего не меняет.
+func (r *ChatRepository) EraseUserMessages(ctx context.Context, userID, placeholder string) (int64, error) {
+	tag, err := r.db.Exec(ctx, `
+UPDATE chat_messages
+SET body = $2, edited_at = NOW()
+WHERE from_user_id = $1 AND body <> $2`, userID, placeholder)
+	if err != nil {
+		return 0, fmt.Errorf("erase messages: %w", err)
+	}
+	if _, err := r.db.Exec(ctx, `DELETE FROM chat_thread_hides WHERE user_id = $1`, userID); err != nil {
+		return 0, fmt.Errorf("delete thread hides: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
 // IsHidden — true если тред скрыт для юзера.
 func (r *ChatRepository) IsHidden(ctx context.Context, userID, threadID string) (bool, error) {
 	var n int
@@ -24,6 +24,7 @@ type Chat interface {
 	EditMessage(ctx context.Context, id, fromUserID, body string) (*chatv1.Message, error)
 	HideThread(ctx context.Context, userID, threadID string) error
 	HiddenSet(ctx context.Context, userID string) (map[string]struct{}, error)
+	EraseUserMessages(ctx context.Context, userID, placeholder string) (int64, error)
 }
 
 type Repository struct {
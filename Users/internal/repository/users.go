@@ -489,14 +489,15 @@ WHERE id = $1 AND deleted_at IS NULL`
 	return r.GetProfile(ctx, userID)
 }
 
+// DeleteProfile удаляет профиль целиком: в нём только персональные данные,
+// хранить которые после удаления аккаунта незачем. Строка удаляется и
+// тогда, когда профиль был скрыт мягким удалением до этого.
 func (r *UsersRepository) DeleteProfile(ctx context.Context, id string) error {
 	log.Printf("Repository: Deleting profile with ID: %s", id)
 
 	query, args, err := r.sb.
-		Update(PROFILE_TABLE).
-		Set("deleted_at", squirrel.Expr("NOW()")).
+		Delete(PROFILE_TABLE).
 		Where(squirrel.Eq{"id": id}).
-		Where("deleted_at IS NULL").
 		ToSql()
 	if err != nil {
 		log.Printf("Repository: Failed to build delete profile query: %v", err)
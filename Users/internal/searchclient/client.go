@@ -11,8 +11,9 @@ import (
 
 	searchv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/search/v1"
 	usersv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/users/v1"
+	"github.com/studjobs/hh_for_students/users/internal/mtls"
+	"github.com/studjobs/hh_for_students/users/internal/tracing"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 const indexTimeout = 5 * time.Second
@@ -28,7 +29,12 @@ func New(addr string) *Client {
 		log.Printf("searchclient: SEARCH_GRPC_ADDR is empty, indexing disabled")
 		return &Client{}
 	}
-	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	creds, err := mtls.DialOption(mtls.ConfigFromEnv(), addr)
+	if err != nil {
+		log.Printf("searchclient: mTLS config: %v (indexing disabled)", err)
+		return &Client{}
+	}
+	conn, err := grpc.NewClient(addr, creds, tracing.DialOption())
 	if err != nil {
 		log.Printf("searchclient: dial %s failed: %v (indexing disabled)", addr, err)
 		return &Client{}
@@ -0,0 +1,2 @@
+-- Удалённые профили не восстановить.
+SELECT 1;
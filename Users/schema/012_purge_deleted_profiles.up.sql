@@ -0,0 +1,3 @@
+-- Профили удаляются целиком (см. UsersRepository.DeleteProfile); убираем
+-- оставшиеся от мягкого удаления.
+DELETE FROM profiles WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,20 @@
+package server
+
+import (
+	usersv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/users/v1"
+	"github.com/studjobs/hh_for_students/users/internal/mtls"
+)
+
+// Policy — кто может вызывать методы сервиса при включённом mTLS. Чат и
+// остальные методы профилей — только Gateway.
+var Policy = mtls.Policy{
+	Default: []string{mtls.Gateway},
+	Methods: map[string][]string{
+		// Achievements проверяет роль владельца перед выдачей навыков.
+		usersv1.UsersService_GetProfile_FullMethodName: {mtls.Gateway, mtls.Achievements},
+		// Verified-навыки выдаются только по результату проверки работы.
+		usersv1.UsersService_AddVerifiedSkills_FullMethodName: {mtls.Achievements, mtls.MicroTasks},
+		// Переиндексация Search.
+		usersv1.UsersService_GetAllProfiles_FullMethodName: {mtls.Gateway, mtls.Search},
+	},
+}
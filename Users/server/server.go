@@ -8,6 +8,7 @@ import (
 	chatv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/chat/v1"
 	usersv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/users/v1"
 	"github.com/studjobs/hh_for_students/users/internal/metrics"
+	"github.com/studjobs/hh_for_students/users/internal/tracing"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/health"
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
@@ -20,8 +21,9 @@ type Server struct {
 	healthServer *health.Server
 }
 
-func New(port string, usersService usersv1.UsersServiceServer, chatService chatv1.ChatServiceServer) *Server {
-	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(metrics.UnaryInterceptor()))
+func New(port string, opts []grpc.ServerOption, usersService usersv1.UsersServiceServer, chatService chatv1.ChatServiceServer) *Server {
+	// Метрики — первыми, чтобы учитывать и отклонённые mTLS-политикой вызовы.
+	grpcServer := grpc.NewServer(append([]grpc.ServerOption{tracing.ServerOption(), grpc.ChainUnaryInterceptor(metrics.UnaryInterceptor())}, opts...)...)
 
 	// Регистрация сервисов
 	usersv1.RegisterUsersServiceServer(grpcServer, usersService)
@@ -3,14 +3,17 @@ package main
 import (
 	"hh_for_students/vacancy-service/internal/handlers"
 	"hh_for_students/vacancy-service/internal/metrics"
+	"hh_for_students/vacancy-service/internal/mtls"
 	"hh_for_students/vacancy-service/internal/repository"
 	"hh_for_students/vacancy-service/internal/searchclient"
 	"hh_for_students/vacancy-service/internal/service"
+	"hh_for_students/vacancy-service/internal/tracing"
 	"hh_for_students/vacancy-service/server"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/viper"
 
+	"context"
 	"log"
 	"os"
 	"os/signal"
@@ -27,6 +30,14 @@ func main() {
 		log.Printf("warning: error loading .env file: %s", err.Error())
 	}
 
+	// Трассировка — до клиентов и сервера: их инструментирование берёт
+	// глобальный TracerProvider при создании.
+	shutdownTracing, err := tracing.Init(context.Background(), mtls.Vacancy)
+	if err != nil {
+		log.Fatalf("failed to initialize tracing: %s", err.Error())
+	}
+	defer shutdownTracing()
+
 	dbPassword := os.Getenv("DB_PASS")
 	if dbPassword == "" {
 		log.Fatal("DB_PASS environment variable is required")
@@ -66,7 +77,11 @@ func main() {
 	log.Printf("Starting Vacancy Service on gRPC port: %s", grpcPort)
 
 	// Запуск gRPC сервера
-	grpcServer := server.New(grpcPort, vacancyHandlers, applicationHandlers)
+	tlsOpts, err := mtls.ServerOptions(mtls.ConfigFromEnv(), server.Policy)
+	if err != nil {
+		log.Fatalf("Failed to configure mTLS: %v", err)
+	}
+	grpcServer := server.New(grpcPort, tlsOpts, vacancyHandlers, applicationHandlers)
 
 	// Graceful shutdown
 	go func() {
@@ -11,8 +11,10 @@ import (
 
 	searchv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/search/v1"
 	vacancyv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/vacancy/v1"
+	"hh_for_students/vacancy-service/internal/mtls"
+	"hh_for_students/vacancy-service/internal/tracing"
+
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 const indexTimeout = 5 * time.Second
@@ -27,7 +29,12 @@ func New(addr string) *Client {
 		log.Printf("searchclient: SEARCH_GRPC_ADDR is empty, indexing disabled")
 		return &Client{}
 	}
-	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	creds, err := mtls.DialOption(mtls.ConfigFromEnv(), addr)
+	if err != nil {
+		log.Printf("searchclient: mTLS config: %v (indexing disabled)", err)
+		return &Client{}
+	}
+	conn, err := grpc.NewClient(addr, creds, tracing.DialOption())
 	if err != nil {
 		log.Printf("searchclient: dial %s failed: %v (indexing disabled)", addr, err)
 		return &Client{}
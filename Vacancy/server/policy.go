@@ -0,0 +1,16 @@
+package server
+
+import (
+	vacancyv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/vacancy/v1"
+	"hh_for_students/vacancy-service/internal/mtls"
+)
+
+// Policy — кто может вызывать методы сервиса при включённом mTLS. Отклики —
+// только Gateway.
+var Policy = mtls.Policy{
+	Default: []string{mtls.Gateway},
+	Methods: map[string][]string{
+		// Переиндексация Search.
+		vacancyv1.VacancyService_GetAllVacancies_FullMethodName: {mtls.Gateway, mtls.Search},
+	},
+}
@@ -8,6 +8,7 @@ import (
 	applicationv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/application/v1"
 	vacancyv1 "github.com/StudJobs/proto_srtucture/gen/go/proto/vacancy/v1"
 	"hh_for_students/vacancy-service/internal/metrics"
+	"hh_for_students/vacancy-service/internal/tracing"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/health"
@@ -21,8 +22,9 @@ type Server struct {
 	healthServer *health.Server
 }
 
-func New(port string, vacancyService vacancyv1.VacancyServiceServer, applicationService applicationv1.ApplicationServiceServer) *Server {
-	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(metrics.UnaryInterceptor()))
+func New(port string, opts []grpc.ServerOption, vacancyService vacancyv1.VacancyServiceServer, applicationService applicationv1.ApplicationServiceServer) *Server {
+	// Метрики — первыми, чтобы учитывать и отклонённые mTLS-политикой вызовы.
+	grpcServer := grpc.NewServer(append([]grpc.ServerOption{tracing.ServerOption(), grpc.ChainUnaryInterceptor(metrics.UnaryInterceptor())}, opts...)...)
 
 	// Регистрация сервисов
 	vacancyv1.RegisterVacancyServiceServer(grpcServer, vacancyService)
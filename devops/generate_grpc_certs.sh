@@ -0,0 +1,65 @@
+#!/bin/sh
+# generate_grpc_certs.sh — локальный CA и сертификаты сервисов для mTLS между
+# API-Gateway и gRPC-сервисами. CN сертификата — имя сервиса, по нему сервер
+# решает, какие методы доступны вызывающему (server/policy.go каждого сервиса).
+# SAN — docker-имя сервиса и localhost (для grpc_health_probe в контейнере).
+#
+# Повторный запуск ничего не перевыпускает: чтобы перевыпустить всё, удали
+# devops/certs/grpc; один сертификат — удали его .crt.
+
+set -e
+
+CERT_DIR="$(cd "$(dirname "$0")" && pwd)/certs/grpc"
+DAYS=825
+
+# имя сервиса (CN) : docker-имена (SAN)
+SERVICES="
+api-gateway:api-gateway
+auth:auth
+users:user,users
+achievements:achieve,achievements
+company:company
+vacancy:vacancy
+skills:skills
+search:search
+microtasks:microtasks
+ops:
+"
+
+mkdir -p "$CERT_DIR"
+cd "$CERT_DIR"
+
+if [ ! -f ca.crt ]; then
+    echo "Generating gRPC CA..."
+    openssl genrsa -out ca.key 4096
+    openssl req -new -x509 -key ca.key -out ca.crt -days 3650 \
+        -subj "/O=StudJobs/CN=StudJobs gRPC CA"
+fi
+
+for entry in $SERVICES; do
+    name="${entry%%:*}"
+    hosts="${entry#*:}"
+
+    if [ -f "$name.crt" ]; then
+        continue
+    fi
+    echo "Generating certificate for $name..."
+
+    san="DNS:localhost"
+    for host in $(echo "$hosts" | tr ',' ' '); do
+        san="$san,DNS:$host"
+    done
+
+    openssl genrsa -out "$name.key" 2048
+    openssl req -new -key "$name.key" -out "$name.csr" -subj "/O=StudJobs/CN=$name"
+    printf 'basicConstraints = CA:FALSE\nkeyUsage = digitalSignature, keyEncipherment\nextendedKeyUsage = serverAuth, clientAuth\nsubjectAltName = %s\n' "$san" > "$name.ext"
+    openssl x509 -req -in "$name.csr" -CA ca.crt -CAkey ca.key -CAcreateserial \
+        -out "$name.crt" -days "$DAYS" -extfile "$name.ext"
+    rm -f "$name.csr" "$name.ext"
+done
+
+# Закрытые ключи — только владельцу. Контейнеры сервисов работают от root и
+# читают смонтированные read-only ключи и с правами 600.
+chmod 600 ./*.key
+
+echo "gRPC certificates are in $CERT_DIR"